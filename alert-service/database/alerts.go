@@ -55,13 +55,33 @@ func (md *MongoDatabase) ExistNoDataAlertByHost(hostname string) (bool, error) {
 	return val > 0, nil
 }
 
+// AckOldAlerts ack the alerts older than dueDays, recording the change in their status history.
+// The resolved alerts keep their status
 func (md *MongoDatabase) AckOldAlerts(dueDays int) (*mongo.UpdateResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	expiredDate := md.TimeNow().AddDate(0, 0, -dueDays)
-	filter := bson.M{"date": bson.M{"$lt": expiredDate}}
-	update := bson.M{"$set": bson.M{"alertStatus": "ACK"}}
+	now := md.TimeNow()
+	expiredDate := now.AddDate(0, 0, -dueDays)
+	filter := bson.M{
+		"date":        bson.M{"$lt": expiredDate},
+		"alertStatus": bson.M{"$nin": bson.A{model.AlertStatusAck, model.AlertStatusResolved}},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"statusHistory": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$statusHistory", bson.A{}}},
+				bson.A{bson.M{
+					"date":     now,
+					"username": "",
+					"field":    model.AlertStatusChangeFieldStatus,
+					"from":     "$alertStatus",
+					"to":       model.AlertStatusAck,
+				}},
+			}},
+			"alertStatus": model.AlertStatusAck,
+		}}},
+	}
 
 	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("alerts").
 		UpdateMany(ctx, filter, update)
@@ -73,6 +93,7 @@ func (md *MongoDatabase) AckOldAlerts(dueDays int) (*mongo.UpdateResult, error)
 	return res, nil
 }
 
+// RemoveOldAlerts remove the acked and resolved alerts older than dueDays
 func (md *MongoDatabase) RemoveOldAlerts(dueDays int) (*mongo.DeleteResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -80,7 +101,7 @@ func (md *MongoDatabase) RemoveOldAlerts(dueDays int) (*mongo.DeleteResult, erro
 	expiredDate := md.TimeNow().AddDate(0, 0, -dueDays)
 	filter := bson.M{
 		"date":        bson.M{"$lt": expiredDate},
-		"alertStatus": "ACK",
+		"alertStatus": bson.M{"$in": bson.A{model.AlertStatusAck, model.AlertStatusResolved}},
 	}
 
 	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("alerts").
@@ -131,3 +152,19 @@ func (md *MongoDatabase) FindAlertsByDate(startDate, endDate time.Time) ([]model
 
 	return alerts, nil
 }
+
+// FindAlertRoutingRules return all the alert routing rules
+func (md *MongoDatabase) FindAlertRoutingRules() ([]model.AlertRoutingRule, error) {
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("alert_routing_rules").
+		Find(context.TODO(), bson.D{})
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	rules := make([]model.AlertRoutingRule, 0)
+	if err := cur.All(context.TODO(), &rules); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return rules, nil
+}
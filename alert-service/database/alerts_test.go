@@ -90,3 +90,49 @@ func (m *MongodbSuite) TestExistNoDataAlert_SuccessExist() {
 
 	assert.True(m.T(), exist)
 }
+
+func (m *MongodbSuite) TestAckOldAlerts() {
+	defer m.db.Client.Database(m.dbname).Collection("alerts").DeleteMany(context.TODO(), bson.M{})
+
+	m.db.TimeNow = utils.Btc(utils.P("2019-11-15T18:02:03Z"))
+	defer func() { m.db.TimeNow = nil }()
+
+	resolved := alert1
+	resolved.ID = utils.Str2oid("5dd40bfb12f54dfda7b1c292")
+	resolved.AlertStatus = model.AlertStatusResolved
+
+	recent := alert1
+	recent.ID = utils.Str2oid("5dd40bfb12f54dfda7b1c293")
+	recent.Date = utils.P("2019-11-14T18:02:03Z")
+
+	for _, alert := range []model.Alert{alert1, resolved, recent} {
+		_, err := m.db.InsertAlert(alert)
+		require.NoError(m.T(), err)
+	}
+
+	res, err := m.db.AckOldAlerts(7)
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), int64(1), res.ModifiedCount)
+
+	find := func(alert model.Alert) model.Alert {
+		var out model.Alert
+		err := m.db.Client.Database(m.dbname).Collection("alerts").FindOne(context.TODO(), bson.M{"_id": alert.ID}).Decode(&out)
+		require.NoError(m.T(), err)
+
+		return out
+	}
+
+	acked := find(alert1)
+	assert.Equal(m.T(), model.AlertStatusAck, acked.AlertStatus)
+	assert.Equal(m.T(), []model.AlertStatusChange{
+		{
+			Date:  utils.P("2019-11-15T18:02:03Z"),
+			Field: model.AlertStatusChangeFieldStatus,
+			From:  model.AlertStatusNew,
+			To:    model.AlertStatusAck,
+		},
+	}, acked.StatusHistory)
+
+	assert.Equal(m.T(), model.AlertStatusResolved, find(resolved).AlertStatus)
+	assert.Equal(m.T(), model.AlertStatusNew, find(recent).AlertStatus)
+}
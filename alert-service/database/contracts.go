@@ -0,0 +1,65 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// contractsCollections contains the collections of the contracts with a support expiration, by technology
+var contractsCollections = map[string]string{
+	model.TechnologyOracleDatabase:     "oracle_database_contracts",
+	model.TechnologyOracleMySQL:        "mysql_contracts",
+	model.TechnologyMicrosoftSQLServer: "ms_sqlserver_database_contracts",
+}
+
+// FindContractsSupport return the support expiration of the Oracle, MySQL and SQL Server contracts that have one
+func (md *MongoDatabase) FindContractsSupport() ([]model.ContractSupport, error) {
+	contracts := make([]model.ContractSupport, 0)
+
+	for _, technology := range []string{model.TechnologyOracleDatabase, model.TechnologyOracleMySQL, model.TechnologyMicrosoftSQLServer} {
+		opts := options.Find().SetProjection(bson.M{
+			"contractID":        1,
+			"location":          1,
+			"supportExpiration": 1,
+		})
+
+		cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(contractsCollections[technology]).
+			Find(context.TODO(), bson.M{"supportExpiration": bson.M{"$type": "date"}}, opts)
+		if err != nil {
+			return nil, utils.NewError(err, "DB ERROR")
+		}
+
+		current := make([]model.ContractSupport, 0)
+		if err := cur.All(context.TODO(), &current); err != nil {
+			return nil, utils.NewError(err, "DB ERROR")
+		}
+
+		for i := range current {
+			current[i].Technology = technology
+		}
+
+		contracts = append(contracts, current...)
+	}
+
+	return contracts, nil
+}
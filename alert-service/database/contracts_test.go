@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestFindContractsSupport() {
+	for _, collection := range contractsCollections {
+		defer m.db.Client.Database(m.dbname).Collection(collection).DeleteMany(context.TODO(), bson.M{})
+	}
+
+	expiration := utils.P("2024-06-30T00:00:00Z")
+
+	_, err := m.db.Client.Database(m.dbname).Collection("oracle_database_contracts").InsertMany(context.TODO(), []interface{}{
+		bson.M{"_id": utils.Str2oid("5dd40bfb12f54dfda7b1c291"), "contractID": "AID001", "location": "Italy", "supportExpiration": expiration},
+		bson.M{"_id": utils.Str2oid("5dd40bfb12f54dfda7b1c292"), "contractID": "AID002", "location": "Italy", "supportExpiration": nil},
+	})
+	require.NoError(m.T(), err)
+
+	_, err = m.db.Client.Database(m.dbname).Collection("mysql_contracts").InsertOne(context.TODO(),
+		bson.M{"_id": utils.Str2oid("5dd40bfb12f54dfda7b1c293"), "contractID": "MYSQL001", "location": "Germany", "supportExpiration": expiration})
+	require.NoError(m.T(), err)
+
+	_, err = m.db.Client.Database(m.dbname).Collection("ms_sqlserver_database_contracts").InsertOne(context.TODO(),
+		bson.M{"_id": utils.Str2oid("5dd40bfb12f54dfda7b1c294"), "contractID": "MSSQL001", "location": "Germany"})
+	require.NoError(m.T(), err)
+
+	actual, err := m.db.FindContractsSupport()
+	require.NoError(m.T(), err)
+
+	expected := []model.ContractSupport{
+		{
+			ID:                utils.Str2oid("5dd40bfb12f54dfda7b1c291"),
+			Technology:        model.TechnologyOracleDatabase,
+			ContractID:        "AID001",
+			Location:          "Italy",
+			SupportExpiration: expiration,
+		},
+		{
+			ID:                utils.Str2oid("5dd40bfb12f54dfda7b1c293"),
+			Technology:        model.TechnologyOracleMySQL,
+			ContractID:        "MYSQL001",
+			Location:          "Germany",
+			SupportExpiration: expiration,
+		},
+	}
+	assert.Equal(m.T(), expected, actual)
+}
+
+func (m *MongodbSuite) TestExistAlert() {
+	defer m.db.Client.Database(m.dbname).Collection("alerts").DeleteMany(context.TODO(), bson.M{})
+
+	alert := model.Alert{
+		ID:            utils.Str2oid("5dd40bfb12f54dfda7b1c291"),
+		AlertCategory: model.AlertCategoryLicense,
+		AlertCode:     model.AlertCodeContractSupportExpiring,
+		AlertSeverity: model.AlertSeverityWarning,
+		AlertStatus:   model.AlertStatusAck,
+		Date:          utils.P("2024-04-01T00:00:00Z"),
+		OtherInfo:     map[string]interface{}{"contractID": "5dd40bfb12f54dfda7b1c293", "leadTime": 90},
+	}
+	_, err := m.db.InsertAlert(alert)
+	require.NoError(m.T(), err)
+
+	exist, err := m.db.ExistAlert(alert)
+	require.NoError(m.T(), err)
+	assert.True(m.T(), exist)
+
+	other := alert
+	other.OtherInfo = map[string]interface{}{"contractID": "5dd40bfb12f54dfda7b1c293", "leadTime": 30}
+
+	exist, err = m.db.ExistAlert(other)
+	require.NoError(m.T(), err)
+	assert.False(m.T(), exist)
+}
@@ -37,6 +37,8 @@ type MongoDatabaseInterface interface {
 	Init()
 	// FindHostData find a host data
 	FindHostData(id primitive.ObjectID) (model.HostDataBE, error)
+	// FindCurrentHostData return the current hostdata of the host
+	FindCurrentHostData(hostname string) (model.HostDataBE, error)
 	// FindMostRecentHostDataOlderThan return the most recest hostdata that is older than t
 	FindMostRecentHostDataOlderThan(hostname string, t time.Time) (model.HostDataBE, error)
 	// InsertAlert inserr the alert in the database
@@ -47,6 +49,25 @@ type MongoDatabaseInterface interface {
 	AckOldAlerts(dueDays int) (*mongo.UpdateResult, error)
 	RemoveOldAlerts(dueDays int) (*mongo.DeleteResult, error)
 	FindAlertsByDate(startDate, endDate time.Time) ([]model.Alert, error)
+	// FindAlertRoutingRules return all the alert routing rules
+	FindAlertRoutingRules() ([]model.AlertRoutingRule, error)
+
+	// FindOpenAlertDuplicate return the new or acked alert identical to alert, or nil if there isn't
+	FindOpenAlertDuplicate(alert model.Alert) (*model.Alert, error)
+	// ExistAlert return true if an alert identical to alert exists, whatever its status
+	ExistAlert(alert model.Alert) (bool, error)
+	// FoldAlert increments the occurrences of the alert with id and updates it with the date, description and other info of alert
+	FoldAlert(id primitive.ObjectID, alert model.Alert) error
+	// InsertAlertRaise keep track that the alert was raised, to detect flapping alerts
+	InsertAlertRaise(alert model.Alert) error
+	// CountAlertRaises return how many times an alert identical to alert was raised since the date
+	CountAlertRaises(alert model.Alert, since time.Time) (int64, error)
+	// RemoveOldAlertRaises delete the raises older than dueDays
+	RemoveOldAlertRaises(dueDays int) (*mongo.DeleteResult, error)
+	// FindActiveMaintenanceWindows return the maintenance windows active at the date
+	FindActiveMaintenanceWindows(at time.Time) ([]model.MaintenanceWindow, error)
+	// FindContractsSupport return the support expiration of the Oracle, MySQL and SQL Server contracts that have one
+	FindContractsSupport() ([]model.ContractSupport, error)
 
 	GetSimulatedHosts() ([]model.SimulatedHost, error)
 	UpdateHostCores(hostname string, cores int) error
@@ -22,6 +22,7 @@ import (
 	"github.com/amreo/mu"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
@@ -48,6 +49,26 @@ func (md *MongoDatabase) FindHostData(id primitive.ObjectID) (model.HostDataBE,
 	return out, nil
 }
 
+// FindCurrentHostData return the current hostdata of the host
+func (md *MongoDatabase) FindCurrentHostData(hostname string) (model.HostDataBE, error) {
+	res := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").FindOne(context.TODO(), bson.M{
+		"hostname": hostname,
+		"archived": false,
+	})
+	if res.Err() == mongo.ErrNoDocuments {
+		return model.HostDataBE{}, utils.ErrHostNotFound
+	} else if res.Err() != nil {
+		return model.HostDataBE{}, utils.NewError(res.Err(), "DB ERROR")
+	}
+
+	var out model.HostDataBE
+	if err := res.Decode(&out); err != nil {
+		return model.HostDataBE{}, utils.NewError(err, "DB ERROR")
+	}
+
+	return out, nil
+}
+
 // FindMostRecentHostDataOlderThan return the most recest hostdata that is older than t
 func (md *MongoDatabase) FindMostRecentHostDataOlderThan(hostname string, t time.Time) (model.HostDataBE, error) {
 	var out model.HostDataBE
@@ -0,0 +1,187 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const alertRaisesCollection = "alert_raises"
+
+// alertIdentity return the filter that matches the alerts identical to alert:
+// same code, hostname, dbname and the other identity keys of its code
+func alertIdentity(alert model.Alert) bson.M {
+	identity := bson.M{
+		"alertCode":          alert.AlertCode,
+		"otherInfo.hostname": nil,
+		"otherInfo.dbname":   nil,
+	}
+
+	if hostname := alert.Hostname(); hostname != "" {
+		identity["otherInfo.hostname"] = hostname
+	}
+
+	if dbname := alert.Dbname(); dbname != "" {
+		identity["otherInfo.dbname"] = dbname
+	}
+
+	for _, key := range alert.IdentityKeys() {
+		identity["otherInfo."+key] = alert.OtherInfo[key]
+	}
+
+	return identity
+}
+
+// FindOpenAlertDuplicate return the new or acked alert identical to alert, or nil if there isn't.
+// The acked alerts are still open, so raising them again doesn't create a new alert
+func (md *MongoDatabase) FindOpenAlertDuplicate(alert model.Alert) (*model.Alert, error) {
+	filter := alertIdentity(alert)
+	filter["alertStatus"] = bson.M{"$in": bson.A{model.AlertStatusNew, model.AlertStatusAck}}
+
+	res := md.Client.Database(md.Config.Mongodb.DBName).Collection("alerts").FindOne(context.TODO(), filter)
+	if res.Err() == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if res.Err() != nil {
+		return nil, utils.NewError(res.Err(), "DB ERROR")
+	}
+
+	var out model.Alert
+	if err := res.Decode(&out); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return &out, nil
+}
+
+// ExistAlert return true if an alert identical to alert exists, whatever its status
+func (md *MongoDatabase) ExistAlert(alert model.Alert) (bool, error) {
+	count, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("alerts").
+		CountDocuments(context.TODO(), alertIdentity(alert))
+	if err != nil {
+		return false, utils.NewError(err, "DB ERROR")
+	}
+
+	return count > 0, nil
+}
+
+// FoldAlert increments the occurrences of the alert with id and updates it with the date, description and other info of alert,
+// so it shows the last figures of the alert
+func (md *MongoDatabase) FoldAlert(id primitive.ObjectID, alert model.Alert) error {
+	set := bson.M{
+		"occurrences": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$occurrences", 1}}, 1}},
+		"lastSeen":    alert.Date,
+		"description": alert.Description,
+	}
+
+	if alert.OtherInfo != nil {
+		set["otherInfo"] = bson.M{"$literal": alert.OtherInfo}
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: set}},
+	}
+
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("alerts").
+		UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// InsertAlertRaise keep track that the alert was raised, to detect flapping alerts
+func (md *MongoDatabase) InsertAlertRaise(alert model.Alert) error {
+	identity := alertIdentity(alert)
+	otherInfo := bson.M{
+		"hostname": identity["otherInfo.hostname"],
+		"dbname":   identity["otherInfo.dbname"],
+	}
+
+	for _, key := range alert.IdentityKeys() {
+		if value, ok := alert.OtherInfo[key]; ok {
+			otherInfo[key] = value
+		}
+	}
+
+	raise := bson.M{
+		"alertCode": alert.AlertCode,
+		"otherInfo": otherInfo,
+		"date":      alert.Date,
+	}
+
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(alertRaisesCollection).
+		InsertOne(context.TODO(), raise)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// CountAlertRaises return how many times an alert identical to alert was raised since the date
+func (md *MongoDatabase) CountAlertRaises(alert model.Alert, since time.Time) (int64, error) {
+	filter := alertIdentity(alert)
+	filter["date"] = bson.M{"$gte": since}
+
+	count, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(alertRaisesCollection).
+		CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, utils.NewError(err, "DB ERROR")
+	}
+
+	return count, nil
+}
+
+// RemoveOldAlertRaises delete the raises older than dueDays
+func (md *MongoDatabase) RemoveOldAlertRaises(dueDays int) (*mongo.DeleteResult, error) {
+	expiredDate := md.TimeNow().AddDate(0, 0, -dueDays)
+
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(alertRaisesCollection).
+		DeleteMany(context.TODO(), bson.M{"date": bson.M{"$lt": expiredDate}})
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return res, nil
+}
+
+// FindActiveMaintenanceWindows return the maintenance windows active at the date
+func (md *MongoDatabase) FindActiveMaintenanceWindows(at time.Time) ([]model.MaintenanceWindow, error) {
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("maintenance_windows").
+		Find(context.TODO(), bson.M{
+			"start": bson.M{"$lte": at},
+			"end":   bson.M{"$gt": at},
+		})
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	windows := make([]model.MaintenanceWindow, 0)
+	if err := cur.All(context.TODO(), &windows); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return windows, nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestFindOpenAlertDuplicate_FoldAlert() {
+	defer m.db.Client.Database(m.dbname).Collection("alerts").DeleteMany(context.TODO(), bson.M{})
+
+	open := model.Alert{
+		ID:            utils.Str2oid("5dd40bfb12f54dfda7b1c291"),
+		AlertCategory: model.AlertCategoryAgent,
+		AlertCode:     model.AlertCodeAgentError,
+		AlertSeverity: model.AlertSeverityCritical,
+		AlertStatus:   model.AlertStatusNew,
+		Date:          utils.P("2019-11-05T18:02:03Z"),
+		Description:   "first error",
+		OtherInfo:     map[string]interface{}{"hostname": "myhost"},
+	}
+	_, err := m.db.InsertAlert(open)
+	require.NoError(m.T(), err)
+
+	m.T().Run("Other dbname", func(t *testing.T) {
+		other := open
+		other.OtherInfo = map[string]interface{}{"hostname": "myhost", "dbname": "mydb"}
+
+		duplicate, err := m.db.FindOpenAlertDuplicate(other)
+		require.NoError(t, err)
+		assert.Nil(t, duplicate)
+	})
+
+	m.T().Run("Other identity key of the code", func(t *testing.T) {
+		expiring := model.Alert{
+			ID:            utils.Str2oid("5dd40bfb12f54dfda7b1c292"),
+			AlertCategory: model.AlertCategoryLicense,
+			AlertCode:     model.AlertCodeContractSupportExpiring,
+			AlertSeverity: model.AlertSeverityWarning,
+			AlertStatus:   model.AlertStatusNew,
+			Date:          utils.P("2019-11-05T18:02:03Z"),
+			OtherInfo:     map[string]interface{}{"contractID": "c1", "leadTime": 30},
+		}
+		_, err := m.db.InsertAlert(expiring)
+		require.NoError(t, err)
+
+		other := expiring
+		other.OtherInfo = map[string]interface{}{"contractID": "c2", "leadTime": 30}
+
+		duplicate, err := m.db.FindOpenAlertDuplicate(other)
+		require.NoError(t, err)
+		assert.Nil(t, duplicate)
+
+		duplicate, err = m.db.FindOpenAlertDuplicate(expiring)
+		require.NoError(t, err)
+		require.NotNil(t, duplicate)
+		assert.Equal(t, expiring.ID, duplicate.ID)
+	})
+
+	m.T().Run("Fold", func(t *testing.T) {
+		duplicate, err := m.db.FindOpenAlertDuplicate(open)
+		require.NoError(t, err)
+		require.NotNil(t, duplicate)
+		assert.Equal(t, open.ID, duplicate.ID)
+
+		folded := open
+		folded.Date = utils.P("2019-11-06T18:02:03Z")
+		folded.Description = "second error"
+		folded.OtherInfo = map[string]interface{}{"hostname": "myhost", "errors": int32(2)}
+
+		err = m.db.FoldAlert(duplicate.ID, folded)
+		require.NoError(t, err)
+
+		var out model.Alert
+		err = m.db.Client.Database(m.dbname).Collection("alerts").
+			FindOne(context.TODO(), bson.M{"_id": open.ID}).Decode(&out)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, out.Occurrences)
+		assert.Equal(t, utils.P("2019-11-06T18:02:03Z"), *out.LastSeen)
+		assert.Equal(t, "second error", out.Description)
+		assert.Equal(t, map[string]interface{}{"hostname": "myhost", "errors": int32(2)}, out.OtherInfo)
+	})
+
+	m.T().Run("Acked alert", func(t *testing.T) {
+		_, err := m.db.Client.Database(m.dbname).Collection("alerts").
+			UpdateOne(context.TODO(), bson.M{"_id": open.ID}, bson.M{"$set": bson.M{"alertStatus": model.AlertStatusAck}})
+		require.NoError(t, err)
+
+		duplicate, err := m.db.FindOpenAlertDuplicate(open)
+		require.NoError(t, err)
+		require.NotNil(t, duplicate)
+		assert.Equal(t, open.ID, duplicate.ID)
+	})
+
+	m.T().Run("Resolved alert", func(t *testing.T) {
+		_, err := m.db.Client.Database(m.dbname).Collection("alerts").
+			UpdateOne(context.TODO(), bson.M{"_id": open.ID}, bson.M{"$set": bson.M{"alertStatus": model.AlertStatusResolved}})
+		require.NoError(t, err)
+
+		duplicate, err := m.db.FindOpenAlertDuplicate(open)
+		require.NoError(t, err)
+		assert.Nil(t, duplicate)
+	})
+}
+
+func (m *MongodbSuite) TestCountAlertRaises() {
+	defer m.db.Client.Database(m.dbname).Collection(alertRaisesCollection).DeleteMany(context.TODO(), bson.M{})
+
+	alert := model.Alert{
+		AlertCode: model.AlertCodeNoData,
+		OtherInfo: map[string]interface{}{"hostname": "myhost"},
+	}
+
+	for _, date := range []string{"2019-11-05T10:00:00Z", "2019-11-05T11:00:00Z", "2019-11-05T12:00:00Z"} {
+		alert.Date = utils.P(date)
+		require.NoError(m.T(), m.db.InsertAlertRaise(alert))
+	}
+
+	count, err := m.db.CountAlertRaises(alert, utils.P("2019-11-05T10:30:00Z"))
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), int64(2), count)
+
+	other := alert
+	other.OtherInfo = map[string]interface{}{"hostname": "otherhost"}
+
+	count, err = m.db.CountAlertRaises(other, utils.P("2019-11-05T00:00:00Z"))
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), int64(0), count)
+}
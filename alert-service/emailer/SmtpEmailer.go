@@ -100,3 +100,21 @@ func (emailer *SMTPEmailer) SendReportEmail(subject string, to []string, attachm
 
 	return emailer.send(*m)
 }
+
+func (emailer *SMTPEmailer) SendEmailWithAttachments(subject, text string, to []string, attachments []Attachment) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", emailer.Config.AlertService.Emailer.From)
+	m.SetHeader("To", to...)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", text)
+
+	for _, a := range attachments {
+		content := a.Content
+		m.Attach(a.Filename, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		}))
+	}
+
+	return emailer.send(*m)
+}
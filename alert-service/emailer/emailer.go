@@ -23,4 +23,12 @@ type Emailer interface {
 	SendEmail(subject string, text string, to []string) error
 	SendHtmlEmail(subject, text string, to []string) error
 	SendReportEmail(subject string, to []string, attachmentBuff bytes.Buffer) error
+	// SendEmailWithAttachments send a email with the attachments
+	SendEmailWithAttachments(subject, text string, to []string, attachments []Attachment) error
+}
+
+// Attachment contains a file attached to a email
+type Attachment struct {
+	Filename string
+	Content  []byte
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/bamzi/jobrunner"
 	"github.com/ercole-io/ercole/v2/alert-service/database"
 	"github.com/ercole-io/ercole/v2/alert-service/emailer"
+	"github.com/ercole-io/ercole/v2/alert-service/service"
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
 )
@@ -19,6 +20,7 @@ type Job struct {
 	Database database.MongoDatabaseInterface
 	Log      logger.Logger
 	Emailer  emailer.Emailer
+	Service  service.AlertServiceInterface
 }
 
 func (j *Job) Init() {
@@ -53,6 +55,15 @@ func (j *Job) Init() {
 		jobrunner.Now(&reportAlertJob)
 	}
 
+	contractSupportAlertJob := ContractSupportAlertJob{Service: j.Service, Log: j.Log}
+	if err := jobrunner.Schedule(j.Config.AlertService.ContractSupportAlertJob.Crontab, &contractSupportAlertJob); err != nil {
+		j.Log.Errorf("something went wrong scheduling contractSupportAlertJob: %v", err)
+	}
+
+	if j.Config.AlertService.ContractSupportAlertJob.RunAtStartup {
+		jobrunner.Now(&contractSupportAlertJob)
+	}
+
 	simulatedHostAlertJob := SimulatedHostAlertJob{Database: j.Database, Config: j.Config, Log: j.Log, Emailer: j.Emailer}
 	jobrunner.Every(time.Minute*5, &simulatedHostAlertJob)
 }
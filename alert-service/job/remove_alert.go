@@ -20,4 +20,12 @@ func (j *RemoveAlertJob) Run() {
 	}
 
 	j.Log.Infof("removed %d documents", res.DeletedCount)
+
+	raises, err := j.Database.RemoveOldAlertRaises(j.Config.AlertService.RemoveAlertJob.DueDays)
+	if err != nil {
+		j.Log.Errorf("remove alert job: %v", err)
+		return
+	}
+
+	j.Log.Infof("removed %d alert raises", raises.DeletedCount)
 }
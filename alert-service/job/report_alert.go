@@ -21,6 +21,8 @@ import (
 	"time"
 
 	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/robfig/cron/v3"
+
 	"github.com/ercole-io/ercole/v2/alert-service/database"
 	"github.com/ercole-io/ercole/v2/alert-service/emailer"
 	"github.com/ercole-io/ercole/v2/config"
@@ -38,24 +40,15 @@ type ReportAlertJob struct {
 }
 
 func (r *ReportAlertJob) Run() {
-	cronIntervals := map[string]int{
-		"@daily":   1,
-		"@weekly":  7,
-		"@monthly": 30,
-	}
-
-	crontab := strings.ToLower(r.Config.AlertService.ReportAlertJob.Crontab)
+	now := time.Now()
 
-	days, validCron := cronIntervals[crontab]
-	if !validCron {
-		r.Log.Error("report alert job - invalid crontab configuration")
+	from, err := reportAlertFrom(r.Config.AlertService.ReportAlertJob.Crontab, now)
+	if err != nil {
+		r.Log.Errorf("report alert job - invalid crontab configuration: %s", err)
 		return
 	}
 
-	now := time.Now()
-	end := now.AddDate(0, 0, -days)
-
-	alerts, err := r.Database.FindAlertsByDate(end, now)
+	alerts, err := r.Database.FindAlertsByDate(from, now)
 	if err != nil {
 		r.Log.Error(err)
 		return
@@ -140,6 +133,54 @@ tr:nth-child(even) {
 	}
 }
 
+// reportAlertFrom return the start of the period reported by the run at now: the @daily, @weekly and @monthly
+// crontabs report the last 1, 7 and 30 days, the others the time since the previous activation
+func reportAlertFrom(crontab string, now time.Time) (time.Time, error) {
+	cronIntervals := map[string]int{
+		"@daily":   1,
+		"@weekly":  7,
+		"@monthly": 30,
+	}
+
+	if days, ok := cronIntervals[strings.ToLower(crontab)]; ok {
+		return now.AddDate(0, 0, -days), nil
+	}
+
+	schedule, err := cron.ParseStandard(crontab)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	current, ok := lastActivationBefore(schedule, now.Add(time.Nanosecond))
+	if !ok {
+		return time.Time{}, fmt.Errorf("%q is never activated", crontab)
+	}
+
+	previous, ok := lastActivationBefore(schedule, current)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%q is never activated", crontab)
+	}
+
+	return previous, nil
+}
+
+// lastActivationBefore return the last activation of the schedule before t, searched in the previous two years
+func lastActivationBefore(schedule cron.Schedule, t time.Time) (time.Time, bool) {
+	for d := time.Minute; d <= 2*366*24*time.Hour; d *= 2 {
+		var last time.Time
+
+		for a := schedule.Next(t.Add(-d)); a.Before(t); a = schedule.Next(a) {
+			last = a
+		}
+
+		if !last.IsZero() {
+			return last, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
 func (r *ReportAlertJob) createAlertReportXlsx(alerts []model.Alert) (*excelize.File, error) {
 	sheet := "Alerts"
 	headers := []string{
@@ -199,6 +240,10 @@ func (r *ReportAlertJob) getAlertMails(alerts []model.Alert) AlertMails {
 		model.AlertCodeMissingDatabase:         {r.Config.AlertService.Emailer.AlertType.MissingDatabase.Enable, r.Config.AlertService.Emailer.AlertType.MissingDatabase.To},
 		model.AlertCodeAgentError:              {r.Config.AlertService.Emailer.AlertType.AgentError.Enable, r.Config.AlertService.Emailer.AlertType.AgentError.To},
 		model.AlertCodeNoData:                  {r.Config.AlertService.Emailer.AlertType.NoData.Enable, r.Config.AlertService.Emailer.AlertType.NoData.To},
+		model.AlertCodeLicenseNonCompliant:     {r.Config.AlertService.Emailer.AlertType.LicenseNonCompliant.Enable, r.Config.AlertService.Emailer.AlertType.LicenseNonCompliant.To},
+		model.AlertCodeContractSupportExpiring: {r.Config.AlertService.Emailer.AlertType.ContractSupportExpiring.Enable, r.Config.AlertService.Emailer.AlertType.ContractSupportExpiring.To},
+		model.AlertCodeContractSupportExpired:  {r.Config.AlertService.Emailer.AlertType.ContractSupportExpired.Enable, r.Config.AlertService.Emailer.AlertType.ContractSupportExpired.To},
+		model.AlertCodeClusterTopologyMismatch: {r.Config.AlertService.Emailer.AlertType.ClusterTopologyMismatch.Enable, r.Config.AlertService.Emailer.AlertType.ClusterTopologyMismatch.To},
 	}
 
 	for _, alert := range alerts {
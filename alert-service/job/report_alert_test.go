@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestReportAlertFrom(t *testing.T) {
+	testCases := []struct {
+		crontab  string
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			crontab:  "@daily",
+			now:      utils.P("2024-03-11T00:00:00Z"),
+			expected: utils.P("2024-03-10T00:00:00Z"),
+		},
+		{
+			crontab:  "@WEEKLY",
+			now:      utils.P("2024-03-10T00:00:00Z"),
+			expected: utils.P("2024-03-03T00:00:00Z"),
+		},
+		{
+			crontab:  "@monthly",
+			now:      utils.P("2024-03-31T00:00:00Z"),
+			expected: utils.P("2024-03-01T00:00:00Z"),
+		},
+		{
+			crontab:  "0 8 * * 1-5",
+			now:      utils.P("2024-03-11T08:00:00.200Z"),
+			expected: utils.P("2024-03-08T08:00:00Z"),
+		},
+		{
+			crontab:  "0 8 * * 1-5",
+			now:      utils.P("2024-03-12T08:00:01Z"),
+			expected: utils.P("2024-03-11T08:00:00Z"),
+		},
+		{
+			crontab:  "*/15 * * * *",
+			now:      utils.P("2024-03-12T10:30:00Z"),
+			expected: utils.P("2024-03-12T10:15:00Z"),
+		},
+		{
+			crontab:  "0 6 1 */3 *",
+			now:      utils.P("2024-04-01T06:00:00Z"),
+			expected: utils.P("2024-01-01T06:00:00Z"),
+		},
+	}
+
+	for _, tc := range testCases {
+		actual, err := reportAlertFrom(tc.crontab, tc.now.UTC())
+		require.NoError(t, err, tc.crontab)
+		assert.Equal(t, tc.expected, actual.UTC(), tc.crontab)
+	}
+
+	_, err := reportAlertFrom("every day", utils.P("2024-03-11T00:00:00Z"))
+	assert.Error(t, err)
+}
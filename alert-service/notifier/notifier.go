@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package notifier contains the channels used to deliver the alerts other than the emailer
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const (
+	WebhookType = "webhook"
+	SlackType   = "slack"
+	TeamsType   = "teams"
+	SyslogType  = "syslog"
+)
+
+// Notifier is a interface that wrap methods used to deliver an alert to a channel
+type Notifier interface {
+	// GetType return the type of the channel
+	GetType() string
+	// GetName return the name used to identify the notifier in the logs
+	GetName() string
+	// Accept return true if the alert should be delivered by the notifier
+	Accept(alert model.Alert) bool
+	// Notify deliver the alert, retrying with backoff as configured
+	Notify(alert model.Alert) error
+}
+
+// BuildNotifiers return the enabled notifiers configured in conf
+func BuildNotifiers(conf config.Notifiers) []Notifier {
+	notifiers := make([]Notifier, 0)
+
+	for i, c := range conf.Webhooks {
+		if c.Enabled {
+			notifiers = append(notifiers, &WebhookNotifier{Config: c, name: nameOrDefault(c.Name, WebhookType, i)})
+		}
+	}
+
+	for i, c := range conf.Slack {
+		if c.Enabled {
+			notifiers = append(notifiers, &SlackNotifier{Config: c, name: nameOrDefault(c.Name, SlackType, i)})
+		}
+	}
+
+	for i, c := range conf.Teams {
+		if c.Enabled {
+			notifiers = append(notifiers, &TeamsNotifier{Config: c, name: nameOrDefault(c.Name, TeamsType, i)})
+		}
+	}
+
+	for i, c := range conf.Syslog {
+		if c.Enabled {
+			notifiers = append(notifiers, &SyslogNotifier{Config: c, name: nameOrDefault(c.Name, SyslogType, i)})
+		}
+	}
+
+	return notifiers
+}
+
+func nameOrDefault(name, notifierType string, i int) string {
+	if name != "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s-%d", notifierType, i)
+}
+
+// Match return true if the alert is accepted by the filter
+func Match(filter config.NotifierFilter, alert model.Alert) bool {
+	if len(filter.AlertCodes) > 0 && !utils.Contains(filter.AlertCodes, alert.AlertCode) {
+		return false
+	}
+
+	if len(filter.AlertSeverities) > 0 && !utils.Contains(filter.AlertSeverities, alert.AlertSeverity) {
+		return false
+	}
+
+	return true
+}
+
+// sleep is replaced in tests
+var sleep = time.Sleep
+
+// Retry call send until it succeed or the attempts configured in policy are exhausted,
+// waiting an exponential backoff between the attempts. It return the last error
+func Retry(policy config.NotifierRetry, send func() error) error {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := time.Duration(policy.InitialBackoff) * time.Millisecond
+	maxBackoff := time.Duration(policy.MaxBackoff) * time.Millisecond
+
+	var err error
+
+	for i := 0; i < attempts; i++ {
+		if i > 0 && backoff > 0 {
+			sleep(backoff)
+
+			backoff *= 2
+			if maxBackoff > 0 && backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		if err = send(); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Subject return the short description of the alert
+func Subject(alert model.Alert) string {
+	if val, ok := alert.OtherInfo["hostname"]; ok {
+		return fmt.Sprintf("%s %s on %s", alert.AlertSeverity, alert.Description, val)
+	}
+
+	return fmt.Sprintf("%s %s", alert.AlertSeverity, alert.Description)
+}
+
+// Message return the full description of the alert
+func Message(alert model.Alert) string {
+	if val, ok := alert.OtherInfo["hostname"]; ok {
+		return fmt.Sprintf("Date: %s\nSeverity: %s\nHost: %s\nCode: %s\n%s", alert.Date, alert.AlertSeverity, val, alert.AlertCode, alert.Description)
+	}
+
+	return fmt.Sprintf("Date: %s\nSeverity: %s\nCode: %s\n%s", alert.Date, alert.AlertSeverity, alert.AlertCode, alert.Description)
+}
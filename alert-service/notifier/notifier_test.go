@@ -0,0 +1,281 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+var testAlert = model.Alert{
+	AlertCategory:           model.AlertCategoryLicense,
+	AlertAffectedTechnology: model.TechnologyOracleDatabasePtr,
+	AlertStatus:             model.AlertStatusNew,
+	OtherInfo:               map[string]interface{}{"hostname": "TestHostname"},
+	AlertSeverity:           model.AlertSeverityCritical,
+	Description:             "This is just an alert test",
+	Date:                    utils.P("2019-09-02T10:25:28Z"),
+	AlertCode:               model.AlertCodeNewLicense,
+}
+
+func TestBuildNotifiers(t *testing.T) {
+	conf := config.Notifiers{
+		Webhooks: []config.WebhookNotifier{{Enabled: true, Name: "oncall"}, {Enabled: false}},
+		Slack:    []config.SlackNotifier{{Enabled: true}},
+		Teams:    []config.TeamsNotifier{{Enabled: false}},
+		Syslog:   []config.SyslogNotifier{{Enabled: true}},
+	}
+
+	notifiers := BuildNotifiers(conf)
+	require.Len(t, notifiers, 3)
+
+	assert.Equal(t, WebhookType, notifiers[0].GetType())
+	assert.Equal(t, "oncall", notifiers[0].GetName())
+	assert.Equal(t, SlackType, notifiers[1].GetType())
+	assert.Equal(t, "slack-0", notifiers[1].GetName())
+	assert.Equal(t, SyslogType, notifiers[2].GetType())
+}
+
+func TestMatch(t *testing.T) {
+	assert.True(t, Match(config.NotifierFilter{}, testAlert))
+	assert.True(t, Match(config.NotifierFilter{
+		AlertCodes:      []string{model.AlertCodeNewLicense, model.AlertCodeNoData},
+		AlertSeverities: []string{model.AlertSeverityCritical},
+	}, testAlert))
+	assert.False(t, Match(config.NotifierFilter{AlertCodes: []string{model.AlertCodeNoData}}, testAlert))
+	assert.False(t, Match(config.NotifierFilter{AlertSeverities: []string{model.AlertSeverityWarning}}, testAlert))
+}
+
+func TestRetry(t *testing.T) {
+	var waits []time.Duration
+
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	t.Run("Success after failures", func(t *testing.T) {
+		waits = nil
+		calls := 0
+
+		err := Retry(config.NotifierRetry{MaxAttempts: 4, InitialBackoff: 100, MaxBackoff: 300}, func() error {
+			calls++
+			if calls < 4 {
+				return errors.New("fail")
+			}
+
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, calls)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, waits)
+	})
+
+	t.Run("Attempts exhausted", func(t *testing.T) {
+		waits = nil
+		calls := 0
+
+		err := Retry(config.NotifierRetry{MaxAttempts: 2, InitialBackoff: 10}, func() error {
+			calls++
+			return errors.New("fail")
+		})
+
+		assert.EqualError(t, err, "fail")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Zero policy does a single attempt", func(t *testing.T) {
+		calls := 0
+
+		err := Retry(config.NotifierRetry{}, func() error {
+			calls++
+			return errors.New("fail")
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var body []byte
+
+	var header http.Header
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		assert.Equal(t, http.MethodPut, r.Method)
+	}))
+	defer ts.Close()
+
+	n := WebhookNotifier{Config: config.WebhookNotifier{
+		URL:          ts.URL,
+		Method:       http.MethodPut,
+		Headers:      map[string]string{"Authorization": "Bearer token"},
+		BodyTemplate: `{"summary": {{json (subject .)}}, "code": {{json .AlertCode}}, "host": {{json .OtherInfo.hostname}}}`,
+	}}
+
+	require.NoError(t, n.Notify(testAlert))
+
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+	assert.JSONEq(t, `{"summary": "CRITICAL This is just an alert test on TestHostname", "code": "NEW_LICENSE", "host": "TestHostname"}`, string(body))
+}
+
+func TestWebhookNotifier_NotifyDefaultBody(t *testing.T) {
+	var actual model.Alert
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&actual))
+	}))
+	defer ts.Close()
+
+	n := WebhookNotifier{Config: config.WebhookNotifier{URL: ts.URL}}
+
+	require.NoError(t, n.Notify(testAlert))
+	assert.Equal(t, testAlert.AlertCode, actual.AlertCode)
+	assert.Equal(t, testAlert.Description, actual.Description)
+}
+
+func TestWebhookNotifier_NotifyRetry(t *testing.T) {
+	sleep = func(d time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	n := WebhookNotifier{Config: config.WebhookNotifier{URL: ts.URL, Retry: config.NotifierRetry{MaxAttempts: 2}}}
+	assert.Error(t, n.Notify(testAlert))
+
+	calls = 0
+	n.Config.Retry.MaxAttempts = 3
+	assert.NoError(t, n.Notify(testAlert))
+	assert.Equal(t, 3, calls)
+}
+
+func TestWebhookNotifier_NotifyBadTemplate(t *testing.T) {
+	n := WebhookNotifier{Config: config.WebhookNotifier{URL: "http://127.0.0.1", BodyTemplate: "{{.Missing"}}
+	assert.Error(t, n.Notify(testAlert))
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var actual slackMessage
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&actual))
+	}))
+	defer ts.Close()
+
+	n := SlackNotifier{Config: config.SlackNotifier{WebhookURL: ts.URL, Channel: "#ercole"}}
+	require.NoError(t, n.Notify(testAlert))
+
+	assert.Equal(t, "#ercole", actual.Channel)
+	assert.Equal(t, "CRITICAL This is just an alert test on TestHostname", actual.Text)
+	require.Len(t, actual.Attachments, 1)
+	assert.Equal(t, "#d32f2f", actual.Attachments[0].Color)
+	assert.Contains(t, actual.Attachments[0].Fields, slackField{Title: "Host", Value: "TestHostname", Short: true})
+}
+
+func TestTeamsNotifier_Notify(t *testing.T) {
+	var actual teamsMessageCard
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&actual))
+	}))
+	defer ts.Close()
+
+	n := TeamsNotifier{Config: config.TeamsNotifier{WebhookURL: ts.URL}}
+	require.NoError(t, n.Notify(testAlert))
+
+	assert.Equal(t, "MessageCard", actual.Type)
+	assert.Equal(t, "d32f2f", actual.ThemeColor)
+	require.Len(t, actual.Sections, 1)
+	assert.Contains(t, actual.Sections[0].Facts, teamsFact{Name: "Date", Value: "2019-09-02T10:25:28Z"})
+}
+
+func TestSyslogNotifier_Notify(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	n := SyslogNotifier{Config: config.SyslogNotifier{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Hostname: "ercole-server",
+	}}
+	require.NoError(t, n.Notify(testAlert))
+
+	buf := make([]byte, 2048)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	size, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	msg := string(buf[:size])
+	assert.True(t, strings.HasPrefix(msg, "<130>1 2019-09-02T10:25:28Z ercole-server ercole "), msg)
+	assert.Contains(t, msg, ` NEW_LICENSE [alert@32473 code="NEW_LICENSE" category="LICENSE" severity="CRITICAL" hostname="TestHostname"] `)
+	assert.True(t, strings.HasSuffix(msg, "CRITICAL This is just an alert test on TestHostname"), msg)
+}
+
+func TestSyslogNotifier_NotifyTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		b, _ := io.ReadAll(c)
+		received <- string(b)
+	}()
+
+	n := SyslogNotifier{Config: config.SyslogNotifier{Network: "tcp", Address: ln.Addr().String(), Hostname: "h"}}
+	require.NoError(t, n.Notify(testAlert))
+
+	msg := <-received
+	parts := strings.SplitN(msg, " ", 2)
+	require.Len(t, parts, 2)
+	assert.Equal(t, strconv.Itoa(len(parts[1])), parts[0])
+}
+
+func TestEscapeSDParam(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\]`, escapeSDParam(`a"b\c]`))
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package notifier
+
+import (
+	"fmt"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+// SlackNotifier send the alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	Config config.SlackNotifier
+	name   string
+}
+
+type slackMessage struct {
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Text   string       `json:"text"`
+	Fields []slackField `json:"fields"`
+	Ts     int64        `json:"ts"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (n *SlackNotifier) GetType() string {
+	return SlackType
+}
+
+func (n *SlackNotifier) GetName() string {
+	return n.name
+}
+
+func (n *SlackNotifier) Accept(alert model.Alert) bool {
+	return Match(n.Config.Filter, alert)
+}
+
+func (n *SlackNotifier) Notify(alert model.Alert) error {
+	fields := []slackField{
+		{Title: "Severity", Value: alert.AlertSeverity, Short: true},
+		{Title: "Code", Value: alert.AlertCode, Short: true},
+		{Title: "Category", Value: alert.AlertCategory, Short: true},
+	}
+
+	if hostname, ok := alert.OtherInfo["hostname"]; ok {
+		fields = append(fields, slackField{Title: "Host", Value: fmt.Sprint(hostname), Short: true})
+	}
+
+	msg := slackMessage{
+		Channel:  n.Config.Channel,
+		Username: n.Config.Username,
+		Text:     Subject(alert),
+		Attachments: []slackAttachment{
+			{
+				Color:  severityColor(alert.AlertSeverity),
+				Text:   alert.Description,
+				Fields: fields,
+				Ts:     alert.Date.Unix(),
+			},
+		},
+	}
+
+	client := newHTTPClient(n.Config.Timeout, false)
+
+	return postJSON(client, n.Config.WebhookURL, msg, n.Config.Retry)
+}
+
+func severityColor(severity string) string {
+	switch severity {
+	case model.AlertSeverityCritical:
+		return "#d32f2f"
+	case model.AlertSeverityWarning:
+		return "#f9a825"
+	default:
+		return "#1976d2"
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package notifier
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const (
+	syslogDefaultFacility = 16 // local0
+	syslogDefaultAppName  = "ercole"
+	// syslogEnterpriseID is the private enterprise number used in the SD-ID of the structured data
+	syslogEnterpriseID = 32473
+)
+
+// SyslogNotifier send the alerts to a syslog receiver using the RFC5424 format.
+// Messages sent over tcp are framed with octet counting as described in RFC6587
+type SyslogNotifier struct {
+	Config config.SyslogNotifier
+	name   string
+}
+
+func (n *SyslogNotifier) GetType() string {
+	return SyslogType
+}
+
+func (n *SyslogNotifier) GetName() string {
+	return n.name
+}
+
+func (n *SyslogNotifier) Accept(alert model.Alert) bool {
+	return Match(n.Config.Filter, alert)
+}
+
+func (n *SyslogNotifier) Notify(alert model.Alert) error {
+	network := n.Config.Network
+	if network == "" {
+		network = "udp"
+	}
+
+	msg := n.format(alert)
+	if strings.HasPrefix(network, "tcp") {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	timeout := defaultHTTPTimeout
+	if n.Config.Timeout > 0 {
+		timeout = time.Duration(n.Config.Timeout) * time.Second
+	}
+
+	return Retry(n.Config.Retry, func() error {
+		conn, err := net.DialTimeout(network, n.Config.Address, timeout)
+		if err != nil {
+			return utils.NewError(err, "SYSLOG")
+		}
+		defer conn.Close()
+
+		if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			return utils.NewError(err, "SYSLOG")
+		}
+
+		if _, err := conn.Write([]byte(msg)); err != nil {
+			return utils.NewError(err, "SYSLOG")
+		}
+
+		return nil
+	})
+}
+
+// format return the alert as RFC5424 message
+func (n *SyslogNotifier) format(alert model.Alert) string {
+	facility := n.Config.Facility
+	if facility == 0 {
+		facility = syslogDefaultFacility
+	}
+
+	appName := n.Config.AppName
+	if appName == "" {
+		appName = syslogDefaultAppName
+	}
+
+	hostname := n.Config.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	sd := fmt.Sprintf("[alert@%d code=\"%s\" category=\"%s\" severity=\"%s\"",
+		syslogEnterpriseID, escapeSDParam(alert.AlertCode), escapeSDParam(alert.AlertCategory), escapeSDParam(alert.AlertSeverity))
+
+	if alertHostname, ok := alert.OtherInfo["hostname"]; ok {
+		sd += fmt.Sprintf(" hostname=\"%s\"", escapeSDParam(fmt.Sprint(alertHostname)))
+	}
+
+	sd += "]"
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		facility*8+syslogSeverity(alert.AlertSeverity),
+		alert.Date.UTC().Format(time.RFC3339Nano),
+		hostname,
+		appName,
+		os.Getpid(),
+		alert.AlertCode,
+		sd,
+		Subject(alert))
+}
+
+func syslogSeverity(severity string) int {
+	switch severity {
+	case model.AlertSeverityCritical:
+		return 2
+	case model.AlertSeverityWarning:
+		return 4
+	default:
+		return 6
+	}
+}
+
+func escapeSDParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+// TeamsNotifier send the alerts to a Microsoft Teams incoming webhook using the MessageCard format
+type TeamsNotifier struct {
+	Config config.TeamsNotifier
+	name   string
+}
+
+type teamsMessageCard struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	ThemeColor string         `json:"themeColor"`
+	Summary    string         `json:"summary"`
+	Title      string         `json:"title"`
+	Sections   []teamsSection `json:"sections"`
+}
+
+type teamsSection struct {
+	Text  string      `json:"text"`
+	Facts []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (n *TeamsNotifier) GetType() string {
+	return TeamsType
+}
+
+func (n *TeamsNotifier) GetName() string {
+	return n.name
+}
+
+func (n *TeamsNotifier) Accept(alert model.Alert) bool {
+	return Match(n.Config.Filter, alert)
+}
+
+func (n *TeamsNotifier) Notify(alert model.Alert) error {
+	facts := []teamsFact{
+		{Name: "Date", Value: formatDate(alert.Date)},
+		{Name: "Severity", Value: alert.AlertSeverity},
+		{Name: "Code", Value: alert.AlertCode},
+		{Name: "Category", Value: alert.AlertCategory},
+	}
+
+	if hostname, ok := alert.OtherInfo["hostname"]; ok {
+		facts = append(facts, teamsFact{Name: "Host", Value: fmt.Sprint(hostname)})
+	}
+
+	card := teamsMessageCard{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: strings.TrimPrefix(severityColor(alert.AlertSeverity), "#"),
+		Summary:    Subject(alert),
+		Title:      Subject(alert),
+		Sections: []teamsSection{
+			{
+				Text:  alert.Description,
+				Facts: facts,
+			},
+		},
+	}
+
+	client := newHTTPClient(n.Config.Timeout, false)
+
+	return postJSON(client, n.Config.WebhookURL, card, n.Config.Retry)
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package notifier
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// WebhookNotifier send the alerts to a generic HTTP endpoint
+type WebhookNotifier struct {
+	Config config.WebhookNotifier
+	name   string
+}
+
+func (n *WebhookNotifier) GetType() string {
+	return WebhookType
+}
+
+func (n *WebhookNotifier) GetName() string {
+	return n.name
+}
+
+func (n *WebhookNotifier) Accept(alert model.Alert) bool {
+	return Match(n.Config.Filter, alert)
+}
+
+func (n *WebhookNotifier) Notify(alert model.Alert) error {
+	body, err := n.body(alert)
+	if err != nil {
+		return err
+	}
+
+	method := n.Config.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	client := newHTTPClient(n.Config.Timeout, n.Config.DisableSSLCertificateValidation)
+
+	return Retry(n.Config.Retry, func() error {
+		req, err := http.NewRequest(method, n.Config.URL, bytes.NewReader(body))
+		if err != nil {
+			return utils.NewError(err, "WEBHOOK")
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		for k, v := range n.Config.Headers {
+			req.Header.Set(k, v)
+		}
+
+		return do(client, req)
+	})
+}
+
+func (n *WebhookNotifier) body(alert model.Alert) ([]byte, error) {
+	if n.Config.BodyTemplate == "" {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			return nil, utils.NewError(err, "WEBHOOK")
+		}
+
+		return body, nil
+	}
+
+	return executeTemplate(n.Config.BodyTemplate, alert)
+}
+
+// executeTemplate render the text/template tmpl using the alert as data.
+// The json function can be used to quote the values, for example {"text": {{json .Description}}}
+func executeTemplate(tmpl string, alert model.Alert) ([]byte, error) {
+	funcs := template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"subject": Subject,
+		"message": Message,
+	}
+
+	t, err := template.New("body").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return nil, utils.NewError(err, "TEMPLATE")
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, alert); err != nil {
+		return nil, utils.NewError(err, "TEMPLATE")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newHTTPClient(timeout int, disableSSLCertificateValidation bool) *http.Client {
+	client := &http.Client{Timeout: defaultHTTPTimeout}
+
+	if timeout > 0 {
+		client.Timeout = time.Duration(timeout) * time.Second
+	}
+
+	if disableSSLCertificateValidation {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	return client
+}
+
+func postJSON(client *http.Client, url string, payload interface{}, policy config.NotifierRetry) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return utils.NewError(err, "NOTIFIER")
+	}
+
+	return Retry(policy, func() error {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return utils.NewError(err, "NOTIFIER")
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		return do(client, req)
+	})
+}
+
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return utils.NewError(err, "NOTIFIER")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return utils.NewError(fmt.Errorf("received status %d: %s", resp.StatusCode, respBody), "NOTIFIER")
+	}
+
+	return nil
+}
@@ -18,34 +18,113 @@ package service
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/ercole-io/ercole/v2/model"
 )
 
-// ThrowNewAlert create and insert in the database a new NEW_DATABASE alert
+// ThrowNewAlert create and insert in the database a new alert.
+// If an identical alert is still open the new one is folded into it, and flapping alerts or
+// alerts covered by a maintenance window are recorded but not notified
 func (as *AlertService) ThrowNewAlert(alert model.Alert) error {
 	alert.ID = primitive.NewObjectIDFromTimestamp(as.TimeNow())
 	alert.AlertStatus = model.AlertStatusNew
 
-	_, err := as.Database.InsertAlert(alert)
+	if alert.Date.IsZero() {
+		alert.Date = as.TimeNow()
+	}
+
+	duplicate, err := as.Database.FindOpenAlertDuplicate(alert)
 	if err != nil {
 		return err
 	}
 
+	if duplicate != nil {
+		return as.Database.FoldAlert(duplicate.ID, alert)
+	}
+
+	if err := as.Database.InsertAlertRaise(alert); err != nil {
+		return err
+	}
+
+	alert.Occurrences = 1
+	alert.LastSeen = &alert.Date
+
+	if alert.Flapping, err = as.isFlapping(alert); err != nil {
+		return err
+	}
+
+	inMaintenance, err := as.isInMaintenance(alert)
+	if err != nil {
+		return err
+	}
+
+	alert.Suppressed = alert.Flapping || inMaintenance
+
+	if _, err := as.Database.InsertAlert(alert); err != nil {
+		return err
+	}
+
+	if alert.Suppressed {
+		if as.Config.AlertService.LogAlertThrows {
+			as.Log.Infof("Alert %s of %s was suppressed (flapping: %t, maintenance: %t)",
+				alert.AlertCode, alert.Hostname(), alert.Flapping, inMaintenance)
+		}
+
+		return nil
+	}
+
 	return as.AlertInsertion(alert)
 }
 
+// isFlapping return true if the alert was raised more than the flapping threshold in the flapping window
+func (as *AlertService) isFlapping(alert model.Alert) (bool, error) {
+	suppression := as.Config.AlertService.Suppression
+	if suppression.FlappingThreshold <= 0 {
+		return false, nil
+	}
+
+	since := alert.Date.Add(-time.Duration(suppression.FlappingWindow) * time.Minute)
+
+	raises, err := as.Database.CountAlertRaises(alert, since)
+	if err != nil {
+		return false, err
+	}
+
+	return raises > int64(suppression.FlappingThreshold), nil
+}
+
+// isInMaintenance return true if the alert is covered by an active maintenance window
+func (as *AlertService) isInMaintenance(alert model.Alert) (bool, error) {
+	windows, err := as.Database.FindActiveMaintenanceWindows(as.TimeNow())
+	if err != nil {
+		return false, err
+	}
+
+	if len(windows) == 0 {
+		return false, nil
+	}
+
+	host := as.alertHost(alert)
+
+	for _, window := range windows {
+		if window.Covers(alert, host) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // ThrowNewDatabaseAlert create and insert in the database a new NEW_DATABASE alert
 func (as *AlertService) ThrowNewDatabaseAlert(dbname string, hostname string) error {
 	alr := model.Alert{
-		ID:                      primitive.NewObjectIDFromTimestamp(as.TimeNow()),
 		AlertAffectedTechnology: model.TechnologyOracleDatabasePtr,
 		AlertCategory:           model.AlertCategoryLicense,
 		AlertCode:               model.AlertCodeNewDatabase,
 		AlertSeverity:           model.AlertSeverityInfo,
-		AlertStatus:             model.AlertStatusNew,
 		Date:                    as.TimeNow(),
 		Description:             fmt.Sprintf("The database '%s' was created on the server %s", dbname, hostname),
 		OtherInfo: map[string]interface{}{
@@ -54,8 +133,7 @@ func (as *AlertService) ThrowNewDatabaseAlert(dbname string, hostname string) er
 		},
 	}
 
-	_, err := as.Database.InsertAlert(alr)
-	if err != nil {
+	if err := as.ThrowNewAlert(alr); err != nil {
 		return err
 	}
 
@@ -63,19 +141,16 @@ func (as *AlertService) ThrowNewDatabaseAlert(dbname string, hostname string) er
 		as.Log.Warnf("Alert NEW_DATABASE of %s/%s was thrown\n", hostname, dbname)
 	}
 
-	//Schedule the email notification
-	return as.AlertInsertion(alr)
+	return nil
 }
 
 // ThrowNewServerAlert create and insert in the database a new NEW_SERVER alert
 func (as *AlertService) ThrowNewServerAlert(hostname string) error {
 	alr := model.Alert{
-		ID:                      primitive.NewObjectIDFromTimestamp(as.TimeNow()),
 		AlertAffectedTechnology: nil,
 		AlertCategory:           model.AlertCategoryEngine,
 		AlertCode:               model.AlertCodeNewServer,
 		AlertSeverity:           model.AlertSeverityInfo,
-		AlertStatus:             model.AlertStatusNew,
 		Date:                    as.TimeNow(),
 		Description:             fmt.Sprintf("The server '%s' was added to ercole", hostname),
 		OtherInfo: map[string]interface{}{
@@ -83,8 +158,7 @@ func (as *AlertService) ThrowNewServerAlert(hostname string) error {
 		},
 	}
 
-	_, err := as.Database.InsertAlert(alr)
-	if err != nil {
+	if err := as.ThrowNewAlert(alr); err != nil {
 		return err
 	}
 
@@ -92,19 +166,16 @@ func (as *AlertService) ThrowNewServerAlert(hostname string) error {
 		as.Log.Warnf("Alert NEW_SERVER of %s was thrown\n", hostname)
 	}
 
-	//Schedule the email notification
-	return as.AlertInsertion(alr)
+	return nil
 }
 
 // ThrowNewEnterpriseLicenseAlert create and insert in the database a new NEW_DATABASE alert
 func (as *AlertService) ThrowNewEnterpriseLicenseAlert(hostname string) error {
 	alr := model.Alert{
-		ID:                      primitive.NewObjectIDFromTimestamp(as.TimeNow()),
 		AlertAffectedTechnology: model.TechnologyOracleDatabasePtr,
 		AlertCategory:           model.AlertCategoryLicense,
 		AlertCode:               model.AlertCodeNewLicense,
 		AlertSeverity:           model.AlertSeverityCritical,
-		AlertStatus:             model.AlertStatusNew,
 		Date:                    as.TimeNow(),
 		Description:             fmt.Sprintf("A new Enterprise license has been enabled to %s", hostname),
 		OtherInfo: map[string]interface{}{
@@ -112,8 +183,7 @@ func (as *AlertService) ThrowNewEnterpriseLicenseAlert(hostname string) error {
 		},
 	}
 
-	_, err := as.Database.InsertAlert(alr)
-	if err != nil {
+	if err := as.ThrowNewAlert(alr); err != nil {
 		return err
 	}
 
@@ -121,19 +191,16 @@ func (as *AlertService) ThrowNewEnterpriseLicenseAlert(hostname string) error {
 		as.Log.Warnf("Alert NEW_LICENSE of %s was thrown\n", hostname)
 	}
 
-	//Schedule the email notification
-	return as.AlertInsertion(alr)
+	return nil
 }
 
 // ThrowActivatedFeaturesAlert create and insert in the database a new NEW_OPTION alert
 func (as *AlertService) ThrowActivatedFeaturesAlert(dbname string, hostname string, activatedFeatures []string) error {
 	alr := model.Alert{
-		ID:                      primitive.NewObjectIDFromTimestamp(as.TimeNow()),
 		AlertAffectedTechnology: model.TechnologyOracleDatabasePtr,
 		AlertCategory:           model.AlertCategoryLicense,
 		AlertCode:               model.AlertCodeNewOption,
 		AlertSeverity:           model.AlertSeverityCritical,
-		AlertStatus:             model.AlertStatusNew,
 		Date:                    as.TimeNow(),
 		Description:             fmt.Sprintf("The database %s on %s has enabled new features (%s) on server", dbname, hostname, strings.Join(activatedFeatures, ", ")),
 		OtherInfo: map[string]interface{}{
@@ -143,8 +210,7 @@ func (as *AlertService) ThrowActivatedFeaturesAlert(dbname string, hostname stri
 		},
 	}
 
-	_, err := as.Database.InsertAlert(alr)
-	if err != nil {
+	if err := as.ThrowNewAlert(alr); err != nil {
 		return err
 	}
 
@@ -152,19 +218,16 @@ func (as *AlertService) ThrowActivatedFeaturesAlert(dbname string, hostname stri
 		as.Log.Warnf("Alert NEW_OPTIONS of %s/%s was thrown\n", hostname, dbname)
 	}
 
-	//Schedule the email notification
-	return as.AlertInsertion(alr)
+	return nil
 }
 
 // ThrowNoDataAlert create and insert in the database a new NO_DATA alert
 func (as *AlertService) ThrowNoDataAlert(hostname string, freshnessThreshold int) error {
 	alr := model.Alert{
-		ID:                      primitive.NewObjectIDFromTimestamp(as.TimeNow()),
 		AlertAffectedTechnology: nil,
 		AlertCategory:           model.AlertCategoryAgent,
 		AlertCode:               model.AlertCodeNoData,
 		AlertSeverity:           model.AlertSeverityCritical,
-		AlertStatus:             model.AlertStatusNew,
 		Date:                    as.TimeNow(),
 		Description:             fmt.Sprintf("No data received from the host %s in the last %d day(s)", hostname, freshnessThreshold),
 		OtherInfo: map[string]interface{}{
@@ -172,8 +235,7 @@ func (as *AlertService) ThrowNoDataAlert(hostname string, freshnessThreshold int
 		},
 	}
 
-	_, err := as.Database.InsertAlert(alr)
-	if err != nil {
+	if err := as.ThrowNewAlert(alr); err != nil {
 		return err
 	}
 
@@ -181,19 +243,16 @@ func (as *AlertService) ThrowNoDataAlert(hostname string, freshnessThreshold int
 		as.Log.Infof("Alert NO_DATA of %s was thrown\n", hostname)
 	}
 
-	//Schedule the email notification
-	return as.AlertInsertion(alr)
+	return nil
 }
 
 // ThrowUnlistedRunningDatabasesAlert create and insert in the database a new UNLISTED_RUNNING_DATABASE alert
 func (as *AlertService) ThrowUnlistedRunningDatabasesAlert(dbname string, hostname string) error {
 	alr := model.Alert{
-		ID:                      primitive.NewObjectIDFromTimestamp(as.TimeNow()),
 		AlertAffectedTechnology: model.TechnologyOracleDatabasePtr,
 		AlertCategory:           model.AlertCategoryEngine,
 		AlertCode:               model.AlertCodeUnlistedRunningDatabase,
 		AlertSeverity:           model.AlertSeverityWarning,
-		AlertStatus:             model.AlertStatusNew,
 		Date:                    as.TimeNow(),
 		Description:             fmt.Sprintf("The database %s is not listed in the oratab of the host %s", dbname, hostname),
 		OtherInfo: map[string]interface{}{
@@ -202,8 +261,7 @@ func (as *AlertService) ThrowUnlistedRunningDatabasesAlert(dbname string, hostna
 		},
 	}
 
-	_, err := as.Database.InsertAlert(alr)
-	if err != nil {
+	if err := as.ThrowNewAlert(alr); err != nil {
 		return err
 	}
 
@@ -211,6 +269,5 @@ func (as *AlertService) ThrowUnlistedRunningDatabasesAlert(dbname string, hostna
 		as.Log.Warnf("Alert UNLISTED_RUNNING_DATABASE of %s/%s was thrown\n", hostname, dbname)
 	}
 
-	//Schedule the email notification
-	return as.AlertInsertion(alr)
+	return nil
 }
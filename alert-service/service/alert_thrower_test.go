@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/mock/gomock"
 
 	"github.com/ercole-io/ercole/v2/config"
@@ -50,10 +51,141 @@ func TestThrowNewAlert(t *testing.T) {
 
 	alert := model.Alert{}
 
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {}).Times(1)
 
 	require.NoError(t, as.ThrowNewAlert(alert))
 }
+
+func TestThrowNewAlert_FoldDuplicate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := AlertService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Queue:    hub.New(),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	alert := model.Alert{
+		AlertCode:   model.AlertCodeNoData,
+		Description: "No data received from the host myhost in the last 2 day(s)",
+		Date:        utils.P("2019-11-05T14:00:00Z"),
+		OtherInfo:   map[string]interface{}{"hostname": "myhost"},
+	}
+	duplicate := model.Alert{ID: utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"), AlertCode: model.AlertCodeNoData}
+
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(&duplicate, nil).Times(1)
+	db.EXPECT().FoldAlert(duplicate.ID, gomock.Any()).
+		Do(func(id primitive.ObjectID, folded model.Alert) {
+			assert.Equal(t, alert.Date, folded.Date)
+			assert.Equal(t, alert.Description, folded.Description)
+			assert.Equal(t, alert.OtherInfo, folded.OtherInfo)
+		}).Return(nil).Times(1)
+
+	require.NoError(t, as.ThrowNewAlert(alert))
+}
+
+func TestThrowNewAlert_Flapping(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := AlertService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Queue:    hub.New(),
+		Log:      logger.NewLogger("TEST"),
+		Config: config.Configuration{
+			AlertService: config.AlertService{
+				Suppression: config.AlertSuppression{FlappingWindow: 60, FlappingThreshold: 3},
+			},
+		},
+	}
+
+	alert := model.Alert{
+		AlertCode: model.AlertCodeNoData,
+		Date:      utils.P("2019-11-05T14:00:00Z"),
+		OtherInfo: map[string]interface{}{"hostname": "myhost"},
+	}
+
+	t.Run("Not flapping", func(t *testing.T) {
+		db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+		db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+		db.EXPECT().CountAlertRaises(gomock.Any(), utils.P("2019-11-05T13:00:00Z")).Return(int64(3), nil).Times(1)
+		db.EXPECT().FindActiveMaintenanceWindows(gomock.Any()).Return(nil, nil).Times(1)
+		db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
+			assert.False(t, alert.Flapping)
+			assert.False(t, alert.Suppressed)
+			assert.Equal(t, 1, alert.Occurrences)
+		}).Times(1)
+
+		require.NoError(t, as.ThrowNewAlert(alert))
+	})
+
+	t.Run("Flapping", func(t *testing.T) {
+		db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+		db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+		db.EXPECT().CountAlertRaises(gomock.Any(), utils.P("2019-11-05T13:00:00Z")).Return(int64(4), nil).Times(1)
+		db.EXPECT().FindActiveMaintenanceWindows(gomock.Any()).Return(nil, nil).Times(1)
+		db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
+			assert.True(t, alert.Flapping)
+			assert.True(t, alert.Suppressed)
+		}).Times(1)
+
+		require.NoError(t, as.ThrowNewAlert(alert))
+	})
+}
+
+func TestThrowNewAlert_MaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := AlertService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Queue:    hub.New(),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	alert := model.Alert{
+		AlertCode: model.AlertCodeAgentError,
+		Date:      utils.P("2019-11-05T14:00:00Z"),
+		OtherInfo: map[string]interface{}{"hostname": "myhost"},
+	}
+	windows := []model.MaintenanceWindow{
+		{Name: "datacenter move", Locations: []string{"Italy"}},
+	}
+
+	t.Run("Covered", func(t *testing.T) {
+		db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+		db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+		db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return(windows, nil).Times(1)
+		db.EXPECT().FindCurrentHostData("myhost").Return(model.HostDataBE{Hostname: "myhost", Location: "Italy"}, nil).Times(1)
+		db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
+			assert.True(t, alert.Suppressed)
+		}).Times(1)
+
+		require.NoError(t, as.ThrowNewAlert(alert))
+	})
+
+	t.Run("Not covered", func(t *testing.T) {
+		db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+		db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+		db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return(windows, nil).Times(1)
+		db.EXPECT().FindCurrentHostData("myhost").Return(model.HostDataBE{Hostname: "myhost", Location: "Germany"}, nil).Times(1)
+		db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
+			assert.False(t, alert.Suppressed)
+		}).Times(1)
+
+		require.NoError(t, as.ThrowNewAlert(alert))
+	})
+}
 func TestThrowNewDatabaseAlert_Success(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -70,6 +202,9 @@ func TestThrowNewDatabaseAlert_Success(t *testing.T) {
 		},
 	}
 
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
 		assert.Equal(t, model.AlertCategoryLicense, alert.AlertCategory)
 		assert.Equal(t, model.TechnologyOracleDatabase, *alert.AlertAffectedTechnology)
@@ -96,6 +231,9 @@ func TestThrowNewDatabaseAlert_DatabaseError(t *testing.T) {
 		Database: db,
 		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, aerrMock).Times(1)
 	assert.Equal(t, aerrMock, as.ThrowNewDatabaseAlert("bestdb", "myhost"))
 }
@@ -118,6 +256,9 @@ func TestThrowNewServerAlert_Success(t *testing.T) {
 		},
 	}
 
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
 		assert.Equal(t, model.AlertCategoryEngine, alert.AlertCategory)
 		assert.Nil(t, alert.AlertAffectedTechnology)
@@ -143,6 +284,9 @@ func TestThrowNewServerAlert_DatabaseError(t *testing.T) {
 		Database: db,
 		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, aerrMock).Times(1)
 	assert.Equal(t, aerrMock, as.ThrowNewServerAlert("myhost"))
 }
@@ -163,6 +307,9 @@ func TestThrowNewEnterpriseLicenseAlert_Success(t *testing.T) {
 				LogAlertThrows: true},
 		},
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
 		assert.Equal(t, model.AlertCategoryLicense, alert.AlertCategory)
 		assert.Equal(t, model.TechnologyOracleDatabase, *alert.AlertAffectedTechnology)
@@ -187,6 +334,9 @@ func TestThrowNewEnterpriseLicenseAlert_DatabaseError(t *testing.T) {
 		Database: db,
 		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, aerrMock).Times(1)
 	assert.Equal(t, aerrMock, as.ThrowNewEnterpriseLicenseAlert("myhost"))
 }
@@ -207,6 +357,9 @@ func TestThrowActivatedFeaturesAlert_Success(t *testing.T) {
 				LogAlertThrows: true},
 		},
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
 		assert.Equal(t, model.AlertCategoryLicense, alert.AlertCategory)
 		assert.Equal(t, model.TechnologyOracleDatabase, *alert.AlertAffectedTechnology)
@@ -233,6 +386,9 @@ func TestThrowActivatedFeaturesAlert_DatabaseError(t *testing.T) {
 		Database: db,
 		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, aerrMock).Times(1)
 	assert.Equal(t, aerrMock, as.ThrowActivatedFeaturesAlert("mydb", "myhost", []string{"fastibility", "slowibility"}))
 }
@@ -253,6 +409,9 @@ func TestThrowNoDataAlert_Success(t *testing.T) {
 				LogAlertThrows: true},
 		},
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
 		assert.Equal(t, model.AlertCategoryAgent, alert.AlertCategory)
 		assert.Nil(t, alert.AlertAffectedTechnology)
@@ -277,6 +436,9 @@ func TestThrowNoDataAlert_DatabaseError(t *testing.T) {
 		Database: db,
 		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, aerrMock).Times(1)
 	assert.Equal(t, aerrMock, as.ThrowNoDataAlert("myhost", 90))
 }
@@ -295,6 +457,9 @@ func TestThrowUnlistedRunningDatabasesAlert_Success(t *testing.T) {
 				LogAlertThrows: true},
 		},
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
 		assert.Equal(t, model.AlertCategoryEngine, alert.AlertCategory)
 		assert.Equal(t, model.TechnologyOracleDatabase, *alert.AlertAffectedTechnology)
@@ -320,6 +485,9 @@ func TestThrowUnlistedRunningDatabasesAlert_DatabaseError(t *testing.T) {
 		Database: db,
 		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
 	}
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(1)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(1)
+	db.EXPECT().FindActiveMaintenanceWindows(utils.P("2019-11-05T14:02:03Z")).Return([]model.MaintenanceWindow{}, nil).Times(1)
 	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, aerrMock).Times(1)
 	assert.Equal(t, aerrMock, as.ThrowUnlistedRunningDatabasesAlert("mydb", "myhost"))
 }
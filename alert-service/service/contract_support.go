@@ -0,0 +1,116 @@
+// Copyright (c) 2020 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+// ThrowContractSupportAlerts create and insert in the database the CONTRACT_SUPPORT_EXPIRING
+// and CONTRACT_SUPPORT_EXPIRED alerts of the contracts whose support is near to expire or expired.
+// Every contract raise at most one alert for each lead time and one when the support expires
+func (as *AlertService) ThrowContractSupportAlerts() error {
+	contracts, err := as.Database.FindContractsSupport()
+	if err != nil {
+		return err
+	}
+
+	leadTimes := make([]int, 0, len(as.Config.AlertService.ContractSupportAlertJob.LeadTimes))
+	for _, leadTime := range as.Config.AlertService.ContractSupportAlertJob.LeadTimes {
+		if leadTime > 0 {
+			leadTimes = append(leadTimes, leadTime)
+		}
+	}
+
+	sort.Ints(leadTimes)
+
+	now := as.TimeNow()
+
+	for _, contract := range contracts {
+		alert, ok := contractSupportAlert(contract, now, leadTimes)
+		if !ok {
+			continue
+		}
+
+		exist, err := as.Database.ExistAlert(alert)
+		if err != nil {
+			return err
+		}
+
+		if exist {
+			continue
+		}
+
+		if err := as.ThrowNewAlert(alert); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// contractSupportAlert return the alert that must be raised for the contract, if any.
+// leadTimes must be sorted in ascending order
+func contractSupportAlert(contract model.ContractSupport, now time.Time, leadTimes []int) (model.Alert, bool) {
+	technology := contract.Technology
+
+	alert := model.Alert{
+		AlertAffectedTechnology: &technology,
+		AlertCategory:           model.AlertCategoryLicense,
+		Date:                    now,
+		OtherInfo: map[string]interface{}{
+			"contractID":        contract.ID.Hex(),
+			"contractNumber":    contract.ContractID,
+			"technology":        contract.Technology,
+			"location":          contract.Location,
+			"supportExpiration": contract.SupportExpiration,
+		},
+	}
+
+	daysLeft := contract.DaysLeft(now)
+
+	if daysLeft <= 0 {
+		alert.AlertCode = model.AlertCodeContractSupportExpired
+		alert.AlertSeverity = model.AlertSeverityCritical
+		alert.Description = fmt.Sprintf("The support of the %s contract %s expired on %s",
+			contract.Technology, contract.ContractID, contract.SupportExpiration.Format("2006-01-02"))
+		alert.OtherInfo["leadTime"] = 0
+
+		return alert, true
+	}
+
+	leadTime, ok := contract.SupportLeadTime(now, leadTimes)
+	if !ok {
+		return model.Alert{}, false
+	}
+
+	alert.AlertCode = model.AlertCodeContractSupportExpiring
+	alert.AlertSeverity = model.AlertSeverityWarning
+
+	if leadTime == leadTimes[0] {
+		alert.AlertSeverity = model.AlertSeverityCritical
+	}
+
+	alert.Description = fmt.Sprintf("The support of the %s contract %s expires in %d day(s), on %s",
+		contract.Technology, contract.ContractID, daysLeft, contract.SupportExpiration.Format("2006-01-02"))
+	alert.OtherInfo["leadTime"] = leadTime
+
+	return alert, true
+}
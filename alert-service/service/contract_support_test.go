@@ -0,0 +1,142 @@
+// Copyright (c) 2020 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestThrowContractSupportAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := AlertService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2024-03-01T10:00:00Z")),
+		Queue:    hub.New(),
+		Log:      logger.NewLogger("TEST"),
+		Config: config.Configuration{
+			AlertService: config.AlertService{
+				ContractSupportAlertJob: config.ContractSupportAlertJob{LeadTimes: []int{90, 30, 7}},
+			},
+		},
+	}
+
+	contracts := []model.ContractSupport{
+		{
+			ID:                utils.Str2oid("5dd40bfb12f54dfda7b1c291"),
+			Technology:        model.TechnologyOracleDatabase,
+			ContractID:        "AID001",
+			SupportExpiration: utils.P("2024-12-31T00:00:00Z"),
+		},
+		{
+			ID:                utils.Str2oid("5dd40bfb12f54dfda7b1c292"),
+			Technology:        model.TechnologyOracleMySQL,
+			ContractID:        "MYSQL001",
+			Location:          "Italy",
+			SupportExpiration: utils.P("2024-03-20T00:00:00Z"),
+		},
+		{
+			ID:                utils.Str2oid("5dd40bfb12f54dfda7b1c293"),
+			Technology:        model.TechnologyMicrosoftSQLServer,
+			ContractID:        "MSSQL001",
+			SupportExpiration: utils.P("2024-02-01T00:00:00Z"),
+		},
+		{
+			ID:                utils.Str2oid("5dd40bfb12f54dfda7b1c294"),
+			Technology:        model.TechnologyOracleDatabase,
+			ContractID:        "AID002",
+			SupportExpiration: utils.P("2024-03-05T00:00:00Z"),
+		},
+	}
+
+	inserted := make([]model.Alert, 0)
+
+	db.EXPECT().FindContractsSupport().Return(contracts, nil)
+	db.EXPECT().ExistAlert(gomock.Any()).Return(false, nil).Times(2)
+	db.EXPECT().ExistAlert(gomock.Any()).Return(true, nil)
+	db.EXPECT().FindOpenAlertDuplicate(gomock.Any()).Return(nil, nil).Times(2)
+	db.EXPECT().InsertAlertRaise(gomock.Any()).Return(nil).Times(2)
+	db.EXPECT().FindActiveMaintenanceWindows(gomock.Any()).Return(nil, nil).Times(2)
+	db.EXPECT().InsertAlert(gomock.Any()).Return(nil, nil).Do(func(alert model.Alert) {
+		inserted = append(inserted, alert)
+	}).Times(2)
+
+	require.NoError(t, as.ThrowContractSupportAlerts())
+	require.Len(t, inserted, 2)
+
+	assert.Equal(t, model.AlertCodeContractSupportExpiring, inserted[0].AlertCode)
+	assert.Equal(t, model.AlertSeverityWarning, inserted[0].AlertSeverity)
+	assert.Equal(t, model.AlertCategoryLicense, inserted[0].AlertCategory)
+	assert.Equal(t, model.TechnologyOracleMySQL, *inserted[0].AlertAffectedTechnology)
+	assert.Equal(t, "The support of the Oracle/MySQL contract MYSQL001 expires in 19 day(s), on 2024-03-20", inserted[0].Description)
+	assert.Equal(t, "5dd40bfb12f54dfda7b1c292", inserted[0].OtherInfo["contractID"])
+	assert.Equal(t, "Italy", inserted[0].OtherInfo["location"])
+	assert.Equal(t, 30, inserted[0].OtherInfo["leadTime"])
+
+	assert.Equal(t, model.AlertCodeContractSupportExpired, inserted[1].AlertCode)
+	assert.Equal(t, model.AlertSeverityCritical, inserted[1].AlertSeverity)
+	assert.Equal(t, "The support of the Microsoft/SQLServer contract MSSQL001 expired on 2024-02-01", inserted[1].Description)
+	assert.Equal(t, 0, inserted[1].OtherInfo["leadTime"])
+}
+
+func TestThrowContractSupportAlerts_DatabaseError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := AlertService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2024-03-01T10:00:00Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().FindContractsSupport().Return(nil, errors.New("test error"))
+
+	require.Error(t, as.ThrowContractSupportAlerts())
+}
+
+func TestContractSupportAlert(t *testing.T) {
+	now := utils.P("2024-03-01T10:00:00Z")
+	leadTimes := []int{7, 30, 90}
+	contract := model.ContractSupport{
+		ID:                utils.Str2oid("5dd40bfb12f54dfda7b1c291"),
+		Technology:        model.TechnologyOracleDatabase,
+		ContractID:        "AID001",
+		SupportExpiration: utils.P("2024-03-05T00:00:00Z"),
+	}
+
+	alert, ok := contractSupportAlert(contract, now, leadTimes)
+	require.True(t, ok)
+	assert.Equal(t, model.AlertCodeContractSupportExpiring, alert.AlertCode)
+	assert.Equal(t, model.AlertSeverityCritical, alert.AlertSeverity)
+	assert.Equal(t, 7, alert.OtherInfo["leadTime"])
+
+	_, ok = contractSupportAlert(contract, now, []int{})
+	assert.False(t, ok)
+}
@@ -18,12 +18,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/ercole-io/ercole/v2/alert-service/database"
 	"github.com/ercole-io/ercole/v2/alert-service/emailer"
+	"github.com/ercole-io/ercole/v2/alert-service/notifier"
 	"github.com/ercole-io/ercole/v2/logger"
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
@@ -50,6 +52,8 @@ type AlertServiceInterface interface {
 	ThrowActivatedFeaturesAlert(dbname string, hostname string, activatedFeatures []string) error
 	// ThrowNoDataAlert create and insert in the database a new NO_DATA alert
 	ThrowNoDataAlert(hostname string, freshnessThreshold int) error
+	// ThrowContractSupportAlerts create and insert in the database the alerts about the contracts whose support is expiring or expired
+	ThrowContractSupportAlerts() error
 }
 
 // AlertService is the concrete implementation of HostDataServiceInterface. It saves data to a MongoDB database
@@ -66,6 +70,8 @@ type AlertService struct {
 	Log logger.Logger
 	// Emailer contains the emailer layer
 	Emailer emailer.Emailer
+	// Notifiers contains the notification channels other than the emailer
+	Notifiers []notifier.Notifier
 }
 
 // Init initializes the service and database
@@ -126,89 +132,125 @@ func (as *AlertService) ProcessMsg(msg hub.Message) {
 func (as *AlertService) ProcessAlertInsertion(params hub.Fields) {
 	alert := params["alert"].(model.Alert)
 
-	// check if alert severity is enabled
-	if alert.AlertSeverity == model.AlertSeverityWarning && !as.Config.AlertService.Emailer.AlertSeverity.Warning {
-		return
+	rules, err := as.Database.FindAlertRoutingRules()
+	if err != nil {
+		as.Log.Error(err)
 	}
 
-	to := as.Config.AlertService.Emailer.To
+	if len(rules) == 0 {
+		as.notify(alert, as.Notifiers)
+
+		if to, ok := as.directiveRecipients(alert); ok {
+			as.sendEmail(alert, to)
+		}
 
-	// check if alert notification is enabled
-	if alert.IsCode(model.AlertCodeNewServer) && !as.Config.AlertService.Emailer.AlertType.NewHost.Enable {
 		return
 	}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.NewHost.To...)
+	route := model.RouteAlert(rules, alert, as.alertHost(alert))
 
-	if alert.IsCode(model.AlertCodeNewDatabase) && !as.Config.AlertService.Emailer.AlertType.NewDatabase.Enable {
-		return
+	notifiers := make([]notifier.Notifier, 0, len(route.Channels))
+
+	for _, n := range as.Notifiers {
+		if utils.Contains(route.Channels, n.GetName()) {
+			notifiers = append(notifiers, n)
+		}
 	}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.NewDatabase.To...)
+	as.notify(alert, notifiers)
 
-	if alert.IsCode(model.AlertCodeNewLicense) && !as.Config.AlertService.Emailer.AlertType.NewLicense.Enable {
-		return
+	if len(route.Recipients) > 0 {
+		as.sendEmail(alert, route.Recipients)
 	}
+}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.NewLicense.To...)
+// directiveRecipients return the recipients of the alert according to the AlertType directives,
+// used when no routing rule is stored. It return false if the alert mustn't be sent by email
+func (as *AlertService) directiveRecipients(alert model.Alert) ([]string, bool) {
+	emailer := as.Config.AlertService.Emailer
 
-	if alert.IsCode(model.AlertCodeNewOption) && !as.Config.AlertService.Emailer.AlertType.NewOption.Enable {
-		return
+	// check if alert severity is enabled
+	if alert.AlertSeverity == model.AlertSeverityWarning && !emailer.AlertSeverity.Warning {
+		return nil, false
 	}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.NewOption.To...)
-
-	if alert.IsCode(model.AlertCodeUnlistedRunningDatabase) && !as.Config.AlertService.Emailer.AlertType.NewUnlistedRunningDatabase.Enable {
-		return
+	directives := map[string]config.Directive{
+		model.AlertCodeNewServer:               emailer.AlertType.NewHost,
+		model.AlertCodeNewDatabase:             emailer.AlertType.NewDatabase,
+		model.AlertCodeNewLicense:              emailer.AlertType.NewLicense,
+		model.AlertCodeNewOption:               emailer.AlertType.NewOption,
+		model.AlertCodeUnlistedRunningDatabase: emailer.AlertType.NewUnlistedRunningDatabase,
+		model.AlertCodeIncreasedCPUCores:       emailer.AlertType.NewHostCpu,
+		model.AlertCodeMissingPrimaryDatabase:  emailer.AlertType.MissingPrimaryDatabase,
+		model.AlertCodeMissingDatabase:         emailer.AlertType.MissingDatabase,
+		model.AlertCodeAgentError:              emailer.AlertType.AgentError,
+		model.AlertCodeNoData:                  emailer.AlertType.NoData,
+		model.AlertCodeLicenseNonCompliant:     emailer.AlertType.LicenseNonCompliant,
+		model.AlertCodeContractSupportExpiring: emailer.AlertType.ContractSupportExpiring,
+		model.AlertCodeContractSupportExpired:  emailer.AlertType.ContractSupportExpired,
+		model.AlertCodeClusterTopologyMismatch: emailer.AlertType.ClusterTopologyMismatch,
 	}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.NewUnlistedRunningDatabase.To...)
+	to := make([]string, 0, len(emailer.To))
+	to = append(to, emailer.To...)
 
-	if alert.IsCode(model.AlertCodeIncreasedCPUCores) && !as.Config.AlertService.Emailer.AlertType.NewHostCpu.Enable {
-		return
+	if directive, ok := directives[alert.AlertCode]; ok {
+		if !directive.Enable {
+			return nil, false
+		}
+
+		to = append(to, directive.To...)
 	}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.NewHostCpu.To...)
+	return to, true
+}
 
-	if alert.IsCode(model.AlertCodeMissingPrimaryDatabase) && !as.Config.AlertService.Emailer.AlertType.MissingPrimaryDatabase.Enable {
-		return
+// alertHost return the current hostdata of the host of the alert, if any
+func (as *AlertService) alertHost(alert model.Alert) *model.HostDataBE {
+	hostname, ok := alert.OtherInfo["hostname"]
+	if !ok {
+		return nil
 	}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.MissingPrimaryDatabase.To...)
+	host, err := as.Database.FindCurrentHostData(fmt.Sprint(hostname))
+	if err != nil {
+		if !errors.Is(err, utils.ErrHostNotFound) {
+			as.Log.Error(err)
+		}
 
-	if alert.IsCode(model.AlertCodeMissingDatabase) && !as.Config.AlertService.Emailer.AlertType.MissingDatabase.Enable {
-		return
+		return nil
 	}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.MissingDatabase.To...)
+	return &host
+}
 
-	if alert.IsCode(model.AlertCodeAgentError) && !as.Config.AlertService.Emailer.AlertType.AgentError.Enable {
-		return
+func (as *AlertService) sendEmail(alert model.Alert, to []string) {
+	err := notifier.Retry(as.Config.AlertService.Emailer.Retry, func() error {
+		return as.Emailer.SendEmail(notifier.Subject(alert), notifier.Message(alert), to)
+	})
+	if err != nil {
+		as.Log.Error(err)
 	}
+}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.AgentError.To...)
+func (as *AlertService) notify(alert model.Alert, notifiers []notifier.Notifier) {
+	var wg sync.WaitGroup
 
-	if alert.IsCode(model.AlertCodeNoData) && !as.Config.AlertService.Emailer.AlertType.NoData.Enable {
-		return
-	}
+	for _, n := range notifiers {
+		if !n.Accept(alert) {
+			continue
+		}
 
-	to = append(to, as.Config.AlertService.Emailer.AlertType.NoData.To...)
+		wg.Add(1)
 
-	//Create the subject and message
-	var subject, message string
+		go func(n notifier.Notifier) {
+			defer wg.Done()
 
-	if val, ok := alert.OtherInfo["hostname"]; ok {
-		subject = fmt.Sprintf("%s %s on %s", alert.AlertSeverity, alert.Description, val)
-		message = fmt.Sprintf("Date: %s\nSeverity: %s\nHost: %s\nCode: %s\n%s", alert.Date, alert.AlertSeverity, val, alert.AlertCode, alert.Description)
-	} else {
-		subject = fmt.Sprintf("%s %s", alert.AlertSeverity, alert.Description)
-		message = fmt.Sprintf("Date: %s\nSeverity: %s\nCode: %s\n%s", alert.Date, alert.AlertSeverity, alert.AlertCode, alert.Description)
+			if err := n.Notify(alert); err != nil {
+				as.Log.Errorf("can't notify alert %s to %s %q: %s", alert.AlertCode, n.GetType(), n.GetName(), err)
+			}
+		}(n)
 	}
 
-	// Send the email
-	err := as.Emailer.SendEmail(subject, message, to)
-	if err != nil {
-		as.Log.Error(err)
-		return
-	}
+	wg.Wait()
 }
@@ -20,8 +20,10 @@ import (
 	"testing"
 
 	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
+	"github.com/ercole-io/ercole/v2/alert-service/notifier"
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
 	"github.com/ercole-io/ercole/v2/model"
@@ -33,12 +35,15 @@ func TestProcessMsg_AlertInsertion(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	emailer := NewMockEmailer(mockCtrl)
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	db.EXPECT().FindAlertRoutingRules().Return([]model.AlertRoutingRule{}, nil)
 
 	as := AlertService{
-		Emailer: emailer,
-		TimeNow: utils.Btc(utils.P("2019-11-05T16:02:03Z")),
-		Log:     logger.NewLogger("TEST"),
-		Queue:   hub.New(),
+		Emailer:  emailer,
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T16:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+		Queue:    hub.New(),
 		Config: config.Configuration{
 			AlertService: config.AlertService{
 				Emailer: config.Emailer{
@@ -108,12 +113,15 @@ func TestProcessAlertInsertion_WithHostname(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	emailer := NewMockEmailer(mockCtrl)
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	db.EXPECT().FindAlertRoutingRules().Return([]model.AlertRoutingRule{}, nil)
 
 	as := AlertService{
-		Emailer: emailer,
-		TimeNow: utils.Btc(utils.P("2019-11-05T16:02:03Z")),
-		Log:     logger.NewLogger("TEST"),
-		Queue:   hub.New(),
+		Emailer:  emailer,
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T16:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+		Queue:    hub.New(),
 		Config: config.Configuration{
 			AlertService: config.AlertService{
 				Emailer: config.Emailer{
@@ -164,12 +172,15 @@ func TestProcessAlertInsertion_WithoutHostname(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	emailer := NewMockEmailer(mockCtrl)
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	db.EXPECT().FindAlertRoutingRules().Return([]model.AlertRoutingRule{}, nil)
 
 	as := AlertService{
-		Emailer: emailer,
-		TimeNow: utils.Btc(utils.P("2019-11-05T16:02:03Z")),
-		Log:     logger.NewLogger("TEST"),
-		Queue:   hub.New(),
+		Emailer:  emailer,
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T16:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+		Queue:    hub.New(),
 		Config: config.Configuration{
 			AlertService: config.AlertService{
 				Emailer: config.Emailer{
@@ -219,12 +230,15 @@ func TestProcessAlertInsertion_EmailerError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	emailer := NewMockEmailer(mockCtrl)
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	db.EXPECT().FindAlertRoutingRules().Return([]model.AlertRoutingRule{}, nil)
 
 	as := AlertService{
-		Emailer: emailer,
-		TimeNow: utils.Btc(utils.P("2019-11-05T16:02:03Z")),
-		Log:     logger.NewLogger("TEST"),
-		Queue:   hub.New(),
+		Emailer:  emailer,
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T16:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+		Queue:    hub.New(),
 		Config: config.Configuration{
 			AlertService: config.AlertService{
 				Emailer: config.Emailer{
@@ -269,3 +283,206 @@ This is just an alert test to a mocked emailer.`,
 
 	as.ProcessAlertInsertion(params)
 }
+
+type fakeNotifier struct {
+	filter   config.NotifierFilter
+	notified []model.Alert
+	err      error
+}
+
+func (n *fakeNotifier) GetType() string { return "fake" }
+func (n *fakeNotifier) GetName() string { return "fake" }
+func (n *fakeNotifier) Accept(alert model.Alert) bool {
+	return notifier.Match(n.filter, alert)
+}
+func (n *fakeNotifier) Notify(alert model.Alert) error {
+	n.notified = append(n.notified, alert)
+	return n.err
+}
+
+func TestProcessAlertInsertion_Notifiers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	emailer := NewMockEmailer(mockCtrl)
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	db.EXPECT().FindAlertRoutingRules().Return([]model.AlertRoutingRule{}, nil)
+
+	accepting := &fakeNotifier{filter: config.NotifierFilter{AlertCodes: []string{model.AlertCodeNewLicense}}}
+	failing := &fakeNotifier{err: errMock}
+	discarding := &fakeNotifier{filter: config.NotifierFilter{AlertSeverities: []string{model.AlertSeverityInfo}}}
+
+	as := AlertService{
+		Emailer:   emailer,
+		Database:  db,
+		Notifiers: []notifier.Notifier{accepting, failing, discarding},
+		TimeNow:   utils.Btc(utils.P("2019-11-05T16:02:03Z")),
+		Log:       logger.NewLogger("TEST"),
+		Queue:     hub.New(),
+		Config: config.Configuration{
+			AlertService: config.AlertService{
+				Emailer: config.Emailer{
+					To:      []string{"test@ercole.test"},
+					Enabled: true,
+					Retry:   config.NotifierRetry{MaxAttempts: 2},
+					AlertType: config.AlertType{
+						NewLicense: config.Directive{Enable: true},
+					},
+				},
+			},
+		},
+	}
+
+	alert := model.Alert{
+		AlertAffectedTechnology: model.TechnologyOracleDatabasePtr,
+		AlertCategory:           model.AlertCategoryLicense,
+		OtherInfo:               map[string]interface{}{},
+		AlertSeverity:           model.AlertSeverityCritical,
+		Description:             "This is just an alert test to a mocked emailer.",
+		Date:                    utils.P("2019-09-02T10:25:28Z"),
+		AlertCode:               model.AlertCodeNewLicense,
+	}
+
+	gomock.InOrder(
+		emailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Return(aerrMock),
+		emailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	as.ProcessAlertInsertion(hub.Fields{"alert": alert})
+
+	assert.Equal(t, []model.Alert{alert}, accepting.notified)
+	assert.Equal(t, []model.Alert{alert}, failing.notified)
+	assert.Empty(t, discarding.notified)
+}
+
+func TestProcessAlertInsertion_DirectiveRecipients(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	emailer := NewMockEmailer(mockCtrl)
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	as := AlertService{
+		Emailer:  emailer,
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T16:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+		Config: config.Configuration{
+			AlertService: config.AlertService{
+				Emailer: config.Emailer{
+					To: []string{"test@ercole.test"},
+					AlertType: config.AlertType{
+						NewHost:    config.Directive{Enable: true, To: []string{"hosts@ercole.test"}},
+						NewLicense: config.Directive{Enable: true, To: []string{"licenses@ercole.test"}},
+						NoData:     config.Directive{Enable: false},
+					},
+				},
+			},
+		},
+	}
+
+	alert := model.Alert{
+		AlertSeverity: model.AlertSeverityCritical,
+		OtherInfo:     map[string]interface{}{},
+		Date:          utils.P("2019-09-02T10:25:28Z"),
+	}
+
+	t.Run("Only the directive of the alert code", func(t *testing.T) {
+		alert.AlertCode = model.AlertCodeNewLicense
+
+		db.EXPECT().FindAlertRoutingRules().Return(nil, nil)
+		emailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), []string{"test@ercole.test", "licenses@ercole.test"})
+
+		as.ProcessAlertInsertion(hub.Fields{"alert": alert})
+	})
+
+	t.Run("Code without directive", func(t *testing.T) {
+		alert.AlertCode = model.AlertCodeMissingHostInCmdb
+
+		db.EXPECT().FindAlertRoutingRules().Return(nil, nil)
+		emailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), []string{"test@ercole.test"})
+
+		as.ProcessAlertInsertion(hub.Fields{"alert": alert})
+	})
+
+	t.Run("Disabled directive", func(t *testing.T) {
+		alert.AlertCode = model.AlertCodeNoData
+
+		db.EXPECT().FindAlertRoutingRules().Return(nil, nil)
+
+		as.ProcessAlertInsertion(hub.Fields{"alert": alert})
+	})
+}
+
+type namedNotifier struct {
+	fakeNotifier
+	name string
+}
+
+func (n *namedNotifier) GetName() string { return n.name }
+
+func TestProcessAlertInsertion_RoutingRules(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	emailer := NewMockEmailer(mockCtrl)
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	oncall := &namedNotifier{name: "oncall"}
+	other := &namedNotifier{name: "other"}
+
+	as := AlertService{
+		Emailer:   emailer,
+		Database:  db,
+		Notifiers: []notifier.Notifier{oncall, other},
+		TimeNow:   utils.Btc(utils.P("2019-11-05T16:02:03Z")),
+		Log:       logger.NewLogger("TEST"),
+		Config: config.Configuration{
+			AlertService: config.AlertService{
+				Emailer: config.Emailer{
+					To: []string{"test@ercole.test"},
+				},
+			},
+		},
+	}
+
+	alert := model.Alert{
+		AlertCode:     model.AlertCodeMissingHostInCmdb,
+		AlertSeverity: model.AlertSeverityWarning,
+		OtherInfo:     map[string]interface{}{"hostname": "foobar"},
+		Date:          utils.P("2019-09-02T10:25:28Z"),
+	}
+
+	rules := []model.AlertRoutingRule{
+		{
+			Name:       "italy",
+			Enabled:    true,
+			Match:      model.AlertRoutingMatch{Locations: []string{"Italy"}},
+			Recipients: []string{"italy@ercole.test"},
+			Channels:   []string{"oncall"},
+		},
+	}
+
+	t.Run("Matching rule", func(t *testing.T) {
+		db.EXPECT().FindAlertRoutingRules().Return(rules, nil)
+		db.EXPECT().FindCurrentHostData("foobar").Return(model.HostDataBE{Hostname: "foobar", Location: "Italy"}, nil)
+		emailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), []string{"italy@ercole.test"})
+
+		as.ProcessAlertInsertion(hub.Fields{"alert": alert})
+
+		assert.Equal(t, []model.Alert{alert}, oncall.notified)
+		assert.Empty(t, other.notified)
+	})
+
+	t.Run("No matching rule", func(t *testing.T) {
+		oncall.notified = nil
+
+		db.EXPECT().FindAlertRoutingRules().Return(rules, nil)
+		db.EXPECT().FindCurrentHostData("foobar").Return(model.HostDataBE{}, utils.ErrHostNotFound)
+
+		as.ProcessAlertInsertion(hub.Fields{"alert": alert})
+
+		assert.Empty(t, oncall.notified)
+		assert.Empty(t, other.notified)
+	})
+}
@@ -17,14 +17,18 @@ package auth
 
 import (
 	"crypto/rsa"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
 	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/context"
 
 	"github.com/ercole-io/ercole/v2/api-service/dto"
 	apiservice_service "github.com/ercole-io/ercole/v2/api-service/service"
@@ -33,6 +37,7 @@ import (
 const (
 	BasicType = "basic"
 	LdapType  = "ldap"
+	OidcType  = "oidc"
 )
 
 // AuthenticationProvider is a interface that wrap methods used to authenticate users
@@ -57,9 +62,9 @@ type ErcoleClaims struct {
 // BuildAuthenticationProvider return a authentication provider that match what is requested in the configuration
 // It's initialized
 func BuildAuthenticationProvider(conf config.AuthenticationProviderConfig, service apiservice_service.APIService, timeNow func() time.Time, log logger.Logger) []AuthenticationProvider {
-	provs := make([]AuthenticationProvider, 0, 2)
+	provs := make([]AuthenticationProvider, 0, 3)
 
-	if len(conf.Types) == 0 || (!utils.Contains(conf.Types, BasicType) && !utils.Contains(conf.Types, LdapType)) {
+	if len(conf.Types) == 0 || (!utils.Contains(conf.Types, BasicType) && !utils.Contains(conf.Types, LdapType) && !utils.Contains(conf.Types, OidcType)) {
 		panic("The AuthenticationProvider type wasn't recognized or supported")
 	}
 
@@ -83,6 +88,16 @@ func BuildAuthenticationProvider(conf config.AuthenticationProviderConfig, servi
 		provs = append(provs, prov)
 	}
 
+	if utils.Contains(conf.Types, OidcType) {
+		prov := new(OIDCAuthenticationProvider)
+		prov.Config = conf
+		prov.Log = log
+		prov.TimeNow = timeNow
+		prov.Service = service
+
+		provs = append(provs, prov)
+	}
+
 	return provs
 }
 
@@ -139,3 +154,28 @@ func validateBearerToken(tokenString string, timeNow func() time.Time, publicKey
 
 	return token.Claims.(*ErcoleClaims), nil
 }
+
+// isAPIToken return true if the authorization header contains an API token instead of a JWT
+func isAPIToken(tokenString string) bool {
+	return strings.HasPrefix(tokenString, "Bearer "+model.APITokenPrefix)
+}
+
+// authenticateAPIToken serve the request as the owner of the API token in the authorization header.
+// The token is saved in the request context, so the middlewares can limit the request to its scope
+func authenticateAPIToken(service apiservice_service.APIService, log logger.Logger, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	tokenString := r.Header.Get("Authorization")[len("Bearer "):]
+
+	user, token, err := service.AuthenticateAPIToken(tokenString)
+	if errors.Is(err, utils.ErrInvalidAPIToken) {
+		utils.WriteAndLogError(log, w, http.StatusUnauthorized, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	context.Set(r, "user", *user)
+	context.Set(r, "apiToken", *token)
+
+	next.ServeHTTP(w, r)
+}
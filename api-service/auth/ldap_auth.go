@@ -231,6 +231,11 @@ func (ap *LDAPAuthenticationProvider) AuthenticateMiddleware(next http.Handler)
 			return
 		}
 
+		if isAPIToken(tokenString) {
+			authenticateAPIToken(ap.Service, ap.Log, next, w, r)
+			return
+		}
+
 		if strings.HasPrefix(tokenString, "Bearer ") {
 			claims, err := validateBearerToken(tokenString, ap.TimeNow, ap.publicKey)
 			if err != nil {
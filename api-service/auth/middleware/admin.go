@@ -21,6 +21,12 @@ func Admin(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// an API token limited to some locations doesn't grant the administration
+		if token, ok := context.Get(r, "apiToken").(model.APIToken); ok && token.IsLocationScoped() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
 		next(w, r)
 	}
 }
@@ -0,0 +1,226 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// auditRedacted replaces the secrets in the audit log
+const auditRedacted = "********"
+
+// auditSecretFields are the parts of the names of the fields whose values are redacted
+var auditSecretFields = []string{"password", "secret", "privatekey", "token"}
+
+// AuditService stores the entries of the audit log
+type AuditService interface {
+	InsertAuditEntry(entry model.AuditEntry) error
+}
+
+// AuditSnapshot return the current state of the entity modified by the request, nil if it doesn't exist.
+// It's called before and after the request, to record what has changed
+type AuditSnapshot func(r *http.Request, body []byte) (interface{}, error)
+
+// AuditOptions describe the routes whose requests are recorded
+type AuditOptions struct {
+	// Service is the name of the service that receives the requests
+	Service string
+	// AuthProvider is the type of the authentication provider of the routes
+	AuthProvider string
+	// Prefix is the path prefix of the authentication provider, removed from the recorded routes
+	Prefix string
+	// Snapshots contains the functions that load the entities modified by the routes,
+	// by method and route (es. "DELETE /hosts/{hostname}")
+	Snapshots map[string]AuditSnapshot
+}
+
+// Audit records in the audit log the requests that modify data, with the user, the route,
+// the status of the response and the changes of the modified entity
+func Audit(service AuditService, log logger.Logger, options AuditOptions) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			var body []byte
+
+			if r.Body != nil {
+				var err error
+				if body, err = io.ReadAll(r.Body); err != nil {
+					utils.WriteAndLogError(log, w, http.StatusBadRequest, utils.NewError(err, http.StatusText(http.StatusBadRequest)))
+					return
+				}
+
+				r.Body.Close()
+			}
+
+			entry := newAuditEntry(r, options)
+			snapshot := options.Snapshots[entry.Method+" "+entry.Route]
+
+			if snapshot != nil {
+				entry.Before = takeAuditSnapshot(snapshot, r, body, log)
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			recorder := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			h.ServeHTTP(recorder, r)
+			entry.Status = recorder.status
+
+			switch {
+			case snapshot != nil && entry.Status < http.StatusBadRequest:
+				entry.After = takeAuditSnapshot(snapshot, r, body, log)
+
+				changes, err := model.DiffAuditSnapshots(entry.Before, entry.After)
+				if err != nil {
+					log.Warnf("Can't compare the snapshots of %s %s: %s", entry.Method, entry.Path, err)
+				} else {
+					entry.Changes = changes
+				}
+			case snapshot == nil && json.Valid(body):
+				var value interface{}
+				if err := json.Unmarshal(body, &value); err == nil {
+					entry.After, _ = toAuditJSON(value)
+				}
+			}
+
+			if err := service.InsertAuditEntry(entry); err != nil {
+				log.Errorf("Can't record %s %s in the audit log: %s", entry.Method, entry.Path, err)
+			}
+		})
+	}
+}
+
+func newAuditEntry(r *http.Request, options AuditOptions) model.AuditEntry {
+	entry := model.AuditEntry{
+		Service:      options.Service,
+		AuthProvider: options.AuthProvider,
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		Target:       mux.Vars(r),
+		Location:     r.URL.Query().Get("location"),
+		Changes:      []model.AuditChange{},
+	}
+
+	entry.Route = routeTemplate(r, options.Prefix)
+
+	if entry.Target == nil {
+		entry.Target = map[string]string{}
+	}
+
+	if user, ok := context.Get(r, "user").(model.User); ok {
+		entry.Username = user.Username
+	} else if username, _, ok := r.BasicAuth(); ok {
+		entry.Username = username
+	}
+
+	if token, ok := context.Get(r, "apiToken").(model.APIToken); ok {
+		id := token.ID
+		entry.APITokenID = &id
+	}
+
+	return entry
+}
+
+func takeAuditSnapshot(snapshot AuditSnapshot, r *http.Request, body []byte, log logger.Logger) json.RawMessage {
+	value, err := snapshot(r, body)
+	if err != nil {
+		log.Warnf("Can't load the entity modified by %s %s: %s", r.Method, r.URL.Path, err)
+		return nil
+	}
+
+	res, err := toAuditJSON(value)
+	if err != nil {
+		log.Warnf("Can't marshal the entity modified by %s %s: %s", r.Method, r.URL.Path, err)
+		return nil
+	}
+
+	return res
+}
+
+// toAuditJSON marshal the value with its secrets redacted
+func toAuditJSON(value interface{}) (json.RawMessage, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+
+	if generic == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(redactAuditValue(generic))
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if isAuditSecret(key) && nested != nil && nested != "" {
+				v[key] = auditRedacted
+			} else {
+				v[key] = redactAuditValue(nested)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactAuditValue(v[i])
+		}
+	}
+
+	return value
+}
+
+func isAuditSecret(field string) bool {
+	field = strings.ToLower(field)
+
+	for _, secret := range auditSecretFields {
+		if strings.Contains(field, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// auditResponseWriter keeps the status of the response
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -4,14 +4,20 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/ercole-io/ercole/v2/api-service/service"
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
 	"github.com/gorilla/context"
 	"github.com/gorilla/mux"
 )
 
-func Location(service service.APIServiceInterface) mux.MiddlewareFunc {
+// LocationsService list the locations visible by an user
+type LocationsService interface {
+	ListLocations(user interface{}) ([]string, error)
+}
+
+// Location restricts the location query parameter to the locations visible by the user,
+// and to the locations of the API token that authenticated the request, if any
+func Location(service LocationsService) mux.MiddlewareFunc {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			tokenString := r.Header.Get("Authorization")
@@ -32,14 +38,33 @@ func Location(service service.APIServiceInterface) mux.MiddlewareFunc {
 				return
 			}
 
-			if user.IsAdmin() {
+			token, isAPIToken := context.Get(r, "apiToken").(model.APIToken)
+			scoped := isAPIToken && token.IsLocationScoped()
+
+			if user.IsAdmin() && !scoped {
 				h.ServeHTTP(w, r)
 				return
 			}
 
-			locations, err := service.ListLocations(user)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
+			var locations []string
+
+			if user.IsAdmin() {
+				locations = token.Locations
+			} else {
+				userLocations, err := service.ListLocations(user)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				locations = userLocations
+				if scoped {
+					locations = ScopeLocations(userLocations, token.Locations)
+				}
+			}
+
+			if scoped && len(locations) == 0 {
+				w.WriteHeader(http.StatusForbidden)
 				return
 			}
 
@@ -64,6 +89,20 @@ func Location(service service.APIServiceInterface) mux.MiddlewareFunc {
 			}
 
 			splittedLocation := strings.Split(location, ",")
+
+			// the API tokens can't reach any location outside of their scope
+			if scoped {
+				for _, l := range splittedLocation {
+					if !utils.ContainsI(locations, strings.TrimSpace(l)) {
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+				}
+
+				h.ServeHTTP(w, r)
+				return
+			}
+
 			if utils.ContainsSomeI(locations, splittedLocation...) {
 				h.ServeHTTP(w, r)
 				return
@@ -73,3 +112,20 @@ func Location(service service.APIServiceInterface) mux.MiddlewareFunc {
 		})
 	}
 }
+
+// ScopeLocations return the locations of the token that are visible by its owner
+func ScopeLocations(userLocations, tokenLocations []string) []string {
+	if utils.ContainsI(userLocations, model.AllLocation) {
+		return tokenLocations
+	}
+
+	locations := make([]string, 0, len(tokenLocations))
+
+	for _, location := range tokenLocations {
+		if utils.ContainsI(userLocations, location) {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+)
+
+// PermissionsService returns the effective permission of an user on a location
+type PermissionsService interface {
+	GetUserPermission(user interface{}, location string) (string, error)
+}
+
+// TargetLocations return the locations of the entities modified by the request,
+// none if they don't exist yet or they don't belong to a location
+type TargetLocations func(r *http.Request, body []byte) ([]string, error)
+
+// WriteOptions describe how the locations modified by the routes are resolved
+type WriteOptions struct {
+	// Prefix is the path prefix of the authentication provider, removed from the routes
+	Prefix string
+	// Targets contains the functions that resolve the locations of the entities modified by the routes,
+	// by method and route (es. "DELETE /hosts/{hostname}")
+	Targets map[string]TargetLocations
+}
+
+// Write rejects the requests that modify data if the user has only the read permission
+// on the locations of the modified entities, or if they are authenticated by a read only API token
+// or by an API token scoped to other locations. The routes without a target, or whose entities
+// aren't found, require the write permission on all the locations
+func Write(service PermissionsService, options WriteOptions) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			tokenString := r.Header.Get("Authorization")
+			if strings.HasPrefix(tokenString, "Basic") {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			claims, exists := context.GetOk(r, "user")
+			if !exists {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			user, ok := claims.(model.User)
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			token, isAPIToken := context.Get(r, "apiToken").(model.APIToken)
+			if isAPIToken && token.Permission != model.WritePermission {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			scoped := isAPIToken && token.IsLocationScoped()
+
+			if user.IsAdmin() && !scoped {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			location := model.AllLocation
+
+			if target := options.Targets[r.Method+" "+routeTemplate(r, options.Prefix)]; target != nil {
+				locations, err := targetLocations(target, r)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				if len(locations) > 0 {
+					location = strings.Join(locations, ",")
+				}
+			}
+
+			// the API tokens can't modify any location outside of their scope
+			if scoped && !inScope(token, location) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			if user.IsAdmin() {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			permission, err := service.GetUserPermission(user, location)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			if permission != model.WritePermission && permission != model.AdminPermission {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// inScope return true if all the locations are in the scope of the token
+func inScope(token model.APIToken, location string) bool {
+	if location == "" {
+		return true
+	}
+
+	for _, l := range strings.Split(location, ",") {
+		if !utils.ContainsI(token.Locations, strings.TrimSpace(l)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// targetLocations resolve the locations modified by the request, leaving its body readable by the handler
+func targetLocations(target TargetLocations, r *http.Request) ([]string, error) {
+	var body []byte
+
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return target(r, body)
+}
+
+// routeTemplate return the template of the route matched by the request, without the prefix
+func routeTemplate(r *http.Request, prefix string) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return strings.TrimPrefix(template, prefix)
+		}
+	}
+
+	return ""
+}
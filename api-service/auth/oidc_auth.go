@@ -0,0 +1,526 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/context"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/api-service/service"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const (
+	defaultOIDCUsernameClaim = "preferred_username"
+	defaultOIDCGroupsClaim   = "groups"
+)
+
+// OIDCAuthenticationProvider is the concrete implementation of AuthenticationProvider that provide an OpenID Connect user authentication.
+// The groups of the user returned by the issuer are matched with the tags of the ercole groups, that contain the roles.
+type OIDCAuthenticationProvider struct {
+	// Config contains the dataservice global configuration
+	Config config.AuthenticationProviderConfig
+	// TimeNow contains a function that return the current time
+	TimeNow func() time.Time
+	// Log contains logger formatted
+	Log logger.Logger
+	// privateKey contains the private key used to sign the JWT tokens
+	privateKey *rsa.PrivateKey
+	// publicKey contains the public key used to check the JWT tokens
+	publicKey *rsa.PublicKey
+	// Service contains the underlying service used to perform various logical and store operations
+	Service service.APIService
+	// Client is the http client used to contact the issuer
+	Client *http.Client
+
+	mutex     sync.RWMutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Init initializes the service and database
+func (ap *OIDCAuthenticationProvider) Init() {
+	raw, err := os.ReadFile(ap.Config.PrivateKey)
+	if err != nil {
+		ap.Log.Panic(err)
+	}
+
+	ap.privateKey, ap.publicKey, err = parsePrivateKey(raw)
+	if err != nil {
+		ap.Log.Panic(utils.NewErrorf("Unable to parse the private key: %s", err))
+	}
+
+	if ap.Client == nil {
+		ap.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	// the issuer could be temporarily unreachable, the discovery is retried at the first login
+	if _, err := ap.getDiscovery(); err != nil {
+		ap.Log.Error(err)
+	}
+}
+
+// GetUserInfoIfCredentialsAreCorrect isn't supported, the credentials of the users are checked by the issuer
+func (ap *OIDCAuthenticationProvider) GetUserInfoIfCredentialsAreCorrect(username string, password string) (*dto.User, error) {
+	return nil, utils.ErrUnsupportedCredentials
+}
+
+// Authorize redirect the user to the authorization endpoint of the issuer
+func (ap *OIDCAuthenticationProvider) Authorize(w http.ResponseWriter, r *http.Request) {
+	discovery, err := ap.getDiscovery()
+	if err != nil {
+		utils.WriteAndLogError(ap.Log, w, http.StatusBadGateway, err)
+		return
+	}
+
+	scopes := ap.Config.OIDCScopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid"}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", ap.Config.OIDCClientID)
+	query.Set("redirect_uri", ap.Config.OIDCRedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+
+	if state := r.URL.Query().Get("state"); state != "" {
+		query.Set("state", state)
+	}
+
+	http.Redirect(w, r, discovery.AuthorizationEndpoint+"?"+query.Encode(), http.StatusFound)
+}
+
+// GetToken exchange the authorization code with the issuer and return an ercole token
+func (ap *OIDCAuthenticationProvider) GetToken(w http.ResponseWriter, r *http.Request) {
+	type LoginRequest struct {
+		Code        string `json:"code"`
+		RedirectURI string `json:"redirectUri"`
+	}
+
+	var request LoginRequest
+
+	//Parse the request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.WriteAndLogError(ap.Log, w, http.StatusBadRequest, utils.NewError(err, http.StatusText(http.StatusUnprocessableEntity)))
+		return
+	}
+
+	if request.Code == "" {
+		utils.WriteAndLogError(ap.Log, w, http.StatusBadRequest, utils.NewError(errors.New("The authorization code is missing"), http.StatusText(http.StatusBadRequest)))
+		return
+	}
+
+	if request.RedirectURI == "" {
+		request.RedirectURI = ap.Config.OIDCRedirectURL
+	}
+
+	idToken, err := ap.exchangeCode(request.Code, request.RedirectURI)
+	if err != nil {
+		utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, err)
+		return
+	}
+
+	claims, err := ap.validateIssuerToken(idToken)
+	if err != nil {
+		ap.Log.Debugf("Invalid token: %s", err)
+		utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, utils.ErrInvalidToken)
+
+		return
+	}
+
+	userInfo, err := ap.getUserInfo(claims)
+	if err != nil {
+		utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, err)
+		return
+	}
+
+	token, err := buildToken(ap.TimeNow(), ap.Config.TokenValidityTimeout, *userInfo, ap.privateKey)
+	if err != nil {
+		ap.Log.Errorf("Unable to get signed token: %s", err)
+		utils.WriteAndLogError(ap.Log, w, http.StatusInternalServerError, fmt.Errorf("Unable to get signed token"))
+
+		return
+	}
+
+	if _, err := w.Write([]byte(token)); err != nil {
+		utils.WriteAndLogError(ap.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+// AuthenticateMiddleware return the middleware used to check if the users are authenticated
+// Bearer tokens can be the ones returned by GetToken or the ones released by the issuer
+func (ap *OIDCAuthenticationProvider) AuthenticateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := r.Header.Get("Authorization")
+		if tokenString == "" {
+			utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, utils.NewError(errors.New("You don't have setted the authorization header"), http.StatusText(http.StatusUnauthorized)))
+			return
+		}
+
+		if strings.HasPrefix(tokenString, "Basic ") {
+			tokenString = tokenString[len("Basic "):]
+			val, err := base64.StdEncoding.DecodeString(tokenString)
+			if err != nil {
+				utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, utils.NewError(err, http.StatusText(http.StatusUnauthorized)))
+				return
+			}
+
+			if !bytes.ContainsAny(val, ":") {
+				utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, utils.NewError(errors.New("A : is missing in the auth header"), http.StatusText(http.StatusUnauthorized)))
+				return
+			}
+
+			user := val[:bytes.IndexRune(val, ':')]
+			password := val[bytes.IndexRune(val, ':')+1:]
+
+			if subtle.ConstantTimeCompare(user, []byte(ap.Config.Username)) == 0 || subtle.ConstantTimeCompare(password, []byte(ap.Config.Password)) == 0 {
+				utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, utils.NewError(errors.New("Invalid credentials"), http.StatusText(http.StatusUnauthorized)))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if isAPIToken(tokenString) {
+			authenticateAPIToken(ap.Service, ap.Log, next, w, r)
+			return
+		}
+
+		if strings.HasPrefix(tokenString, "Bearer ") {
+			if claims, err := validateBearerToken(tokenString, ap.TimeNow, ap.publicKey); err == nil && claims != nil {
+				ercoleGroups := ap.Service.GetMatchedGroupsName(claims.Groups)
+
+				context.Set(r, "user", model.User{Username: claims.Subject, Groups: ercoleGroups})
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := ap.validateIssuerToken(tokenString[len("Bearer "):])
+			if err != nil {
+				ap.Log.Debugf("Invalid token: %s", err)
+				utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, utils.ErrInvalidToken)
+
+				return
+			}
+
+			userInfo, err := ap.getUserInfo(claims)
+			if err != nil {
+				utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, err)
+				return
+			}
+
+			context.Set(r, "user", model.User{Username: userInfo.Username, FirstName: userInfo.FirstName, LastName: userInfo.LastName, Groups: userInfo.Groups})
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		utils.WriteAndLogError(ap.Log, w, http.StatusUnauthorized, utils.NewErrorf("The authorization header value doesn't begin with Basic or Bearer"))
+	})
+}
+
+func (ap *OIDCAuthenticationProvider) GetType() string {
+	return OidcType
+}
+
+func (ap *OIDCAuthenticationProvider) httpClient() *http.Client {
+	if ap.Client == nil {
+		return http.DefaultClient
+	}
+
+	return ap.Client
+}
+
+func (ap *OIDCAuthenticationProvider) getDiscovery() (*oidcDiscovery, error) {
+	ap.mutex.RLock()
+	discovery := ap.discovery
+	ap.mutex.RUnlock()
+
+	if discovery != nil {
+		return discovery, nil
+	}
+
+	issuer := strings.TrimSuffix(ap.Config.OIDCIssuer, "/")
+
+	discovery = &oidcDiscovery{}
+	if err := ap.getJSON(issuer+"/.well-known/openid-configuration", discovery); err != nil {
+		return nil, utils.NewErrorf("%w: %s", utils.ErrOIDCDiscovery, err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, utils.NewErrorf("%w: issuer %q doesn't match the configured one", utils.ErrOIDCDiscovery, discovery.Issuer)
+	}
+
+	keys, err := ap.fetchKeys(discovery.JwksURI)
+	if err != nil {
+		return nil, err
+	}
+
+	ap.mutex.Lock()
+	ap.discovery = discovery
+	ap.keys = keys
+	ap.mutex.Unlock()
+
+	return discovery, nil
+}
+
+func (ap *OIDCAuthenticationProvider) fetchKeys(jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := ap.getJSON(jwksURI, &jwks); err != nil {
+		return nil, utils.NewErrorf("%w: %s", utils.ErrOIDCDiscovery, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			ap.Log.Warnf("Invalid modulus of the key %q: %s", jwk.Kid, err)
+			continue
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			ap.Log.Warnf("Invalid exponent of the key %q: %s", jwk.Kid, err)
+			continue
+		}
+
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+// getKey return the key of the issuer with the kid, the keys are fetched again if it's missing because they could have been rotated
+func (ap *OIDCAuthenticationProvider) getKey(kid string) (*rsa.PublicKey, error) {
+	discovery, err := ap.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	ap.mutex.RLock()
+	key, ok := ap.keys[kid]
+	ap.mutex.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	keys, err := ap.fetchKeys(discovery.JwksURI)
+	if err != nil {
+		return nil, err
+	}
+
+	ap.mutex.Lock()
+	ap.keys = keys
+	ap.mutex.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Unknown key %q", kid)
+}
+
+func (ap *OIDCAuthenticationProvider) getJSON(url string, out interface{}) error {
+	resp, err := ap.httpClient().Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// exchangeCode exchange the authorization code at the token endpoint of the issuer and return the id token
+func (ap *OIDCAuthenticationProvider) exchangeCode(code, redirectURI string) (string, error) {
+	discovery, err := ap.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", ap.Config.OIDCClientID)
+	form.Set("client_secret", ap.Config.OIDCClientSecret)
+
+	resp, err := ap.httpClient().PostForm(discovery.TokenEndpoint, form)
+	if err != nil {
+		return "", utils.NewError(err, http.StatusText(http.StatusUnauthorized))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", utils.NewErrorf("The issuer refused the authorization code: %s", resp.Status)
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", utils.NewError(err, http.StatusText(http.StatusUnauthorized))
+	}
+
+	if tokenResponse.IDToken == "" {
+		return "", utils.NewErrorf("The issuer didn't return an id token")
+	}
+
+	return tokenResponse.IDToken, nil
+}
+
+// validateIssuerToken check the signature, the issuer, the audience and the validity of a token released by the issuer
+func (ap *OIDCAuthenticationProvider) validateIssuerToken(tokenString string) (jwt.MapClaims, error) {
+	discovery, err := ap.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	jwt.TimeFunc = ap.TimeNow
+	claims := jwt.MapClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return ap.getKey(kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name}))
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, utils.ErrInvalidToken
+	}
+
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, fmt.Errorf("Invalid issuer")
+	}
+
+	if !claims.VerifyAudience(ap.Config.OIDCClientID, true) {
+		return nil, fmt.Errorf("Invalid audience")
+	}
+
+	return claims, nil
+}
+
+// getUserInfo return the user described by the claims, with the ercole groups matched by the groups claim
+func (ap *OIDCAuthenticationProvider) getUserInfo(claims jwt.MapClaims) (*dto.User, error) {
+	usernameClaim := ap.Config.OIDCUsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = defaultOIDCUsernameClaim
+	}
+
+	username, _ := claims[usernameClaim].(string)
+	if username == "" {
+		username, _ = claims["sub"].(string)
+	}
+
+	if username == "" {
+		return nil, utils.ErrInvalidUser
+	}
+
+	groupsClaim := ap.Config.OIDCGroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = defaultOIDCGroupsClaim
+	}
+
+	groups := make([]string, 0)
+
+	switch v := claims[groupsClaim].(type) {
+	case string:
+		groups = append(groups, v)
+	case []interface{}:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+
+	ercoleGroups := ap.Service.GetMatchedGroupsName(groups)
+	if len(ercoleGroups) == 0 {
+		return nil, utils.ErrGroupNotFound
+	}
+
+	firstName, _ := claims["given_name"].(string)
+	lastName, _ := claims["family_name"].(string)
+
+	return &dto.User{
+		Username:  username,
+		FirstName: firstName,
+		LastName:  lastName,
+		Groups:    ercoleGroups,
+	}, nil
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiservice_database "github.com/ercole-io/ercole/v2/api-service/database"
+	apiservice_service "github.com/ercole-io/ercole/v2/api-service/service"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+type groupsByTagDatabase struct {
+	apiservice_database.MongoDatabaseInterface
+	groups []model.Group
+}
+
+func (db *groupsByTagDatabase) GetGroupByTag(tag string) (*model.Group, error) {
+	for i := range db.groups {
+		if db.groups[i].IsTag(tag) {
+			return &db.groups[i], nil
+		}
+	}
+
+	return nil, utils.ErrGroupNotFound
+}
+
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+	// idToken is returned by the token endpoint for the valid code
+	idToken string
+}
+
+const mockIssuerCode = "valid-code"
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuer := &mockIssuer{key: key, kid: "key-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": issuer.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(issuer.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(issuer.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != mockIssuerCode ||
+			r.PostForm.Get("client_id") != "ercole" || r.PostForm.Get("client_secret") != "s3cr3t" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": issuer.idToken, "token_type": "Bearer"})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (m *mockIssuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+
+	ss, err := token.SignedString(m.key)
+	require.NoError(t, err)
+
+	return ss
+}
+
+var oidcTestNow = utils.P("2019-11-05T14:02:03Z")
+
+func (m *mockIssuer) claims(audience string, groups ...string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                m.server.URL,
+		"aud":                audience,
+		"sub":                "0001",
+		"preferred_username": "jdoe",
+		"given_name":         "John",
+		"family_name":        "Doe",
+		"groups":             groups,
+		"iat":                oidcTestNow.Unix(),
+		"exp":                oidcTestNow.Add(time.Hour).Unix(),
+	}
+}
+
+func newTestOIDCProvider(t *testing.T, issuer *mockIssuer) *OIDCAuthenticationProvider {
+	keyFile := filepath.Join(t.TempDir(), "private.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(testRSAPrivateKey), 0600))
+
+	ap := &OIDCAuthenticationProvider{
+		Config: config.AuthenticationProviderConfig{
+			PrivateKey:           keyFile,
+			TokenValidityTimeout: 1000,
+			OIDCIssuer:           issuer.server.URL,
+			OIDCClientID:         "ercole",
+			OIDCClientSecret:     "s3cr3t",
+			OIDCRedirectURL:      "https://ercole.example.com/oidc/callback",
+			OIDCScopes:           []string{"openid", "profile"},
+		},
+		TimeNow: utils.Btc(oidcTestNow),
+		Log:     logger.NewLogger("TEST"),
+		Service: apiservice_service.APIService{
+			Database: &groupsByTagDatabase{
+				groups: []model.Group{{Name: "Admins", Roles: []string{"admin"}, Tags: []string{"ercole-admins"}}},
+			},
+		},
+		Client: issuer.server.Client(),
+	}
+	ap.Init()
+
+	return ap
+}
+
+func TestBuildAuthenticationProvider_OIDC(t *testing.T) {
+	testConf := config.AuthenticationProviderConfig{
+		Types: []string{"oidc"},
+	}
+
+	aps := BuildAuthenticationProvider(testConf, apiservice_service.APIService{}, nil, nil)
+
+	require.Len(t, aps, 1)
+	assert.Equal(t, OidcType, aps[0].GetType())
+	assert.IsType(t, &OIDCAuthenticationProvider{}, aps[0])
+}
+
+func TestOIDCAuthorize(t *testing.T) {
+	issuer := newMockIssuer(t)
+	ap := newTestOIDCProvider(t, issuer)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/oidc/login?state=xyz", nil)
+	require.NoError(t, err)
+
+	ap.Authorize(rr, req)
+
+	require.Equal(t, http.StatusFound, rr.Code)
+	location := rr.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, issuer.server.URL+"/authorize?"))
+	assert.Contains(t, location, "client_id=ercole")
+	assert.Contains(t, location, "response_type=code")
+	assert.Contains(t, location, "scope=openid+profile")
+	assert.Contains(t, location, "state=xyz")
+}
+
+func TestOIDCGetToken_Success(t *testing.T) {
+	issuer := newMockIssuer(t)
+	issuer.idToken = issuer.sign(t, issuer.claims("ercole", "ercole-admins", "others"))
+	ap := newTestOIDCProvider(t, issuer)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/oidc/login", strings.NewReader(`{"code": "valid-code"}`))
+	require.NoError(t, err)
+
+	ap.GetToken(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	claims, err := validateBearerToken("Bearer "+rr.Body.String(), ap.TimeNow, ap.publicKey)
+	require.NoError(t, err)
+	assert.Equal(t, "jdoe", claims.Subject)
+	assert.Equal(t, []string{"Admins"}, claims.Groups)
+}
+
+func TestOIDCGetToken_InvalidCode(t *testing.T) {
+	issuer := newMockIssuer(t)
+	issuer.idToken = issuer.sign(t, issuer.claims("ercole", "ercole-admins"))
+	ap := newTestOIDCProvider(t, issuer)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/oidc/login", strings.NewReader(`{"code": "wrong-code"}`))
+	require.NoError(t, err)
+
+	ap.GetToken(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestOIDCGetToken_MissingCode(t *testing.T) {
+	issuer := newMockIssuer(t)
+	ap := newTestOIDCProvider(t, issuer)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/oidc/login", strings.NewReader(`{}`))
+	require.NoError(t, err)
+
+	ap.GetToken(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestOIDCGetToken_NoMatchedGroups(t *testing.T) {
+	issuer := newMockIssuer(t)
+	issuer.idToken = issuer.sign(t, issuer.claims("ercole", "others"))
+	ap := newTestOIDCProvider(t, issuer)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/oidc/login", strings.NewReader(`{"code": "valid-code"}`))
+	require.NoError(t, err)
+
+	ap.GetToken(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestOIDCAuthenticateMiddleware_IssuerToken(t *testing.T) {
+	issuer := newMockIssuer(t)
+	ap := newTestOIDCProvider(t, issuer)
+
+	var user model.User
+
+	handler := ap.AuthenticateMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = context.Get(r, "user").(model.User)
+	}))
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+issuer.sign(t, issuer.claims("ercole", "ercole-admins")))
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, model.User{Username: "jdoe", FirstName: "John", LastName: "Doe", Groups: []string{"Admins"}}, user)
+}
+
+func TestOIDCAuthenticateMiddleware_ErcoleToken(t *testing.T) {
+	issuer := newMockIssuer(t)
+	issuer.idToken = issuer.sign(t, issuer.claims("ercole", "ercole-admins"))
+	ap := newTestOIDCProvider(t, issuer)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/oidc/login", strings.NewReader(`{"code": "valid-code"}`))
+	require.NoError(t, err)
+
+	ap.GetToken(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	called := false
+	handler := ap.AuthenticateMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	rr2 := httptest.NewRecorder()
+	req2, err := http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+	req2.Header.Set("Authorization", "Bearer "+rr.Body.String())
+
+	handler.ServeHTTP(rr2, req2)
+
+	require.Equal(t, http.StatusOK, rr2.Code)
+	assert.True(t, called)
+}
+
+func TestOIDCAuthenticateMiddleware_InvalidTokens(t *testing.T) {
+	issuer := newMockIssuer(t)
+	ap := newTestOIDCProvider(t, issuer)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	expired := issuer.claims("ercole", "ercole-admins")
+	expired["exp"] = oidcTestNow.Add(-time.Minute).Unix()
+
+	wrongIssuer := issuer.claims("ercole", "ercole-admins")
+	wrongIssuer["iss"] = "https://another.example.com"
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.claims("ercole", "ercole-admins"))
+	forged.Header["kid"] = issuer.kid
+	forgedToken, err := forged.SignedString(otherKey)
+	require.NoError(t, err)
+
+	tokens := map[string]string{
+		"wrong audience": issuer.sign(t, issuer.claims("another-client", "ercole-admins")),
+		"wrong issuer":   issuer.sign(t, wrongIssuer),
+		"expired":        issuer.sign(t, expired),
+		"forged":         forgedToken,
+		"no groups":      issuer.sign(t, issuer.claims("ercole")),
+	}
+
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			handler := ap.AuthenticateMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("the handler must not be called")
+			}))
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		})
+	}
+}
+
+func TestOIDCGetUserInfoIfCredentialsAreCorrect(t *testing.T) {
+	ap := &OIDCAuthenticationProvider{}
+
+	res, err := ap.GetUserInfoIfCredentialsAreCorrect("foobar", "password")
+
+	assert.ErrorIs(t, err, utils.ErrUnsupportedCredentials)
+	assert.Nil(t, res)
+}
@@ -159,6 +159,11 @@ func (ap *BasicAuthenticationProvider) AuthenticateMiddleware(next http.Handler)
 			return
 		}
 
+		if isAPIToken(tokenString) {
+			authenticateAPIToken(ap.Service, ap.Log, next, w, r)
+			return
+		}
+
 		if strings.HasPrefix(tokenString, "Bearer ") {
 			claims, err := validateBearerToken(tokenString, ap.TimeNow, ap.publicKey)
 			if err != nil {
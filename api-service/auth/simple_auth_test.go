@@ -29,8 +29,10 @@ import (
 	r "math/rand"
 
 	jwt "github.com/golang-jwt/jwt/v4"
+	gorillacontext "github.com/gorilla/context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	apiservice_database "github.com/ercole-io/ercole/v2/api-service/database"
 	"github.com/ercole-io/ercole/v2/api-service/dto"
@@ -110,7 +112,10 @@ var db = &apiservice_database.MongoDatabase{
 }
 
 var serviceAuth = &apiservice_service.APIService{
-	Database: db,
+	Database:    db,
+	TimeNow:     time.Now,
+	Log:         logger.NewLogger("TEST"),
+	NewObjectID: primitive.NewObjectID,
 }
 
 func TestGetUserInfoIfCredentialsAreCorrect_WhenAreCredentialsAreWrong(t *testing.T) {
@@ -686,3 +691,78 @@ func TestAuthenticateMiddleware_BearerOk(t *testing.T) {
 	db.Client.Database(db.Config.Mongodb.DBName).Collection("users").Drop(context.TODO())
 	db.Client.Disconnect(context.TODO())
 }
+
+func TestAuthenticateMiddleware_APIToken(t *testing.T) {
+	db.ConnectToMongodb()
+
+	defer serviceAuth.RemoveServiceAccount("automation")
+
+	_, err := serviceAuth.AddServiceAccount(model.User{Username: "automation", Groups: []string{"Test"}})
+	require.NoError(t, err)
+
+	token, value, err := serviceAuth.CreateAPIToken(model.APIToken{
+		Name:       "inventory sync",
+		Username:   "automation",
+		Locations:  []string{"Italy"},
+		Permission: model.ReadPermission,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	bap := BasicAuthenticationProvider{
+		Log:     logger.NewLogger("TEST", logger.LogVerbosely(true)),
+		Service: *serviceAuth,
+	}
+
+	var user model.User
+
+	var apiToken model.APIToken
+
+	handler := bap.AuthenticateMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = gorillacontext.Get(r, "user").(model.User)
+		apiToken = gorillacontext.Get(r, "apiToken").(model.APIToken)
+
+		w.WriteHeader(222)
+	}))
+
+	t.Run("Valid token", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/myping", nil)
+		require.NoError(t, err)
+		req.Header.Add("Authorization", "Bearer "+value)
+
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, 222, rr.Code)
+		assert.Equal(t, model.User{Username: "automation", Groups: []string{"Test"}, ServiceAccount: true}, user)
+		assert.Equal(t, token.ID, apiToken.ID)
+		assert.Equal(t, []string{"Italy"}, apiToken.Locations)
+	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		require.NoError(t, serviceAuth.RevokeAPIToken(token.ID))
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/myping", nil)
+		require.NoError(t, err)
+		req.Header.Add("Authorization", "Bearer "+value)
+
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/myping", nil)
+		require.NoError(t, err)
+		req.Header.Add("Authorization", "Bearer "+model.APITokenPrefix+"unknown")
+
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	db.Client.Database(db.Config.Mongodb.DBName).Drop(context.TODO())
+	db.Client.Disconnect(context.TODO())
+}
@@ -18,8 +18,11 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/url"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
 	"github.com/ercole-io/ercole/v2/api-service/dto"
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
@@ -69,3 +72,24 @@ func (c *Client) AckAlerts(filter dto.AlertsFilter) error {
 
 	return nil
 }
+
+func (c *Client) ResolveAlert(id primitive.ObjectID, reason string) error {
+	b := struct {
+		Reason string `json:"reason"`
+	}{
+		Reason: reason,
+	}
+
+	body, err := json.Marshal(b)
+	if err != nil {
+		return utils.NewError(err, "Can't marshal")
+	}
+
+	resp, err := c.getResponse(context.TODO(), fmt.Sprintf("/alerts/%s/resolve", id.Hex()), "POST", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -26,6 +26,8 @@ import (
 	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
 	"github.com/ercole-io/ercole/v2/api-service/dto"
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/model"
@@ -35,10 +37,13 @@ import (
 type ApiSvcClientInterface interface {
 	GetAlertsByFilter(filter dto.AlertsFilter) ([]model.Alert, error)
 	AckAlerts(filter dto.AlertsFilter) error
+	ResolveAlert(id primitive.ObjectID, reason string) error
 	GetOracleDatabaseLicenseTypes() ([]model.OracleDatabaseLicenseType, error)
 	GetSQLServerDatabaseLicenseTypes() ([]model.SqlServerDatabaseLicenseType, error)
 	GetMySqlDatabaseLicenseTypes() ([]model.MySqlLicenseType, error)
 	GetOracleDatabases() ([]model.OracleDatabase, error)
+	GetDatabaseLicensesCompliance(location string) ([]dto.LicenseCompliance, error)
+	GetClusterReconciliation() (*dto.ClusterReconciliation, error)
 }
 
 type Client struct {
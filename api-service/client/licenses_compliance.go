@@ -0,0 +1,39 @@
+// Copyright (c) 2022 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+)
+
+func (c *Client) GetDatabaseLicensesCompliance(location string) ([]dto.LicenseCompliance, error) {
+	response := struct {
+		LicensesCompliance []dto.LicenseCompliance `json:"licensesCompliance"`
+	}{}
+
+	params := url.Values{}
+	params.Add("location", location)
+
+	err := c.getParsedResponseWithParams(context.TODO(), "/hosts/technologies/all/databases/licenses-compliance", nil, &response, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.LicensesCompliance, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (ctrl *APIController) ListAgentCredentials(w http.ResponseWriter, r *http.Request) {
+	credentials, err := ctrl.Service.ListAgentCredentials()
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"agentCredentials": credentials,
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// EnrollAgentCredential create the credential of the agent of the host in the body.
+// The secret is returned only in this response
+func (ctrl *APIController) EnrollAgentCredential(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Hostname string `json:"hostname"`
+	}
+
+	if err := utils.Decode(r.Body, &request); err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	}
+
+	credential, secret, err := ctrl.Service.EnrollAgentCredential(request.Hostname)
+	if errors.Is(err, utils.ErrInvalidAgentCredential) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, utils.ErrAgentCredentialAlreadyExists) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, agentCredentialResponse(credential, secret))
+}
+
+// RotateAgentCredential replace the secret of the credential of the host.
+// The new secret is returned only in this response
+func (ctrl *APIController) RotateAgentCredential(w http.ResponseWriter, r *http.Request) {
+	credential, secret, err := ctrl.Service.RotateAgentCredential(mux.Vars(r)["hostname"])
+	if errors.Is(err, utils.ErrAgentCredentialNotFound) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, utils.ErrInvalidAgentCredential) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, agentCredentialResponse(credential, secret))
+}
+
+func (ctrl *APIController) RevokeAgentCredential(w http.ResponseWriter, r *http.Request) {
+	err := ctrl.Service.RevokeAgentCredential(mux.Vars(r)["hostname"])
+	if errors.Is(err, utils.ErrAgentCredentialNotFound) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// agentCredentialResponse contains what is needed to configure the agent:
+// it authenticate with its hostname as username and the secret as password
+func agentCredentialResponse(credential *model.AgentCredential, secret string) map[string]interface{} {
+	return map[string]interface{}{
+		"agentCredential": credential,
+		"username":        credential.Hostname,
+		"secret":          secret,
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestListAgentCredentials(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		credentials := []model.AgentCredential{
+			{Hostname: "rac1", Hint: "ercagt_AbCd", CreatedAt: utils.P("2019-11-01T14:02:03Z")},
+		}
+		as.EXPECT().ListAgentCredentials().Return(credentials, nil)
+
+		req, err := http.NewRequest("GET", "", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.ListAgentCredentials).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(map[string]interface{}{"agentCredentials": credentials}), rr.Body.String())
+	})
+
+	t.Run("Internal server error", func(t *testing.T) {
+		as.EXPECT().ListAgentCredentials().Return(nil, aerrMock)
+
+		req, err := http.NewRequest("GET", "", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.ListAgentCredentials).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestEnrollAgentCredential(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		credential := model.AgentCredential{
+			ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+			Hostname:  "rac1",
+			Hint:      "ercagt_AbCd",
+			CreatedAt: utils.P("2019-11-05T14:02:03Z"),
+		}
+		as.EXPECT().EnrollAgentCredential("rac1").Return(&credential, "ercagt_AbCdEf", nil)
+
+		req, err := http.NewRequest("POST", "", strings.NewReader(`{"hostname":"rac1"}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.EnrollAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(map[string]interface{}{
+			"agentCredential": credential,
+			"username":        "rac1",
+			"secret":          "ercagt_AbCdEf",
+		}), rr.Body.String())
+	})
+
+	t.Run("Already enrolled", func(t *testing.T) {
+		as.EXPECT().EnrollAgentCredential("rac1").Return(nil, "", utils.ErrAgentCredentialAlreadyExists)
+
+		req, err := http.NewRequest("POST", "", strings.NewReader(`{"hostname":"rac1"}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.EnrollAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Invalid hostname", func(t *testing.T) {
+		as.EXPECT().EnrollAgentCredential("").Return(nil, "", utils.ErrInvalidAgentCredential)
+
+		req, err := http.NewRequest("POST", "", strings.NewReader(`{}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.EnrollAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Bad request", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "", strings.NewReader(`{"foo":"bar"}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.EnrollAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRotateAgentCredential(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		rotatedAt := utils.P("2019-11-05T14:02:03Z")
+		credential := model.AgentCredential{
+			Hostname:  "rac1",
+			Hint:      "ercagt_GhIj",
+			CreatedAt: utils.P("2019-11-01T14:02:03Z"),
+			RotatedAt: &rotatedAt,
+		}
+		as.EXPECT().RotateAgentCredential("rac1").Return(&credential, "ercagt_GhIjKl", nil)
+
+		req, err := http.NewRequest("POST", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"hostname": "rac1"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RotateAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(map[string]interface{}{
+			"agentCredential": credential,
+			"username":        "rac1",
+			"secret":          "ercagt_GhIjKl",
+		}), rr.Body.String())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().RotateAgentCredential("rac9").Return(nil, "", utils.ErrAgentCredentialNotFound)
+
+		req, err := http.NewRequest("POST", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"hostname": "rac9"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RotateAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		as.EXPECT().RotateAgentCredential("rac2").Return(nil, "", utils.ErrInvalidAgentCredential)
+
+		req, err := http.NewRequest("POST", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"hostname": "rac2"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RotateAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRevokeAgentCredential(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		as.EXPECT().RevokeAgentCredential("rac1").Return(nil)
+
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"hostname": "rac1"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RevokeAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().RevokeAgentCredential("rac9").Return(utils.ErrAgentCredentialNotFound)
+
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"hostname": "rac9"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RevokeAgentCredential).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
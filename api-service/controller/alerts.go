@@ -25,6 +25,7 @@ import (
 
 	"github.com/golang/gddo/httputil"
 	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/ercole-io/ercole/v2/api-service/dto"
@@ -37,6 +38,8 @@ import (
 func (ctrl *APIController) SearchAlerts(w http.ResponseWriter, r *http.Request) {
 	var mode, search, sortBy, location, environment, severity, status, category, code, description, hostname string
 
+	var lifecycle filter.AlertLifecycle
+
 	var sortDesc bool
 
 	var pageNumber, pageSize int
@@ -79,7 +82,7 @@ func (ctrl *APIController) SearchAlerts(w http.ResponseWriter, r *http.Request)
 	}
 
 	status = r.URL.Query().Get("status")
-	if status != "" && status != model.AlertStatusNew && status != model.AlertStatusAck && status != model.AlertStatusDismissed {
+	if status != "" && status != model.AlertStatusNew && status != model.AlertStatusAck && status != model.AlertStatusDismissed && status != model.AlertStatusResolved {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, utils.NewError(errors.New("invalid status"), "Invalid  status"))
 		return
 	}
@@ -92,6 +95,12 @@ func (ctrl *APIController) SearchAlerts(w http.ResponseWriter, r *http.Request)
 
 	hostname = r.URL.Query().Get("hostname")
 
+	lifecycle.Assignee = r.URL.Query().Get("assignee")
+
+	lifecycle.ResolutionReason = r.URL.Query().Get("resolution-reason")
+
+	lifecycle.Comment = r.URL.Query().Get("comment")
+
 	if from, err = utils.Str2time(r.URL.Query().Get("from"), utils.MIN_TIME); err != nil {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
 		return
@@ -136,10 +145,10 @@ func (ctrl *APIController) SearchAlerts(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		ctrl.searchAlertsXLSX(w, r, status, from, to, *filter)
+		ctrl.searchAlertsXLSX(w, r, status, from, to, *filter, lifecycle)
 
 	default:
-		ctrl.searchAlertsJSON(w, r, mode, search, sortBy, sortDesc, pageNumber, pageSize, location, environment, severity, status, category, code, description, hostname, from, to, olderThan, *filter)
+		ctrl.searchAlertsJSON(w, r, mode, search, sortBy, sortDesc, pageNumber, pageSize, location, environment, severity, status, category, code, description, hostname, from, to, olderThan, *filter, lifecycle)
 	}
 }
 
@@ -147,7 +156,7 @@ func (ctrl *APIController) SearchAlerts(w http.ResponseWriter, r *http.Request)
 func (ctrl *APIController) searchAlertsJSON(w http.ResponseWriter, r *http.Request,
 	mode string, search string, sortBy string, sortDesc bool, pageNumber int, pageSize int,
 	location, environment, severity, status, category, code, description, hostname string,
-	from time.Time, to time.Time, olderThan time.Time, globalFilter dto.GlobalFilter) {
+	from time.Time, to time.Time, olderThan time.Time, globalFilter dto.GlobalFilter, lifecycle filter.AlertLifecycle) {
 	filters := filter.New()
 	filters.Page = pageNumber
 
@@ -173,6 +182,8 @@ func (ctrl *APIController) searchAlertsJSON(w http.ResponseWriter, r *http.Reque
 			To:          to,
 			OlderThan:   olderThan,
 			Filter:      filters,
+
+			AlertLifecycle: lifecycle,
 		})
 		if err != nil {
 			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
@@ -181,7 +192,7 @@ func (ctrl *APIController) searchAlertsJSON(w http.ResponseWriter, r *http.Reque
 
 		utils.WriteJSONResponse(w, http.StatusOK, response)
 	} else if pageSize == 0 {
-		response, err := ctrl.Service.GetAlerts(status, from, to, globalFilter)
+		response, err := ctrl.Service.GetAlerts(status, from, to, globalFilter, lifecycle)
 		if err != nil {
 			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
 			return
@@ -195,8 +206,8 @@ func (ctrl *APIController) searchAlertsJSON(w http.ResponseWriter, r *http.Reque
 }
 
 // searchAlertsXLSX search alerts using the filters in the request returning it in XLSX format
-func (ctrl *APIController) searchAlertsXLSX(w http.ResponseWriter, r *http.Request, status string, from time.Time, to time.Time, filter dto.GlobalFilter) {
-	xlsx, err := ctrl.Service.SearchAlertsAsXLSX(status, from, to, filter)
+func (ctrl *APIController) searchAlertsXLSX(w http.ResponseWriter, r *http.Request, status string, from time.Time, to time.Time, filter dto.GlobalFilter, lifecycle filter.AlertLifecycle) {
+	xlsx, err := ctrl.Service.SearchAlertsAsXLSX(status, from, to, filter, lifecycle)
 	if err != nil {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
 		return
@@ -241,7 +252,7 @@ func (ctrl *APIController) AckAlerts(w http.ResponseWriter, r *http.Request) {
 		filter.IDs = body.Ids
 	}
 
-	err := ctrl.Service.AckAlerts(filter)
+	err := ctrl.Service.AckAlerts(filter, requestUsername(r))
 	if errors.Is(err, utils.ErrAlertNotFound) {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
 	} else if errors.Is(err, utils.ErrInvalidAck) {
@@ -253,3 +264,80 @@ func (ctrl *APIController) AckAlerts(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// AssignAlert set the assignee of the alert in the request
+func (ctrl *APIController) AssignAlert(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Assignee string `json:"assignee"`
+	}{}
+
+	ctrl.changeAlert(w, r, &body, func(id primitive.ObjectID, username string) (*model.Alert, error) {
+		return ctrl.Service.AssignAlert(id, body.Assignee, username)
+	})
+}
+
+// CommentAlert add the comment in the request to the alert
+func (ctrl *APIController) CommentAlert(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Text string `json:"text"`
+	}{}
+
+	ctrl.changeAlert(w, r, &body, func(id primitive.ObjectID, username string) (*model.Alert, error) {
+		return ctrl.Service.CommentAlert(id, body.Text, username)
+	})
+}
+
+// ResolveAlert resolve the alert for the reason in the request
+func (ctrl *APIController) ResolveAlert(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Reason string `json:"reason"`
+	}{}
+
+	ctrl.changeAlert(w, r, &body, func(id primitive.ObjectID, username string) (*model.Alert, error) {
+		return ctrl.Service.ResolveAlert(id, body.Reason, username)
+	})
+}
+
+// changeAlert decode the body of the request and apply the change to the alert in the path
+func (ctrl *APIController) changeAlert(w http.ResponseWriter, r *http.Request, body interface{},
+	change func(id primitive.ObjectID, username string) (*model.Alert, error)) {
+	if ctrl.Config.APIService.ReadOnly {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusForbidden, utils.NewError(errors.New("The API is disabled because the service is put in read-only mode"), "FORBIDDEN_REQUEST"))
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, utils.NewError(err, http.StatusText(http.StatusUnprocessableEntity)))
+		return
+	}
+
+	if err := utils.Decode(r.Body, body); err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	}
+
+	alert, err := change(id, requestUsername(r))
+
+	switch {
+	case errors.Is(err, utils.ErrAlertNotFound):
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
+	case errors.Is(err, utils.ErrInvalidAlertComment), errors.Is(err, utils.ErrInvalidAlertResolution):
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+	case errors.Is(err, utils.ErrAlertAlreadyResolved):
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusConflict, err)
+	case err != nil:
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+	default:
+		utils.WriteJSONResponse(w, http.StatusOK, alert)
+	}
+}
+
+// requestUsername return the username of the user that sent the request, empty if it isn't authenticated
+func requestUsername(r *http.Request) string {
+	if user, ok := context.Get(r, "user").(model.User); ok {
+		return user.Username
+	}
+
+	return ""
+}
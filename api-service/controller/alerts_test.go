@@ -25,6 +25,8 @@ import (
 	alertFilter "github.com/ercole-io/ercole/v2/api-service/dto/filter"
 
 	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -337,7 +339,7 @@ func TestSearchAlertsAsXLSX_Success(t *testing.T) {
 	xlsx := excelize.File{}
 
 	as.EXPECT().
-		SearchAlertsAsXLSX("NEW", from, to, filter).
+		SearchAlertsAsXLSX("NEW", from, to, filter, alertFilter.AlertLifecycle{}).
 		Return(&xlsx, nil)
 
 	rr := httptest.NewRecorder()
@@ -561,7 +563,7 @@ func TestSearchAlertsXLSX_InternalServerError(t *testing.T) {
 		Return(locations, nil)
 
 	as.EXPECT().
-		SearchAlertsAsXLSX("NEW", from, to, filter).
+		SearchAlertsAsXLSX("NEW", from, to, filter, alertFilter.AlertLifecycle{}).
 		Return(nil, aerrMock)
 
 	rr := httptest.NewRecorder()
@@ -651,7 +653,7 @@ func TestAckAlerts_FailNotFound(t *testing.T) {
 		IDs: []primitive.ObjectID{utils.Str2oid("5dc3f534db7e81a98b726a52")},
 	}
 
-	as.EXPECT().AckAlerts(a).
+	as.EXPECT().AckAlerts(a, "").
 		Return(utils.ErrAlertNotFound)
 
 	rr := httptest.NewRecorder()
@@ -688,7 +690,7 @@ func TestAckAlerts_FailInternalServerError(t *testing.T) {
 		IDs: []primitive.ObjectID{utils.Str2oid("5dc3f534db7e81a98b726a52")},
 	}
 
-	as.EXPECT().AckAlerts(a).
+	as.EXPECT().AckAlerts(a, "").
 		Return(aerrMock)
 
 	rr := httptest.NewRecorder()
@@ -732,7 +734,7 @@ func TestAckAlerts_Success(t *testing.T) {
 				"host": "pippo",
 			},
 		}
-		as.EXPECT().AckAlerts(a).Return(nil)
+		as.EXPECT().AckAlerts(a, "usertest").Return(nil)
 
 		rr := httptest.NewRecorder()
 		handler := http.HandlerFunc(ac.AckAlerts)
@@ -743,9 +745,196 @@ func TestAckAlerts_Success(t *testing.T) {
 		}
 		req, err := http.NewRequest("POST", "/alerts/acks", bytes.NewReader([]byte(utils.ToJSON(body))))
 		require.NoError(t, err)
+		context.Set(req, "user", model.User{Username: "usertest"})
 
 		handler.ServeHTTP(rr, req)
 
 		require.Equal(t, http.StatusNoContent, rr.Code)
 	})
 }
+
+func TestAssignAlert_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+	alert := model.Alert{ID: id, Assignee: "rossi"}
+
+	as.EXPECT().AssignAlert(id, "rossi", "admin").Return(&alert, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.AssignAlert)
+	req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/assign", bytes.NewReader([]byte(`{"assignee": "rossi"}`)))
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+	context.Set(req, "user", model.User{Username: "admin"})
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(alert), rr.Body.String())
+}
+
+func TestAssignAlert_Fail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+
+	t.Run("Invalid id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/foobar/assign", bytes.NewReader([]byte(`{"assignee": "rossi"}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "foobar"})
+
+		http.HandlerFunc(ac.AssignAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Invalid body", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/assign", bytes.NewReader([]byte(`{"foo": "bar"}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+
+		http.HandlerFunc(ac.AssignAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().AssignAlert(id, "rossi", "").Return(nil, utils.ErrAlertNotFound)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/assign", bytes.NewReader([]byte(`{"assignee": "rossi"}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+
+		http.HandlerFunc(ac.AssignAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Read only", func(t *testing.T) {
+		ac := ac
+		ac.Config.APIService.ReadOnly = true
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/assign", bytes.NewReader([]byte(`{"assignee": "rossi"}`)))
+		require.NoError(t, err)
+
+		http.HandlerFunc(ac.AssignAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestCommentAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+
+	t.Run("Success", func(t *testing.T) {
+		alert := model.Alert{ID: id, Comments: []model.AlertComment{{Username: "admin", Text: "contract requested"}}}
+		as.EXPECT().CommentAlert(id, "contract requested", "admin").Return(&alert, nil)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/comments", bytes.NewReader([]byte(`{"text": "contract requested"}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+		context.Set(req, "user", model.User{Username: "admin"})
+
+		http.HandlerFunc(ac.CommentAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(alert), rr.Body.String())
+	})
+
+	t.Run("Invalid comment", func(t *testing.T) {
+		as.EXPECT().CommentAlert(id, "", "").Return(nil, utils.ErrInvalidAlertComment)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/comments", bytes.NewReader([]byte(`{"text": ""}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+
+		http.HandlerFunc(ac.CommentAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestResolveAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+
+	t.Run("Success", func(t *testing.T) {
+		alert := model.Alert{ID: id, AlertStatus: model.AlertStatusResolved, ResolutionReason: "licenses bought"}
+		as.EXPECT().ResolveAlert(id, "licenses bought", "admin").Return(&alert, nil)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/resolve", bytes.NewReader([]byte(`{"reason": "licenses bought"}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+		context.Set(req, "user", model.User{Username: "admin"})
+
+		http.HandlerFunc(ac.ResolveAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(alert), rr.Body.String())
+	})
+
+	t.Run("Already resolved", func(t *testing.T) {
+		as.EXPECT().ResolveAlert(id, "licenses bought", "").Return(nil, utils.ErrAlertAlreadyResolved)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/resolve", bytes.NewReader([]byte(`{"reason": "licenses bought"}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+
+		http.HandlerFunc(ac.ResolveAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Invalid resolution", func(t *testing.T) {
+		as.EXPECT().ResolveAlert(id, "", "").Return(nil, utils.ErrInvalidAlertResolution)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/resolve", bytes.NewReader([]byte(`{"reason": ""}`)))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+
+		http.HandlerFunc(ac.ResolveAlert).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// ListAPITokens return the API tokens of the user in the username query parameter, or all of them
+func (ctrl *APIController) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := ctrl.Service.ListAPITokens(r.URL.Query().Get("username"))
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"apiTokens": tokens,
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// CreateAPIToken create an API token, owned by the requesting user if the username isn't specified.
+// The value of the token is returned only in this response
+func (ctrl *APIController) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var token model.APIToken
+
+	if err := utils.Decode(r.Body, &token); err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	}
+
+	if token.Username == "" {
+		if user, ok := context.Get(r, "user").(model.User); ok {
+			token.Username = user.Username
+		}
+	}
+
+	res, value, err := ctrl.Service.CreateAPIToken(token)
+	if errors.Is(err, utils.ErrInvalidAPIToken) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"apiToken": res,
+		"token":    value,
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, response)
+}
+
+func (ctrl *APIController) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, utils.NewError(err, http.StatusText(http.StatusUnprocessableEntity)))
+		return
+	}
+
+	err = ctrl.Service.RevokeAPIToken(id)
+	if errors.Is(err, utils.ErrAPITokenNotFound) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *APIController) ListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	serviceAccounts, err := ctrl.Service.ListServiceAccounts()
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, dto.ToUsers(serviceAccounts))
+}
+
+func (ctrl *APIController) AddServiceAccount(w http.ResponseWriter, r *http.Request) {
+	var user model.User
+
+	if err := utils.Decode(r.Body, &user); err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := ctrl.Service.AddServiceAccount(user)
+	if errors.Is(err, utils.ErrInvalidServiceAccount) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, dto.ToUser(res))
+}
+
+func (ctrl *APIController) RemoveServiceAccount(w http.ResponseWriter, r *http.Request) {
+	err := ctrl.Service.RemoveServiceAccount(mux.Vars(r)["username"])
+	if errors.Is(err, utils.ErrInvalidUser) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, utils.ErrInvalidServiceAccount) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestCreateAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	token := model.APIToken{
+		Name:       "inventory sync",
+		Locations:  []string{"Italy"},
+		Permission: model.ReadPermission,
+		ExpiresAt:  utils.P("2020-11-05T14:02:03Z"),
+	}
+
+	t.Run("Success, owned by the requesting user", func(t *testing.T) {
+		owned := token
+		owned.Username = "alice"
+
+		expected := owned
+		expected.ID = utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")
+		expected.Hint = "ercpat_AbCd"
+
+		as.EXPECT().CreateAPIToken(owned).Return(&expected, "ercpat_AbCdEf", nil)
+
+		body, err := json.Marshal(token)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "", bytes.NewReader(body))
+		require.NoError(t, err)
+		context.Set(req, "user", model.User{Username: "alice", Groups: []string{model.GroupAdmin}})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.CreateAPIToken).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(map[string]interface{}{
+			"apiToken": expected,
+			"token":    "ercpat_AbCdEf",
+		}), rr.Body.String())
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		owned := token
+		owned.Username = "automation"
+
+		as.EXPECT().CreateAPIToken(owned).Return(nil, "", utils.ErrInvalidAPIToken)
+
+		body, err := json.Marshal(owned)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.CreateAPIToken).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		as.EXPECT().RevokeAPIToken(utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")).Return(nil)
+
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RevokeAPIToken).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().RevokeAPIToken(utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")).Return(utils.ErrAPITokenNotFound)
+
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RevokeAPIToken).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "invalid"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RevokeAPIToken).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestAddServiceAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		user := model.User{Username: "automation", Groups: []string{"inventory"}}
+		expected := model.User{Username: "automation", Groups: []string{"inventory"}, ServiceAccount: true}
+
+		as.EXPECT().AddServiceAccount(user).Return(&expected, nil)
+
+		req, err := http.NewRequest("POST", "", bytes.NewReader([]byte(`{"username": "automation", "groups": ["inventory"]}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.AddServiceAccount).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{"username": "automation", "firstName": "", "lastName": "", "groups": ["inventory"], "serviceAccount": true}`, rr.Body.String())
+	})
+
+	t.Run("Invalid service account", func(t *testing.T) {
+		as.EXPECT().AddServiceAccount(model.User{Username: "alice"}).Return(nil, utils.ErrInvalidServiceAccount)
+
+		req, err := http.NewRequest("POST", "", bytes.NewReader([]byte(`{"username": "alice"}`)))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.AddServiceAccount).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRemoveServiceAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "Success", err: nil, expected: http.StatusNoContent},
+		{name: "Not found", err: utils.ErrInvalidUser, expected: http.StatusNotFound},
+		{name: "Not a service account", err: utils.ErrInvalidServiceAccount, expected: http.StatusBadRequest},
+		{name: "Error", err: aerrMock, expected: http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			as.EXPECT().RemoveServiceAccount("automation").Return(tc.err)
+
+			req, err := http.NewRequest("DELETE", "", nil)
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"username": "automation"})
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(ac.RemoveServiceAccount).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expected, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,540 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang/gddo/httputil"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
+	"github.com/ercole-io/ercole/v2/api-service/domain"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// SearchAuditEntries search the audit log using the filters in the request, returning the entries as JSON or XLSX
+func (ctrl *APIController) SearchAuditEntries(w http.ResponseWriter, r *http.Request) {
+	filter, err := dto.GetAuditFilter(r)
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	contentType := httputil.NegotiateContentType(r, []string{"application/json", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, "application/json")
+
+	switch contentType {
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		xlsx, err := ctrl.Service.SearchAuditEntriesAsXLSX(*filter)
+		if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+			return
+		}
+
+		utils.WriteXLSXResponse(w, xlsx)
+	default:
+		entries, err := ctrl.Service.SearchAuditEntries(*filter)
+		if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+			return
+		}
+
+		utils.WriteJSONResponse(w, http.StatusOK, entries)
+	}
+}
+
+// auditSnapshots return the functions that load the entities modified by the routes,
+// so the audit log records what they changed. The other routes record the body of the request:
+// the ones adding entities, the ones modifying many entities at once (es. POST /licenses/ignore, POST /alerts/ack,
+// POST /contracts/{databaseType}/upload) and the ones whose entities can't be read back (es. the alerts, the api tokens)
+func (ctrl *APIController) auditSnapshots() map[string]middleware.AuditSnapshot {
+	return map[string]middleware.AuditSnapshot{
+		"POST /configuration": ctrl.configAuditSnapshot,
+
+		"PUT /groups/{name}":    ctrl.groupAuditSnapshot,
+		"DELETE /groups/{name}": ctrl.groupAuditSnapshot,
+
+		"DELETE /hosts/{hostname}": ctrl.hostAuditSnapshot,
+		"PUT /hosts/{hostname}/technologies/oracle/databases/{dbname}/licenses/{licenseTypeID}/ignored/{ignored}": ctrl.oracleDatabaseLicenseAuditSnapshot,
+		"PUT /hosts/{hostname}/technologies/oracle/missing-dbs/{dbname}/ignored/{ignored}":                        ctrl.oracleMissingDatabaseAuditSnapshot,
+		"PUT /hosts/{hostname}/technologies/mysql/databases/{dbname}/ignored/{ignored}":                           ctrl.mySQLInstanceAuditSnapshot,
+		"PUT /hosts/{hostname}/technologies/microsoft/databases/{dbname}/ignored/{ignored}":                       ctrl.sqlServerInstanceAuditSnapshot,
+
+		"PATCH /exadata/{rackID}/hide": ctrl.exadataAuditSnapshot,
+		"PATCH /exadata/{rackID}/show": ctrl.exadataAuditSnapshot,
+
+		"PUT /contracts/oracle/database":                          ctrl.oracleDatabaseContractAuditSnapshot,
+		"DELETE /contracts/oracle/database/{id}":                  ctrl.oracleDatabaseContractAuditSnapshot,
+		"POST /contracts/oracle/database/{id}/hosts":              ctrl.oracleDatabaseContractAuditSnapshot,
+		"DELETE /contracts/oracle/database/{id}/hosts/{hostname}": ctrl.oracleDatabaseContractAuditSnapshot,
+		"PUT /contracts/mysql/database/{id}":                      ctrl.mySQLContractAuditSnapshot,
+		"DELETE /contracts/mysql/database/{id}":                   ctrl.mySQLContractAuditSnapshot,
+		"PUT /contracts/microsoft/database":                       ctrl.sqlServerContractAuditSnapshot,
+		"DELETE /contracts/microsoft/database/{id}":               ctrl.sqlServerContractAuditSnapshot,
+
+		"PUT /oracle/database/license-types/{id}":    ctrl.oracleDatabaseLicenseTypeAuditSnapshot,
+		"DELETE /oracle/database/license-types/{id}": ctrl.oracleDatabaseLicenseTypeAuditSnapshot,
+		"PUT /oracle/database/core-factors/{id}":     ctrl.coreFactorAuditSnapshot,
+		"DELETE /oracle/database/core-factors/{id}":  ctrl.coreFactorAuditSnapshot,
+
+		"PUT /maintenance-windows/{id}":    ctrl.maintenanceWindowAuditSnapshot,
+		"DELETE /maintenance-windows/{id}": ctrl.maintenanceWindowAuditSnapshot,
+		"DELETE /scenarios/{id}":           ctrl.scenarioAuditSnapshot,
+
+		"PUT /users/{username}":                     ctrl.userAuditSnapshot,
+		"DELETE /users/{username}":                  ctrl.userAuditSnapshot,
+		"PUT /roles/{roleName}":                     ctrl.roleAuditSnapshot,
+		"DELETE /roles/{roleName}":                  ctrl.roleAuditSnapshot,
+		"PUT /alert-routing-rules/{id}":             ctrl.alertRoutingRuleAuditSnapshot,
+		"DELETE /alert-routing-rules/{id}":          ctrl.alertRoutingRuleAuditSnapshot,
+		"PUT /report-subscriptions/{id}":            ctrl.reportSubscriptionAuditSnapshot,
+		"DELETE /report-subscriptions/{id}":         ctrl.reportSubscriptionAuditSnapshot,
+		"DELETE /service-accounts/{username}":       ctrl.serviceAccountAuditSnapshot,
+		"POST /agent-credentials/{hostname}/rotate": ctrl.agentCredentialAuditSnapshot,
+		"DELETE /agent-credentials/{hostname}":      ctrl.agentCredentialAuditSnapshot,
+
+		"PUT /nodes/{name}":    ctrl.nodeAuditSnapshot,
+		"DELETE /nodes/{name}": ctrl.nodeAuditSnapshot,
+	}
+}
+
+// auditHost return the current host, nil if it doesn't exist or it has been dismissed
+func (ctrl *APIController) auditHost(hostname string) (*dto.HostData, error) {
+	host, err := ctrl.Service.GetHost(hostname, utils.MAX_TIME, false)
+	if errors.Is(err, utils.ErrHostNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return host, nil
+}
+
+func (ctrl *APIController) hostAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	host, err := ctrl.auditHost(mux.Vars(r)["hostname"])
+	if host == nil || err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"hostname":    host.Hostname,
+		"location":    host.Location,
+		"environment": host.Environment,
+		"archived":    host.Archived,
+	}, nil
+}
+
+func (ctrl *APIController) oracleDatabaseLicenseAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	host, err := ctrl.auditHost(mux.Vars(r)["hostname"])
+	if host == nil || err != nil {
+		return nil, err
+	}
+
+	if host.Features.Oracle == nil || host.Features.Oracle.Database == nil {
+		return nil, nil
+	}
+
+	for _, db := range host.Features.Oracle.Database.Databases {
+		if db.Name != mux.Vars(r)["dbname"] {
+			continue
+		}
+
+		for _, license := range db.Licenses {
+			if license.LicenseTypeID == mux.Vars(r)["licenseTypeID"] {
+				return ignoredAuditSnapshot(license.Ignored, license.IgnoredComment), nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) mySQLInstanceAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	host, err := ctrl.auditHost(mux.Vars(r)["hostname"])
+	if host == nil || err != nil {
+		return nil, err
+	}
+
+	if host.Features.MySQL == nil {
+		return nil, nil
+	}
+
+	for _, instance := range host.Features.MySQL.Instances {
+		if instance.Name == mux.Vars(r)["dbname"] {
+			return ignoredAuditSnapshot(instance.License.Ignored, instance.License.IgnoredComment), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) sqlServerInstanceAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	host, err := ctrl.auditHost(mux.Vars(r)["hostname"])
+	if host == nil || err != nil {
+		return nil, err
+	}
+
+	if host.Features.Microsoft == nil || host.Features.Microsoft.SQLServer == nil {
+		return nil, nil
+	}
+
+	for _, instance := range host.Features.Microsoft.SQLServer.Instances {
+		if instance.Name == mux.Vars(r)["dbname"] {
+			return ignoredAuditSnapshot(instance.License.Ignored, instance.License.IgnoredComment), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) oracleMissingDatabaseAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	host, err := ctrl.auditHost(mux.Vars(r)["hostname"])
+	if host == nil || err != nil {
+		return nil, err
+	}
+
+	if host.Features.Oracle == nil || host.Features.Oracle.Database == nil {
+		return nil, nil
+	}
+
+	for _, db := range host.Features.Oracle.Database.MissingDatabases {
+		if db.Name == mux.Vars(r)["dbname"] {
+			return ignoredAuditSnapshot(db.Ignored, db.IgnoredComment), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func ignoredAuditSnapshot(ignored bool, ignoredComment string) map[string]interface{} {
+	return map[string]interface{}{
+		"ignored":        ignored,
+		"ignoredComment": ignoredComment,
+	}
+}
+
+// auditExadata return the exadata, shown or hidden, nil if it doesn't exist
+func (ctrl *APIController) auditExadata(rackID string) (*domain.OracleExadataInstance, bool, error) {
+	for _, hidden := range []bool{false, true} {
+		instance, err := ctrl.Service.GetExadataInstance(rackID, hidden)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		} else if err != nil {
+			return nil, false, err
+		}
+
+		return instance, hidden, nil
+	}
+
+	return nil, false, nil
+}
+
+func (ctrl *APIController) exadataAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	instance, hidden, err := ctrl.auditExadata(mux.Vars(r)["rackID"])
+	if instance == nil || err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"rackID": mux.Vars(r)["rackID"],
+		"hidden": hidden,
+	}, nil
+}
+
+// auditOracleDatabaseContract return the contract modified by the request, nil if it doesn't exist
+func (ctrl *APIController) auditOracleDatabaseContract(r *http.Request, body []byte) (*dto.OracleDatabaseContractFE, error) {
+	id := auditEntityID(r, body)
+
+	contracts, err := ctrl.Service.GetOracleDatabaseContracts(dto.NewGetOracleDatabaseContractsFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contracts {
+		if contracts[i].ID.Hex() == id {
+			return &contracts[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) oracleDatabaseContractAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	contract, err := ctrl.auditOracleDatabaseContract(r, body)
+	if contract == nil || err != nil {
+		return nil, err
+	}
+
+	return *contract, nil
+}
+
+// auditMySQLContract return the contract modified by the request, nil if it doesn't exist
+func (ctrl *APIController) auditMySQLContract(r *http.Request, body []byte) (*model.MySQLContract, error) {
+	id := auditEntityID(r, body)
+
+	contracts, err := ctrl.Service.GetMySQLContracts(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contracts {
+		if contracts[i].ID.Hex() == id {
+			return &contracts[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) mySQLContractAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	contract, err := ctrl.auditMySQLContract(r, body)
+	if contract == nil || err != nil {
+		return nil, err
+	}
+
+	return *contract, nil
+}
+
+// auditSqlServerContract return the contract modified by the request, nil if it doesn't exist
+func (ctrl *APIController) auditSqlServerContract(r *http.Request, body []byte) (*model.SqlServerDatabaseContract, error) {
+	id := auditEntityID(r, body)
+
+	contracts, err := ctrl.Service.GetSqlServerDatabaseContracts(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contracts {
+		if contracts[i].ID.Hex() == id {
+			return &contracts[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) sqlServerContractAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	contract, err := ctrl.auditSqlServerContract(r, body)
+	if contract == nil || err != nil {
+		return nil, err
+	}
+
+	return *contract, nil
+}
+
+func (ctrl *APIController) oracleDatabaseLicenseTypeAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	licenseTypes, err := ctrl.Service.GetOracleDatabaseLicenseTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, licenseType := range licenseTypes {
+		if licenseType.ID == mux.Vars(r)["id"] {
+			return licenseType, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) coreFactorAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	coreFactors, err := ctrl.Service.GetCoreFactors()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, coreFactor := range coreFactors {
+		if coreFactor.ID.Hex() == mux.Vars(r)["id"] {
+			return coreFactor, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) configAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	return ctrl.Service.GetConfig()
+}
+
+func (ctrl *APIController) groupAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	group, err := ctrl.Service.GetGroup(mux.Vars(r)["name"])
+	if errors.Is(err, utils.ErrGroupNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (ctrl *APIController) maintenanceWindowAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, nil
+	}
+
+	window, err := ctrl.Service.GetMaintenanceWindow(id)
+	if errors.Is(err, utils.ErrMaintenanceWindowNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return window, nil
+}
+
+func (ctrl *APIController) scenarioAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, nil
+	}
+
+	scenario, err := ctrl.Service.GetScenario(id)
+	if errors.Is(err, utils.ErrScenarioNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return scenario, nil
+}
+
+func (ctrl *APIController) userAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	user, err := ctrl.Service.GetUser(mux.Vars(r)["username"])
+	if errors.Is(err, utils.ErrInvalidUser) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"username":  user.Username,
+		"firstName": user.FirstName,
+		"lastName":  user.LastName,
+		"groups":    user.Groups,
+	}, nil
+}
+
+func (ctrl *APIController) roleAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	role, err := ctrl.Service.GetRole(mux.Vars(r)["roleName"])
+	if errors.Is(err, utils.ErrRoleNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (ctrl *APIController) alertRoutingRuleAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, nil
+	}
+
+	rule, err := ctrl.Service.GetAlertRoutingRule(id)
+	if errors.Is(err, utils.ErrAlertRoutingRuleNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (ctrl *APIController) reportSubscriptionAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, nil
+	}
+
+	subscription, err := ctrl.Service.GetReportSubscription(id)
+	if errors.Is(err, utils.ErrReportSubscriptionNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (ctrl *APIController) serviceAccountAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	accounts, err := ctrl.Service.ListServiceAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		if account.Username == mux.Vars(r)["username"] {
+			return map[string]interface{}{
+				"username": account.Username,
+				"groups":   account.Groups,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) agentCredentialAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	credentials, err := ctrl.Service.ListAgentCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, credential := range credentials {
+		if strings.EqualFold(credential.Hostname, mux.Vars(r)["hostname"]) {
+			return credential, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *APIController) nodeAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	node, err := ctrl.Service.GetNode(mux.Vars(r)["name"])
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// auditEntityID return the id of the modified entity, from the route or from the body of the request
+func auditEntityID(r *http.Request, body []byte) string {
+	if id, ok := mux.Vars(r)["id"]; ok {
+		return id
+	}
+
+	var entity struct {
+		ID string `json:"id"`
+	}
+
+	if err := json.Unmarshal(body, &entity); err != nil {
+		return ""
+	}
+
+	return entity.ID
+}
@@ -0,0 +1,364 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/api-service/domain"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestSearchAuditEntries_JSON(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	filter := dto.AuditFilter{
+		Username:   "admin",
+		Route:      "/hosts/{hostname}",
+		From:       utils.P("2020-06-10T11:54:59Z"),
+		To:         utils.MAX_TIME,
+		PageNumber: 1,
+		PageSize:   10,
+	}
+	res := dto.AuditEntriesResponse{
+		Content: []model.AuditEntry{
+			{
+				ID:       utils.Str2oid("5dc3f534db7e81a98b726a52"),
+				Date:     utils.P("2020-06-11T11:54:59Z"),
+				Service:  model.AuditServiceAPI,
+				Username: "admin",
+				Method:   "DELETE",
+				Route:    "/hosts/{hostname}",
+				Path:     "/hosts/foobar",
+				Target:   map[string]string{"hostname": "foobar"},
+				Status:   http.StatusNoContent,
+				Changes:  []model.AuditChange{},
+			},
+		},
+		Metadata: dto.PagingMetadata{Empty: false, First: false, Last: true, Number: 1, Size: 10, TotalElements: 11, TotalPages: 2},
+	}
+
+	as.EXPECT().SearchAuditEntries(filter).Return(&res, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.SearchAuditEntries)
+	req, err := http.NewRequest("GET", "/admin/audit?username=admin&route=%2Fhosts%2F%7Bhostname%7D&from=2020-06-10T11%3A54%3A59Z&page=1&size=10", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(res), rr.Body.String())
+}
+
+func TestSearchAuditEntries_XLSX(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	filter := dto.AuditFilter{
+		Service: model.AuditServiceThunder,
+		From:    utils.MIN_TIME,
+		To:      utils.MAX_TIME,
+	}
+	xlsx := excelize.File{}
+
+	as.EXPECT().SearchAuditEntriesAsXLSX(filter).Return(&xlsx, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.SearchAuditEntries)
+	req, err := http.NewRequest("GET", "/admin/audit?service=thunder-service", nil)
+	require.NoError(t, err)
+
+	req.Header.Add("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	_, err = excelize.OpenReader(rr.Body)
+	require.NoError(t, err)
+}
+
+func TestSearchAuditEntries_UnprocessableEntity(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	for _, query := range []string{"from=yesterday", "to=tomorrow", "page=-1", "size=abc"} {
+		t.Run(query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(ac.SearchAuditEntries)
+			req, err := http.NewRequest("GET", "/admin/audit?"+query, nil)
+			require.NoError(t, err)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		})
+	}
+}
+
+func TestSearchAuditEntries_InternalServerError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	as.EXPECT().SearchAuditEntries(gomock.Any()).Return(nil, aerrMock)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.SearchAuditEntries)
+	req, err := http.NewRequest("GET", "/admin/audit", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestAudit_HideExadata(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "admin", Groups: []string{model.GroupAdmin}}
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+	handler := ac.GetApiControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	gomock.InOrder(
+		as.EXPECT().GetExadataInstance("rack1", false).Return(&domain.OracleExadataInstance{}, nil),
+		as.EXPECT().HideExadataInstance("rack1").Return(nil),
+		as.EXPECT().GetExadataInstance("rack1", false).Return(nil, mongo.ErrNoDocuments),
+		as.EXPECT().GetExadataInstance("rack1", true).Return(&domain.OracleExadataInstance{}, nil),
+	)
+
+	var entry model.AuditEntry
+	as.EXPECT().InsertAuditEntry(gomock.Any()).
+		Do(func(e model.AuditEntry) { entry = e }).
+		Return(nil)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("PATCH", "/exadata/rack1/hide", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	assert.Equal(t, model.AuditServiceAPI, entry.Service)
+	assert.Equal(t, "admin", entry.Username)
+	assert.Equal(t, "fake", entry.AuthProvider)
+	assert.Equal(t, "PATCH", entry.Method)
+	assert.Equal(t, "/exadata/{rackID}/hide", entry.Route)
+	assert.Equal(t, "/exadata/rack1/hide", entry.Path)
+	assert.Equal(t, map[string]string{"rackID": "rack1"}, entry.Target)
+	assert.Equal(t, http.StatusNoContent, entry.Status)
+	assert.JSONEq(t, `{"rackID":"rack1","hidden":false}`, string(entry.Before))
+	assert.JSONEq(t, `{"rackID":"rack1","hidden":true}`, string(entry.After))
+	assert.Equal(t, []model.AuditChange{
+		{Field: "hidden", Before: json.RawMessage("false"), After: json.RawMessage("true")},
+	}, entry.Changes)
+}
+
+func TestAudit_RedactsSecrets(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "admin", Groups: []string{model.GroupAdmin}}
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+	handler := ac.GetApiControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	as.EXPECT().UpdatePassword("admin", "0ld", "N3w").Return(nil)
+	as.EXPECT().RemoveLimitedGroup(user).Return(nil)
+
+	var entry model.AuditEntry
+	as.EXPECT().InsertAuditEntry(gomock.Any()).
+		Do(func(e model.AuditEntry) { entry = e }).
+		Return(nil)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/users/admin/change-password", strings.NewReader(`{"oldPassword":"0ld","newPassword":"N3w","confirmedPassword":"N3w"}`))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	assert.Equal(t, "/users/{username}/change-password", entry.Route)
+	assert.Equal(t, map[string]string{"username": "admin"}, entry.Target)
+	assert.Equal(t, http.StatusNoContent, entry.Status)
+	assert.Nil(t, entry.Before)
+	assert.JSONEq(t, `{"oldPassword":"********","newPassword":"********","confirmedPassword":"********"}`, string(entry.After))
+	assert.NotContains(t, string(entry.After), "N3w")
+	assert.Empty(t, entry.Changes)
+}
+
+func TestHostAuditSnapshot(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest("DELETE", "/hosts/foobar", nil), map[string]string{"hostname": "foobar"})
+
+	t.Run("existing host", func(t *testing.T) {
+		as.EXPECT().GetHost("foobar", utils.MAX_TIME, false).
+			Return(&dto.HostData{Hostname: "foobar", Location: "Italy", Environment: "PRD"}, nil)
+
+		actual, err := ac.hostAuditSnapshot(req, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{
+			"hostname":    "foobar",
+			"location":    "Italy",
+			"environment": "PRD",
+			"archived":    false,
+		}, actual)
+	})
+
+	t.Run("dismissed host", func(t *testing.T) {
+		as.EXPECT().GetHost("foobar", utils.MAX_TIME, false).Return(nil, utils.ErrHostNotFound)
+
+		actual, err := ac.hostAuditSnapshot(req, nil)
+		require.NoError(t, err)
+
+		assert.Nil(t, actual)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		as.EXPECT().GetHost("foobar", utils.MAX_TIME, false).Return(nil, aerrMock)
+
+		_, err := ac.hostAuditSnapshot(req, nil)
+		assert.ErrorIs(t, err, aerrMock)
+	})
+}
+
+func TestMaintenanceWindowAuditSnapshot(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("5dd40bfb12f54dfda7b1c291")
+	req := mux.SetURLVars(httptest.NewRequest("PUT", "/maintenance-windows/"+id.Hex(), nil), map[string]string{"id": id.Hex()})
+
+	t.Run("existing window", func(t *testing.T) {
+		window := &model.MaintenanceWindow{ID: id, Name: "patching"}
+		as.EXPECT().GetMaintenanceWindow(id).Return(window, nil)
+
+		actual, err := ac.maintenanceWindowAuditSnapshot(req, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, window, actual)
+	})
+
+	t.Run("missing window", func(t *testing.T) {
+		as.EXPECT().GetMaintenanceWindow(id).Return(nil, utils.ErrMaintenanceWindowNotFound)
+
+		actual, err := ac.maintenanceWindowAuditSnapshot(req, nil)
+		require.NoError(t, err)
+
+		assert.Nil(t, actual)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		invalid := mux.SetURLVars(httptest.NewRequest("PUT", "/maintenance-windows/foo", nil), map[string]string{"id": "foo"})
+
+		actual, err := ac.maintenanceWindowAuditSnapshot(invalid, nil)
+		require.NoError(t, err)
+
+		assert.Nil(t, actual)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		as.EXPECT().GetMaintenanceWindow(id).Return(nil, aerrMock)
+
+		_, err := ac.maintenanceWindowAuditSnapshot(req, nil)
+		assert.ErrorIs(t, err, aerrMock)
+	})
+}
+
+func TestAuditEntityID(t *testing.T) {
+	fromRoute := mux.SetURLVars(httptest.NewRequest("DELETE", "/contracts/mysql/database/abc", nil), map[string]string{"id": "abc"})
+	assert.Equal(t, "abc", auditEntityID(fromRoute, nil))
+
+	fromBody := httptest.NewRequest("PUT", "/contracts/oracle/database", nil)
+	assert.Equal(t, "def", auditEntityID(fromBody, []byte(`{"id":"def","contractID":"123"}`)))
+	assert.Equal(t, "", auditEntityID(fromBody, []byte(`{`)))
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/golang/gddo/httputil"
+	"github.com/gorilla/context"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// GetClusterReconciliation return the inconsistencies between the clusters reported by the hypervisors,
+// the Veritas clusters and the hosts, in the locations of the user
+func (ctrl *APIController) GetClusterReconciliation(w http.ResponseWriter, r *http.Request) {
+	choice := httputil.NegotiateContentType(r, []string{"application/json", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, "application/json")
+
+	filter, err := dto.GetGlobalFilter(r)
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	// the requests authenticated with the Basic credentials, as the ones of the data-service, have no user
+	// and see all the locations
+	if user, ok := context.Get(r, "user").(model.User); ok && filter.Location == "" {
+		locations, errLocation := ctrl.Service.ListLocations(user)
+
+		if errLocation != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, errLocation)
+			return
+		}
+
+		filter.Location = strings.Join(locations, ",")
+	}
+
+	switch choice {
+	case "application/json":
+		res, err := ctrl.Service.GetClusterReconciliation(*filter)
+		if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+			return
+		}
+
+		utils.WriteJSONResponse(w, http.StatusOK, res)
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		res, err := ctrl.Service.GetClusterReconciliationAsXLSX(*filter)
+		if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+			return
+		}
+
+		utils.WriteXLSXResponse(w, res)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/gorilla/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestGetClusterReconciliation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config: config.Configuration{
+			ResourceFilePath: "../../resources",
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	t.Run("JSON in the locations of the user", func(t *testing.T) {
+		user := model.User{Username: "usertest"}
+
+		expected := &dto.ClusterReconciliation{
+			Issues: []dto.ClusterReconciliationIssue{
+				{Kind: dto.ClusterIssueUnknownVM, Hostname: "ghost", Clusters: []string{"Puzzait"}, Location: "Italy"},
+			},
+			Counts: map[string]int{dto.ClusterIssueUnknownVM: 1},
+		}
+
+		as.EXPECT().ListLocations(user).Return([]string{"Italy", "Germany"}, nil)
+		as.EXPECT().
+			GetClusterReconciliation(dto.GlobalFilter{Location: "Italy,Germany", OlderThan: utils.MAX_TIME}).
+			Return(expected, nil)
+
+		req, err := http.NewRequest("GET", "/hosts/cluster-reconciliation", nil)
+		require.NoError(t, err)
+		context.Set(req, "user", user)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetClusterReconciliation).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+	})
+
+	t.Run("JSON in all the locations with the Basic credentials", func(t *testing.T) {
+		expected := &dto.ClusterReconciliation{
+			Issues: []dto.ClusterReconciliationIssue{},
+			Counts: map[string]int{},
+		}
+
+		as.EXPECT().
+			GetClusterReconciliation(dto.GlobalFilter{OlderThan: utils.MAX_TIME}).
+			Return(expected, nil)
+
+		req, err := http.NewRequest("GET", "/hosts/cluster-reconciliation", nil)
+		require.NoError(t, err)
+		req.SetBasicAuth("user", "password")
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetClusterReconciliation).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		as.EXPECT().
+			GetClusterReconciliationAsXLSX(dto.GlobalFilter{Location: "Italy", Environment: "PRD", OlderThan: utils.MAX_TIME}).
+			Return(excelize.NewFile(), nil)
+
+		req, err := http.NewRequest("GET", "/hosts/cluster-reconciliation?location=Italy&environment=PRD", nil)
+		require.NoError(t, err)
+		req.Header.Add("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetClusterReconciliation).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		_, err = excelize.OpenReader(rr.Body)
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid older-than", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/hosts/cluster-reconciliation?older-than=foobar", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetClusterReconciliation).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Internal server error", func(t *testing.T) {
+		as.EXPECT().GetClusterReconciliation(gomock.Any()).Return(nil, aerrMock)
+
+		req, err := http.NewRequest("GET", "/hosts/cluster-reconciliation?location=Italy", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetClusterReconciliation).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/360EntSecGroup-Skylar/excelize"
 	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
 	"github.com/ercole-io/ercole/v2/api-service/dto"
 	"github.com/ercole-io/ercole/v2/api-service/service"
 	"github.com/ercole-io/ercole/v2/config"
@@ -128,6 +129,8 @@ type APIControllerInterface interface {
 	UpdateMissingDatabaseIgnoredField(w http.ResponseWriter, r *http.Request)
 
 	GetVirtualHostWithoutCluster(w http.ResponseWriter, r *http.Request)
+	// GetClusterReconciliation return the inconsistencies between the clusters and the hosts
+	GetClusterReconciliation(w http.ResponseWriter, r *http.Request)
 
 	// GetInfoForFrontendDashboard return all informations needed for the frontend dashboard page
 	GetInfoForFrontendDashboard(w http.ResponseWriter, r *http.Request)
@@ -177,6 +180,14 @@ type APIControllerInterface interface {
 	AddOracleDatabaseLicenseType(w http.ResponseWriter, r *http.Request)
 	// UpdateOracleDatabaseLicenseType update a licence type - Oracle/Database contract part
 	UpdateOracleDatabaseLicenseType(w http.ResponseWriter, r *http.Request)
+	// GetCoreFactors return the Oracle processor core factor table
+	GetCoreFactors(w http.ResponseWriter, r *http.Request)
+	// AddCoreFactor add a row to the Oracle processor core factor table
+	AddCoreFactor(w http.ResponseWriter, r *http.Request)
+	// UpdateCoreFactor update a row of the Oracle processor core factor table
+	UpdateCoreFactor(w http.ResponseWriter, r *http.Request)
+	// DeleteCoreFactor remove a row from the Oracle processor core factor table
+	DeleteCoreFactor(w http.ResponseWriter, r *http.Request)
 
 	ListOracleGrantDbaByHostname(w http.ResponseWriter, r *http.Request)
 	GetOracleGrantDbaJSON(hostname string, filters *dto.GlobalFilter) ([]dto.OracleGrantDbaDto, error)
@@ -283,5 +294,9 @@ func (ctrl *APIController) userHasAccessToLocation(r *http.Request, location str
 		return false
 	}
 
+	if token, ok := context.Get(r, "apiToken").(model.APIToken); ok && token.IsLocationScoped() {
+		locations = middleware.ScopeLocations(locations, token.Locations)
+	}
+
 	return utils.ContainsSomeI(locations, location, model.AllLocation)
 }
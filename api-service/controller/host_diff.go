@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// GetHostDiff return what changed on the host between the from and to dates
+func (ctrl *APIController) GetHostDiff(w http.ResponseWriter, r *http.Request) {
+	hostname := mux.Vars(r)["hostname"]
+
+	from, to, err := parseHostHistoryRange(r)
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	diff, err := ctrl.Service.GetHostDiff(hostname, from, to)
+	if err != nil {
+		ctrl.writeHostHistoryError(w, err)
+		return
+	}
+
+	if !ctrl.userHasAccessToLocation(r, diff.Location) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusForbidden, utils.ErrPermissionDenied)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, diff)
+}
+
+// GetHostTimeline return the changes of every snapshot of the host inserted between the from and to dates
+func (ctrl *APIController) GetHostTimeline(w http.ResponseWriter, r *http.Request) {
+	hostname := mux.Vars(r)["hostname"]
+
+	from, to, err := parseHostHistoryRange(r)
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	timeline, err := ctrl.Service.GetHostTimeline(hostname, from, to)
+	if err != nil {
+		ctrl.writeHostHistoryError(w, err)
+		return
+	}
+
+	if !ctrl.userHasAccessToLocation(r, timeline.Location) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusForbidden, utils.ErrPermissionDenied)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, timeline)
+}
+
+func parseHostHistoryRange(r *http.Request) (from, to time.Time, err error) {
+	if from, err = utils.Str2time(r.URL.Query().Get("from"), utils.MIN_TIME); err != nil {
+		return
+	}
+
+	to, err = utils.Str2time(r.URL.Query().Get("to"), utils.MAX_TIME)
+
+	return
+}
+
+func (ctrl *APIController) writeHostHistoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrInvalidHostHistoryRange):
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+	case errors.Is(err, utils.ErrHostNotFound):
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
+	default:
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestGetHostDiff(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	from := utils.P("2024-01-15T00:00:00Z")
+	to := utils.P("2024-02-15T00:00:00Z")
+
+	newRequest := func(t *testing.T, query string) *http.Request {
+		req, err := http.NewRequest("GET", "/hosts/vm01/diff?"+query, nil)
+		require.NoError(t, err)
+
+		return mux.SetURLVars(req, map[string]string{"hostname": "vm01"})
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		expected := dto.HostDiff{
+			Hostname:   "vm01",
+			Location:   "Italy",
+			From:       from,
+			To:         to,
+			ToSnapshot: utils.P("2024-02-01T10:00:00Z"),
+			Changes: []dto.HostChange{
+				{Category: dto.HostChangeCategoryHost, Action: dto.HostChangeChanged, Item: "cpuCores", Old: 2, New: 4},
+			},
+		}
+
+		var user interface{}
+		as.EXPECT().ListLocations(user).Return([]string{"Italy"}, nil)
+		as.EXPECT().GetHostDiff("vm01", from, to).Return(&expected, nil)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDiff).ServeHTTP(rr, newRequest(t, "from=2024-01-15T00%3A00%3A00Z&to=2024-02-15T00%3A00%3A00Z"))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+	})
+
+	t.Run("Forbidden location", func(t *testing.T) {
+		var user interface{}
+		as.EXPECT().ListLocations(user).Return([]string{"Germany"}, nil)
+		as.EXPECT().GetHostDiff("vm01", from, to).Return(&dto.HostDiff{Hostname: "vm01", Location: "Italy"}, nil)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDiff).ServeHTTP(rr, newRequest(t, "from=2024-01-15T00%3A00%3A00Z&to=2024-02-15T00%3A00%3A00Z"))
+
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		as.EXPECT().GetHostDiff("vm01", to, from).Return(nil, utils.ErrInvalidHostHistoryRange)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDiff).ServeHTTP(rr, newRequest(t, "from=2024-02-15T00%3A00%3A00Z&to=2024-01-15T00%3A00%3A00Z"))
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Host not found", func(t *testing.T) {
+		as.EXPECT().GetHostDiff("vm01", utils.MIN_TIME, utils.MAX_TIME).Return(nil, utils.ErrHostNotFound)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDiff).ServeHTTP(rr, newRequest(t, ""))
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Unparsable date", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDiff).ServeHTTP(rr, newRequest(t, "from=yesterday"))
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestGetHostTimeline(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	expected := dto.HostTimeline{
+		Hostname: "vm01",
+		Location: "Italy",
+		From:     utils.P("2024-01-15T00:00:00Z"),
+		To:       utils.MAX_TIME,
+		Entries: []dto.HostTimelineEntry{
+			{
+				Date: utils.P("2024-03-01T10:00:00Z"),
+				Changes: []dto.HostChange{
+					{Category: dto.HostChangeCategoryHost, Action: dto.HostChangeChanged, Item: "cpuCores", Old: 2, New: 4},
+				},
+			},
+		},
+	}
+
+	var user interface{}
+	as.EXPECT().ListLocations(user).Return([]string{"Italy"}, nil)
+	as.EXPECT().GetHostTimeline("vm01", utils.P("2024-01-15T00:00:00Z"), utils.MAX_TIME).Return(&expected, nil)
+
+	req, err := http.NewRequest("GET", "/hosts/vm01/timeline?from=2024-01-15T00%3A00%3A00Z", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"hostname": "vm01"})
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(ac.GetHostTimeline).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+}
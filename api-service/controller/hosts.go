@@ -308,7 +308,7 @@ func (ctrl *APIController) DismissHost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = ctrl.Service.DismissHost(hostname)
+	err = ctrl.Service.DismissHost(hostname, requestUsername(r))
 	if errors.Is(err, utils.ErrHostNotFound) {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
 		return
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/gorilla/context"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1130,12 +1131,12 @@ func TestDismissHost_Success(t *testing.T) {
 		GetHost("foobar", utils.MAX_TIME, true).
 		Return(&dto.HostData{Location: "Italy"}, nil)
 
-	var user interface{}
+	user := model.User{Username: "usertest"}
 	as.EXPECT().
 		ListLocations(user).
 		Return([]string{"Italy", "Germany", "France"}, nil)
 
-	as.EXPECT().DismissHost("foobar").Return(nil)
+	as.EXPECT().DismissHost("foobar", "usertest").Return(nil)
 
 	rr := httptest.NewRecorder()
 	handler := http.HandlerFunc(ac.DismissHost)
@@ -1144,6 +1145,7 @@ func TestDismissHost_Success(t *testing.T) {
 		"hostname": "foobar",
 	})
 	require.NoError(t, err)
+	context.Set(req, "user", user)
 
 	handler.ServeHTTP(rr, req)
 
@@ -1224,7 +1226,7 @@ func TestDismissHost_FailInternalServerError(t *testing.T) {
 		ListLocations(user).
 		Return([]string{"Italy", "Germany", "France"}, nil)
 
-	as.EXPECT().DismissHost("foobar").Return(aerrMock)
+	as.EXPECT().DismissHost("foobar", "").Return(aerrMock)
 
 	rr := httptest.NewRecorder()
 	handler := http.HandlerFunc(ac.DismissHost)
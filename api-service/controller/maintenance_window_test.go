@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestListMaintenanceWindows(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	windows := []model.MaintenanceWindow{
+		{
+			ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+			Name:      "patching",
+			Start:     utils.P("2024-03-01T20:00:00Z"),
+			End:       utils.P("2024-03-01T23:00:00Z"),
+			Hostnames: []string{"foobar"},
+		},
+	}
+	as.EXPECT().ListMaintenanceWindows().Return(windows, nil)
+
+	req, err := http.NewRequest("GET", "", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(ac.ListMaintenanceWindows).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(map[string]interface{}{"maintenanceWindows": windows}), rr.Body.String())
+}
+
+func TestUpdateMaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	window := model.MaintenanceWindow{
+		Name:      "patching",
+		Start:     utils.P("2024-03-01T20:00:00Z"),
+		End:       utils.P("2024-03-01T23:00:00Z"),
+		Locations: []string{"Italy"},
+	}
+	expected := window
+	expected.ID = utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")
+
+	t.Run("Success", func(t *testing.T) {
+		as.EXPECT().UpdateMaintenanceWindow(expected).Return(&expected, nil)
+
+		body, err := json.Marshal(window)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("PUT", "", bytes.NewReader(body))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.UpdateMaintenanceWindow).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		as.EXPECT().UpdateMaintenanceWindow(expected).Return(nil, utils.ErrInvalidMaintenanceWindow)
+
+		body, err := json.Marshal(window)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("PUT", "", bytes.NewReader(body))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.UpdateMaintenanceWindow).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().UpdateMaintenanceWindow(expected).Return(nil, utils.ErrMaintenanceWindowNotFound)
+
+		body, err := json.Marshal(window)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("PUT", "", bytes.NewReader(body))
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.UpdateMaintenanceWindow).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestGetCoreFactors_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	coreFactors := []model.CoreFactor{
+		{
+			ID:              utils.Str2oid("000000000000000000000001"),
+			Description:     "Amazon Web Services",
+			CloudMembership: model.CloudMembershipAws,
+			Factor:          1,
+		},
+	}
+
+	as.EXPECT().GetCoreFactors().Return(coreFactors, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.GetCoreFactors)
+	req, err := http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(map[string]interface{}{"coreFactors": coreFactors}), rr.Body.String())
+}
+
+func TestAddCoreFactor_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	request := model.CoreFactor{
+		Description:   "SPARC T4",
+		CPUModelRegex: "SPARC-T4",
+		Factor:        0.5,
+		Priority:      5,
+	}
+
+	result := request
+	result.ID = utils.Str2oid("000000000000000000000001")
+
+	as.EXPECT().AddCoreFactor(request).Return(&result, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.AddCoreFactor)
+	req, err := http.NewRequest("POST", "/", bytes.NewReader([]byte(utils.ToJSON(request))))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(result), rr.Body.String())
+}
+
+func TestAddCoreFactor_ReadOnly(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config: config.Configuration{
+			APIService: config.APIService{
+				ReadOnly: true,
+			},
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.AddCoreFactor)
+	req, err := http.NewRequest("POST", "/", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestAddCoreFactor_Invalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	request := model.CoreFactor{Description: "Empty"}
+
+	as.EXPECT().AddCoreFactor(request).Return(nil, utils.ErrInvalidCoreFactor)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.AddCoreFactor)
+	req, err := http.NewRequest("POST", "/", bytes.NewReader([]byte(utils.ToJSON(request))))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestUpdateCoreFactor_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	request := model.CoreFactor{
+		Description:     "Oracle Cloud Infrastructure",
+		CloudMembership: model.CloudMembershipOci,
+		Factor:          1,
+	}
+
+	expected := request
+	expected.ID = utils.Str2oid("000000000000000000000001")
+
+	as.EXPECT().UpdateCoreFactor(expected).Return(&expected, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.UpdateCoreFactor)
+	req, err := http.NewRequest("PUT", "/", bytes.NewReader([]byte(utils.ToJSON(request))))
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{
+		"id": "000000000000000000000001",
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+}
+
+func TestUpdateCoreFactor_NotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	request := model.CoreFactor{
+		CloudMembership: model.CloudMembershipOci,
+		Factor:          1,
+	}
+
+	as.EXPECT().UpdateCoreFactor(gomock.Any()).Return(nil, utils.ErrCoreFactorNotFound)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.UpdateCoreFactor)
+	req, err := http.NewRequest("PUT", "/", bytes.NewReader([]byte(utils.ToJSON(request))))
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{
+		"id": "000000000000000000000001",
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestDeleteCoreFactor_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	as.EXPECT().DeleteCoreFactor(utils.Str2oid("000000000000000000000001")).Return(nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.DeleteCoreFactor)
+	req, err := http.NewRequest("DELETE", "/", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{
+		"id": "000000000000000000000001",
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestDeleteCoreFactor_InvalidID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.DeleteCoreFactor)
+	req, err := http.NewRequest("DELETE", "/", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{
+		"id": "not-an-id",
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// writeTargets return the functions that resolve the locations of the entities modified by the routes,
+// so the write permission is checked where the entities are. The other routes modify global settings
+// or many locations at once (es. POST /contracts/{databaseType}/upload), and require the write permission on all the locations
+func (ctrl *APIController) writeTargets() map[string]middleware.TargetLocations {
+	return map[string]middleware.TargetLocations{
+		"PUT /hosts/{hostname}/create-dr": ctrl.hostLocations,
+		"DELETE /hosts/{hostname}":        ctrl.hostLocations,
+		"PUT /hosts/{hostname}/technologies/oracle/databases/{dbname}/licenses/{licenseTypeID}/ignored/{ignored}": ctrl.hostLocations,
+		"PUT /hosts/{hostname}/technologies/oracle/missing-dbs/{dbname}/ignored/{ignored}":                        ctrl.hostLocations,
+		"PUT /hosts/{hostname}/technologies/mysql/databases/{dbname}/ignored/{ignored}":                           ctrl.hostLocations,
+		"PUT /hosts/{hostname}/technologies/microsoft/databases/{dbname}/ignored/{ignored}":                       ctrl.hostLocations,
+		"POST /licenses/ignore": ctrl.ignoredLicensesLocations,
+
+		"POST /alerts/ack":           ctrl.ackedAlertsLocations,
+		"POST /alerts/{id}/assign":   ctrl.alertLocations,
+		"POST /alerts/{id}/comments": ctrl.alertLocations,
+		"POST /alerts/{id}/resolve":  ctrl.alertLocations,
+
+		"POST /exadata/{rackID}/components/{hostID}/vms/{name}": ctrl.exadataLocations,
+		"POST /exadata/{rackID}/components/{hostID}":            ctrl.exadataLocations,
+		"POST /exadata/{rackID}/rdma":                           ctrl.exadataLocations,
+		"PATCH /exadata/{rackID}/hide":                          ctrl.exadataLocations,
+		"PATCH /exadata/{rackID}/show":                          ctrl.exadataLocations,
+
+		"POST /contracts/oracle/database":                         ctrl.oracleDatabaseContractLocations,
+		"PUT /contracts/oracle/database":                          ctrl.oracleDatabaseContractLocations,
+		"DELETE /contracts/oracle/database/{id}":                  ctrl.oracleDatabaseContractLocations,
+		"POST /contracts/oracle/database/{id}/hosts":              ctrl.oracleDatabaseContractLocations,
+		"DELETE /contracts/oracle/database/{id}/hosts/{hostname}": ctrl.oracleDatabaseContractLocations,
+		"POST /contracts/mysql/database":                          ctrl.mySQLContractLocations,
+		"PUT /contracts/mysql/database/{id}":                      ctrl.mySQLContractLocations,
+		"DELETE /contracts/mysql/database/{id}":                   ctrl.mySQLContractLocations,
+		"POST /contracts/microsoft/database":                      ctrl.sqlServerContractLocations,
+		"PUT /contracts/microsoft/database":                       ctrl.sqlServerContractLocations,
+		"DELETE /contracts/microsoft/database/{id}":               ctrl.sqlServerContractLocations,
+	}
+}
+
+// hostsLocations return the locations of the current hosts, skipping the hosts that don't exist
+func (ctrl *APIController) hostsLocations(hostnames ...string) ([]string, error) {
+	locations := make([]string, 0, len(hostnames))
+
+	for _, hostname := range hostnames {
+		host, err := ctrl.auditHost(hostname)
+		if err != nil {
+			return nil, err
+		}
+
+		if host != nil {
+			locations = append(locations, host.Location)
+		}
+	}
+
+	return locations, nil
+}
+
+func (ctrl *APIController) hostLocations(r *http.Request, body []byte) ([]string, error) {
+	return ctrl.hostsLocations(mux.Vars(r)["hostname"])
+}
+
+func (ctrl *APIController) ignoredLicensesLocations(r *http.Request, body []byte) ([]string, error) {
+	req := make([]dto.IgnoreLicenseRequest, 0)
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, nil
+	}
+
+	hostnames := make([]string, 0, len(req))
+	for _, license := range req {
+		hostnames = append(hostnames, license.Hostname)
+	}
+
+	return ctrl.hostsLocations(hostnames...)
+}
+
+// alertsLocations return the locations of the hosts of the alerts, none if any of them
+// isn't found or isn't related to a current host
+func (ctrl *APIController) alertsLocations(ids ...primitive.ObjectID) ([]string, error) {
+	locations := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		alert, err := ctrl.Service.GetAlert(id)
+		if errors.Is(err, utils.ErrAlertNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if alert.Hostname() == "" {
+			return nil, nil
+		}
+
+		hostLocations, err := ctrl.hostsLocations(alert.Hostname())
+		if len(hostLocations) == 0 || err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, hostLocations...)
+	}
+
+	return locations, nil
+}
+
+func (ctrl *APIController) alertLocations(r *http.Request, body []byte) ([]string, error) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, nil
+	}
+
+	return ctrl.alertsLocations(id)
+}
+
+// ackedAlertsLocations return the locations of the alerts acked by id. The alerts acked by filter
+// can be of any location
+func (ctrl *APIController) ackedAlertsLocations(r *http.Request, body []byte) ([]string, error) {
+	var req struct {
+		Ids []primitive.ObjectID `json:"ids"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil || len(req.Ids) == 0 {
+		return nil, nil
+	}
+
+	return ctrl.alertsLocations(req.Ids...)
+}
+
+func (ctrl *APIController) exadataLocations(r *http.Request, body []byte) ([]string, error) {
+	instance, _, err := ctrl.auditExadata(mux.Vars(r)["rackID"])
+	if instance == nil || err != nil {
+		return nil, err
+	}
+
+	return []string{instance.Location}, nil
+}
+
+// contractLocations return the location of the contract and, when the request moves it, the location in the body
+func contractLocations(current string, body []byte) []string {
+	locations := make([]string, 0, 2)
+	if current != "" {
+		locations = append(locations, current)
+	}
+
+	var contract struct {
+		Location string `json:"location"`
+	}
+
+	if err := json.Unmarshal(body, &contract); err == nil && contract.Location != "" && contract.Location != current {
+		locations = append(locations, contract.Location)
+	}
+
+	return locations
+}
+
+func (ctrl *APIController) oracleDatabaseContractLocations(r *http.Request, body []byte) ([]string, error) {
+	contract, err := ctrl.auditOracleDatabaseContract(r, body)
+	if err != nil || contract == nil {
+		return contractLocations("", body), err
+	}
+
+	return contractLocations(contract.Location, body), nil
+}
+
+func (ctrl *APIController) mySQLContractLocations(r *http.Request, body []byte) ([]string, error) {
+	contract, err := ctrl.auditMySQLContract(r, body)
+	if err != nil || contract == nil {
+		return contractLocations("", body), err
+	}
+
+	return contractLocations(contract.Location, body), nil
+}
+
+func (ctrl *APIController) sqlServerContractLocations(r *http.Request, body []byte) ([]string, error) {
+	contract, err := ctrl.auditSqlServerContract(r, body)
+	if err != nil || contract == nil {
+		return contractLocations("", body), err
+	}
+
+	return contractLocations(contract.Location, body), nil
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestAddReportSubscription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	subscription := model.ReportSubscription{
+		Name:       "Weekly hosts",
+		Report:     model.ReportHosts,
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatXLSX,
+		Recipients: []string{"dba@ercole.test"},
+		Enabled:    true,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		expected := subscription
+		expected.ID = utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")
+
+		as.EXPECT().AddReportSubscription(subscription).Return(&expected, nil)
+
+		body, err := json.Marshal(subscription)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.AddReportSubscription).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+	})
+
+	t.Run("Invalid subscription", func(t *testing.T) {
+		as.EXPECT().AddReportSubscription(subscription).Return(nil, utils.ErrInvalidReportSubscription)
+
+		body, err := json.Marshal(subscription)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", "", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.AddReportSubscription).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDeleteReportSubscription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		as.EXPECT().DeleteReportSubscription(utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")).Return(nil)
+
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.DeleteReportSubscription).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().DeleteReportSubscription(utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")).Return(utils.ErrReportSubscriptionNotFound)
+
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.DeleteReportSubscription).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "pippo"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.DeleteReportSubscription).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestRunReportSubscription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")
+
+	t.Run("Success", func(t *testing.T) {
+		expected := model.ReportRun{
+			ID:             utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+			SubscriptionID: id,
+			Date:           utils.P("2019-11-05T14:02:03Z"),
+			Trigger:        model.ReportRunTriggerManual,
+			Success:        true,
+			Recipients:     []string{"dba@ercole.test"},
+			Attachments:    []string{"hosts_20191105.xlsx"},
+		}
+
+		as.EXPECT().RunReportSubscription(id).Return(&expected, nil)
+
+		req, err := http.NewRequest("POST", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RunReportSubscription).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().RunReportSubscription(id).Return(nil, utils.ErrReportSubscriptionNotFound)
+
+		req, err := http.NewRequest("POST", "", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.RunReportSubscription).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestListReportRuns(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa")
+	runs := []model.ReportRun{
+		{
+			ID:             utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+			SubscriptionID: id,
+			Date:           utils.P("2019-11-05T14:02:03Z"),
+			Trigger:        model.ReportRunTriggerSchedule,
+			Success:        false,
+			Error:          "Emailer is disabled",
+			Recipients:     []string{"dba@ercole.test"},
+		},
+	}
+
+	as.EXPECT().ListReportRuns(id).Return(runs, nil)
+
+	req, err := http.NewRequest("GET", "", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "aaaaaaaaaaaaaaaaaaaaaaaa"})
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(ac.ListReportRuns).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, utils.ToJSON(map[string]interface{}{"runs": runs}), rr.Body.String())
+}
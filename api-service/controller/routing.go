@@ -23,6 +23,7 @@ import (
 
 	"github.com/ercole-io/ercole/v2/api-service/auth"
 	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
+	"github.com/ercole-io/ercole/v2/model"
 )
 
 const (
@@ -42,6 +43,7 @@ func (ctrl *APIController) GetApiControllerHandler(auths []auth.AuthenticationPr
 	})
 
 	for _, ap := range auths {
+		selfServiceSubrouter := router.NewRoute().Subrouter()
 		subrouter := router.NewRoute().Subrouter()
 		settingsSubrouter := router.NewRoute().Subrouter()
 		prefix := ""
@@ -56,17 +58,48 @@ func (ctrl *APIController) GetApiControllerHandler(auths []auth.AuthenticationPr
 			prefix = "/ldap"
 		}
 
+		if ap.GetType() == auth.OidcType {
+			if oap, ok := ap.(*auth.OIDCAuthenticationProvider); ok {
+				router.HandleFunc("/oidc/login", oap.Authorize).Methods("GET")
+			}
+
+			router.HandleFunc("/oidc/login", ap.GetToken).Methods("POST")
+
+			prefix = "/oidc"
+		}
+
+		audit := middleware.Audit(ctrl.Service, ctrl.Log, middleware.AuditOptions{
+			Service:      model.AuditServiceAPI,
+			AuthProvider: ap.GetType(),
+			Prefix:       prefix,
+			Snapshots:    ctrl.auditSnapshots(),
+		})
+
+		selfServiceSubrouter.Use(ap.AuthenticateMiddleware)
+		selfServiceSubrouter.Use(audit)
+		ctrl.setupSelfServiceRoutes(selfServiceSubrouter.PathPrefix(prefix).Subrouter())
+
 		subrouter.Use(ap.AuthenticateMiddleware)
+		subrouter.Use(middleware.Write(ctrl.Service, middleware.WriteOptions{Prefix: prefix, Targets: ctrl.writeTargets()}))
 		subrouter.Use(middleware.Location(ctrl.Service))
+		subrouter.Use(audit)
 		ctrl.setupProtectedRoutes(subrouter.PathPrefix(prefix).Subrouter())
 
 		settingsSubrouter.Use(ap.AuthenticateMiddleware)
+		settingsSubrouter.Use(middleware.Write(ctrl.Service, middleware.WriteOptions{Prefix: prefix}))
+		settingsSubrouter.Use(audit)
 		ctrl.setupSettingsRoutes(settingsSubrouter.PathPrefix(prefix + "/settings").Subrouter())
 	}
 
 	return router
 }
 
+// setupSelfServiceRoutes setup the routes that every user can call on their own account,
+// regardless of the permission granted by their roles
+func (ctrl *APIController) setupSelfServiceRoutes(router *mux.Router) {
+	router.HandleFunc(fmt.Sprintf("%s/{username}/change-password", userGroup), ctrl.ChangePassword).Methods("POST")
+}
+
 func (ctrl *APIController) setupProtectedRoutes(router *mux.Router) {
 	// ERCOLE
 	router.HandleFunc("/version", ctrl.GetVersion).Methods("GET")
@@ -78,7 +111,6 @@ func (ctrl *APIController) setupProtectedRoutes(router *mux.Router) {
 	router.HandleFunc(userGroup, ctrl.GetUsers).Methods("GET")
 	router.HandleFunc(fmt.Sprintf("%s/info", userGroup), ctrl.GetInfo).Methods("GET")
 	router.HandleFunc(fmt.Sprintf("%s/{username}", userGroup), ctrl.GetUser).Methods("GET")
-	router.HandleFunc(fmt.Sprintf("%s/{username}/change-password", userGroup), ctrl.ChangePassword).Methods("POST")
 
 	// GROUPS
 	router.HandleFunc("/groups", ctrl.InsertGroup).Methods("POST")
@@ -99,8 +131,11 @@ func (ctrl *APIController) setupProtectedRoutes(router *mux.Router) {
 	router.HandleFunc("/hosts/clusters/{name}", ctrl.GetCluster).Methods("GET")
 
 	router.HandleFunc("/hosts/no-clusters", ctrl.GetVirtualHostWithoutCluster).Methods("GET")
+	router.HandleFunc("/hosts/cluster-reconciliation", ctrl.GetClusterReconciliation).Methods("GET")
 
 	router.HandleFunc("/hosts/{hostname}", ctrl.GetHost).Methods("GET")
+	router.HandleFunc("/hosts/{hostname}/diff", ctrl.GetHostDiff).Methods("GET")
+	router.HandleFunc("/hosts/{hostname}/timeline", ctrl.GetHostTimeline).Methods("GET")
 	router.HandleFunc("/hosts/{hostname}/create-dr", ctrl.CreateDr).Methods("PUT")
 	router.HandleFunc("/hosts/{hostname}", ctrl.DismissHost).Methods("DELETE")
 	router.HandleFunc("/hosts/{hostname}/technologies/oracle/databases/{dbname}/licenses/{licenseTypeID}/ignored/{ignored}", ctrl.UpdateLicenseIgnoredField).Methods("PUT")
@@ -215,6 +250,16 @@ func (ctrl *APIController) setupProtectedRoutes(router *mux.Router) {
 	// ALERTS
 	router.HandleFunc("/alerts", ctrl.SearchAlerts).Methods("GET")
 	router.HandleFunc("/alerts/ack", ctrl.AckAlerts).Methods("POST")
+	router.HandleFunc("/alerts/{id}/assign", ctrl.AssignAlert).Methods("POST")
+	router.HandleFunc("/alerts/{id}/comments", ctrl.CommentAlert).Methods("POST")
+	router.HandleFunc("/alerts/{id}/resolve", ctrl.ResolveAlert).Methods("POST")
+
+	// MAINTENANCE WINDOWS
+	router.HandleFunc("/maintenance-windows", ctrl.ListMaintenanceWindows).Methods("GET")
+	router.HandleFunc("/maintenance-windows", ctrl.AddMaintenanceWindow).Methods("POST")
+	router.HandleFunc("/maintenance-windows/{id}", ctrl.GetMaintenanceWindow).Methods("GET")
+	router.HandleFunc("/maintenance-windows/{id}", ctrl.UpdateMaintenanceWindow).Methods("PUT")
+	router.HandleFunc("/maintenance-windows/{id}", ctrl.DeleteMaintenanceWindow).Methods("DELETE")
 
 	router.HandleFunc("/database/connection/status", ctrl.GetDatabaseConnectionStatus).Methods("GET")
 
@@ -257,6 +302,10 @@ func (ctrl *APIController) setupSettingsRoutes(router *mux.Router) {
 	router.HandleFunc("/oracle/database/license-types/{id}", ctrl.DeleteOracleDatabaseLicenseType).Methods("DELETE")
 	router.HandleFunc("/oracle/database/license-types", ctrl.AddOracleDatabaseLicenseType).Methods("POST")
 	router.HandleFunc("/oracle/database/license-types/{id}", ctrl.UpdateOracleDatabaseLicenseType).Methods("PUT")
+	router.HandleFunc("/oracle/database/core-factors", ctrl.GetCoreFactors).Methods("GET")
+	router.HandleFunc("/oracle/database/core-factors", ctrl.AddCoreFactor).Methods("POST")
+	router.HandleFunc("/oracle/database/core-factors/{id}", ctrl.UpdateCoreFactor).Methods("PUT")
+	router.HandleFunc("/oracle/database/core-factors/{id}", ctrl.DeleteCoreFactor).Methods("DELETE")
 	router.HandleFunc("/microsoft/database/license-types", ctrl.GetSqlServerDatabaseLicenseTypes).Methods("GET")
 	router.HandleFunc("/mysql/database/license-types", ctrl.GetMySqlLicenseTypes).Methods("GET")
 }
@@ -279,6 +328,42 @@ func (ctrl *APIController) setupAdminRoutes(router *mux.Router) {
 	router.HandleFunc("/roles/{roleName}", middleware.Admin(ctrl.UpdateRole)).Methods("PUT")
 	router.HandleFunc("/roles/{roleName}", middleware.Admin(ctrl.RemoveRole)).Methods("DELETE")
 
+	// ALERT ROUTING RULES
+	router.HandleFunc("/alert-routing-rules", middleware.Admin(ctrl.ListAlertRoutingRules)).Methods("GET")
+	router.HandleFunc("/alert-routing-rules", middleware.Admin(ctrl.AddAlertRoutingRule)).Methods("POST")
+	router.HandleFunc("/alert-routing-rules/dry-run/{alertID}", middleware.Admin(ctrl.DryRunAlertRouting)).Methods("GET")
+	router.HandleFunc("/alert-routing-rules/{id}", middleware.Admin(ctrl.GetAlertRoutingRule)).Methods("GET")
+	router.HandleFunc("/alert-routing-rules/{id}", middleware.Admin(ctrl.UpdateAlertRoutingRule)).Methods("PUT")
+	router.HandleFunc("/alert-routing-rules/{id}", middleware.Admin(ctrl.DeleteAlertRoutingRule)).Methods("DELETE")
+
+	// REPORT SUBSCRIPTIONS
+	router.HandleFunc("/report-subscriptions", middleware.Admin(ctrl.ListReportSubscriptions)).Methods("GET")
+	router.HandleFunc("/report-subscriptions", middleware.Admin(ctrl.AddReportSubscription)).Methods("POST")
+	router.HandleFunc("/report-subscriptions/{id}", middleware.Admin(ctrl.GetReportSubscription)).Methods("GET")
+	router.HandleFunc("/report-subscriptions/{id}", middleware.Admin(ctrl.UpdateReportSubscription)).Methods("PUT")
+	router.HandleFunc("/report-subscriptions/{id}", middleware.Admin(ctrl.DeleteReportSubscription)).Methods("DELETE")
+	router.HandleFunc("/report-subscriptions/{id}/run", middleware.Admin(ctrl.RunReportSubscription)).Methods("POST")
+	router.HandleFunc("/report-subscriptions/{id}/history", middleware.Admin(ctrl.ListReportRuns)).Methods("GET")
+
+	// API TOKENS
+	router.HandleFunc("/api-tokens", middleware.Admin(ctrl.ListAPITokens)).Methods("GET")
+	router.HandleFunc("/api-tokens", middleware.Admin(ctrl.CreateAPIToken)).Methods("POST")
+	router.HandleFunc("/api-tokens/{id}", middleware.Admin(ctrl.RevokeAPIToken)).Methods("DELETE")
+
+	// SERVICE ACCOUNTS
+	router.HandleFunc("/service-accounts", middleware.Admin(ctrl.ListServiceAccounts)).Methods("GET")
+	router.HandleFunc("/service-accounts", middleware.Admin(ctrl.AddServiceAccount)).Methods("POST")
+	router.HandleFunc("/service-accounts/{username}", middleware.Admin(ctrl.RemoveServiceAccount)).Methods("DELETE")
+
+	// AGENT CREDENTIALS
+	router.HandleFunc("/agent-credentials", middleware.Admin(ctrl.ListAgentCredentials)).Methods("GET")
+	router.HandleFunc("/agent-credentials", middleware.Admin(ctrl.EnrollAgentCredential)).Methods("POST")
+	router.HandleFunc("/agent-credentials/{hostname}/rotate", middleware.Admin(ctrl.RotateAgentCredential)).Methods("POST")
+	router.HandleFunc("/agent-credentials/{hostname}", middleware.Admin(ctrl.RevokeAgentCredential)).Methods("DELETE")
+
+	// AUDIT
+	router.HandleFunc("/audit", middleware.Admin(ctrl.SearchAuditEntries)).Methods("GET")
+
 	// NODES
 	router.HandleFunc("/nodes", ctrl.AddNode).Methods("POST")
 	router.HandleFunc("/nodes/{name}", ctrl.GetNode).Methods("GET")
@@ -16,14 +16,26 @@
 package controller
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
 	"github.com/ercole-io/ercole/v2/api-service/service"
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/gorilla/context"
 	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
 )
 
 func TestAPIController_setupProtectedRoutes(t *testing.T) {
@@ -57,3 +69,357 @@ func TestAPIController_setupProtectedRoutes(t *testing.T) {
 		})
 	}
 }
+
+type fakeAuthenticationProvider struct {
+	user     model.User
+	apiToken *model.APIToken
+}
+
+func (ap *fakeAuthenticationProvider) Init() {}
+
+func (ap *fakeAuthenticationProvider) AuthenticateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context.Set(r, "user", ap.user)
+
+		if ap.apiToken != nil {
+			context.Set(r, "apiToken", *ap.apiToken)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (ap *fakeAuthenticationProvider) GetToken(w http.ResponseWriter, r *http.Request) {}
+
+func (ap *fakeAuthenticationProvider) GetUserInfoIfCredentialsAreCorrect(username string, password string) (*dto.User, error) {
+	return nil, nil
+}
+
+func (ap *fakeAuthenticationProvider) GetType() string {
+	return "fake"
+}
+
+var routeVariable = regexp.MustCompile(`{[^}]+}`)
+
+// mutatingRoutes returns the method and an example path of every route that modifies data
+func mutatingRoutes(t *testing.T, router *mux.Router) [][2]string {
+	routes := make([][2]string, 0)
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			if method != http.MethodGet {
+				routes = append(routes, [2]string{method, routeVariable.ReplaceAllString(template, "test")})
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, routes)
+
+	return routes
+}
+
+func TestGetApiControllerHandler_ReadOnlyUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "reader", Groups: []string{"readers"}}
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+	handler := ac.GetApiControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	for _, route := range mutatingRoutes(t, handler.(*mux.Router)) {
+		method, path := route[0], route[1]
+		if strings.HasSuffix(path, "/change-password") && !strings.HasPrefix(path, "/admin") {
+			continue
+		}
+
+		t.Run(method+" "+path, func(t *testing.T) {
+			as.EXPECT().GetHost("test", utils.MAX_TIME, false).Return(nil, utils.ErrHostNotFound).AnyTimes()
+			as.EXPECT().GetExadataInstance("test", gomock.Any()).Return(nil, mongo.ErrNoDocuments).AnyTimes()
+			as.EXPECT().GetOracleDatabaseContracts(gomock.Any()).Return([]dto.OracleDatabaseContractFE{}, nil).AnyTimes()
+			as.EXPECT().GetMySQLContracts(gomock.Any()).Return([]model.MySQLContract{}, nil).AnyTimes()
+			as.EXPECT().GetSqlServerDatabaseContracts(gomock.Any()).Return([]model.SqlServerDatabaseContract{}, nil).AnyTimes()
+			as.EXPECT().GetAlert(gomock.Any()).Return(nil, utils.ErrAlertNotFound).AnyTimes()
+			as.EXPECT().GetUserPermission(user, model.AllLocation).Return(model.ReadPermission, nil).Times(1)
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(method, path+"?location=Italy", strings.NewReader("{}"))
+			require.NoError(t, err)
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+		})
+	}
+}
+
+func TestGetApiControllerHandler_WriteUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "writer", Groups: []string{"writers"}}
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+	handler := ac.GetApiControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	t.Run("write permission", func(t *testing.T) {
+		as.EXPECT().GetUserPermission(user, model.AllLocation).Return(model.WritePermission, nil).Times(1)
+		as.EXPECT().ListLocations(user).Return([]string{"Italy"}, nil).Times(1)
+		as.EXPECT().InsertAuditEntry(gomock.Any()).
+			Do(func(entry model.AuditEntry) {
+				assert.Equal(t, model.AuditServiceAPI, entry.Service)
+				assert.Equal(t, "writer", entry.Username)
+				assert.Equal(t, "POST", entry.Method)
+				assert.Equal(t, "/groups", entry.Route)
+				assert.Equal(t, "Italy", entry.Location)
+				assert.Equal(t, http.StatusBadRequest, entry.Status)
+			}).Return(nil).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/groups?location=Italy", strings.NewReader("{"))
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("refused on the global settings with the write permission on a location", func(t *testing.T) {
+		as.EXPECT().GetUserPermission(user, model.AllLocation).Return(model.ReadPermission, nil).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/groups?location=Italy", strings.NewReader("{"))
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("refused on an alert of a host of another location", func(t *testing.T) {
+		id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+		alert := &model.Alert{ID: id, OtherInfo: map[string]interface{}{"hostname": "berlin01"}}
+		host := &dto.HostData{Hostname: "berlin01", Location: "Germany"}
+		as.EXPECT().GetAlert(id).Return(alert, nil).Times(1)
+		as.EXPECT().GetHost("berlin01", utils.MAX_TIME, false).Return(host, nil).Times(1)
+		as.EXPECT().GetUserPermission(user, "Germany").Return(model.ReadPermission, nil).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/alerts/5dc3f534db7e81a98b726a52/resolve?location=Italy", strings.NewReader(`{"reason":"fixed"}`))
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("refused on a host of another location", func(t *testing.T) {
+		host := &dto.HostData{Hostname: "berlin01", Location: "Germany"}
+		as.EXPECT().GetHost("berlin01", utils.MAX_TIME, false).Return(host, nil).Times(1)
+		as.EXPECT().GetUserPermission(user, "Germany").Return(model.ReadPermission, nil).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("DELETE", "/hosts/berlin01?location=Italy", nil)
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("refused on a contract moved to another location", func(t *testing.T) {
+		contract := model.MySQLContract{ID: utils.Str2oid("5dc3f534db7e81a98b726a52"), Location: "Italy"}
+		as.EXPECT().GetMySQLContracts(nil).Return([]model.MySQLContract{contract}, nil).Times(1)
+		as.EXPECT().GetUserPermission(user, "Italy,Germany").Return(model.ReadPermission, nil).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("PUT", "/contracts/mysql/database/5dc3f534db7e81a98b726a52", strings.NewReader(`{"location":"Germany"}`))
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("read requests are not checked", func(t *testing.T) {
+		as.EXPECT().ListLocations(user).Return([]string{"Italy"}, nil).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/version", nil)
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("users can always change their own password", func(t *testing.T) {
+		as.EXPECT().InsertAuditEntry(gomock.Any()).Return(nil).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/users/writer/change-password", strings.NewReader("{"))
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("error retrieving permission", func(t *testing.T) {
+		as.EXPECT().GetUserPermission(user, model.AllLocation).Return("", aerrMock).Times(1)
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("DELETE", "/scenarios/test", nil)
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestGetApiControllerHandler_AdminUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "admin", Groups: []string{model.GroupAdmin}}
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+	handler := ac.GetApiControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	as.EXPECT().InsertAuditEntry(gomock.Any()).Return(nil).Times(1)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/groups", strings.NewReader("{"))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetApiControllerHandler_APIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockAPIServiceInterface(mockCtrl)
+
+	ac := APIController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	admin := model.User{Username: "admin", Groups: []string{model.GroupAdmin}}
+	automation := model.User{Username: "automation", Groups: []string{"inventory"}, ServiceAccount: true}
+
+	serve := func(user model.User, token model.APIToken, method, path string) int {
+		handler := ac.GetApiControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user, apiToken: &token}})
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, strings.NewReader("{"))
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		return rr.Code
+	}
+
+	t.Run("read only token can't modify data, even if its owner is admin", func(t *testing.T) {
+		token := model.APIToken{Permission: model.ReadPermission}
+
+		assert.Equal(t, http.StatusForbidden, serve(admin, token, "POST", "/groups"))
+	})
+
+	t.Run("write token is limited by the permission of its owner", func(t *testing.T) {
+		token := model.APIToken{Permission: model.WritePermission}
+		as.EXPECT().GetUserPermission(automation, model.AllLocation).Return(model.ReadPermission, nil).Times(1)
+
+		assert.Equal(t, http.StatusForbidden, serve(automation, token, "POST", "/groups?location=Italy"))
+	})
+
+	t.Run("write token of admin", func(t *testing.T) {
+		token := model.APIToken{ID: utils.Str2oid("5dc3f534db7e81a98b726a52"), Permission: model.WritePermission, Locations: []string{}}
+		as.EXPECT().InsertAuditEntry(gomock.Any()).
+			Do(func(entry model.AuditEntry) {
+				assert.Equal(t, "admin", entry.Username)
+				assert.Equal(t, &token.ID, entry.APITokenID)
+			}).Return(nil).Times(1)
+
+		assert.Equal(t, http.StatusBadRequest, serve(admin, token, "POST", "/groups"))
+	})
+
+	t.Run("token scoped to some locations of admin", func(t *testing.T) {
+		token := model.APIToken{Permission: model.ReadPermission, Locations: []string{"Italy"}}
+
+		assert.Equal(t, http.StatusOK, serve(admin, token, "GET", "/version?location=Italy"))
+		assert.Equal(t, http.StatusForbidden, serve(admin, token, "GET", "/version?location=Germany"))
+		assert.Equal(t, http.StatusForbidden, serve(admin, token, "GET", "/version?location=Italy,Germany"))
+		assert.Equal(t, http.StatusUnauthorized, serve(admin, token, "GET", "/admin/api-tokens"))
+	})
+
+	t.Run("token scoped to some locations of admin can't reach the hosts of the other locations", func(t *testing.T) {
+		token := model.APIToken{Permission: model.WritePermission, Locations: []string{"Italy"}}
+		host := &dto.HostData{Hostname: "berlin01", Location: "Germany"}
+		as.EXPECT().GetHost("berlin01", utils.MAX_TIME, false).Return(host, nil).Times(2)
+		as.EXPECT().ListLocations(admin).Return([]string{"Italy", "Germany"}, nil).Times(1)
+
+		assert.Equal(t, http.StatusForbidden, serve(admin, token, "GET", "/hosts/berlin01"))
+		assert.Equal(t, http.StatusForbidden, serve(admin, token, "DELETE", "/hosts/berlin01"))
+	})
+
+	t.Run("write token scoped to some locations of admin", func(t *testing.T) {
+		token := model.APIToken{Permission: model.WritePermission, Locations: []string{"Italy"}}
+		id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+		alert := &model.Alert{ID: id, OtherInfo: map[string]interface{}{"hostname": "milano01"}}
+		as.EXPECT().GetAlert(id).Return(alert, nil).Times(1)
+		as.EXPECT().GetHost("milano01", utils.MAX_TIME, false).Return(&dto.HostData{Hostname: "milano01", Location: "Italy"}, nil).Times(1)
+		as.EXPECT().InsertAuditEntry(gomock.Any()).Return(nil).Times(1)
+
+		assert.Equal(t, http.StatusBadRequest, serve(admin, token, "POST", "/alerts/5dc3f534db7e81a98b726a52/resolve"))
+		assert.Equal(t, http.StatusForbidden, serve(admin, token, "POST", "/groups?location=Italy"))
+	})
+
+	t.Run("token scoped to locations not visible by its owner", func(t *testing.T) {
+		token := model.APIToken{Permission: model.ReadPermission, Locations: []string{"Italy", "Germany"}}
+		as.EXPECT().ListLocations(automation).Return([]string{"Italy"}, nil).Times(3)
+
+		assert.Equal(t, http.StatusOK, serve(automation, token, "GET", "/version"))
+		assert.Equal(t, http.StatusOK, serve(automation, token, "GET", "/version?location=Italy"))
+		assert.Equal(t, http.StatusForbidden, serve(automation, token, "GET", "/version?location=Germany"))
+	})
+
+	t.Run("token scoped to none of the locations of its owner", func(t *testing.T) {
+		token := model.APIToken{Permission: model.ReadPermission, Locations: []string{"Germany"}}
+		as.EXPECT().ListLocations(automation).Return([]string{"Italy"}, nil).Times(1)
+
+		assert.Equal(t, http.StatusForbidden, serve(automation, token, "GET", "/version"))
+	})
+}
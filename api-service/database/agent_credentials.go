@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const agentCredentialCollection = "agent_credentials"
+
+// ListAgentCredentials return the credentials of the agents sorted by hostname
+func (md *MongoDatabase) ListAgentCredentials() ([]model.AgentCredential, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "hostname", Value: 1}})
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentCredentialCollection).
+		Find(context.TODO(), bson.M{}, opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	credentials := make([]model.AgentCredential, 0)
+
+	if err := cur.All(context.TODO(), &credentials); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return credentials, nil
+}
+
+// GetAgentCredential return the credential enrolled for the agent of the host
+func (md *MongoDatabase) GetAgentCredential(hostname string) (*model.AgentCredential, error) {
+	res := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentCredentialCollection).
+		FindOne(context.TODO(), bson.M{"hostname": hostname})
+	if res.Err() == mongo.ErrNoDocuments {
+		return nil, utils.ErrAgentCredentialNotFound
+	} else if res.Err() != nil {
+		return nil, utils.NewError(res.Err(), "DB ERROR")
+	}
+
+	var out model.AgentCredential
+	if err := res.Decode(&out); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return &out, nil
+}
+
+// SaveAgentCredential insert the credential or replace the one of the same host
+func (md *MongoDatabase) SaveAgentCredential(credential model.AgentCredential) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentCredentialCollection).
+		ReplaceOne(context.TODO(),
+			bson.M{"hostname": credential.Hostname},
+			credential,
+			options.Replace().SetUpsert(true))
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// RevokeAgentCredential set the revocation date of the credential of the host, if it isn't already revoked
+func (md *MongoDatabase) RevokeAgentCredential(hostname string, revokedAt time.Time) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentCredentialCollection).
+		UpdateOne(context.TODO(),
+			bson.M{"hostname": hostname},
+			bson.A{bson.M{"$set": bson.M{"revokedAt": bson.M{"$ifNull": bson.A{"$revokedAt", revokedAt}}}}})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.MatchedCount == 0 {
+		return utils.ErrAgentCredentialNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestAgentCredentials() {
+	_, err := m.db.Client.Database(m.dbname).Collection(agentCredentialCollection).DeleteMany(context.TODO(), bson.M{})
+	m.Require().NoError(err)
+
+	defer m.db.Client.Database(m.dbname).Collection(agentCredentialCollection).DeleteMany(context.TODO(), bson.M{})
+
+	rac2 := model.AgentCredential{
+		ID:        utils.Str2oid("654a1d2f3b8e7c0001a1c4d2"),
+		Hostname:  "rac2",
+		Hash:      "hash-rac2",
+		Hint:      "ercagt_EfGh",
+		CreatedAt: utils.P("2024-03-02T10:00:00Z"),
+	}
+	rac1 := model.AgentCredential{
+		ID:        utils.Str2oid("654a1d2f3b8e7c0001a1c4d1"),
+		Hostname:  "rac1",
+		Hash:      "hash-rac1",
+		Hint:      "ercagt_AbCd",
+		CreatedAt: utils.P("2024-03-01T10:00:00Z"),
+	}
+
+	for _, credential := range []model.AgentCredential{rac2, rac1} {
+		m.Require().NoError(m.db.SaveAgentCredential(credential))
+	}
+
+	m.T().Run("List", func(t *testing.T) {
+		actual, err := m.db.ListAgentCredentials()
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.AgentCredential{rac1, rac2}, actual)
+	})
+
+	m.T().Run("Get", func(t *testing.T) {
+		actual, err := m.db.GetAgentCredential("rac2")
+		m.Require().NoError(err)
+		m.Assert().Equal(rac2, *actual)
+
+		_, err = m.db.GetAgentCredential("unknown")
+		m.Assert().ErrorIs(err, utils.ErrAgentCredentialNotFound)
+	})
+
+	m.T().Run("Rotate", func(t *testing.T) {
+		rotatedAt := utils.P("2024-03-04T10:00:00Z")
+		rotated := rac1
+		rotated.Hash = "hash-rac1-rotated"
+		rotated.Hint = "ercagt_IjKl"
+		rotated.RotatedAt = &rotatedAt
+		m.Require().NoError(m.db.SaveAgentCredential(rotated))
+
+		actual, err := m.db.GetAgentCredential("rac1")
+		m.Require().NoError(err)
+		m.Assert().Equal(rotated, *actual)
+	})
+
+	m.T().Run("Revoke", func(t *testing.T) {
+		first := utils.P("2024-03-05T10:00:00Z")
+		m.Require().NoError(m.db.RevokeAgentCredential("rac2", first))
+		m.Require().NoError(m.db.RevokeAgentCredential("rac2", utils.P("2024-03-06T10:00:00Z")))
+
+		actual, err := m.db.GetAgentCredential("rac2")
+		m.Require().NoError(err)
+		m.Assert().Equal(first, *actual.RevokedAt)
+
+		m.Assert().ErrorIs(m.db.RevokeAgentCredential("unknown", first), utils.ErrAgentCredentialNotFound)
+	})
+}
@@ -24,6 +24,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/amreo/mu"
@@ -61,6 +62,7 @@ func (md *MongoDatabase) SearchAlerts(alertFilter alert_filter.Alert) (*dto.Pagi
 			mu.APOptionalStage(alertFilter.Hostname != "", mu.APMatch(bson.M{
 				"otherInfo.hostname": primitive.Regex{Pattern: regexp.QuoteMeta(alertFilter.Hostname), Options: "i"},
 			})),
+			alertLifecycleStages(alertFilter.AlertLifecycle),
 			mu.APMatch(bson.M{
 				"date": bson.M{
 					"$gte": alertFilter.From,
@@ -245,7 +247,22 @@ func (md *MongoDatabase) SearchAlerts(alertFilter alert_filter.Alert) (*dto.Pagi
 	return dto.ToPagination(nil, int(count), alertFilter.Filter.Limit, alertFilter.Filter.Page), nil
 }
 
-func (md *MongoDatabase) GetAlerts(location, environment, status string, from, to, olderThan time.Time) ([]map[string]interface{}, error) {
+// alertLifecycleStages return the stages that filter the alerts by assignee, resolution reason and comments
+func alertLifecycleStages(lifecycle alert_filter.AlertLifecycle) interface{} {
+	return mu.MAPipeline(
+		mu.APOptionalStage(lifecycle.Assignee != "", mu.APMatch(bson.M{
+			"assignee": lifecycle.Assignee,
+		})),
+		mu.APOptionalStage(lifecycle.ResolutionReason != "", mu.APMatch(bson.M{
+			"resolutionReason": primitive.Regex{Pattern: regexp.QuoteMeta(lifecycle.ResolutionReason), Options: "i"},
+		})),
+		mu.APOptionalStage(lifecycle.Comment != "", mu.APMatch(bson.M{
+			"comments.text": primitive.Regex{Pattern: regexp.QuoteMeta(lifecycle.Comment), Options: "i"},
+		})),
+	)
+}
+
+func (md *MongoDatabase) GetAlerts(location, environment, status string, from, to, olderThan time.Time, lifecycle alert_filter.AlertLifecycle) ([]map[string]interface{}, error) {
 	options := options.Aggregate().SetAllowDiskUse(true)
 
 	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(alertsCollection).Aggregate(
@@ -258,6 +275,7 @@ func (md *MongoDatabase) GetAlerts(location, environment, status string, from, t
 				},
 				"alertStatus": bson.M{"$eq": status},
 			}),
+			alertLifecycleStages(lifecycle),
 			mu.APSet(bson.M{
 				"hostname": "$otherInfo.hostname",
 			}),
@@ -374,7 +392,7 @@ func (md *MongoDatabase) CountAlertsNODATA(alertsFilter dto.AlertsFilter) (int64
 	return count, nil
 }
 
-func (md *MongoDatabase) UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus string) error {
+func (md *MongoDatabase) UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus, username string) error {
 	data, err := bson.Marshal(alertsFilter)
 	if err != nil {
 		return err
@@ -415,14 +433,32 @@ func (md *MongoDatabase) UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newSt
 		filter["alertStatus"] = bson.M{"$ne": model.AlertStatusDismissed}
 	}
 
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"statusHistory": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$statusHistory", bson.A{}}},
+				bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$alertStatus", newStatus}},
+					bson.A{},
+					bson.A{bson.M{
+						"date":     md.TimeNow(),
+						"username": username,
+						"field":    model.AlertStatusChangeFieldStatus,
+						"from":     "$alertStatus",
+						"to":       newStatus,
+					}},
+				}},
+			}},
+			"alertStatus": newStatus,
+		}}},
+	}
+
 	_, err = md.Client.Database(md.Config.Mongodb.DBName).
 		Collection(alertsCollection).
 		UpdateMany(
 			context.TODO(),
 			filter,
-			bson.M{"$set": bson.M{
-				"alertStatus": newStatus,
-			}},
+			update,
 		)
 	if err != nil {
 		return utils.NewError(err, "DB ERROR")
@@ -446,3 +482,61 @@ func (md *MongoDatabase) RemoveAlertsNODATA(alertsFilter dto.AlertsFilter) error
 
 	return nil
 }
+
+// FindAlert return the alert specified by id
+func (md *MongoDatabase) FindAlert(id primitive.ObjectID) (*model.Alert, error) {
+	res := md.Client.Database(md.Config.Mongodb.DBName).Collection(alertsCollection).
+		FindOne(context.TODO(), bson.M{"_id": id})
+	if res.Err() == mongo.ErrNoDocuments {
+		return nil, utils.ErrAlertNotFound
+	} else if res.Err() != nil {
+		return nil, utils.NewError(res.Err(), "DB ERROR")
+	}
+
+	var out model.Alert
+	if err := res.Decode(&out); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return &out, nil
+}
+
+// AssignAlert set the assignee of the alert and record the change in its status history
+func (md *MongoDatabase) AssignAlert(id primitive.ObjectID, assignee string, change model.AlertStatusChange) error {
+	return md.updateAlert(id, bson.M{
+		"$set":  bson.M{"assignee": assignee},
+		"$push": bson.M{"statusHistory": change},
+	})
+}
+
+// AddAlertComment append the comment to the alert
+func (md *MongoDatabase) AddAlertComment(id primitive.ObjectID, comment model.AlertComment) error {
+	return md.updateAlert(id, bson.M{
+		"$push": bson.M{"comments": comment},
+	})
+}
+
+// ResolveAlert set the alert as resolved and record the change in its status history
+func (md *MongoDatabase) ResolveAlert(id primitive.ObjectID, reason string, change model.AlertStatusChange) error {
+	return md.updateAlert(id, bson.M{
+		"$set": bson.M{
+			"alertStatus":      model.AlertStatusResolved,
+			"resolutionReason": reason,
+		},
+		"$push": bson.M{"statusHistory": change},
+	})
+}
+
+func (md *MongoDatabase) updateAlert(id primitive.ObjectID, update bson.M) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(alertsCollection).
+		UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.MatchedCount == 0 {
+		return utils.ErrAlertNotFound
+	}
+
+	return nil
+}
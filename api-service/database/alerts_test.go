@@ -600,7 +600,7 @@ func (m *MongodbSuite) TestGetAlerts() {
 	}
 
 	m.T().Run("should_get_alerts", func(t *testing.T) {
-		out, err := m.db.GetAlerts("", "", "NEW", utils.MIN_TIME, utils.MAX_TIME, utils.MAX_TIME)
+		out, err := m.db.GetAlerts("", "", "NEW", utils.MIN_TIME, utils.MAX_TIME, utils.MAX_TIME, alertFilter.AlertLifecycle{})
 		m.Require().NoError(err)
 		var expectedOut []map[string]interface{} = []map[string]interface{}{
 			alert1, alert2, alert3, alert4,
@@ -611,6 +611,19 @@ func (m *MongodbSuite) TestGetAlerts() {
 }
 
 func (m *MongodbSuite) TestUpdateAlertsStatus() {
+	m.db.TimeNow = utils.Btc(utils.P("2019-11-06T10:00:00Z"))
+	defer func() { m.db.TimeNow = time.Now }()
+
+	ackChange := []model.AlertStatusChange{
+		{
+			Date:     utils.P("2019-11-06T10:00:00Z"),
+			Username: "usertest",
+			Field:    model.AlertStatusChangeFieldStatus,
+			From:     model.AlertStatusNew,
+			To:       model.AlertStatusAck,
+		},
+	}
+
 	a := model.Alert{
 
 		AlertAffectedTechnology: nil,
@@ -637,7 +650,8 @@ func (m *MongodbSuite) TestUpdateAlertsStatus() {
 		OtherInfo: map[string]interface{}{
 			"hostname": "myhost",
 		},
-		ID: utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+		ID:            utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+		StatusHistory: ackChange,
 	}
 
 	b := model.Alert{
@@ -666,7 +680,8 @@ func (m *MongodbSuite) TestUpdateAlertsStatus() {
 			"hostname": "myhost",
 			"dbname":   "pippo",
 		},
-		ID: utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+		ID:            utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+		StatusHistory: ackChange,
 	}
 
 	testCases := []struct {
@@ -731,7 +746,7 @@ func (m *MongodbSuite) TestUpdateAlertsStatus() {
 		_, _ = m.db.Client.Database(m.dbname).Collection(alertsCollection).
 			InsertMany(context.TODO(), alerts)
 
-		actErr := m.db.UpdateAlertsStatus(tc.filter, model.AlertStatusAck)
+		actErr := m.db.UpdateAlertsStatus(tc.filter, model.AlertStatusAck, "usertest")
 		if tc.expErr == nil {
 			assert.Nil(m.T(), actErr)
 		} else {
@@ -842,3 +857,75 @@ func (m *MongodbSuite) TestRemoveAlertsNODATA() {
 		clean()
 	}
 }
+
+func (m *MongodbSuite) TestAlertLifecycle() {
+	defer m.db.Client.Database(m.dbname).Collection(alertsCollection).DeleteMany(context.TODO(), bson.M{})
+
+	a := model.Alert{
+		AlertCategory: model.AlertCategoryLicense,
+		AlertCode:     model.AlertCodeLicenseNonCompliant,
+		AlertSeverity: model.AlertSeverityCritical,
+		AlertStatus:   model.AlertStatusNew,
+		Date:          utils.P("2019-11-05T18:02:03Z"),
+		Description:   "The license isn't compliant",
+		OtherInfo:     map[string]interface{}{},
+		ID:            utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+	}
+	_, err := m.db.Client.Database(m.dbname).Collection(alertsCollection).InsertOne(context.TODO(), a)
+	require.NoError(m.T(), err)
+
+	assignment := model.AlertStatusChange{
+		Date:     utils.P("2019-11-06T10:00:00Z"),
+		Username: "admin",
+		Field:    model.AlertStatusChangeFieldAssignee,
+		To:       "rossi",
+	}
+	comment := model.AlertComment{
+		Date:     utils.P("2019-11-06T11:00:00Z"),
+		Username: "rossi",
+		Text:     "Contract requested to the vendor",
+	}
+	resolution := model.AlertStatusChange{
+		Date:     utils.P("2019-11-07T10:00:00Z"),
+		Username: "rossi",
+		Field:    model.AlertStatusChangeFieldStatus,
+		From:     model.AlertStatusNew,
+		To:       model.AlertStatusResolved,
+		Reason:   "Licenses bought",
+	}
+
+	m.T().Run("should_assign_comment_and_resolve", func(t *testing.T) {
+		require.NoError(t, m.db.AssignAlert(a.ID, "rossi", assignment))
+		require.NoError(t, m.db.AddAlertComment(a.ID, comment))
+		require.NoError(t, m.db.ResolveAlert(a.ID, "Licenses bought", resolution))
+
+		actual, err := m.db.FindAlert(a.ID)
+		require.NoError(t, err)
+
+		expected := a
+		expected.AlertStatus = model.AlertStatusResolved
+		expected.Assignee = "rossi"
+		expected.Comments = []model.AlertComment{comment}
+		expected.ResolutionReason = "Licenses bought"
+		expected.StatusHistory = []model.AlertStatusChange{assignment, resolution}
+
+		assert.Equal(t, &expected, actual)
+	})
+
+	m.T().Run("should_filter_by_lifecycle", func(t *testing.T) {
+		actual, err := m.db.GetAlerts("", "", "", utils.MIN_TIME, utils.MAX_TIME, utils.MAX_TIME,
+			alertFilter.AlertLifecycle{Assignee: "rossi", Comment: "vendor"})
+		require.NoError(t, err)
+		assert.Len(t, actual, 1)
+
+		actual, err = m.db.GetAlerts("", "", "", utils.MIN_TIME, utils.MAX_TIME, utils.MAX_TIME,
+			alertFilter.AlertLifecycle{Assignee: "bianchi"})
+		require.NoError(t, err)
+		assert.Len(t, actual, 0)
+	})
+
+	m.T().Run("should_not_find_alert", func(t *testing.T) {
+		err := m.db.AssignAlert(utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"), "rossi", assignment)
+		assert.ErrorIs(t, err, utils.ErrAlertNotFound)
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const apiTokenCollection = "api_tokens"
+
+// ListAPITokens return the API tokens of the user sorted by creation date, or the ones of all the users
+// if username is empty
+func (md *MongoDatabase) ListAPITokens(username string) ([]model.APIToken, error) {
+	filter := bson.M{}
+	if username != "" {
+		filter["username"] = username
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(apiTokenCollection).
+		Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	tokens := make([]model.APIToken, 0)
+
+	if err := cur.All(context.TODO(), &tokens); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return tokens, nil
+}
+
+// GetAPIToken return the API token specified by id
+func (md *MongoDatabase) GetAPIToken(id primitive.ObjectID) (*model.APIToken, error) {
+	return md.findAPIToken(bson.M{"_id": id})
+}
+
+// GetAPITokenByHash return the API token whose hash is hash
+func (md *MongoDatabase) GetAPITokenByHash(hash string) (*model.APIToken, error) {
+	return md.findAPIToken(bson.M{"hash": hash})
+}
+
+func (md *MongoDatabase) findAPIToken(filter bson.M) (*model.APIToken, error) {
+	res := md.Client.Database(md.Config.Mongodb.DBName).Collection(apiTokenCollection).
+		FindOne(context.TODO(), filter)
+	if res.Err() == mongo.ErrNoDocuments {
+		return nil, utils.ErrAPITokenNotFound
+	} else if res.Err() != nil {
+		return nil, utils.NewError(res.Err(), "DB ERROR")
+	}
+
+	var out model.APIToken
+	if err := res.Decode(&out); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return &out, nil
+}
+
+func (md *MongoDatabase) InsertAPIToken(token model.APIToken) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(apiTokenCollection).
+		InsertOne(context.TODO(), token)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// RevokeAPIToken set the revocation date of the API token, if it isn't already revoked
+func (md *MongoDatabase) RevokeAPIToken(id primitive.ObjectID, revokedAt time.Time) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(apiTokenCollection).
+		UpdateOne(context.TODO(),
+			bson.M{"_id": id},
+			bson.A{bson.M{"$set": bson.M{"revokedAt": bson.M{"$ifNull": bson.A{"$revokedAt", revokedAt}}}}})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.MatchedCount == 0 {
+		return utils.ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeUserAPITokens revoke all the API tokens of the user that aren't already revoked
+func (md *MongoDatabase) RevokeUserAPITokens(username string, revokedAt time.Time) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(apiTokenCollection).
+		UpdateMany(context.TODO(),
+			bson.M{"username": username, "revokedAt": nil},
+			bson.M{"$set": bson.M{"revokedAt": revokedAt}})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// UpdateAPITokenLastUsed set the last time the API token has been used
+func (md *MongoDatabase) UpdateAPITokenLastUsed(id primitive.ObjectID, lastUsedAt time.Time) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(apiTokenCollection).
+		UpdateOne(context.TODO(), bson.M{"_id": id}, bson.M{"$set": bson.M{"lastUsedAt": lastUsedAt}})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestAPITokens() {
+	_, err := m.db.Client.Database(m.dbname).Collection(apiTokenCollection).DeleteMany(context.TODO(), bson.M{})
+	m.Require().NoError(err)
+
+	defer m.db.Client.Database(m.dbname).Collection(apiTokenCollection).DeleteMany(context.TODO(), bson.M{})
+
+	inventory := model.APIToken{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1c3d1"),
+		Name:       "inventory sync",
+		Username:   "automation",
+		Hash:       "hash-inventory",
+		Hint:       "ercpat_AbCd",
+		Locations:  []string{"Italy"},
+		Permission: model.ReadPermission,
+		ExpiresAt:  utils.P("2025-03-01T10:00:00Z"),
+		CreatedAt:  utils.P("2024-03-01T10:00:00Z"),
+	}
+	backup := model.APIToken{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1c3d2"),
+		Name:       "backup",
+		Username:   "automation",
+		Hash:       "hash-backup",
+		Hint:       "ercpat_EfGh",
+		Locations:  []string{},
+		Permission: model.WritePermission,
+		ExpiresAt:  utils.P("2025-03-01T10:00:00Z"),
+		CreatedAt:  utils.P("2024-03-02T10:00:00Z"),
+	}
+	personal := model.APIToken{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1c3d3"),
+		Name:       "personal",
+		Username:   "alice",
+		Hash:       "hash-personal",
+		Hint:       "ercpat_IjKl",
+		Locations:  []string{},
+		Permission: model.ReadPermission,
+		ExpiresAt:  utils.P("2025-03-01T10:00:00Z"),
+		CreatedAt:  utils.P("2024-03-03T10:00:00Z"),
+	}
+
+	for _, token := range []model.APIToken{inventory, backup, personal} {
+		m.Require().NoError(m.db.InsertAPIToken(token))
+	}
+
+	m.T().Run("List", func(t *testing.T) {
+		actual, err := m.db.ListAPITokens("")
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.APIToken{inventory, backup, personal}, actual)
+
+		actual, err = m.db.ListAPITokens("automation")
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.APIToken{inventory, backup}, actual)
+	})
+
+	m.T().Run("GetByHash", func(t *testing.T) {
+		actual, err := m.db.GetAPITokenByHash("hash-backup")
+		m.Require().NoError(err)
+		m.Assert().Equal(backup, *actual)
+
+		_, err = m.db.GetAPITokenByHash("hash-unknown")
+		m.Assert().ErrorIs(err, utils.ErrAPITokenNotFound)
+	})
+
+	m.T().Run("UpdateLastUsed", func(t *testing.T) {
+		lastUsed := utils.P("2024-03-04T10:00:00Z")
+		m.Require().NoError(m.db.UpdateAPITokenLastUsed(inventory.ID, lastUsed))
+
+		actual, err := m.db.GetAPIToken(inventory.ID)
+		m.Require().NoError(err)
+		m.Assert().Equal(lastUsed, *actual.LastUsedAt)
+	})
+
+	m.T().Run("Revoke", func(t *testing.T) {
+		first := utils.P("2024-03-05T10:00:00Z")
+		m.Require().NoError(m.db.RevokeAPIToken(inventory.ID, first))
+		m.Require().NoError(m.db.RevokeAPIToken(inventory.ID, utils.P("2024-03-06T10:00:00Z")))
+
+		actual, err := m.db.GetAPIToken(inventory.ID)
+		m.Require().NoError(err)
+		m.Assert().Equal(first, *actual.RevokedAt)
+
+		m.Assert().ErrorIs(m.db.RevokeAPIToken(utils.Str2oid("654a1d2f3b8e7c0001a1c3ff"), first), utils.ErrAPITokenNotFound)
+	})
+
+	m.T().Run("RevokeUser", func(t *testing.T) {
+		revokedAt := utils.P("2024-03-07T10:00:00Z")
+		m.Require().NoError(m.db.RevokeUserAPITokens("automation", revokedAt))
+
+		actual, err := m.db.ListAPITokens("automation")
+		m.Require().NoError(err)
+		m.Assert().Equal(utils.P("2024-03-05T10:00:00Z"), *actual[0].RevokedAt)
+		m.Assert().Equal(revokedAt, *actual[1].RevokedAt)
+
+		other, err := m.db.GetAPIToken(personal.ID)
+		m.Require().NoError(err)
+		m.Assert().Nil(other.RevokedAt)
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const auditCollection = "audit"
+
+// InsertAuditEntry append the entry to the audit log
+func (md *MongoDatabase) InsertAuditEntry(entry model.AuditEntry) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(auditCollection).
+		InsertOne(context.TODO(), entry)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// SearchAuditEntries return the entries of the audit log that match the filter, the newest first
+func (md *MongoDatabase) SearchAuditEntries(filter dto.AuditFilter) (*dto.AuditEntriesResponse, error) {
+	query := bson.M{
+		"date": bson.M{
+			"$gte": filter.From,
+			"$lt":  filter.To,
+		},
+	}
+
+	for field, value := range map[string]string{
+		"username": filter.Username,
+		"service":  filter.Service,
+		"method":   filter.Method,
+		"route":    filter.Route,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+
+	if filter.Search != "" {
+		query["path"] = bson.M{"$regex": regexp.QuoteMeta(filter.Search), "$options": "i"}
+	}
+
+	collection := md.Client.Database(md.Config.Mongodb.DBName).Collection(auditCollection)
+
+	total, err := collection.CountDocuments(context.TODO(), query)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}})
+	if filter.PageSize > 0 {
+		opts.SetSkip(int64(filter.PageNumber * filter.PageSize)).SetLimit(int64(filter.PageSize))
+	}
+
+	cur, err := collection.Find(context.TODO(), query, opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	res := dto.AuditEntriesResponse{
+		Content: make([]model.AuditEntry, 0),
+	}
+
+	if err := cur.All(context.TODO(), &res.Content); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	totalPages := 1
+	if filter.PageSize > 0 {
+		totalPages = int((total + int64(filter.PageSize) - 1) / int64(filter.PageSize))
+	}
+
+	res.Metadata = dto.PagingMetadata{
+		Empty:         len(res.Content) == 0,
+		First:         filter.PageNumber == 0,
+		Last:          filter.PageNumber >= totalPages-1,
+		Number:        filter.PageNumber,
+		Size:          len(res.Content),
+		TotalElements: int(total),
+		TotalPages:    totalPages,
+	}
+
+	return &res, nil
+}
+
+// DeleteAuditEntriesOlderThan remove the entries of the audit log recorded before the date,
+// returning how many have been removed
+func (md *MongoDatabase) DeleteAuditEntriesOlderThan(date time.Time) (int64, error) {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(auditCollection).
+		DeleteMany(context.TODO(), bson.M{"date": bson.M{"$lt": date}})
+	if err != nil {
+		return 0, utils.NewError(err, "DB ERROR")
+	}
+
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestAuditEntries() {
+	_, err := m.db.Client.Database(m.dbname).Collection(auditCollection).DeleteMany(context.TODO(), bson.M{})
+	m.Require().NoError(err)
+
+	defer m.db.Client.Database(m.dbname).Collection(auditCollection).DeleteMany(context.TODO(), bson.M{})
+
+	dismiss := model.AuditEntry{
+		ID:           utils.Str2oid("654a1d2f3b8e7c0001a1c5d1"),
+		Date:         utils.P("2024-03-01T10:00:00Z"),
+		Service:      model.AuditServiceAPI,
+		Username:     "alice",
+		AuthProvider: "basic",
+		Method:       "DELETE",
+		Route:        "/hosts/{hostname}",
+		Path:         "/hosts/rac1",
+		Target:       map[string]string{"hostname": "rac1"},
+		Status:       204,
+		Before:       json.RawMessage(`{"hostname":"rac1"}`),
+		Changes: []model.AuditChange{
+			{Field: "hostname", Before: json.RawMessage(`"rac1"`)},
+		},
+	}
+	hide := model.AuditEntry{
+		ID:           utils.Str2oid("654a1d2f3b8e7c0001a1c5d2"),
+		Date:         utils.P("2024-03-02T10:00:00Z"),
+		Service:      model.AuditServiceAPI,
+		Username:     "bob",
+		AuthProvider: "ldap",
+		Method:       "PATCH",
+		Route:        "/exadata/{rackID}/hide",
+		Path:         "/exadata/RACK-01/hide",
+		Target:       map[string]string{"rackID": "RACK-01"},
+		Status:       204,
+		Changes:      []model.AuditChange{},
+	}
+	profile := model.AuditEntry{
+		ID:           utils.Str2oid("654a1d2f3b8e7c0001a1c5d3"),
+		Date:         utils.P("2024-03-03T10:00:00Z"),
+		Service:      model.AuditServiceThunder,
+		Username:     "alice",
+		AuthProvider: "basic",
+		Method:       "POST",
+		Route:        "/aws/configurations",
+		Path:         "/aws/configurations",
+		Target:       map[string]string{},
+		Status:       200,
+		After:        json.RawMessage(`{"secretAccessKey":"********"}`),
+		Changes:      []model.AuditChange{},
+	}
+
+	for _, entry := range []model.AuditEntry{dismiss, hide, profile} {
+		m.Require().NoError(m.db.InsertAuditEntry(entry))
+	}
+
+	all := dto.AuditFilter{From: utils.MIN_TIME, To: utils.MAX_TIME}
+
+	m.T().Run("All", func(t *testing.T) {
+		actual, err := m.db.SearchAuditEntries(all)
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.AuditEntry{profile, hide, dismiss}, actual.Content)
+		m.Assert().Equal(dto.PagingMetadata{
+			First:         true,
+			Last:          true,
+			Size:          3,
+			TotalElements: 3,
+			TotalPages:    1,
+		}, actual.Metadata)
+	})
+
+	m.T().Run("Filtered", func(t *testing.T) {
+		filter := all
+		filter.Username = "alice"
+		filter.Service = model.AuditServiceAPI
+
+		actual, err := m.db.SearchAuditEntries(filter)
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.AuditEntry{dismiss}, actual.Content)
+
+		filter = all
+		filter.Search = "rack-01"
+
+		actual, err = m.db.SearchAuditEntries(filter)
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.AuditEntry{hide}, actual.Content)
+
+		filter = all
+		filter.To = utils.P("2024-03-02T10:00:00Z")
+
+		actual, err = m.db.SearchAuditEntries(filter)
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.AuditEntry{dismiss}, actual.Content)
+	})
+
+	m.T().Run("Paged", func(t *testing.T) {
+		filter := all
+		filter.PageNumber = 1
+		filter.PageSize = 2
+
+		actual, err := m.db.SearchAuditEntries(filter)
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.AuditEntry{dismiss}, actual.Content)
+		m.Assert().Equal(dto.PagingMetadata{
+			Last:          true,
+			Number:        1,
+			Size:          1,
+			TotalElements: 3,
+			TotalPages:    2,
+		}, actual.Metadata)
+	})
+
+	m.T().Run("DeleteOlderThan", func(t *testing.T) {
+		deleted, err := m.db.DeleteAuditEntriesOlderThan(utils.P("2024-03-02T10:00:00Z"))
+		m.Require().NoError(err)
+		m.Assert().Equal(int64(1), deleted)
+
+		actual, err := m.db.SearchAuditEntries(all)
+		m.Require().NoError(err)
+		m.Assert().Equal([]model.AuditEntry{profile, hide}, actual.Content)
+	})
+}
@@ -48,11 +48,15 @@ type MongoDatabaseInterface interface {
 	// GetHost fetch all informations about a host in the database
 	GetHost(hostname string, olderThan time.Time, raw bool) (*dto.HostData, error)
 	GetHostData(hostname string, olderThan time.Time) (*model.HostDataBE, error)
+	// ListHostDataHistory return the snapshots of the host inserted after from and not after to, oldest first
+	ListHostDataHistory(hostname string, from, to time.Time) ([]model.HostDataBE, error)
+	// GetHostVirtualizationCluster return the virtualization cluster that was running the host at the olderThan date
+	GetHostVirtualizationCluster(hostname string, olderThan time.Time) (*dto.HostVirtualizationCluster, error)
 	GetHostDatas(filter dto.GlobalFilter) ([]model.HostDataBE, error)
 	// SearchAlerts search alerts
 	SearchAlerts(alertFilter alert_filter.Alert) (*dto.Pagination, error)
 	// GetAlerts get alerts
-	GetAlerts(location, environment, status string, from, to, olderThan time.Time) ([]map[string]interface{}, error)
+	GetAlerts(location, environment, status string, from, to, olderThan time.Time, lifecycle alert_filter.AlertLifecycle) ([]map[string]interface{}, error)
 	// SearchClusters search clusters
 	SearchClusters(mode string, keywords []string, sortBy string, sortDesc bool, page int, pageSize int, location string, environment string, olderThan time.Time) ([]dto.Cluster, error)
 	GetClusters(filter dto.GlobalFilter) ([]dto.Cluster, error)
@@ -147,6 +151,14 @@ type MongoDatabaseInterface interface {
 	UpdateOracleDatabaseLicenseType(licenseType model.OracleDatabaseLicenseType) error
 	// RemoveOracleDatabaseLicenseType remove a licence type - Oracle/Database contract part from the database
 	RemoveOracleDatabaseLicenseType(id string) error
+	// GetCoreFactors return the rows of the core factor table
+	GetCoreFactors() ([]model.CoreFactor, error)
+	// InsertCoreFactor insert a row into the core factor table
+	InsertCoreFactor(coreFactor model.CoreFactor) error
+	// UpdateCoreFactor update a row of the core factor table
+	UpdateCoreFactor(coreFactor model.CoreFactor) error
+	// RemoveCoreFactor remove a row of the core factor table
+	RemoveCoreFactor(id primitive.ObjectID) error
 
 	FindGrantDbaByHostname(hostname string, filter dto.GlobalFilter) ([]dto.OracleGrantDbaDto, error)
 
@@ -169,8 +181,8 @@ type MongoDatabaseInterface interface {
 
 	// ReplaceHostData adds a new hostdata to the database
 	ReplaceHostData(hostData model.HostDataBE) error
-	// UpdateAlertsStatus change the status of the specified alerts
-	UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus string) error
+	// UpdateAlertsStatus change the status of the specified alerts, recording the user in their status history
+	UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus, username string) error
 	// CountAlertsNODATA gets alert with alertCode equals to "NO_DATA"
 	CountAlertsNODATA(alertsFilter dto.AlertsFilter) (int64, error)
 	// DismissHost dismiss the specified host
@@ -187,6 +199,14 @@ type MongoDatabaseInterface interface {
 	GetListDismissedHostsByRangeDates(from time.Time, to time.Time) ([]string, error)
 	// RemoveAlertsNODATA delete all alerts with alertCode equals to "NO_DATA"
 	RemoveAlertsNODATA(alertsFilter dto.AlertsFilter) error
+	// FindAlert return the alert specified by id
+	FindAlert(id primitive.ObjectID) (*model.Alert, error)
+	// AssignAlert set the assignee of the alert and record the change in its status history
+	AssignAlert(id primitive.ObjectID, assignee string, change model.AlertStatusChange) error
+	// AddAlertComment append the comment to the alert
+	AddAlertComment(id primitive.ObjectID, comment model.AlertComment) error
+	// ResolveAlert set the alert as resolved and record the change in its status history
+	ResolveAlert(id primitive.ObjectID, reason string, change model.AlertStatusChange) error
 
 	// FindHostData find the current hostdata with a certain hostname
 	FindHostData(hostname string) (model.HostDataBE, error)
@@ -251,6 +271,30 @@ type MongoDatabaseInterface interface {
 	UpdateRole(name string, documents bson.D) error
 	RemoveRole(roleName string) error
 
+	// ALERT ROUTING RULES
+	ListAlertRoutingRules() ([]model.AlertRoutingRule, error)
+	GetAlertRoutingRule(id primitive.ObjectID) (*model.AlertRoutingRule, error)
+	InsertAlertRoutingRule(rule model.AlertRoutingRule) error
+	UpdateAlertRoutingRule(rule model.AlertRoutingRule) error
+	DeleteAlertRoutingRule(id primitive.ObjectID) error
+
+	// MAINTENANCE WINDOWS
+	ListMaintenanceWindows() ([]model.MaintenanceWindow, error)
+	GetMaintenanceWindow(id primitive.ObjectID) (*model.MaintenanceWindow, error)
+	InsertMaintenanceWindow(window model.MaintenanceWindow) error
+	UpdateMaintenanceWindow(window model.MaintenanceWindow) error
+	DeleteMaintenanceWindow(id primitive.ObjectID) error
+
+	// REPORT SUBSCRIPTIONS
+	ListReportSubscriptions() ([]model.ReportSubscription, error)
+	GetReportSubscription(id primitive.ObjectID) (*model.ReportSubscription, error)
+	InsertReportSubscription(subscription model.ReportSubscription) error
+	UpdateReportSubscription(subscription model.ReportSubscription) error
+	UpdateReportSubscriptionLastRun(id primitive.ObjectID, lastRun time.Time) error
+	DeleteReportSubscription(id primitive.ObjectID) error
+	InsertReportRun(run model.ReportRun) error
+	ListReportRuns(subscriptionID primitive.ObjectID) ([]model.ReportRun, error)
+
 	// GROUPS
 	InsertGroup(group model.Group) error
 	GetGroup(name string) (*model.Group, error)
@@ -268,6 +312,27 @@ type MongoDatabaseInterface interface {
 	RemoveUser(username string) error
 	UpdatePassword(username string, password string, salt string) error
 	GetUserLocations(username string) ([]string, error)
+	GetUserRoles(username string) ([]model.Role, error)
+
+	// API TOKENS
+	ListAPITokens(username string) ([]model.APIToken, error)
+	GetAPIToken(id primitive.ObjectID) (*model.APIToken, error)
+	GetAPITokenByHash(hash string) (*model.APIToken, error)
+	InsertAPIToken(token model.APIToken) error
+	RevokeAPIToken(id primitive.ObjectID, revokedAt time.Time) error
+	RevokeUserAPITokens(username string, revokedAt time.Time) error
+	UpdateAPITokenLastUsed(id primitive.ObjectID, lastUsedAt time.Time) error
+
+	// AGENT CREDENTIALS
+	ListAgentCredentials() ([]model.AgentCredential, error)
+	GetAgentCredential(hostname string) (*model.AgentCredential, error)
+	SaveAgentCredential(credential model.AgentCredential) error
+	RevokeAgentCredential(hostname string, revokedAt time.Time) error
+
+	// AUDIT
+	InsertAuditEntry(entry model.AuditEntry) error
+	SearchAuditEntries(filter dto.AuditFilter) (*dto.AuditEntriesResponse, error)
+	DeleteAuditEntriesOlderThan(date time.Time) (int64, error)
 
 	// TREE
 	GetNodesByRoles(roles []string) ([]model.Node, error)
@@ -325,13 +390,9 @@ type MongoDatabaseInterface interface {
 	FindClusterVeritasLicenses(filter dto.GlobalFilter) ([]dto.ClusterVeritasLicense, error)
 
 	CreateScenario(scenario *model.Scenario) (*model.Scenario, error)
-	CreateSimulatedHosts(hosts ...model.SimulatedHost) error
-	UpdateHostCores(hostname string, cores int) error
-	RemoveSimulatedHost(id primitive.ObjectID) error
 	GetScenarios() ([]model.Scenario, error)
 	GetScenario(id primitive.ObjectID) (*model.Scenario, error)
 	RemoveScenario(id primitive.ObjectID) error
-	UpdateLicenseCount(hostname string, licenseCount int) error
 }
 
 // MongoDatabase is a implementation
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/amreo/mu"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// ListHostDataHistory return the snapshots of the host inserted after from and not after to, oldest first
+func (md *MongoDatabase) ListHostDataHistory(hostname string, from, to time.Time) ([]model.HostDataBE, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").Find(context.TODO(),
+		bson.M{
+			"hostname": hostname,
+			"createdAt": bson.M{
+				"$gt":  from,
+				"$lte": to,
+			},
+		},
+		opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	hostdatas := make([]model.HostDataBE, 0)
+	if err := cur.All(context.TODO(), &hostdatas); err != nil {
+		return nil, utils.NewError(err, "DECODE ERROR")
+	}
+
+	return hostdatas, nil
+}
+
+// GetHostVirtualizationCluster return the virtualization cluster that was running the host at the olderThan date,
+// nil if the host wasn't running in any known cluster
+func (md *MongoDatabase) GetHostVirtualizationCluster(hostname string, olderThan time.Time) (*dto.HostVirtualizationCluster, error) {
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").Aggregate(
+		context.TODO(),
+		mu.MAPipeline(
+			FilterByOldnessSteps(olderThan),
+			mu.APMatch(bson.M{
+				"clusters.vms.hostname": hostname,
+			}),
+			mu.APUnwind("$clusters"),
+			mu.APReplaceWith("$clusters"),
+			mu.APUnwind("$vms"),
+			mu.APMatch(bson.M{
+				"vms.hostname": hostname,
+			}),
+			mu.APProject(bson.M{
+				"_id":                false,
+				"name":               true,
+				"virtualizationNode": "$vms.virtualizationNode",
+			}),
+			mu.APLimit(1),
+		),
+	)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	if !cur.Next(context.TODO()) {
+		return nil, nil
+	}
+
+	var cluster dto.HostVirtualizationCluster
+	if err := cur.Decode(&cluster); err != nil {
+		return nil, utils.NewError(err, "DECODE ERROR")
+	}
+
+	return &cluster, nil
+}
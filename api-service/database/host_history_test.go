@@ -0,0 +1,96 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestHostHistory() {
+	defer m.db.Client.Database(m.dbname).Collection("hosts").DeleteMany(context.TODO(), map[string]interface{}{})
+
+	snapshots := []model.HostDataBE{
+		{
+			ID:        utils.Str2oid("654a1d2f3b8e7c0001a1b2e1"),
+			Hostname:  "vm01",
+			Archived:  true,
+			CreatedAt: utils.P("2024-01-01T10:00:00Z"),
+		},
+		{
+			ID:        utils.Str2oid("654a1d2f3b8e7c0001a1b2e2"),
+			Hostname:  "vm01",
+			Archived:  true,
+			CreatedAt: utils.P("2024-02-01T10:00:00Z"),
+		},
+		{
+			ID:        utils.Str2oid("654a1d2f3b8e7c0001a1b2e3"),
+			Hostname:  "vm01",
+			CreatedAt: utils.P("2024-03-01T10:00:00Z"),
+		},
+		{
+			ID:        utils.Str2oid("654a1d2f3b8e7c0001a1b2e4"),
+			Hostname:  "esx01",
+			Archived:  true,
+			CreatedAt: utils.P("2024-01-01T10:00:00Z"),
+			Clusters: []model.ClusterInfo{
+				{Name: "Puzzait", VMs: []model.VMInfo{{Name: "vm01", Hostname: "vm01", VirtualizationNode: "node1"}}},
+			},
+		},
+		{
+			ID:        utils.Str2oid("654a1d2f3b8e7c0001a1b2e5"),
+			Hostname:  "esx01",
+			CreatedAt: utils.P("2024-02-15T10:00:00Z"),
+			Clusters: []model.ClusterInfo{
+				{Name: "Puzzait", VMs: []model.VMInfo{}},
+				{Name: "Bart", VMs: []model.VMInfo{{Name: "vm01", Hostname: "vm01", VirtualizationNode: "node2"}}},
+			},
+		},
+	}
+	for _, s := range snapshots {
+		require.NoError(m.T(), m.db.InsertHostdata(s))
+	}
+
+	m.T().Run("List history", func(t *testing.T) {
+		actual, err := m.db.ListHostDataHistory("vm01", utils.P("2024-01-01T10:00:00Z"), utils.P("2024-03-01T10:00:00Z"))
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		require.Equal(t, snapshots[1].ID, actual[0].ID)
+		require.Equal(t, snapshots[2].ID, actual[1].ID)
+	})
+
+	m.T().Run("Cluster", func(t *testing.T) {
+		actual, err := m.db.GetHostVirtualizationCluster("vm01", utils.P("2024-02-01T00:00:00Z"))
+		require.NoError(t, err)
+		require.Equal(t, &dto.HostVirtualizationCluster{Name: "Puzzait", VirtualizationNode: "node1"}, actual)
+
+		actual, err = m.db.GetHostVirtualizationCluster("vm01", utils.MAX_TIME)
+		require.NoError(t, err)
+		require.Equal(t, &dto.HostVirtualizationCluster{Name: "Bart", VirtualizationNode: "node2"}, actual)
+	})
+
+	m.T().Run("No cluster", func(t *testing.T) {
+		actual, err := m.db.GetHostVirtualizationCluster("vm01", utils.P("2023-12-01T00:00:00Z"))
+		require.NoError(t, err)
+		require.Nil(t, actual)
+	})
+}
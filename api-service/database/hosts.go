@@ -467,6 +467,7 @@ func (md *MongoDatabase) getHosts(mode string, filters dto.SearchHostsFilters, o
 							0,
 						), 0.0),
 						"processorModel":    "$info.cpuModel",
+						"cloudMembership":   "$cloud.membership",
 						"processors":        "$info.cpuSockets",
 						"coresPerProcessor": "$info.coresPerSocket",
 						"threadsPerCore": mu.APOCond(
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const maintenanceWindowCollection = "maintenance_windows"
+
+// ListMaintenanceWindows return all the maintenance windows sorted by start date
+func (md *MongoDatabase) ListMaintenanceWindows() ([]model.MaintenanceWindow, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start", Value: -1}})
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(maintenanceWindowCollection).
+		Find(context.TODO(), bson.D{}, opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	windows := make([]model.MaintenanceWindow, 0)
+
+	if err := cur.All(context.TODO(), &windows); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return windows, nil
+}
+
+// GetMaintenanceWindow return the maintenance window specified by id
+func (md *MongoDatabase) GetMaintenanceWindow(id primitive.ObjectID) (*model.MaintenanceWindow, error) {
+	res := md.Client.Database(md.Config.Mongodb.DBName).Collection(maintenanceWindowCollection).
+		FindOne(context.TODO(), bson.M{"_id": id})
+	if res.Err() == mongo.ErrNoDocuments {
+		return nil, utils.ErrMaintenanceWindowNotFound
+	} else if res.Err() != nil {
+		return nil, utils.NewError(res.Err(), "DB ERROR")
+	}
+
+	var out model.MaintenanceWindow
+	if err := res.Decode(&out); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return &out, nil
+}
+
+func (md *MongoDatabase) InsertMaintenanceWindow(window model.MaintenanceWindow) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(maintenanceWindowCollection).
+		InsertOne(context.TODO(), window)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+func (md *MongoDatabase) UpdateMaintenanceWindow(window model.MaintenanceWindow) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(maintenanceWindowCollection).
+		ReplaceOne(context.TODO(), bson.M{"_id": window.ID}, window)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.MatchedCount == 0 {
+		return utils.ErrMaintenanceWindowNotFound
+	}
+
+	return nil
+}
+
+func (md *MongoDatabase) DeleteMaintenanceWindow(id primitive.ObjectID) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(maintenanceWindowCollection).
+		DeleteOne(context.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.DeletedCount == 0 {
+		return utils.ErrMaintenanceWindowNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const oracleCoreFactorsCollection = "oracle_core_factors"
+
+// GetCoreFactors return the rows of the core factor table
+func (md *MongoDatabase) GetCoreFactors() ([]model.CoreFactor, error) {
+	ctx := context.TODO()
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(oracleCoreFactorsCollection).
+		Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "priority", Value: 1}}))
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	coreFactors := make([]model.CoreFactor, 0)
+	if err := cur.All(ctx, &coreFactors); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return coreFactors, nil
+}
+
+// InsertCoreFactor insert a row into the core factor table
+func (md *MongoDatabase) InsertCoreFactor(coreFactor model.CoreFactor) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(oracleCoreFactorsCollection).
+		InsertOne(context.TODO(), coreFactor)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// UpdateCoreFactor update a row of the core factor table
+func (md *MongoDatabase) UpdateCoreFactor(coreFactor model.CoreFactor) error {
+	result, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(oracleCoreFactorsCollection).
+		ReplaceOne(context.TODO(), bson.M{"_id": coreFactor.ID}, coreFactor)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if result.MatchedCount != 1 {
+		return utils.ErrCoreFactorNotFound
+	}
+
+	return nil
+}
+
+// RemoveCoreFactor remove a row of the core factor table
+func (md *MongoDatabase) RemoveCoreFactor(id primitive.ObjectID) error {
+	result, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(oracleCoreFactorsCollection).
+		DeleteOne(context.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if result.DeletedCount == 0 {
+		return utils.ErrCoreFactorNotFound
+	}
+
+	return nil
+}
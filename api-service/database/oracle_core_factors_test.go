@@ -0,0 +1,70 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *MongodbSuite) TestCoreFactors() {
+	_, err := m.db.Client.Database(m.dbname).Collection(oracleCoreFactorsCollection).DeleteMany(context.TODO(), bson.M{})
+	m.Require().NoError(err)
+
+	defer m.db.Client.Database(m.dbname).Collection(oracleCoreFactorsCollection).DeleteMany(context.TODO(), bson.M{})
+
+	power := model.CoreFactor{
+		ID:            utils.Str2oid("654a1d2f3b8e7c0001a1b2c3"),
+		Description:   "IBM POWER",
+		CPUModelRegex: "POWER[0-9]",
+		Factor:        1,
+		Priority:      10,
+	}
+	xeon := model.CoreFactor{
+		ID:            utils.Str2oid("654a1d2f3b8e7c0001a1b2c4"),
+		Description:   "Intel Xeon",
+		CPUModelRegex: "Xeon",
+		Factor:        0.5,
+		Priority:      50,
+	}
+
+	m.Require().NoError(m.db.InsertCoreFactor(xeon))
+	m.Require().NoError(m.db.InsertCoreFactor(power))
+
+	actual, err := m.db.GetCoreFactors()
+	m.Require().NoError(err)
+	m.Assert().Equal([]model.CoreFactor{power, xeon}, actual)
+
+	xeon.Factor = 1
+	m.Require().NoError(m.db.UpdateCoreFactor(xeon))
+
+	actual, err = m.db.GetCoreFactors()
+	m.Require().NoError(err)
+	m.Assert().Equal([]model.CoreFactor{power, xeon}, actual)
+
+	m.Require().NoError(m.db.RemoveCoreFactor(power.ID))
+
+	actual, err = m.db.GetCoreFactors()
+	m.Require().NoError(err)
+	m.Assert().Equal([]model.CoreFactor{xeon}, actual)
+
+	m.Assert().ErrorIs(m.db.RemoveCoreFactor(power.ID), utils.ErrCoreFactorNotFound)
+	m.Assert().ErrorIs(m.db.UpdateCoreFactor(model.CoreFactor{ID: primitive.NewObjectID()}), utils.ErrCoreFactorNotFound)
+}
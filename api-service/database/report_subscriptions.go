@@ -0,0 +1,160 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const (
+	reportSubscriptionCollection = "report_subscriptions"
+	reportRunCollection          = "report_runs"
+)
+
+// ListReportSubscriptions return all the report subscriptions sorted by name
+func (md *MongoDatabase) ListReportSubscriptions() ([]model.ReportSubscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportSubscriptionCollection).
+		Find(context.TODO(), bson.D{}, opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	subscriptions := make([]model.ReportSubscription, 0)
+
+	if err := cur.All(context.TODO(), &subscriptions); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return subscriptions, nil
+}
+
+// GetReportSubscription return the report subscription specified by id
+func (md *MongoDatabase) GetReportSubscription(id primitive.ObjectID) (*model.ReportSubscription, error) {
+	res := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportSubscriptionCollection).
+		FindOne(context.TODO(), bson.M{"_id": id})
+	if res.Err() == mongo.ErrNoDocuments {
+		return nil, utils.ErrReportSubscriptionNotFound
+	} else if res.Err() != nil {
+		return nil, utils.NewError(res.Err(), "DB ERROR")
+	}
+
+	var out model.ReportSubscription
+	if err := res.Decode(&out); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return &out, nil
+}
+
+func (md *MongoDatabase) InsertReportSubscription(subscription model.ReportSubscription) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportSubscriptionCollection).
+		InsertOne(context.TODO(), subscription)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+func (md *MongoDatabase) UpdateReportSubscription(subscription model.ReportSubscription) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportSubscriptionCollection).
+		ReplaceOne(context.TODO(), bson.M{"_id": subscription.ID}, subscription)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.MatchedCount == 0 {
+		return utils.ErrReportSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// UpdateReportSubscriptionLastRun set the date of the last run of the report subscription
+func (md *MongoDatabase) UpdateReportSubscriptionLastRun(id primitive.ObjectID, lastRun time.Time) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportSubscriptionCollection).
+		UpdateOne(context.TODO(), bson.M{"_id": id}, bson.M{"$set": bson.M{"lastRun": lastRun}})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.MatchedCount == 0 {
+		return utils.ErrReportSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// DeleteReportSubscription delete the report subscription and its runs
+func (md *MongoDatabase) DeleteReportSubscription(id primitive.ObjectID) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportSubscriptionCollection).
+		DeleteOne(context.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.DeletedCount == 0 {
+		return utils.ErrReportSubscriptionNotFound
+	}
+
+	_, err = md.Client.Database(md.Config.Mongodb.DBName).Collection(reportRunCollection).
+		DeleteMany(context.TODO(), bson.M{"subscriptionID": id})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+func (md *MongoDatabase) InsertReportRun(run model.ReportRun) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportRunCollection).
+		InsertOne(context.TODO(), run)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// ListReportRuns return the runs of the report subscription, the most recent first
+func (md *MongoDatabase) ListReportRuns(subscriptionID primitive.ObjectID) ([]model.ReportRun, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}})
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(reportRunCollection).
+		Find(context.TODO(), bson.M{"subscriptionID": subscriptionID}, opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	runs := make([]model.ReportRun, 0)
+
+	if err := cur.All(context.TODO(), &runs); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestReportSubscriptions() {
+	for _, collection := range []string{reportSubscriptionCollection, reportRunCollection} {
+		_, err := m.db.Client.Database(m.dbname).Collection(collection).DeleteMany(context.TODO(), bson.M{})
+		m.Require().NoError(err)
+
+		defer m.db.Client.Database(m.dbname).Collection(collection).DeleteMany(context.TODO(), bson.M{})
+	}
+
+	hosts := model.ReportSubscription{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1b2d1"),
+		Name:       "Weekly hosts",
+		Report:     model.ReportHosts,
+		Filters:    model.ReportFilters{Location: "Italy"},
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatXLSX,
+		Recipients: []string{"dba@ercole.test"},
+		Enabled:    true,
+		CreatedAt:  utils.P("2024-03-01T10:00:00Z"),
+	}
+	compliance := model.ReportSubscription{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1b2d2"),
+		Name:       "Daily compliance",
+		Report:     model.ReportLicensesCompliance,
+		Schedule:   "@daily",
+		Format:     model.ReportFormatPDF,
+		Recipients: []string{"procurement@ercole.test"},
+		Enabled:    true,
+		CreatedAt:  utils.P("2024-03-01T10:00:00Z"),
+	}
+
+	m.Require().NoError(m.db.InsertReportSubscription(hosts))
+	m.Require().NoError(m.db.InsertReportSubscription(compliance))
+
+	actual, err := m.db.ListReportSubscriptions()
+	m.Require().NoError(err)
+	m.Assert().Equal([]model.ReportSubscription{compliance, hosts}, actual)
+
+	hosts.Format = model.ReportFormatCSV
+	m.Require().NoError(m.db.UpdateReportSubscription(hosts))
+
+	lastRun := utils.P("2024-03-04T07:00:00Z")
+	m.Require().NoError(m.db.UpdateReportSubscriptionLastRun(hosts.ID, lastRun))
+	hosts.LastRun = &lastRun
+
+	got, err := m.db.GetReportSubscription(hosts.ID)
+	m.Require().NoError(err)
+	m.Assert().Equal(hosts, *got)
+
+	runs := []model.ReportRun{
+		{
+			ID:             utils.Str2oid("654a1d2f3b8e7c0001a1b2e1"),
+			SubscriptionID: hosts.ID,
+			Date:           utils.P("2024-03-04T07:00:00Z"),
+			Trigger:        model.ReportRunTriggerSchedule,
+			Success:        true,
+			Recipients:     []string{"dba@ercole.test"},
+			Attachments:    []string{"hosts.csv"},
+		},
+		{
+			ID:             utils.Str2oid("654a1d2f3b8e7c0001a1b2e2"),
+			SubscriptionID: hosts.ID,
+			Date:           utils.P("2024-03-05T09:30:00Z"),
+			Trigger:        model.ReportRunTriggerManual,
+			Success:        false,
+			Error:          "Emailer is disabled",
+			Recipients:     []string{"dba@ercole.test"},
+			Attachments:    []string{},
+		},
+	}
+
+	for _, run := range runs {
+		m.Require().NoError(m.db.InsertReportRun(run))
+	}
+
+	actualRuns, err := m.db.ListReportRuns(hosts.ID)
+	m.Require().NoError(err)
+	m.Assert().Equal([]model.ReportRun{runs[1], runs[0]}, actualRuns)
+
+	m.Require().NoError(m.db.DeleteReportSubscription(hosts.ID))
+
+	actualRuns, err = m.db.ListReportRuns(hosts.ID)
+	m.Require().NoError(err)
+	m.Assert().Empty(actualRuns)
+
+	_, err = m.db.GetReportSubscription(hosts.ID)
+	m.Assert().ErrorIs(err, utils.ErrReportSubscriptionNotFound)
+	m.Assert().ErrorIs(m.db.DeleteReportSubscription(hosts.ID), utils.ErrReportSubscriptionNotFound)
+	m.Assert().ErrorIs(m.db.UpdateReportSubscription(model.ReportSubscription{ID: primitive.NewObjectID()}), utils.ErrReportSubscriptionNotFound)
+	m.Assert().ErrorIs(m.db.UpdateReportSubscriptionLastRun(primitive.NewObjectID(), lastRun), utils.ErrReportSubscriptionNotFound)
+}
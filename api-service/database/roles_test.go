@@ -96,3 +96,58 @@ func (m *MongodbSuite) TestGetRole() {
 		assert.Equal(t, roles, actual)
 	})
 }
+
+func (m *MongodbSuite) TestGetUserRoles() {
+	defer m.db.Client.Database(m.dbname).Collection(userCollection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(groupCollection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(roleCollection).DeleteMany(context.TODO(), bson.M{})
+
+	roles := []model.Role{
+		{
+			Name:       "read_italy",
+			Permission: model.ReadPermission,
+			Locations:  []string{"Italy"},
+		},
+		{
+			Name:       "write_germany",
+			Permission: model.WritePermission,
+			Locations:  []string{"Germany"},
+		},
+		{
+			Name:       "write_all",
+			Permission: model.WritePermission,
+			Locations:  model.AllLocations,
+		},
+	}
+	_, err := m.db.Client.Database(m.dbname).Collection(roleCollection).
+		InsertMany(context.TODO(), []interface{}{roles[0], roles[1], roles[2]})
+	require.Nil(m.T(), err)
+
+	_, err = m.db.Client.Database(m.dbname).Collection(groupCollection).
+		InsertMany(context.TODO(), []interface{}{
+			model.Group{Name: "readers", Roles: []string{"read_italy"}},
+			model.Group{Name: "writers", Roles: []string{"write_germany"}},
+		})
+	require.Nil(m.T(), err)
+
+	_, err = m.db.Client.Database(m.dbname).Collection(userCollection).
+		InsertMany(context.TODO(), []interface{}{
+			model.User{Username: "user01", Groups: []string{"readers", "writers"}},
+			model.User{Username: "user02"},
+		})
+	require.Nil(m.T(), err)
+
+	m.T().Run("should_load_roles_of_groups", func(t *testing.T) {
+		actual, err := m.db.GetUserRoles("user01")
+		m.Require().NoError(err)
+
+		assert.ElementsMatch(t, roles[:2], actual)
+	})
+
+	m.T().Run("should_load_empty", func(t *testing.T) {
+		actual, err := m.db.GetUserRoles("user02")
+		m.Require().NoError(err)
+
+		assert.Equal(t, []model.Role{}, actual)
+	})
+}
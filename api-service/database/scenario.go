@@ -24,10 +24,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const (
-	scenarioCollection      = "scenarios"
-	simulatedHostCollection = "simulated_hosts"
-)
+const scenarioCollection = "scenarios"
 
 func (md *MongoDatabase) CreateScenario(scenario *model.Scenario) (*model.Scenario, error) {
 	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(scenarioCollection).InsertOne(context.Background(), scenario)
@@ -42,74 +39,6 @@ func (md *MongoDatabase) CreateScenario(scenario *model.Scenario) (*model.Scenar
 	return scenario, nil
 }
 
-func (md *MongoDatabase) CreateSimulatedHosts(hosts ...model.SimulatedHost) error {
-	var docs []any
-	for _, h := range hosts {
-		docs = append(docs, h)
-	}
-
-	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(simulatedHostCollection).InsertMany(context.Background(), docs)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (md *MongoDatabase) UpdateHostCores(hostname string, cores int) error {
-	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(hostCollection).
-		UpdateOne(
-			context.Background(),
-			bson.M{"hostname": hostname, "archived": false},
-			bson.M{
-				"$set": bson.M{
-					"info.cpuCores": cores,
-				},
-			},
-		)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (md *MongoDatabase) UpdateLicenseCount(hostname string, licenseCount int) error {
-	filter := bson.M{
-		"archived": false,
-		"hostname": hostname,
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"features.oracle.database.databases.$[].licenses.$[].count": licenseCount,
-		},
-	}
-
-	result, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(hostCollection).UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		return err
-	}
-
-	if result.MatchedCount != 1 {
-		return utils.ErrLicenseNotFound
-	}
-
-	return nil
-}
-
-func (md *MongoDatabase) RemoveSimulatedHost(id primitive.ObjectID) error {
-	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(simulatedHostCollection).
-		DeleteOne(context.Background(), bson.M{
-			"_id": id,
-		})
-	if err != nil {
-		return utils.NewError(err, "DB ERROR")
-	}
-
-	return nil
-}
-
 func (md *MongoDatabase) GetScenarios() ([]model.Scenario, error) {
 	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(scenarioCollection).
 		Find(context.Background(), bson.D{})
@@ -51,6 +51,7 @@ func (db *MongodbSuite) SetupSuite() {
 				DBName: fmt.Sprintf("ercole_test_%d", rand.Int()),
 			},
 		},
+		TimeNow: time.Now,
 	}
 	if !ok {
 		db.db.Config.Mongodb.URI = "mongodb://127.0.0.1:27017"
@@ -184,3 +184,50 @@ func (md *MongoDatabase) GetUserLocations(username string) ([]string, error) {
 
 	return locations, nil
 }
+
+// GetUserRoles returns the roles granted to the user by the groups it belongs to
+func (md *MongoDatabase) GetUserRoles(username string) ([]model.Role, error) {
+	ctx := context.TODO()
+
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "username", Value: username}}}},
+		bson.D{{Key: "$unwind", Value: "$groups"}},
+		bson.D{
+			{Key: "$lookup",
+				Value: bson.D{
+					{Key: "from", Value: "groups"},
+					{Key: "localField", Value: "groups"},
+					{Key: "foreignField", Value: "name"},
+					{Key: "as", Value: "groupDetails"},
+				},
+			},
+		},
+		bson.D{{Key: "$unwind", Value: "$groupDetails"}},
+		bson.D{{Key: "$unwind", Value: "$groupDetails.roles"}},
+		bson.D{
+			{Key: "$lookup",
+				Value: bson.D{
+					{Key: "from", Value: roleCollection},
+					{Key: "localField", Value: "groupDetails.roles"},
+					{Key: "foreignField", Value: "name"},
+					{Key: "as", Value: "roleDetails"},
+				},
+			},
+		},
+		bson.D{{Key: "$unwind", Value: "$roleDetails"}},
+		bson.D{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: "$roleDetails"}}}},
+		bson.D{{Key: "$project", Value: bson.D{{Key: "_id", Value: 0}}}},
+	}
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(userCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	roles := make([]model.Role, 0)
+	if err := cur.All(ctx, &roles); err != nil {
+		return nil, utils.NewError(err, "Decode ERROR")
+	}
+
+	return roles, nil
+}
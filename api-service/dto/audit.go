@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dto
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+type AuditEntriesResponse struct {
+	Content  []model.AuditEntry `json:"content" bson:"content"`
+	Metadata PagingMetadata     `json:"metadata" bson:"metadata"`
+}
+
+// AuditFilter contains the filters used to search the audit log
+type AuditFilter struct {
+	Username string
+	Service  string
+	Method   string
+	Route    string
+	// Search is searched in the path of the requests, that contains the ids of the modified entities
+	Search string
+	From   time.Time
+	To     time.Time
+
+	// PageNumber starts from 0, all the entries are returned if PageSize is 0
+	PageNumber int
+	PageSize   int
+}
+
+func GetAuditFilter(r *http.Request) (*AuditFilter, error) {
+	var err error
+
+	f := &AuditFilter{
+		Username: r.URL.Query().Get("username"),
+		Service:  r.URL.Query().Get("service"),
+		Method:   r.URL.Query().Get("method"),
+		Route:    r.URL.Query().Get("route"),
+		Search:   r.URL.Query().Get("search"),
+	}
+
+	if f.From, err = utils.Str2time(r.URL.Query().Get("from"), utils.MIN_TIME); err != nil {
+		return nil, err
+	}
+
+	if f.To, err = utils.Str2time(r.URL.Query().Get("to"), utils.MAX_TIME); err != nil {
+		return nil, err
+	}
+
+	if f.PageNumber, err = utils.Str2int(r.URL.Query().Get("page"), 0); err != nil {
+		return nil, err
+	}
+
+	if f.PageSize, err = utils.Str2int(r.URL.Query().Get("size"), 0); err != nil {
+		return nil, err
+	}
+
+	if f.PageNumber < 0 || f.PageSize < 0 {
+		return nil, utils.NewErrorf("%w: page and size can't be negative", utils.ErrInvalidAuditFilter)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package dto
+
+// Kinds of the inconsistencies between the clusters reported by the hypervisors and the hosts
+const (
+	// ClusterIssueUnknownVM is a VM reported by a hypervisor whose host hasn't sent any hostdata
+	ClusterIssueUnknownVM = "UNKNOWN_VM"
+	// ClusterIssueVirtualHostWithoutCluster is a virtual host that isn't in any cluster reported by the hypervisors
+	ClusterIssueVirtualHostWithoutCluster = "VIRTUAL_HOST_WITHOUT_CLUSTER"
+	// ClusterIssueVMInMultipleClusters is a VM reported by more than one cluster
+	ClusterIssueVMInMultipleClusters = "VM_IN_MULTIPLE_CLUSTERS"
+	// ClusterIssueCappedCPUMismatch is a VM whose capped CPU is reported differently by the hypervisors,
+	// or that isn't capped while the other VMs of the cluster using its licenses are
+	ClusterIssueCappedCPUMismatch = "CAPPED_CPU_MISMATCH"
+	// ClusterIssueVeritasMembershipMismatch is a host whose Veritas cluster members don't agree on the membership
+	ClusterIssueVeritasMembershipMismatch = "VERITAS_MEMBERSHIP_MISMATCH"
+)
+
+// ClusterReconciliationIssue is an inconsistency between the clusters and the hosts
+type ClusterReconciliationIssue struct {
+	Kind     string `json:"kind"`
+	Hostname string `json:"hostname"`
+	// VMName is the name of the VM in the hypervisor, if it's reported by one
+	VMName         string   `json:"vmName,omitempty"`
+	Clusters       []string `json:"clusters"`
+	LicenseTypeIDs []string `json:"licenseTypeIDs,omitempty"`
+	Location       string   `json:"location"`
+	Environment    string   `json:"environment"`
+	Description    string   `json:"description"`
+}
+
+// ClusterReconciliation contains the inconsistencies found between the clusters and the hosts
+type ClusterReconciliation struct {
+	Issues []ClusterReconciliationIssue `json:"issues"`
+	// Counts contains the number of issues of each kind
+	Counts map[string]int `json:"counts"`
+}
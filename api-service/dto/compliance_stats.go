@@ -29,4 +29,6 @@ type Stats struct {
 	HostCount               int     `json:"hostCount"`
 	CompliancePercentageVal float64 `json:"compliancePercentageVal"`
 	CompliancePercentageStr string  `json:"compliancePercentageStr"`
+	// ConsumedLicensesPerCloud are the licenses consumed by hosts in each authorized cloud environment
+	ConsumedLicensesPerCloud map[string]float64 `json:"consumedLicensesPerCloud,omitempty"`
 }
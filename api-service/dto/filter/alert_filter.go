@@ -34,4 +34,13 @@ type Alert struct {
 	From        time.Time `json:"from"`
 	To          time.Time `json:"to"`
 	OlderThan   time.Time `json:"olderThan"`
+
+	AlertLifecycle
+}
+
+// AlertLifecycle contains the filters on the assignment, comments and resolution of the alerts
+type AlertLifecycle struct {
+	Assignee         string `json:"assignee"`
+	ResolutionReason string `json:"resolutionReason"`
+	Comment          string `json:"comment"`
 }
@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dto
+
+import "time"
+
+// Categories of the changes of a host between two snapshots
+const (
+	HostChangeCategoryHost     = "host"
+	HostChangeCategoryCluster  = "cluster"
+	HostChangeCategoryDatabase = "database"
+	HostChangeCategoryPdb      = "pdb"
+	HostChangeCategorySchema   = "schema"
+	HostChangeCategoryOption   = "option"
+	HostChangeCategoryPatch    = "patch"
+	HostChangeCategoryLicense  = "license"
+)
+
+// Actions of the changes of a host between two snapshots
+const (
+	HostChangeAdded   = "added"
+	HostChangeRemoved = "removed"
+	HostChangeChanged = "changed"
+)
+
+// HostChange is a single difference between two snapshots of a host
+type HostChange struct {
+	Category   string      `json:"category"`
+	Action     string      `json:"action"`
+	Technology string      `json:"technology,omitempty"`
+	Database   string      `json:"database,omitempty"`
+	Item       string      `json:"item"`
+	Old        interface{} `json:"old,omitempty"`
+	New        interface{} `json:"new,omitempty"`
+}
+
+// HostDiff contains what changed on a host between two dates
+type HostDiff struct {
+	Hostname string    `json:"hostname"`
+	Location string    `json:"location"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	// FromSnapshot is nil when the host didn't exist yet at the from date
+	FromSnapshot *time.Time   `json:"fromSnapshot"`
+	ToSnapshot   time.Time    `json:"toSnapshot"`
+	Changes      []HostChange `json:"changes"`
+}
+
+// HostTimelineEntry contains the changes introduced by a snapshot of the host
+type HostTimelineEntry struct {
+	Date    time.Time    `json:"date"`
+	Changes []HostChange `json:"changes"`
+}
+
+// HostTimeline contains the snapshots of a host that changed something in a period
+type HostTimeline struct {
+	Hostname string              `json:"hostname"`
+	Location string              `json:"location"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Entries  []HostTimelineEntry `json:"entries"`
+}
+
+// HostVirtualizationCluster is the virtualization cluster running a host
+type HostVirtualizationCluster struct {
+	Name               string `json:"name" bson:"name"`
+	VirtualizationNode string `json:"virtualizationNode" bson:"virtualizationNode"`
+}
@@ -122,4 +122,6 @@ type HostUsingOracleDatabaseLicenses struct {
 	// Original value of licenseCount (UncoveredLicenses), DO NOT EDIT!
 	// If LicenseType Metric is Named User Plus Perpetual, value isn't PerUser (must be multiplied *25)
 	OriginalCount float64 `json:"originalCount" bson:"originalCount"`
+	// CloudMembership is the authorized cloud environment of the host, if any
+	CloudMembership string `json:"cloudMembership,omitempty" bson:"cloudMembership,omitempty"`
 }
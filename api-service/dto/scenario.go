@@ -21,9 +21,13 @@ import (
 )
 
 type CreateScenarioRequest struct {
-	Name     string                      `json:"name"`
-	Location string                      `json:"location"`
-	Hosts    []CreateHostScenarioRequest `json:"hosts"`
+	Name               string                             `json:"name"`
+	Location           string                             `json:"location"`
+	Hosts              []CreateHostScenarioRequest        `json:"hosts"`
+	AddHosts           []AddHostScenarioRequest           `json:"addHosts"`
+	RemoveHosts        []string                           `json:"removeHosts"`
+	MoveHosts          []MoveHostScenarioRequest          `json:"moveHosts"`
+	LicenseTypeChanges []ChangeLicenseTypeScenarioRequest `json:"licenseTypeChanges"`
 }
 
 type CreateHostScenarioRequest struct {
@@ -31,12 +35,43 @@ type CreateHostScenarioRequest struct {
 	Core     int    `json:"core"`
 }
 
+type AddHostScenarioRequest struct {
+	Hostname    string                       `json:"hostname"`
+	Location    string                       `json:"location"`
+	Environment string                       `json:"environment"`
+	Cluster     string                       `json:"cluster"`
+	Core        int                          `json:"core"`
+	Databases   []AddDatabaseScenarioRequest `json:"databases"`
+}
+
+type AddDatabaseScenarioRequest struct {
+	Name           string   `json:"name"`
+	Version        string   `json:"version"`
+	LicenseTypeIDs []string `json:"licenseTypeIDs"`
+}
+
+type MoveHostScenarioRequest struct {
+	Hostname string `json:"hostname"`
+	Cluster  string `json:"cluster"`
+}
+
+type ChangeLicenseTypeScenarioRequest struct {
+	Hostname          string `json:"hostname"`
+	DbName            string `json:"dbName"`
+	FromLicenseTypeID string `json:"fromLicenseTypeID"`
+	ToLicenseTypeID   string `json:"toLicenseTypeID"`
+}
+
 type ScenarioResponse struct {
-	ID        string          `json:"id"`
-	Name      string          `json:"name"`
-	CreatedAt time.Time       `json:"createdAt"`
-	Location  string          `json:"location"`
-	Hosts     []SimulatedHost `json:"hosts"`
+	ID                 string              `json:"id"`
+	Name               string              `json:"name"`
+	CreatedAt          time.Time           `json:"createdAt"`
+	Location           string              `json:"location"`
+	Hosts              []SimulatedHost     `json:"hosts"`
+	HypotheticalHosts  []HypotheticalHost  `json:"hypotheticalHosts"`
+	RemovedHosts       []string            `json:"removedHosts"`
+	MovedHosts         []MovedHost         `json:"movedHosts"`
+	LicenseTypeChanges []LicenseTypeChange `json:"licenseTypeChanges"`
 }
 
 type SimulatedHost struct {
@@ -55,18 +90,81 @@ func ToSimulatedHost(m model.SimulatedHost) SimulatedHost {
 	}
 }
 
+type HypotheticalHost struct {
+	Hostname    string   `json:"hostname"`
+	Location    string   `json:"location"`
+	Environment string   `json:"environment"`
+	Cluster     string   `json:"cluster"`
+	Core        int      `json:"core"`
+	Databases   []string `json:"databases"`
+}
+
+func ToHypotheticalHost(m model.HypotheticalHost) HypotheticalHost {
+	databases := make([]string, 0)
+
+	if m.Host.Features.Oracle != nil && m.Host.Features.Oracle.Database != nil {
+		for _, db := range m.Host.Features.Oracle.Database.Databases {
+			databases = append(databases, db.Name)
+		}
+	}
+
+	return HypotheticalHost{
+		Hostname:    m.Host.Hostname,
+		Location:    m.Host.Location,
+		Environment: m.Host.Environment,
+		Cluster:     m.Cluster,
+		Core:        m.Host.Info.CPUCores,
+		Databases:   databases,
+	}
+}
+
+type MovedHost struct {
+	Hostname    string `json:"hostname"`
+	FromCluster string `json:"fromCluster"`
+	ToCluster   string `json:"toCluster"`
+}
+
+type LicenseTypeChange struct {
+	Hostname          string `json:"hostname"`
+	DbName            string `json:"dbName"`
+	FromLicenseTypeID string `json:"fromLicenseTypeID"`
+	ToLicenseTypeID   string `json:"toLicenseTypeID"`
+}
+
 func ToScenarioResponse(m model.Scenario) ScenarioResponse {
 	simulatedHosts := make([]SimulatedHost, 0)
 	for _, host := range m.Hosts {
 		simulatedHosts = append(simulatedHosts, ToSimulatedHost(host))
 	}
 
+	hypotheticalHosts := make([]HypotheticalHost, 0)
+	for _, host := range m.HypotheticalHosts {
+		hypotheticalHosts = append(hypotheticalHosts, ToHypotheticalHost(host))
+	}
+
+	removedHosts := make([]string, 0)
+	removedHosts = append(removedHosts, m.RemovedHosts...)
+
+	movedHosts := make([]MovedHost, 0)
+	for _, moved := range m.MovedHosts {
+		movedHosts = append(movedHosts, MovedHost(moved))
+	}
+
+	licenseTypeChanges := make([]LicenseTypeChange, 0)
+	for _, change := range m.LicenseTypeChanges {
+		licenseTypeChanges = append(licenseTypeChanges, LicenseTypeChange(change))
+	}
+
 	return ScenarioResponse{
-		ID:        m.ID.Hex(),
-		Name:      m.Name,
-		CreatedAt: m.CreatedAt,
-		Location:  m.Location,
-		Hosts:     simulatedHosts,
+		ID:                 m.ID.Hex(),
+		Name:               m.Name,
+		CreatedAt:          m.CreatedAt,
+		Location:           m.Location,
+		Hosts:              simulatedHosts,
+		HypotheticalHosts:  hypotheticalHosts,
+		RemovedHosts:       removedHosts,
+		MovedHosts:         movedHosts,
+		LicenseTypeChanges: licenseTypeChanges,
 	}
 }
 
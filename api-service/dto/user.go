@@ -24,6 +24,8 @@ type User struct {
 	FirstName string   `json:"firstName"`
 	LastName  string   `json:"lastName"`
 	Groups    []string `json:"groups"`
+
+	ServiceAccount bool `json:"serviceAccount,omitempty"`
 }
 
 type Users []User
@@ -39,6 +41,8 @@ func ToUser(userModel *model.User) User {
 			FirstName: userModel.FirstName,
 			LastName:  userModel.LastName,
 			Groups:    userModel.Groups,
+
+			ServiceAccount: userModel.ServiceAccount,
 		}
 	}
 
@@ -0,0 +1,33 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"github.com/ercole-io/ercole/v2/api-service/service"
+	"github.com/ercole-io/ercole/v2/logger"
+)
+
+// AuditRetentionJob remove the entries of the audit log older than the retention
+type AuditRetentionJob struct {
+	Service service.APIServiceInterface
+	Log     logger.Logger
+}
+
+func (j *AuditRetentionJob) Run() {
+	if err := j.Service.DeleteOldAuditEntries(); err != nil {
+		j.Log.Errorf("audit retention job: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package job contains the jobs of the api-service
+package job
+
+import (
+	"github.com/bamzi/jobrunner"
+
+	"github.com/ercole-io/ercole/v2/api-service/service"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+)
+
+type Job struct {
+	Config  config.Configuration
+	Service service.APIServiceInterface
+	Log     logger.Logger
+}
+
+func (j *Job) Init() {
+	j.Log.Infof("init api-service jobs")
+
+	jobrunner.Start()
+
+	reportSubscriptionJob := ReportSubscriptionJob{Service: j.Service, Log: j.Log}
+	if err := jobrunner.Schedule(j.Config.APIService.ReportSubscriptionJob.Crontab, &reportSubscriptionJob); err != nil {
+		j.Log.Errorf("something went wrong scheduling reportSubscriptionJob: %v", err)
+	}
+
+	if j.Config.APIService.ReportSubscriptionJob.RunAtStartup {
+		jobrunner.Now(&reportSubscriptionJob)
+	}
+
+	auditRetentionJob := AuditRetentionJob{Service: j.Service, Log: j.Log}
+	if err := jobrunner.Schedule(j.Config.APIService.AuditRetentionJob.Crontab, &auditRetentionJob); err != nil {
+		j.Log.Errorf("something went wrong scheduling auditRetentionJob: %v", err)
+	}
+
+	if j.Config.APIService.AuditRetentionJob.RunAtStartup {
+		jobrunner.Now(&auditRetentionJob)
+	}
+}
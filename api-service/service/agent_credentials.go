@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+// agentSecretHintLength is the number of characters of the secret, after the prefix, kept as hint
+const agentSecretHintLength = 4
+
+// ListAgentCredentials return the credentials enrolled for the agents
+func (as *APIService) ListAgentCredentials() ([]model.AgentCredential, error) {
+	return as.Database.ListAgentCredentials()
+}
+
+// EnrollAgentCredential create the credential of the agent of the host, returning it with its secret.
+// A revoked credential is replaced by the new one
+func (as *APIService) EnrollAgentCredential(hostname string) (*model.AgentCredential, string, error) {
+	if strings.TrimSpace(hostname) == "" {
+		return nil, "", utils.NewErrorf("%w: hostname is empty", utils.ErrInvalidAgentCredential)
+	}
+
+	existing, err := as.Database.GetAgentCredential(hostname)
+	if err == nil && !existing.IsRevoked() {
+		return nil, "", utils.NewErrorf("%w: %s", utils.ErrAgentCredentialAlreadyExists, hostname)
+	} else if err != nil && !errors.Is(err, utils.ErrAgentCredentialNotFound) {
+		return nil, "", err
+	}
+
+	credential := model.AgentCredential{
+		ID:        as.NewObjectID(),
+		Hostname:  hostname,
+		CreatedAt: as.TimeNow(),
+	}
+
+	secret, err := as.setAgentSecret(&credential)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &credential, secret, nil
+}
+
+// RotateAgentCredential replace the secret of the credential of the host, returning it with the new secret.
+// The previous secret can't be used anymore
+func (as *APIService) RotateAgentCredential(hostname string) (*model.AgentCredential, string, error) {
+	credential, err := as.Database.GetAgentCredential(hostname)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if credential.IsRevoked() {
+		return nil, "", utils.NewErrorf("%w: the credential of %s has been revoked", utils.ErrInvalidAgentCredential, hostname)
+	}
+
+	now := as.TimeNow()
+	credential.RotatedAt = &now
+
+	secret, err := as.setAgentSecret(credential)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return credential, secret, nil
+}
+
+// RevokeAgentCredential revoke the credential of the host, whose agent can't send data anymore
+func (as *APIService) RevokeAgentCredential(hostname string) error {
+	return as.Database.RevokeAgentCredential(hostname, as.TimeNow())
+}
+
+func (as *APIService) setAgentSecret(credential *model.AgentCredential) (string, error) {
+	secret, err := cr.GenerateToken(model.AgentSecretPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	credential.Hash = cr.HashToken(secret)
+	credential.Hint = secret[:len(model.AgentSecretPrefix)+agentSecretHintLength]
+
+	if err := as.Database.SaveAgentCredential(*credential); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
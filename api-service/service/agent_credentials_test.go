@@ -0,0 +1,151 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+func TestEnrollAgentCredential_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	t.Run("New host", func(t *testing.T) {
+		var saved model.AgentCredential
+
+		gomock.InOrder(
+			db.EXPECT().GetAgentCredential("rac1").Return(nil, utils.ErrAgentCredentialNotFound),
+			db.EXPECT().SaveAgentCredential(gomock.Any()).DoAndReturn(func(credential model.AgentCredential) error {
+				saved = credential
+				return nil
+			}),
+		)
+
+		actual, secret, err := as.EnrollAgentCredential("rac1")
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(secret, model.AgentSecretPrefix))
+		assert.Equal(t, cr.HashToken(secret), actual.Hash)
+		assert.Equal(t, secret[:len(model.AgentSecretPrefix)+agentSecretHintLength], actual.Hint)
+		assert.Equal(t, "rac1", actual.Hostname)
+		assert.Equal(t, utils.P("2024-03-11T08:00:00Z"), actual.CreatedAt)
+		assert.Equal(t, *actual, saved)
+	})
+
+	t.Run("Revoked credential", func(t *testing.T) {
+		revokedAt := utils.P("2024-03-01T08:00:00Z")
+
+		gomock.InOrder(
+			db.EXPECT().GetAgentCredential("rac2").
+				Return(&model.AgentCredential{Hostname: "rac2", Hash: "old", RevokedAt: &revokedAt}, nil),
+			db.EXPECT().SaveAgentCredential(gomock.Any()).Return(nil),
+		)
+
+		actual, secret, err := as.EnrollAgentCredential("rac2")
+		require.NoError(t, err)
+
+		assert.Equal(t, cr.HashToken(secret), actual.Hash)
+		assert.False(t, actual.IsRevoked())
+	})
+}
+
+func TestEnrollAgentCredential_Fail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	t.Run("Empty hostname", func(t *testing.T) {
+		_, _, err := as.EnrollAgentCredential(" ")
+		assert.ErrorIs(t, err, utils.ErrInvalidAgentCredential)
+	})
+
+	t.Run("Already enrolled", func(t *testing.T) {
+		db.EXPECT().GetAgentCredential("rac1").Return(&model.AgentCredential{Hostname: "rac1"}, nil)
+
+		_, _, err := as.EnrollAgentCredential("rac1")
+		assert.ErrorIs(t, err, utils.ErrAgentCredentialAlreadyExists)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		db.EXPECT().GetAgentCredential("rac1").Return(nil, errMock)
+
+		_, _, err := as.EnrollAgentCredential("rac1")
+		assert.ErrorIs(t, err, errMock)
+	})
+}
+
+func TestRotateAgentCredential(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	t.Run("Success", func(t *testing.T) {
+		credential := model.AgentCredential{
+			ID:        utils.Str2oid("654a1d2f3b8e7c0001a1c4d1"),
+			Hostname:  "rac1",
+			Hash:      "old",
+			CreatedAt: utils.P("2024-03-01T08:00:00Z"),
+		}
+
+		gomock.InOrder(
+			db.EXPECT().GetAgentCredential("rac1").Return(&credential, nil),
+			db.EXPECT().SaveAgentCredential(gomock.Any()).Return(nil),
+		)
+
+		actual, secret, err := as.RotateAgentCredential("rac1")
+		require.NoError(t, err)
+
+		assert.Equal(t, cr.HashToken(secret), actual.Hash)
+		assert.Equal(t, credential.ID, actual.ID)
+		assert.Equal(t, utils.P("2024-03-01T08:00:00Z"), actual.CreatedAt)
+		assert.Equal(t, utils.P("2024-03-11T08:00:00Z"), *actual.RotatedAt)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		db.EXPECT().GetAgentCredential("rac9").Return(nil, utils.ErrAgentCredentialNotFound)
+
+		_, _, err := as.RotateAgentCredential("rac9")
+		assert.ErrorIs(t, err, utils.ErrAgentCredentialNotFound)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		revokedAt := utils.P("2024-03-01T08:00:00Z")
+		db.EXPECT().GetAgentCredential("rac2").Return(&model.AgentCredential{Hostname: "rac2", RevokedAt: &revokedAt}, nil)
+
+		_, _, err := as.RotateAgentCredential("rac2")
+		assert.ErrorIs(t, err, utils.ErrInvalidAgentCredential)
+	})
+}
+
+func TestRevokeAgentCredential(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	db.EXPECT().RevokeAgentCredential("rac1", utils.P("2024-03-11T08:00:00Z")).Return(nil)
+
+	require.NoError(t, as.RevokeAgentCredential("rac1"))
+}
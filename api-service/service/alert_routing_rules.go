@@ -0,0 +1,102 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/schema"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (as *APIService) ListAlertRoutingRules() ([]model.AlertRoutingRule, error) {
+	return as.Database.ListAlertRoutingRules()
+}
+
+func (as *APIService) GetAlertRoutingRule(id primitive.ObjectID) (*model.AlertRoutingRule, error) {
+	return as.Database.GetAlertRoutingRule(id)
+}
+
+func (as *APIService) AddAlertRoutingRule(rule model.AlertRoutingRule) (*model.AlertRoutingRule, error) {
+	if err := validateAlertRoutingRule(rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = primitive.NewObjectID()
+
+	if err := as.Database.InsertAlertRoutingRule(rule); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (as *APIService) UpdateAlertRoutingRule(rule model.AlertRoutingRule) (*model.AlertRoutingRule, error) {
+	if err := validateAlertRoutingRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := as.Database.UpdateAlertRoutingRule(rule); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (as *APIService) DeleteAlertRoutingRule(id primitive.ObjectID) error {
+	return as.Database.DeleteAlertRoutingRule(id)
+}
+
+func (as *APIService) DryRunAlertRouting(alertID primitive.ObjectID) (*model.AlertRoute, error) {
+	alert, err := as.Database.FindAlert(alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := as.Database.ListAlertRoutingRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var host *model.HostDataBE
+
+	if hostname, ok := alert.OtherInfo["hostname"]; ok {
+		hostdata, err := as.Database.FindHostData(fmt.Sprint(hostname))
+		if err == nil {
+			host = &hostdata
+		} else if !errors.Is(err, utils.ErrHostNotFound) {
+			return nil, err
+		}
+	}
+
+	route := model.RouteAlert(rules, *alert, host)
+
+	return &route, nil
+}
+
+func validateAlertRoutingRule(rule model.AlertRoutingRule) error {
+	raw, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	return schema.ValidateAlertRoutingRule(raw)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2022 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestAddAlertRoutingRule(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		rule := model.AlertRoutingRule{
+			Name:       "dba",
+			Enabled:    true,
+			Priority:   1,
+			Match:      model.AlertRoutingMatch{Severities: []string{model.AlertSeverityCritical}},
+			Recipients: []string{"dba@ercole.test"},
+		}
+
+		db.EXPECT().InsertAlertRoutingRule(gomock.Any()).
+			DoAndReturn(func(actual model.AlertRoutingRule) error {
+				assert.False(t, actual.ID.IsZero())
+				assert.Equal(t, rule.Name, actual.Name)
+
+				return nil
+			}).Times(1)
+
+		actual, err := as.AddAlertRoutingRule(rule)
+		require.NoError(t, err)
+		assert.False(t, actual.ID.IsZero())
+	})
+
+	t.Run("Invalid rule", func(t *testing.T) {
+		rule := model.AlertRoutingRule{
+			Name:       "wrong",
+			Recipients: []string{"not an email"},
+		}
+
+		actual, err := as.AddAlertRoutingRule(rule)
+		require.ErrorIs(t, err, utils.ErrInvalidAlertRoutingRule)
+		assert.Nil(t, actual)
+	})
+}
+
+func TestUpdateAlertRoutingRule(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+	}
+
+	rule := model.AlertRoutingRule{
+		ID:       utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+		Name:     "dba",
+		Enabled:  true,
+		Priority: 1,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		db.EXPECT().UpdateAlertRoutingRule(rule).Return(nil).Times(1)
+
+		actual, err := as.UpdateAlertRoutingRule(rule)
+		require.NoError(t, err)
+		assert.Equal(t, &rule, actual)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		db.EXPECT().UpdateAlertRoutingRule(rule).Return(utils.ErrAlertRoutingRuleNotFound).Times(1)
+
+		actual, err := as.UpdateAlertRoutingRule(rule)
+		require.ErrorIs(t, err, utils.ErrAlertRoutingRuleNotFound)
+		assert.Nil(t, actual)
+	})
+}
+
+func TestDryRunAlertRouting(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+	}
+
+	alertID := utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb")
+	alert := model.Alert{
+		ID:            alertID,
+		AlertCode:     model.AlertCodeNoData,
+		AlertSeverity: model.AlertSeverityCritical,
+		OtherInfo:     map[string]interface{}{"hostname": "foobar"},
+	}
+	rules := []model.AlertRoutingRule{
+		{Name: "italy", Enabled: true, Match: model.AlertRoutingMatch{Locations: []string{"Italy"}}, Recipients: []string{"italy@ercole.test"}},
+		{Name: "critical", Enabled: true, Priority: 1, Match: model.AlertRoutingMatch{Severities: []string{model.AlertSeverityCritical}}, Channels: []string{"oncall"}},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		db.EXPECT().FindAlert(alertID).Return(&alert, nil).Times(1)
+		db.EXPECT().ListAlertRoutingRules().Return(rules, nil).Times(1)
+		db.EXPECT().FindHostData("foobar").Return(model.HostDataBE{Hostname: "foobar", Location: "Italy"}, nil).Times(1)
+
+		actual, err := as.DryRunAlertRouting(alertID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"italy@ercole.test"}, actual.Recipients)
+		assert.Equal(t, []string{"oncall"}, actual.Channels)
+		assert.Len(t, actual.Rules, 2)
+	})
+
+	t.Run("Host not found", func(t *testing.T) {
+		db.EXPECT().FindAlert(alertID).Return(&alert, nil).Times(1)
+		db.EXPECT().ListAlertRoutingRules().Return(rules, nil).Times(1)
+		db.EXPECT().FindHostData("foobar").Return(model.HostDataBE{}, utils.ErrHostNotFound).Times(1)
+
+		actual, err := as.DryRunAlertRouting(alertID)
+		require.NoError(t, err)
+		assert.Empty(t, actual.Recipients)
+		assert.Equal(t, []string{"oncall"}, actual.Channels)
+	})
+
+	t.Run("Alert not found", func(t *testing.T) {
+		db.EXPECT().FindAlert(alertID).Return(nil, utils.ErrAlertNotFound).Times(1)
+
+		actual, err := as.DryRunAlertRouting(alertID)
+		require.ErrorIs(t, err, utils.ErrAlertNotFound)
+		assert.Nil(t, actual)
+	})
+}
@@ -17,6 +17,7 @@
 package service
 
 import (
+	"strings"
 	"time"
 
 	"github.com/360EntSecGroup-Skylar/excelize"
@@ -35,8 +36,8 @@ func (as *APIService) SearchAlerts(alertFilter alert_filter.Alert) (*dto.Paginat
 	return as.Database.SearchAlerts(alertFilter)
 }
 
-func (as *APIService) GetAlerts(status string, from, to time.Time, filter dto.GlobalFilter) ([]map[string]interface{}, error) {
-	alerts, err := as.Database.GetAlerts(filter.Location, filter.Environment, status, from, to, filter.OlderThan)
+func (as *APIService) GetAlerts(status string, from, to time.Time, filter dto.GlobalFilter, lifecycle alert_filter.AlertLifecycle) ([]map[string]interface{}, error) {
+	alerts, err := as.Database.GetAlerts(filter.Location, filter.Environment, status, from, to, filter.OlderThan, lifecycle)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +46,8 @@ func (as *APIService) GetAlerts(status string, from, to time.Time, filter dto.Gl
 }
 
 // SearchAlertsAsXLSX return alerts as xlxs file
-func (as *APIService) SearchAlertsAsXLSX(status string, from, to time.Time, filter dto.GlobalFilter) (*excelize.File, error) {
-	alerts, err := as.Database.GetAlerts(filter.Location, filter.Environment, status, from, to, filter.OlderThan)
+func (as *APIService) SearchAlertsAsXLSX(status string, from, to time.Time, filter dto.GlobalFilter, lifecycle alert_filter.AlertLifecycle) (*excelize.File, error) {
+	alerts, err := as.Database.GetAlerts(filter.Location, filter.Environment, status, from, to, filter.OlderThan, lifecycle)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +60,9 @@ func (as *APIService) SearchAlertsAsXLSX(status string, from, to time.Time, filt
 		"Hostname",
 		"Code",
 		"Description",
+		"Status",
+		"Assignee",
+		"Resolution Reason",
 	}
 
 	sheets, err := exutils.NewXLSX(as.Config, sheet, headers...)
@@ -76,12 +80,15 @@ func (as *APIService) SearchAlertsAsXLSX(status string, from, to time.Time, filt
 		sheets.SetCellValue("Alerts", nextAxis(), val["hostname"])
 		sheets.SetCellValue("Alerts", nextAxis(), val["alertCode"])
 		sheets.SetCellValue("Alerts", nextAxis(), val["description"])
+		sheets.SetCellValue("Alerts", nextAxis(), val["alertStatus"])
+		sheets.SetCellValue("Alerts", nextAxis(), val["assignee"])
+		sheets.SetCellValue("Alerts", nextAxis(), val["resolutionReason"])
 	}
 
 	return sheets, nil
 }
 
-func (as *APIService) AckAlerts(alertsFilter dto.AlertsFilter) error {
+func (as *APIService) AckAlerts(alertsFilter dto.AlertsFilter, username string) error {
 	if alertsFilter.AlertCode != nil && *alertsFilter.AlertCode == model.AlertCodeNoData {
 		return utils.NewErrorf("%w: you are trying to ack alerts with code: %s",
 			utils.ErrInvalidAck,
@@ -101,13 +108,104 @@ func (as *APIService) AckAlerts(alertsFilter dto.AlertsFilter) error {
 		}
 	}
 
-	return as.Database.UpdateAlertsStatus(alertsFilter, model.AlertStatusAck)
+	return as.Database.UpdateAlertsStatus(alertsFilter, model.AlertStatusAck, username)
 }
 
 func (as *APIService) RemoveAlertsNODATA(alertsFilter dto.AlertsFilter) error {
 	return as.Database.RemoveAlertsNODATA(alertsFilter)
 }
 
-func (as *APIService) UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus string) error {
-	return as.Database.UpdateAlertsStatus(alertsFilter, newStatus)
+func (as *APIService) UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus, username string) error {
+	return as.Database.UpdateAlertsStatus(alertsFilter, newStatus, username)
+}
+
+// GetAlert return the alert specified by id
+func (as *APIService) GetAlert(id primitive.ObjectID) (*model.Alert, error) {
+	return as.Database.FindAlert(id)
+}
+
+// AssignAlert set the assignee of the alert, an empty assignee remove the assignment
+func (as *APIService) AssignAlert(id primitive.ObjectID, assignee, username string) (*model.Alert, error) {
+	alert, err := as.Database.FindAlert(id)
+	if err != nil {
+		return nil, err
+	}
+
+	change := model.AlertStatusChange{
+		Date:     as.TimeNow(),
+		Username: username,
+		Field:    model.AlertStatusChangeFieldAssignee,
+		From:     alert.Assignee,
+		To:       assignee,
+	}
+
+	if err := as.Database.AssignAlert(id, assignee, change); err != nil {
+		return nil, err
+	}
+
+	alert.Assignee = assignee
+	alert.StatusHistory = append(alert.StatusHistory, change)
+
+	return alert, nil
+}
+
+// CommentAlert add a comment to the alert
+func (as *APIService) CommentAlert(id primitive.ObjectID, text, username string) (*model.Alert, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, utils.NewErrorf("%w: the text is empty", utils.ErrInvalidAlertComment)
+	}
+
+	alert, err := as.Database.FindAlert(id)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := model.AlertComment{
+		Date:     as.TimeNow(),
+		Username: username,
+		Text:     text,
+	}
+
+	if err := as.Database.AddAlertComment(id, comment); err != nil {
+		return nil, err
+	}
+
+	alert.Comments = append(alert.Comments, comment)
+
+	return alert, nil
+}
+
+// ResolveAlert set the alert as resolved for the reason
+func (as *APIService) ResolveAlert(id primitive.ObjectID, reason, username string) (*model.Alert, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, utils.NewErrorf("%w: the reason is empty", utils.ErrInvalidAlertResolution)
+	}
+
+	alert, err := as.Database.FindAlert(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if alert.AlertStatus == model.AlertStatusResolved {
+		return nil, utils.ErrAlertAlreadyResolved
+	}
+
+	change := model.AlertStatusChange{
+		Date:     as.TimeNow(),
+		Username: username,
+		Field:    model.AlertStatusChangeFieldStatus,
+		From:     alert.AlertStatus,
+		To:       model.AlertStatusResolved,
+		Reason:   reason,
+	}
+
+	if err := as.Database.ResolveAlert(id, reason, change); err != nil {
+		return nil, err
+	}
+
+	alert.AlertStatus = model.AlertStatusResolved
+	alert.ResolutionReason = reason
+	alert.StatusHistory = append(alert.StatusHistory, change)
+
+	return alert, nil
 }
@@ -140,9 +140,9 @@ func TestAcknowledgeAlerts(t *testing.T) {
 		}
 
 		db.EXPECT().CountAlertsNODATA(tc.filter).Return(count, nil)
-		db.EXPECT().UpdateAlertsStatus(tc.filter, model.AlertStatusAck).Return(tc.expErr)
+		db.EXPECT().UpdateAlertsStatus(tc.filter, model.AlertStatusAck, "usertest").Return(tc.expErr)
 
-		actErr := as.AckAlerts(tc.filter)
+		actErr := as.AckAlerts(tc.filter, "usertest")
 		assert.Equal(t, tc.expErr, actErr)
 	}
 }
@@ -163,7 +163,7 @@ func TestAcknowledgeAlerts_FailAlertCodeNoData(t *testing.T) {
 
 	as := APIService{}
 
-	actErr := as.AckAlerts(a_ack)
+	actErr := as.AckAlerts(a_ack, "usertest")
 	require.Error(t, actErr, dataErr.Message)
 }
 
@@ -184,7 +184,7 @@ func TestAcknowledgeAlerts_FailCountAlertsNoData(t *testing.T) {
 
 	db.EXPECT().CountAlertsNODATA(a_ack).Return(count, aerrMock)
 
-	actErr := as.AckAlerts(a_ack)
+	actErr := as.AckAlerts(a_ack, "usertest")
 	require.Equal(t, aerrMock, actErr)
 }
 
@@ -204,7 +204,7 @@ func TestAcknowledgeAlerts_FailAckAlertsNoData(t *testing.T) {
 
 	db.EXPECT().CountAlertsNODATA(a_ack).Return(count, nil)
 
-	actErr := as.AckAlerts(a_ack)
+	actErr := as.AckAlerts(a_ack, "usertest")
 	require.Error(t, actErr)
 }
 
@@ -254,7 +254,9 @@ func TestSearchAlertsAsXLSX_Success(t *testing.T) {
 		},
 	}
 
-	db.EXPECT().GetAlerts("Italy", "TST", "NEW", utils.P("2020-06-10T11:54:59Z"), utils.P("2020-06-17T11:54:59Z"), utils.P("2019-12-05T14:02:03Z")).
+	lifecycle := filter.AlertLifecycle{}
+
+	db.EXPECT().GetAlerts("Italy", "TST", "NEW", utils.P("2020-06-10T11:54:59Z"), utils.P("2020-06-17T11:54:59Z"), utils.P("2019-12-05T14:02:03Z"), lifecycle).
 		Return(data, nil).Times(1)
 
 	filter := dto.GlobalFilter{
@@ -266,7 +268,7 @@ func TestSearchAlertsAsXLSX_Success(t *testing.T) {
 	from := utils.P("2020-06-10T11:54:59Z")
 	to := utils.P("2020-06-17T11:54:59Z")
 
-	actual, err := as.SearchAlertsAsXLSX("NEW", from, to, filter)
+	actual, err := as.SearchAlertsAsXLSX("NEW", from, to, filter, lifecycle)
 	require.NoError(t, err)
 	assert.Equal(t, "LICENSE", actual.GetCellValue("Alerts", "A2"))
 	assert.Equal(t, "2020-07-23 08:01:13.746 +0000 UTC", actual.GetCellValue("Alerts", "B2"))
@@ -309,9 +311,128 @@ func TestUpdateAlertsStatus_Success(t *testing.T) {
 			Database: db,
 		}
 
-		db.EXPECT().UpdateAlertsStatus(tc.filter, model.AlertStatusDismissed).Return(tc.expErr)
+		db.EXPECT().UpdateAlertsStatus(tc.filter, model.AlertStatusDismissed, "usertest").Return(tc.expErr)
 
-		actErr := as.UpdateAlertsStatus(tc.filter, model.AlertStatusDismissed)
+		actErr := as.UpdateAlertsStatus(tc.filter, model.AlertStatusDismissed, "usertest")
 		assert.Equal(t, tc.expErr, actErr)
 	}
 }
+
+func TestAssignAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+	alert := model.Alert{ID: id, AlertStatus: model.AlertStatusNew, Assignee: "jdoe"}
+	change := model.AlertStatusChange{
+		Date:     utils.P("2019-11-05T14:02:03Z"),
+		Username: "admin",
+		Field:    model.AlertStatusChangeFieldAssignee,
+		From:     "jdoe",
+		To:       "rossi",
+	}
+
+	db.EXPECT().FindAlert(id).Return(&alert, nil)
+	db.EXPECT().AssignAlert(id, "rossi", change).Return(nil)
+
+	actual, err := as.AssignAlert(id, "rossi", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "rossi", actual.Assignee)
+	assert.Equal(t, []model.AlertStatusChange{change}, actual.StatusHistory)
+}
+
+func TestAssignAlert_NotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+	db.EXPECT().FindAlert(id).Return(nil, utils.ErrAlertNotFound)
+
+	_, err := as.AssignAlert(id, "rossi", "admin")
+	assert.ErrorIs(t, err, utils.ErrAlertNotFound)
+}
+
+func TestCommentAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+
+	t.Run("Success", func(t *testing.T) {
+		alert := model.Alert{ID: id, AlertStatus: model.AlertStatusNew}
+		comment := model.AlertComment{Date: utils.P("2019-11-05T14:02:03Z"), Username: "admin", Text: "contract requested"}
+
+		db.EXPECT().FindAlert(id).Return(&alert, nil)
+		db.EXPECT().AddAlertComment(id, comment).Return(nil)
+
+		actual, err := as.CommentAlert(id, "contract requested", "admin")
+		require.NoError(t, err)
+		assert.Equal(t, []model.AlertComment{comment}, actual.Comments)
+	})
+
+	t.Run("Empty text", func(t *testing.T) {
+		_, err := as.CommentAlert(id, "  ", "admin")
+		assert.ErrorIs(t, err, utils.ErrInvalidAlertComment)
+	})
+}
+
+func TestResolveAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+
+	t.Run("Success", func(t *testing.T) {
+		alert := model.Alert{ID: id, AlertStatus: model.AlertStatusAck}
+		change := model.AlertStatusChange{
+			Date:     utils.P("2019-11-05T14:02:03Z"),
+			Username: "admin",
+			Field:    model.AlertStatusChangeFieldStatus,
+			From:     model.AlertStatusAck,
+			To:       model.AlertStatusResolved,
+			Reason:   "licenses bought",
+		}
+
+		db.EXPECT().FindAlert(id).Return(&alert, nil)
+		db.EXPECT().ResolveAlert(id, "licenses bought", change).Return(nil)
+
+		actual, err := as.ResolveAlert(id, "licenses bought", "admin")
+		require.NoError(t, err)
+		assert.Equal(t, model.AlertStatusResolved, actual.AlertStatus)
+		assert.Equal(t, "licenses bought", actual.ResolutionReason)
+		assert.Equal(t, []model.AlertStatusChange{change}, actual.StatusHistory)
+	})
+
+	t.Run("Already resolved", func(t *testing.T) {
+		alert := model.Alert{ID: id, AlertStatus: model.AlertStatusResolved}
+		db.EXPECT().FindAlert(id).Return(&alert, nil)
+
+		_, err := as.ResolveAlert(id, "licenses bought", "admin")
+		assert.ErrorIs(t, err, utils.ErrAlertAlreadyResolved)
+	})
+
+	t.Run("Empty reason", func(t *testing.T) {
+		_, err := as.ResolveAlert(id, "", "admin")
+		assert.ErrorIs(t, err, utils.ErrInvalidAlertResolution)
+	})
+}
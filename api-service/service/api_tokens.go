@@ -0,0 +1,202 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/schema"
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+const (
+	// apiTokenHintLength is the number of characters of the token, after the prefix, kept as hint
+	apiTokenHintLength = 4
+	// apiTokenLastUsedPrecision avoid to update the last use of a token at every request
+	apiTokenLastUsedPrecision = time.Minute
+)
+
+// ListAPITokens return the API tokens of the user, or the ones of all the users if username is empty
+func (as *APIService) ListAPITokens(username string) ([]model.APIToken, error) {
+	return as.Database.ListAPITokens(username)
+}
+
+// CreateAPIToken validate and insert a new API token of the user, returning it with its value.
+// Only the hash of the value is stored, so it can't be retrieved again
+func (as *APIService) CreateAPIToken(token model.APIToken) (*model.APIToken, string, error) {
+	if token.Locations == nil {
+		token.Locations = []string{}
+	}
+
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := schema.ValidateAPIToken(raw); err != nil {
+		return nil, "", err
+	}
+
+	now := as.TimeNow()
+	if !token.ExpiresAt.After(now) {
+		return nil, "", utils.NewErrorf("%w: expiresAt must be in the future", utils.ErrInvalidAPIToken)
+	}
+
+	if _, err := as.Database.GetUser(token.Username); errors.Is(err, utils.ErrInvalidUser) {
+		return nil, "", utils.NewErrorf("%w: user %s doesn't exist", utils.ErrInvalidAPIToken, token.Username)
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	value, err := cr.GenerateToken(model.APITokenPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token.ID = as.NewObjectID()
+	token.Hash = cr.HashToken(value)
+	token.Hint = value[:len(model.APITokenPrefix)+apiTokenHintLength]
+	token.CreatedAt = now
+	token.LastUsedAt = nil
+	token.RevokedAt = nil
+
+	if err := as.Database.InsertAPIToken(token); err != nil {
+		return nil, "", err
+	}
+
+	return &token, value, nil
+}
+
+// RevokeAPIToken revoke the API token, that can't be used anymore
+func (as *APIService) RevokeAPIToken(id primitive.ObjectID) error {
+	return as.Database.RevokeAPIToken(id, as.TimeNow())
+}
+
+// AuthenticateAPIToken return the owner of the API token and the token itself,
+// if the token is valid and its owner still exists
+func (as *APIService) AuthenticateAPIToken(value string) (*model.User, *model.APIToken, error) {
+	if !strings.HasPrefix(value, model.APITokenPrefix) {
+		return nil, nil, utils.ErrInvalidAPIToken
+	}
+
+	token, err := as.Database.GetAPITokenByHash(cr.HashToken(value))
+	if errors.Is(err, utils.ErrAPITokenNotFound) {
+		return nil, nil, utils.ErrInvalidAPIToken
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	now := as.TimeNow()
+	if !token.IsValid(now) {
+		return nil, nil, utils.ErrInvalidAPIToken
+	}
+
+	owner, err := as.Database.GetUser(token.Username)
+	if errors.Is(err, utils.ErrInvalidUser) {
+		return nil, nil, utils.ErrInvalidAPIToken
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenLastUsedPrecision {
+		if err := as.Database.UpdateAPITokenLastUsed(token.ID, now); err != nil {
+			as.Log.Warnf("Can't update the last use of the API token %s: %s", token.ID.Hex(), err)
+		}
+
+		token.LastUsedAt = &now
+	}
+
+	user := model.User{
+		Username:       owner.Username,
+		FirstName:      owner.FirstName,
+		LastName:       owner.LastName,
+		Groups:         owner.Groups,
+		ServiceAccount: owner.ServiceAccount,
+	}
+
+	return &user, token, nil
+}
+
+// ListServiceAccounts return the users used by the automations
+func (as *APIService) ListServiceAccounts() ([]model.User, error) {
+	users, err := as.Database.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	serviceAccounts := make([]model.User, 0)
+
+	for _, user := range users {
+		if user.ServiceAccount {
+			serviceAccounts = append(serviceAccounts, user)
+		}
+	}
+
+	return serviceAccounts, nil
+}
+
+// AddServiceAccount insert an user without password, that can authenticate only with its API tokens.
+// Its groups grant its roles like for the other users
+func (as *APIService) AddServiceAccount(user model.User) (*model.User, error) {
+	if strings.TrimSpace(user.Username) == "" {
+		return nil, utils.NewErrorf("%w: username is empty", utils.ErrInvalidServiceAccount)
+	}
+
+	if _, err := as.Database.GetUser(user.Username); err == nil {
+		return nil, utils.NewErrorf("%w: user %s already exists", utils.ErrInvalidServiceAccount, user.Username)
+	} else if !errors.Is(err, utils.ErrInvalidUser) {
+		return nil, err
+	}
+
+	serviceAccount := model.User{
+		Username:       user.Username,
+		FirstName:      user.FirstName,
+		LastName:       user.LastName,
+		Groups:         utils.RemoveString(user.Groups, model.GroupLimited),
+		ServiceAccount: true,
+	}
+
+	if serviceAccount.Groups == nil {
+		serviceAccount.Groups = []string{}
+	}
+
+	if err := as.Database.AddUser(serviceAccount); err != nil {
+		return nil, err
+	}
+
+	return &serviceAccount, nil
+}
+
+// RemoveServiceAccount revoke the API tokens of the service account and remove it
+func (as *APIService) RemoveServiceAccount(username string) error {
+	user, err := as.Database.GetUser(username)
+	if err != nil {
+		return err
+	}
+
+	if !user.ServiceAccount {
+		return utils.NewErrorf("%w: %s isn't a service account", utils.ErrInvalidServiceAccount, username)
+	}
+
+	return as.RemoveUser(user.Username)
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+func newAPITokenTestService(mockCtrl *gomock.Controller) (APIService, *MockMongoDatabaseInterface) {
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	as := APIService{
+		Database:    db,
+		TimeNow:     utils.Btc(utils.P("2024-03-11T08:00:00Z")),
+		Log:         logger.NewLogger("TEST"),
+		NewObjectID: utils.NewObjectIDForTests(),
+	}
+
+	return as, db
+}
+
+func TestCreateAPIToken_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	token := model.APIToken{
+		Name:       "inventory sync",
+		Username:   "automation",
+		Locations:  []string{"Italy"},
+		Permission: model.ReadPermission,
+		ExpiresAt:  utils.P("2025-03-11T08:00:00Z"),
+	}
+
+	var inserted model.APIToken
+
+	gomock.InOrder(
+		db.EXPECT().GetUser("automation").Return(&model.User{Username: "automation", ServiceAccount: true}, nil),
+		db.EXPECT().InsertAPIToken(gomock.Any()).DoAndReturn(func(token model.APIToken) error {
+			inserted = token
+			return nil
+		}),
+	)
+
+	actual, value, err := as.CreateAPIToken(token)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(value, model.APITokenPrefix))
+	assert.Equal(t, cr.HashToken(value), actual.Hash)
+	assert.Equal(t, value[:len(model.APITokenPrefix)+apiTokenHintLength], actual.Hint)
+	assert.Equal(t, utils.Str2oid("000000000000000000000001"), actual.ID)
+	assert.Equal(t, utils.P("2024-03-11T08:00:00Z"), actual.CreatedAt)
+	assert.Equal(t, *actual, inserted)
+}
+
+func TestCreateAPIToken_Fail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	valid := model.APIToken{
+		Name:       "inventory sync",
+		Username:   "automation",
+		Permission: model.WritePermission,
+		ExpiresAt:  utils.P("2025-03-11T08:00:00Z"),
+	}
+
+	t.Run("Invalid permission", func(t *testing.T) {
+		token := valid
+		token.Permission = model.AdminPermission
+
+		_, _, err := as.CreateAPIToken(token)
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+
+	t.Run("Already expired", func(t *testing.T) {
+		token := valid
+		token.ExpiresAt = utils.P("2024-03-11T07:59:59Z")
+
+		_, _, err := as.CreateAPIToken(token)
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		db.EXPECT().GetUser("automation").Return(nil, utils.ErrInvalidUser)
+
+		_, _, err := as.CreateAPIToken(valid)
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		db.EXPECT().GetUser("automation").Return(&model.User{Username: "automation"}, nil)
+		db.EXPECT().InsertAPIToken(gomock.Any()).Return(errMock)
+
+		_, _, err := as.CreateAPIToken(valid)
+		assert.ErrorIs(t, err, errMock)
+	})
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	id := utils.Str2oid("654a1d2f3b8e7c0001a1c3d1")
+	db.EXPECT().RevokeAPIToken(id, utils.P("2024-03-11T08:00:00Z")).Return(nil)
+
+	assert.NoError(t, as.RevokeAPIToken(id))
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	value := model.APITokenPrefix + "c2VjcmV0"
+	hash := cr.HashToken(value)
+	now := utils.P("2024-03-11T08:00:00Z")
+
+	owner := &model.User{
+		Username:       "automation",
+		Groups:         []string{"inventory"},
+		ServiceAccount: true,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		token := &model.APIToken{
+			ID:         utils.Str2oid("654a1d2f3b8e7c0001a1c3d1"),
+			Username:   "automation",
+			Hash:       hash,
+			Locations:  []string{"Italy"},
+			Permission: model.ReadPermission,
+			ExpiresAt:  utils.P("2025-03-11T08:00:00Z"),
+		}
+
+		db.EXPECT().GetAPITokenByHash(hash).Return(token, nil)
+		db.EXPECT().GetUser("automation").Return(owner, nil)
+		db.EXPECT().UpdateAPITokenLastUsed(token.ID, now).Return(nil)
+
+		user, actual, err := as.AuthenticateAPIToken(value)
+		require.NoError(t, err)
+		assert.Equal(t, model.User{Username: "automation", Groups: []string{"inventory"}, ServiceAccount: true}, *user)
+		assert.Equal(t, token.ID, actual.ID)
+		assert.Equal(t, now, *actual.LastUsedAt)
+	})
+
+	t.Run("Recently used", func(t *testing.T) {
+		lastUsed := now.Add(-30 * time.Second)
+		token := &model.APIToken{
+			Username:   "automation",
+			Hash:       hash,
+			Permission: model.ReadPermission,
+			ExpiresAt:  utils.P("2025-03-11T08:00:00Z"),
+			LastUsedAt: &lastUsed,
+		}
+
+		db.EXPECT().GetAPITokenByHash(hash).Return(token, nil)
+		db.EXPECT().GetUser("automation").Return(owner, nil)
+
+		_, actual, err := as.AuthenticateAPIToken(value)
+		require.NoError(t, err)
+		assert.Equal(t, lastUsed, *actual.LastUsedAt)
+	})
+
+	t.Run("Not an API token", func(t *testing.T) {
+		_, _, err := as.AuthenticateAPIToken("eyJhbGciOiJSUzI1NiJ9")
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		db.EXPECT().GetAPITokenByHash(hash).Return(nil, utils.ErrAPITokenNotFound)
+
+		_, _, err := as.AuthenticateAPIToken(value)
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		db.EXPECT().GetAPITokenByHash(hash).Return(&model.APIToken{Username: "automation", ExpiresAt: now}, nil)
+
+		_, _, err := as.AuthenticateAPIToken(value)
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		revokedAt := utils.P("2024-03-10T08:00:00Z")
+		db.EXPECT().GetAPITokenByHash(hash).Return(&model.APIToken{
+			Username:  "automation",
+			ExpiresAt: utils.P("2025-03-11T08:00:00Z"),
+			RevokedAt: &revokedAt,
+		}, nil)
+
+		_, _, err := as.AuthenticateAPIToken(value)
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+
+	t.Run("Removed owner", func(t *testing.T) {
+		db.EXPECT().GetAPITokenByHash(hash).Return(&model.APIToken{Username: "automation", ExpiresAt: utils.P("2025-03-11T08:00:00Z")}, nil)
+		db.EXPECT().GetUser("automation").Return(nil, utils.ErrInvalidUser)
+
+		_, _, err := as.AuthenticateAPIToken(value)
+		assert.ErrorIs(t, err, utils.ErrInvalidAPIToken)
+	})
+}
+
+func TestListServiceAccounts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	automation := model.User{Username: "automation", Groups: []string{"inventory"}, ServiceAccount: true}
+	db.EXPECT().ListUsers().Return([]model.User{
+		{Username: "alice", Groups: []string{"admin"}},
+		automation,
+	}, nil)
+
+	actual, err := as.ListServiceAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []model.User{automation}, actual)
+}
+
+func TestAddServiceAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	t.Run("Success", func(t *testing.T) {
+		expected := model.User{Username: "automation", Groups: []string{"inventory"}, ServiceAccount: true}
+
+		db.EXPECT().GetUser("automation").Return(nil, utils.ErrInvalidUser)
+		db.EXPECT().AddUser(expected).Return(nil)
+
+		actual, err := as.AddServiceAccount(model.User{
+			Username: "automation",
+			Password: "ignored",
+			Groups:   []string{"inventory", model.GroupLimited},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expected, *actual)
+	})
+
+	t.Run("Empty username", func(t *testing.T) {
+		_, err := as.AddServiceAccount(model.User{Username: " "})
+		assert.ErrorIs(t, err, utils.ErrInvalidServiceAccount)
+	})
+
+	t.Run("Already exists", func(t *testing.T) {
+		db.EXPECT().GetUser("alice").Return(&model.User{Username: "alice"}, nil)
+
+		_, err := as.AddServiceAccount(model.User{Username: "alice"})
+		assert.ErrorIs(t, err, utils.ErrInvalidServiceAccount)
+	})
+}
+
+func TestRemoveServiceAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	t.Run("Success", func(t *testing.T) {
+		gomock.InOrder(
+			db.EXPECT().GetUser("automation").Return(&model.User{Username: "automation", ServiceAccount: true}, nil),
+			db.EXPECT().RevokeUserAPITokens("automation", utils.P("2024-03-11T08:00:00Z")).Return(nil),
+			db.EXPECT().RemoveUser("automation").Return(nil),
+		)
+
+		assert.NoError(t, as.RemoveServiceAccount("automation"))
+	})
+
+	t.Run("Not a service account", func(t *testing.T) {
+		db.EXPECT().GetUser("alice").Return(&model.User{Username: "alice"}, nil)
+
+		assert.ErrorIs(t, as.RemoveServiceAccount("alice"), utils.ErrInvalidServiceAccount)
+	})
+}
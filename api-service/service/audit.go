@@ -0,0 +1,132 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils/exutils"
+)
+
+// InsertAuditEntry append the entry to the audit log, with the current date
+func (as *APIService) InsertAuditEntry(entry model.AuditEntry) error {
+	entry.ID = as.NewObjectID()
+	entry.Date = as.TimeNow()
+
+	return as.Database.InsertAuditEntry(entry)
+}
+
+// SearchAuditEntries return the entries of the audit log that match the filter, the newest first
+func (as *APIService) SearchAuditEntries(filter dto.AuditFilter) (*dto.AuditEntriesResponse, error) {
+	return as.Database.SearchAuditEntries(filter)
+}
+
+// SearchAuditEntriesAsXLSX return all the entries of the audit log that match the filter as XLSX file
+func (as *APIService) SearchAuditEntriesAsXLSX(filter dto.AuditFilter) (*excelize.File, error) {
+	filter.PageNumber = 0
+	filter.PageSize = 0
+
+	entries, err := as.Database.SearchAuditEntries(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := "Audit"
+	headers := []string{
+		"Date",
+		"Service",
+		"Username",
+		"Auth Provider",
+		"Method",
+		"Route",
+		"Path",
+		"Location",
+		"Status",
+		"Changes",
+		"Request",
+	}
+
+	sheets, err := exutils.NewXLSX(as.Config, sheet, headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	axisHelp := exutils.NewAxisHelper(1)
+
+	for _, entry := range entries.Content {
+		nextAxis := axisHelp.NewRow()
+		sheets.SetCellValue(sheet, nextAxis(), entry.Date.UTC().String())
+		sheets.SetCellValue(sheet, nextAxis(), entry.Service)
+		sheets.SetCellValue(sheet, nextAxis(), entry.Username)
+		sheets.SetCellValue(sheet, nextAxis(), entry.AuthProvider)
+		sheets.SetCellValue(sheet, nextAxis(), entry.Method)
+		sheets.SetCellValue(sheet, nextAxis(), entry.Route)
+		sheets.SetCellValue(sheet, nextAxis(), entry.Path)
+		sheets.SetCellValue(sheet, nextAxis(), entry.Location)
+		sheets.SetCellValue(sheet, nextAxis(), entry.Status)
+		sheets.SetCellValue(sheet, nextAxis(), formatAuditChanges(entry.Changes))
+
+		if len(entry.Changes) == 0 {
+			sheets.SetCellValue(sheet, nextAxis(), string(entry.After))
+		}
+	}
+
+	return sheets, nil
+}
+
+// formatAuditChanges return a line for each change, like field: before -> after
+func formatAuditChanges(changes []model.AuditChange) string {
+	lines := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		before, after := "-", "-"
+
+		if len(change.Before) > 0 {
+			before = string(change.Before)
+		}
+
+		if len(change.After) > 0 {
+			after = string(change.After)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", change.Field, before, after))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DeleteOldAuditEntries remove the entries of the audit log older than the retention.
+// The entries are kept forever if the retention isn't set
+func (as *APIService) DeleteOldAuditEntries() error {
+	days := as.Config.APIService.AuditRetentionJob.DaysThreshold
+	if days <= 0 {
+		return nil
+	}
+
+	deleted, err := as.Database.DeleteAuditEntriesOlderThan(as.TimeNow().AddDate(0, 0, -days))
+	if err != nil {
+		return err
+	}
+
+	as.Log.Infof("Removed %d entries older than %d days from the audit log", deleted, days)
+
+	return nil
+}
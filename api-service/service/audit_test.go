@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestInsertAuditEntry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	entry := model.AuditEntry{
+		Service:  model.AuditServiceAPI,
+		Username: "alice",
+		Method:   "DELETE",
+		Route:    "/hosts/{hostname}",
+		Path:     "/hosts/rac1",
+		Target:   map[string]string{"hostname": "rac1"},
+		Status:   204,
+	}
+
+	expected := entry
+	expected.ID = utils.Str2oid("000000000000000000000001")
+	expected.Date = utils.P("2024-03-11T08:00:00Z")
+
+	db.EXPECT().InsertAuditEntry(expected).Return(nil)
+
+	require.NoError(t, as.InsertAuditEntry(entry))
+}
+
+func TestSearchAuditEntriesAsXLSX(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2024-03-11T08:00:00Z")),
+		Config: config.Configuration{
+			ResourceFilePath: "../../resources",
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	filter := dto.AuditFilter{Username: "alice", From: utils.MIN_TIME, To: utils.MAX_TIME, PageNumber: 2, PageSize: 10}
+	expectedFilter := filter
+	expectedFilter.PageNumber = 0
+	expectedFilter.PageSize = 0
+
+	db.EXPECT().SearchAuditEntries(expectedFilter).Return(&dto.AuditEntriesResponse{
+		Content: []model.AuditEntry{
+			{
+				Date:         utils.P("2024-03-02T10:00:00Z"),
+				Service:      model.AuditServiceAPI,
+				Username:     "alice",
+				AuthProvider: "basic",
+				Method:       "PUT",
+				Route:        "/hosts/{hostname}/technologies/mysql/databases/{dbname}/ignored/{ignored}",
+				Path:         "/hosts/rac1/technologies/mysql/databases/db1/ignored/true",
+				Location:     "Italy",
+				Status:       200,
+				Changes: []model.AuditChange{
+					{Field: "ignored", Before: json.RawMessage(`false`), After: json.RawMessage(`true`)},
+					{Field: "ignoredComment", After: json.RawMessage(`"test"`)},
+				},
+			},
+			{
+				Date:         utils.P("2024-03-01T10:00:00Z"),
+				Service:      model.AuditServiceThunder,
+				Username:     "alice",
+				AuthProvider: "basic",
+				Method:       "POST",
+				Route:        "/aws/configurations",
+				Path:         "/aws/configurations",
+				Status:       200,
+				After:        json.RawMessage(`{"secretAccessKey":"********"}`),
+				Changes:      []model.AuditChange{},
+			},
+		},
+	}, nil)
+
+	sheets, err := as.SearchAuditEntriesAsXLSX(filter)
+	require.NoError(t, err)
+
+	sheet := "Audit"
+	assert.Equal(t, "Date", sheets.GetCellValue(sheet, "A1"))
+	assert.Equal(t, "2024-03-02 10:00:00 +0000 UTC", sheets.GetCellValue(sheet, "A2"))
+	assert.Equal(t, "api-service", sheets.GetCellValue(sheet, "B2"))
+	assert.Equal(t, "alice", sheets.GetCellValue(sheet, "C2"))
+	assert.Equal(t, "PUT", sheets.GetCellValue(sheet, "E2"))
+	assert.Equal(t, "Italy", sheets.GetCellValue(sheet, "H2"))
+	assert.Equal(t, "200", sheets.GetCellValue(sheet, "I2"))
+	assert.Equal(t, "ignored: false -> true\nignoredComment: - -> \"test\"", sheets.GetCellValue(sheet, "J2"))
+	assert.Equal(t, "", sheets.GetCellValue(sheet, "K2"))
+	assert.Equal(t, "thunder-service", sheets.GetCellValue(sheet, "B3"))
+	assert.Equal(t, `{"secretAccessKey":"********"}`, sheets.GetCellValue(sheet, "K3"))
+	assert.Equal(t, "", sheets.GetCellValue(sheet, "A4"))
+}
+
+func TestDeleteOldAuditEntries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db := newAPITokenTestService(mockCtrl)
+
+	t.Run("Retention", func(t *testing.T) {
+		as.Config.APIService.AuditRetentionJob.DaysThreshold = 30
+		db.EXPECT().DeleteAuditEntriesOlderThan(utils.P("2024-02-10T08:00:00Z")).Return(int64(3), nil)
+
+		require.NoError(t, as.DeleteOldAuditEntries())
+	})
+
+	t.Run("Kept forever", func(t *testing.T) {
+		as.Config.APIService.AuditRetentionJob.DaysThreshold = 0
+
+		require.NoError(t, as.DeleteOldAuditEntries())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		as.Config.APIService.AuditRetentionJob.DaysThreshold = 30
+		db.EXPECT().DeleteAuditEntriesOlderThan(gomock.Any()).Return(int64(0), errMock)
+
+		assert.ErrorIs(t, as.DeleteOldAuditEntries(), errMock)
+	})
+}
@@ -0,0 +1,472 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/ercole-io/ercole/v2/utils/exutils"
+)
+
+// GetClusterReconciliation cross-checks the VMs reported by the hypervisors and the Veritas clusters
+// against the hostdata of the hosts. All the hosts are matched, then the issues are filtered by
+// the location and the environment of the filter
+func (as *APIService) GetClusterReconciliation(filter dto.GlobalFilter) (*dto.ClusterReconciliation, error) {
+	hostdatas, err := as.Database.GetHostDatas(dto.GlobalFilter{OlderThan: filter.OlderThan})
+	if err != nil {
+		return nil, err
+	}
+
+	reconciler := newClusterReconciler(hostdatas)
+	reconciler.checkVMs()
+	reconciler.checkVirtualHosts()
+	reconciler.checkVeritasClusters()
+
+	locations := make(map[string]bool)
+	if filter.Location != "" && filter.Location != model.AllLocation {
+		for _, location := range strings.Split(filter.Location, ",") {
+			locations[location] = true
+		}
+	}
+
+	res := &dto.ClusterReconciliation{
+		Issues: make([]dto.ClusterReconciliationIssue, 0, len(reconciler.issues)),
+		Counts: make(map[string]int),
+	}
+
+	for _, issue := range reconciler.issues {
+		if len(locations) > 0 && !locations[issue.Location] {
+			continue
+		}
+
+		if filter.Environment != "" && filter.Environment != issue.Environment {
+			continue
+		}
+
+		res.Issues = append(res.Issues, issue)
+		res.Counts[issue.Kind]++
+	}
+
+	sort.SliceStable(res.Issues, func(i, j int) bool {
+		if res.Issues[i].Kind != res.Issues[j].Kind {
+			return res.Issues[i].Kind < res.Issues[j].Kind
+		}
+
+		return res.Issues[i].Hostname < res.Issues[j].Hostname
+	})
+
+	return res, nil
+}
+
+// GetClusterReconciliationAsXLSX return the cluster reconciliation issues as xlsx file
+func (as *APIService) GetClusterReconciliationAsXLSX(filter dto.GlobalFilter) (*excelize.File, error) {
+	reconciliation, err := as.GetClusterReconciliation(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := "Cluster Reconciliation"
+	headers := []string{
+		"Kind",
+		"Hostname",
+		"VM Name",
+		"Clusters",
+		"License Types",
+		"Location",
+		"Environment",
+		"Description",
+	}
+
+	sheets, err := exutils.NewXLSX(as.Config, sheet, headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	axisHelp := exutils.NewAxisHelper(1)
+
+	for _, val := range reconciliation.Issues {
+		nextAxis := axisHelp.NewRow()
+		sheets.SetCellValue(sheet, nextAxis(), val.Kind)
+		sheets.SetCellValue(sheet, nextAxis(), val.Hostname)
+		sheets.SetCellValue(sheet, nextAxis(), val.VMName)
+		sheets.SetCellValue(sheet, nextAxis(), strings.Join(val.Clusters, ", "))
+		sheets.SetCellValue(sheet, nextAxis(), strings.Join(val.LicenseTypeIDs, ", "))
+		sheets.SetCellValue(sheet, nextAxis(), val.Location)
+		sheets.SetCellValue(sheet, nextAxis(), val.Environment)
+		sheets.SetCellValue(sheet, nextAxis(), val.Description)
+	}
+
+	return sheets, nil
+}
+
+// reportedVM is a VM as reported by a cluster of a hypervisor
+type reportedVM struct {
+	model.VMInfo
+	cluster string
+	// reporter is the host of the agent that reported the cluster
+	reporter *model.HostDataBE
+	// host is the host of the VM, nil if it hasn't sent any hostdata
+	host *model.HostDataBE
+}
+
+type clusterReconciler struct {
+	hosts       []model.HostDataBE
+	byHostname  map[string]*model.HostDataBE
+	byShortname map[string][]*model.HostDataBE
+
+	// vms contains the reported VMs grouped by host, in the order they are reported
+	vms      map[string][]reportedVM
+	vmsOrder []string
+
+	issues     []dto.ClusterReconciliationIssue
+	issueIndex map[string]int
+}
+
+func newClusterReconciler(hosts []model.HostDataBE) *clusterReconciler {
+	r := &clusterReconciler{
+		hosts:       hosts,
+		byHostname:  make(map[string]*model.HostDataBE, len(hosts)),
+		byShortname: make(map[string][]*model.HostDataBE, len(hosts)),
+		vms:         make(map[string][]reportedVM),
+		issueIndex:  make(map[string]int),
+	}
+
+	for i := range hosts {
+		host := &hosts[i]
+		hostname := strings.ToLower(host.Hostname)
+		r.byHostname[hostname] = host
+		r.byShortname[shortHostname(hostname)] = append(r.byShortname[shortHostname(hostname)], host)
+	}
+
+	for i := range hosts {
+		for _, cluster := range hosts[i].Clusters {
+			for _, vm := range cluster.VMs {
+				if strings.TrimSpace(vm.Hostname) == "" {
+					continue
+				}
+
+				reported := reportedVM{VMInfo: vm, cluster: cluster.Name, reporter: &hosts[i], host: r.match(vm.Hostname)}
+
+				key := "?" + strings.ToLower(vm.Hostname)
+				if reported.host != nil {
+					key = reported.host.Hostname
+				}
+
+				if _, ok := r.vms[key]; !ok {
+					r.vmsOrder = append(r.vmsOrder, key)
+				}
+
+				r.vms[key] = append(r.vms[key], reported)
+			}
+		}
+	}
+
+	return r
+}
+
+// match return the host with hostname, compared case insensitive and without the domain
+// when there isn't an exact match. It return nil if there isn't any or it's ambiguous
+func (r *clusterReconciler) match(hostname string) *model.HostDataBE {
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+
+	if host, ok := r.byHostname[hostname]; ok {
+		return host
+	}
+
+	if hosts := r.byShortname[shortHostname(hostname)]; len(hosts) == 1 {
+		return hosts[0]
+	}
+
+	return nil
+}
+
+func (r *clusterReconciler) checkVMs() {
+	for _, key := range r.vmsOrder {
+		reports := r.vms[key]
+		first := reports[0]
+
+		clusters := make([]string, 0)
+		cappedBy := map[bool][]string{}
+
+		for _, reported := range reports {
+			if !utils.Contains(clusters, reported.cluster) {
+				clusters = append(clusters, reported.cluster)
+			}
+
+			if !utils.Contains(cappedBy[reported.CappedCPU], reported.cluster) {
+				cappedBy[reported.CappedCPU] = append(cappedBy[reported.CappedCPU], reported.cluster)
+			}
+		}
+
+		sort.Strings(clusters)
+
+		if first.host == nil {
+			r.addIssue(first.issue(dto.ClusterIssueUnknownVM, clusters,
+				fmt.Sprintf("The VM %s is reported by the cluster %s but its host hasn't sent any hostdata",
+					first.Name, strings.Join(clusters, ", "))))
+		}
+
+		if len(clusters) > 1 {
+			r.addIssue(first.issue(dto.ClusterIssueVMInMultipleClusters, clusters,
+				fmt.Sprintf("The VM %s is reported by the clusters %s", first.Name, strings.Join(clusters, ", "))))
+		}
+
+		if len(cappedBy[true]) > 0 && len(cappedBy[false]) > 0 {
+			r.addIssue(first.issue(dto.ClusterIssueCappedCPUMismatch, clusters,
+				fmt.Sprintf("The VM %s has capped CPU according to the cluster %s but not according to the cluster %s",
+					first.Name, strings.Join(cappedBy[true], ", "), strings.Join(cappedBy[false], ", "))))
+		}
+	}
+
+	r.checkCappedLicenses()
+}
+
+// checkCappedLicenses look for the VMs not capped in a cluster where the other VMs using the same
+// licenses are capped: they make the capping ignored when the licenses are counted
+func (r *clusterReconciler) checkCappedLicenses() {
+	clusters := make(map[string][]reportedVM)
+	clustersOrder := make([]string, 0)
+
+	for _, key := range r.vmsOrder {
+		seen := make(map[string]bool)
+
+		for _, reported := range r.vms[key] {
+			if reported.host == nil || seen[reported.cluster] {
+				continue
+			}
+
+			seen[reported.cluster] = true
+
+			if _, ok := clusters[reported.cluster]; !ok {
+				clustersOrder = append(clustersOrder, reported.cluster)
+			}
+
+			clusters[reported.cluster] = append(clusters[reported.cluster], reported)
+		}
+	}
+
+	for _, cluster := range clustersOrder {
+		capped := make(map[string][]string)
+		notCapped := make(map[string][]reportedVM)
+		licenseTypeIDs := make([]string, 0)
+
+		for _, reported := range clusters[cluster] {
+			for _, licenseTypeID := range oracleLicenseTypeIDs(reported.host) {
+				if _, ok := capped[licenseTypeID]; !ok {
+					if _, ok := notCapped[licenseTypeID]; !ok {
+						licenseTypeIDs = append(licenseTypeIDs, licenseTypeID)
+					}
+				}
+
+				if reported.CappedCPU {
+					capped[licenseTypeID] = append(capped[licenseTypeID], reported.host.Hostname)
+				} else {
+					notCapped[licenseTypeID] = append(notCapped[licenseTypeID], reported)
+				}
+			}
+		}
+
+		for _, licenseTypeID := range licenseTypeIDs {
+			if len(capped[licenseTypeID]) == 0 {
+				continue
+			}
+
+			for _, reported := range notCapped[licenseTypeID] {
+				issue := reported.issue(dto.ClusterIssueCappedCPUMismatch, []string{cluster},
+					fmt.Sprintf("The VM %s hasn't capped CPU while %s of the cluster %s using the license %s have, so the capping isn't applied",
+						reported.Name, strings.Join(capped[licenseTypeID], ", "), cluster, licenseTypeID))
+				issue.LicenseTypeIDs = []string{licenseTypeID}
+
+				r.addIssue(issue)
+			}
+		}
+	}
+}
+
+func (r *clusterReconciler) checkVirtualHosts() {
+	for i := range r.hosts {
+		host := &r.hosts[i]
+
+		if host.Info.HardwareAbstraction != model.HardwareAbstractionVirtual ||
+			host.Info.HardwareAbstractionTechnology == model.HardwareAbstractionTechnologyHpvirt {
+			continue
+		}
+
+		if _, ok := r.vms[host.Hostname]; ok {
+			continue
+		}
+
+		r.addIssue(dto.ClusterReconciliationIssue{
+			Kind:        dto.ClusterIssueVirtualHostWithoutCluster,
+			Hostname:    host.Hostname,
+			Clusters:    []string{},
+			Location:    host.Location,
+			Environment: host.Environment,
+			Description: fmt.Sprintf("The host is virtual (%s) but it isn't in any cluster reported by the hypervisors",
+				host.Info.HardwareAbstractionTechnology),
+		})
+	}
+}
+
+func (r *clusterReconciler) checkVeritasClusters() {
+	for i := range r.hosts {
+		host := &r.hosts[i]
+
+		if !host.ClusterMembershipStatus.VeritasClusterServer {
+			continue
+		}
+
+		members := r.veritasMembers(host)
+
+		for _, member := range members {
+			if member == host.Hostname {
+				continue
+			}
+
+			var description string
+
+			memberHost := r.match(member)
+
+			switch {
+			case memberHost == nil:
+				description = fmt.Sprintf("The Veritas cluster member %s hasn't sent any hostdata", member)
+			case !memberHost.ClusterMembershipStatus.VeritasClusterServer:
+				description = fmt.Sprintf("The Veritas cluster member %s doesn't report to be in a Veritas cluster", member)
+			default:
+				if memberMembers := r.veritasMembers(memberHost); strings.Join(memberMembers, ",") != strings.Join(members, ",") {
+					description = fmt.Sprintf("The Veritas cluster member %s reports the members %s instead of %s",
+						member, strings.Join(memberMembers, ", "), strings.Join(members, ", "))
+				}
+			}
+
+			if description == "" {
+				continue
+			}
+
+			r.addIssue(dto.ClusterReconciliationIssue{
+				Kind:        dto.ClusterIssueVeritasMembershipMismatch,
+				Hostname:    host.Hostname,
+				Clusters:    []string{},
+				Location:    host.Location,
+				Environment: host.Environment,
+				Description: description,
+			})
+		}
+	}
+}
+
+// veritasMembers return the sorted hostnames of the Veritas cluster of the host, host included
+func (r *clusterReconciler) veritasMembers(host *model.HostDataBE) []string {
+	members := []string{host.Hostname}
+
+	for _, member := range host.ClusterMembershipStatus.VeritasClusterHostnames {
+		hostname := strings.ToLower(strings.TrimSpace(member))
+		if memberHost := r.match(member); memberHost != nil {
+			hostname = memberHost.Hostname
+		}
+
+		if hostname != "" && !utils.Contains(members, hostname) {
+			members = append(members, hostname)
+		}
+	}
+
+	sort.Strings(members)
+
+	return members
+}
+
+// addIssue add the issue, merging it with the one of the same kind and host if any
+func (r *clusterReconciler) addIssue(issue dto.ClusterReconciliationIssue) {
+	key := issue.Kind + "/" + issue.Hostname
+
+	i, ok := r.issueIndex[key]
+	if !ok {
+		r.issueIndex[key] = len(r.issues)
+		r.issues = append(r.issues, issue)
+
+		return
+	}
+
+	existing := &r.issues[i]
+	existing.Description += "; " + issue.Description
+
+	for _, cluster := range issue.Clusters {
+		if !utils.Contains(existing.Clusters, cluster) {
+			existing.Clusters = append(existing.Clusters, cluster)
+		}
+	}
+
+	for _, licenseTypeID := range issue.LicenseTypeIDs {
+		if !utils.Contains(existing.LicenseTypeIDs, licenseTypeID) {
+			existing.LicenseTypeIDs = append(existing.LicenseTypeIDs, licenseTypeID)
+		}
+	}
+
+	sort.Strings(existing.Clusters)
+	sort.Strings(existing.LicenseTypeIDs)
+}
+
+// issue return an issue of the VM, in the location of its host or,
+// if it hasn't sent any hostdata, of the host that reported its cluster
+func (vm reportedVM) issue(kind string, clusters []string, description string) dto.ClusterReconciliationIssue {
+	issue := dto.ClusterReconciliationIssue{
+		Kind:        kind,
+		Hostname:    vm.Hostname,
+		VMName:      vm.Name,
+		Clusters:    clusters,
+		Location:    vm.reporter.Location,
+		Environment: vm.reporter.Environment,
+		Description: description,
+	}
+
+	if vm.host != nil {
+		issue.Hostname = vm.host.Hostname
+		issue.Location = vm.host.Location
+		issue.Environment = vm.host.Environment
+	}
+
+	return issue
+}
+
+// oracleLicenseTypeIDs return the license types of the Oracle databases of the host, not ignored,
+// as they are considered when the capping of the cluster is applied
+func oracleLicenseTypeIDs(host *model.HostDataBE) []string {
+	licenseTypeIDs := make([]string, 0)
+
+	if host.Features.Oracle == nil || host.Features.Oracle.Database == nil {
+		return licenseTypeIDs
+	}
+
+	for _, database := range host.Features.Oracle.Database.Databases {
+		for _, license := range database.Licenses {
+			if !license.Ignored && !utils.Contains(licenseTypeIDs, license.LicenseTypeID) {
+				licenseTypeIDs = append(licenseTypeIDs, license.LicenseTypeID)
+			}
+		}
+	}
+
+	return licenseTypeIDs
+}
+
+func shortHostname(hostname string) string {
+	return strings.SplitN(hostname, ".", 2)[0]
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func clusterReconciliationHostdatas() []model.HostDataBE {
+	oracleLicense := func(licenseTypeID string) model.Features {
+		return model.Features{
+			Oracle: &model.OracleFeature{
+				Database: &model.OracleDatabaseFeature{
+					Databases: []model.OracleDatabase{
+						{Name: "ERCOLE", Licenses: []model.OracleDatabaseLicense{{LicenseTypeID: licenseTypeID, Count: 1}}},
+					},
+				},
+			},
+		}
+	}
+
+	return []model.HostDataBE{
+		{
+			Hostname:    "hyper1",
+			Location:    "Italy",
+			Environment: "PROD",
+			Info:        model.Host{HardwareAbstraction: model.HardwareAbstractionPhysical},
+			Clusters: []model.ClusterInfo{
+				{
+					Name: "Puzzait",
+					VMs: []model.VMInfo{
+						{Name: "vm-db1", Hostname: "db1.example.com", CappedCPU: true},
+						{Name: "vm-db2", Hostname: "DB2", CappedCPU: false},
+						{Name: "vm-ghost", Hostname: "ghost", CappedCPU: false},
+						{Name: "vm-off", Hostname: ""},
+					},
+				},
+				{
+					Name: "Bart",
+					VMs: []model.VMInfo{
+						{Name: "vm-db2-bis", Hostname: "db2", CappedCPU: true},
+					},
+				},
+			},
+		},
+		{
+			Hostname:    "db1",
+			Location:    "Italy",
+			Environment: "PROD",
+			Info:        model.Host{HardwareAbstraction: model.HardwareAbstractionVirtual, HardwareAbstractionTechnology: model.HardwareAbstractionTechnologyVmware},
+			Features:    oracleLicense("A90611"),
+		},
+		{
+			Hostname:    "db2",
+			Location:    "Germany",
+			Environment: "TEST",
+			Info:        model.Host{HardwareAbstraction: model.HardwareAbstractionVirtual, HardwareAbstractionTechnology: model.HardwareAbstractionTechnologyVmware},
+			Features:    oracleLicense("A90611"),
+		},
+		{
+			Hostname:    "lonely",
+			Location:    "Italy",
+			Environment: "PROD",
+			Info:        model.Host{HardwareAbstraction: model.HardwareAbstractionVirtual, HardwareAbstractionTechnology: model.HardwareAbstractionTechnologyKvm},
+		},
+		{
+			Hostname:    "hpux",
+			Location:    "Italy",
+			Environment: "PROD",
+			Info:        model.Host{HardwareAbstraction: model.HardwareAbstractionVirtual, HardwareAbstractionTechnology: model.HardwareAbstractionTechnologyHpvirt},
+		},
+		{
+			Hostname:    "vcs1",
+			Location:    "Italy",
+			Environment: "PROD",
+			Info:        model.Host{HardwareAbstraction: model.HardwareAbstractionPhysical},
+			ClusterMembershipStatus: model.ClusterMembershipStatus{
+				VeritasClusterServer:    true,
+				VeritasClusterHostnames: []string{"vcs1", "vcs2", "vcs3"},
+			},
+		},
+		{
+			Hostname:    "vcs2",
+			Location:    "Italy",
+			Environment: "PROD",
+			Info:        model.Host{HardwareAbstraction: model.HardwareAbstractionPhysical},
+			ClusterMembershipStatus: model.ClusterMembershipStatus{
+				VeritasClusterServer:    true,
+				VeritasClusterHostnames: []string{"VCS1.example.com", "vcs2"},
+			},
+		},
+	}
+}
+
+func TestGetClusterReconciliation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	t.Run("All the issues", func(t *testing.T) {
+		db.EXPECT().GetHostDatas(dto.GlobalFilter{OlderThan: utils.MAX_TIME}).Return(clusterReconciliationHostdatas(), nil)
+
+		actual, err := as.GetClusterReconciliation(dto.GlobalFilter{Location: model.AllLocation, OlderThan: utils.MAX_TIME})
+		require.NoError(t, err)
+
+		expected := &dto.ClusterReconciliation{
+			Issues: []dto.ClusterReconciliationIssue{
+				{
+					Kind:           dto.ClusterIssueCappedCPUMismatch,
+					Hostname:       "db2",
+					VMName:         "vm-db2",
+					Clusters:       []string{"Bart", "Puzzait"},
+					LicenseTypeIDs: []string{"A90611"},
+					Location:       "Germany",
+					Environment:    "TEST",
+					Description: "The VM vm-db2 has capped CPU according to the cluster Bart but not according to the cluster Puzzait; " +
+						"The VM vm-db2 hasn't capped CPU while db1 of the cluster Puzzait using the license A90611 have, so the capping isn't applied",
+				},
+				{
+					Kind:        dto.ClusterIssueUnknownVM,
+					Hostname:    "ghost",
+					VMName:      "vm-ghost",
+					Clusters:    []string{"Puzzait"},
+					Location:    "Italy",
+					Environment: "PROD",
+					Description: "The VM vm-ghost is reported by the cluster Puzzait but its host hasn't sent any hostdata",
+				},
+				{
+					Kind:        dto.ClusterIssueVeritasMembershipMismatch,
+					Hostname:    "vcs1",
+					Clusters:    []string{},
+					Location:    "Italy",
+					Environment: "PROD",
+					Description: "The Veritas cluster member vcs2 reports the members vcs1, vcs2 instead of vcs1, vcs2, vcs3; " +
+						"The Veritas cluster member vcs3 hasn't sent any hostdata",
+				},
+				{
+					Kind:        dto.ClusterIssueVeritasMembershipMismatch,
+					Hostname:    "vcs2",
+					Clusters:    []string{},
+					Location:    "Italy",
+					Environment: "PROD",
+					Description: "The Veritas cluster member vcs1 reports the members vcs1, vcs2, vcs3 instead of vcs1, vcs2",
+				},
+				{
+					Kind:        dto.ClusterIssueVirtualHostWithoutCluster,
+					Hostname:    "lonely",
+					Clusters:    []string{},
+					Location:    "Italy",
+					Environment: "PROD",
+					Description: "The host is virtual (KVM) but it isn't in any cluster reported by the hypervisors",
+				},
+				{
+					Kind:        dto.ClusterIssueVMInMultipleClusters,
+					Hostname:    "db2",
+					VMName:      "vm-db2",
+					Clusters:    []string{"Bart", "Puzzait"},
+					Location:    "Germany",
+					Environment: "TEST",
+					Description: "The VM vm-db2 is reported by the clusters Bart, Puzzait",
+				},
+			},
+			Counts: map[string]int{
+				dto.ClusterIssueCappedCPUMismatch:         1,
+				dto.ClusterIssueUnknownVM:                 1,
+				dto.ClusterIssueVeritasMembershipMismatch: 2,
+				dto.ClusterIssueVirtualHostWithoutCluster: 1,
+				dto.ClusterIssueVMInMultipleClusters:      1,
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Filtered by location and environment", func(t *testing.T) {
+		db.EXPECT().GetHostDatas(dto.GlobalFilter{OlderThan: utils.MAX_TIME}).Return(clusterReconciliationHostdatas(), nil)
+
+		actual, err := as.GetClusterReconciliation(dto.GlobalFilter{Location: "Germany,France", Environment: "TEST", OlderThan: utils.MAX_TIME})
+		require.NoError(t, err)
+
+		require.Len(t, actual.Issues, 2)
+		assert.Equal(t, dto.ClusterIssueCappedCPUMismatch, actual.Issues[0].Kind)
+		assert.Equal(t, dto.ClusterIssueVMInMultipleClusters, actual.Issues[1].Kind)
+		assert.Equal(t, map[string]int{dto.ClusterIssueCappedCPUMismatch: 1, dto.ClusterIssueVMInMultipleClusters: 1}, actual.Counts)
+
+		db.EXPECT().GetHostDatas(dto.GlobalFilter{OlderThan: utils.MAX_TIME}).Return(clusterReconciliationHostdatas(), nil)
+
+		actual, err = as.GetClusterReconciliation(dto.GlobalFilter{Location: "Italy", Environment: "TEST", OlderThan: utils.MAX_TIME})
+		require.NoError(t, err)
+		assert.Empty(t, actual.Issues)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db.EXPECT().GetHostDatas(gomock.Any()).Return(nil, aerrMock)
+
+		_, err := as.GetClusterReconciliation(dto.GlobalFilter{OlderThan: utils.MAX_TIME})
+		assert.Equal(t, aerrMock, err)
+	})
+}
+
+func TestGetClusterReconciliationAsXLSX(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Config: config.Configuration{
+			ResourceFilePath: "../../resources",
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().GetHostDatas(dto.GlobalFilter{OlderThan: utils.MAX_TIME}).Return(clusterReconciliationHostdatas(), nil)
+
+	sheets, err := as.GetClusterReconciliationAsXLSX(dto.GlobalFilter{Location: "Germany", OlderThan: utils.MAX_TIME})
+	require.NoError(t, err)
+
+	sheet := "Cluster Reconciliation"
+	assert.Equal(t, "Kind", sheets.GetCellValue(sheet, "A1"))
+	assert.Equal(t, "CAPPED_CPU_MISMATCH", sheets.GetCellValue(sheet, "A2"))
+	assert.Equal(t, "db2", sheets.GetCellValue(sheet, "B2"))
+	assert.Equal(t, "vm-db2", sheets.GetCellValue(sheet, "C2"))
+	assert.Equal(t, "Bart, Puzzait", sheets.GetCellValue(sheet, "D2"))
+	assert.Equal(t, "A90611", sheets.GetCellValue(sheet, "E2"))
+	assert.Equal(t, "Germany", sheets.GetCellValue(sheet, "F2"))
+	assert.Equal(t, "TEST", sheets.GetCellValue(sheet, "G2"))
+	assert.Equal(t, "VM_IN_MULTIPLE_CLUSTERS", sheets.GetCellValue(sheet, "A3"))
+	assert.Equal(t, "", sheets.GetCellValue(sheet, "A4"))
+}
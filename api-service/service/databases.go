@@ -299,7 +299,7 @@ func (as *APIService) GetUsedLicensesPerDatabases(hostname string, filter dto.Gl
 	return usedLicenses, nil
 }
 
-func (as *APIService) clusterLicenses(license dto.DatabaseUsedLicense, clusters []dto.Cluster) (float64, *dto.Cluster, error) {
+func (as *APIService) clusterLicenses(license dto.DatabaseUsedLicense, clusters []dto.Cluster, coreFactor float64) (float64, *dto.Cluster, error) {
 	clusterByHostnames := make(map[string]*dto.Cluster)
 
 	for i := range clusters {
@@ -313,10 +313,10 @@ func (as *APIService) clusterLicenses(license dto.DatabaseUsedLicense, clusters
 		return 0, nil, utils.ErrHostNotInCluster
 	}
 
-	return float64(cluster.CPU) * 0.5, cluster, nil
+	return float64(cluster.CPU) * coreFactor, cluster, nil
 }
 
-func (as *APIService) veritasClusterLicenses(hostdata *model.HostDataBE, hostdatasPerHostname map[string]*model.HostDataBE) (float64, string, string, error) {
+func (as *APIService) veritasClusterLicenses(hostdata *model.HostDataBE, hostdatasPerHostname map[string]*model.HostDataBE, coreFactor float64) (float64, string, string, error) {
 	clusterCores, err := hostdata.GetClusterCores(hostdatasPerHostname)
 
 	if errors.Is(err, utils.ErrHostNotInCluster) {
@@ -332,7 +332,71 @@ func (as *APIService) veritasClusterLicenses(hostdata *model.HostDataBE, hostdat
 
 	clusterName := strings.Join(hostnames, ",")
 
-	return float64(clusterCores) * hostdata.CoreFactor(), clusterName, "VeritasCluster", nil
+	return float64(clusterCores) * coreFactor, clusterName, "VeritasCluster", nil
+}
+
+// cloudUsedLicenses returns the licenses used by a database on an authorized cloud instance, applying the cloud licensing policy.
+// Processor licenses are multiplied by the factor of the cloud row of the core factor table, if any
+func cloudUsedLicenses(license dto.DatabaseUsedLicense, hostdata *model.HostDataBE, coreFactors *model.CoreFactorTable) (float64, bool) {
+	if license.Metric != model.LicenseTypeMetricProcessorPerpetual && license.Metric != model.LicenseTypeMetricNamedUserPlusPerpetual {
+		return 0, false
+	}
+
+	if !model.IsAuthorizedCloud(hostdata.Cloud.Membership) || hostdata.Features.Oracle == nil || hostdata.Features.Oracle.Database == nil {
+		return 0, false
+	}
+
+	for _, db := range hostdata.Features.Oracle.Database.Databases {
+		if db.Name != license.DbName {
+			continue
+		}
+
+		edition := db.Edition()
+
+		used, ok := hostdata.CloudLicenses(edition)
+		if !ok {
+			return 0, false
+		}
+
+		if edition == model.OracleDatabaseEditionStandard {
+			return used, true
+		}
+
+		if coreFactor, ok := coreFactors.Lookup(hostdata); ok && coreFactor.CloudMembership != "" {
+			used *= coreFactor.Factor
+		}
+
+		return used, true
+	}
+
+	return 0, false
+}
+
+// coreFactorUsedLicenses returns the licenses used by an Enterprise or Extreme database according to the core factor table.
+// It returns false if the license isn't counted on cores or the host doesn't match any row of the table
+func coreFactorUsedLicenses(license dto.DatabaseUsedLicense, hostdata *model.HostDataBE, coreFactors *model.CoreFactorTable) (float64, bool) {
+	if license.Metric != model.LicenseTypeMetricProcessorPerpetual && license.Metric != model.LicenseTypeMetricNamedUserPlusPerpetual {
+		return 0, false
+	}
+
+	coreFactor, ok := coreFactors.Lookup(hostdata)
+	if !ok || hostdata.Features.Oracle == nil || hostdata.Features.Oracle.Database == nil {
+		return 0, false
+	}
+
+	for _, db := range hostdata.Features.Oracle.Database.Databases {
+		if db.Name != license.DbName {
+			continue
+		}
+
+		if edition := db.Edition(); edition != model.OracleDatabaseEditionEnterprise && edition != model.OracleDatabaseEditionExtreme {
+			return 0, false
+		}
+
+		return float64(hostdata.Info.CPUCores) * coreFactor.Factor, true
+	}
+
+	return 0, false
 }
 
 func (as *APIService) GetUsedLicensesPerDatabasesAsXLSX(filter dto.GlobalFilter) (*excelize.File, error) {
@@ -421,6 +485,11 @@ func (as *APIService) getOracleDatabasesUsedLicenses(hostname string, filter dto
 		return nil, err
 	}
 
+	coreFactors, err := as.getCoreFactorTable()
+	if err != nil {
+		return nil, err
+	}
+
 	usedLicenses := make([]dto.DatabaseUsedLicense, 0, len(oracleLics.Content))
 
 	for _, o := range oracleLics.Content {
@@ -487,7 +556,15 @@ func (as *APIService) getOracleDatabasesUsedLicenses(hostname string, filter dto
 			continue
 		}
 
-		consumedLicenses, cluster, err := as.clusterLicenses(l, clusters)
+		if used, ok := cloudUsedLicenses(l, hostdata, coreFactors); ok {
+			usedLicenses[i].UsedLicenses = used * model.GetFactorByMetric(usedLicenses[i].Metric)
+		} else if used, ok := coreFactorUsedLicenses(l, hostdata, coreFactors); ok {
+			usedLicenses[i].UsedLicenses = used * model.GetFactorByMetric(usedLicenses[i].Metric)
+		}
+
+		coreFactor := coreFactors.Factor(hostdata)
+
+		consumedLicenses, cluster, err := as.clusterLicenses(l, clusters, coreFactor)
 		if err != nil && !errors.Is(err, utils.ErrHostNotInCluster) {
 			return nil, err
 		} else if !errors.Is(err, utils.ErrHostNotInCluster) {
@@ -507,7 +584,7 @@ func (as *APIService) getOracleDatabasesUsedLicenses(hostname string, filter dto
 			continue
 		}
 
-		consumedLicenses, clusterName, clusterType, err := as.veritasClusterLicenses(hostdata, hostdatasPerHostname)
+		consumedLicenses, clusterName, clusterType, err := as.veritasClusterLicenses(hostdata, hostdatasPerHostname, coreFactor)
 		if err != nil && !errors.Is(err, utils.ErrHostNotInCluster) {
 			return nil, err
 		} else if !errors.Is(err, utils.ErrHostNotInCluster) {
@@ -522,7 +599,7 @@ func (as *APIService) getOracleDatabasesUsedLicenses(hostname string, filter dto
 
 	usedLicenses = as.manageStandardDBVersionLicenses(usedLicenses, clusters, hostdatasPerHostname)
 
-	as.CalcVeritasClusterLicenses(usedLicenses)
+	as.CalcVeritasClusterLicenses(usedLicenses, coreFactors, hostdatasPerHostname)
 
 	errHypervisor := as.checkOlvmCappedHypervisorLicenses(hypervisorLicenses, clustersMap)
 	if errHypervisor != nil {
@@ -658,7 +735,7 @@ func (as *APIService) manageStandardDBVersionLicenses(usedLicenses []dto.Databas
 	return usedLicenses
 }
 
-func (as *APIService) CalcVeritasClusterLicenses(usedLicenses []dto.DatabaseUsedLicense) {
+func (as *APIService) CalcVeritasClusterLicenses(usedLicenses []dto.DatabaseUsedLicense, coreFactors *model.CoreFactorTable, hostdatasPerHostname map[string]*model.HostDataBE) {
 	for i := 0; i < len(usedLicenses); i++ {
 		ul := &usedLicenses[i]
 		hosts := strings.Split(ul.ClusterName, ",")
@@ -669,7 +746,7 @@ func (as *APIService) CalcVeritasClusterLicenses(usedLicenses []dto.DatabaseUsed
 		}
 
 		if ul.ClusterType == "VeritasCluster" && strings.Contains(ul.Hostname, "_DR") {
-			totalCPU := 0
+			clusterLicenses := 0.0
 
 			for _, host := range realClusterHosts {
 				cpu, err := as.Database.GetCpuCore(host)
@@ -677,13 +754,18 @@ func (as *APIService) CalcVeritasClusterLicenses(usedLicenses []dto.DatabaseUsed
 					continue
 				}
 
-				totalCPU += cpu
+				coreFactor := 0.5
+				if hostdata, ok := hostdatasPerHostname[host]; ok {
+					coreFactor = coreFactors.Factor(hostdata)
+				}
+
+				clusterLicenses += float64(cpu) * coreFactor
 			}
 
-			ul.ClusterLicenses = float64(totalCPU) / 2
+			ul.ClusterLicenses = clusterLicenses
 
 			if ul.Metric == "Named User Plus Perpetual" {
-				ul.ClusterLicenses = (float64(totalCPU) / 2) * 25
+				ul.ClusterLicenses = clusterLicenses * 25
 			}
 		}
 
@@ -693,6 +693,8 @@ func TestGetUsedLicensesPerDatabases_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	usedLicensesMySQL := []dto.MySQLUsedLicense{
 		{
 			LicenseTypeID:   model.MySqlPartNumber,
@@ -870,6 +872,8 @@ func TestGetUsedLicensesPerDatabases_VMWareCluster_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	usedLicensesMySQL := []dto.MySQLUsedLicense{}
 	clusters := []dto.Cluster{
 		{
@@ -980,6 +984,8 @@ func TestGetUsedLicensesPerDatabases_VeritasCluster_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	usedLicensesMySQL := []dto.MySQLUsedLicense{}
 	clusters := []dto.Cluster{}
 	contracts := []model.MySQLContract{}
@@ -1090,6 +1096,8 @@ func TestGetUsedLicensesPerDatabasesAsXLSX_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleLics := dto.OracleDatabaseUsedLicenseSearchResponse{
 		Content: []dto.OracleDatabaseUsedLicense{{
 			LicenseTypeID: "A12345",
@@ -1242,6 +1250,8 @@ func TestGetOracleDatabasesUsedLicenses_Host_WithActiveDataguardAndGoldenGate_Su
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleLics := dto.OracleDatabaseUsedLicenseSearchResponse{
 		Content: []dto.OracleDatabaseUsedLicense{
 			{
@@ -1418,6 +1428,8 @@ func TestGetOracleDatabasesUsedLicenses_VeritasCluster_WithActiveDataguardAndGol
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleLics := dto.OracleDatabaseUsedLicenseSearchResponse{
 		Content: []dto.OracleDatabaseUsedLicense{
 			{
@@ -1600,6 +1612,8 @@ func TestGetOracleDatabasesUsedLicenses_Host_WithRacAndRacOneNode_Success(t *tes
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleLics := dto.OracleDatabaseUsedLicenseSearchResponse{
 		Content: []dto.OracleDatabaseUsedLicense{
 			{
@@ -1741,6 +1755,8 @@ func TestGetOracleDatabasesUsedLicenses_VeritasCluster_WithRacAndRacOneNode_Succ
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleLics := dto.OracleDatabaseUsedLicenseSearchResponse{
 		Content: []dto.OracleDatabaseUsedLicense{
 			{
@@ -1888,6 +1904,8 @@ func TestGetOracleDatabasesUsedLicenses_VmwareCluster_WithRacAndRacOneNode_Succe
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleLics := dto.OracleDatabaseUsedLicenseSearchResponse{
 		Content: []dto.OracleDatabaseUsedLicense{
 			{
@@ -2079,6 +2097,8 @@ func TestGetDatabaseLicensesComplianceAsXLSX_Success(t *testing.T) {
 		Database: db,
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleContracts := []dto.OracleDatabaseContractFE{
 		{
 			ID:              objID,
@@ -2277,6 +2297,8 @@ func TestGetDatabaseLicensesCompliance_Success(t *testing.T) {
 		Database: db,
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleContracts := []dto.OracleDatabaseContractFE{
 		{
 			ID:                       objID,
@@ -2542,6 +2564,8 @@ func TestGetUsedLicensesPerHost_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	filter := dto.GlobalFilter{
 		Location:    "Dubai",
 		Environment: "TEST",
@@ -2748,6 +2772,8 @@ func TestGetUsedLicensesPerHostAsXLSX_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	filter := dto.GlobalFilter{
 		Location:    "Dubai",
 		Environment: "TEST",
@@ -2919,6 +2945,8 @@ func TestGetUsedLicensesPerCluster_OneVm_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	filter := dto.GlobalFilter{
 		Location:    "Dubai",
 		Environment: "TEST",
@@ -3004,6 +3032,8 @@ func TestGetUsedLicensesPerCluster_MultipleVms_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	filter := dto.GlobalFilter{
 		Location:    "Dubai",
 		Environment: "TEST",
@@ -3136,6 +3166,8 @@ func TestGetUsedLicensesPerClusterAsXLSX_Success(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	filter := dto.GlobalFilter{
 		Location:    "Dubai",
 		Environment: "TEST",
@@ -3215,6 +3247,8 @@ func TestGetDatabaseLicensesComplianceSqlServerHostWithContractContract_Success(
 		Database: db,
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleContracts := []dto.OracleDatabaseContractFE{}
 
 	searchResponse := dto.OracleDatabaseUsedLicenseSearchResponse{}
@@ -3317,6 +3351,8 @@ func TestGetDatabaseLicensesComplianceSqlServerHostNoContract_Success(t *testing
 		Database: db,
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleContracts := []dto.OracleDatabaseContractFE{}
 
 	searchResponse := dto.OracleDatabaseUsedLicenseSearchResponse{}
@@ -3408,6 +3444,8 @@ func TestGetDatabaseLicensesComplianceSqlServerHostInClusterWithContract_Success
 		Database: db,
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	oracleContracts := []dto.OracleDatabaseContractFE{}
 
 	searchResponse := dto.OracleDatabaseUsedLicenseSearchResponse{}
@@ -3580,3 +3618,49 @@ func TestGetDatabaseLicensesComplianceSqlServerHostInClusterWithContract_Success
 	}
 	assert.ElementsMatch(t, expected, actual)
 }
+
+func TestCloudUsedLicenses(t *testing.T) {
+	hostdata := &model.HostDataBE{
+		Hostname: "cloudy",
+		Info:     model.Host{CPUCores: 4, CPUThreads: 8, ThreadsPerCore: 2},
+		Cloud:    model.Cloud{Membership: model.CloudMembershipAzure},
+		Features: model.Features{
+			Oracle: &model.OracleFeature{
+				Database: &model.OracleDatabaseFeature{
+					Databases: []model.OracleDatabase{
+						{Name: "ENTDB", Version: "19.0.0.0.0 Enterprise Edition"},
+						{Name: "STDDB", Version: "19.0.0.0.0 Standard Edition"},
+					},
+				},
+			},
+		},
+	}
+
+	license := dto.DatabaseUsedLicense{
+		Hostname: "cloudy",
+		DbName:   "ENTDB",
+		Metric:   model.LicenseTypeMetricProcessorPerpetual,
+	}
+
+	used, ok := cloudUsedLicenses(license, hostdata, nil)
+	require.True(t, ok)
+	assert.Equal(t, 4.0, used)
+
+	coreFactors, err := model.NewCoreFactorTable([]model.CoreFactor{
+		{CloudMembership: model.CloudMembershipAzure, Factor: 0.5},
+	})
+	require.NoError(t, err)
+
+	used, ok = cloudUsedLicenses(license, hostdata, coreFactors)
+	require.True(t, ok)
+	assert.Equal(t, 2.0, used)
+
+	license.DbName = "STDDB"
+	used, ok = cloudUsedLicenses(license, hostdata, coreFactors)
+	require.True(t, ok)
+	assert.Equal(t, 2.0, used)
+
+	hostdata.Cloud.Membership = model.CloudMembershipNone
+	_, ok = cloudUsedLicenses(license, hostdata, coreFactors)
+	assert.False(t, ok)
+}
@@ -167,11 +167,25 @@ func (as *APIService) oracleStats(locations []string) (*dto.Stats, error) {
 		}
 	}
 
-	compliances, err := as.GetOracleDatabaseLicensesCompliance(locations)
+	compliances, usages, err := as.oracleDatabaseLicensesCompliance(locations)
 	if err != nil {
 		return nil, err
 	}
 
+	var consumedPerCloud map[string]float64
+
+	for _, usage := range usages {
+		if usage.CloudMembership == "" {
+			continue
+		}
+
+		if consumedPerCloud == nil {
+			consumedPerCloud = make(map[string]float64)
+		}
+
+		consumedPerCloud[usage.CloudMembership] += usage.OriginalCount
+	}
+
 	compliancePercentage := float64(0.0)
 
 	if len(compliances) > 0 {
@@ -189,10 +203,11 @@ func (as *APIService) oracleStats(locations []string) (*dto.Stats, error) {
 	}
 
 	return &dto.Stats{
-		Count:                   int(count),
-		HostCount:               int(hostCount),
-		CompliancePercentageVal: compliancePercentage,
-		CompliancePercentageStr: fmt.Sprintf("%.2f%%", compliancePercentage),
+		Count:                    int(count),
+		HostCount:                int(hostCount),
+		CompliancePercentageVal:  compliancePercentage,
+		CompliancePercentageStr:  fmt.Sprintf("%.2f%%", compliancePercentage),
+		ConsumedLicensesPerCloud: consumedPerCloud,
 	}, nil
 }
 
@@ -510,6 +510,8 @@ func TestGetComplianceStatsAsAdmin(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	hostdatas := []model.HostDataBE{
 		{
 			ClusterMembershipStatus: model.ClusterMembershipStatus{
@@ -811,6 +813,8 @@ func TestGetComplianceStatsAsUser(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	hostdatas := []model.HostDataBE{
 		{
 			ClusterMembershipStatus: model.ClusterMembershipStatus{
@@ -0,0 +1,518 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// GetHostDiff return what changed on the host between the snapshot valid at from and the one valid at to
+func (as *APIService) GetHostDiff(hostname string, from, to time.Time) (*dto.HostDiff, error) {
+	if !from.Before(to) {
+		return nil, utils.NewErrorf("%w: from must be before to", utils.ErrInvalidHostHistoryRange)
+	}
+
+	newer, err := as.Database.GetHostData(hostname, to)
+	if err != nil {
+		return nil, err
+	}
+
+	newerCluster, err := as.Database.GetHostVirtualizationCluster(hostname, to)
+	if err != nil {
+		return nil, err
+	}
+
+	older, err := as.getHostDataIfExists(hostname, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var olderCluster *dto.HostVirtualizationCluster
+
+	var fromSnapshot *time.Time
+
+	if older != nil {
+		olderCluster, err = as.Database.GetHostVirtualizationCluster(hostname, from)
+		if err != nil {
+			return nil, err
+		}
+
+		createdAt := older.CreatedAt
+		fromSnapshot = &createdAt
+	}
+
+	return &dto.HostDiff{
+		Hostname:     hostname,
+		Location:     newer.Location,
+		From:         from,
+		To:           to,
+		FromSnapshot: fromSnapshot,
+		ToSnapshot:   newer.CreatedAt,
+		Changes:      diffHostData(older, olderCluster, newer, newerCluster),
+	}, nil
+}
+
+// GetHostTimeline return the snapshots of the host inserted between from and to that changed something,
+// each one with its changes compared to the previous snapshot
+func (as *APIService) GetHostTimeline(hostname string, from, to time.Time) (*dto.HostTimeline, error) {
+	if !from.Before(to) {
+		return nil, utils.NewErrorf("%w: from must be before to", utils.ErrInvalidHostHistoryRange)
+	}
+
+	previous, err := as.getHostDataIfExists(hostname, from)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := as.Database.ListHostDataHistory(hostname, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous == nil && len(snapshots) == 0 {
+		return nil, utils.NewErrorf("%w: %s", utils.ErrHostNotFound, hostname)
+	}
+
+	var previousCluster *dto.HostVirtualizationCluster
+
+	timeline := dto.HostTimeline{
+		Hostname: hostname,
+		From:     from,
+		To:       to,
+		Entries:  make([]dto.HostTimelineEntry, 0),
+	}
+
+	if previous != nil {
+		timeline.Location = previous.Location
+
+		previousCluster, err = as.Database.GetHostVirtualizationCluster(hostname, from)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range snapshots {
+		current := &snapshots[i]
+
+		cluster, err := as.Database.GetHostVirtualizationCluster(hostname, current.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if changes := diffHostData(previous, previousCluster, current, cluster); len(changes) > 0 {
+			timeline.Entries = append(timeline.Entries, dto.HostTimelineEntry{
+				Date:    current.CreatedAt,
+				Changes: changes,
+			})
+		}
+
+		timeline.Location = current.Location
+		previous, previousCluster = current, cluster
+	}
+
+	return &timeline, nil
+}
+
+// getHostDataIfExists return the snapshot of the host valid at olderThan, nil if the host didn't exist yet
+func (as *APIService) getHostDataIfExists(hostname string, olderThan time.Time) (*model.HostDataBE, error) {
+	hostdata, err := as.Database.GetHostData(hostname, olderThan)
+	if errors.Is(err, utils.ErrHostNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return hostdata, nil
+}
+
+// diffHostData compare two snapshots of the same host, older is nil when the host didn't exist
+func diffHostData(older *model.HostDataBE, olderCluster *dto.HostVirtualizationCluster,
+	newer *model.HostDataBE, newerCluster *dto.HostVirtualizationCluster) []dto.HostChange {
+	changes := make([]dto.HostChange, 0)
+
+	if older == nil {
+		changes = append(changes, dto.HostChange{
+			Category: dto.HostChangeCategoryHost,
+			Action:   dto.HostChangeAdded,
+			Item:     newer.Hostname,
+		})
+		older = &model.HostDataBE{}
+	} else {
+		changes = append(changes, diffHostInfo(older.Info, newer.Info)...)
+		changes = append(changes, diffClusterMembership(older.ClusterMembershipStatus, newer.ClusterMembershipStatus)...)
+	}
+
+	changes = append(changes, diffVirtualizationCluster(olderCluster, newerCluster)...)
+	changes = append(changes, diffOracleDatabases(oracleDatabases(older), oracleDatabases(newer))...)
+
+	for _, technology := range []string{
+		model.TechnologyOracleMySQL,
+		model.TechnologyMicrosoftSQLServer,
+		model.TechnologyPostgreSQLPostgreSQL,
+		model.TechnologyMongoDBMongoDB,
+	} {
+		changes = append(changes, diffSet(
+			dto.HostChange{Category: dto.HostChangeCategoryDatabase, Technology: technology},
+			instanceNames(older, technology), instanceNames(newer, technology))...)
+	}
+
+	return changes
+}
+
+func diffHostInfo(older, newer model.Host) []dto.HostChange {
+	fields := []struct {
+		item       string
+		older, new interface{}
+	}{
+		{"cpuModel", older.CPUModel, newer.CPUModel},
+		{"cpuSockets", older.CPUSockets, newer.CPUSockets},
+		{"cpuCores", older.CPUCores, newer.CPUCores},
+		{"cpuThreads", older.CPUThreads, newer.CPUThreads},
+		{"memoryTotal", older.MemoryTotal, newer.MemoryTotal},
+	}
+
+	changes := make([]dto.HostChange, 0)
+
+	for _, f := range fields {
+		if f.older != f.new {
+			changes = append(changes, dto.HostChange{
+				Category: dto.HostChangeCategoryHost,
+				Action:   dto.HostChangeChanged,
+				Item:     f.item,
+				Old:      f.older,
+				New:      f.new,
+			})
+		}
+	}
+
+	return changes
+}
+
+func diffClusterMembership(older, newer model.ClusterMembershipStatus) []dto.HostChange {
+	olderHostnames := sortedCopy(older.VeritasClusterHostnames)
+	newerHostnames := sortedCopy(newer.VeritasClusterHostnames)
+
+	fields := []struct {
+		item       string
+		older, new interface{}
+	}{
+		{"oracleClusterware", older.OracleClusterware, newer.OracleClusterware},
+		{"sunCluster", older.SunCluster, newer.SunCluster},
+		{"hacmp", older.HACMP, newer.HACMP},
+		{"veritasClusterServer", older.VeritasClusterServer, newer.VeritasClusterServer},
+		{"veritasClusterHostnames", strings.Join(olderHostnames, ","), strings.Join(newerHostnames, ",")},
+	}
+
+	changes := make([]dto.HostChange, 0)
+
+	for _, f := range fields {
+		if f.older == f.new {
+			continue
+		}
+
+		change := dto.HostChange{
+			Category: dto.HostChangeCategoryCluster,
+			Action:   dto.HostChangeChanged,
+			Item:     f.item,
+			Old:      f.older,
+			New:      f.new,
+		}
+
+		if f.item == "veritasClusterHostnames" {
+			change.Old, change.New = olderHostnames, newerHostnames
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func diffVirtualizationCluster(older, newer *dto.HostVirtualizationCluster) []dto.HostChange {
+	switch {
+	case older == nil && newer == nil:
+		return nil
+	case older == nil:
+		return []dto.HostChange{{
+			Category: dto.HostChangeCategoryCluster,
+			Action:   dto.HostChangeAdded,
+			Item:     "virtualizationCluster",
+			New:      newer.Name,
+		}}
+	case newer == nil:
+		return []dto.HostChange{{
+			Category: dto.HostChangeCategoryCluster,
+			Action:   dto.HostChangeRemoved,
+			Item:     "virtualizationCluster",
+			Old:      older.Name,
+		}}
+	case older.Name != newer.Name:
+		return []dto.HostChange{{
+			Category: dto.HostChangeCategoryCluster,
+			Action:   dto.HostChangeChanged,
+			Item:     "virtualizationCluster",
+			Old:      older.Name,
+			New:      newer.Name,
+		}}
+	case older.VirtualizationNode != newer.VirtualizationNode:
+		return []dto.HostChange{{
+			Category: dto.HostChangeCategoryCluster,
+			Action:   dto.HostChangeChanged,
+			Item:     "virtualizationNode",
+			Old:      older.VirtualizationNode,
+			New:      newer.VirtualizationNode,
+		}}
+	}
+
+	return nil
+}
+
+// diffOracleDatabases compare the databases by name. Added and removed databases only report
+// their license counts, the other changes are reported for the databases present in both snapshots
+func diffOracleDatabases(older, newer map[string]model.OracleDatabase) []dto.HostChange {
+	changes := make([]dto.HostChange, 0)
+
+	for _, name := range unionKeys(older, newer) {
+		olderDb, inOlder := older[name]
+		newerDb, inNewer := newer[name]
+
+		template := dto.HostChange{
+			Technology: model.TechnologyOracleDatabase,
+			Database:   name,
+		}
+
+		switch {
+		case !inOlder:
+			changes = append(changes, withChange(template, dto.HostChangeCategoryDatabase, dto.HostChangeAdded, name))
+		case !inNewer:
+			changes = append(changes, withChange(template, dto.HostChangeCategoryDatabase, dto.HostChangeRemoved, name))
+		default:
+			if olderDb.Version != newerDb.Version {
+				change := withChange(template, dto.HostChangeCategoryDatabase, dto.HostChangeChanged, "version")
+				change.Old, change.New = olderDb.Version, newerDb.Version
+				changes = append(changes, change)
+			}
+
+			template.Category = dto.HostChangeCategoryPdb
+			changes = append(changes, diffSet(template, pdbNames(olderDb), pdbNames(newerDb))...)
+
+			template.Category = dto.HostChangeCategorySchema
+			changes = append(changes, diffSet(template, schemaNames(olderDb), schemaNames(newerDb))...)
+
+			template.Category = dto.HostChangeCategoryOption
+			changes = append(changes, diffSet(template, optionNames(olderDb), optionNames(newerDb))...)
+
+			template.Category = dto.HostChangeCategoryPatch
+			changes = append(changes, diffSet(template, patchNames(olderDb), patchNames(newerDb))...)
+		}
+
+		template.Category = dto.HostChangeCategoryLicense
+		changes = append(changes, diffLicenseCounts(template, olderDb.Licenses, newerDb.Licenses)...)
+	}
+
+	return changes
+}
+
+func diffLicenseCounts(template dto.HostChange, older, newer []model.OracleDatabaseLicense) []dto.HostChange {
+	olderCounts := make(map[string]float64)
+	for _, l := range older {
+		olderCounts[l.LicenseTypeID] += l.Count
+	}
+
+	newerCounts := make(map[string]float64)
+	for _, l := range newer {
+		newerCounts[l.LicenseTypeID] += l.Count
+	}
+
+	changes := make([]dto.HostChange, 0)
+
+	for _, id := range unionKeys(olderCounts, newerCounts) {
+		olderCount, newerCount := olderCounts[id], newerCounts[id]
+		if olderCount == newerCount {
+			continue
+		}
+
+		change := withChange(template, dto.HostChangeCategoryLicense, dto.HostChangeChanged, id)
+		change.Old, change.New = olderCount, newerCount
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// diffSet return the items added and removed, with the other fields copied from template
+func diffSet(template dto.HostChange, older, newer []string) []dto.HostChange {
+	olderSet := make(map[string]bool, len(older))
+	for _, item := range older {
+		olderSet[item] = true
+	}
+
+	newerSet := make(map[string]bool, len(newer))
+	for _, item := range newer {
+		newerSet[item] = true
+	}
+
+	changes := make([]dto.HostChange, 0)
+
+	for _, item := range unionKeys(olderSet, newerSet) {
+		switch {
+		case !olderSet[item]:
+			changes = append(changes, withChange(template, template.Category, dto.HostChangeAdded, item))
+		case !newerSet[item]:
+			changes = append(changes, withChange(template, template.Category, dto.HostChangeRemoved, item))
+		}
+	}
+
+	return changes
+}
+
+func withChange(template dto.HostChange, category, action, item string) dto.HostChange {
+	template.Category = category
+	template.Action = action
+	template.Item = item
+
+	return template
+}
+
+func unionKeys[T any](a, b map[string]T) []string {
+	keys := make([]string, 0, len(a)+len(b))
+
+	for k := range a {
+		keys = append(keys, k)
+	}
+
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedCopy(s []string) []string {
+	res := make([]string, len(s))
+	copy(res, s)
+	sort.Strings(res)
+
+	return res
+}
+
+func oracleDatabases(hostdata *model.HostDataBE) map[string]model.OracleDatabase {
+	res := make(map[string]model.OracleDatabase)
+
+	if hostdata.Features.Oracle == nil || hostdata.Features.Oracle.Database == nil {
+		return res
+	}
+
+	for _, db := range hostdata.Features.Oracle.Database.Databases {
+		res[db.Name] = db
+	}
+
+	return res
+}
+
+func pdbNames(db model.OracleDatabase) []string {
+	res := make([]string, 0, len(db.PDBs))
+	for _, pdb := range db.PDBs {
+		res = append(res, pdb.Name)
+	}
+
+	return res
+}
+
+// schemaNames return the users of the database and of its PDBs, the latter prefixed by the PDB name
+func schemaNames(db model.OracleDatabase) []string {
+	res := make([]string, 0, len(db.Schemas))
+	for _, schema := range db.Schemas {
+		res = append(res, schema.User)
+	}
+
+	for _, pdb := range db.PDBs {
+		for _, schema := range pdb.Schemas {
+			res = append(res, pdb.Name+"/"+schema.User)
+		}
+	}
+
+	return res
+}
+
+// optionNames return the products of the features currently used by the database
+func optionNames(db model.OracleDatabase) []string {
+	res := make([]string, 0)
+
+	for _, stat := range db.FeatureUsageStats {
+		if stat.CurrentlyUsed {
+			res = append(res, stat.Product)
+		}
+	}
+
+	return res
+}
+
+func patchNames(db model.OracleDatabase) []string {
+	res := make([]string, 0, len(db.Patches))
+	for _, patch := range db.Patches {
+		res = append(res, strings.TrimSpace(fmt.Sprintf("%d %s", patch.PatchID, patch.Description)))
+	}
+
+	return res
+}
+
+func instanceNames(hostdata *model.HostDataBE, technology string) []string {
+	res := make([]string, 0)
+	features := hostdata.Features
+
+	switch technology {
+	case model.TechnologyOracleMySQL:
+		if features.MySQL != nil {
+			for _, instance := range features.MySQL.Instances {
+				res = append(res, instance.Name)
+			}
+		}
+	case model.TechnologyMicrosoftSQLServer:
+		if features.Microsoft != nil && features.Microsoft.SQLServer != nil {
+			for _, instance := range features.Microsoft.SQLServer.Instances {
+				res = append(res, instance.Name)
+			}
+		}
+	case model.TechnologyPostgreSQLPostgreSQL:
+		if features.PostgreSQL != nil {
+			for _, instance := range features.PostgreSQL.Instances {
+				res = append(res, instance.Name)
+			}
+		}
+	case model.TechnologyMongoDBMongoDB:
+		if features.MongoDB != nil {
+			for _, instance := range features.MongoDB.Instances {
+				res = append(res, instance.Name)
+			}
+		}
+	}
+
+	return res
+}
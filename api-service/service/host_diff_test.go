@@ -0,0 +1,238 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func hostDiffTestSnapshot(createdAt string, cores int, dbs ...model.OracleDatabase) model.HostDataBE {
+	return model.HostDataBE{
+		Hostname:  "vm01",
+		Location:  "Italy",
+		CreatedAt: utils.P(createdAt),
+		Info: model.Host{
+			CPUModel:    "Intel(R) Xeon(R) Gold 6248R CPU @ 3.00GHz",
+			CPUCores:    cores,
+			MemoryTotal: 32,
+		},
+		Features: model.Features{
+			Oracle: &model.OracleFeature{
+				Database: &model.OracleDatabaseFeature{
+					Databases: dbs,
+				},
+			},
+		},
+	}
+}
+
+func TestDiffHostData(t *testing.T) {
+	olderDb := model.OracleDatabase{
+		Name:    "ERCOLE",
+		Version: "19.0.0.0.0 Enterprise Edition",
+		PDBs: []model.OracleDatabasePluggableDatabase{
+			{Name: "PDB1", Schemas: []model.OracleDatabaseSchema{{User: "APP"}}},
+			{Name: "PDB2"},
+		},
+		Schemas: []model.OracleDatabaseSchema{{User: "SYS"}},
+		FeatureUsageStats: []model.OracleDatabaseFeatureUsageStat{
+			{Product: "Partitioning", Feature: "Partitioning (user)", CurrentlyUsed: true},
+			{Product: "Diagnostics Pack", Feature: "AWR Report", CurrentlyUsed: false},
+		},
+		Patches: []model.OracleDatabasePatch{{PatchID: 31281355, Description: "Database Release Update : 19.8.0.0.200714"}},
+		Licenses: []model.OracleDatabaseLicense{
+			{LicenseTypeID: "A90611", Count: 2},
+			{LicenseTypeID: "A90619", Count: 0},
+		},
+	}
+	removedDb := model.OracleDatabase{
+		Name:     "OLD",
+		Licenses: []model.OracleDatabaseLicense{{LicenseTypeID: "A90611", Count: 2}},
+	}
+
+	newerDb := olderDb
+	newerDb.PDBs = []model.OracleDatabasePluggableDatabase{
+		{Name: "PDB1", Schemas: []model.OracleDatabaseSchema{{User: "APP"}, {User: "REPORTS"}}},
+		{Name: "PDB3"},
+	}
+	newerDb.FeatureUsageStats = []model.OracleDatabaseFeatureUsageStat{
+		{Product: "Partitioning", Feature: "Partitioning (user)", CurrentlyUsed: true},
+		{Product: "Diagnostics Pack", Feature: "AWR Report", CurrentlyUsed: true},
+	}
+	newerDb.Patches = append(newerDb.Patches, model.OracleDatabasePatch{PatchID: 31771877, Description: "Database Release Update : 19.9.0.0.201020"})
+	newerDb.Licenses = []model.OracleDatabaseLicense{
+		{LicenseTypeID: "A90611", Count: 4},
+		{LicenseTypeID: "A90619", Count: 4},
+	}
+	addedDb := model.OracleDatabase{Name: "NEW"}
+
+	older := hostDiffTestSnapshot("2024-01-01T10:00:00Z", 2, olderDb, removedDb)
+	older.ClusterMembershipStatus = model.ClusterMembershipStatus{VeritasClusterServer: true, VeritasClusterHostnames: []string{"vm02", "vm01"}}
+	older.Features.MySQL = &model.MySQLFeature{Instances: []model.MySQLInstance{{Name: "mysql:3306"}}}
+
+	newer := hostDiffTestSnapshot("2024-02-01T10:00:00Z", 4, addedDb, newerDb)
+	newer.ClusterMembershipStatus = model.ClusterMembershipStatus{VeritasClusterServer: true, VeritasClusterHostnames: []string{"vm01", "vm02"}}
+
+	oracle := model.TechnologyOracleDatabase
+	expected := []dto.HostChange{
+		{Category: dto.HostChangeCategoryHost, Action: dto.HostChangeChanged, Item: "cpuCores", Old: 2, New: 4},
+		{Category: dto.HostChangeCategoryCluster, Action: dto.HostChangeChanged, Item: "virtualizationCluster", Old: "Puzzait", New: "Bart"},
+		{Category: dto.HostChangeCategoryPdb, Action: dto.HostChangeRemoved, Technology: oracle, Database: "ERCOLE", Item: "PDB2"},
+		{Category: dto.HostChangeCategoryPdb, Action: dto.HostChangeAdded, Technology: oracle, Database: "ERCOLE", Item: "PDB3"},
+		{Category: dto.HostChangeCategorySchema, Action: dto.HostChangeAdded, Technology: oracle, Database: "ERCOLE", Item: "PDB1/REPORTS"},
+		{Category: dto.HostChangeCategoryOption, Action: dto.HostChangeAdded, Technology: oracle, Database: "ERCOLE", Item: "Diagnostics Pack"},
+		{Category: dto.HostChangeCategoryPatch, Action: dto.HostChangeAdded, Technology: oracle, Database: "ERCOLE", Item: "31771877 Database Release Update : 19.9.0.0.201020"},
+		{Category: dto.HostChangeCategoryLicense, Action: dto.HostChangeChanged, Technology: oracle, Database: "ERCOLE", Item: "A90611", Old: 2.0, New: 4.0},
+		{Category: dto.HostChangeCategoryLicense, Action: dto.HostChangeChanged, Technology: oracle, Database: "ERCOLE", Item: "A90619", Old: 0.0, New: 4.0},
+		{Category: dto.HostChangeCategoryDatabase, Action: dto.HostChangeAdded, Technology: oracle, Database: "NEW", Item: "NEW"},
+		{Category: dto.HostChangeCategoryDatabase, Action: dto.HostChangeRemoved, Technology: oracle, Database: "OLD", Item: "OLD"},
+		{Category: dto.HostChangeCategoryLicense, Action: dto.HostChangeChanged, Technology: oracle, Database: "OLD", Item: "A90611", Old: 2.0, New: 0.0},
+		{Category: dto.HostChangeCategoryDatabase, Action: dto.HostChangeRemoved, Technology: model.TechnologyOracleMySQL, Item: "mysql:3306"},
+	}
+
+	actual := diffHostData(&older, &dto.HostVirtualizationCluster{Name: "Puzzait", VirtualizationNode: "node1"},
+		&newer, &dto.HostVirtualizationCluster{Name: "Bart", VirtualizationNode: "node2"})
+	assert.Equal(t, expected, actual)
+}
+
+func TestDiffHostData_NewHost(t *testing.T) {
+	newer := hostDiffTestSnapshot("2024-02-01T10:00:00Z", 4, model.OracleDatabase{
+		Name:     "ERCOLE",
+		Licenses: []model.OracleDatabaseLicense{{LicenseTypeID: "A90611", Count: 2}},
+	})
+
+	expected := []dto.HostChange{
+		{Category: dto.HostChangeCategoryHost, Action: dto.HostChangeAdded, Item: "vm01"},
+		{Category: dto.HostChangeCategoryDatabase, Action: dto.HostChangeAdded, Technology: model.TechnologyOracleDatabase, Database: "ERCOLE", Item: "ERCOLE"},
+		{Category: dto.HostChangeCategoryLicense, Action: dto.HostChangeChanged, Technology: model.TechnologyOracleDatabase, Database: "ERCOLE", Item: "A90611", Old: 0.0, New: 2.0},
+	}
+
+	assert.Equal(t, expected, diffHostData(nil, nil, &newer, nil))
+}
+
+func TestGetHostDiff(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	from := utils.P("2024-01-15T00:00:00Z")
+	to := utils.P("2024-02-15T00:00:00Z")
+	older := hostDiffTestSnapshot("2024-01-01T10:00:00Z", 2)
+	newer := hostDiffTestSnapshot("2024-02-01T10:00:00Z", 4)
+
+	t.Run("Success", func(t *testing.T) {
+		db.EXPECT().GetHostData("vm01", to).Return(&newer, nil)
+		db.EXPECT().GetHostVirtualizationCluster("vm01", to).Return(nil, nil)
+		db.EXPECT().GetHostData("vm01", from).Return(&older, nil)
+		db.EXPECT().GetHostVirtualizationCluster("vm01", from).Return(nil, nil)
+
+		actual, err := as.GetHostDiff("vm01", from, to)
+		require.NoError(t, err)
+
+		fromSnapshot := utils.P("2024-01-01T10:00:00Z")
+		expected := &dto.HostDiff{
+			Hostname:     "vm01",
+			Location:     "Italy",
+			From:         from,
+			To:           to,
+			FromSnapshot: &fromSnapshot,
+			ToSnapshot:   utils.P("2024-02-01T10:00:00Z"),
+			Changes: []dto.HostChange{
+				{Category: dto.HostChangeCategoryHost, Action: dto.HostChangeChanged, Item: "cpuCores", Old: 2, New: 4},
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Host not found", func(t *testing.T) {
+		db.EXPECT().GetHostData("vm01", to).Return(nil, utils.ErrHostNotFound)
+
+		_, err := as.GetHostDiff("vm01", from, to)
+		assert.ErrorIs(t, err, utils.ErrHostNotFound)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		_, err := as.GetHostDiff("vm01", to, from)
+		assert.ErrorIs(t, err, utils.ErrInvalidHostHistoryRange)
+	})
+}
+
+func TestGetHostTimeline(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	from := utils.P("2024-01-15T00:00:00Z")
+	to := utils.P("2024-03-15T00:00:00Z")
+	first := hostDiffTestSnapshot("2024-01-01T10:00:00Z", 2)
+	snapshots := []model.HostDataBE{
+		hostDiffTestSnapshot("2024-02-01T10:00:00Z", 2),
+		hostDiffTestSnapshot("2024-03-01T10:00:00Z", 4),
+	}
+
+	db.EXPECT().GetHostData("vm01", from).Return(&first, nil)
+	db.EXPECT().ListHostDataHistory("vm01", from, to).Return(snapshots, nil)
+	db.EXPECT().GetHostVirtualizationCluster("vm01", from).Return(nil, nil)
+	db.EXPECT().GetHostVirtualizationCluster("vm01", utils.P("2024-02-01T10:00:00Z")).
+		Return(&dto.HostVirtualizationCluster{Name: "Puzzait", VirtualizationNode: "node1"}, nil)
+	db.EXPECT().GetHostVirtualizationCluster("vm01", utils.P("2024-03-01T10:00:00Z")).
+		Return(&dto.HostVirtualizationCluster{Name: "Puzzait", VirtualizationNode: "node1"}, nil)
+
+	actual, err := as.GetHostTimeline("vm01", from, to)
+	require.NoError(t, err)
+
+	expected := &dto.HostTimeline{
+		Hostname: "vm01",
+		Location: "Italy",
+		From:     from,
+		To:       to,
+		Entries: []dto.HostTimelineEntry{
+			{
+				Date: utils.P("2024-02-01T10:00:00Z"),
+				Changes: []dto.HostChange{
+					{Category: dto.HostChangeCategoryCluster, Action: dto.HostChangeAdded, Item: "virtualizationCluster", New: "Puzzait"},
+				},
+			},
+			{
+				Date: utils.P("2024-03-01T10:00:00Z"),
+				Changes: []dto.HostChange{
+					{Category: dto.HostChangeCategoryHost, Action: dto.HostChangeChanged, Item: "cpuCores", Old: 2, New: 4},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual)
+}
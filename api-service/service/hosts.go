@@ -47,6 +47,13 @@ func (as *APIService) SearchHostsAsLMS(filters dto.SearchHostsAsLMS) (*excelize.
 		return nil, utils.NewError(err, "")
 	}
 
+	coreFactors, err := as.getCoreFactorTable()
+	if err != nil {
+		return nil, err
+	}
+
+	setCoreFactorLicensesLMS(coreFactors, hosts)
+
 	csiByHostname, err := as.getCSIsByHostname()
 	if err != nil {
 		return nil, utils.NewError(err, "")
@@ -94,6 +101,8 @@ func (as *APIService) SearchHostsAsLMS(filters dto.SearchHostsAsLMS) (*excelize.
 					return nil, utils.NewError(err, "")
 				}
 
+				setCoreFactorLicensesLMS(coreFactors, cHosts)
+
 				for i := 0; i < len(cHosts); i++ {
 					if !headerHostCreated {
 						indexsheetHostAdded := lms.NewSheet(sheetHostAdded)
@@ -139,6 +148,8 @@ func (as *APIService) SearchHostsAsLMS(filters dto.SearchHostsAsLMS) (*excelize.
 					return nil, utils.NewError(err, "")
 				}
 
+				setCoreFactorLicensesLMS(coreFactors, dHosts)
+
 				for i := 0; i < len(dHosts); i++ {
 					if !headerHostDismissed {
 						indexsheetHostDismissed := lms.NewSheet(sheetHostDismissed)
@@ -213,6 +224,45 @@ func (as *APIService) GetHostsMysqlAsLMS(filters dto.SearchHostsAsLMS) (*exceliz
 	return lms, nil
 }
 
+// setCoreFactorLicensesLMS recompute the processor licenses used by Enterprise databases with the core factor table
+func setCoreFactorLicensesLMS(coreFactors *model.CoreFactorTable, hosts []map[string]interface{}) {
+	for _, host := range hosts {
+		if host["productLicenseAllocated"] != "EE" || host["usingLicenseCount"] == 0.0 {
+			continue
+		}
+
+		if metric := host["licenseMetricAllocated"]; metric != model.LicenseTypeMetricProcessorPerpetual && metric != "processor" {
+			continue
+		}
+
+		processorModel, _ := host["processorModel"].(string)
+		cloudMembership, _ := host["cloudMembership"].(string)
+
+		coreFactor, ok := coreFactors.Lookup(&model.HostDataBE{
+			Info:  model.Host{CPUModel: processorModel},
+			Cloud: model.Cloud{Membership: cloudMembership},
+		})
+		if !ok {
+			continue
+		}
+
+		var physicalCores float64
+
+		switch cores := host["physicalCores"].(type) {
+		case int32:
+			physicalCores = float64(cores)
+		case int64:
+			physicalCores = float64(cores)
+		case float64:
+			physicalCores = cores
+		default:
+			continue
+		}
+
+		host["usingLicenseCount"] = physicalCores * coreFactor.Factor
+	}
+}
+
 func setCellValueLMS(lms *excelize.File, sheetName string, i int, csiByHostname map[string][]string, val map[string]interface{}, primaryDBs map[string]string) {
 	lms.SetCellValue(sheetName, fmt.Sprintf("B%d", i), val["physicalServerName"])
 	lms.SetCellValue(sheetName, fmt.Sprintf("C%d", i), val["virtualServerName"])
@@ -499,17 +549,17 @@ func (as *APIService) ListEnvironments(location string, environment string, olde
 }
 
 // DismissHost dismiss the specified host
-func (as *APIService) DismissHost(hostname string) error {
+func (as *APIService) DismissHost(hostname, username string) error {
 	filter := dto.AlertsFilter{OtherInfo: map[string]interface{}{"hostname": hostname}}
 	if err := as.RemoveAlertsNODATA(filter); err != nil {
 		as.Log.Errorf("Can't delete alerts by %s", hostname)
 	}
 
-	if err := as.AckAlerts(filter); err != nil {
+	if err := as.AckAlerts(filter, username); err != nil {
 		as.Log.Errorf("Can't ack hostname %s alerts by filter", hostname)
 	}
 
-	if err := as.UpdateAlertsStatus(filter, model.AlertStatusDismissed); err != nil {
+	if err := as.UpdateAlertsStatus(filter, model.AlertStatusDismissed, username); err != nil {
 		as.Log.Errorf("Can't dismiss hostname %s alerts by filter", hostname)
 	}
 
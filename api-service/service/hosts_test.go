@@ -172,6 +172,8 @@ func TestSearchHostsAsLMS(t *testing.T) {
 		Log: logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	hosts := []map[string]interface{}{
 		{
 			"coresPerProcessor":        1,
@@ -992,8 +994,8 @@ func TestDismissHost_Success(t *testing.T) {
 	filter := dto.AlertsFilter{OtherInfo: map[string]interface{}{"hostname": "foobar"}}
 	db.EXPECT().RemoveAlertsNODATA(filter).Return(nil).Times(1)
 	db.EXPECT().CountAlertsNODATA(filter).Return(count, nil).Times(1)
-	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusAck).Return(nil)
-	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusDismissed).Return(nil)
+	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusAck, "usertest").Return(nil)
+	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusDismissed, "usertest").Return(nil)
 	commonFilters := dto.NewSearchHostsFilters()
 	db.EXPECT().SearchHosts(
 		"hostnames",
@@ -1013,7 +1015,7 @@ func TestDismissHost_Success(t *testing.T) {
 
 	db.EXPECT().DismissHost("foobar").Return(nil).Times(1)
 
-	err := as.DismissHost("foobar")
+	err := as.DismissHost("foobar", "usertest")
 	require.NoError(t, err)
 }
 
@@ -1039,8 +1041,8 @@ func TestDismissHost_Fail(t *testing.T) {
 	filter := dto.AlertsFilter{OtherInfo: map[string]interface{}{"hostname": "foobar"}}
 	db.EXPECT().RemoveAlertsNODATA(filter).Return(nil).Times(1)
 	db.EXPECT().CountAlertsNODATA(filter).Return(count, nil).Times(1)
-	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusAck).Return(nil)
-	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusDismissed).Return(nil)
+	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusAck, "usertest").Return(nil)
+	db.EXPECT().UpdateAlertsStatus(filter, model.AlertStatusDismissed, "usertest").Return(nil)
 	commonFilters := dto.NewSearchHostsFilters()
 	db.EXPECT().SearchHosts(
 		"hostnames",
@@ -1049,7 +1051,7 @@ func TestDismissHost_Fail(t *testing.T) {
 	db.EXPECT().ListOracleDatabaseContracts(gomock.Any()).Return(listContracts, nil)
 	db.EXPECT().DismissHost("foobar").Return(aerrMock).Times(1)
 
-	err := as.DismissHost("foobar")
+	err := as.DismissHost("foobar", "usertest")
 	assert.Error(t, err)
 }
 
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/schema"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (as *APIService) ListMaintenanceWindows() ([]model.MaintenanceWindow, error) {
+	return as.Database.ListMaintenanceWindows()
+}
+
+func (as *APIService) GetMaintenanceWindow(id primitive.ObjectID) (*model.MaintenanceWindow, error) {
+	return as.Database.GetMaintenanceWindow(id)
+}
+
+func (as *APIService) AddMaintenanceWindow(window model.MaintenanceWindow) (*model.MaintenanceWindow, error) {
+	if err := validateMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+
+	window.ID = primitive.NewObjectID()
+
+	if err := as.Database.InsertMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+
+	return &window, nil
+}
+
+func (as *APIService) UpdateMaintenanceWindow(window model.MaintenanceWindow) (*model.MaintenanceWindow, error) {
+	if err := validateMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+
+	if err := as.Database.UpdateMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+
+	return &window, nil
+}
+
+func (as *APIService) DeleteMaintenanceWindow(id primitive.ObjectID) error {
+	return as.Database.DeleteMaintenanceWindow(id)
+}
+
+func validateMaintenanceWindow(window model.MaintenanceWindow) error {
+	raw, err := json.Marshal(window)
+	if err != nil {
+		return err
+	}
+
+	if err := schema.ValidateMaintenanceWindow(raw); err != nil {
+		return err
+	}
+
+	if !window.End.After(window.Start) {
+		return fmt.Errorf("%w: the end must be after the start", utils.ErrInvalidMaintenanceWindow)
+	}
+
+	return nil
+}
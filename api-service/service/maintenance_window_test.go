@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestAddMaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+	}
+
+	window := model.MaintenanceWindow{
+		Name:      "patching",
+		Start:     utils.P("2024-03-01T20:00:00Z"),
+		End:       utils.P("2024-03-01T23:00:00Z"),
+		Hostnames: []string{"foobar"},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		db.EXPECT().InsertMaintenanceWindow(gomock.Any()).
+			DoAndReturn(func(actual model.MaintenanceWindow) error {
+				assert.False(t, actual.ID.IsZero())
+				assert.Equal(t, window.Hostnames, actual.Hostnames)
+
+				return nil
+			}).Times(1)
+
+		actual, err := as.AddMaintenanceWindow(window)
+		require.NoError(t, err)
+		assert.False(t, actual.ID.IsZero())
+	})
+
+	t.Run("End before start", func(t *testing.T) {
+		wrong := window
+		wrong.End = utils.P("2024-03-01T19:00:00Z")
+
+		actual, err := as.AddMaintenanceWindow(wrong)
+		require.ErrorIs(t, err, utils.ErrInvalidMaintenanceWindow)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("Without hosts, locations or tags", func(t *testing.T) {
+		wrong := window
+		wrong.Hostnames = nil
+
+		actual, err := as.AddMaintenanceWindow(wrong)
+		require.ErrorIs(t, err, utils.ErrInvalidMaintenanceWindow)
+		assert.Nil(t, actual)
+	})
+}
+
+func TestUpdateMaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+	}
+
+	window := model.MaintenanceWindow{
+		ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+		Name:      "patching",
+		Start:     utils.P("2024-03-01T20:00:00Z"),
+		End:       utils.P("2024-03-01T23:00:00Z"),
+		Locations: []string{"Italy"},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		db.EXPECT().UpdateMaintenanceWindow(window).Return(nil).Times(1)
+
+		actual, err := as.UpdateMaintenanceWindow(window)
+		require.NoError(t, err)
+		assert.Equal(t, &window, actual)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		db.EXPECT().UpdateMaintenanceWindow(window).Return(utils.ErrMaintenanceWindowNotFound).Times(1)
+
+		actual, err := as.UpdateMaintenanceWindow(window)
+		require.ErrorIs(t, err, utils.ErrMaintenanceWindowNotFound)
+		assert.Nil(t, actual)
+	})
+}
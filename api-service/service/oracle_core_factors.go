@@ -0,0 +1,68 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"github.com/ercole-io/ercole/v2/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetCoreFactors return the rows of the core factor table
+func (as *APIService) GetCoreFactors() ([]model.CoreFactor, error) {
+	return as.Database.GetCoreFactors()
+}
+
+// AddCoreFactor add a row to the core factor table
+func (as *APIService) AddCoreFactor(coreFactor model.CoreFactor) (*model.CoreFactor, error) {
+	if err := coreFactor.Validate(); err != nil {
+		return nil, err
+	}
+
+	coreFactor.ID = as.NewObjectID()
+
+	if err := as.Database.InsertCoreFactor(coreFactor); err != nil {
+		return nil, err
+	}
+
+	return &coreFactor, nil
+}
+
+// UpdateCoreFactor update a row of the core factor table
+func (as *APIService) UpdateCoreFactor(coreFactor model.CoreFactor) (*model.CoreFactor, error) {
+	if err := coreFactor.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := as.Database.UpdateCoreFactor(coreFactor); err != nil {
+		return nil, err
+	}
+
+	return &coreFactor, nil
+}
+
+// DeleteCoreFactor remove a row from the core factor table
+func (as *APIService) DeleteCoreFactor(id primitive.ObjectID) error {
+	return as.Database.RemoveCoreFactor(id)
+}
+
+func (as *APIService) getCoreFactorTable() (*model.CoreFactorTable, error) {
+	coreFactors, err := as.Database.GetCoreFactors()
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewCoreFactorTable(coreFactors)
+}
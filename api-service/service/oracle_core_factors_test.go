@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestAddCoreFactor_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database:    db,
+		Config:      config.Configuration{},
+		NewObjectID: utils.NewObjectIDForTests(),
+	}
+
+	coreFactor := model.CoreFactor{
+		Description:   "SPARC T4",
+		CPUModelRegex: "SPARC-T4",
+		Factor:        0.5,
+		Priority:      5,
+	}
+
+	expected := coreFactor
+	expected.ID = utils.Str2oid("000000000000000000000001")
+
+	db.EXPECT().InsertCoreFactor(expected).Return(nil)
+
+	res, err := as.AddCoreFactor(coreFactor)
+	require.NoError(t, err)
+	assert.Equal(t, &expected, res)
+}
+
+func TestAddCoreFactor_Invalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database:    db,
+		Config:      config.Configuration{},
+		NewObjectID: utils.NewObjectIDForTests(),
+	}
+
+	_, err := as.AddCoreFactor(model.CoreFactor{
+		CPUModelRegex:   "SPARC",
+		CloudMembership: model.CloudMembershipAws,
+		Factor:          0.5,
+	})
+	assert.ErrorIs(t, err, utils.ErrInvalidCoreFactor)
+}
+
+func TestUpdateCoreFactor_NotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+	}
+
+	coreFactor := model.CoreFactor{
+		ID:              utils.Str2oid("000000000000000000000001"),
+		CloudMembership: model.CloudMembershipOci,
+		Factor:          1,
+	}
+
+	db.EXPECT().UpdateCoreFactor(coreFactor).Return(utils.ErrCoreFactorNotFound)
+
+	_, err := as.UpdateCoreFactor(coreFactor)
+	assert.ErrorIs(t, err, utils.ErrCoreFactorNotFound)
+}
+
+func TestGetCoreFactorTable_Error(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+	}
+
+	db.EXPECT().GetCoreFactors().Return(nil, errors.New("DB ERROR"))
+
+	_, err := as.getCoreFactorTable()
+	assert.Error(t, err)
+}
+
+func TestCoreFactorUsedLicenses(t *testing.T) {
+	coreFactors, err := model.NewCoreFactorTable([]model.CoreFactor{
+		{CPUModelRegex: "SPARC", Factor: 0.75},
+	})
+	require.NoError(t, err)
+
+	hostdata := &model.HostDataBE{
+		Hostname: "sparcy",
+		Info:     model.Host{CPUModel: "SPARC-T4", CPUCores: 16},
+		Features: model.Features{
+			Oracle: &model.OracleFeature{
+				Database: &model.OracleDatabaseFeature{
+					Databases: []model.OracleDatabase{
+						{Name: "ENTDB", Version: "19.0.0.0.0 Enterprise Edition"},
+						{Name: "STDDB", Version: "19.0.0.0.0 Standard Edition"},
+					},
+				},
+			},
+		},
+	}
+
+	license := dto.DatabaseUsedLicense{
+		Hostname: "sparcy",
+		DbName:   "ENTDB",
+		Metric:   model.LicenseTypeMetricProcessorPerpetual,
+	}
+
+	used, ok := coreFactorUsedLicenses(license, hostdata, coreFactors)
+	require.True(t, ok)
+	assert.Equal(t, 12.0, used)
+
+	license.DbName = "STDDB"
+	_, ok = coreFactorUsedLicenses(license, hostdata, coreFactors)
+	assert.False(t, ok)
+
+	license.DbName = "ENTDB"
+	hostdata.Info.CPUModel = "Intel(R) Xeon(R) Platinum 8160"
+	_, ok = coreFactorUsedLicenses(license, hostdata, coreFactors)
+	assert.False(t, ok)
+}
+
+func TestSetCoreFactorLicensesLMS(t *testing.T) {
+	coreFactors, err := model.NewCoreFactorTable([]model.CoreFactor{
+		{CloudMembership: model.CloudMembershipAws, Factor: 1},
+		{CPUModelRegex: "Xeon", Factor: 0.5},
+	})
+	require.NoError(t, err)
+
+	hosts := []map[string]interface{}{
+		{
+			"productLicenseAllocated": "EE",
+			"licenseMetricAllocated":  model.LicenseTypeMetricProcessorPerpetual,
+			"usingLicenseCount":       2.0,
+			"processorModel":          "Intel(R) Xeon(R) Platinum 8160",
+			"cloudMembership":         model.CloudMembershipAws,
+			"physicalCores":           int32(8),
+		},
+		{
+			"productLicenseAllocated": "EE",
+			"licenseMetricAllocated":  "processor",
+			"usingLicenseCount":       2.0,
+			"processorModel":          "Intel(R) Xeon(R) Platinum 8160",
+			"physicalCores":           int64(8),
+		},
+		{
+			"productLicenseAllocated": "SE",
+			"licenseMetricAllocated":  model.LicenseTypeMetricProcessorPerpetual,
+			"usingLicenseCount":       1.0,
+			"processorModel":          "Intel(R) Xeon(R) Platinum 8160",
+			"physicalCores":           int32(8),
+		},
+	}
+
+	setCoreFactorLicensesLMS(coreFactors, hosts)
+
+	assert.Equal(t, 8.0, hosts[0]["usingLicenseCount"])
+	assert.Equal(t, 4.0, hosts[1]["usingLicenseCount"])
+	assert.Equal(t, 1.0, hosts[2]["usingLicenseCount"])
+}
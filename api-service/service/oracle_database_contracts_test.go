@@ -325,6 +325,8 @@ func TestGetOracleDatabaseContracts_Success(t *testing.T) {
 		NewObjectID: utils.NewObjectIDForTests(),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	licenseTypes := []model.OracleDatabaseLicenseType{
 		{
 			ID:              "PID002",
@@ -438,6 +440,8 @@ func TestGetOracleDatabaseContractsCluster_Success(t *testing.T) {
 		NewObjectID: utils.NewObjectIDForTests(),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	licenseTypes := []model.OracleDatabaseLicenseType{
 		{
 			ID:              "PID002",
@@ -610,6 +614,8 @@ func TestGetOracleDatabaseContractsClusterCappedCPU_Success(t *testing.T) {
 		NewObjectID: utils.NewObjectIDForTests(),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	licenseTypes := []model.OracleDatabaseLicenseType{
 		{
 			ID:              "PID002",
@@ -782,6 +788,8 @@ func TestGetOracleDatabaseContractsClusterCappedCPU2_Success(t *testing.T) {
 		NewObjectID: utils.NewObjectIDForTests(),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	licenseTypes := []model.OracleDatabaseLicenseType{
 		{
 			ID:              "PID002",
@@ -991,6 +999,8 @@ func TestGetOracleDatabaseContracts_SuccessFilter1(t *testing.T) {
 		NewObjectID: utils.NewObjectIDForTests(),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	parts := []model.OracleDatabaseLicenseType{
 		{
 			ID:              "PID002",
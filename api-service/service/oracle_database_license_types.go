@@ -64,26 +64,33 @@ func (as *APIService) GetOracleDatabaseLicenseType(id string) (*model.OracleData
 }
 
 func (as *APIService) GetOracleDatabaseLicensesCompliance(locations []string) ([]dto.LicenseCompliance, error) {
+	licenses, _, err := as.oracleDatabaseLicensesCompliance(locations)
+
+	return licenses, err
+}
+
+// oracleDatabaseLicensesCompliance returns the compliance of the licenses and the usages it has been computed on
+func (as *APIService) oracleDatabaseLicensesCompliance(locations []string) ([]dto.LicenseCompliance, []dto.HostUsingOracleDatabaseLicenses, error) {
 	filter := dto.NewGetOracleDatabaseContractsFilter()
 	filter.Locations = locations
 
 	contracts, err := as.Database.ListOracleDatabaseContracts(filter)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	usages, err := as.getLicensesUsage(filter.Locations)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := as.assignOracleDatabaseContractsToHosts(contracts, usages); err != nil {
-		return nil, utils.NewError(err, "can't assign contracts to hosts")
+		return nil, nil, utils.NewError(err, "can't assign contracts to hosts")
 	}
 
 	getLicenseCompliance, err := as.getterNewLicenseCompliance()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	licenses := make(map[string]*dto.LicenseCompliance)
@@ -149,7 +156,7 @@ func (as *APIService) GetOracleDatabaseLicensesCompliance(locations []string) ([
 		result = append(result, *license)
 	}
 
-	return result, nil
+	return result, usages, nil
 }
 
 func (as *APIService) getLicensesUsage(locations []string) ([]dto.HostUsingOracleDatabaseLicenses, error) {
@@ -278,6 +285,10 @@ func (as *APIService) getLicensesUsage(locations []string) ([]dto.HostUsingOracl
 			OriginalCount: licensesCount,
 		}
 
+		if host, ok := hostdatasMap[name]; ok && typeClusterHost == "host" && model.IsAuthorizedCloud(host.Cloud.Membership) {
+			g.CloudMembership = host.Cloud.Membership
+		}
+
 		usages = append(usages, g)
 	}
 
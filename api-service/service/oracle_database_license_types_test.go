@@ -179,6 +179,8 @@ func TestGetLicensesCompliance(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	hostdatas := []model.HostDataBE{
 		{
 			ClusterMembershipStatus: model.ClusterMembershipStatus{
@@ -391,6 +393,8 @@ func TestGetLicensesCompliance_Veritas(t *testing.T) {
 		Log:      logger.NewLogger("TEST"),
 	}
 
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
 	hostdatas := []model.HostDataBE{
 		{
 			Hostname: "test1",
@@ -0,0 +1,323 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/alert-service/emailer"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/schema"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/ercole-io/ercole/v2/utils/exutils"
+)
+
+// ReportExporter return the xlsx of a report filtered by the filters of a subscription
+type ReportExporter func(filters model.ReportFilters) (*excelize.File, error)
+
+func (as *APIService) ListReportSubscriptions() ([]model.ReportSubscription, error) {
+	return as.Database.ListReportSubscriptions()
+}
+
+func (as *APIService) GetReportSubscription(id primitive.ObjectID) (*model.ReportSubscription, error) {
+	return as.Database.GetReportSubscription(id)
+}
+
+func (as *APIService) AddReportSubscription(subscription model.ReportSubscription) (*model.ReportSubscription, error) {
+	if err := as.validateReportSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	subscription.ID = as.NewObjectID()
+	subscription.CreatedAt = as.TimeNow()
+	subscription.LastRun = nil
+
+	if err := as.Database.InsertReportSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (as *APIService) UpdateReportSubscription(subscription model.ReportSubscription) (*model.ReportSubscription, error) {
+	if err := as.validateReportSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	old, err := as.Database.GetReportSubscription(subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.CreatedAt = old.CreatedAt
+	subscription.LastRun = old.LastRun
+
+	if err := as.Database.UpdateReportSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (as *APIService) DeleteReportSubscription(id primitive.ObjectID) error {
+	return as.Database.DeleteReportSubscription(id)
+}
+
+// ListReportRuns return the history of the deliveries of the report subscription
+func (as *APIService) ListReportRuns(id primitive.ObjectID) ([]model.ReportRun, error) {
+	if _, err := as.Database.GetReportSubscription(id); err != nil {
+		return nil, err
+	}
+
+	return as.Database.ListReportRuns(id)
+}
+
+// RunReportSubscription deliver now the report subscription, even if it's disabled.
+// A failed delivery isn't an error: it's recorded in the returned run
+func (as *APIService) RunReportSubscription(id primitive.ObjectID) (*model.ReportRun, error) {
+	subscription, err := as.Database.GetReportSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return as.runReportSubscription(*subscription, model.ReportRunTriggerManual)
+}
+
+// RunDueReportSubscriptions deliver the enabled report subscriptions whose schedule
+// has been activated since their last run, or since their creation
+func (as *APIService) RunDueReportSubscriptions() error {
+	subscriptions, err := as.Database.ListReportSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	now := as.TimeNow()
+
+	for _, subscription := range subscriptions {
+		if !subscription.Enabled || !reportSubscriptionIsDue(subscription, now) {
+			continue
+		}
+
+		if _, err := as.runReportSubscription(subscription, model.ReportRunTriggerSchedule); err != nil {
+			as.Log.Errorf("Can't run report subscription %q: %s", subscription.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func reportSubscriptionIsDue(subscription model.ReportSubscription, now time.Time) bool {
+	schedule, err := cron.ParseStandard(subscription.Schedule)
+	if err != nil {
+		return false
+	}
+
+	from := subscription.CreatedAt
+	if subscription.LastRun != nil {
+		from = *subscription.LastRun
+	}
+
+	return !schedule.Next(from.In(now.Location())).After(now)
+}
+
+func (as *APIService) runReportSubscription(subscription model.ReportSubscription, trigger string) (*model.ReportRun, error) {
+	now := as.TimeNow()
+
+	run := model.ReportRun{
+		ID:             as.NewObjectID(),
+		SubscriptionID: subscription.ID,
+		Date:           now,
+		Trigger:        trigger,
+		Recipients:     subscription.Recipients,
+		Attachments:    []string{},
+	}
+
+	attachments, err := as.deliverReportSubscription(subscription, now)
+	if err != nil {
+		as.Log.Errorf("Can't deliver report subscription %q: %s", subscription.Name, err)
+		run.Error = err.Error()
+	} else {
+		run.Success = true
+
+		for _, a := range attachments {
+			run.Attachments = append(run.Attachments, a.Filename)
+		}
+	}
+
+	if err := as.Database.InsertReportRun(run); err != nil {
+		return nil, err
+	}
+
+	if err := as.Database.UpdateReportSubscriptionLastRun(subscription.ID, now); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (as *APIService) deliverReportSubscription(subscription model.ReportSubscription, now time.Time) ([]emailer.Attachment, error) {
+	if as.Emailer == nil || !as.Config.AlertService.Emailer.Enabled {
+		return nil, utils.ErrEmailerDisabled
+	}
+
+	exporter, ok := as.getReportExporter(subscription.Report)
+	if !ok {
+		return nil, utils.NewErrorf("%w: report %s isn't available", utils.ErrInvalidReportSubscription, subscription.Report)
+	}
+
+	file, err := exporter(subscription.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := reportAttachments(file, subscription, now)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("Ercole report %s - %s", subscription.Name, now.Format("02/01/2006"))
+	text := fmt.Sprintf("Please see the attached %s report.", subscription.Report)
+
+	if err := as.Emailer.SendEmailWithAttachments(subject, text, subscription.Recipients, attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// reportAttachments convert the xlsx of the report in the format of the subscription.
+// Reports in CSV format have an attachment for each sheet
+func reportAttachments(file *excelize.File, subscription model.ReportSubscription, now time.Time) ([]emailer.Attachment, error) {
+	filename := fmt.Sprintf("%s_%s", subscription.Report, now.Format("20060102"))
+
+	switch subscription.Format {
+	case model.ReportFormatCSV:
+		sheets, err := exutils.ToCSV(file)
+		if err != nil {
+			return nil, err
+		}
+
+		attachments := make([]emailer.Attachment, 0, len(sheets))
+
+		for _, sheet := range sheets {
+			name := filename + ".csv"
+			if len(sheets) > 1 {
+				name = fmt.Sprintf("%s_%s.csv", filename, strings.ReplaceAll(strings.ToLower(sheet.Name), " ", "_"))
+			}
+
+			attachments = append(attachments, emailer.Attachment{Filename: name, Content: sheet.Content})
+		}
+
+		return attachments, nil
+
+	case model.ReportFormatPDF:
+		return []emailer.Attachment{{Filename: filename + ".pdf", Content: exutils.ToPDF(file, subscription.Name)}}, nil
+
+	default:
+		buf, err := file.WriteToBuffer()
+		if err != nil {
+			return nil, utils.NewError(err, "XLSX")
+		}
+
+		return []emailer.Attachment{{Filename: filename + ".xlsx", Content: buf.Bytes()}}, nil
+	}
+}
+
+// getReportExporter return the exporter of the report, the ones not provided by the api-service
+// are searched in ReportExporters
+func (as *APIService) getReportExporter(report string) (ReportExporter, bool) {
+	globalFilter := func(filters model.ReportFilters) dto.GlobalFilter {
+		return dto.GlobalFilter{
+			Location:    filters.Location,
+			Environment: filters.Environment,
+			OlderThan:   utils.MAX_TIME,
+		}
+	}
+
+	switch report {
+	case model.ReportHosts:
+		return func(filters model.ReportFilters) (*excelize.File, error) {
+			f := dto.NewSearchHostsFilters()
+			f.Location = filters.Location
+			f.Environment = filters.Environment
+
+			return as.SearchHostsAsXLSX(f)
+		}, true
+	case model.ReportUsedLicensesPerHost:
+		return func(filters model.ReportFilters) (*excelize.File, error) {
+			return as.GetUsedLicensesPerHostAsXLSX(globalFilter(filters))
+		}, true
+	case model.ReportUsedLicensesPerCluster:
+		return func(filters model.ReportFilters) (*excelize.File, error) {
+			return as.GetUsedLicensesPerClusterAsXLSX(globalFilter(filters))
+		}, true
+	case model.ReportLicensesCompliance:
+		return func(filters model.ReportFilters) (*excelize.File, error) {
+			return as.GetDatabaseLicensesComplianceAsXLSX(strings.Split(filters.Location, ","))
+		}, true
+	case model.ReportExadata:
+		return func(filters model.ReportFilters) (*excelize.File, error) {
+			return as.GetAllExadataInstanceAsXlsx()
+		}, true
+	case model.ReportOracleDatabasePoliciesAudit:
+		return func(filters model.ReportFilters) (*excelize.File, error) {
+			dbs, err := as.ListOracleDatabasePoliciesAudit()
+			if err != nil {
+				return nil, err
+			}
+
+			pdbs, err := as.ListOracleDatabasePdbPoliciesAudit()
+			if err != nil {
+				return nil, err
+			}
+
+			return as.CreateOraclePoliciesAuditXlsx(dbs, pdbs)
+		}, true
+	}
+
+	exporter, ok := as.ReportExporters[report]
+
+	return exporter, ok
+}
+
+func (as *APIService) validateReportSubscription(subscription model.ReportSubscription) error {
+	raw, err := json.Marshal(subscription)
+	if err != nil {
+		return err
+	}
+
+	if err := schema.ValidateReportSubscription(raw); err != nil {
+		return err
+	}
+
+	if _, err := cron.ParseStandard(subscription.Schedule); err != nil {
+		return utils.NewErrorf("%w: invalid schedule %q: %s", utils.ErrInvalidReportSubscription, subscription.Schedule, err)
+	}
+
+	if _, ok := as.getReportExporter(subscription.Report); !ok {
+		return utils.NewErrorf("%w: report %s isn't available", utils.ErrInvalidReportSubscription, subscription.Report)
+	}
+
+	return nil
+}
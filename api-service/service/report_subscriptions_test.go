@@ -0,0 +1,331 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/alert-service/emailer"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func newReportSubscriptionTestService(mockCtrl *gomock.Controller, emailerEnabled bool) (APIService, *MockMongoDatabaseInterface, *MockEmailer) {
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	em := NewMockEmailer(mockCtrl)
+
+	as := APIService{
+		Database: db,
+		Config: config.Configuration{
+			AlertService: config.AlertService{
+				Emailer: config.Emailer{Enabled: emailerEnabled},
+			},
+		},
+		TimeNow:     utils.Btc(utils.P("2024-03-11T08:00:00Z")),
+		Log:         logger.NewLogger("TEST"),
+		NewObjectID: utils.NewObjectIDForTests(),
+		Emailer:     em,
+		ReportExporters: map[string]ReportExporter{
+			model.ReportAwsRecommendations: func(filters model.ReportFilters) (*excelize.File, error) {
+				file := excelize.NewFile()
+				file.SetSheetName("Sheet1", "Recommendations")
+				file.SetCellValue("Recommendations", "A1", "Category")
+				file.SetCellValue("Recommendations", "A2", "Unused resources")
+
+				return file, nil
+			},
+		},
+	}
+
+	return as, db, em
+}
+
+func TestAddReportSubscription_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db, _ := newReportSubscriptionTestService(mockCtrl, true)
+
+	subscription := model.ReportSubscription{
+		Name:       "Weekly AWS",
+		Report:     model.ReportAwsRecommendations,
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatCSV,
+		Recipients: []string{"cloud@ercole.test"},
+		Enabled:    true,
+	}
+
+	expected := subscription
+	expected.ID = utils.Str2oid("000000000000000000000001")
+	expected.CreatedAt = utils.P("2024-03-11T08:00:00Z")
+
+	db.EXPECT().InsertReportSubscription(expected).Return(nil)
+
+	actual, err := as.AddReportSubscription(subscription)
+	require.NoError(t, err)
+	assert.Equal(t, expected, *actual)
+}
+
+func TestAddReportSubscription_Invalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, _, _ := newReportSubscriptionTestService(mockCtrl, true)
+
+	valid := model.ReportSubscription{
+		Name:       "Daily hosts",
+		Report:     model.ReportHosts,
+		Schedule:   "@daily",
+		Format:     model.ReportFormatXLSX,
+		Recipients: []string{"dba@ercole.test"},
+		Enabled:    true,
+	}
+
+	invalidSchedule := valid
+	invalidSchedule.Schedule = "61 * * * *"
+
+	notAvailable := valid
+	notAvailable.Report = model.ReportOciRecommendations
+
+	invalidFormat := valid
+	invalidFormat.Format = "DOCX"
+
+	noRecipients := valid
+	noRecipients.Recipients = []string{}
+
+	for _, subscription := range []model.ReportSubscription{invalidSchedule, notAvailable, invalidFormat, noRecipients} {
+		_, err := as.AddReportSubscription(subscription)
+		assert.ErrorIs(t, err, utils.ErrInvalidReportSubscription)
+	}
+}
+
+func TestUpdateReportSubscription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db, _ := newReportSubscriptionTestService(mockCtrl, true)
+
+	lastRun := utils.P("2024-03-04T07:00:00Z")
+	old := model.ReportSubscription{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1b2d1"),
+		Name:       "Weekly hosts",
+		Report:     model.ReportHosts,
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatXLSX,
+		Recipients: []string{"dba@ercole.test"},
+		Enabled:    true,
+		CreatedAt:  utils.P("2024-03-01T10:00:00Z"),
+		LastRun:    &lastRun,
+	}
+
+	subscription := old
+	subscription.Format = model.ReportFormatPDF
+	subscription.CreatedAt = time.Time{}
+	subscription.LastRun = nil
+
+	expected := old
+	expected.Format = model.ReportFormatPDF
+
+	db.EXPECT().GetReportSubscription(old.ID).Return(&old, nil)
+	db.EXPECT().UpdateReportSubscription(expected).Return(nil)
+
+	actual, err := as.UpdateReportSubscription(subscription)
+	require.NoError(t, err)
+	assert.Equal(t, expected, *actual)
+}
+
+func TestReportSubscriptionIsDue(t *testing.T) {
+	lastRun := utils.P("2024-03-04T07:00:00Z")
+
+	testCases := []struct {
+		schedule string
+		lastRun  *time.Time
+		now      time.Time
+		expected bool
+	}{
+		{"0 7 * * 1", nil, utils.P("2024-03-04T06:59:00Z"), false},
+		{"0 7 * * 1", nil, utils.P("2024-03-04T07:00:00Z"), true},
+		{"0 7 * * 1", &lastRun, utils.P("2024-03-04T07:01:00Z"), false},
+		{"0 7 * * 1", &lastRun, utils.P("2024-03-11T07:00:00Z"), true},
+		{"@daily", &lastRun, utils.P("2024-03-05T00:00:00Z"), true},
+		{"not a crontab", nil, utils.P("2024-03-11T07:00:00Z"), false},
+	}
+
+	for _, tc := range testCases {
+		subscription := model.ReportSubscription{
+			Schedule:  tc.schedule,
+			CreatedAt: utils.P("2024-03-01T10:00:00Z"),
+			LastRun:   tc.lastRun,
+		}
+
+		assert.Equal(t, tc.expected, reportSubscriptionIsDue(subscription, tc.now.UTC()), "%s %v", tc.schedule, tc.now)
+	}
+}
+
+func TestRunReportSubscription_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db, em := newReportSubscriptionTestService(mockCtrl, true)
+
+	subscription := model.ReportSubscription{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1b2d1"),
+		Name:       "Weekly AWS",
+		Report:     model.ReportAwsRecommendations,
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatCSV,
+		Recipients: []string{"cloud@ercole.test"},
+		Enabled:    false,
+	}
+
+	expected := model.ReportRun{
+		ID:             utils.Str2oid("000000000000000000000001"),
+		SubscriptionID: subscription.ID,
+		Date:           utils.P("2024-03-11T08:00:00Z"),
+		Trigger:        model.ReportRunTriggerManual,
+		Success:        true,
+		Recipients:     []string{"cloud@ercole.test"},
+		Attachments:    []string{"aws-recommendations_20240311.csv"},
+	}
+
+	gomock.InOrder(
+		db.EXPECT().GetReportSubscription(subscription.ID).Return(&subscription, nil),
+		em.EXPECT().SendEmailWithAttachments("Ercole report Weekly AWS - 11/03/2024", "Please see the attached aws-recommendations report.",
+			[]string{"cloud@ercole.test"},
+			[]emailer.Attachment{{Filename: "aws-recommendations_20240311.csv", Content: []byte("Category\nUnused resources\n")}}).
+			Return(nil),
+		db.EXPECT().InsertReportRun(expected).Return(nil),
+		db.EXPECT().UpdateReportSubscriptionLastRun(subscription.ID, utils.P("2024-03-11T08:00:00Z")).Return(nil),
+	)
+
+	actual, err := as.RunReportSubscription(subscription.ID)
+	require.NoError(t, err)
+	assert.Equal(t, expected, *actual)
+}
+
+func TestRunReportSubscription_EmailerDisabled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db, _ := newReportSubscriptionTestService(mockCtrl, false)
+
+	subscription := model.ReportSubscription{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1b2d1"),
+		Name:       "Weekly AWS",
+		Report:     model.ReportAwsRecommendations,
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatXLSX,
+		Recipients: []string{"cloud@ercole.test"},
+		Enabled:    true,
+	}
+
+	db.EXPECT().GetReportSubscription(subscription.ID).Return(&subscription, nil)
+	db.EXPECT().InsertReportRun(gomock.Any()).Return(nil)
+	db.EXPECT().UpdateReportSubscriptionLastRun(subscription.ID, utils.P("2024-03-11T08:00:00Z")).Return(nil)
+
+	actual, err := as.RunReportSubscription(subscription.ID)
+	require.NoError(t, err)
+	assert.False(t, actual.Success)
+	assert.Equal(t, utils.ErrEmailerDisabled.Error(), actual.Error)
+	assert.Empty(t, actual.Attachments)
+}
+
+func TestRunReportSubscription_NotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db, _ := newReportSubscriptionTestService(mockCtrl, true)
+
+	id := utils.Str2oid("654a1d2f3b8e7c0001a1b2d1")
+	db.EXPECT().GetReportSubscription(id).Return(nil, utils.ErrReportSubscriptionNotFound)
+
+	_, err := as.RunReportSubscription(id)
+	assert.ErrorIs(t, err, utils.ErrReportSubscriptionNotFound)
+}
+
+func TestRunDueReportSubscriptions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db, em := newReportSubscriptionTestService(mockCtrl, true)
+
+	lastRun := utils.P("2024-03-11T07:00:00Z")
+	due := model.ReportSubscription{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1b2d1"),
+		Name:       "Weekly AWS",
+		Report:     model.ReportAwsRecommendations,
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatPDF,
+		Recipients: []string{"cloud@ercole.test"},
+		Enabled:    true,
+		CreatedAt:  utils.P("2024-03-01T10:00:00Z"),
+	}
+	alreadyRun := due
+	alreadyRun.ID = utils.Str2oid("654a1d2f3b8e7c0001a1b2d2")
+	alreadyRun.LastRun = &lastRun
+
+	disabled := due
+	disabled.ID = utils.Str2oid("654a1d2f3b8e7c0001a1b2d3")
+	disabled.Enabled = false
+
+	db.EXPECT().ListReportSubscriptions().Return([]model.ReportSubscription{due, alreadyRun, disabled}, nil)
+	em.EXPECT().SendEmailWithAttachments(gomock.Any(), gomock.Any(), []string{"cloud@ercole.test"}, gomock.Any()).Return(nil)
+	db.EXPECT().InsertReportRun(gomock.Any()).
+		Do(func(run model.ReportRun) {
+			assert.Equal(t, due.ID, run.SubscriptionID)
+			assert.Equal(t, model.ReportRunTriggerSchedule, run.Trigger)
+			assert.Equal(t, []string{"aws-recommendations_20240311.pdf"}, run.Attachments)
+		}).
+		Return(nil)
+	db.EXPECT().UpdateReportSubscriptionLastRun(due.ID, utils.P("2024-03-11T08:00:00Z")).Return(nil)
+
+	require.NoError(t, as.RunDueReportSubscriptions())
+}
+
+func TestRunDueReportSubscriptions_Failure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as, db, em := newReportSubscriptionTestService(mockCtrl, true)
+
+	failing := model.ReportSubscription{
+		ID:         utils.Str2oid("654a1d2f3b8e7c0001a1b2d1"),
+		Name:       "Weekly AWS",
+		Report:     model.ReportAwsRecommendations,
+		Schedule:   "0 7 * * 1",
+		Format:     model.ReportFormatPDF,
+		Recipients: []string{"cloud@ercole.test"},
+		Enabled:    true,
+		CreatedAt:  utils.P("2024-03-01T10:00:00Z"),
+	}
+	next := failing
+	next.ID = utils.Str2oid("654a1d2f3b8e7c0001a1b2d2")
+	next.Name = "Weekly AWS bis"
+
+	db.EXPECT().ListReportSubscriptions().Return([]model.ReportSubscription{failing, next}, nil)
+	em.EXPECT().SendEmailWithAttachments(gomock.Any(), gomock.Any(), []string{"cloud@ercole.test"}, gomock.Any()).Return(nil).Times(2)
+	gomock.InOrder(
+		db.EXPECT().InsertReportRun(gomock.Any()).Return(aerrMock),
+		db.EXPECT().InsertReportRun(gomock.Any()).
+			Do(func(run model.ReportRun) {
+				assert.Equal(t, next.ID, run.SubscriptionID)
+			}).
+			Return(nil),
+		db.EXPECT().UpdateReportSubscriptionLastRun(next.ID, utils.P("2024-03-11T08:00:00Z")).Return(nil),
+	)
+
+	require.NoError(t, as.RunDueReportSubscriptions())
+}
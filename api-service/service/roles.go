@@ -18,9 +18,11 @@ package service
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/schema"
+	"github.com/ercole-io/ercole/v2/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -82,3 +84,67 @@ func (as *APIService) UpdateRole(role model.Role) error {
 func (as *APIService) RemoveRole(roleName string) error {
 	return as.Database.RemoveRole(roleName)
 }
+
+// GetUserPermission returns the effective permission of the user on the location.
+// A comma separated list of locations requires the permission on each of them,
+// while an empty location is satisfied by a role on any location.
+func (as *APIService) GetUserPermission(user interface{}, location string) (string, error) {
+	u := user.(model.User)
+
+	if u.IsAdmin() {
+		return model.AdminPermission, nil
+	}
+
+	roles, err := as.Database.GetUserRoles(u.Username)
+	if err != nil {
+		return "", err
+	}
+
+	locations := make([]string, 0)
+
+	for _, l := range strings.Split(location, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			locations = append(locations, l)
+		}
+	}
+
+	for _, permission := range []string{model.AdminPermission, model.WritePermission} {
+		if hasPermission(roles, permission, locations) {
+			return permission, nil
+		}
+	}
+
+	return model.ReadPermission, nil
+}
+
+func hasPermission(roles []model.Role, permission string, locations []string) bool {
+	granted := make([]string, 0)
+
+	for _, role := range roles {
+		// the admin permission includes the write one
+		if role.Permission != permission && role.Permission != model.AdminPermission {
+			continue
+		}
+
+		granted = append(granted, role.Locations...)
+		if role.Location != "" {
+			granted = append(granted, role.Location)
+		}
+	}
+
+	if len(granted) == 0 {
+		return false
+	}
+
+	if utils.ContainsI(granted, model.AllLocation) || len(locations) == 0 {
+		return true
+	}
+
+	for _, l := range locations {
+		if !utils.ContainsI(granted, l) {
+			return false
+		}
+	}
+
+	return true
+}
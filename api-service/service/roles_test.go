@@ -166,3 +166,120 @@ func TestUpdateRole(t *testing.T) {
 		require.EqualError(t, err, "Invalid location")
 	})
 }
+
+func TestGetUserPermission(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+	}
+
+	user := model.User{Username: "user01", Groups: []string{"group01"}}
+	roles := []model.Role{
+		{
+			Name:       "read_all",
+			Permission: model.ReadPermission,
+			Locations:  model.AllLocations,
+		},
+		{
+			Name:       "write_italy",
+			Permission: model.WritePermission,
+			Locations:  []string{"Italy"},
+		},
+		{
+			Name:       "write_germany",
+			Permission: model.WritePermission,
+			Location:   "Germany",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		roles    []model.Role
+		location string
+		expected string
+	}{
+		{
+			name:     "write on a granted location",
+			roles:    roles,
+			location: "italy",
+			expected: model.WritePermission,
+		},
+		{
+			name:     "write on every requested location",
+			roles:    roles,
+			location: "Italy,Germany",
+			expected: model.WritePermission,
+		},
+		{
+			name:     "read on a location without write roles",
+			roles:    roles,
+			location: "Italy,France",
+			expected: model.ReadPermission,
+		},
+		{
+			name:     "write without a requested location",
+			roles:    roles,
+			location: "",
+			expected: model.WritePermission,
+		},
+		{
+			name:     "read on all locations",
+			roles:    roles,
+			location: model.AllLocation,
+			expected: model.ReadPermission,
+		},
+		{
+			name: "admin role",
+			roles: []model.Role{
+				{Name: "admin", Permission: model.AdminPermission, Locations: model.AllLocations},
+			},
+			location: "France",
+			expected: model.AdminPermission,
+		},
+		{
+			name: "write on all locations",
+			roles: []model.Role{
+				{Name: "write_all", Permission: model.WritePermission, Locations: model.AllLocations},
+			},
+			location: "France",
+			expected: model.WritePermission,
+		},
+		{
+			name:     "read without roles",
+			roles:    []model.Role{},
+			location: "",
+			expected: model.ReadPermission,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db.EXPECT().GetUserRoles("user01").Return(tc.roles, nil).Times(1)
+
+			actual, err := as.GetUserPermission(user, tc.location)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("admin user", func(t *testing.T) {
+		admin := model.User{Username: "admin", Groups: []string{model.GroupAdmin}}
+
+		actual, err := as.GetUserPermission(admin, "Italy")
+		require.NoError(t, err)
+
+		assert.Equal(t, model.AdminPermission, actual)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db.EXPECT().GetUserRoles("user01").Return(nil, errMock).Times(1)
+
+		actual, err := as.GetUserPermission(user, "Italy")
+		require.EqualError(t, err, "MockError")
+
+		assert.Empty(t, actual)
+	})
+}
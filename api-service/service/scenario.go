@@ -20,22 +20,100 @@ import (
 
 	"github.com/ercole-io/ercole/v2/api-service/dto"
 	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type scenarioLicenses struct {
+	compliance     []model.LicenseCompliance
+	database       []model.LicenseUsedDatabase
+	host           []model.LicenseUsedHost
+	cluster        []model.LicenseUsedCluster
+	clusterVeritas []model.LicenseUsedClusterVeritas
+}
+
+// CreateScenario evaluates the licenses with the changes of the request applied on top of the live data.
+// Only the resulting scenario is saved, hostdata are never modified
 func (as *APIService) CreateScenario(req dto.CreateScenarioRequest) (*model.Scenario, error) {
+	scenario, err := as.newScenario(req)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := as.getScenarioLicenses(req.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	scenarioDB, err := newScenarioDatabase(as.Database, scenario)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := *as
+	simulation.Database = scenarioDB
+
+	got, err := simulation.getScenarioLicenses(req.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario.LicenseCompliance.Actual = actual.compliance
+	scenario.LicenseCompliance.Got = got.compliance
+	scenario.LicenseUsed.LicenseDatabase.Actual = actual.database
+	scenario.LicenseUsed.LicenseDatabase.Got = got.database
+	scenario.LicenseUsed.LicenseHost.Actual = actual.host
+	scenario.LicenseUsed.LicenseHost.Got = got.host
+	scenario.LicenseUsed.LicenseHypervisorCluster.Actual = actual.cluster
+	scenario.LicenseUsed.LicenseHypervisorCluster.Got = got.cluster
+	scenario.LicenseUsed.LicenseClusterVeritas.Actual = actual.clusterVeritas
+	scenario.LicenseUsed.LicenseClusterVeritas.Got = got.clusterVeritas
+
+	return as.Database.CreateScenario(scenario)
+}
+
+func (as *APIService) newScenario(req dto.CreateScenarioRequest) (*model.Scenario, error) {
 	scenario := &model.Scenario{
-		ID:        primitive.NewObjectID(),
-		Name:      req.Name,
-		CreatedAt: time.Now(),
-		Location:  req.Location,
+		ID:                 primitive.NewObjectID(),
+		Name:               req.Name,
+		CreatedAt:          time.Now(),
+		Location:           req.Location,
+		Hosts:              make([]model.SimulatedHost, 0, len(req.Hosts)),
+		HypotheticalHosts:  make([]model.HypotheticalHost, 0, len(req.AddHosts)),
+		RemovedHosts:       make([]string, 0, len(req.RemoveHosts)),
+		MovedHosts:         make([]model.MovedHost, 0, len(req.MoveHosts)),
+		LicenseTypeChanges: make([]model.LicenseTypeChange, 0, len(req.LicenseTypeChanges)),
 	}
 
-	filter := dto.GlobalFilter{
-		Location: req.Location,
+	removed := make(map[string]bool, len(req.RemoveHosts))
+
+	for _, hostname := range req.RemoveHosts {
+		exist, err := as.Database.ExistHostdata(hostname)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exist {
+			return nil, utils.NewErrorf("%w: %s", utils.ErrHostNotFound, hostname)
+		}
+
+		removed[hostname] = true
+		scenario.RemovedHosts = append(scenario.RemovedHosts, hostname)
+	}
+
+	isRemoved := func(hostname string) error {
+		if removed[hostname] {
+			return utils.NewErrorf("%w: host %s is removed by the scenario", utils.ErrInvalidScenario, hostname)
+		}
+
+		return nil
 	}
 
 	for _, h := range req.Hosts {
+		if err := isRemoved(h.Hostname); err != nil {
+			return nil, err
+		}
+
 		host, err := as.Database.FindHostData(h.Hostname)
 		if err != nil {
 			return nil, err
@@ -49,104 +127,184 @@ func (as *APIService) CreateScenario(req dto.CreateScenarioRequest) (*model.Scen
 		})
 	}
 
-	actualCompliance, err := as.getLicenseCompliance(req.Location)
-	if err != nil {
-		return nil, err
-	}
+	var clusters []dto.Cluster
 
-	scenario.LicenseCompliance.Actual = actualCompliance
+	if len(req.AddHosts) > 0 || len(req.MoveHosts) > 0 {
+		var err error
 
-	actualUsedPerDatabase, err := as.getLicenseUsedPerDatabase("", filter)
-	if err != nil {
-		return nil, err
+		clusters, err = as.Database.GetClusters(dto.GlobalFilter{OlderThan: utils.MAX_TIME})
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	scenario.LicenseUsed.LicenseDatabase.Actual = actualUsedPerDatabase
+	clusterExists := func(name string) error {
+		for _, cluster := range clusters {
+			if cluster.Name == name {
+				return nil
+			}
+		}
 
-	actualUsedPerHost, err := as.getLicenseUsedPerHost(filter)
-	if err != nil {
-		return nil, err
+		return utils.NewErrorf("%w: %s", utils.ErrClusterNotFound, name)
 	}
 
-	scenario.LicenseUsed.LicenseHost.Actual = actualUsedPerHost
+	for _, h := range req.AddHosts {
+		if h.Hostname == "" {
+			return nil, utils.NewErrorf("%w: hypothetical host without hostname", utils.ErrInvalidScenario)
+		}
 
-	actualUsedCluster, err := as.getLicenseUsedPerCluster(filter)
-	if err != nil {
-		return nil, err
-	}
+		exist, err := as.Database.ExistHostdata(h.Hostname)
+		if err != nil {
+			return nil, err
+		}
 
-	scenario.LicenseUsed.LicenseHypervisorCluster.Actual = actualUsedCluster
+		for _, added := range scenario.HypotheticalHosts {
+			exist = exist || added.Host.Hostname == h.Hostname
+		}
 
-	actualUsedClusterVeritas, err := as.getLicenseUsedPerClusterVeritas(filter)
-	if err != nil {
-		return nil, err
-	}
+		if exist {
+			return nil, utils.NewErrorf("%w: host %s already exists", utils.ErrInvalidScenario, h.Hostname)
+		}
 
-	scenario.LicenseUsed.LicenseClusterVeritas.Actual = actualUsedClusterVeritas
+		if h.Cluster != "" {
+			if err := clusterExists(h.Cluster); err != nil {
+				return nil, err
+			}
+		}
 
-	err = as.Database.CreateSimulatedHosts(scenario.Hosts...)
-	if err != nil {
-		return nil, err
+		scenario.HypotheticalHosts = append(scenario.HypotheticalHosts, model.HypotheticalHost{
+			Host:    newHypotheticalHostData(h, req.Location),
+			Cluster: h.Cluster,
+		})
 	}
 
-	for _, simulatedHost := range scenario.Hosts {
-		if err := as.UpdateHostLicenseCount(simulatedHost.Host.Hostname, simulatedHost.Core); err != nil {
+	for _, h := range req.MoveHosts {
+		if err := isRemoved(h.Hostname); err != nil {
+			return nil, err
+		}
+
+		if err := clusterExists(h.Cluster); err != nil {
 			return nil, err
 		}
+
+		exist, err := as.Database.ExistHostdata(h.Hostname)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exist {
+			return nil, utils.NewErrorf("%w: %s", utils.ErrHostNotFound, h.Hostname)
+		}
+
+		moved := model.MovedHost{
+			Hostname:  h.Hostname,
+			ToCluster: h.Cluster,
+		}
+
+		for _, cluster := range clusters {
+			for _, vm := range cluster.VMs {
+				if vm.Hostname == h.Hostname {
+					moved.FromCluster = cluster.Name
+				}
+			}
+		}
+
+		scenario.MovedHosts = append(scenario.MovedHosts, moved)
 	}
 
-	gotCompliance, err := as.getLicenseCompliance(req.Location)
-	if err != nil {
-		return nil, err
+	for _, c := range req.LicenseTypeChanges {
+		if err := isRemoved(c.Hostname); err != nil {
+			return nil, err
+		}
+
+		if c.FromLicenseTypeID == "" || c.ToLicenseTypeID == "" {
+			return nil, utils.NewErrorf("%w: license type change of %s without license types", utils.ErrInvalidScenario, c.Hostname)
+		}
+
+		scenario.LicenseTypeChanges = append(scenario.LicenseTypeChanges, model.LicenseTypeChange{
+			Hostname:          c.Hostname,
+			DbName:            c.DbName,
+			FromLicenseTypeID: c.FromLicenseTypeID,
+			ToLicenseTypeID:   c.ToLicenseTypeID,
+		})
 	}
 
-	scenario.LicenseCompliance.Got = gotCompliance
+	return scenario, nil
+}
 
-	gotUsedPerDatabase, err := as.getLicenseUsedPerDatabase("", filter)
-	if err != nil {
-		return nil, err
+func newHypotheticalHostData(req dto.AddHostScenarioRequest, location string) model.HostDataBE {
+	if req.Location != "" {
+		location = req.Location
 	}
 
-	scenario.LicenseUsed.LicenseDatabase.Got = gotUsedPerDatabase
+	databases := make([]model.OracleDatabase, 0, len(req.Databases))
 
-	gotUsedPerHost, err := as.getLicenseUsedPerHost(filter)
-	if err != nil {
-		return nil, err
+	for _, db := range req.Databases {
+		licenses := make([]model.OracleDatabaseLicense, 0, len(db.LicenseTypeIDs))
+		for _, licenseTypeID := range db.LicenseTypeIDs {
+			licenses = append(licenses, model.OracleDatabaseLicense{
+				LicenseTypeID: licenseTypeID,
+				Count:         scenarioLicenseCount(req.Core),
+			})
+		}
+
+		databases = append(databases, model.OracleDatabase{
+			Name:     db.Name,
+			Version:  db.Version,
+			Licenses: licenses,
+		})
 	}
 
-	scenario.LicenseUsed.LicenseHost.Got = gotUsedPerHost
+	return model.HostDataBE{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   time.Now(),
+		Hostname:    req.Hostname,
+		Location:    location,
+		Environment: req.Environment,
+		Info: model.Host{
+			Hostname: req.Hostname,
+			CPUCores: req.Core,
+		},
+		Features: model.Features{
+			Oracle: &model.OracleFeature{
+				Database: &model.OracleDatabaseFeature{
+					Databases: databases,
+				},
+			},
+		},
+	}
+}
 
-	gotUsedCluster, err := as.getLicenseUsedPerCluster(filter)
-	if err != nil {
-		return nil, err
+func (as *APIService) getScenarioLicenses(location string) (*scenarioLicenses, error) {
+	filter := dto.GlobalFilter{
+		Location: location,
 	}
 
-	scenario.LicenseUsed.LicenseHypervisorCluster.Got = gotUsedCluster
+	var res scenarioLicenses
 
-	gotUsedClusterVeritas, err := as.getLicenseUsedPerClusterVeritas(filter)
-	if err != nil {
+	var err error
+
+	if res.compliance, err = as.getLicenseCompliance(location); err != nil {
 		return nil, err
 	}
 
-	scenario.LicenseUsed.LicenseClusterVeritas.Got = gotUsedClusterVeritas
+	if res.database, err = as.getLicenseUsedPerDatabase("", filter); err != nil {
+		return nil, err
+	}
 
-	res, err := as.Database.CreateScenario(scenario)
-	if err != nil {
+	if res.host, err = as.getLicenseUsedPerHost(filter); err != nil {
 		return nil, err
 	}
 
-	for _, simulatedHost := range scenario.Hosts {
-		if err := as.UpdateHostLicenseCount(simulatedHost.Host.Hostname, simulatedHost.Host.Info.CPUCores); err != nil {
-			return nil, err
-		}
+	if res.cluster, err = as.getLicenseUsedPerCluster(filter); err != nil {
+		return nil, err
+	}
 
-		err = as.Database.RemoveSimulatedHost(simulatedHost.ID)
-		if err != nil {
-			return nil, err
-		}
+	if res.clusterVeritas, err = as.getLicenseUsedPerClusterVeritas(filter); err != nil {
+		return nil, err
 	}
 
-	return res, nil
+	return &res, nil
 }
 
 func (as *APIService) getLicenseCompliance(location string) ([]model.LicenseCompliance, error) {
@@ -232,19 +390,3 @@ func (as *APIService) GetScenario(id primitive.ObjectID) (*model.Scenario, error
 func (as *APIService) RemoveScenario(id primitive.ObjectID) error {
 	return as.Database.RemoveScenario(id)
 }
-
-func (as *APIService) UpdateHostLicenseCount(hostname string, core int) error {
-	licenseCount := core / 2
-
-	err := as.Database.UpdateHostCores(hostname, core)
-	if err != nil {
-		return err
-	}
-
-	err = as.Database.UpdateLicenseCount(hostname, licenseCount)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
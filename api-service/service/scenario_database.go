@@ -0,0 +1,563 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/api-service/database"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// scenarioDatabase is a read only view of the live database with the changes of a scenario
+// applied on top. Licenses are calculated against it, so simulated values never reach the hosts collection
+type scenarioDatabase struct {
+	database.MongoDatabaseInterface
+
+	// hosts contains the simulated hostdata, by hostname
+	hosts map[string]model.HostDataBE
+	// removed contains the hostnames removed by the scenario
+	removed map[string]bool
+	// clusters contains the cluster of moved and hypothetical hosts, by hostname
+	clusters map[string]string
+	// vms contains the vm of moved and hypothetical hosts, by hostname
+	vms map[string]dto.VM
+
+	licenseTypes map[string]model.OracleDatabaseLicenseType
+}
+
+func newScenarioDatabase(live database.MongoDatabaseInterface, scenario *model.Scenario) (*scenarioDatabase, error) {
+	db := &scenarioDatabase{
+		MongoDatabaseInterface: live,
+		hosts:                  make(map[string]model.HostDataBE),
+		removed:                make(map[string]bool),
+		clusters:               make(map[string]string),
+		vms:                    make(map[string]dto.VM),
+		licenseTypes:           make(map[string]model.OracleDatabaseLicenseType),
+	}
+
+	licenseTypes, err := live.GetOracleDatabaseLicenseTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lt := range licenseTypes {
+		db.licenseTypes[lt.ID] = lt
+	}
+
+	for _, sh := range scenario.Hosts {
+		hd, ok := db.hosts[sh.Host.Hostname]
+		if !ok {
+			hd = cloneHostData(sh.Host)
+		}
+
+		setHostCores(&hd, sh.Core)
+		db.hosts[hd.Hostname] = hd
+	}
+
+	for _, h := range scenario.HypotheticalHosts {
+		db.hosts[h.Host.Hostname] = cloneHostData(h.Host)
+
+		if h.Cluster != "" {
+			db.clusters[h.Host.Hostname] = h.Cluster
+			db.vms[h.Host.Hostname] = dto.VM{
+				Hostname:          h.Host.Hostname,
+				Name:              h.Host.Hostname,
+				IsErcoleInstalled: true,
+			}
+		}
+	}
+
+	for _, hostname := range scenario.RemovedHosts {
+		db.removed[hostname] = true
+	}
+
+	if len(scenario.MovedHosts) > 0 {
+		liveClusters, err := live.GetClusters(dto.GlobalFilter{OlderThan: utils.MAX_TIME})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, moved := range scenario.MovedHosts {
+			vm := dto.VM{
+				Hostname:          moved.Hostname,
+				Name:              moved.Hostname,
+				IsErcoleInstalled: true,
+			}
+
+			for _, cluster := range liveClusters {
+				for _, clusterVM := range cluster.VMs {
+					if cluster.Name == moved.FromCluster && clusterVM.Hostname == moved.Hostname {
+						vm = clusterVM
+					}
+				}
+			}
+
+			db.clusters[moved.Hostname] = moved.ToCluster
+			db.vms[moved.Hostname] = vm
+		}
+	}
+
+	for _, change := range scenario.LicenseTypeChanges {
+		if _, ok := db.licenseTypes[change.ToLicenseTypeID]; !ok {
+			return nil, utils.NewErrorf("%w: %s", utils.ErrOracleDatabaseLicenseTypeIDNotFound, change.ToLicenseTypeID)
+		}
+
+		hd, ok := db.hosts[change.Hostname]
+		if !ok {
+			hostdata, err := live.FindHostData(change.Hostname)
+			if err != nil {
+				return nil, err
+			}
+
+			hd = cloneHostData(hostdata)
+		}
+
+		if !changeLicenseType(&hd, change) {
+			return nil, utils.NewErrorf("%w: %s on %s", utils.ErrLicenseNotFound, change.FromLicenseTypeID, change.Hostname)
+		}
+
+		db.hosts[hd.Hostname] = hd
+	}
+
+	return db, nil
+}
+
+// cloneHostData copies the parts of the hostdata changed by a scenario
+func cloneHostData(hd model.HostDataBE) model.HostDataBE {
+	if hd.Features.Oracle == nil || hd.Features.Oracle.Database == nil {
+		return hd
+	}
+
+	oracle := *hd.Features.Oracle
+	oracleDatabase := *oracle.Database
+	oracleDatabase.Databases = make([]model.OracleDatabase, len(hd.Features.Oracle.Database.Databases))
+
+	for i, db := range hd.Features.Oracle.Database.Databases {
+		db.Licenses = append([]model.OracleDatabaseLicense(nil), db.Licenses...)
+		oracleDatabase.Databases[i] = db
+	}
+
+	oracle.Database = &oracleDatabase
+	hd.Features.Oracle = &oracle
+
+	return hd
+}
+
+// scenarioLicenseCount returns the licenses used by a database running on a host with these cores
+func scenarioLicenseCount(cores int) float64 {
+	return float64(cores / 2)
+}
+
+func setHostCores(hd *model.HostDataBE, cores int) {
+	hd.Info.CPUCores = cores
+
+	if hd.Features.Oracle == nil || hd.Features.Oracle.Database == nil {
+		return
+	}
+
+	for i := range hd.Features.Oracle.Database.Databases {
+		licenses := hd.Features.Oracle.Database.Databases[i].Licenses
+		for j := range licenses {
+			if licenses[j].Count > 0 {
+				licenses[j].Count = scenarioLicenseCount(cores)
+			}
+		}
+	}
+}
+
+func changeLicenseType(hd *model.HostDataBE, change model.LicenseTypeChange) bool {
+	if hd.Features.Oracle == nil || hd.Features.Oracle.Database == nil {
+		return false
+	}
+
+	changed := false
+
+	for i := range hd.Features.Oracle.Database.Databases {
+		db := &hd.Features.Oracle.Database.Databases[i]
+		if change.DbName != "" && db.Name != change.DbName {
+			continue
+		}
+
+		for j := range db.Licenses {
+			if db.Licenses[j].LicenseTypeID == change.FromLicenseTypeID {
+				db.Licenses[j].LicenseTypeID = change.ToLicenseTypeID
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+func (db *scenarioDatabase) isSimulated(hostname string) bool {
+	_, ok := db.hosts[hostname]
+
+	return ok || db.removed[hostname]
+}
+
+func (db *scenarioDatabase) simulatedHostnames() []string {
+	hostnames := make([]string, 0, len(db.hosts))
+	for hostname := range db.hosts {
+		hostnames = append(hostnames, hostname)
+	}
+
+	sort.Strings(hostnames)
+
+	return hostnames
+}
+
+func matchLocationAndEnvironment(hd model.HostDataBE, location, environment string) bool {
+	if location != "" && location != model.AllLocation && !utils.Contains(strings.Split(location, ","), hd.Location) {
+		return false
+	}
+
+	return environment == "" || hd.Environment == environment
+}
+
+func (db *scenarioDatabase) GetHostDatas(filter dto.GlobalFilter) ([]model.HostDataBE, error) {
+	hostdatas, err := db.MongoDatabaseInterface.GetHostDatas(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]model.HostDataBE, 0, len(hostdatas)+len(db.hosts))
+
+	for _, hd := range hostdatas {
+		if !db.isSimulated(hd.Hostname) {
+			res = append(res, hd)
+		}
+	}
+
+	for _, hostname := range db.simulatedHostnames() {
+		hd := db.hosts[hostname]
+		if matchLocationAndEnvironment(hd, filter.Location, filter.Environment) {
+			res = append(res, hd)
+		}
+	}
+
+	return res, nil
+}
+
+func (db *scenarioDatabase) FindHostData(hostname string) (model.HostDataBE, error) {
+	if db.removed[hostname] {
+		return model.HostDataBE{}, utils.ErrHostNotFound
+	}
+
+	if hd, ok := db.hosts[hostname]; ok {
+		return hd, nil
+	}
+
+	return db.MongoDatabaseInterface.FindHostData(hostname)
+}
+
+func (db *scenarioDatabase) GetHost(hostname string, olderThan time.Time, raw bool) (*dto.HostData, error) {
+	if db.removed[hostname] {
+		return nil, utils.ErrHostNotFound
+	}
+
+	hd, ok := db.hosts[hostname]
+	if !ok {
+		return db.MongoDatabaseInterface.GetHost(hostname, olderThan, raw)
+	}
+
+	return &dto.HostData{
+		ID:                      hd.ID,
+		Archived:                hd.Archived,
+		CreatedAt:               hd.CreatedAt,
+		ServerVersion:           hd.ServerVersion,
+		ServerSchemaVersion:     hd.ServerSchemaVersion,
+		Hostname:                hd.Hostname,
+		Location:                hd.Location,
+		Environment:             hd.Environment,
+		AgentVersion:            hd.AgentVersion,
+		Cluster:                 db.clusters[hostname],
+		VirtualizationNode:      db.vms[hostname].VirtualizationNode,
+		Tags:                    hd.Tags,
+		Info:                    hd.Info,
+		ClusterMembershipStatus: hd.ClusterMembershipStatus,
+		Features:                hd.Features,
+		Filesystems:             hd.Filesystems,
+		Clusters:                hd.Clusters,
+		Cloud:                   hd.Cloud,
+		Errors:                  hd.Errors,
+	}, nil
+}
+
+func (db *scenarioDatabase) ExistHostdata(hostname string) (bool, error) {
+	if db.removed[hostname] {
+		return false, nil
+	}
+
+	if _, ok := db.hosts[hostname]; ok {
+		return true, nil
+	}
+
+	return db.MongoDatabaseInterface.ExistHostdata(hostname)
+}
+
+func (db *scenarioDatabase) ExistHostdataBatch(hostnames []string) ([]string, error) {
+	liveHostnames := make([]string, 0, len(hostnames))
+
+	for _, hostname := range hostnames {
+		if !db.isSimulated(hostname) {
+			liveHostnames = append(liveHostnames, hostname)
+		}
+	}
+
+	existingLive, err := db.MongoDatabaseInterface.ExistHostdataBatch(liveHostnames)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make([]string, 0, len(hostnames))
+
+	for _, hostname := range hostnames {
+		if _, ok := db.hosts[hostname]; ok || utils.Contains(existingLive, hostname) {
+			existing = append(existing, hostname)
+		}
+	}
+
+	return existing, nil
+}
+
+func (db *scenarioDatabase) GetCpuCore(hostname string) (int, error) {
+	if db.removed[hostname] {
+		return 0, utils.ErrHostNotFound
+	}
+
+	if hd, ok := db.hosts[hostname]; ok {
+		return hd.Info.CPUCores, nil
+	}
+
+	return db.MongoDatabaseInterface.GetCpuCore(hostname)
+}
+
+func (db *scenarioDatabase) SearchOracleDatabaseUsedLicenses(hostname string, sortBy string, sortDesc bool, page int, pageSize int,
+	location string, environment string, olderThan time.Time,
+) (*dto.OracleDatabaseUsedLicenseSearchResponse, error) {
+	live, err := db.MongoDatabaseInterface.SearchOracleDatabaseUsedLicenses(hostname, sortBy, sortDesc, page, pageSize, location, environment, olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]dto.OracleDatabaseUsedLicense, 0, len(live.Content))
+
+	for _, usedLicense := range live.Content {
+		if !db.isSimulated(usedLicense.Hostname) {
+			content = append(content, usedLicense)
+		}
+	}
+
+	for _, simulatedHostname := range db.simulatedHostnames() {
+		hd := db.hosts[simulatedHostname]
+		if (hostname != "" && hostname != simulatedHostname) ||
+			!matchLocationAndEnvironment(hd, location, environment) ||
+			hd.Features.Oracle == nil || hd.Features.Oracle.Database == nil {
+			continue
+		}
+
+		for _, oracleDatabase := range hd.Features.Oracle.Database.Databases {
+			for _, license := range oracleDatabase.Licenses {
+				if license.Count <= 0 {
+					continue
+				}
+
+				usedLicenses := license.Count
+				if db.licenseTypes[license.LicenseTypeID].Metric == model.LicenseTypeMetricComputerPerpetual {
+					usedLicenses = 1
+				}
+
+				content = append(content, dto.OracleDatabaseUsedLicense{
+					LicenseTypeID:  license.LicenseTypeID,
+					DbName:         oracleDatabase.Name,
+					Hostname:       hd.Hostname,
+					UsedLicenses:   usedLicenses,
+					Ignored:        license.Ignored,
+					IgnoredComment: license.IgnoredComment,
+				})
+			}
+		}
+	}
+
+	return &dto.OracleDatabaseUsedLicenseSearchResponse{
+		Content:  content,
+		Metadata: live.Metadata,
+	}, nil
+}
+
+func (db *scenarioDatabase) GetMySQLUsedLicenses(hostname string, filter dto.GlobalFilter) ([]dto.MySQLUsedLicense, error) {
+	live, err := db.MongoDatabaseInterface.GetMySQLUsedLicenses(hostname, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.MySQLUsedLicense, 0, len(live))
+
+	for _, usedLicense := range live {
+		if !db.removed[usedLicense.Hostname] {
+			res = append(res, usedLicense)
+		}
+	}
+
+	return res, nil
+}
+
+func (db *scenarioDatabase) SearchSqlServerDatabaseUsedLicenses(hostname string, sortBy string, sortDesc bool, page int, pageSize int,
+	location string, environment string, olderThan time.Time,
+) (*dto.SqlServerDatabaseUsedLicenseSearchResponse, error) {
+	live, err := db.MongoDatabaseInterface.SearchSqlServerDatabaseUsedLicenses(hostname, sortBy, sortDesc, page, pageSize, location, environment, olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]dto.SqlServerDatabaseUsedLicense, 0, len(live.Content))
+
+	for _, usedLicense := range live.Content {
+		if !db.removed[usedLicense.Hostname] {
+			content = append(content, usedLicense)
+		}
+	}
+
+	return &dto.SqlServerDatabaseUsedLicenseSearchResponse{
+		Content:  content,
+		Metadata: live.Metadata,
+	}, nil
+}
+
+func (db *scenarioDatabase) simulateCluster(cluster dto.Cluster) dto.Cluster {
+	vms := make([]dto.VM, 0, len(cluster.VMs))
+
+	for _, vm := range cluster.VMs {
+		if _, moved := db.clusters[vm.Hostname]; moved || db.removed[vm.Hostname] {
+			continue
+		}
+
+		vms = append(vms, vm)
+	}
+
+	hostnames := make([]string, 0, len(db.clusters))
+	for hostname := range db.clusters {
+		hostnames = append(hostnames, hostname)
+	}
+
+	sort.Strings(hostnames)
+
+	for _, hostname := range hostnames {
+		if db.clusters[hostname] == cluster.Name {
+			vms = append(vms, db.vms[hostname])
+		}
+	}
+
+	cluster.VMs = vms
+	cluster.VMsCount = len(vms)
+	cluster.VMsErcoleAgentCount = 0
+
+	for _, vm := range vms {
+		if vm.IsErcoleInstalled {
+			cluster.VMsErcoleAgentCount++
+		}
+	}
+
+	return cluster
+}
+
+func (db *scenarioDatabase) GetClusters(filter dto.GlobalFilter) ([]dto.Cluster, error) {
+	clusters, err := db.MongoDatabaseInterface.GetClusters(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		res = append(res, db.simulateCluster(cluster))
+	}
+
+	return res, nil
+}
+
+func (db *scenarioDatabase) GetCluster(clusterName string, olderThan time.Time) (*dto.Cluster, error) {
+	cluster, err := db.MongoDatabaseInterface.GetCluster(clusterName, olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	simulated := db.simulateCluster(*cluster)
+
+	return &simulated, nil
+}
+
+func (db *scenarioDatabase) FindClusterVeritasLicenses(filter dto.GlobalFilter) ([]dto.ClusterVeritasLicense, error) {
+	licenses, err := db.MongoDatabaseInterface.FindClusterVeritasLicenses(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.ClusterVeritasLicense, 0, len(licenses))
+
+	for _, license := range licenses {
+		simulated := false
+
+		for _, hostname := range license.Hostnames {
+			simulated = simulated || db.isSimulated(hostname)
+		}
+
+		if !simulated {
+			res = append(res, license)
+			continue
+		}
+
+		hostnames := make([]string, 0, len(license.Hostnames))
+		cores := 0
+
+		for _, hostname := range license.Hostnames {
+			if db.removed[hostname] {
+				continue
+			}
+
+			hostCores, err := db.GetCpuCore(hostname)
+			if err != nil {
+				continue
+			}
+
+			hostnames = append(hostnames, hostname)
+			cores += hostCores
+		}
+
+		if len(hostnames) == 0 {
+			continue
+		}
+
+		license.Hostnames = hostnames
+
+		switch {
+		case license.LicenseTypeID == "L47837":
+			license.Count = float64(len(hostnames))
+		case license.Metric == model.LicenseTypeMetricNamedUserPlusPerpetual:
+			license.Count = float64(cores) / 2 * 25
+		default:
+			license.Count = float64(cores) / 2
+		}
+
+		res = append(res, license)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,391 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func scenarioTestHostData(hostname string, cores int, licenseTypeID string, count float64) model.HostDataBE {
+	return model.HostDataBE{
+		Hostname: hostname,
+		Location: "Italy",
+		Info: model.Host{
+			Hostname: hostname,
+			CPUCores: cores,
+		},
+		Features: model.Features{
+			Oracle: &model.OracleFeature{
+				Database: &model.OracleDatabaseFeature{
+					Databases: []model.OracleDatabase{
+						{
+							Name:    "ERCOLE",
+							Version: "19.0.0.0.0 Enterprise Edition",
+							Licenses: []model.OracleDatabaseLicense{
+								{LicenseTypeID: licenseTypeID, Count: count},
+								{LicenseTypeID: "A90650", Count: 0},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCreateScenario_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().GetCoreFactors().Return([]model.CoreFactor{}, nil).AnyTimes()
+
+	host := scenarioTestHostData("ercsoldbx", 4, "A90611", 2)
+	licenseTypes := []model.OracleDatabaseLicenseType{
+		{ID: "A90611", ItemDescription: "Oracle Database Enterprise Edition", Metric: model.LicenseTypeMetricProcessorPerpetual},
+		{ID: "A90650", ItemDescription: "Oracle Partitioning", Metric: model.LicenseTypeMetricProcessorPerpetual},
+	}
+	liveUsedLicenses := &dto.OracleDatabaseUsedLicenseSearchResponse{
+		Content: []dto.OracleDatabaseUsedLicense{
+			{Hostname: "ercsoldbx", DbName: "ERCOLE", LicenseTypeID: "A90611", UsedLicenses: 2},
+		},
+	}
+
+	db.EXPECT().FindHostData("ercsoldbx").Return(host, nil)
+	db.EXPECT().GetOracleDatabaseLicenseTypes().Return(licenseTypes, nil).AnyTimes()
+	db.EXPECT().ListOracleDatabaseContracts(gomock.Any()).Return([]dto.OracleDatabaseContractFE{}, nil).AnyTimes()
+	db.EXPECT().SearchOracleDatabaseUsedLicenses("", "", false, -1, -1, "Italy", "", gomock.Any()).
+		Return(liveUsedLicenses, nil).AnyTimes()
+	db.EXPECT().SearchOracleDatabaseUsedLicenses("", "", false, -1, -1, "", "", gomock.Any()).
+		Return(liveUsedLicenses, nil).AnyTimes()
+	db.EXPECT().GetHostDatas(gomock.Any()).Return([]model.HostDataBE{host}, nil).AnyTimes()
+	db.EXPECT().GetClusters(gomock.Any()).Return([]dto.Cluster{}, nil).AnyTimes()
+	db.EXPECT().ExistHostdataBatch(gomock.Any()).Return([]string{}, nil).AnyTimes()
+	db.EXPECT().GetMySQLUsedLicenses(gomock.Any(), gomock.Any()).Return([]dto.MySQLUsedLicense{}, nil).AnyTimes()
+	db.EXPECT().GetMySQLContracts(gomock.Any()).Return([]model.MySQLContract{}, nil).AnyTimes()
+	db.EXPECT().SearchSqlServerDatabaseUsedLicenses(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&dto.SqlServerDatabaseUsedLicenseSearchResponse{}, nil).AnyTimes()
+	db.EXPECT().GetSqlServerDatabaseLicenseTypes().Return([]model.SqlServerDatabaseLicenseType{}, nil).AnyTimes()
+	db.EXPECT().ListSqlServerDatabaseContracts(gomock.Any()).Return([]model.SqlServerDatabaseContract{}, nil).AnyTimes()
+	db.EXPECT().FindClusterVeritasLicenses(gomock.Any()).Return([]dto.ClusterVeritasLicense{}, nil).AnyTimes()
+	db.EXPECT().CreateScenario(gomock.Any()).DoAndReturn(func(scenario *model.Scenario) (*model.Scenario, error) {
+		return scenario, nil
+	})
+
+	req := dto.CreateScenarioRequest{
+		Name:     "more cores",
+		Location: "Italy",
+		Hosts: []dto.CreateHostScenarioRequest{
+			{Hostname: "ercsoldbx", Core: 8},
+		},
+	}
+
+	res, err := as.CreateScenario(req)
+	require.NoError(t, err)
+
+	require.Len(t, res.LicenseUsed.LicenseDatabase.Actual, 1)
+	assert.Equal(t, 2.0, res.LicenseUsed.LicenseDatabase.Actual[0].UsedLicenses)
+	require.Len(t, res.LicenseUsed.LicenseDatabase.Got, 1)
+	assert.Equal(t, 4.0, res.LicenseUsed.LicenseDatabase.Got[0].UsedLicenses)
+
+	require.Len(t, res.Hosts, 1)
+	assert.Equal(t, 4, res.Hosts[0].Host.Info.CPUCores)
+	assert.Equal(t, 2.0, res.Hosts[0].Host.Features.Oracle.Database.Databases[0].Licenses[0].Count)
+	assert.Equal(t, 2.0, host.Features.Oracle.Database.Databases[0].Licenses[0].Count)
+}
+
+func TestCreateScenario_RemovedHostReused(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+	}
+
+	db.EXPECT().ExistHostdata("ercsoldbx").Return(true, nil)
+
+	req := dto.CreateScenarioRequest{
+		Name:        "invalid",
+		RemoveHosts: []string{"ercsoldbx"},
+		Hosts: []dto.CreateHostScenarioRequest{
+			{Hostname: "ercsoldbx", Core: 8},
+		},
+	}
+
+	res, err := as.CreateScenario(req)
+	assert.ErrorIs(t, err, utils.ErrInvalidScenario)
+	assert.Nil(t, res)
+}
+
+func TestCreateScenario_HypotheticalHostAlreadyExists(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+	}
+
+	db.EXPECT().GetClusters(gomock.Any()).Return([]dto.Cluster{}, nil)
+	db.EXPECT().ExistHostdata("ercsoldbx").Return(true, nil)
+
+	req := dto.CreateScenarioRequest{
+		Name: "invalid",
+		AddHosts: []dto.AddHostScenarioRequest{
+			{Hostname: "ercsoldbx", Core: 8},
+		},
+	}
+
+	res, err := as.CreateScenario(req)
+	assert.ErrorIs(t, err, utils.ErrInvalidScenario)
+	assert.Nil(t, res)
+}
+
+func TestCreateScenario_MoveHostClusterNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	as := APIService{
+		Database: db,
+		Config:   config.Configuration{},
+	}
+
+	db.EXPECT().GetClusters(gomock.Any()).Return([]dto.Cluster{{Name: "cluster1"}}, nil)
+
+	req := dto.CreateScenarioRequest{
+		Name: "invalid",
+		MoveHosts: []dto.MoveHostScenarioRequest{
+			{Hostname: "ercsoldbx", Cluster: "cluster2"},
+		},
+	}
+
+	res, err := as.CreateScenario(req)
+	assert.ErrorIs(t, err, utils.ErrClusterNotFound)
+	assert.Nil(t, res)
+}
+
+func TestScenarioDatabase_Hosts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	live := []model.HostDataBE{
+		scenarioTestHostData("host1", 4, "A90611", 2),
+		scenarioTestHostData("host2", 4, "A90611", 2),
+		scenarioTestHostData("host3", 4, "A90611", 2),
+	}
+
+	scenario := &model.Scenario{
+		Hosts: []model.SimulatedHost{
+			{Host: live[0], Core: 16},
+		},
+		HypotheticalHosts: []model.HypotheticalHost{
+			{Host: scenarioTestHostData("host4", 2, "A90611", 1)},
+		},
+		RemovedHosts: []string{"host2"},
+		LicenseTypeChanges: []model.LicenseTypeChange{
+			{Hostname: "host3", FromLicenseTypeID: "A90611", ToLicenseTypeID: "L76084"},
+		},
+	}
+
+	db.EXPECT().GetOracleDatabaseLicenseTypes().Return([]model.OracleDatabaseLicenseType{
+		{ID: "A90611"}, {ID: "L76084"},
+	}, nil)
+	db.EXPECT().FindHostData("host3").Return(live[2], nil)
+
+	scenarioDB, err := newScenarioDatabase(db, scenario)
+	require.NoError(t, err)
+
+	filter := dto.GlobalFilter{Location: "Italy", OlderThan: utils.MAX_TIME}
+	db.EXPECT().GetHostDatas(filter).Return(live, nil)
+
+	hostdatas, err := scenarioDB.GetHostDatas(filter)
+	require.NoError(t, err)
+
+	hostnames := make([]string, 0)
+	for _, hd := range hostdatas {
+		hostnames = append(hostnames, hd.Hostname)
+	}
+
+	assert.Equal(t, []string{"host1", "host3", "host4"}, hostnames)
+	assert.Equal(t, 16, hostdatas[0].Info.CPUCores)
+	assert.Equal(t, 8.0, hostdatas[0].Features.Oracle.Database.Databases[0].Licenses[0].Count)
+	assert.Equal(t, 0.0, hostdatas[0].Features.Oracle.Database.Databases[0].Licenses[1].Count)
+	assert.Equal(t, "L76084", hostdatas[1].Features.Oracle.Database.Databases[0].Licenses[0].LicenseTypeID)
+
+	assert.Equal(t, 4, live[0].Info.CPUCores)
+	assert.Equal(t, 2.0, live[0].Features.Oracle.Database.Databases[0].Licenses[0].Count)
+	assert.Equal(t, "A90611", live[2].Features.Oracle.Database.Databases[0].Licenses[0].LicenseTypeID)
+
+	_, err = scenarioDB.FindHostData("host2")
+	assert.ErrorIs(t, err, utils.ErrHostNotFound)
+
+	cores, err := scenarioDB.GetCpuCore("host4")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cores)
+
+	db.EXPECT().ExistHostdataBatch([]string{"host5"}).Return([]string{"host5"}, nil)
+
+	existing, err := scenarioDB.ExistHostdataBatch([]string{"host2", "host4", "host5"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host4", "host5"}, existing)
+}
+
+func TestScenarioDatabase_SearchOracleDatabaseUsedLicenses(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	scenario := &model.Scenario{
+		Hosts: []model.SimulatedHost{
+			{Host: scenarioTestHostData("host1", 4, "A90611", 2), Core: 16},
+		},
+		HypotheticalHosts: []model.HypotheticalHost{
+			{Host: scenarioTestHostData("host4", 2, "L76084", 1)},
+		},
+		RemovedHosts: []string{"host2"},
+	}
+
+	db.EXPECT().GetOracleDatabaseLicenseTypes().Return([]model.OracleDatabaseLicenseType{
+		{ID: "A90611", Metric: model.LicenseTypeMetricProcessorPerpetual},
+		{ID: "L76084", Metric: model.LicenseTypeMetricComputerPerpetual},
+	}, nil)
+
+	scenarioDB, err := newScenarioDatabase(db, scenario)
+	require.NoError(t, err)
+
+	db.EXPECT().SearchOracleDatabaseUsedLicenses("", "", false, -1, -1, "", "", utils.MAX_TIME).
+		Return(&dto.OracleDatabaseUsedLicenseSearchResponse{
+			Content: []dto.OracleDatabaseUsedLicense{
+				{Hostname: "host1", DbName: "ERCOLE", LicenseTypeID: "A90611", UsedLicenses: 2},
+				{Hostname: "host2", DbName: "ERCOLE", LicenseTypeID: "A90611", UsedLicenses: 2},
+				{Hostname: "host3", DbName: "ERCOLE", LicenseTypeID: "A90611", UsedLicenses: 2},
+			},
+		}, nil)
+
+	res, err := scenarioDB.SearchOracleDatabaseUsedLicenses("", "", false, -1, -1, "", "", utils.MAX_TIME)
+	require.NoError(t, err)
+
+	assert.Equal(t, []dto.OracleDatabaseUsedLicense{
+		{Hostname: "host3", DbName: "ERCOLE", LicenseTypeID: "A90611", UsedLicenses: 2},
+		{Hostname: "host1", DbName: "ERCOLE", LicenseTypeID: "A90611", UsedLicenses: 8},
+		{Hostname: "host4", DbName: "ERCOLE", LicenseTypeID: "L76084", UsedLicenses: 1},
+	}, res.Content)
+}
+
+func TestScenarioDatabase_GetClusters(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	clusters := []dto.Cluster{
+		{
+			Name: "cluster1",
+			CPU:  32,
+			VMs: []dto.VM{
+				{Hostname: "host1", Name: "host1", CappedCPU: true, IsErcoleInstalled: true},
+				{Hostname: "host2", Name: "host2", IsErcoleInstalled: true},
+			},
+			VMsCount:            2,
+			VMsErcoleAgentCount: 2,
+		},
+		{
+			Name: "cluster2",
+			CPU:  16,
+			VMs: []dto.VM{
+				{Hostname: "host3", Name: "host3", IsErcoleInstalled: true},
+			},
+			VMsCount:            1,
+			VMsErcoleAgentCount: 1,
+		},
+	}
+
+	scenario := &model.Scenario{
+		HypotheticalHosts: []model.HypotheticalHost{
+			{Host: scenarioTestHostData("host4", 2, "A90611", 1), Cluster: "cluster2"},
+		},
+		RemovedHosts: []string{"host2"},
+		MovedHosts: []model.MovedHost{
+			{Hostname: "host1", FromCluster: "cluster1", ToCluster: "cluster2"},
+		},
+	}
+
+	db.EXPECT().GetOracleDatabaseLicenseTypes().Return([]model.OracleDatabaseLicenseType{}, nil)
+	db.EXPECT().GetClusters(dto.GlobalFilter{OlderThan: utils.MAX_TIME}).Return(clusters, nil).Times(2)
+
+	scenarioDB, err := newScenarioDatabase(db, scenario)
+	require.NoError(t, err)
+
+	res, err := scenarioDB.GetClusters(dto.GlobalFilter{OlderThan: utils.MAX_TIME})
+	require.NoError(t, err)
+
+	expected := []dto.Cluster{
+		{
+			Name:                "cluster1",
+			CPU:                 32,
+			VMs:                 []dto.VM{},
+			VMsCount:            0,
+			VMsErcoleAgentCount: 0,
+		},
+		{
+			Name: "cluster2",
+			CPU:  16,
+			VMs: []dto.VM{
+				{Hostname: "host3", Name: "host3", IsErcoleInstalled: true},
+				{Hostname: "host1", Name: "host1", CappedCPU: true, IsErcoleInstalled: true},
+				{Hostname: "host4", Name: "host4", IsErcoleInstalled: true},
+			},
+			VMsCount:            3,
+			VMsErcoleAgentCount: 3,
+		},
+	}
+	assert.Equal(t, expected, res)
+
+	assert.Len(t, clusters[0].VMs, 2)
+}
+
+func TestNewScenarioDatabase_LicenseNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	scenario := &model.Scenario{
+		LicenseTypeChanges: []model.LicenseTypeChange{
+			{Hostname: "host1", FromLicenseTypeID: "L47210", ToLicenseTypeID: "A90611"},
+		},
+	}
+
+	db.EXPECT().GetOracleDatabaseLicenseTypes().Return([]model.OracleDatabaseLicenseType{{ID: "A90611"}}, nil)
+	db.EXPECT().FindHostData("host1").Return(scenarioTestHostData("host1", 4, "A90611", 2), nil)
+
+	_, err := newScenarioDatabase(db, scenario)
+	assert.ErrorIs(t, err, utils.ErrLicenseNotFound)
+}
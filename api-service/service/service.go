@@ -24,6 +24,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	alertServiceClient "github.com/ercole-io/ercole/v2/alert-service/client"
+	"github.com/ercole-io/ercole/v2/alert-service/emailer"
 	"github.com/ercole-io/ercole/v2/api-service/database"
 	"github.com/ercole-io/ercole/v2/api-service/domain"
 	"github.com/ercole-io/ercole/v2/api-service/dto"
@@ -45,12 +46,16 @@ type APIServiceInterface interface {
 	GetHostDataSummaries(filters dto.SearchHostsFilters) ([]dto.HostDataSummary, error)
 	// GetHost return the host specified in the hostname param
 	GetHost(hostname string, olderThan time.Time, raw bool) (*dto.HostData, error)
+	// GetHostDiff return what changed on the host between the snapshot valid at from and the one valid at to
+	GetHostDiff(hostname string, from, to time.Time) (*dto.HostDiff, error)
+	// GetHostTimeline return the snapshots of the host inserted between from and to that changed something
+	GetHostTimeline(hostname string, from, to time.Time) (*dto.HostTimeline, error)
 	// ListManagedTechnologies returns the list of technologies with some stats
 	ListManagedTechnologies(sortBy string, sortDesc bool, location string, environment string, olderThan time.Time) ([]model.TechnologyStatus, error)
 	// SearchAlerts search alerts
 	SearchAlerts(alertFilter alert_filter.Alert) (*dto.Pagination, error)
-	SearchAlertsAsXLSX(status string, from, to time.Time, filter dto.GlobalFilter) (*excelize.File, error)
-	GetAlerts(status string, from, to time.Time, filter dto.GlobalFilter) ([]map[string]interface{}, error)
+	SearchAlertsAsXLSX(status string, from, to time.Time, filter dto.GlobalFilter, lifecycle alert_filter.AlertLifecycle) (*excelize.File, error)
+	GetAlerts(status string, from, to time.Time, filter dto.GlobalFilter, lifecycle alert_filter.AlertLifecycle) ([]map[string]interface{}, error)
 	// SearchClusters search clusters
 	SearchClusters(mode string, search string, sortBy string, sortDesc bool, page int, pageSize int, location string, environment string, olderThan time.Time) ([]dto.Cluster, error)
 	SearchClustersAsXLSX(filter dto.GlobalFilter) (*excelize.File, error)
@@ -149,6 +154,10 @@ type APIServiceInterface interface {
 	DeleteOracleDatabaseLicenseType(id string) error
 	AddOracleDatabaseLicenseType(licenseType model.OracleDatabaseLicenseType) (*model.OracleDatabaseLicenseType, error)
 	UpdateOracleDatabaseLicenseType(licenseType model.OracleDatabaseLicenseType) (*model.OracleDatabaseLicenseType, error)
+	GetCoreFactors() ([]model.CoreFactor, error)
+	AddCoreFactor(coreFactor model.CoreFactor) (*model.CoreFactor, error)
+	UpdateCoreFactor(coreFactor model.CoreFactor) (*model.CoreFactor, error)
+	DeleteCoreFactor(id primitive.ObjectID) error
 
 	ListOracleGrantDbaByHostname(hostname string, filter dto.GlobalFilter) ([]dto.OracleGrantDbaDto, error)
 	CreateOracleGrantDbaXlsx(hostname string, filter dto.GlobalFilter) (*excelize.File, error)
@@ -222,10 +231,18 @@ type APIServiceInterface interface {
 
 	ImportSQLServerDatabaseContracts(reader *csv.Reader) error
 
-	// AckAlerts ack the specified alerts
-	AckAlerts(alertsFilter dto.AlertsFilter) error
-	// DismissHost dismiss the specified host
-	DismissHost(hostname string) error
+	// GetAlert return the alert specified by id
+	GetAlert(id primitive.ObjectID) (*model.Alert, error)
+	// AckAlerts ack the specified alerts on behalf of the user
+	AckAlerts(alertsFilter dto.AlertsFilter, username string) error
+	// AssignAlert set the assignee of the alert, an empty assignee remove the assignment
+	AssignAlert(id primitive.ObjectID, assignee, username string) (*model.Alert, error)
+	// CommentAlert add a comment to the alert
+	CommentAlert(id primitive.ObjectID, text, username string) (*model.Alert, error)
+	// ResolveAlert set the alert as resolved for the reason
+	ResolveAlert(id primitive.ObjectID, reason, username string) (*model.Alert, error)
+	// DismissHost dismiss the specified host on behalf of the user
+	DismissHost(hostname, username string) error
 
 	CreateDR(hostname string) (string, error)
 
@@ -234,9 +251,12 @@ type APIServiceInterface interface {
 	UpdateMissingDatabaseIgnoredField(hostname string, dbname string, ignored bool, ignoredComment string) error
 
 	GetVirtualHostWithoutCluster() ([]dto.VirtualHostWithoutCluster, error)
+	// GetClusterReconciliation return the inconsistencies between the clusters and the hosts
+	GetClusterReconciliation(filter dto.GlobalFilter) (*dto.ClusterReconciliation, error)
+	GetClusterReconciliationAsXLSX(filter dto.GlobalFilter) (*excelize.File, error)
 
-	// UpdateAlertsStatus update alerts status
-	UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus string) error
+	// UpdateAlertsStatus update alerts status on behalf of the user
+	UpdateAlertsStatus(alertsFilter dto.AlertsFilter, newStatus, username string) error
 
 	// GetInfoForFrontendDashboard return all informations needed for the frontend dashboard page
 	GetInfoForFrontendDashboard(location string, environment string, olderThan time.Time) (map[string]interface{}, error)
@@ -308,6 +328,40 @@ type APIServiceInterface interface {
 	AddRole(role model.Role) error
 	UpdateRole(role model.Role) error
 	RemoveRole(roleName string) error
+	GetUserPermission(user interface{}, location string) (string, error)
+
+	// ALERT ROUTING RULES
+	ListAlertRoutingRules() ([]model.AlertRoutingRule, error)
+	GetAlertRoutingRule(id primitive.ObjectID) (*model.AlertRoutingRule, error)
+	AddAlertRoutingRule(rule model.AlertRoutingRule) (*model.AlertRoutingRule, error)
+	UpdateAlertRoutingRule(rule model.AlertRoutingRule) (*model.AlertRoutingRule, error)
+	DeleteAlertRoutingRule(id primitive.ObjectID) error
+
+	// ListReportSubscriptions return all the report subscriptions
+	ListReportSubscriptions() ([]model.ReportSubscription, error)
+	// GetReportSubscription return the report subscription specified by id
+	GetReportSubscription(id primitive.ObjectID) (*model.ReportSubscription, error)
+	// AddReportSubscription validate and insert a report subscription
+	AddReportSubscription(subscription model.ReportSubscription) (*model.ReportSubscription, error)
+	// UpdateReportSubscription validate and update a report subscription
+	UpdateReportSubscription(subscription model.ReportSubscription) (*model.ReportSubscription, error)
+	// DeleteReportSubscription delete a report subscription and its history
+	DeleteReportSubscription(id primitive.ObjectID) error
+	// ListReportRuns return the history of the deliveries of a report subscription
+	ListReportRuns(id primitive.ObjectID) ([]model.ReportRun, error)
+	// RunReportSubscription deliver now a report subscription
+	RunReportSubscription(id primitive.ObjectID) (*model.ReportRun, error)
+	// RunDueReportSubscriptions deliver the report subscriptions whose schedule is due
+	RunDueReportSubscriptions() error
+	// DryRunAlertRouting return the route that the current rules would give to the alert
+	DryRunAlertRouting(alertID primitive.ObjectID) (*model.AlertRoute, error)
+
+	// MAINTENANCE WINDOWS
+	ListMaintenanceWindows() ([]model.MaintenanceWindow, error)
+	GetMaintenanceWindow(id primitive.ObjectID) (*model.MaintenanceWindow, error)
+	AddMaintenanceWindow(window model.MaintenanceWindow) (*model.MaintenanceWindow, error)
+	UpdateMaintenanceWindow(window model.MaintenanceWindow) (*model.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(id primitive.ObjectID) error
 
 	// GROUPS
 	InsertGroup(group model.Group) (*model.Group, error)
@@ -334,6 +388,44 @@ type APIServiceInterface interface {
 	MatchPassword(user *model.User, password string) bool
 	GetUserLocations(username string) ([]string, error)
 
+	// API TOKENS
+	// ListAPITokens return the API tokens of the user, or all of them if username is empty
+	ListAPITokens(username string) ([]model.APIToken, error)
+	// CreateAPIToken validate and insert an API token, returning it with its value
+	CreateAPIToken(token model.APIToken) (*model.APIToken, string, error)
+	// RevokeAPIToken revoke the API token
+	RevokeAPIToken(id primitive.ObjectID) error
+	// AuthenticateAPIToken return the owner of the API token, if the token is valid
+	AuthenticateAPIToken(value string) (*model.User, *model.APIToken, error)
+
+	// SERVICE ACCOUNTS
+	// ListServiceAccounts return the users used by the automations
+	ListServiceAccounts() ([]model.User, error)
+	// AddServiceAccount insert an user that can authenticate only with its API tokens
+	AddServiceAccount(user model.User) (*model.User, error)
+	// RemoveServiceAccount revoke the API tokens of the service account and remove it
+	RemoveServiceAccount(username string) error
+
+	// AGENT CREDENTIALS
+	// ListAgentCredentials return the credentials enrolled for the agents
+	ListAgentCredentials() ([]model.AgentCredential, error)
+	// EnrollAgentCredential create the credential of the agent of the host, returning it with its secret
+	EnrollAgentCredential(hostname string) (*model.AgentCredential, string, error)
+	// RotateAgentCredential replace the secret of the credential of the host
+	RotateAgentCredential(hostname string) (*model.AgentCredential, string, error)
+	// RevokeAgentCredential revoke the credential of the host
+	RevokeAgentCredential(hostname string) error
+
+	// AUDIT
+	// InsertAuditEntry append the entry to the audit log
+	InsertAuditEntry(entry model.AuditEntry) error
+	// SearchAuditEntries return the entries of the audit log that match the filter
+	SearchAuditEntries(filter dto.AuditFilter) (*dto.AuditEntriesResponse, error)
+	// SearchAuditEntriesAsXLSX return the entries of the audit log that match the filter as XLSX file
+	SearchAuditEntriesAsXLSX(filter dto.AuditFilter) (*excelize.File, error)
+	// DeleteOldAuditEntries remove the entries of the audit log older than the retention
+	DeleteOldAuditEntries() error
+
 	GetNodes(groups []string) ([]model.Node, error)
 	GetNode(name string) (*model.Node, error)
 	AddNode(node model.Node) error
@@ -378,6 +470,10 @@ type APIService struct {
 	mockGetOracleDatabaseContracts func(filters dto.GetOracleDatabaseContractsFilter) ([]dto.OracleDatabaseContractFE, error)
 
 	AlertSvcClient alertServiceClient.AlertSvcClientInterface
+	// Emailer is used to deliver the report subscriptions
+	Emailer emailer.Emailer
+	// ReportExporters contains the exporters of the reports not provided by the api-service
+	ReportExporters map[string]ReportExporter
 }
 
 // Init initializes the service and database
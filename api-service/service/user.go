@@ -82,7 +82,12 @@ func (as *APIService) AddLimitedGroup(updatedUser model.User) error {
 	return as.Database.UpdateUserGroups(updatedUser.Username, updatedUser.Groups)
 }
 
+// RemoveUser revoke the API tokens of the user and remove it
 func (as *APIService) RemoveUser(username string) error {
+	if err := as.Database.RevokeUserAPITokens(username, as.TimeNow()); err != nil {
+		return err
+	}
+
 	return as.Database.RemoveUser(username)
 }
 
@@ -21,6 +21,7 @@ import (
 
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -143,12 +144,21 @@ func TestRemoveUser(t *testing.T) {
 	db := NewMockMongoDatabaseInterface(mockCtrl)
 	as := APIService{
 		Database: db,
+		TimeNow:  utils.Btc(utils.P("2024-03-11T08:00:00Z")),
 	}
 
 	t.Run("Success", func(t *testing.T) {
+		db.EXPECT().RevokeUserAPITokens("username", utils.P("2024-03-11T08:00:00Z")).Return(nil)
 		db.EXPECT().RemoveUser("username").Return(nil)
 
 		err := as.RemoveUser("username")
 		assert.Nil(t, err)
 	})
+
+	t.Run("Error revoking the API tokens", func(t *testing.T) {
+		db.EXPECT().RevokeUserAPITokens("username", utils.P("2024-03-11T08:00:00Z")).Return(errMock)
+
+		err := as.RemoveUser("username")
+		require.EqualError(t, err, "MockError")
+	})
 }
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
 	"github.com/ercole-io/ercole/v2/chart-service/service"
 	"github.com/ercole-io/ercole/v2/logger"
 	"github.com/ercole-io/ercole/v2/utils"
@@ -56,6 +57,10 @@ type ChartController struct {
 	Log logger.Logger
 	// Authenticator contains the authenticator
 	Authenticator []auth.AuthenticationProvider
+	// LocationsService lists the locations visible by the users
+	LocationsService middleware.LocationsService
+	// AuditService records the requests that modify data in the audit log
+	AuditService middleware.AuditService
 }
 
 // GetTechnologiesMetrics return metrics of all technologies
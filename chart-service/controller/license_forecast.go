@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/golang/gddo/httputil"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const defaultLicenseForecastHorizon = 90
+
+// GetLicenseComplianceForecast return the forecast of consumed licenses in JSON or XLSX format
+func (ctrl *ChartController) GetLicenseComplianceForecast(w http.ResponseWriter, r *http.Request) {
+	horizon, err := utils.Str2int(r.URL.Query().Get("horizon"), defaultLicenseForecastHorizon)
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	}
+
+	location := r.URL.Query().Get("location")
+
+	choice := httputil.NegotiateContentType(r, []string{"application/json", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, "application/json")
+
+	switch choice {
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		xlsx, err := ctrl.Service.GetLicenseComplianceForecastAsXLSX(horizon, location)
+		if err != nil {
+			ctrl.writeLicenseForecastError(w, err)
+			return
+		}
+
+		utils.WriteXLSXResponse(w, xlsx)
+
+	default:
+		forecast, err := ctrl.Service.GetLicenseComplianceForecast(horizon, location)
+		if err != nil {
+			ctrl.writeLicenseForecastError(w, err)
+			return
+		}
+
+		response := map[string]interface{}{
+			"licenseComplianceForecast": forecast,
+		}
+
+		utils.WriteJSONResponse(w, http.StatusOK, response)
+	}
+}
+
+func (ctrl *ChartController) writeLicenseForecastError(w http.ResponseWriter, err error) {
+	if errors.Is(err, utils.ErrInvalidForecastHorizon) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+}
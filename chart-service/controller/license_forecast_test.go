@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	dto "github.com/ercole-io/ercole/v2/chart-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestGetLicenseComplianceForecast_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockChartServiceInterface(mockCtrl)
+	ac := ChartController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	breach := utils.P("2019-12-01T00:00:00Z")
+	forecast := []dto.LicenseComplianceForecast{
+		{
+			LicenseTypeID: "A90611",
+			Model:         dto.LicenseForecastModelLinear,
+			Samples:       30,
+			Trend:         0.5,
+			Consumed:      20,
+			Purchased:     30,
+			BreachDate:    &breach,
+			Forecast:      []dto.LicenseComplianceForecastValue{},
+		},
+	}
+
+	as.EXPECT().GetLicenseComplianceForecast(90, "").
+		Return(forecast, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.GetLicenseComplianceForecast)
+	req, err := http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	expected := map[string]interface{}{
+		"licenseComplianceForecast": forecast,
+	}
+	assert.JSONEq(t, utils.ToJSON(expected), rr.Body.String())
+}
+
+func TestGetLicenseComplianceForecast_HorizonAndLocation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockChartServiceInterface(mockCtrl)
+	ac := ChartController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	as.EXPECT().GetLicenseComplianceForecast(365, "Italy,Germany").
+		Return([]dto.LicenseComplianceForecast{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.GetLicenseComplianceForecast)
+	req, err := http.NewRequest("GET", "/?horizon=365&location=Italy,Germany", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestGetLicenseComplianceForecast_XLSX(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockChartServiceInterface(mockCtrl)
+	ac := ChartController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	xlsx := excelize.File{}
+
+	as.EXPECT().GetLicenseComplianceForecastAsXLSX(90, "Italy").
+		Return(&xlsx, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.GetLicenseComplianceForecast)
+	req, err := http.NewRequest("GET", "/?location=Italy", nil)
+	require.NoError(t, err)
+
+	req.Header.Add("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	_, err = excelize.OpenReader(rr.Body)
+	require.NoError(t, err)
+}
+
+func TestGetLicenseComplianceForecast_BadRequest(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockChartServiceInterface(mockCtrl)
+	ac := ChartController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	t.Run("Horizon isn't a number", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(ac.GetLicenseComplianceForecast)
+		req, err := http.NewRequest("GET", "/?horizon=foo", nil)
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Horizon out of range", func(t *testing.T) {
+		as.EXPECT().GetLicenseComplianceForecast(5000, "").
+			Return(nil, utils.NewErrorf("%w: 5000", utils.ErrInvalidForecastHorizon))
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(ac.GetLicenseComplianceForecast)
+		req, err := http.NewRequest("GET", "/?horizon=5000", nil)
+		require.NoError(t, err)
+
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetLicenseComplianceForecast_InternalServerError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockChartServiceInterface(mockCtrl)
+	ac := ChartController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	as.EXPECT().GetLicenseComplianceForecast(90, "").
+		Return(nil, aerrMock)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.GetLicenseComplianceForecast)
+	req, err := http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+}
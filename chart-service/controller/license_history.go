@@ -34,7 +34,7 @@ func (ctrl *ChartController) GetLicenseComplianceHistory(w http.ResponseWriter,
 		return
 	}
 
-	history, err := ctrl.Service.GetLicenseComplianceHistory(startdate, enddate)
+	history, err := ctrl.Service.GetLicenseComplianceHistory(startdate, enddate, r.URL.Query().Get("location"))
 	if err != nil {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
 		return
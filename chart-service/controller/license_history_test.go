@@ -46,7 +46,7 @@ func TestGetLicenseComplianceHistory_Success(t *testing.T) {
 	start := utils.MIN_TIME
 	end := utils.MAX_TIME
 
-	as.EXPECT().GetLicenseComplianceHistory(start, end).
+	as.EXPECT().GetLicenseComplianceHistory(start, end, "").
 		Return(history, nil)
 
 	rr := httptest.NewRecorder()
@@ -78,7 +78,7 @@ func TestGetLicenseComplianceHistory_InternalServerError(t *testing.T) {
 	start := utils.MIN_TIME
 	end := utils.MAX_TIME
 
-	as.EXPECT().GetLicenseComplianceHistory(start, end).
+	as.EXPECT().GetLicenseComplianceHistory(start, end, "").
 		Return(nil, aerrMock)
 
 	rr := httptest.NewRecorder()
@@ -90,3 +90,29 @@ func TestGetLicenseComplianceHistory_InternalServerError(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, rr.Code)
 }
+
+func TestGetLicenseComplianceHistory_Location(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockChartServiceInterface(mockCtrl)
+	ac := ChartController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	history := []dto.LicenseComplianceHistory{}
+
+	as.EXPECT().GetLicenseComplianceHistory(utils.MIN_TIME, utils.MAX_TIME, "Italy,Germany").
+		Return(history, nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.GetLicenseComplianceHistory)
+	req, err := http.NewRequest("GET", "/?location=Italy,Germany", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
+	"github.com/ercole-io/ercole/v2/model"
 )
 
 // GetChartControllerHandler setup the routes of the router using the handler in the controller as http handler
@@ -42,7 +44,16 @@ func (ctrl *ChartController) GetChartControllerHandler(auths []auth.Authenticati
 			prefix = "/ldap"
 		}
 
+		if ap.GetType() == auth.OidcType {
+			prefix = "/oidc"
+		}
+
 		subrouter.Use(ap.AuthenticateMiddleware)
+		subrouter.Use(middleware.Audit(ctrl.AuditService, ctrl.Log, middleware.AuditOptions{
+			Service:      model.AuditServiceChart,
+			AuthProvider: ap.GetType(),
+			Prefix:       prefix,
+		}))
 		ctrl.setupProtectedRoutes(subrouter.PathPrefix(prefix).Subrouter())
 	}
 
@@ -52,7 +63,10 @@ func (ctrl *ChartController) GetChartControllerHandler(auths []auth.Authenticati
 func (ctrl *ChartController) setupProtectedRoutes(router *mux.Router) {
 	router.HandleFunc("/settings/technologies-metrics", ctrl.GetTechnologiesMetrics).Methods("GET")
 
-	router.HandleFunc("/technologies/all/license-history", ctrl.GetLicenseComplianceHistory).Methods("GET")
+	router.Handle("/technologies/all/license-history",
+		middleware.Location(ctrl.LocationsService)(http.HandlerFunc(ctrl.GetLicenseComplianceHistory))).Methods("GET")
+	router.Handle("/technologies/all/license-forecast",
+		middleware.Location(ctrl.LocationsService)(http.HandlerFunc(ctrl.GetLicenseComplianceForecast))).Methods("GET")
 	router.HandleFunc("/technologies/oracle/database", ctrl.GetOracleDatabaseChart).Methods("GET")
 
 	router.HandleFunc("/technologies/changes", ctrl.GetChangeChart).Methods("GET")
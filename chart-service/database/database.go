@@ -42,7 +42,8 @@ type MongoDatabaseInterface interface {
 	GetOracleDatabaseChartByVersion(location string, environment string, olderThan time.Time) ([]dto.ChartBubble, error)
 	// GetOracleDatabaseChartByWork return the chart data about the work of all database
 	GetOracleDatabaseChartByWork(location string, environment string, olderThan time.Time) ([]dto.ChartBubble, error)
-	GetLicenseComplianceHistory(start, end time.Time) ([]dto.LicenseComplianceHistory, error)
+	// GetLicenseComplianceHistory return the history of the locations, or the global one if locations is empty
+	GetLicenseComplianceHistory(start, end time.Time, locations []string) ([]dto.LicenseComplianceHistory, error)
 
 	GetHostCores(location, environment string, olderThan, newerThan time.Time) ([]dto.HostCores, error)
 }
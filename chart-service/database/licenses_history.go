@@ -26,11 +26,17 @@ import (
 	"github.com/ercole-io/ercole/v2/utils"
 )
 
-func (md *MongoDatabase) GetLicenseComplianceHistory(start, end time.Time) ([]dto.LicenseComplianceHistory, error) {
+func (md *MongoDatabase) GetLicenseComplianceHistory(start, end time.Time, locations []string) ([]dto.LicenseComplianceHistory, error) {
+	location := bson.D{{Key: "$exists", Value: false}}
+	if len(locations) > 0 {
+		location = bson.D{{Key: "$in", Value: locations}}
+	}
+
 	pipeline := bson.A{
 		bson.D{
 			{Key: "$match",
 				Value: bson.D{
+					{Key: "location", Value: location},
 					{Key: "history.date", Value: bson.D{{Key: "$gt", Value: start}}},
 					{Key: "history.date", Value: bson.D{{Key: "$lt", Value: end}}},
 				},
@@ -34,7 +34,7 @@ func (m *MongodbSuite) TestGetLicenseComplianceHistory() {
 		start := utils.MIN_TIME
 		end := utils.MAX_TIME
 
-		out, err := m.db.GetLicenseComplianceHistory(start, end)
+		out, err := m.db.GetLicenseComplianceHistory(start, end, nil)
 		m.Require().NoError(err)
 
 		expectedOut := []dto.LicenseComplianceHistory{
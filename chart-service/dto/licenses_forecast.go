@@ -0,0 +1,56 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dto
+
+import (
+	"time"
+)
+
+const (
+	// LicenseForecastModelNone is used when the history hasn't enough samples to fit a trend
+	LicenseForecastModelNone = "none"
+	// LicenseForecastModelLinear is a least squares linear trend
+	LicenseForecastModelLinear = "linear"
+	// LicenseForecastModelLinearWeekly is a least squares linear trend with a weekly seasonality
+	LicenseForecastModelLinearWeekly = "linear+weekly"
+)
+
+type LicenseComplianceForecast struct {
+	LicenseTypeID   string `json:"licenseTypeID"`
+	ItemDescription string `json:"itemDescription"`
+	Metric          string `json:"metric"`
+	Model           string `json:"model"`
+	Samples         int    `json:"samples"`
+	// Trend is the number of consumed licenses gained per day
+	Trend     float64 `json:"trend"`
+	Consumed  float64 `json:"consumed"`
+	Purchased float64 `json:"purchased"`
+	// BreachDate is the first day in which the consumed licenses are predicted to exceed the purchased ones
+	BreachDate *time.Time `json:"breachDate"`
+	// EarliestBreachDate is the breach date according to the upper bound of the confidence band
+	EarliestBreachDate *time.Time `json:"earliestBreachDate"`
+	// LatestBreachDate is the breach date according to the lower bound of the confidence band
+	LatestBreachDate *time.Time                       `json:"latestBreachDate"`
+	Forecast         []LicenseComplianceForecastValue `json:"forecast"`
+}
+
+type LicenseComplianceForecastValue struct {
+	Date      time.Time `json:"date"`
+	Consumed  float64   `json:"consumed"`
+	Lower     float64   `json:"lower"`
+	Upper     float64   `json:"upper"`
+	Purchased float64   `json:"purchased"`
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+
+	"github.com/ercole-io/ercole/v2/chart-service/dto"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/ercole-io/ercole/v2/utils/exutils"
+)
+
+const (
+	// MaxLicenseForecastHorizon is the max number of days that can be forecasted
+	MaxLicenseForecastHorizon = 1095
+	// licenseForecastZ is the z-score of the 95% confidence band
+	licenseForecastZ = 1.96
+	// licenseForecastSeason is the period, in days, of the seasonal component
+	licenseForecastSeason = 7
+	// licenseForecastIterations is the number of backfitting iterations of trend and seasonality
+	licenseForecastIterations = 50
+)
+
+// GetLicenseComplianceForecast return the projection of consumed licenses for the next horizon days,
+// fitted on the daily licenses compliance history of the comma separated locations
+func (as *ChartService) GetLicenseComplianceForecast(horizon int, location string) ([]dto.LicenseComplianceForecast, error) {
+	if horizon < 1 || horizon > MaxLicenseForecastHorizon {
+		return nil, utils.NewErrorf("%w: %d, it must be between 1 and %d days", utils.ErrInvalidForecastHorizon, horizon, MaxLicenseForecastHorizon)
+	}
+
+	now := as.TimeNow()
+
+	licenses, err := as.GetLicenseComplianceHistory(utils.MIN_TIME, now, location)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	forecasts := make([]dto.LicenseComplianceForecast, 0, len(licenses))
+	for _, license := range licenses {
+		forecasts = append(forecasts, forecastLicenseCompliance(license, today, horizon))
+	}
+
+	return forecasts, nil
+}
+
+// GetLicenseComplianceForecastAsXLSX return the licenses forecast as xlsx, with a summary sheet and a sheet with daily values
+func (as *ChartService) GetLicenseComplianceForecastAsXLSX(horizon int, location string) (*excelize.File, error) {
+	forecasts, err := as.GetLicenseComplianceForecast(horizon, location)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := "Forecast"
+	headers := []string{
+		"License Type ID",
+		"Description",
+		"Metric",
+		"Model",
+		"Consumed",
+		"Purchased",
+		"Trend per day",
+		"Breach Date",
+		"Earliest Breach Date",
+		"Latest Breach Date",
+	}
+
+	file, err := exutils.NewXLSX(as.Config, sheet, headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	axisHelp := exutils.NewAxisHelper(1)
+
+	for _, val := range forecasts {
+		nextAxis := axisHelp.NewRow()
+		file.SetCellValue(sheet, nextAxis(), val.LicenseTypeID)
+		file.SetCellValue(sheet, nextAxis(), val.ItemDescription)
+		file.SetCellValue(sheet, nextAxis(), val.Metric)
+		file.SetCellValue(sheet, nextAxis(), val.Model)
+		file.SetCellValue(sheet, nextAxis(), val.Consumed)
+		file.SetCellValue(sheet, nextAxis(), val.Purchased)
+		file.SetCellValue(sheet, nextAxis(), val.Trend)
+		file.SetCellValue(sheet, nextAxis(), formatForecastDate(val.BreachDate))
+		file.SetCellValue(sheet, nextAxis(), formatForecastDate(val.EarliestBreachDate))
+		file.SetCellValue(sheet, nextAxis(), formatForecastDate(val.LatestBreachDate))
+	}
+
+	valuesSheet := "Forecast values"
+	file.NewSheet(valuesSheet)
+
+	valuesAxisHelp := exutils.NewAxisHelper(0)
+	valuesAxisHelp.NewRowAndFill(file, valuesSheet, "License Type ID", "Description", "Date", "Consumed", "Lower", "Upper", "Purchased")
+
+	for _, val := range forecasts {
+		for _, v := range val.Forecast {
+			nextAxis := valuesAxisHelp.NewRow()
+			file.SetCellValue(valuesSheet, nextAxis(), val.LicenseTypeID)
+			file.SetCellValue(valuesSheet, nextAxis(), val.ItemDescription)
+			file.SetCellValue(valuesSheet, nextAxis(), v.Date.Format("2006-01-02"))
+			file.SetCellValue(valuesSheet, nextAxis(), v.Consumed)
+			file.SetCellValue(valuesSheet, nextAxis(), v.Lower)
+			file.SetCellValue(valuesSheet, nextAxis(), v.Upper)
+			file.SetCellValue(valuesSheet, nextAxis(), v.Purchased)
+		}
+	}
+
+	return file, nil
+}
+
+func formatForecastDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+
+	return date.Format("2006-01-02")
+}
+
+// forecastLicenseCompliance project the consumed licenses from the day after today for horizon days.
+// Purchased licenses are assumed to stay the same of the last day of the history
+func forecastLicenseCompliance(license dto.LicenseComplianceHistory, today time.Time, horizon int) dto.LicenseComplianceForecast {
+	forecast := dto.LicenseComplianceForecast{
+		LicenseTypeID:   license.LicenseTypeID,
+		ItemDescription: license.ItemDescription,
+		Metric:          license.Metric,
+		Model:           dto.LicenseForecastModelNone,
+		Samples:         len(license.History),
+		Forecast:        []dto.LicenseComplianceForecastValue{},
+	}
+
+	if len(license.History) == 0 {
+		return forecast
+	}
+
+	last := license.History[len(license.History)-1]
+	forecast.Consumed = last.Consumed
+	forecast.Purchased = last.Purchased
+
+	if last.Consumed > last.Purchased {
+		breach := last.Date
+		forecast.BreachDate, forecast.EarliestBreachDate, forecast.LatestBreachDate = &breach, &breach, &breach
+	}
+
+	trend, ok := fitLicenseTrend(license.History)
+	if !ok {
+		return forecast
+	}
+
+	forecast.Model = trend.model
+	forecast.Trend = trend.slope
+
+	for i := 1; i <= horizon; i++ {
+		date := today.AddDate(0, 0, i)
+		consumed, lower, upper := trend.predict(date)
+
+		forecast.Forecast = append(forecast.Forecast, dto.LicenseComplianceForecastValue{
+			Date:      date,
+			Consumed:  consumed,
+			Lower:     lower,
+			Upper:     upper,
+			Purchased: last.Purchased,
+		})
+
+		if forecast.BreachDate == nil && consumed > last.Purchased {
+			forecast.BreachDate = &forecast.Forecast[i-1].Date
+		}
+
+		if forecast.EarliestBreachDate == nil && upper > last.Purchased {
+			forecast.EarliestBreachDate = &forecast.Forecast[i-1].Date
+		}
+
+		if forecast.LatestBreachDate == nil && lower > last.Purchased {
+			forecast.LatestBreachDate = &forecast.Forecast[i-1].Date
+		}
+	}
+
+	return forecast
+}
+
+// licenseTrend is a least squares linear trend of the consumed licenses,
+// optionally corrected by the mean residual of each day of the week
+type licenseTrend struct {
+	model     string
+	origin    time.Time
+	samples   int
+	intercept float64
+	slope     float64
+	meanX     float64
+	sxx       float64
+	seasonal  [licenseForecastSeason]float64
+	stdDev    float64
+}
+
+func fitLicenseTrend(history []dto.LicenseComplianceHistoricValue) (licenseTrend, bool) {
+	trend := licenseTrend{
+		model:   dto.LicenseForecastModelLinear,
+		samples: len(history),
+	}
+
+	if len(history) < 2 {
+		return trend, false
+	}
+
+	trend.origin = history[0].Date
+
+	xs := make([]float64, len(history))
+	for i, val := range history {
+		xs[i] = trend.days(val.Date)
+	}
+
+	params := 2
+	iterations := 1
+
+	var counts [licenseForecastSeason]int
+
+	if xs[len(xs)-1]-xs[0] >= 2*licenseForecastSeason && len(history) >= 2*licenseForecastSeason {
+		for _, x := range xs {
+			counts[trend.season(x)]++
+		}
+
+		for _, c := range counts {
+			if c > 0 {
+				params++
+			}
+		}
+
+		trend.model = dto.LicenseForecastModelLinearWeekly
+		params--
+		iterations = licenseForecastIterations
+	}
+
+	// trend and seasonality are fitted together by backfitting:
+	// the line is fitted on deseasonalized values, the seasonality on detrended ones
+	for it := 0; it < iterations; it++ {
+		deseasonalized := make([]float64, len(history))
+		for i, val := range history {
+			deseasonalized[i] = val.Consumed - trend.seasonal[trend.season(xs[i])]
+		}
+
+		if !trend.fitLine(xs, deseasonalized) {
+			return trend, false
+		}
+
+		if trend.model != dto.LicenseForecastModelLinearWeekly {
+			break
+		}
+
+		var sums [licenseForecastSeason]float64
+		for i, val := range history {
+			sums[trend.season(xs[i])] += val.Consumed - (trend.intercept + trend.slope*xs[i])
+		}
+
+		var total float64
+		var seasons int
+
+		for s := range sums {
+			if counts[s] > 0 {
+				trend.seasonal[s] = sums[s] / float64(counts[s])
+				total += trend.seasonal[s]
+				seasons++
+			}
+		}
+
+		for s := range trend.seasonal {
+			if counts[s] > 0 {
+				trend.seasonal[s] -= total / float64(seasons)
+			}
+		}
+	}
+
+	residuals := make([]float64, len(history))
+	for i, val := range history {
+		residuals[i] = val.Consumed - (trend.intercept + trend.slope*xs[i] + trend.seasonal[trend.season(xs[i])])
+	}
+
+	if len(history) > params {
+		var sse float64
+		for _, r := range residuals {
+			sse += r * r
+		}
+
+		trend.stdDev = math.Sqrt(sse / float64(len(history)-params))
+	}
+
+	return trend, true
+}
+
+// fitLine fit with least squares the line of ys over xs
+func (t *licenseTrend) fitLine(xs, ys []float64) bool {
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+
+	n := float64(len(xs))
+	t.meanX = sumX / n
+	meanY := sumY / n
+
+	var sxy float64
+
+	t.sxx = 0
+	for i := range xs {
+		t.sxx += (xs[i] - t.meanX) * (xs[i] - t.meanX)
+		sxy += (xs[i] - t.meanX) * (ys[i] - meanY)
+	}
+
+	if t.sxx == 0 {
+		return false
+	}
+
+	t.slope = sxy / t.sxx
+	t.intercept = meanY - t.slope*t.meanX
+
+	return true
+}
+
+// days return the number of days between the origin of the trend and date
+func (t licenseTrend) days(date time.Time) float64 {
+	return math.Round(date.Sub(t.origin).Hours() / 24)
+}
+
+func (t licenseTrend) season(x float64) int {
+	return int(math.Mod(x, licenseForecastSeason))
+}
+
+// predict return the predicted consumed licenses at date and its 95% prediction interval
+func (t licenseTrend) predict(date time.Time) (value, lower, upper float64) {
+	x := t.days(date)
+	value = t.intercept + t.slope*x + t.seasonal[t.season(x)]
+
+	margin := licenseForecastZ * t.stdDev * math.Sqrt(1+1/float64(t.samples)+(x-t.meanX)*(x-t.meanX)/t.sxx)
+	lower, upper = value-margin, value+margin
+
+	return math.Max(value, 0), math.Max(lower, 0), math.Max(upper, 0)
+}
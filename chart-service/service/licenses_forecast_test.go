@@ -0,0 +1,182 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/chart-service/dto"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func linearHistory(origin time.Time, days int, intercept, slope, purchased float64) []dto.LicenseComplianceHistoricValue {
+	history := make([]dto.LicenseComplianceHistoricValue, 0, days)
+	for i := 0; i < days; i++ {
+		history = append(history, dto.LicenseComplianceHistoricValue{
+			Date:      origin.AddDate(0, 0, i),
+			Consumed:  intercept + slope*float64(i),
+			Purchased: purchased,
+		})
+	}
+
+	return history
+}
+
+func TestFitLicenseTrend_Linear(t *testing.T) {
+	origin := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	trend, ok := fitLicenseTrend(linearHistory(origin, 10, 10, 2, 40))
+	require.True(t, ok)
+
+	assert.Equal(t, dto.LicenseForecastModelLinear, trend.model)
+	assert.InDelta(t, 2, trend.slope, 1e-9)
+	assert.InDelta(t, 10, trend.intercept, 1e-9)
+	assert.InDelta(t, 0, trend.stdDev, 1e-9)
+
+	value, lower, upper := trend.predict(origin.AddDate(0, 0, 20))
+	assert.InDelta(t, 50, value, 1e-9)
+	assert.InDelta(t, 50, lower, 1e-9)
+	assert.InDelta(t, 50, upper, 1e-9)
+}
+
+func TestFitLicenseTrend_Weekly(t *testing.T) {
+	origin := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	history := linearHistory(origin, 28, 20, 0.5, 100)
+	for i := range history {
+		if i%7 >= 5 {
+			history[i].Consumed -= 7
+		} else {
+			history[i].Consumed += 2.8
+		}
+	}
+
+	trend, ok := fitLicenseTrend(history)
+	require.True(t, ok)
+
+	assert.Equal(t, dto.LicenseForecastModelLinearWeekly, trend.model)
+	assert.InDelta(t, 0.5, trend.slope, 0.05)
+
+	weekday, _, _ := trend.predict(origin.AddDate(0, 0, 29))
+	weekend, _, _ := trend.predict(origin.AddDate(0, 0, 33))
+	assert.InDelta(t, 9.8, weekday-weekend+4*trend.slope, 0.1)
+	assert.Less(t, trend.stdDev, 1.0)
+}
+
+func TestFitLicenseTrend_NotEnoughSamples(t *testing.T) {
+	origin := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, ok := fitLicenseTrend(linearHistory(origin, 1, 10, 0, 40))
+	assert.False(t, ok)
+}
+
+func TestForecastLicenseCompliance(t *testing.T) {
+	origin := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	today := origin.AddDate(0, 0, 9)
+
+	t.Run("Breach in the horizon", func(t *testing.T) {
+		history := linearHistory(origin, 10, 10, 2, 40)
+		history[4].Consumed += 1
+		history[5].Consumed -= 1
+
+		license := dto.LicenseComplianceHistory{
+			LicenseTypeID:   "A90611",
+			ItemDescription: "Oracle Database Enterprise Edition",
+			Metric:          "Processor Perpetual",
+			History:         history,
+		}
+
+		actual := forecastLicenseCompliance(license, today, 30)
+
+		assert.Equal(t, "A90611", actual.LicenseTypeID)
+		assert.Equal(t, dto.LicenseForecastModelLinear, actual.Model)
+		assert.Equal(t, 10, actual.Samples)
+		assert.Equal(t, float64(28), actual.Consumed)
+		assert.Equal(t, float64(40), actual.Purchased)
+		require.Len(t, actual.Forecast, 30)
+		assert.Equal(t, today.AddDate(0, 0, 1), actual.Forecast[0].Date)
+
+		require.NotNil(t, actual.BreachDate)
+		assert.Equal(t, origin.AddDate(0, 0, 16), *actual.BreachDate)
+		require.NotNil(t, actual.EarliestBreachDate)
+		require.NotNil(t, actual.LatestBreachDate)
+		assert.False(t, actual.EarliestBreachDate.After(*actual.BreachDate))
+		assert.False(t, actual.LatestBreachDate.Before(*actual.BreachDate))
+
+		for _, v := range actual.Forecast {
+			assert.LessOrEqual(t, v.Lower, v.Consumed)
+			assert.GreaterOrEqual(t, v.Upper, v.Consumed)
+			assert.Equal(t, float64(40), v.Purchased)
+		}
+	})
+
+	t.Run("No breach", func(t *testing.T) {
+		license := dto.LicenseComplianceHistory{
+			LicenseTypeID: "A90611",
+			History:       linearHistory(origin, 10, 30, -1, 40),
+		}
+
+		actual := forecastLicenseCompliance(license, today, 30)
+
+		assert.Nil(t, actual.BreachDate)
+		assert.Nil(t, actual.EarliestBreachDate)
+		assert.Nil(t, actual.LatestBreachDate)
+		assert.Equal(t, float64(0), actual.Forecast[29].Consumed)
+	})
+
+	t.Run("Already in breach", func(t *testing.T) {
+		license := dto.LicenseComplianceHistory{
+			LicenseTypeID: "A90611",
+			History:       linearHistory(origin, 10, 50, 0, 40),
+		}
+
+		actual := forecastLicenseCompliance(license, today, 30)
+
+		require.NotNil(t, actual.BreachDate)
+		assert.Equal(t, today, *actual.BreachDate)
+		assert.Equal(t, today, *actual.EarliestBreachDate)
+		assert.Equal(t, today, *actual.LatestBreachDate)
+	})
+
+	t.Run("Not enough samples", func(t *testing.T) {
+		license := dto.LicenseComplianceHistory{
+			LicenseTypeID: "A90611",
+			History:       linearHistory(origin, 1, 10, 0, 40),
+		}
+
+		actual := forecastLicenseCompliance(license, today, 30)
+
+		assert.Equal(t, dto.LicenseForecastModelNone, actual.Model)
+		assert.Empty(t, actual.Forecast)
+		assert.Nil(t, actual.BreachDate)
+	})
+}
+
+func TestGetLicenseComplianceForecast_InvalidHorizon(t *testing.T) {
+	as := ChartService{
+		TimeNow: utils.Btc(utils.P("2024-03-10T10:00:00Z")),
+	}
+
+	for _, horizon := range []int{0, -1, MaxLicenseForecastHorizon + 1} {
+		_, err := as.GetLicenseComplianceForecast(horizon, "")
+		assert.True(t, errors.Is(err, utils.ErrInvalidForecastHorizon))
+	}
+}
@@ -18,14 +18,20 @@ package service
 
 import (
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/ercole-io/ercole/v2/chart-service/dto"
 	"github.com/ercole-io/ercole/v2/model"
 )
 
-func (as *ChartService) GetLicenseComplianceHistory(start, end time.Time) ([]dto.LicenseComplianceHistory, error) {
-	licenses, err := as.Database.GetLicenseComplianceHistory(start, end)
+func (as *ChartService) GetLicenseComplianceHistory(start, end time.Time, location string) ([]dto.LicenseComplianceHistory, error) {
+	locations := []string{}
+	if location != "" && !strings.EqualFold(location, model.AllLocation) {
+		locations = strings.Split(location, ",")
+	}
+
+	licenses, err := as.Database.GetLicenseComplianceHistory(start, end, locations)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +64,7 @@ func (as *ChartService) GetLicenseComplianceHistory(start, end time.Time) ([]dto
 		license.History = sortAndKeepOnlyLastEntryOfEachDay(license.History)
 	}
 
+	licenses = mergeLocationsLicensesCompliance(licenses)
 	licenses = mergeMySqlLicensesCompliance(licenses, mySqlTypes)
 	licenses = mergeSqlServerLicensesCompliance(licenses, sqlServerTypes)
 	licenses = removeEmptyLicensesCompliance(licenses)
@@ -163,6 +170,31 @@ func mergeSqlServerLicensesCompliance(licenses []dto.LicenseComplianceHistory, s
 	return append(licenses, *sqlServer)
 }
 
+// mergeLocationsLicensesCompliance sum the histories of the same license in different locations
+func mergeLocationsLicensesCompliance(licenses []dto.LicenseComplianceHistory) []dto.LicenseComplianceHistory {
+	result := make([]dto.LicenseComplianceHistory, 0, len(licenses))
+	indexes := make(map[string]int)
+
+	for _, license := range licenses {
+		key := license.LicenseTypeID
+		if key == "" {
+			key = "description:" + license.ItemDescription
+		}
+
+		i, ok := indexes[key]
+		if !ok {
+			indexes[key] = len(result)
+			result = append(result, license)
+
+			continue
+		}
+
+		result[i].History = mergeLicenseComplianceHistoricValues(result[i].History, license.History)
+	}
+
+	return result
+}
+
 func mergeLicenseComplianceHistoricValues(a, b []dto.LicenseComplianceHistoricValue) []dto.LicenseComplianceHistoricValue {
 	merged := make([]dto.LicenseComplianceHistoricValue, 0)
 
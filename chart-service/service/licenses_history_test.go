@@ -542,3 +542,48 @@ func TestMergeLicenseComplianceHistoricValues(t *testing.T) {
 		assert.Equal(t, testCase.expected, actual)
 	}
 }
+
+func TestMergeLocationsLicensesCompliance(t *testing.T) {
+	day1 := time.Date(2021, 6, 15, 0, 0, 0, 0, time.Local)
+	day2 := time.Date(2021, 6, 16, 0, 0, 0, 0, time.Local)
+
+	input := []dto.LicenseComplianceHistory{
+		{
+			LicenseTypeID: "A90611",
+			History: []dto.LicenseComplianceHistoricValue{
+				{Date: day1, Consumed: 1, Covered: 1, Purchased: 2},
+				{Date: day2, Consumed: 2, Covered: 1, Purchased: 2},
+			},
+		},
+		{
+			LicenseTypeID: "L47247",
+			History: []dto.LicenseComplianceHistoricValue{
+				{Date: day1, Consumed: 5, Covered: 5, Purchased: 5},
+			},
+		},
+		{
+			LicenseTypeID: "A90611",
+			History: []dto.LicenseComplianceHistoricValue{
+				{Date: day2, Consumed: 3, Covered: 3, Purchased: 4},
+			},
+		},
+	}
+
+	expected := []dto.LicenseComplianceHistory{
+		{
+			LicenseTypeID: "A90611",
+			History: []dto.LicenseComplianceHistoricValue{
+				{Date: day1, Consumed: 1, Covered: 1, Purchased: 2},
+				{Date: day2, Consumed: 5, Covered: 4, Purchased: 6},
+			},
+		},
+		{
+			LicenseTypeID: "L47247",
+			History: []dto.LicenseComplianceHistoricValue{
+				{Date: day1, Consumed: 5, Covered: 5, Purchased: 5},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, mergeLocationsLicensesCompliance(input))
+}
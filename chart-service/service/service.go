@@ -20,6 +20,8 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/360EntSecGroup-Skylar/excelize"
+
 	apiservice_client "github.com/ercole-io/ercole/v2/api-service/client"
 	"github.com/ercole-io/ercole/v2/chart-service/database"
 	"github.com/ercole-io/ercole/v2/chart-service/dto"
@@ -38,7 +40,12 @@ type ChartServiceInterface interface {
 
 	// GetOracleDatabaseChart return a chart associated to teh
 	GetOracleDatabaseChart(metric string, location string, environment string, olderThan time.Time) (dto.Chart, error)
-	GetLicenseComplianceHistory(start, end time.Time) ([]dto.LicenseComplianceHistory, error)
+	// GetLicenseComplianceHistory return the licenses compliance history of the comma separated locations, summed, or the global one
+	GetLicenseComplianceHistory(start, end time.Time, location string) ([]dto.LicenseComplianceHistory, error)
+	// GetLicenseComplianceForecast return the projection of consumed and purchased licenses for the next horizon days
+	GetLicenseComplianceForecast(horizon int, location string) ([]dto.LicenseComplianceForecast, error)
+	// GetLicenseComplianceForecastAsXLSX return the licenses forecast as xlsx
+	GetLicenseComplianceForecastAsXLSX(horizon int, location string) (*excelize.File, error)
 
 	// GetTechnologiesMetrics return metrics of all technologies
 	GetTechnologiesMetrics() (map[string]model.TechnologySupportedMetrics, error)
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ercole-io/ercole/v2/logger"
+	thunderservice_database "github.com/ercole-io/ercole/v2/thunder-service/database"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+var cloudSecretsCmd = &cobra.Command{
+	Use:   "cloud-secrets",
+	Short: "Manage the encryption of the cloud credentials",
+	Long: `Manage the master keys used by the thunder-service to encrypt the credentials of the cloud profiles.
+The current master key is set in ThunderService.CloudSecrets.Key, or in the file ThunderService.CloudSecrets.KeyFile.`,
+}
+
+var cloudSecretsGenerateKeyCmd = &cobra.Command{
+	Use:   "generate-key",
+	Short: "Generate a new master key",
+	Long:  `Generate a new random master key, base64 encoded`,
+	Run: func(_ *cobra.Command, _ []string) {
+		key, err := cr.GenerateSecretsKey()
+		if err != nil {
+			logger.NewLogger("THUN").Fatal(err)
+		}
+
+		fmt.Println(key)
+	},
+}
+
+var cloudSecretsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Encrypt the cloud credentials with the current master key",
+	Long: `Encrypt all the credentials of the cloud profiles with the current master key, including the ones stored in clear.
+To rotate the master key, set the new one as ThunderService.CloudSecrets.Key and move the old one in
+ThunderService.CloudSecrets.PreviousKeys, restart the thunder-service and run this command.
+When it completes the old key can be removed from PreviousKeys.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		log := logger.NewLogger("THUN", logger.LogVerbosely(verbose))
+
+		db := &thunderservice_database.MongoDatabase{
+			Config:  ercoleConfig,
+			TimeNow: time.Now,
+			Log:     log,
+		}
+		db.Init()
+
+		if db.Keyring == nil {
+			log.Fatal("No master key configured in ThunderService.CloudSecrets")
+		}
+
+		updated, err := db.RotateCloudSecrets()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Infof("%d cloud credentials encrypted with the master key %s", updated, db.Keyring.CurrentKeyID())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cloudSecretsCmd)
+	cloudSecretsCmd.AddCommand(cloudSecretsGenerateKeyCmd)
+	cloudSecretsCmd.AddCommand(cloudSecretsRotateCmd)
+}
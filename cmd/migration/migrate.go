@@ -19,6 +19,7 @@ import (
 	"errors"
 
 	"github.com/spf13/cobra"
+	migrate "github.com/xakep666/mongo-migrate"
 
 	"github.com/ercole-io/ercole/v2/config"
 	migration "github.com/ercole-io/ercole/v2/database-migration"
@@ -26,14 +27,27 @@ import (
 	"github.com/ercole-io/ercole/v2/utils"
 )
 
-// NewMigrateCmd represents the migrate command
 func NewMigrateCmd(conf *config.Configuration) *cobra.Command {
-	return &cobra.Command{
+	var dryRun bool
+
+	cmd := &cobra.Command{
 		Use:   "migrate",
 		Short: "Migrate the database",
 		Long:  `Migrate the database to the latest known version`,
 		Run: func(command *cobra.Command, args []string) {
 			log := logger.NewLogger("SERV")
+			setCloudSecrets(log, conf)
+
+			if dryRun {
+				pending, err := migration.MigrateUp(conf.Mongodb, 0, true)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				logMigrations(log, "Pending migrations", pending)
+
+				return
+			}
 
 			err := migration.Migrate(conf.Mongodb)
 			if err != nil && errors.Is(err, utils.ErrConnDB) {
@@ -44,4 +58,94 @@ func NewMigrateCmd(conf *config.Configuration) *cobra.Command {
 			log.Info("Migrate successfully")
 		},
 	}
+
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "List the migrations to run without changing the database")
+
+	cmd.AddCommand(newMigrateUpCmd(conf, &dryRun))
+	cmd.AddCommand(newMigrateDownCmd(conf, &dryRun))
+
+	return cmd
+}
+
+func newMigrateUpCmd(conf *config.Configuration, dryRun *bool) *cobra.Command {
+	var to uint64
+
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply the migrations",
+		Long:  `Apply the migrations up to the version specified by --to, or up to the latest one`,
+		Run: func(command *cobra.Command, args []string) {
+			log := logger.NewLogger("SERV")
+			setCloudSecrets(log, conf)
+
+			migrations, err := migration.MigrateUp(conf.Mongodb, to, *dryRun)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if *dryRun {
+				logMigrations(log, "Pending migrations", migrations)
+				return
+			}
+
+			logMigrations(log, "Applied migrations", migrations)
+		},
+	}
+
+	cmd.Flags().Uint64Var(&to, "to", 0, "Version to migrate to, the latest one if not set")
+
+	return cmd
+}
+
+func newMigrateDownCmd(conf *config.Configuration, dryRun *bool) *cobra.Command {
+	var to uint64
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the migrations",
+		Long:  `Roll back the migrations down to the version specified by --to`,
+		Run: func(command *cobra.Command, args []string) {
+			log := logger.NewLogger("SERV")
+			setCloudSecrets(log, conf)
+
+			migrations, err := migration.MigrateDown(conf.Mongodb, to, *dryRun)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if *dryRun {
+				logMigrations(log, "Migrations to roll back", migrations)
+				return
+			}
+
+			logMigrations(log, "Rolled back migrations", migrations)
+		},
+	}
+
+	cmd.Flags().Uint64Var(&to, "to", 0, "Version to roll back to")
+
+	if err := cmd.MarkFlagRequired("to"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func logMigrations(log logger.Logger, title string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		log.Infof("%s: none", title)
+		return
+	}
+
+	log.Infof("%s:", title)
+
+	for _, m := range migrations {
+		log.Infof("%d %s", m.Version, m.Description)
+	}
+}
+
+func setCloudSecrets(log logger.Logger, conf *config.Configuration) {
+	if err := migration.SetCloudSecrets(conf.ThunderService.CloudSecrets); err != nil {
+		log.Fatal(err)
+	}
 }
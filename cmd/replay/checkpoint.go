@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replay
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Checkpoint records the names of the payloads already replayed, one per line,
+// so an interrupted or partially failed replay can be resumed
+type Checkpoint struct {
+	mutex sync.Mutex
+	file  *os.File
+	done  map[string]bool
+}
+
+// OpenCheckpoint read the payloads already replayed from path, creating it if it doesn't exist
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{done: make(map[string]bool)}
+
+	if in, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				c.done[line] = true
+			}
+		}
+
+		in.Close()
+
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("Can't read the checkpoint %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	c.file = file
+
+	return c, nil
+}
+
+// Done return true if the payload was already replayed
+func (c *Checkpoint) Done(name string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.done[name]
+}
+
+// MarkDone record the payload as replayed
+func (c *Checkpoint) MarkDone(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.done[name] {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(c.file, name); err != nil {
+		return err
+	}
+
+	c.done[name] = true
+
+	return c.file.Sync()
+}
+
+// Close the checkpoint file
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
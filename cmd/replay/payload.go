@@ -0,0 +1,228 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replay
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Payload is a saved agent hostdata to replay
+type Payload struct {
+	// Name identifies the payload in the source, it's the path relative to the directory or the name in the archive
+	Name string
+	// Path is the file from which the payload is read
+	Path       string
+	Hostname   string
+	CapturedAt time.Time
+}
+
+// LoadPayloads return the .json payloads of a directory or of a tar archive, optionally gzipped,
+// sorted by capture time. The capture time is the createdAt field of the payload if present,
+// otherwise the modification time of the file. The payloads of an archive are extracted in a
+// temporary directory, removed by the returned cleanup func
+func LoadPayloads(source string) ([]Payload, func(), error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var payloads []Payload
+
+	cleanup := func() {}
+
+	if info.IsDir() {
+		payloads, err = loadDirPayloads(source)
+	} else {
+		var tmpDir string
+
+		tmpDir, err = os.MkdirTemp("", "ercole-replay-")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cleanup = func() { os.RemoveAll(tmpDir) }
+
+		payloads, err = loadArchivePayloads(source, tmpDir)
+	}
+
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	sortPayloads(payloads)
+
+	return payloads, cleanup, nil
+}
+
+func loadDirPayloads(dir string) ([]Payload, error) {
+	payloads := make([]Payload, 0)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !isPayloadName(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		payload, err := newPayload(filepath.ToSlash(name), path, info.ModTime())
+		if err != nil {
+			return err
+		}
+
+		payloads = append(payloads, *payload)
+
+		return nil
+	})
+
+	return payloads, err
+}
+
+func loadArchivePayloads(archive, tmpDir string) ([]Payload, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	var in io.Reader = reader
+
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+
+		in = gz
+	}
+
+	payloads := make([]Payload, 0)
+	tr := tar.NewReader(in)
+
+	for i := 0; ; i++ {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Can't read the archive %s: %w", archive, err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !isPayloadName(filepath.Base(header.Name)) {
+			continue
+		}
+
+		path := filepath.Join(tmpDir, fmt.Sprintf("%06d.json", i))
+		if err := extract(tr, path); err != nil {
+			return nil, err
+		}
+
+		payload, err := newPayload(header.Name, path, header.ModTime)
+		if err != nil {
+			return nil, err
+		}
+
+		payloads = append(payloads, *payload)
+	}
+
+	return payloads, nil
+}
+
+func extract(r io.Reader, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func isPayloadName(name string) bool {
+	return !strings.HasPrefix(name, ".") && strings.EqualFold(filepath.Ext(name), ".json")
+}
+
+// newPayload read the hostname and the capture time of the payload.
+// Unparsable payloads are kept, their errors are reported when they are replayed
+func newPayload(name, path string, modTime time.Time) (*Payload, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{
+		Name:       name,
+		Path:       path,
+		CapturedAt: modTime.UTC(),
+	}
+
+	var header struct {
+		Hostname  string          `json:"hostname"`
+		CreatedAt json.RawMessage `json:"createdAt"`
+	}
+
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return payload, nil
+	}
+
+	payload.Hostname = header.Hostname
+
+	var createdAt time.Time
+	if err := json.Unmarshal(header.CreatedAt, &createdAt); err == nil && !createdAt.IsZero() {
+		payload.CapturedAt = createdAt.UTC()
+	}
+
+	return payload, nil
+}
+
+func sortPayloads(payloads []Payload) {
+	sort.SliceStable(payloads, func(i, j int) bool {
+		if !payloads[i].CapturedAt.Equal(payloads[j].CapturedAt) {
+			return payloads[i].CapturedAt.Before(payloads[j].CapturedAt)
+		}
+
+		return payloads[i].Name < payloads[j].Name
+	})
+}
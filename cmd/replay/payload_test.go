@@ -0,0 +1,134 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replay
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func writePayload(t *testing.T, path, content string, modTime time.Time) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestLoadPayloads_Directory(t *testing.T) {
+	dir := t.TempDir()
+
+	writePayload(t, filepath.Join(dir, "b.json"), `{"hostname": "host1"}`, utils.P("2024-01-02T10:00:00Z"))
+	writePayload(t, filepath.Join(dir, "sub", "a.json"), `{"hostname": "host2", "createdAt": "2024-01-01T08:00:00Z"}`,
+		utils.P("2024-03-01T10:00:00Z"))
+	writePayload(t, filepath.Join(dir, "c.json"), `not a json`, utils.P("2024-01-03T10:00:00Z"))
+	writePayload(t, filepath.Join(dir, "notes.txt"), `ignored`, utils.P("2024-01-01T00:00:00Z"))
+	writePayload(t, filepath.Join(dir, ".hidden.json"), `{"hostname": "host3"}`, utils.P("2024-01-01T00:00:00Z"))
+
+	payloads, cleanup, err := LoadPayloads(dir)
+	require.NoError(t, err)
+	defer cleanup()
+
+	expected := []Payload{
+		{Name: "sub/a.json", Path: filepath.Join(dir, "sub", "a.json"), Hostname: "host2", CapturedAt: utils.P("2024-01-01T08:00:00Z")},
+		{Name: "b.json", Path: filepath.Join(dir, "b.json"), Hostname: "host1", CapturedAt: utils.P("2024-01-02T10:00:00Z")},
+		{Name: "c.json", Path: filepath.Join(dir, "c.json"), CapturedAt: utils.P("2024-01-03T10:00:00Z")},
+	}
+	assert.Equal(t, expected, payloads)
+}
+
+func TestLoadPayloads_Archive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "payloads.tar.gz")
+
+	file, err := os.Create(archive)
+	require.NoError(t, err)
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range []struct {
+		name    string
+		content string
+		modTime time.Time
+	}{
+		{"payloads/host1-2.json", `{"hostname": "host1"}`, utils.P("2024-01-02T10:00:00Z")},
+		{"payloads/host1-1.json", `{"hostname": "host1"}`, utils.P("2024-01-01T10:00:00Z")},
+		{"payloads/README", `ignored`, utils.P("2024-01-01T10:00:00Z")},
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     entry.name,
+			Mode:     0o644,
+			Size:     int64(len(entry.content)),
+			ModTime:  entry.modTime,
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, file.Close())
+
+	payloads, cleanup, err := LoadPayloads(archive)
+	require.NoError(t, err)
+
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "payloads/host1-1.json", payloads[0].Name)
+	assert.Equal(t, utils.P("2024-01-01T10:00:00Z"), payloads[0].CapturedAt)
+	assert.Equal(t, "payloads/host1-2.json", payloads[1].Name)
+
+	raw, err := os.ReadFile(payloads[1].Path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hostname": "host1"}`, string(raw))
+
+	cleanup()
+
+	_, err = os.Stat(payloads[0].Path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay.checkpoint")
+
+	checkpoint, err := OpenCheckpoint(path)
+	require.NoError(t, err)
+	assert.False(t, checkpoint.Done("a.json"))
+
+	require.NoError(t, checkpoint.MarkDone("a.json"))
+	require.NoError(t, checkpoint.MarkDone("sub/b.json"))
+	require.NoError(t, checkpoint.MarkDone("a.json"))
+	require.NoError(t, checkpoint.Close())
+
+	checkpoint, err = OpenCheckpoint(path)
+	require.NoError(t, err)
+	defer checkpoint.Close()
+
+	assert.True(t, checkpoint.Done("a.json"))
+	assert.True(t, checkpoint.Done("sub/b.json"))
+	assert.False(t, checkpoint.Done("c.json"))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a.json\nsub/b.json\n", string(raw))
+}
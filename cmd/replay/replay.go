@@ -0,0 +1,163 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	alertservice_client "github.com/ercole-io/ercole/v2/alert-service/client"
+	apiservice_client "github.com/ercole-io/ercole/v2/api-service/client"
+	"github.com/ercole-io/ercole/v2/config"
+	dataservice_database "github.com/ercole-io/ercole/v2/data-service/database"
+	dataservice_service "github.com/ercole-io/ercole/v2/data-service/service"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+func NewReplayCmd(conf *config.Configuration) *cobra.Command {
+	var parallelism int
+
+	var checkpointPath string
+
+	var throwAlerts bool
+
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "replay <directory|archive>",
+		Short: "Replay saved hostdata",
+		Long: `Insert the .json agent payloads of a directory or of a tar archive, optionally gzipped, in the order they were captured.
+The capture time, that is the createdAt of the payload or otherwise the modification time of its file, is used as creation date of the hostdata.
+The payloads are inserted directly in the database, the api-service must be reachable. Payloads older than the current hostdata of their host are saved as history.
+The replayed payloads are recorded in the checkpoint file: running again the command resumes the replay skipping them.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(command *cobra.Command, args []string) {
+			log := logger.NewLogger("RPLY", logger.LogVerbosely(verbose))
+			source := filepath.Clean(args[0])
+
+			if checkpointPath == "" {
+				checkpointPath = source + ".checkpoint"
+			}
+
+			payloads, cleanup, err := LoadPayloads(source)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer cleanup()
+
+			checkpoint, err := OpenCheckpoint(checkpointPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer checkpoint.Close()
+
+			service := newHostDataService(*conf, throwAlerts, log)
+
+			replayer := &Replayer{
+				Insert:      service.ReplayHostData,
+				Parallelism: parallelism,
+				Checkpoint:  checkpoint,
+				Verbose:     verbose,
+				Out:         command.OutOrStdout(),
+				Log:         log,
+			}
+
+			summary := replayer.Run(payloads)
+			printSummary(command, summary, checkpointPath)
+
+			if summary.Invalid > 0 || summary.Failed > 0 {
+				cleanup()
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().IntVarP(&parallelism, "parallelism", "p", 4, "Number of hosts replayed in parallel")
+	cmd.Flags().StringVar(&checkpointPath, "checkpoint", "", "Checkpoint file (default <directory|archive>.checkpoint)")
+	cmd.Flags().BoolVar(&throwAlerts, "throw-alerts", false, "Throw the alerts raised by the replayed hostdata")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print the outcome of every payload")
+
+	return cmd
+}
+
+func newHostDataService(conf config.Configuration, throwAlerts bool, log logger.Logger) *dataservice_service.HostDataService {
+	db := &dataservice_database.MongoDatabase{
+		Config:  conf,
+		TimeNow: time.Now,
+		Log:     log,
+	}
+	db.Init()
+
+	if configDB, err := db.ReadConfig(); err == nil && configDB != nil {
+		conf = *configDB
+	}
+
+	var alertSvcClient alertservice_client.AlertSvcClientInterface = discardAlertsClient{}
+	if throwAlerts {
+		alertSvcClient = alertservice_client.NewClient(conf.AlertService)
+	}
+
+	return &dataservice_service.HostDataService{
+		Config:         conf,
+		ServerVersion:  conf.Version,
+		Database:       db,
+		AlertSvcClient: alertSvcClient,
+		ApiSvcClient:   apiservice_client.NewClient(conf.APIService),
+		TimeNow:        time.Now,
+		Log:            log,
+	}
+}
+
+// discardAlertsClient is used when the alerts aren't thrown during the replay
+type discardAlertsClient struct{}
+
+func (discardAlertsClient) ThrowNewAlert(alert model.Alert) error {
+	return nil
+}
+
+func printSummary(command *cobra.Command, summary Summary, checkpointPath string) {
+	out := command.OutOrStdout()
+
+	fmt.Fprintf(out, "\nReplayed %d payloads in %s\n", summary.Total, summary.Duration.Round(time.Second))
+	fmt.Fprintf(out, "  already done:    %d\n", summary.AlreadyDone)
+	fmt.Fprintf(out, "  replayed:        %d\n", summary.Replayed)
+	fmt.Fprintf(out, "  already present: %d\n", summary.AlreadyPresent)
+	fmt.Fprintf(out, "  invalid:         %d\n", summary.Invalid)
+	fmt.Fprintf(out, "  failed:          %d\n", summary.Failed)
+
+	if len(summary.ValidationErrors) > 0 {
+		fmt.Fprintf(out, "\nValidation errors:\n")
+
+		for _, e := range summary.ValidationErrors {
+			fmt.Fprintf(out, "%s: %s\n", e.Name, e.Error)
+		}
+	}
+
+	if len(summary.Failures) > 0 {
+		fmt.Fprintf(out, "\nFailures:\n")
+
+		for _, e := range summary.Failures {
+			fmt.Fprintf(out, "%s: %s\n", e.Name, e.Error)
+		}
+
+		fmt.Fprintf(out, "\nRun again the command to resume the replay, the checkpoint is %s\n", checkpointPath)
+	}
+}
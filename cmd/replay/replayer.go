@@ -0,0 +1,312 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/schema"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/ercole-io/ercole/v2/utils/sanitizer"
+)
+
+// progressEvery is the number of processed payloads between two progress lines
+const progressEvery = 100
+
+// Replayer insert the payloads in capture order. The payloads of different hosts are inserted in parallel,
+// the ones of the same host never concurrently. After a failure the remaining payloads of the host are
+// skipped, so they are inserted in order when the replay is resumed
+type Replayer struct {
+	Insert      func(hostdata model.HostDataBE, capturedAt time.Time) error
+	Parallelism int
+	Checkpoint  *Checkpoint
+	Verbose     bool
+	Out         io.Writer
+	Log         logger.Logger
+}
+
+// PayloadError is the error of a payload that wasn't replayed
+type PayloadError struct {
+	Name  string
+	Error string
+}
+
+// Summary of a replay
+type Summary struct {
+	Total int
+	// AlreadyDone are the payloads recorded in the checkpoint by a previous replay
+	AlreadyDone int
+	Replayed    int
+	// AlreadyPresent are the payloads whose hostdata was already in the database
+	AlreadyPresent   int
+	Invalid          int
+	Failed           int
+	ValidationErrors []PayloadError
+	Failures         []PayloadError
+	Duration         time.Duration
+}
+
+type outcome int
+
+const (
+	outcomeReplayed outcome = iota
+	outcomeAlreadyPresent
+	outcomeInvalid
+	outcomeFailed
+)
+
+// Run replay the payloads, that must be sorted by capture time
+func (r *Replayer) Run(payloads []Payload) Summary {
+	start := time.Now()
+	summary := Summary{Total: len(payloads)}
+
+	pending := make([]Payload, 0, len(payloads))
+
+	for _, p := range payloads {
+		if r.Checkpoint != nil && r.Checkpoint.Done(p.Name) {
+			summary.AlreadyDone++
+			continue
+		}
+
+		pending = append(pending, p)
+	}
+
+	s := newScheduler(pending)
+
+	var mutex sync.Mutex
+
+	processed := 0
+
+	record := func(p Payload, res outcome, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch res {
+		case outcomeReplayed:
+			summary.Replayed++
+		case outcomeAlreadyPresent:
+			summary.AlreadyPresent++
+		case outcomeInvalid:
+			summary.Invalid++
+			summary.ValidationErrors = append(summary.ValidationErrors, PayloadError{Name: p.Name, Error: err.Error()})
+		case outcomeFailed:
+			summary.Failed++
+			summary.Failures = append(summary.Failures, PayloadError{Name: p.Name, Error: err.Error()})
+		}
+
+		processed++
+
+		if r.Verbose {
+			status := "ok"
+			if err != nil {
+				status = err.Error()
+			}
+
+			r.printf("[%d/%d] %s %s: %s\n", processed, len(pending), p.Name, p.CapturedAt.Format(time.RFC3339), status)
+		} else if processed%progressEvery == 0 || processed == len(pending) {
+			r.printf("%d/%d payloads processed\n", processed, len(pending))
+		}
+	}
+
+	parallelism := r.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				p, skipped, ok := s.claim()
+				for _, sp := range skipped {
+					record(sp, outcomeFailed, fmt.Errorf("Skipped because a previous payload of %s failed", sp.Hostname))
+				}
+
+				if !ok {
+					return
+				}
+
+				res, err := r.replay(p)
+				record(p, res, err)
+				s.release(p, res != outcomeFailed)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	sortPayloadErrors(summary.ValidationErrors)
+	sortPayloadErrors(summary.Failures)
+	summary.Duration = time.Since(start)
+
+	return summary
+}
+
+func (r *Replayer) replay(p Payload) (outcome, error) {
+	hostdata, err := r.readHostdata(p)
+	if err != nil {
+		return outcomeInvalid, err
+	}
+
+	err = r.Insert(*hostdata, p.CapturedAt)
+
+	res := outcomeReplayed
+
+	if errors.Is(err, utils.ErrHostDataAlreadyExists) {
+		res = outcomeAlreadyPresent
+	} else if err != nil {
+		return outcomeFailed, err
+	}
+
+	if r.Checkpoint != nil {
+		if err := r.Checkpoint.MarkDone(p.Name); err != nil {
+			r.Log.Errorf("Can't update the checkpoint with %s: %v", p.Name, err)
+		}
+	}
+
+	return res, nil
+}
+
+// readHostdata sanitize, validate and upcast the payload as the data-service does
+func (r *Replayer) readHostdata(p Payload) (*model.HostDataBE, error) {
+	raw, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, utils.ErrInvalidJSON
+	}
+
+	sanitized, err := sanitizer.NewSanitizer(r.Log).Sanitize(m)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to sanitize: %w", err)
+	}
+
+	if raw, err = json.Marshal(sanitized); err != nil {
+		return nil, fmt.Errorf("Unable to marshal: %w", err)
+	}
+
+	if raw, _, err = schema.UpcastHostdata(raw); err != nil {
+		return nil, err
+	}
+
+	var hostdata model.HostDataBE
+	if err := json.Unmarshal(raw, &hostdata); err != nil {
+		return nil, err
+	}
+
+	return &hostdata, nil
+}
+
+func sortPayloadErrors(errs []PayloadError) {
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Name < errs[j].Name })
+}
+
+func (r *Replayer) printf(format string, a ...interface{}) {
+	if r.Out != nil {
+		fmt.Fprintf(r.Out, format, a...)
+	}
+}
+
+// scheduler hands out the payloads in order, never two of the same host at the same time
+type scheduler struct {
+	mutex       sync.Mutex
+	cond        *sync.Cond
+	pending     []Payload
+	inFlight    map[string]bool
+	failedHosts map[string]bool
+}
+
+func newScheduler(pending []Payload) *scheduler {
+	s := &scheduler{
+		pending:     pending,
+		inFlight:    make(map[string]bool),
+		failedHosts: make(map[string]bool),
+	}
+	s.cond = sync.NewCond(&s.mutex)
+
+	return s
+}
+
+// claim return the first pending payload whose host isn't in flight, waiting if all of them are.
+// It also return the payloads dropped because a previous payload of their host failed
+func (s *scheduler) claim() (Payload, []Payload, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var skipped []Payload
+
+	for {
+		blocked := false
+
+		for i := 0; i < len(s.pending); i++ {
+			p := s.pending[i]
+
+			if s.failedHosts[p.Hostname] {
+				skipped = append(skipped, p)
+				s.pending = append(s.pending[:i], s.pending[i+1:]...)
+				i--
+
+				continue
+			}
+
+			if s.inFlight[p.Hostname] {
+				blocked = true
+				continue
+			}
+
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			s.inFlight[p.Hostname] = true
+
+			return p, skipped, true
+		}
+
+		if !blocked {
+			return Payload{}, skipped, false
+		}
+
+		s.cond.Wait()
+	}
+}
+
+func (s *scheduler) release(p Payload, success bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.inFlight, p.Hostname)
+
+	if !success && p.Hostname != "" {
+		s.failedHosts[p.Hostname] = true
+	}
+
+	s.cond.Broadcast()
+}
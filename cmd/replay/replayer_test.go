@@ -0,0 +1,206 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+type insertedHostdata struct {
+	hostname   string
+	capturedAt time.Time
+}
+
+type fakeInserter struct {
+	mutex    sync.Mutex
+	inFlight map[string]bool
+	inserted []insertedHostdata
+	fail     map[time.Time]error
+	t        *testing.T
+}
+
+func (f *fakeInserter) insert(hostdata model.HostDataBE, capturedAt time.Time) error {
+	f.mutex.Lock()
+	assert.False(f.t, f.inFlight[hostdata.Hostname], "%s inserted concurrently", hostdata.Hostname)
+	f.inFlight[hostdata.Hostname] = true
+	f.mutex.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.inFlight, hostdata.Hostname)
+
+	if err := f.fail[capturedAt]; err != nil {
+		return err
+	}
+
+	f.inserted = append(f.inserted, insertedHostdata{hostdata.Hostname, capturedAt})
+
+	return nil
+}
+
+func (f *fakeInserter) insertedOf(hostname string) []time.Time {
+	res := make([]time.Time, 0)
+
+	for _, i := range f.inserted {
+		if i.hostname == hostname {
+			res = append(res, i.capturedAt)
+		}
+	}
+
+	return res
+}
+
+func newTestPayloads(t *testing.T, dir string) []Payload {
+	raw, err := os.ReadFile("../../fixture/test_dataservice_hostdata_v1_00.json")
+	require.NoError(t, err)
+
+	var hostdata map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &hostdata))
+
+	payloads := make([]Payload, 0)
+
+	for day := 1; day <= 5; day++ {
+		for _, hostname := range []string{"host1", "host2", "host3"} {
+			hostdata["hostname"] = hostname
+			raw, err := json.Marshal(hostdata)
+			require.NoError(t, err)
+
+			capturedAt := time.Date(2024, 1, day, 10, 0, 0, 0, time.UTC)
+			name := hostname + capturedAt.Format("-20060102") + ".json"
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), raw, 0o644))
+
+			payloads = append(payloads, Payload{
+				Name:       name,
+				Path:       filepath.Join(dir, name),
+				Hostname:   hostname,
+				CapturedAt: capturedAt,
+			})
+		}
+	}
+
+	return payloads
+}
+
+func TestReplayer_Run(t *testing.T) {
+	dir := t.TempDir()
+	payloads := newTestPayloads(t, dir)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"hostname": "host4"}`), 0o644))
+	payloads = append(payloads, Payload{Name: "invalid.json", Path: invalid, Hostname: "host4", CapturedAt: utils.P("2024-01-06T10:00:00Z")})
+
+	inserter := &fakeInserter{
+		inFlight: make(map[string]bool),
+		fail: map[time.Time]error{
+			utils.P("2024-01-05T10:00:00Z"): utils.ErrHostDataAlreadyExists,
+		},
+		t: t,
+	}
+
+	checkpoint, err := OpenCheckpoint(filepath.Join(dir, "replay.checkpoint"))
+	require.NoError(t, err)
+	defer checkpoint.Close()
+
+	replayer := &Replayer{
+		Insert:      inserter.insert,
+		Parallelism: 3,
+		Checkpoint:  checkpoint,
+		Log:         logger.NewLogger("TEST"),
+	}
+
+	summary := replayer.Run(payloads)
+
+	assert.Equal(t, 16, summary.Total)
+	assert.Equal(t, 12, summary.Replayed)
+	assert.Equal(t, 3, summary.AlreadyPresent)
+	assert.Equal(t, 1, summary.Invalid)
+	assert.Equal(t, 0, summary.Failed)
+	require.Len(t, summary.ValidationErrors, 1)
+	assert.Equal(t, "invalid.json", summary.ValidationErrors[0].Name)
+
+	for _, hostname := range []string{"host1", "host2", "host3"} {
+		assert.Equal(t, []time.Time{
+			utils.P("2024-01-01T10:00:00Z"),
+			utils.P("2024-01-02T10:00:00Z"),
+			utils.P("2024-01-03T10:00:00Z"),
+			utils.P("2024-01-04T10:00:00Z"),
+		}, inserter.insertedOf(hostname))
+	}
+
+	assert.True(t, checkpoint.Done("host1-20240105.json"))
+	assert.False(t, checkpoint.Done("invalid.json"))
+}
+
+func TestReplayer_ResumeAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	payloads := newTestPayloads(t, dir)
+
+	checkpoint, err := OpenCheckpoint(filepath.Join(dir, "replay.checkpoint"))
+	require.NoError(t, err)
+	defer checkpoint.Close()
+
+	inserter := &fakeInserter{
+		inFlight: make(map[string]bool),
+		fail: map[time.Time]error{
+			utils.P("2024-01-03T10:00:00Z"): errors.New("connection refused"),
+		},
+		t: t,
+	}
+
+	replayer := &Replayer{
+		Insert:      inserter.insert,
+		Parallelism: 2,
+		Checkpoint:  checkpoint,
+		Log:         logger.NewLogger("TEST"),
+	}
+
+	summary := replayer.Run(payloads)
+	assert.Equal(t, 6, summary.Replayed)
+	assert.Equal(t, 9, summary.Failed)
+	assert.Len(t, summary.Failures, 9)
+
+	inserter.fail = nil
+	inserter.inserted = nil
+
+	summary = replayer.Run(payloads)
+	assert.Equal(t, 6, summary.AlreadyDone)
+	assert.Equal(t, 9, summary.Replayed)
+	assert.Equal(t, 0, summary.Failed)
+
+	for _, hostname := range []string{"host1", "host2", "host3"} {
+		assert.Equal(t, []time.Time{
+			utils.P("2024-01-03T10:00:00Z"),
+			utils.P("2024-01-04T10:00:00Z"),
+			utils.P("2024-01-05T10:00:00Z"),
+		}, inserter.insertedOf(hostname))
+	}
+}
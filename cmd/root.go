@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ercole-io/ercole/v2/cmd/migration"
+	"github.com/ercole-io/ercole/v2/cmd/replay"
 	"github.com/ercole-io/ercole/v2/cmd/repo"
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
@@ -85,4 +86,6 @@ func init() {
 	rootCmd.AddCommand(migration.NewMigrateStatusCmd(&ercoleConfig))
 
 	rootCmd.AddCommand(repo.NewRepoCmd(&ercoleConfig))
+
+	rootCmd.AddCommand(replay.NewReplayCmd(&ercoleConfig))
 }
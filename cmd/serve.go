@@ -22,12 +22,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/360EntSecGroup-Skylar/excelize"
 	"github.com/gorilla/handlers"
 	"github.com/rs/cors"
 	"github.com/spf13/cobra"
 
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
 
 	migration "github.com/ercole-io/ercole/v2/database-migration"
@@ -42,18 +44,21 @@ import (
 	alertservice_database "github.com/ercole-io/ercole/v2/alert-service/database"
 	alertservice_emailer "github.com/ercole-io/ercole/v2/alert-service/emailer"
 	alertservice_job "github.com/ercole-io/ercole/v2/alert-service/job"
+	alertservice_notifier "github.com/ercole-io/ercole/v2/alert-service/notifier"
 	alertservice_service "github.com/ercole-io/ercole/v2/alert-service/service"
 
 	apiservice_auth "github.com/ercole-io/ercole/v2/api-service/auth"
 	apiservice_client "github.com/ercole-io/ercole/v2/api-service/client"
 	apiservice_controller "github.com/ercole-io/ercole/v2/api-service/controller"
 	apiservice_database "github.com/ercole-io/ercole/v2/api-service/database"
+	apiservice_job "github.com/ercole-io/ercole/v2/api-service/job"
 	apiservice_service "github.com/ercole-io/ercole/v2/api-service/service"
 
 	chartservice_controller "github.com/ercole-io/ercole/v2/chart-service/controller"
 	chartservice_database "github.com/ercole-io/ercole/v2/chart-service/database"
 	chartservice_service "github.com/ercole-io/ercole/v2/chart-service/service"
 
+	thunderservice_client "github.com/ercole-io/ercole/v2/thunder-service/client"
 	thunderservice_controller "github.com/ercole-io/ercole/v2/thunder-service/controller"
 	thunderservice_database "github.com/ercole-io/ercole/v2/thunder-service/database"
 	thunderservice_job "github.com/ercole-io/ercole/v2/thunder-service/job"
@@ -118,6 +123,10 @@ func serve(enableDataService bool,
 	if ercoleConfig.Mongodb.Migrate {
 		log.Info("Migrating...")
 
+		if err := migration.SetCloudSecrets(ercoleConfig.ThunderService.CloudSecrets); err != nil {
+			log.Fatal(err)
+		}
+
 		err := migration.Migrate(ercoleConfig.Mongodb)
 		if err != nil {
 			log.Warn(err)
@@ -186,6 +195,10 @@ func serveDataService(config config.Configuration, wg *sync.WaitGroup) {
 		Log:            log,
 	}
 
+	if config.DataService.HostDataQueue.Enabled {
+		service.StartHostDataQueueWorkers()
+	}
+
 	job := &dataservice_job.Job{
 		Config:        config,
 		ServerVersion: config.Version,
@@ -236,23 +249,25 @@ func serveAlertService(config config.Configuration, wg *sync.WaitGroup) {
 		Config: config,
 	}
 
-	job := &alertservice_job.Job{
-		Config:   config,
-		Database: db,
-		Log:      log,
-		Emailer:  emailer,
+	service := &alertservice_service.AlertService{
+		Config:    config,
+		Database:  db,
+		TimeNow:   time.Now,
+		Log:       log,
+		Emailer:   emailer,
+		Notifiers: alertservice_notifier.BuildNotifiers(config.AlertService.Notifiers),
 	}
-	job.Init()
+	ctx, cancel := context.WithCancel(context.Background())
+	service.Init(ctx, wg)
 
-	service := &alertservice_service.AlertService{
+	job := &alertservice_job.Job{
 		Config:   config,
 		Database: db,
-		TimeNow:  time.Now,
 		Log:      log,
 		Emailer:  emailer,
+		Service:  service,
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	service.Init(ctx, wg)
+	job.Init()
 
 	ctrl := &alertservice_controller.AlertQueueController{
 		Config:  config,
@@ -294,15 +309,24 @@ func serveAPIService(config config.Configuration, wg *sync.WaitGroup) {
 	}
 
 	service := &apiservice_service.APIService{
-		Config:         config,
-		Version:        serverVersion,
-		Database:       db,
-		TimeNow:        time.Now,
-		Log:            log,
-		AlertSvcClient: alertservice_client.NewClient(config.AlertService),
+		Config:          config,
+		Version:         serverVersion,
+		Database:        db,
+		TimeNow:         time.Now,
+		Log:             log,
+		AlertSvcClient:  alertservice_client.NewClient(config.AlertService),
+		Emailer:         &alertservice_emailer.SMTPEmailer{Config: config},
+		ReportExporters: thunderReportExporters(config),
 	}
 	service.Init()
 
+	job := &apiservice_job.Job{
+		Config:  config,
+		Service: service,
+		Log:     log,
+	}
+	job.Init()
+
 	auths := apiservice_auth.BuildAuthenticationProvider(config.APIService.AuthenticationProvider, *service, time.Now, log)
 	for _, auth := range auths {
 		if utils.Contains(config.APIService.AuthenticationProvider.Types, auth.GetType()) {
@@ -334,6 +358,21 @@ func serveAPIService(config config.Configuration, wg *sync.WaitGroup) {
 	}()
 }
 
+// thunderReportExporters return the exporters of the cloud recommendations reports that can be subscribed in the api-service,
+// downloaded from the thunder-service
+func thunderReportExporters(config config.Configuration) map[string]apiservice_service.ReportExporter {
+	client := thunderservice_client.NewClient(config.ThunderService, config.APIService.AuthenticationProvider)
+
+	return map[string]apiservice_service.ReportExporter{
+		model.ReportAwsRecommendations: func(model.ReportFilters) (*excelize.File, error) {
+			return client.GetAwsRecommendationsAsXLSX()
+		},
+		model.ReportOciRecommendations: func(model.ReportFilters) (*excelize.File, error) {
+			return client.GetOciRecommendationsAsXLSX()
+		},
+	}
+}
+
 func serveChartService(config config.Configuration, wg *sync.WaitGroup) {
 	log := logger.NewLogger("CHRT", logger.LogVerbosely(verbose))
 
@@ -384,11 +423,13 @@ func serveChartService(config config.Configuration, wg *sync.WaitGroup) {
 	}
 
 	ctrl := &chartservice_controller.ChartController{
-		Config:        config,
-		Service:       service,
-		TimeNow:       time.Now,
-		Log:           log,
-		Authenticator: auths,
+		Config:           config,
+		Service:          service,
+		TimeNow:          time.Now,
+		Log:              log,
+		Authenticator:    auths,
+		LocationsService: serviceAPI,
+		AuditService:     serviceAPI,
 	}
 
 	h := ctrl.GetChartControllerHandler(auths)
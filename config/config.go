@@ -70,12 +70,24 @@ type DataService struct {
 	AgentUsername string
 	// AgentPassword contains the password of the agent
 	AgentPassword string
+	// EnforceAgentCredentials reject the agents that authenticate with AgentUsername and AgentPassword,
+	// so every agent must use the credential enrolled for its host. The routes not called by the agents
+	// accept only AgentUsername and AgentPassword
+	EnforceAgentCredentials bool
 	// CurrentHostCleaningJob contains the parameters of the current host cleaning
 	CurrentHostCleaningJob CurrentHostCleaningJob
 	// ArchivedCleaningJob contains the parameters of the archived host cleaning
 	ArchivedHostCleaningJob ArchivedHostCleaningJob
 	// FreshnessCheckJob contains the parameters of the freshness check
 	FreshnessCheckJob FreshnessCheckJob
+	// LicenseComplianceAlertJob contains the parameters of the license compliance check
+	LicenseComplianceAlertJob LicenseComplianceAlertJob
+	// ClusterReconciliationAlertJob contains the parameters of the cluster topology reconciliation
+	ClusterReconciliationAlertJob ClusterReconciliationAlertJob
+	// HostDataQueue contains the parameters of the queue of the received hostdata
+	HostDataQueue HostDataQueue
+	// HostDataQueueCleaningJob contains the parameters of the cleaning of the processed hostdata submissions
+	HostDataQueueCleaningJob HostDataQueueCleaningJob
 	// LicenseTypeMetricsDefault default priority order of metric of licenseType when importing HostData
 	LicenseTypeMetricsDefault []string
 	// LicenseTypeMetricsByEnvironment custom priority order of metric of licenseType when importing HostData
@@ -105,10 +117,23 @@ type AlertService struct {
 	QueueBufferSize int
 	// Emailer contains the settings about the emailer
 	Emailer Emailer
+	// Notifiers contains the settings about the notification channels other than the emailer
+	Notifiers Notifiers
+	// Suppression contains the settings about the suppression of the repeated alerts
+	Suppression AlertSuppression
+
+	AckAlertJob             AckAlertJob
+	RemoveAlertJob          RemoveAlertJob
+	ReportAlertJob          ReportAlertJob
+	ContractSupportAlertJob ContractSupportAlertJob
+}
 
-	AckAlertJob    AckAlertJob
-	RemoveAlertJob RemoveAlertJob
-	ReportAlertJob ReportAlertJob
+// AlertSuppression contains the settings about the flapping detection.
+// An alert is flapping when it is raised more than FlappingThreshold times in the last
+// FlappingWindow minutes; flapping alerts are recorded but not notified
+type AlertSuppression struct {
+	FlappingWindow    int
+	FlappingThreshold int
 }
 
 type AckAlertJob struct {
@@ -128,6 +153,13 @@ type ReportAlertJob struct {
 	RunAtStartup bool
 }
 
+type ContractSupportAlertJob struct {
+	Crontab      string
+	RunAtStartup bool
+	// LeadTimes contains the days before the support expiration when the alerts are raised
+	LeadTimes []int
+}
+
 // APIService contains configuration about the api service
 type APIService struct {
 	// RemoteEndpoint contains the endpoint used to connect to the APIService
@@ -164,6 +196,26 @@ type APIService struct {
 
 	// ScopeAsLocation overwrite the location filter in licenses & contracts APIs (es. "location1,location2,location3")
 	ScopeAsLocation string
+
+	// ReportSubscriptionJob contains the crontab used to check which report subscriptions must be delivered
+	ReportSubscriptionJob ReportSubscriptionJob
+	// AuditRetentionJob contains the parameters of the cleaning of the audit log
+	AuditRetentionJob AuditRetentionJob
+}
+
+type ReportSubscriptionJob struct {
+	Crontab      string
+	RunAtStartup bool
+}
+
+// AuditRetentionJob contains the parameters of the cleaning of the audit log
+type AuditRetentionJob struct {
+	// Crontab contains the crontab string used to schedule the cleaning
+	Crontab string
+	// DaysThreshold contains how many days the entries are kept, 0 to keep them forever
+	DaysThreshold int
+	// RunAtStartup contains true if the job should run when the service start, otherwise false
+	RunAtStartup bool
 }
 
 // RepoService contains configuration about the repo service
@@ -205,6 +257,21 @@ type ThunderService struct {
 	AwsDataRetrieveJob         AwsDataRetrieveJob
 
 	GcpDataRetrieveJob GcpDataRetrieveJob
+
+	AzureDataRetrieveJob AzureDataRetrieveJob
+
+	// CloudSecrets contains the master keys used to encrypt the credentials of the cloud profiles
+	CloudSecrets CloudSecrets `bson:"-" json:"-"`
+}
+
+// CloudSecrets contains the base64 encoded master keys used to encrypt the credentials of the cloud profiles
+type CloudSecrets struct {
+	// Key is the current master key, the credentials are stored in clear if it isn't set
+	Key string
+	// KeyFile is the file containing the current master key, read when Key is empty
+	KeyFile string
+	// PreviousKeys contains the master keys replaced by a rotation, used only to decrypt
+	PreviousKeys []string
 }
 
 // Mongodb contains configuration about the database connection, some data logic and migration
@@ -225,6 +292,46 @@ type FreshnessCheckJob struct {
 	RunAtStartup bool
 }
 
+// LicenseComplianceAlertJob contains parameters for the license compliance check
+type LicenseComplianceAlertJob struct {
+	// Crontab contains the crontab string used to schedule the license compliance check
+	Crontab string
+	// RunAtStartup contains true if the job should run when the service start, otherwise false
+	RunAtStartup bool
+}
+
+// ClusterReconciliationAlertJob contains parameters for the cluster topology reconciliation
+type ClusterReconciliationAlertJob struct {
+	// Crontab contains the crontab string used to schedule the cluster topology reconciliation
+	Crontab string
+	// RunAtStartup contains true if the job should run when the service start, otherwise false
+	RunAtStartup bool
+}
+
+// HostDataQueue contains parameters for the queue of the received hostdata
+type HostDataQueue struct {
+	// Enabled contains true if the hostdata are queued and inserted by the workers, otherwise they are inserted during the request
+	Enabled bool
+	// Concurrency contains the number of workers that process the queue, 1 if zero
+	Concurrency int
+	// MaxAttempts contains the maximum number of attempts before a submission is moved to the dead letters, 1 if zero
+	MaxAttempts int
+	// RetryBackoff contains the seconds waited before the first retry, doubled at every further attempt
+	RetryBackoff int
+	// PollInterval contains the milliseconds waited by an idle worker before polling the queue again
+	PollInterval int
+}
+
+// HostDataQueueCleaningJob contains parameters for the cleaning of the processed hostdata submissions
+type HostDataQueueCleaningJob struct {
+	// Crontab contains the crontab string used to schedule the cleaning
+	Crontab string
+	// HourThreshold contains how many hours the processed submissions are kept, 0 to keep them forever
+	HourThreshold int
+	// RunAtStartup contains true if the job should run when the service start, otherwise false
+	RunAtStartup bool
+}
+
 // CurrentHostCleaningJob contains parameters for the current host cleaning
 type CurrentHostCleaningJob struct {
 	// Crontab contains the crontab string used to schedule the cleaning
@@ -290,6 +397,18 @@ type GcpDataRetrieveJob struct {
 	ThroughputStoragePercentage uint
 }
 
+type AzureDataRetrieveJob struct {
+	// Crontab contains the crontab string used to schedule the job
+	Crontab string
+	// RunAtStartup contains true if the job should run when the service start, otherwise false
+	RunAtStartup bool
+	// MetricsDays contains the number of days of Azure Monitor metrics used for the rightsizing
+	MetricsDays int
+	// AvgCpuPercentage and MaxCpuPercentage are the cpu usages under which a virtual machine is oversized
+	AvgCpuPercentage float64
+	MaxCpuPercentage float64
+}
+
 // HTTPRepoService contains parameters for a single serving service
 type HTTPRepoService struct {
 	// Enable contains true it the service is enabled, otherwise false
@@ -350,6 +469,8 @@ type Emailer struct {
 	AlertType AlertType
 	// AlertSeverity contains the possible severity of alert that can be sent
 	AlertSeverity AlertSeverity
+	// Retry contains the retry policy used when an email can't be sent
+	Retry NotifierRetry
 }
 
 type AlertType struct {
@@ -363,6 +484,124 @@ type AlertType struct {
 	MissingDatabase            Directive
 	AgentError                 Directive
 	NoData                     Directive
+	LicenseNonCompliant        Directive
+	ContractSupportExpiring    Directive
+	ContractSupportExpired     Directive
+	ClusterTopologyMismatch    Directive
+}
+
+// Notifiers contains the settings of the alert notification channels
+type Notifiers struct {
+	// Webhooks contains the generic HTTP webhooks
+	Webhooks []WebhookNotifier
+	// Slack contains the Slack incoming webhooks
+	Slack []SlackNotifier
+	// Teams contains the Microsoft Teams incoming webhooks
+	Teams []TeamsNotifier
+	// Syslog contains the RFC5424 syslog receivers
+	Syslog []SyslogNotifier
+}
+
+// WebhookNotifier contains the settings of a generic HTTP webhook
+type WebhookNotifier struct {
+	// Enabled contains true if the notifier is enabled, otherwise false
+	Enabled bool
+	// Name identifies the notifier in the logs
+	Name string
+	// URL contains the endpoint that receives the alerts
+	URL string
+	// Method contains the HTTP method, POST if empty
+	Method string
+	// Headers contains additional HTTP headers
+	Headers map[string]string
+	// BodyTemplate contains the text/template used to build the body, the alert is marshalled as JSON if empty
+	BodyTemplate string
+	// Timeout contains the number of seconds after which the request is aborted
+	Timeout int
+	// DisableSSLCertificateValidation contains true if disable the certification validation, otherwise false
+	DisableSSLCertificateValidation bool
+	// Filter contains the alerts that are sent to this notifier
+	Filter NotifierFilter
+	// Retry contains the retry policy of the notifier
+	Retry NotifierRetry
+}
+
+// SlackNotifier contains the settings of a Slack incoming webhook
+type SlackNotifier struct {
+	// Enabled contains true if the notifier is enabled, otherwise false
+	Enabled bool
+	// Name identifies the notifier in the logs
+	Name string
+	// WebhookURL contains the URL of the incoming webhook
+	WebhookURL string
+	// Channel overrides the default channel of the webhook
+	Channel string
+	// Username overrides the default username of the webhook
+	Username string
+	// Timeout contains the number of seconds after which the request is aborted
+	Timeout int
+	// Filter contains the alerts that are sent to this notifier
+	Filter NotifierFilter
+	// Retry contains the retry policy of the notifier
+	Retry NotifierRetry
+}
+
+// TeamsNotifier contains the settings of a Microsoft Teams incoming webhook
+type TeamsNotifier struct {
+	// Enabled contains true if the notifier is enabled, otherwise false
+	Enabled bool
+	// Name identifies the notifier in the logs
+	Name string
+	// WebhookURL contains the URL of the incoming webhook
+	WebhookURL string
+	// Timeout contains the number of seconds after which the request is aborted
+	Timeout int
+	// Filter contains the alerts that are sent to this notifier
+	Filter NotifierFilter
+	// Retry contains the retry policy of the notifier
+	Retry NotifierRetry
+}
+
+// SyslogNotifier contains the settings of a RFC5424 syslog receiver
+type SyslogNotifier struct {
+	// Enabled contains true if the notifier is enabled, otherwise false
+	Enabled bool
+	// Name identifies the notifier in the logs
+	Name string
+	// Network contains the transport protocol, udp or tcp
+	Network string
+	// Address contains host:port of the syslog receiver
+	Address string
+	// Facility contains the syslog facility code, local0 (16) if zero
+	Facility int
+	// AppName contains the APP-NAME field, ercole if empty
+	AppName string
+	// Hostname contains the HOSTNAME field, the local hostname if empty
+	Hostname string
+	// Timeout contains the number of seconds after which the connection is aborted
+	Timeout int
+	// Filter contains the alerts that are sent to this notifier
+	Filter NotifierFilter
+	// Retry contains the retry policy of the notifier
+	Retry NotifierRetry
+}
+
+// NotifierFilter contains the alerts accepted by a notifier
+type NotifierFilter struct {
+	// AlertCodes contains the accepted alert codes, all if empty
+	AlertCodes []string
+	// AlertSeverities contains the accepted alert severities, all if empty
+	AlertSeverities []string
+}
+
+// NotifierRetry contains the retry policy of a notifier
+type NotifierRetry struct {
+	// MaxAttempts contains the maximum number of attempts, 1 if zero
+	MaxAttempts int
+	// InitialBackoff contains the milliseconds waited before the first retry
+	InitialBackoff int
+	// MaxBackoff contains the maximum milliseconds waited between two retries
+	MaxBackoff int
 }
 
 type AlertSeverity struct {
@@ -379,6 +618,7 @@ type AuthenticationProviderConfig struct {
 	// Type contains the type of the source. Supported types are:
 	//	- basic
 	// 	- ldap
+	// 	- oidc
 	Types []string
 	// Service username (basic token)
 	Username string
@@ -396,6 +636,21 @@ type AuthenticationProviderConfig struct {
 	LDAPBindDN           string
 	LDAPBindPassword     string
 	LDAPUserFilter       string
+
+	// OIDCIssuer is the URL of the OpenID Connect issuer, used for the discovery of the endpoints and keys
+	OIDCIssuer string
+	// OIDCClientID is the client id registered on the issuer, it's also the expected audience of the tokens
+	OIDCClientID string
+	// OIDCClientSecret is the client secret registered on the issuer
+	OIDCClientSecret string
+	// OIDCRedirectURL is the URL where the issuer redirects the user with the authorization code
+	OIDCRedirectURL string
+	// OIDCScopes contains the scopes requested to the issuer
+	OIDCScopes []string
+	// OIDCUsernameClaim is the claim that contains the username
+	OIDCUsernameClaim string
+	// OIDCGroupsClaim is the claim that contains the groups of the user, matched with the tags of the ercole groups
+	OIDCGroupsClaim string
 }
 
 // ReadConfig read, parse and return a Configuration from the configuration file
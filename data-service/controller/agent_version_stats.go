@@ -0,0 +1,48 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"net/http"
+
+	"github.com/ercole-io/ercole/v2/schema"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// GetHostdataSchemaVersions return the current hostdata schema version and all the ones accepted,
+// so the agents can choose which one to send
+func (ctrl *DataController) GetHostdataSchemaVersions(w http.ResponseWriter, r *http.Request) {
+	current, supported, err := schema.HostdataSchemaVersions()
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"current":   current,
+		"supported": supported,
+	})
+}
+
+// ListAgentVersionStats return how many hostdata were accepted or rejected, by agent and schema version
+func (ctrl *DataController) ListAgentVersionStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := ctrl.Service.ListAgentVersionStats()
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, stats)
+}
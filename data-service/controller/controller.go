@@ -28,11 +28,13 @@ import (
 
 type DataControllerInterface interface {
 	InsertHostData(w http.ResponseWriter, r *http.Request)
+	GetHostDataSubmission(w http.ResponseWriter, r *http.Request)
 	CompareCmdbInfo(w http.ResponseWriter, r *http.Request)
 
 	InsertExadata(w http.ResponseWriter, r *http.Request)
 
 	AuthenticateMiddleware(h http.Handler) http.Handler
+	AuthenticateAgentMiddleware(h http.Handler) http.Handler
 }
 
 type DataController struct {
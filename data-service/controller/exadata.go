@@ -69,6 +69,11 @@ func (ctrl *DataController) InsertExadata(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if err := checkAgentHostname(r, exadata.Hostname); err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusForbidden, err)
+		return
+	}
+
 	err = ctrl.Service.SaveExadata(&exadata)
 	if err != nil {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
@@ -22,6 +22,9 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/schema"
 	"github.com/ercole-io/ercole/v2/utils"
@@ -53,9 +56,11 @@ func (ctrl *DataController) InsertHostData(w http.ResponseWriter, r *http.Reques
 
 	var hostdata model.HostDataBE
 
-	if validationErr := schema.ValidateHostdata(raw); validationErr != nil {
+	upcasted, header, validationErr := schema.UpcastHostdata(raw)
+	if validationErr != nil {
 		if errors.Is(validationErr, utils.ErrInvalidHostdata) {
 			ctrl.Log.Info(validationErr)
+			ctrl.recordAgentPayload(header, validationErr)
 			utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, validationErr)
 
 			if unmarshalErr := json.Unmarshal(raw, &hostdata); unmarshalErr != nil {
@@ -72,12 +77,33 @@ func (ctrl *DataController) InsertHostData(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = json.Unmarshal(raw, &hostdata)
+	err = json.Unmarshal(upcasted, &hostdata)
 	if err != nil {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
 		return
 	}
 
+	if err := checkAgentHostname(r, hostdata.Hostname); err != nil {
+		ctrl.recordAgentPayload(header, err)
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusForbidden, err)
+
+		return
+	}
+
+	ctrl.recordAgentPayload(header, nil)
+
+	if ctrl.Config.DataService.HostDataQueue.Enabled {
+		id, err := ctrl.Service.EnqueueHostData(hostdata)
+		if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+			return
+		}
+
+		utils.WriteJSONResponse(w, http.StatusAccepted, map[string]interface{}{"id": id})
+
+		return
+	}
+
 	err = ctrl.Service.InsertHostData(hostdata)
 	if err != nil {
 		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
@@ -87,6 +113,43 @@ func (ctrl *DataController) InsertHostData(w http.ResponseWriter, r *http.Reques
 	utils.WriteJSONResponse(w, http.StatusOK, nil)
 }
 
+// GetHostDataSubmission return the status of a queued hostdata
+func (ctrl *DataController) GetHostDataSubmission(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, utils.NewError(err, http.StatusText(http.StatusUnprocessableEntity)))
+		return
+	}
+
+	submission, err := ctrl.Service.GetHostDataSubmission(id)
+	if errors.Is(err, utils.ErrHostDataSubmissionNotFound) {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the agents can't see if the other hosts have sent data
+	if checkAgentHostname(r, submission.Hostname) != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusNotFound, utils.ErrHostDataSubmissionNotFound)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, submission)
+}
+
+// recordAgentPayload update the statistics of the agent versions, a failure doesn't stop the hostdata
+func (ctrl *DataController) recordAgentPayload(header *schema.HostdataHeader, rejection error) {
+	if header == nil {
+		return
+	}
+
+	if err := ctrl.Service.RecordAgentPayload(header.AgentVersion, header.SchemaVersion, header.Upcasted, rejection); err != nil {
+		ctrl.Log.Error(err)
+	}
+}
+
 func (ctrl *DataController) sanitizeJson(raw []byte) ([]byte, error) {
 	var m map[string]interface{}
 
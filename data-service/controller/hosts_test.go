@@ -23,12 +23,16 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	gomock "go.uber.org/mock/gomock"
 
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
 	"github.com/ercole-io/ercole/v2/utils/mongoutils"
 )
@@ -49,6 +53,7 @@ func TestUpdateHostInfo_Success(t *testing.T) {
 
 	expectedHostDataBE := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
 
+	as.EXPECT().RecordAgentPayload("1.6.5", 1, false, nil).Return(nil)
 	as.EXPECT().InsertHostData(expectedHostDataBE).Return(nil)
 
 	handler := http.HandlerFunc(ac.InsertHostData)
@@ -61,6 +66,53 @@ func TestUpdateHostInfo_Success(t *testing.T) {
 	require.Equal(t, http.StatusOK, rr.Code)
 }
 
+func TestUpdateHostInfo_AgentCredential(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockHostDataServiceInterface(mockCtrl)
+	ac := DataController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	raw, err := ioutil.ReadFile("../../fixture/test_dataservice_hostdata_v1_00.json")
+	require.NoError(t, err)
+
+	expectedHostDataBE := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
+
+	t.Run("Hostname of the credential", func(t *testing.T) {
+		as.EXPECT().RecordAgentPayload("1.6.5", 1, false, nil).Return(nil)
+		as.EXPECT().InsertHostData(expectedHostDataBE).Return(nil)
+
+		req, err := http.NewRequest("PUT", "/", bytes.NewReader(raw))
+		require.NoError(t, err)
+		context.Set(req, "agent", model.AgentCredential{Hostname: "RAC1_X"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.InsertHostData).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Hostname of another host", func(t *testing.T) {
+		as.EXPECT().RecordAgentPayload("1.6.5", 1, false, gomock.Any()).
+			Do(func(_ string, _ int, _ bool, err error) {
+				assert.ErrorIs(t, err, utils.ErrAgentHostnameMismatch)
+			}).Return(nil)
+
+		req, err := http.NewRequest("PUT", "/", bytes.NewReader(raw))
+		require.NoError(t, err)
+		context.Set(req, "agent", model.AgentCredential{Hostname: "rac2_x"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.InsertHostData).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
 func TestUpdateHostInfo_FailBadRequest(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -120,6 +172,12 @@ func TestUpdateHostInfo_UnprocessableEntity2(t *testing.T) {
 		Log:     logger.NewLogger("TEST"),
 	}
 
+	as.EXPECT().
+		RecordAgentPayload("", 0, false, gomock.Any()).
+		Do(func(_ string, _ int, _ bool, err error) {
+			assert.ErrorIs(t, err, utils.ErrInvalidHostdata)
+		}).
+		Return(nil)
 	as.EXPECT().
 		AlertInvalidHostData(gomock.Any(), gomock.Any()).
 		Do(func(err error, hd interface{}) {
@@ -137,6 +195,73 @@ func TestUpdateHostInfo_UnprocessableEntity2(t *testing.T) {
 	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
 }
 
+func TestUpdateHostInfo_UnsupportedSchemaVersion(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockHostDataServiceInterface(mockCtrl)
+	ac := DataController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	raw, err := ioutil.ReadFile("../../fixture/test_dataservice_hostdata_v1_00.json")
+	require.NoError(t, err)
+
+	raw = bytes.Replace(raw, []byte(`"schemaVersion": 1`), []byte(`"schemaVersion": 99`), 1)
+
+	as.EXPECT().
+		RecordAgentPayload("1.6.5", 99, false, gomock.Any()).
+		Do(func(_ string, _ int, _ bool, err error) {
+			assert.ErrorIs(t, err, utils.ErrUnsupportedSchemaVersion)
+		}).
+		Return(aerrMock)
+	as.EXPECT().
+		AlertInvalidHostData(gomock.Any(), gomock.Any()).
+		Do(func(err error, hd interface{}) {
+			assert.ErrorIs(t, err, utils.ErrUnsupportedSchemaVersion)
+		})
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.InsertHostData)
+	req, err := http.NewRequest("PUT", "/", bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestUpdateHostInfo_RecordAgentPayloadFail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockHostDataServiceInterface(mockCtrl)
+	ac := DataController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	raw, err := ioutil.ReadFile("../../fixture/test_dataservice_hostdata_v1_00.json")
+	require.NoError(t, err)
+
+	expectedHostDataBE := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
+
+	as.EXPECT().RecordAgentPayload("1.6.5", 1, false, nil).Return(aerrMock)
+	as.EXPECT().InsertHostData(expectedHostDataBE).Return(nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(ac.InsertHostData)
+	req, err := http.NewRequest("PUT", "/", bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
 func TestUpdateHostInfo_InternalServerError(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -153,6 +278,7 @@ func TestUpdateHostInfo_InternalServerError(t *testing.T) {
 
 	expectedHostDataBE := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
 
+	as.EXPECT().RecordAgentPayload("1.6.5", 1, false, nil).Return(nil)
 	as.EXPECT().InsertHostData(expectedHostDataBE).Return(aerrMock)
 
 	rr := httptest.NewRecorder()
@@ -180,6 +306,7 @@ func TestUpdateHostInfo_SuccessAndSanitized(t *testing.T) {
 	require.NoError(t, err)
 
 	expected := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
+	as.EXPECT().RecordAgentPayload("1.6.5", 1, false, nil).Return(nil)
 	as.EXPECT().InsertHostData(expected).Return(nil)
 
 	handler := http.HandlerFunc(ac.InsertHostData)
@@ -191,3 +318,146 @@ func TestUpdateHostInfo_SuccessAndSanitized(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, rr.Code)
 }
+
+func TestUpdateHostInfo_Queued(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockHostDataServiceInterface(mockCtrl)
+	ac := DataController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config: config.Configuration{
+			DataService: config.DataService{
+				HostDataQueue: config.HostDataQueue{Enabled: true},
+			},
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	raw, err := ioutil.ReadFile("../../fixture/test_dataservice_hostdata_v1_00.json")
+	require.NoError(t, err)
+
+	expectedHostDataBE := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+	as.EXPECT().RecordAgentPayload("1.6.5", 1, false, nil).Return(nil)
+	as.EXPECT().EnqueueHostData(expectedHostDataBE).Return(id, nil)
+
+	handler := http.HandlerFunc(ac.InsertHostData)
+	req, err := http.NewRequest("PUT", "/", bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	assert.JSONEq(t, `{"id": "5dc3f534db7e81a98b726a52"}`, rr.Body.String())
+}
+
+func TestUpdateHostInfo_QueuedInternalServerError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockHostDataServiceInterface(mockCtrl)
+	ac := DataController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config: config.Configuration{
+			DataService: config.DataService{
+				HostDataQueue: config.HostDataQueue{Enabled: true},
+			},
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	raw, err := ioutil.ReadFile("../../fixture/test_dataservice_hostdata_v1_00.json")
+	require.NoError(t, err)
+
+	expectedHostDataBE := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
+
+	as.EXPECT().RecordAgentPayload("1.6.5", 1, false, nil).Return(nil)
+	as.EXPECT().EnqueueHostData(expectedHostDataBE).Return(primitive.NilObjectID, aerrMock)
+
+	handler := http.HandlerFunc(ac.InsertHostData)
+	req, err := http.NewRequest("PUT", "/", bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestGetHostDataSubmission(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockHostDataServiceInterface(mockCtrl)
+	ac := DataController{
+		TimeNow: utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}
+
+	id := utils.Str2oid("5dc3f534db7e81a98b726a52")
+
+	t.Run("Success", func(t *testing.T) {
+		submission := model.HostDataSubmission{
+			ID:            id,
+			Hostname:      "foobar",
+			Status:        model.HostDataSubmissionStatusQueued,
+			Attempts:      1,
+			LastError:     "DB ERROR",
+			SubmittedAt:   utils.P("2019-11-05T14:02:03Z"),
+			NextAttemptAt: utils.P("2019-11-05T14:02:33Z"),
+		}
+		as.EXPECT().GetHostDataSubmission(id).Return(&submission, nil)
+
+		req, err := http.NewRequest("GET", "/hosts/submissions/5dc3f534db7e81a98b726a52", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDataSubmission).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, utils.ToJSON(submission), rr.Body.String())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		as.EXPECT().GetHostDataSubmission(id).Return(nil, utils.ErrHostDataSubmissionNotFound)
+
+		req, err := http.NewRequest("GET", "/hosts/submissions/5dc3f534db7e81a98b726a52", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDataSubmission).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Submission of another host", func(t *testing.T) {
+		as.EXPECT().GetHostDataSubmission(id).Return(&model.HostDataSubmission{ID: id, Hostname: "foobar"}, nil)
+
+		req, err := http.NewRequest("GET", "/hosts/submissions/5dc3f534db7e81a98b726a52", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "5dc3f534db7e81a98b726a52"})
+		context.Set(req, "agent", model.AgentCredential{Hostname: "barfoo"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDataSubmission).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/hosts/submissions/foobar", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "foobar"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetHostDataSubmission).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
@@ -16,10 +16,16 @@
 package controller
 
 import (
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"strings"
 
-	"github.com/goji/httpauth"
+	"github.com/gorilla/context"
 	"github.com/gorilla/mux"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
 )
 
 // GetDataControllerHandler setup the routes of the router using the handler in the controller as http handler
@@ -34,22 +40,109 @@ func (ctrl *DataController) GetDataControllerHandler() http.Handler {
 	})
 
 	router.StrictSlash(true)
-	router.Use(ctrl.AuthenticateMiddleware)
 
-	ctrl.setupProtectedRoutes(router)
+	agentRouter := router.NewRoute().Subrouter()
+	agentRouter.Use(ctrl.AuthenticateAgentMiddleware)
+	ctrl.setupAgentRoutes(agentRouter)
+
+	protectedRouter := router.NewRoute().Subrouter()
+	protectedRouter.Use(ctrl.AuthenticateMiddleware)
+	ctrl.setupProtectedRoutes(protectedRouter)
 
 	return router
 }
 
-func (ctrl *DataController) setupProtectedRoutes(router *mux.Router) {
+// setupAgentRoutes setup the routes called by the agents, that accept also the credentials enrolled for their hosts
+func (ctrl *DataController) setupAgentRoutes(router *mux.Router) {
 	router.HandleFunc("/hosts", ctrl.InsertHostData).Methods("POST")
+	router.HandleFunc("/hosts/submissions/{id}", ctrl.GetHostDataSubmission).Methods("GET")
+	router.HandleFunc("/exadatas", ctrl.InsertExadata).Methods("POST")
+}
+
+func (ctrl *DataController) setupProtectedRoutes(router *mux.Router) {
+	router.HandleFunc("/hosts/schema-versions", ctrl.GetHostdataSchemaVersions).Methods("GET")
+	router.HandleFunc("/agents/stats", ctrl.ListAgentVersionStats).Methods("GET")
 	router.HandleFunc("/cmdbs", ctrl.CompareCmdbInfo).Methods("POST")
 	router.HandleFunc("/oracle/license-types", ctrl.InsertOracleLicenseTypes).Methods("POST")
-	router.HandleFunc("/exadatas", ctrl.InsertExadata).Methods("POST")
 }
 
 // AuthenticateMiddleware return the middleware used to authenticate (request) users
+// with the shared agent credential
 func (ctrl *DataController) AuthenticateMiddleware(h http.Handler) http.Handler {
-	basicAuthHandler := httpauth.SimpleBasicAuth(ctrl.Config.DataService.AgentUsername, ctrl.Config.DataService.AgentPassword)
-	return basicAuthHandler(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			ctrl.unauthorized(w, errors.New("Missing basic authentication"))
+			return
+		}
+
+		if !ctrl.isSharedCredential(username, password) {
+			ctrl.unauthorized(w, errors.New("Invalid credentials"))
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// AuthenticateAgentMiddleware return the middleware used to authenticate the agents.
+// The agents authenticate with the shared agent credential, unless the per-agent credentials are enforced,
+// or with their hostname and the secret of the credential enrolled for their host
+func (ctrl *DataController) AuthenticateAgentMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			ctrl.unauthorized(w, errors.New("Missing basic authentication"))
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(username), []byte(ctrl.Config.DataService.AgentUsername)) == 1 {
+			if ctrl.Config.DataService.EnforceAgentCredentials || !ctrl.isSharedCredential(username, password) {
+				ctrl.unauthorized(w, errors.New("Invalid credentials"))
+				return
+			}
+
+			h.ServeHTTP(w, r)
+
+			return
+		}
+
+		credential, err := ctrl.Service.AuthenticateAgent(username, password)
+		if errors.Is(err, utils.ErrInvalidAgentCredential) {
+			ctrl.unauthorized(w, err)
+			return
+		} else if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusInternalServerError, err)
+			return
+		}
+
+		context.Set(r, "agent", *credential)
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (ctrl *DataController) isSharedCredential(username, password string) bool {
+	return subtle.ConstantTimeCompare([]byte(username), []byte(ctrl.Config.DataService.AgentUsername)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(ctrl.Config.DataService.AgentPassword)) == 1
+}
+
+func (ctrl *DataController) unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
+	utils.WriteAndLogError(ctrl.Log, w, http.StatusUnauthorized, utils.NewError(err, http.StatusText(http.StatusUnauthorized)))
+}
+
+// checkAgentHostname return an error if the request has been authenticated by the credential
+// enrolled for a different host
+func checkAgentHostname(r *http.Request, hostname string) error {
+	credential, ok := context.Get(r, "agent").(model.AgentCredential)
+	if !ok {
+		return nil
+	}
+
+	if !strings.EqualFold(credential.Hostname, hostname) {
+		return utils.NewErrorf("%w: %s isn't %s", utils.ErrAgentHostnameMismatch, hostname, credential.Hostname)
+	}
+
+	return nil
 }
@@ -0,0 +1,58 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const agentCredentialCollection = "agent_credentials"
+
+// GetAgentCredential return the credential enrolled for the agent of the host
+func (md *MongoDatabase) GetAgentCredential(hostname string) (*model.AgentCredential, error) {
+	var out model.AgentCredential
+
+	err := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentCredentialCollection).
+		FindOne(context.TODO(), bson.M{"hostname": hostname}).
+		Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, utils.ErrAgentCredentialNotFound
+	} else if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return &out, nil
+}
+
+// UpdateAgentCredentialLastUsed set the last time the agent credential has been used
+func (md *MongoDatabase) UpdateAgentCredentialLastUsed(id primitive.ObjectID, lastUsedAt time.Time) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentCredentialCollection).
+		UpdateOne(context.TODO(), bson.M{"_id": id}, bson.M{"$set": bson.M{"lastUsedAt": lastUsedAt}})
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestAgentCredentials() {
+	defer m.db.Client.Database(m.dbname).Collection(agentCredentialCollection).DeleteMany(context.TODO(), bson.M{})
+
+	credential := model.AgentCredential{
+		ID:        utils.Str2oid("654a1d2f3b8e7c0001a1d4e1"),
+		Hostname:  "itl-csllab-112.sorint.localpeer",
+		Hash:      "hash",
+		Hint:      "ercagt_AbCd",
+		CreatedAt: utils.P("2019-11-05T14:02:03Z"),
+	}
+
+	_, err := m.db.Client.Database(m.dbname).Collection(agentCredentialCollection).InsertOne(context.TODO(), credential)
+	require.NoError(m.T(), err)
+
+	lastUsedAt := utils.P("2019-11-06T14:02:03Z")
+	require.NoError(m.T(), m.db.UpdateAgentCredentialLastUsed(credential.ID, lastUsedAt))
+
+	actual, err := m.db.GetAgentCredential("itl-csllab-112.sorint.localpeer")
+	require.NoError(m.T(), err)
+
+	credential.LastUsedAt = &lastUsedAt
+	require.Equal(m.T(), credential, *actual)
+
+	_, err = m.db.GetAgentCredential("unknown")
+	require.ErrorIs(m.T(), err, utils.ErrAgentCredentialNotFound)
+}
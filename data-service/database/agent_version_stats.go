@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const agentVersionStatsCollection = "agent_version_stats"
+
+// RecordAgentPayload count an hostdata received at t from an agent with agentVersion, declaring schemaVersion.
+// The hostdata was rejected if lastError isn't empty
+func (md *MongoDatabase) RecordAgentPayload(agentVersion string, schemaVersion int, upcasted bool, lastError string, t time.Time) error {
+	inc := bson.M{"accepted": 0, "rejected": 0, "upcasted": 0}
+	set := bson.M{}
+
+	if lastError == "" {
+		inc["accepted"] = 1
+
+		if upcasted {
+			inc["upcasted"] = 1
+		}
+	} else {
+		inc["rejected"] = 1
+		set["lastError"] = lastError
+		set["lastRejectedAt"] = t
+	}
+
+	update := bson.M{
+		"$inc": inc,
+		"$min": bson.M{"firstSeen": t},
+		"$max": bson.M{"lastSeen": t},
+	}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentVersionStatsCollection).
+		UpdateOne(context.TODO(),
+			bson.M{"agentVersion": agentVersion, "schemaVersion": schemaVersion},
+			update,
+			options.Update().SetUpsert(true),
+		)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// ListAgentVersionStats return the statistics of the hostdata received, by agent and schema version
+func (md *MongoDatabase) ListAgentVersionStats() ([]model.AgentVersionStats, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "agentVersion", Value: 1}, {Key: "schemaVersion", Value: 1}}).
+		SetProjection(bson.M{"_id": 0})
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(agentVersionStatsCollection).
+		Find(context.TODO(), bson.M{}, opts)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	out := make([]model.AgentVersionStats, 0)
+	if err := cur.All(context.TODO(), &out); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return out, nil
+}
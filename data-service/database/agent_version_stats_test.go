@@ -0,0 +1,60 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package database
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestAgentVersionStats() {
+	defer m.db.Client.Database(m.dbname).Collection(agentVersionStatsCollection).DeleteMany(context.TODO(), bson.M{})
+
+	require.NoError(m.T(), m.db.RecordAgentPayload("1.9.0", 1, false, "", utils.P("2019-11-05T14:02:03Z")))
+	require.NoError(m.T(), m.db.RecordAgentPayload("1.9.0", 1, true, "", utils.P("2019-11-05T12:02:03Z")))
+	require.NoError(m.T(), m.db.RecordAgentPayload("1.9.0", 1, false, "Invalid hostdata", utils.P("2019-11-05T16:02:03Z")))
+	require.NoError(m.T(), m.db.RecordAgentPayload("2.0.0", 2, false, "", utils.P("2019-11-06T14:02:03Z")))
+
+	actual, err := m.db.ListAgentVersionStats()
+	require.NoError(m.T(), err)
+
+	lastRejectedAt := utils.P("2019-11-05T16:02:03Z")
+	expected := []model.AgentVersionStats{
+		{
+			AgentVersion:   "1.9.0",
+			SchemaVersion:  1,
+			Accepted:       2,
+			Rejected:       1,
+			Upcasted:       1,
+			FirstSeen:      utils.P("2019-11-05T12:02:03Z"),
+			LastSeen:       utils.P("2019-11-05T16:02:03Z"),
+			LastRejectedAt: &lastRejectedAt,
+			LastError:      "Invalid hostdata",
+		},
+		{
+			AgentVersion:  "2.0.0",
+			SchemaVersion: 2,
+			Accepted:      1,
+			FirstSeen:     utils.P("2019-11-06T14:02:03Z"),
+			LastSeen:      utils.P("2019-11-06T14:02:03Z"),
+		},
+	}
+	require.Equal(m.T(), expected, actual)
+}
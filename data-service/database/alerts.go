@@ -53,3 +53,23 @@ func (md *MongoDatabase) DeleteNoDataAlertByHost(hostname string) error {
 
 	return nil
 }
+
+// DeleteNoDataAlertsExcept delete the NO_DATA alerts of all the hosts except the ones in hostnames
+func (md *MongoDatabase) DeleteNoDataAlertsExcept(hostnames []string) error {
+	if hostnames == nil {
+		hostnames = []string{}
+	}
+
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).
+		Collection("alerts").
+		DeleteMany(context.TODO(), bson.M{
+			"alertCode":          model.AlertCodeNoData,
+			"otherInfo.hostname": bson.M{"$nin": hostnames},
+		})
+
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
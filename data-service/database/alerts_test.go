@@ -173,3 +173,49 @@ func (m *MongodbSuite) TestDeleteNoDataAlertByHost_Success() {
 		require.Equal(m.T(), 0, len(alerts))
 	})
 }
+
+func (m *MongodbSuite) TestDeleteNoDataAlertsExcept_Success() {
+	defer m.db.Client.Database(m.dbname).Collection("alerts").DeleteMany(context.TODO(), bson.M{})
+
+	staleAlert := model.Alert{
+		AlertCode:     model.AlertCodeNoData,
+		AlertSeverity: model.AlertSeverityCritical,
+		AlertCategory: model.AlertCategoryAgent,
+		AlertStatus:   model.AlertStatusNew,
+		Date:          utils.P("2019-11-05T18:02:03Z"),
+		Description:   "test desc pippo",
+		OtherInfo: map[string]interface{}{
+			"hostname": "pippo-host",
+		},
+		ID: utils.Str2oid("5dd40bfb12f54dfda7b1c292"),
+	}
+
+	freshAlert := staleAlert
+	freshAlert.ID = utils.Str2oid("5dd40bfb12f54dfda7b1c293")
+	freshAlert.OtherInfo = map[string]interface{}{
+		"hostname": "pluto-host",
+	}
+
+	_, err := m.db.Client.Database(m.dbname).Collection("alerts").InsertMany(context.TODO(), []interface{}{staleAlert, freshAlert})
+	require.NoError(m.T(), err)
+
+	err = m.db.DeleteNoDataAlertsExcept([]string{"pippo-host"})
+	require.NoError(m.T(), err)
+
+	val, err := m.db.Client.Database(m.dbname).Collection("alerts").
+		Find(context.TODO(), bson.M{"alertCode": model.AlertCodeNoData})
+	require.NoError(m.T(), err)
+
+	alerts := make([]model.Alert, 0)
+	err = val.All(context.TODO(), &alerts)
+	require.NoError(m.T(), err)
+	require.Equal(m.T(), []model.Alert{staleAlert}, alerts)
+
+	err = m.db.DeleteNoDataAlertsExcept(nil)
+	require.NoError(m.T(), err)
+
+	count, err := m.db.Client.Database(m.dbname).Collection("alerts").
+		CountDocuments(context.TODO(), bson.M{"alertCode": model.AlertCodeNoData})
+	require.NoError(m.T(), err)
+	require.Equal(m.T(), int64(0), count)
+}
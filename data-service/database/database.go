@@ -34,11 +34,16 @@ import (
 
 type MongoDatabaseInterface interface {
 	Init()
-	DismissHost(hostname string) error
+	// DismissHost archive the current hostdata of the host, as dismissed at dismissedAt
+	DismissHost(hostname string, dismissedAt time.Time) error
+	// DismissHostDataOlderThan archive the current hostdata of the host created before t, as dismissed at t
+	DismissHostDataOlderThan(hostname string, t time.Time) error
 	InsertHostData(hostData model.HostDataBE) error
 	ExistsDR(hostname string) bool
 	GetClusterVeritasLicenseByHostnames(hostnames []string) ([]model.OracleDatabaseLicense, error)
 	GetCurrentHostnames() ([]string, error)
+	// GetCurrentLocations return the locations of the current hosts
+	GetCurrentLocations() ([]string, error)
 	// FindOldCurrentHostnames return the list of current hosts names that haven't sent hostdata after time t
 	FindOldCurrentHostnames(t time.Time) ([]string, error)
 	FindOldCurrentHostdata(hostName string, t time.Time) (bool, error)
@@ -46,12 +51,16 @@ type MongoDatabaseInterface interface {
 	FindOldArchivedHosts(t time.Time) ([]primitive.ObjectID, error)
 	GetActiveHostdata() ([]model.HostDataBE, error)
 	DeleteHostData(id primitive.ObjectID) error
-	HistoricizeLicensesCompliance(licenses []dto.LicenseCompliance) error
+	// HistoricizeLicensesCompliance save the licenses compliance of today, of the location or global if location is empty
+	HistoricizeLicensesCompliance(licenses []dto.LicenseCompliance, location string) error
 
 	DeleteNoDataAlertByHost(hostname string) error
 	DeleteAllNoDataAlerts() error
+	DeleteNoDataAlertsExcept(hostnames []string) error
 	// FindMostRecentHostDataOlderThan return the most recest hostdata that is older than t
 	FindMostRecentHostDataOlderThan(hostname string, t time.Time) (*model.HostDataBE, error)
+	// FindLeastRecentHostDataNotOlderThan return the least recent hostdata that isn't older than t
+	FindLeastRecentHostDataNotOlderThan(hostname string, t time.Time) (*model.HostDataBE, error)
 	GetHostnames() ([]string, error)
 	GetOracleDatabaseLicenseTypes() ([]model.OracleDatabaseLicenseType, error)
 	InsertOracleLicenseType(licenseType model.OracleDatabaseLicenseType) error
@@ -62,6 +71,29 @@ type MongoDatabaseInterface interface {
 	PushComponentToExadataInstance(rackID string, component model.OracleExadataComponent) error
 	SetExadataComponent(rackID string, component model.OracleExadataComponent) error
 	UpdateExadataHidden(rackID string, hidden bool) error
+
+	// EnqueueHostData insert the submission in the queue
+	EnqueueHostData(submission model.HostDataSubmission) error
+	// ClaimHostDataSubmission set as processing and return the oldest queued submission ready at t
+	ClaimHostDataSubmission(t time.Time, excludedHostnames []string) (*model.HostDataSubmission, error)
+	CompleteHostDataSubmission(id primitive.ObjectID, processedAt time.Time) error
+	RetryHostDataSubmission(id primitive.ObjectID, lastError string, nextAttemptAt time.Time) error
+	// SetHostDataSubmissionChecked replace the hostdata of the submission with the checked one, that is going to be inserted
+	SetHostDataSubmissionChecked(id primitive.ObjectID, hostdata model.HostDataBE) error
+	DeadLetterHostDataSubmission(submission model.HostDataSubmission) error
+	// ReleaseHostDataSubmissions queue again the submissions left as processing
+	ReleaseHostDataSubmissions() error
+	FindHostDataSubmission(id primitive.ObjectID) (*model.HostDataSubmission, error)
+	// DeleteHostDataSubmissionsProcessedBefore remove the submissions processed before t and return how many they were
+	DeleteHostDataSubmissionsProcessedBefore(t time.Time) (int64, error)
+
+	// RecordAgentPayload count an hostdata received from an agent, rejected if lastError isn't empty
+	RecordAgentPayload(agentVersion string, schemaVersion int, upcasted bool, lastError string, t time.Time) error
+	ListAgentVersionStats() ([]model.AgentVersionStats, error)
+
+	// GetAgentCredential return the credential enrolled for the agent of the host
+	GetAgentCredential(hostname string) (*model.AgentCredential, error)
+	UpdateAgentCredentialLastUsed(id primitive.ObjectID, lastUsedAt time.Time) error
 }
 
 type MongoDatabase struct {
@@ -0,0 +1,230 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const (
+	hostDataQueueCollection       = "hostdata_queue"
+	hostDataDeadLettersCollection = "hostdata_dead_letters"
+)
+
+// EnqueueHostData insert the submission in the queue
+func (md *MongoDatabase) EnqueueHostData(submission model.HostDataSubmission) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(hostDataQueueCollection).
+		InsertOne(context.TODO(), submission)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// ClaimHostDataSubmission set as processing and return the oldest queued submission ready at t,
+// skipping the ones of excludedHostnames. A submission is claimed only if it's the oldest one of its host
+// still queued or processing, so a submission waiting to be retried holds the newer ones of the same host.
+// It return nil if there isn't any
+func (md *MongoDatabase) ClaimHostDataSubmission(t time.Time, excludedHostnames []string) (*model.HostDataSubmission, error) {
+	if excludedHostnames == nil {
+		excludedHostnames = []string{}
+	}
+
+	collection := md.Client.Database(md.Config.Mongodb.DBName).Collection(hostDataQueueCollection)
+
+	cur, err := collection.Aggregate(context.TODO(), bson.A{
+		bson.M{"$match": bson.M{
+			"status": bson.M{"$in": bson.A{model.HostDataSubmissionStatusQueued, model.HostDataSubmissionStatusProcessing}},
+		}},
+		bson.M{"$sort": bson.D{{Key: "submittedAt", Value: 1}}},
+		bson.M{"$group": bson.M{
+			"_id":           "$hostname",
+			"id":            bson.M{"$first": "$_id"},
+			"status":        bson.M{"$first": "$status"},
+			"nextAttemptAt": bson.M{"$first": "$nextAttemptAt"},
+			"submittedAt":   bson.M{"$first": "$submittedAt"},
+		}},
+		bson.M{"$match": bson.M{
+			"_id":           bson.M{"$nin": excludedHostnames},
+			"status":        model.HostDataSubmissionStatusQueued,
+			"nextAttemptAt": bson.M{"$lte": t},
+		}},
+		bson.M{"$sort": bson.D{{Key: "submittedAt", Value: 1}}},
+		bson.M{"$limit": 1},
+	})
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	var candidates []struct {
+		ID primitive.ObjectID `bson:"id"`
+	}
+	if err := cur.All(context.TODO(), &candidates); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// the status is checked again, the submission could have been claimed meanwhile by another service
+	filter := bson.M{
+		"_id":    candidates[0].ID,
+		"status": model.HostDataSubmissionStatusQueued,
+	}
+	update := bson.M{
+		"$set": bson.M{"status": model.HostDataSubmissionStatusProcessing},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var out model.HostDataSubmission
+
+	err = collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return &out, nil
+}
+
+// CompleteHostDataSubmission set the submission as done, removing its hostdata from the queue
+func (md *MongoDatabase) CompleteHostDataSubmission(id primitive.ObjectID, processedAt time.Time) error {
+	return md.updateHostDataSubmission(id, bson.M{
+		"$set": bson.M{
+			"status":      model.HostDataSubmissionStatusDone,
+			"processedAt": processedAt,
+		},
+		"$unset": bson.M{"lastError": "", "hostData": ""},
+	})
+}
+
+// RetryHostDataSubmission queue again the submission to be processed after nextAttemptAt
+func (md *MongoDatabase) RetryHostDataSubmission(id primitive.ObjectID, lastError string, nextAttemptAt time.Time) error {
+	return md.updateHostDataSubmission(id, bson.M{
+		"$set": bson.M{
+			"status":        model.HostDataSubmissionStatusQueued,
+			"lastError":     lastError,
+			"nextAttemptAt": nextAttemptAt,
+		},
+	})
+}
+
+// SetHostDataSubmissionChecked replace the hostdata of the submission with the checked one, that is going to be inserted.
+// The checks and their alerts aren't repeated if the submission is retried
+func (md *MongoDatabase) SetHostDataSubmissionChecked(id primitive.ObjectID, hostdata model.HostDataBE) error {
+	return md.updateHostDataSubmission(id, bson.M{
+		"$set": bson.M{
+			"hostDataID": hostdata.ID,
+			"hostData":   hostdata,
+		},
+	})
+}
+
+// DeadLetterHostDataSubmission move the submission from the queue to the dead letters
+func (md *MongoDatabase) DeadLetterHostDataSubmission(submission model.HostDataSubmission) error {
+	submission.Status = model.HostDataSubmissionStatusFailed
+
+	db := md.Client.Database(md.Config.Mongodb.DBName)
+
+	if _, err := db.Collection(hostDataDeadLettersCollection).InsertOne(context.TODO(), submission); err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if _, err := db.Collection(hostDataQueueCollection).DeleteOne(context.TODO(), bson.M{"_id": submission.ID}); err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// ReleaseHostDataSubmissions queue again the submissions left as processing,
+// e.g. by a service that stopped while it was processing them
+func (md *MongoDatabase) ReleaseHostDataSubmissions() error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(hostDataQueueCollection).
+		UpdateMany(context.TODO(),
+			bson.M{"status": model.HostDataSubmissionStatusProcessing},
+			bson.M{"$set": bson.M{"status": model.HostDataSubmissionStatusQueued}},
+		)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// FindHostDataSubmission return the submission with id, looking for it in the queue and in the dead letters
+func (md *MongoDatabase) FindHostDataSubmission(id primitive.ObjectID) (*model.HostDataSubmission, error) {
+	db := md.Client.Database(md.Config.Mongodb.DBName)
+
+	for _, collection := range []string{hostDataQueueCollection, hostDataDeadLettersCollection} {
+		var out model.HostDataSubmission
+
+		err := db.Collection(collection).
+			FindOne(context.TODO(), bson.M{"_id": id}, options.FindOne().SetProjection(bson.M{"hostData": 0})).
+			Decode(&out)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		} else if err != nil {
+			return nil, utils.NewError(err, "DB ERROR")
+		}
+
+		return &out, nil
+	}
+
+	return nil, utils.ErrHostDataSubmissionNotFound
+}
+
+// DeleteHostDataSubmissionsProcessedBefore remove the submissions processed before t and return how many they were
+func (md *MongoDatabase) DeleteHostDataSubmissionsProcessedBefore(t time.Time) (int64, error) {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(hostDataQueueCollection).
+		DeleteMany(context.TODO(), bson.M{
+			"status":      model.HostDataSubmissionStatusDone,
+			"processedAt": bson.M{"$lt": t},
+		})
+	if err != nil {
+		return 0, utils.NewError(err, "DB ERROR")
+	}
+
+	return res.DeletedCount, nil
+}
+
+func (md *MongoDatabase) updateHostDataSubmission(id primitive.ObjectID, update bson.M) error {
+	res, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(hostDataQueueCollection).
+		UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	if res.MatchedCount == 0 {
+		return utils.ErrHostDataSubmissionNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (m *MongodbSuite) TestHostDataQueue() {
+	defer m.db.Client.Database(m.dbname).Collection(hostDataQueueCollection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(hostDataDeadLettersCollection).DeleteMany(context.TODO(), bson.M{})
+
+	older := model.HostDataSubmission{
+		ID:            utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+		Hostname:      "foobar",
+		Status:        model.HostDataSubmissionStatusQueued,
+		SubmittedAt:   utils.P("2019-11-05T14:02:03Z"),
+		NextAttemptAt: utils.P("2019-11-05T14:02:03Z"),
+		HostData:      &model.HostDataBE{Hostname: "foobar"},
+	}
+	newer := model.HostDataSubmission{
+		ID:            utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+		Hostname:      "foobar",
+		Status:        model.HostDataSubmissionStatusQueued,
+		SubmittedAt:   utils.P("2019-11-05T14:03:03Z"),
+		NextAttemptAt: utils.P("2019-11-05T14:03:03Z"),
+		HostData:      &model.HostDataBE{Hostname: "foobar"},
+	}
+	other := model.HostDataSubmission{
+		ID:            utils.Str2oid("cccccccccccccccccccccccc"),
+		Hostname:      "barfoo",
+		Status:        model.HostDataSubmissionStatusQueued,
+		SubmittedAt:   utils.P("2019-11-05T14:04:03Z"),
+		NextAttemptAt: utils.P("2019-11-05T14:04:03Z"),
+		HostData:      &model.HostDataBE{Hostname: "barfoo"},
+	}
+
+	for _, submission := range []model.HostDataSubmission{newer, older, other} {
+		require.NoError(m.T(), m.db.EnqueueHostData(submission))
+	}
+
+	m.T().Run("should_not_claim_submissions_not_ready", func(t *testing.T) {
+		actual, err := m.db.ClaimHostDataSubmission(utils.P("2019-11-05T14:00:00Z"), nil)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	m.T().Run("should_claim_the_oldest_submission", func(t *testing.T) {
+		actual, err := m.db.ClaimHostDataSubmission(utils.P("2019-11-05T15:00:00Z"), nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, older.ID, actual.ID)
+		assert.Equal(t, model.HostDataSubmissionStatusProcessing, actual.Status)
+		assert.Equal(t, 1, actual.Attempts)
+		assert.Equal(t, "foobar", actual.HostData.Hostname)
+	})
+
+	m.T().Run("should_skip_excluded_hostnames", func(t *testing.T) {
+		actual, err := m.db.ClaimHostDataSubmission(utils.P("2019-11-05T15:00:00Z"), []string{"foobar"})
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, other.ID, actual.ID)
+	})
+
+	m.T().Run("should_retry_submission", func(t *testing.T) {
+		err := m.db.RetryHostDataSubmission(older.ID, "DB ERROR", utils.P("2019-11-05T16:00:00Z"))
+		require.NoError(t, err)
+
+		actual, err := m.db.FindHostDataSubmission(older.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.HostDataSubmissionStatusQueued, actual.Status)
+		assert.Equal(t, "DB ERROR", actual.LastError)
+		assert.Equal(t, utils.P("2019-11-05T16:00:00Z"), actual.NextAttemptAt)
+		assert.Nil(t, actual.HostData)
+	})
+
+	m.T().Run("should_not_claim_newer_submissions_of_a_host_waiting_a_retry", func(t *testing.T) {
+		actual, err := m.db.ClaimHostDataSubmission(utils.P("2019-11-05T15:00:00Z"), nil)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	m.T().Run("should_set_submission_checked", func(t *testing.T) {
+		hostdata := model.HostDataBE{
+			ID:        older.ID,
+			Hostname:  "foobar",
+			CreatedAt: older.SubmittedAt,
+		}
+		require.NoError(t, m.db.SetHostDataSubmissionChecked(older.ID, hostdata))
+
+		actual, err := m.db.FindHostDataSubmission(older.ID)
+		require.NoError(t, err)
+		require.NotNil(t, actual.HostDataID)
+		assert.Equal(t, older.ID, *actual.HostDataID)
+		assert.Equal(t, model.HostDataSubmissionStatusQueued, actual.Status)
+	})
+
+	m.T().Run("should_complete_submission", func(t *testing.T) {
+		err := m.db.CompleteHostDataSubmission(other.ID, utils.P("2019-11-05T15:01:00Z"))
+		require.NoError(t, err)
+
+		actual, err := m.db.FindHostDataSubmission(other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.HostDataSubmissionStatusDone, actual.Status)
+		require.NotNil(t, actual.ProcessedAt)
+		assert.Equal(t, utils.P("2019-11-05T15:01:00Z"), *actual.ProcessedAt)
+	})
+
+	m.T().Run("should_release_processing_submissions", func(t *testing.T) {
+		claimed, err := m.db.ClaimHostDataSubmission(utils.P("2019-11-05T16:00:00Z"), nil)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, older.ID, claimed.ID)
+
+		require.NoError(t, m.db.ReleaseHostDataSubmissions())
+
+		actual, err := m.db.FindHostDataSubmission(older.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.HostDataSubmissionStatusQueued, actual.Status)
+		assert.Equal(t, 2, actual.Attempts)
+	})
+
+	m.T().Run("should_move_submission_to_dead_letters", func(t *testing.T) {
+		submission := newer
+		submission.LastError = "DB ERROR"
+		require.NoError(t, m.db.DeadLetterHostDataSubmission(submission))
+
+		count, err := m.db.Client.Database(m.dbname).Collection(hostDataQueueCollection).
+			CountDocuments(context.TODO(), bson.M{"_id": newer.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		actual, err := m.db.FindHostDataSubmission(newer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.HostDataSubmissionStatusFailed, actual.Status)
+		assert.Equal(t, "DB ERROR", actual.LastError)
+	})
+
+	m.T().Run("should_delete_processed_submissions", func(t *testing.T) {
+		deleted, err := m.db.DeleteHostDataSubmissionsProcessedBefore(utils.P("2019-11-05T15:01:00Z"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		deleted, err = m.db.DeleteHostDataSubmissionsProcessedBefore(utils.P("2019-11-05T15:02:00Z"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		_, err = m.db.FindHostDataSubmission(other.ID)
+		assert.ErrorIs(t, err, utils.ErrHostDataSubmissionNotFound)
+
+		_, err = m.db.FindHostDataSubmission(older.ID)
+		require.NoError(t, err)
+	})
+
+	m.T().Run("should_not_find_submission", func(t *testing.T) {
+		_, err := m.db.FindHostDataSubmission(utils.Str2oid("dddddddddddddddddddddddd"))
+		assert.ErrorIs(t, err, utils.ErrHostDataSubmissionNotFound)
+
+		err = m.db.CompleteHostDataSubmission(utils.Str2oid("dddddddddddddddddddddddd"), utils.P("2019-11-05T15:01:00Z"))
+		assert.ErrorIs(t, err, utils.ErrHostDataSubmissionNotFound)
+	})
+}
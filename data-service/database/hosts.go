@@ -27,12 +27,12 @@ import (
 	"github.com/ercole-io/ercole/v2/utils"
 )
 
-func (md *MongoDatabase) DismissHost(hostname string) error {
+func (md *MongoDatabase) DismissHost(hostname string, dismissedAt time.Time) error {
 	if _, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").UpdateOne(context.TODO(), bson.M{
 		"hostname":    hostname,
 		"dismissedAt": nil,
 	}, mu.UOSet(bson.M{
-		"dismissedAt": time.Now(),
+		"dismissedAt": dismissedAt,
 		"archived":    true,
 	})); err != nil {
 		return utils.NewError(err, "DB ERROR")
@@ -41,6 +41,23 @@ func (md *MongoDatabase) DismissHost(hostname string) error {
 	}
 }
 
+// DismissHostDataOlderThan archive the current hostdata of the host created before t, as dismissed at t.
+// It's called after inserting the new hostdata, so the host is never left without a current one
+func (md *MongoDatabase) DismissHostDataOlderThan(hostname string, t time.Time) error {
+	if _, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").UpdateMany(context.TODO(), bson.M{
+		"hostname":    hostname,
+		"dismissedAt": nil,
+		"createdAt":   bson.M{"$lt": t},
+	}, mu.UOSet(bson.M{
+		"dismissedAt": t,
+		"archived":    true,
+	})); err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
 func (md *MongoDatabase) InsertHostData(hostData model.HostDataBE) error {
 	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").InsertOne(context.TODO(), hostData)
 	if err != nil {
@@ -70,6 +87,29 @@ func (md *MongoDatabase) GetCurrentHostnames() ([]string, error) {
 	return hosts, nil
 }
 
+// GetCurrentLocations return the locations of the current hosts
+func (md *MongoDatabase) GetCurrentLocations() ([]string, error) {
+	values, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").Distinct(
+		context.TODO(),
+		"location",
+		bson.M{
+			"dismissedAt": nil,
+			"archived":    false,
+		})
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	var locations = make([]string, 0)
+	for _, val := range values {
+		if location, ok := val.(string); ok && location != "" {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations, nil
+}
+
 // FindOldCurrentHostnames return the list of current hosts that haven't sent hostdata after time t
 func (md *MongoDatabase) FindOldCurrentHostnames(t time.Time) ([]string, error) {
 	values, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").Distinct(
@@ -212,6 +252,39 @@ func (md *MongoDatabase) FindMostRecentHostDataOlderThan(hostname string, t time
 	return &out, nil
 }
 
+// FindLeastRecentHostDataNotOlderThan return the least recent hostdata that isn't older than t
+func (md *MongoDatabase) FindLeastRecentHostDataNotOlderThan(hostname string, t time.Time) (*model.HostDataBE, error) {
+	var out model.HostDataBE
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").Aggregate(
+		context.TODO(),
+		mu.MAPipeline(
+			mu.APMatch(bson.M{
+				"hostname":  hostname,
+				"createdAt": bson.M{"$gte": t},
+			}),
+			mu.APSort(bson.M{
+				"createdAt": 1,
+			}),
+			mu.APLimit(1),
+		),
+	)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	hasNext := cur.Next(context.TODO())
+	if !hasNext {
+		return nil, nil
+	}
+
+	if err := cur.Decode(&out); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return &out, nil
+}
+
 func (md *MongoDatabase) GetHostnames() ([]string, error) {
 	values, err := md.Client.Database(md.Config.Mongodb.DBName).Collection("hosts").
 		Distinct(
@@ -37,7 +37,7 @@ func (m *MongodbSuite) TestDismissHost() {
 	require.NoError(m.T(), err)
 	require.Equal(m.T(), []string{"test-small"}, list)
 
-	err = m.db.DismissHost("test-small")
+	err = m.db.DismissHost("test-small", utils.P("2019-11-05T14:02:03Z"))
 	require.NoError(m.T(), err)
 
 	list, err = m.db.FindOldCurrentHostnames(utils.MAX_TIME)
@@ -45,6 +45,36 @@ func (m *MongodbSuite) TestDismissHost() {
 	require.Equal(m.T(), []string{}, list)
 }
 
+func (m *MongodbSuite) TestDismissHostDataOlderThan() {
+	defer m.db.Client.Database(m.dbname).Collection("hosts").DeleteMany(context.TODO(), bson.M{})
+
+	older := model.HostDataBE{
+		ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+		Hostname:  "foobar",
+		CreatedAt: utils.P("2019-11-05T14:00:00Z"),
+	}
+	newer := model.HostDataBE{
+		ID:        utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+		Hostname:  "foobar",
+		CreatedAt: utils.P("2019-11-05T14:02:03Z"),
+	}
+	require.NoError(m.T(), m.db.InsertHostData(older))
+	require.NoError(m.T(), m.db.InsertHostData(newer))
+
+	err := m.db.DismissHostDataOlderThan("foobar", newer.CreatedAt)
+	require.NoError(m.T(), err)
+
+	list, err := m.db.FindOldCurrentHostnames(utils.MAX_TIME)
+	require.NoError(m.T(), err)
+	require.Equal(m.T(), []string{"foobar"}, list)
+
+	var actual model.HostDataBE
+	err = m.db.Client.Database(m.dbname).Collection("hosts").FindOne(context.TODO(), bson.M{"_id": older.ID}).Decode(&actual)
+	require.NoError(m.T(), err)
+	assert.True(m.T(), actual.Archived)
+	assert.Equal(m.T(), newer.CreatedAt, actual.DismissedAt)
+}
+
 func (m *MongodbSuite) TestInsertHostData() {
 	defer m.db.Client.Database(m.dbname).Collection("hosts").DeleteMany(context.TODO(), bson.M{})
 
@@ -121,6 +151,18 @@ func (m *MongodbSuite) TestGetCurrentHostnames() {
 	})
 }
 
+func (m *MongodbSuite) TestGetCurrentLocations() {
+	defer m.db.Client.Database(m.dbname).Collection("hosts").DeleteMany(context.TODO(), bson.M{})
+
+	m.InsertHostData(mongoutils.LoadFixtureMongoHostDataMap(m.T(), "../../fixture/test_apiservice_mongohostdata_01.json"))
+	m.InsertHostData(mongoutils.LoadFixtureMongoHostDataMap(m.T(), "../../fixture/test_apiservice_mongohostdata_04.json"))
+	m.InsertHostData(mongoutils.LoadFixtureMongoHostDataMap(m.T(), "../../fixture/test_apiservice_mongohostdata_06.json"))
+
+	list, err := m.db.GetCurrentLocations()
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), []string{"Italy"}, list)
+}
+
 func (m *MongodbSuite) TestFindOldCurrentHostnames() {
 	defer m.db.Client.Database(m.dbname).Collection("hosts").DeleteMany(context.TODO(), bson.M{})
 	m.InsertHostData(mongoutils.LoadFixtureMongoHostDataMap(m.T(), "../../fixture/test_apiservice_mongohostdata_01.json"))
@@ -254,3 +296,28 @@ func (m *MongodbSuite) TestGetHostnames() {
 	})
 
 }
+
+func (m *MongodbSuite) TestFindLeastRecentHostDataNotOlderThan() {
+	defer m.db.Client.Database(m.dbname).Collection("hosts").DeleteMany(context.TODO(), bson.M{})
+
+	for i, createdAt := range []string{"2024-01-01T10:00:00Z", "2024-02-01T10:00:00Z", "2024-03-01T10:00:00Z"} {
+		require.NoError(m.T(), m.db.InsertHostData(model.HostDataBE{
+			ID:        primitive.NewObjectIDFromTimestamp(utils.P(createdAt)),
+			Archived:  i < 2,
+			CreatedAt: utils.P(createdAt),
+			Hostname:  "rac1-x",
+		}))
+	}
+
+	actual, err := m.db.FindLeastRecentHostDataNotOlderThan("rac1-x", utils.P("2024-01-15T00:00:00Z"))
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), utils.P("2024-02-01T10:00:00Z"), actual.CreatedAt.UTC())
+
+	actual, err = m.db.FindLeastRecentHostDataNotOlderThan("rac1-x", utils.P("2024-02-01T10:00:00Z"))
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), utils.P("2024-02-01T10:00:00Z"), actual.CreatedAt.UTC())
+
+	actual, err = m.db.FindLeastRecentHostDataNotOlderThan("rac1-x", utils.P("2024-03-02T00:00:00Z"))
+	require.NoError(m.T(), err)
+	assert.Nil(m.T(), actual)
+}
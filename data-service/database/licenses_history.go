@@ -29,18 +29,18 @@ import (
 
 const licensesHistoryCollection = "database_licenses_history"
 
-func (md *MongoDatabase) HistoricizeLicensesCompliance(licenses []dto.LicenseCompliance) error {
+func (md *MongoDatabase) HistoricizeLicensesCompliance(licenses []dto.LicenseCompliance, location string) error {
 	now := md.TimeNow()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
 	for _, license := range licenses {
-		done, err := md.updateLicenseComplianceHistoric(license, today)
+		done, err := md.updateLicenseComplianceHistoric(license, location, today)
 		if err != nil {
 			return err
 		}
 
 		if !done {
-			err := md.insertLicenseComplianceHistoric(license, today)
+			err := md.insertLicenseComplianceHistoric(license, location, today)
 			if err != nil {
 				return err
 			}
@@ -50,9 +50,20 @@ func (md *MongoDatabase) HistoricizeLicensesCompliance(licenses []dto.LicenseCom
 	return nil
 }
 
-func (md *MongoDatabase) updateLicenseComplianceHistoric(license dto.LicenseCompliance, today time.Time) (done bool, err error) {
+// licensesHistoryLocation return the filter of the history of a location.
+// The global history has no location
+func licensesHistoryLocation(location string) interface{} {
+	if location == "" {
+		return bson.M{"$exists": false}
+	}
+
+	return location
+}
+
+func (md *MongoDatabase) updateLicenseComplianceHistoric(license dto.LicenseCompliance, location string, today time.Time) (done bool, err error) {
 	filter := bson.M{
 		"licenseTypeID": license.LicenseTypeID,
+		"location":      licensesHistoryLocation(location),
 		"history":       bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "date", Value: today}}}},
 	}
 
@@ -85,9 +96,10 @@ func (md *MongoDatabase) updateLicenseComplianceHistoric(license dto.LicenseComp
 	return true, nil
 }
 
-func (md *MongoDatabase) insertLicenseComplianceHistoric(license dto.LicenseCompliance, today time.Time) error {
+func (md *MongoDatabase) insertLicenseComplianceHistoric(license dto.LicenseCompliance, location string, today time.Time) error {
 	filter := bson.M{
 		"licenseTypeID": license.LicenseTypeID,
+		"location":      licensesHistoryLocation(location),
 	}
 
 	if len(license.LicenseTypeID) == 0 {
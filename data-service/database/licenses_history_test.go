@@ -79,7 +79,7 @@ func (m *MongodbSuite) TestHistoricizeLicensesCompliance() {
 				Unlimited:       false,
 			},
 		}
-		err := m.db.HistoricizeLicensesCompliance(licenses)
+		err := m.db.HistoricizeLicensesCompliance(licenses, "")
 		require.NoError(m.T(), err)
 
 		cur, err := m.db.Client.Database(m.db.Config.Mongodb.DBName).
@@ -160,7 +160,7 @@ func (m *MongodbSuite) TestHistoricizeLicensesCompliance() {
 				Unlimited:       false,
 			},
 		}
-		err := m.db.HistoricizeLicensesCompliance(licenses)
+		err := m.db.HistoricizeLicensesCompliance(licenses, "")
 		require.NoError(m.T(), err)
 
 		cur, err := m.db.Client.Database(m.db.Config.Mongodb.DBName).
@@ -214,7 +214,7 @@ func (m *MongodbSuite) TestHistoricizeLicensesCompliance() {
 				Unlimited:       false,
 			},
 		}
-		err := m.db.HistoricizeLicensesCompliance(licenses)
+		err := m.db.HistoricizeLicensesCompliance(licenses, "")
 		require.NoError(m.T(), err)
 
 		cur, err := m.db.Client.Database(m.db.Config.Mongodb.DBName).
@@ -252,4 +252,39 @@ func (m *MongodbSuite) TestHistoricizeLicensesCompliance() {
 
 		assert.ElementsMatch(m.T(), expected, actual)
 	})
+
+	m.T().Run("Location insert, doesn't change global history", func(t *testing.T) {
+		m.db.TimeNow = func() time.Time { return utils.P("2020-12-06T16:02:03+02:00") }
+
+		licenses := []dto.LicenseCompliance{
+			{
+				LicenseTypeID:   "A90611",
+				ItemDescription: "Oracle Database Enterprise Edition",
+				Metric:          "Processor Perpetual",
+				Consumed:        4,
+				Covered:         2,
+				Purchased:       2,
+			},
+		}
+		err := m.db.HistoricizeLicensesCompliance(licenses, "Italy")
+		require.NoError(m.T(), err)
+
+		var global map[string]interface{}
+		err = m.db.Client.Database(m.db.Config.Mongodb.DBName).
+			Collection("database_licenses_history").
+			FindOne(context.TODO(), bson.M{"licenseTypeID": "A90611", "location": bson.M{"$exists": false}}).
+			Decode(&global)
+		require.NoError(m.T(), err)
+		assert.Len(m.T(), global["history"], 2)
+
+		var italy map[string]interface{}
+		err = m.db.Client.Database(m.db.Config.Mongodb.DBName).
+			Collection("database_licenses_history").
+			FindOne(context.TODO(), bson.M{"licenseTypeID": "A90611", "location": "Italy"}).
+			Decode(&italy)
+		require.NoError(m.T(), err)
+
+		expectedDateDay2 := utils.PDT("2020-12-06T00:00:00+02:00")
+		assert.Equal(m.T(), primitive.A{map[string]interface{}{"consumed": 4.0, "covered": 2.0, "purchased": 2.0, "date": expectedDateDay2}}, italy["history"])
+	})
 }
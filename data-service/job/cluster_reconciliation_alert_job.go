@@ -0,0 +1,111 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package job
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	alert_service_client "github.com/ercole-io/ercole/v2/alert-service/client"
+	api_service_client "github.com/ercole-io/ercole/v2/api-service/client"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+// ClusterReconciliationAlertJob is the job used to check the consistency between the clusters and the hosts
+type ClusterReconciliationAlertJob struct {
+	// TimeNow contains a function that return the current time
+	TimeNow func() time.Time
+	// AlertSvcClient
+	AlertSvcClient alert_service_client.AlertSvcClientInterface
+	// ApiSvcClient
+	ApiSvcClient api_service_client.ApiSvcClientInterface
+	// Config contains the dataservice global configuration
+	Config config.Configuration
+	// Log contains logger formatted
+	Log logger.Logger
+	// NewObjectID return a new ObjectID
+	NewObjectID func() primitive.ObjectID
+}
+
+// Run throws a CLUSTER_TOPOLOGY_MISMATCH alert for each inconsistency between the clusters and the hosts,
+// of each kind and host. An alert still open, new or acked, is folded with the last details of the inconsistency.
+// The open CLUSTER_TOPOLOGY_MISMATCH alerts whose inconsistency disappeared are resolved
+func (job *ClusterReconciliationAlertJob) Run() {
+	openAlerts, err := getOpenAlerts(job.ApiSvcClient, model.AlertCodeClusterTopologyMismatch)
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	reconciliation, err := job.ApiSvcClient.GetClusterReconciliation()
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	found := make(map[string]bool, len(reconciliation.Issues))
+
+	for _, issue := range reconciliation.Issues {
+		key := clusterReconciliationKey(issue.Kind, issue.Hostname)
+		if found[key] {
+			continue
+		}
+
+		found[key] = true
+
+		if err := job.AlertSvcClient.ThrowNewAlert(job.clusterTopologyMismatchAlert(issue)); err != nil {
+			job.Log.Error(err)
+		}
+	}
+
+	for _, alert := range openAlerts {
+		if found[clusterReconciliationKey(alert.OtherInfo["kind"], alert.OtherInfo["hostname"])] {
+			continue
+		}
+
+		if err := job.ApiSvcClient.ResolveAlert(alert.ID, "The cluster topology is consistent"); err != nil {
+			job.Log.Error(err)
+		}
+	}
+}
+
+func (job *ClusterReconciliationAlertJob) clusterTopologyMismatchAlert(issue dto.ClusterReconciliationIssue) model.Alert {
+	return model.Alert{
+		ID:            job.NewObjectID(),
+		AlertCategory: model.AlertCategoryLicense,
+		AlertCode:     model.AlertCodeClusterTopologyMismatch,
+		AlertSeverity: model.AlertSeverityWarning,
+		AlertStatus:   model.AlertStatusNew,
+		Date:          job.TimeNow(),
+		Description:   fmt.Sprintf("Cluster topology mismatch of %s (%s): %s", issue.Hostname, issue.Kind, issue.Description),
+		OtherInfo: map[string]interface{}{
+			"hostname":       issue.Hostname,
+			"kind":           issue.Kind,
+			"vmName":         issue.VMName,
+			"clusters":       issue.Clusters,
+			"licenseTypeIDs": issue.LicenseTypeIDs,
+			"location":       issue.Location,
+		},
+	}
+}
+
+func clusterReconciliationKey(kind, hostname interface{}) string {
+	return fmt.Sprintf("%v/%v", kind, hostname)
+}
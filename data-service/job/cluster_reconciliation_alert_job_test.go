@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestClusterReconciliationAlertJobRun(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	asc := NewMockAlertSvcClientInterface(mockCtrl)
+	apiSvc := NewMockApiSvcClientInterface(mockCtrl)
+
+	job := ClusterReconciliationAlertJob{
+		TimeNow:        utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		AlertSvcClient: asc,
+		ApiSvcClient:   apiSvc,
+		Log:            logger.NewLogger("TEST"),
+		NewObjectID:    utils.NewObjectIDForTests(),
+	}
+
+	newAlerts := []model.Alert{
+		{
+			ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+			AlertCode: model.AlertCodeClusterTopologyMismatch,
+			OtherInfo: map[string]interface{}{"kind": dto.ClusterIssueUnknownVM, "hostname": "foobar"},
+		},
+	}
+	ackedAlerts := []model.Alert{
+		{
+			ID:        utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+			AlertCode: model.AlertCodeClusterTopologyMismatch,
+			OtherInfo: map[string]interface{}{"kind": dto.ClusterIssueVMInMultipleClusters, "hostname": "foobar"},
+		},
+	}
+
+	apiSvc.EXPECT().GetAlertsByFilter(dto.AlertsFilter{
+		AlertCode:   utils.Str2ptr(model.AlertCodeClusterTopologyMismatch),
+		AlertStatus: utils.Str2ptr(model.AlertStatusNew),
+	}).Return(newAlerts, nil)
+	apiSvc.EXPECT().GetAlertsByFilter(dto.AlertsFilter{
+		AlertCode:   utils.Str2ptr(model.AlertCodeClusterTopologyMismatch),
+		AlertStatus: utils.Str2ptr(model.AlertStatusAck),
+	}).Return(ackedAlerts, nil)
+
+	apiSvc.EXPECT().GetClusterReconciliation().Return(&dto.ClusterReconciliation{
+		Issues: []dto.ClusterReconciliationIssue{
+			{Kind: dto.ClusterIssueUnknownVM, Hostname: "foobar", Clusters: []string{"Puzzait"}},
+			{
+				Kind:           dto.ClusterIssueCappedCPUMismatch,
+				Hostname:       "test-db",
+				VMName:         "test-db-vm",
+				Clusters:       []string{"Puzzait"},
+				LicenseTypeIDs: []string{"A90611"},
+				Location:       "Italy",
+				Description:    "The VM test-db-vm hasn't capped CPU",
+			},
+		},
+	}, nil)
+
+	asc.EXPECT().ThrowNewAlert(gomock.Any()).Return(nil).Do(func(alert model.Alert) {
+		assert.Equal(t, "foobar", alert.OtherInfo["hostname"])
+		assert.Equal(t, dto.ClusterIssueUnknownVM, alert.OtherInfo["kind"])
+	}).Times(1)
+	asc.EXPECT().ThrowNewAlert(gomock.Any()).Return(nil).Do(func(alert model.Alert) {
+		assert.Equal(t, model.AlertCodeClusterTopologyMismatch, alert.AlertCode)
+		assert.Equal(t, model.AlertCategoryLicense, alert.AlertCategory)
+		assert.Equal(t, model.AlertSeverityWarning, alert.AlertSeverity)
+		assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), alert.Date)
+		assert.Equal(t, "Cluster topology mismatch of test-db (CAPPED_CPU_MISMATCH): The VM test-db-vm hasn't capped CPU", alert.Description)
+		assert.Equal(t, map[string]interface{}{
+			"hostname":       "test-db",
+			"kind":           dto.ClusterIssueCappedCPUMismatch,
+			"vmName":         "test-db-vm",
+			"clusters":       []string{"Puzzait"},
+			"licenseTypeIDs": []string{"A90611"},
+			"location":       "Italy",
+		}, alert.OtherInfo)
+	}).Times(1)
+
+	apiSvc.EXPECT().ResolveAlert(utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"), "The cluster topology is consistent").Return(nil).Times(1)
+
+	job.Run()
+}
+
+func TestClusterReconciliationAlertJobRun_TwoKindsOnOneHost(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	asc := NewMockAlertSvcClientInterface(mockCtrl)
+	apiSvc := NewMockApiSvcClientInterface(mockCtrl)
+
+	job := ClusterReconciliationAlertJob{
+		TimeNow:        utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		AlertSvcClient: asc,
+		ApiSvcClient:   apiSvc,
+		Log:            logger.NewLogger("TEST"),
+		NewObjectID:    utils.NewObjectIDForTests(),
+	}
+
+	issues := []dto.ClusterReconciliationIssue{
+		{Kind: dto.ClusterIssueUnknownVM, Hostname: "foobar", Location: "Italy"},
+		{Kind: dto.ClusterIssueVMInMultipleClusters, Hostname: "foobar", Location: "Italy"},
+	}
+
+	t.Run("Both kinds are thrown as distinct alerts", func(t *testing.T) {
+		apiSvc.EXPECT().GetAlertsByFilter(gomock.Any()).Return([]model.Alert{}, nil).Times(2)
+		apiSvc.EXPECT().GetClusterReconciliation().Return(&dto.ClusterReconciliation{Issues: issues}, nil)
+
+		thrown := make([]model.Alert, 0)
+		asc.EXPECT().ThrowNewAlert(gomock.Any()).Return(nil).Do(func(alert model.Alert) {
+			thrown = append(thrown, alert)
+		}).Times(2)
+
+		job.Run()
+
+		assert.Len(t, thrown, 2)
+		for _, alert := range thrown {
+			assert.Contains(t, alert.IdentityKeys(), "kind")
+		}
+		assert.NotEqual(t, thrown[0].OtherInfo["kind"], thrown[1].OtherInfo["kind"])
+	})
+
+	t.Run("Only the cleared kind is resolved", func(t *testing.T) {
+		apiSvc.EXPECT().GetAlertsByFilter(gomock.Any()).Return([]model.Alert{
+			{
+				ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+				AlertCode: model.AlertCodeClusterTopologyMismatch,
+				OtherInfo: map[string]interface{}{"kind": dto.ClusterIssueUnknownVM, "hostname": "foobar", "location": "Italy"},
+			},
+			{
+				ID:        utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+				AlertCode: model.AlertCodeClusterTopologyMismatch,
+				OtherInfo: map[string]interface{}{"kind": dto.ClusterIssueVMInMultipleClusters, "hostname": "foobar", "location": "Italy"},
+			},
+		}, nil)
+		apiSvc.EXPECT().GetAlertsByFilter(gomock.Any()).Return([]model.Alert{}, nil)
+		apiSvc.EXPECT().GetClusterReconciliation().Return(&dto.ClusterReconciliation{Issues: issues[1:]}, nil)
+		asc.EXPECT().ThrowNewAlert(gomock.Any()).Return(nil).Do(func(alert model.Alert) {
+			assert.Equal(t, dto.ClusterIssueVMInMultipleClusters, alert.OtherInfo["kind"])
+		}).Times(1)
+		apiSvc.EXPECT().ResolveAlert(utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"), "The cluster topology is consistent").Return(nil).Times(1)
+
+		job.Run()
+	})
+}
+
+func TestClusterReconciliationAlertJobRun_GetReconciliationError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	apiSvc := NewMockApiSvcClientInterface(mockCtrl)
+
+	job := ClusterReconciliationAlertJob{
+		TimeNow:      utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		ApiSvcClient: apiSvc,
+		Log:          logger.NewLogger("TEST"),
+		NewObjectID:  utils.NewObjectIDForTests(),
+	}
+
+	apiSvc.EXPECT().GetAlertsByFilter(gomock.Any()).Return([]model.Alert{
+		{
+			ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+			AlertCode: model.AlertCodeClusterTopologyMismatch,
+			OtherInfo: map[string]interface{}{"kind": dto.ClusterIssueUnknownVM, "hostname": "foobar"},
+		},
+	}, nil)
+	apiSvc.EXPECT().GetAlertsByFilter(gomock.Any()).Return([]model.Alert{}, nil)
+	apiSvc.EXPECT().GetClusterReconciliation().Return(nil, aerrMock)
+
+	job.Run()
+}
@@ -46,7 +46,7 @@ func (job *CurrentHostCleaningJob) Run() {
 	}
 
 	for _, host := range hosts {
-		err := job.Database.DismissHost(host)
+		err := job.Database.DismissHost(host, job.TimeNow())
 		if err != nil {
 			job.Log.Error(err)
 			return
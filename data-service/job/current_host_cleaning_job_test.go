@@ -65,8 +65,8 @@ func TestCurrentHostCleaningJobRun_SuccessOldCurrentHosts(t *testing.T) {
 	}
 
 	db.EXPECT().FindOldCurrentHostnames(utils.P("2019-11-05T4:02:03Z")).Return([]string{"superhost", "pippohost"}, nil).Times(1)
-	db.EXPECT().DismissHost("superhost").Return(nil).Times(1)
-	db.EXPECT().DismissHost("pippohost").Return(nil).Times(1)
+	db.EXPECT().DismissHost("superhost", utils.P("2019-11-05T14:02:03Z")).Return(nil).Times(1)
+	db.EXPECT().DismissHost("pippohost", utils.P("2019-11-05T14:02:03Z")).Return(nil).Times(1)
 
 	chcj.Run()
 }
@@ -89,7 +89,7 @@ func TestCurrentHostCleaningJobRun_DatabaseError1(t *testing.T) {
 	}
 
 	db.EXPECT().FindOldCurrentHostnames(utils.P("2019-11-05T4:02:03Z")).Return([]string{"invalid"}, aerrMock).Times(1)
-	db.EXPECT().DismissHost(gomock.Any()).Times(0)
+	db.EXPECT().DismissHost(gomock.Any(), gomock.Any()).Times(0)
 
 	chcj.Run()
 }
@@ -112,8 +112,8 @@ func TestCurrentHostCleaningJobRun_DatabaseError2(t *testing.T) {
 	}
 
 	db.EXPECT().FindOldCurrentHostnames(utils.P("2019-11-05T4:02:03Z")).Return([]string{"superhost", "pippohost"}, nil).Times(1)
-	db.EXPECT().DismissHost("superhost").Return(aerrMock).Times(1)
-	db.EXPECT().DismissHost("pippohost").Times(0)
+	db.EXPECT().DismissHost("superhost", utils.P("2019-11-05T14:02:03Z")).Return(aerrMock).Times(1)
+	db.EXPECT().DismissHost("pippohost", gomock.Any()).Times(0)
 
 	chcj.Run()
 }
@@ -45,13 +45,11 @@ type FreshnessCheckJob struct {
 	NewObjectID func() primitive.ObjectID
 }
 
-// Run throws NO_DATA alert for each hosts that haven't sent a hostdata withing the host.Period (hours)
+// Run throws NO_DATA alert for each hosts that haven't sent a hostdata withing the host.Period (hours).
+// The NO_DATA alerts of the other hosts are deleted, while the ones of the hosts still stale
+// are kept open so that alert-service can fold the new occurrences into them.
+// The alerts of the hosts whose freshness can't be checked are kept too
 func (job *FreshnessCheckJob) Run() {
-	if err := job.Database.DeleteAllNoDataAlerts(); err != nil {
-		job.Log.Error(err)
-		return
-	}
-
 	hosts, err := job.Database.GetActiveHostdata()
 
 	if err != nil {
@@ -59,6 +57,9 @@ func (job *FreshnessCheckJob) Run() {
 		return
 	}
 
+	staleHosts := make([]model.HostDataBE, 0)
+	keptHostnames := make([]string, 0)
+
 	for _, host := range hosts {
 		var period time.Duration
 
@@ -71,32 +72,44 @@ func (job *FreshnessCheckJob) Run() {
 		isOld, err := job.Database.FindOldCurrentHostdata(host.Hostname, job.TimeNow().Add(-(period)*time.Hour))
 		if err != nil {
 			job.Log.Error(err)
+			keptHostnames = append(keptHostnames, host.Hostname)
+
 			continue
 		}
 
 		if isOld {
-			elapsed := job.TimeNow().Sub(host.CreatedAt)
-			elapsedDays := int(elapsed.Truncate(time.Hour*24).Hours() / 24)
-
-			alert := model.Alert{
-				ID:                      job.NewObjectID(),
-				AlertAffectedTechnology: nil,
-				AlertCategory:           model.AlertCategoryAgent,
-				AlertCode:               model.AlertCodeNoData,
-				AlertSeverity:           model.AlertSeverityCritical,
-				AlertStatus:             model.AlertStatusNew,
-				Date:                    job.TimeNow(),
-				Description:             fmt.Sprintf("No data received from the host %s in the last %d day(s)", host.Hostname, elapsedDays),
-				OtherInfo: map[string]interface{}{
-					"hostname": host.Hostname,
-				},
-			}
-
-			errAlert := job.AlertSvcClient.ThrowNewAlert(alert)
-			if errAlert != nil {
-				job.Log.Error(errAlert)
-				continue
-			}
+			staleHosts = append(staleHosts, host)
+			keptHostnames = append(keptHostnames, host.Hostname)
+		}
+	}
+
+	if err := job.Database.DeleteNoDataAlertsExcept(keptHostnames); err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	for _, host := range staleHosts {
+		elapsed := job.TimeNow().Sub(host.CreatedAt)
+		elapsedDays := int(elapsed.Truncate(time.Hour*24).Hours() / 24)
+
+		alert := model.Alert{
+			ID:                      job.NewObjectID(),
+			AlertAffectedTechnology: nil,
+			AlertCategory:           model.AlertCategoryAgent,
+			AlertCode:               model.AlertCodeNoData,
+			AlertSeverity:           model.AlertSeverityCritical,
+			AlertStatus:             model.AlertStatusNew,
+			Date:                    job.TimeNow(),
+			Description:             fmt.Sprintf("No data received from the host %s in the last %d day(s)", host.Hostname, elapsedDays),
+			OtherInfo: map[string]interface{}{
+				"hostname": host.Hostname,
+			},
+		}
+
+		errAlert := job.AlertSvcClient.ThrowNewAlert(alert)
+		if errAlert != nil {
+			job.Log.Error(errAlert)
+			continue
 		}
 	}
 }
@@ -61,12 +61,12 @@ func TestFreshnessCheckJobRun_SuccessNoOldCurrentHosts(t *testing.T) {
 		},
 	}
 
-	db.EXPECT().DeleteAllNoDataAlerts().Return(nil).Times(1)
-
 	db.EXPECT().GetActiveHostdata().Return(pippo, nil)
 
 	db.EXPECT().FindOldCurrentHostdata(pippo[0].Hostname, utils.P("2019-11-04T14:02:03Z")).Return(false, nil)
 
+	db.EXPECT().DeleteNoDataAlertsExcept([]string{}).Return(nil).Times(1)
+
 	fcj.Run()
 }
 
@@ -100,8 +100,6 @@ func TestFreshnessCheckJobRun_SuccessTwoOldCurrentHosts(t *testing.T) {
 		NewObjectID:    utils.NewObjectIDForTests(),
 	}
 
-	db.EXPECT().DeleteAllNoDataAlerts().Return(nil).Times(1)
-
 	pippo := model.HostDataBE{
 		Hostname:  "pippohost",
 		CreatedAt: utils.P("2019-10-05T14:02:03Z"),
@@ -130,6 +128,8 @@ func TestFreshnessCheckJobRun_SuccessTwoOldCurrentHosts(t *testing.T) {
 	db.EXPECT().FindOldCurrentHostdata(pluto.Hostname, utils.P("2019-11-04T14:02:03Z")).
 		Return(true, nil)
 
+	db.EXPECT().DeleteNoDataAlertsExcept([]string{pippo.Hostname, pluto.Hostname}).Return(nil).Times(1)
+
 	alert1 := model.Alert{
 		ID:                      utils.Str2oid("000000000000000000000001"),
 		AlertAffectedTechnology: nil,
@@ -165,7 +165,7 @@ func TestFreshnessCheckJobRun_SuccessTwoOldCurrentHosts(t *testing.T) {
 	fcj.Run()
 }
 
-func TestFreshnessCheckJobRun_DeleteAllNoDataAlertsError(t *testing.T) {
+func TestFreshnessCheckJobRun_DeleteNoDataAlertsError(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 	db := NewMockMongoDatabaseInterface(mockCtrl)
@@ -192,7 +192,16 @@ func TestFreshnessCheckJobRun_DeleteAllNoDataAlertsError(t *testing.T) {
 		NewObjectID:    utils.NewObjectIDForTests(),
 	}
 
-	db.EXPECT().DeleteAllNoDataAlerts().Return(aerrMock).Times(1)
+	pippo := []model.HostDataBE{
+		{
+			Hostname:  "pippohost",
+			CreatedAt: utils.P("2019-10-05T14:02:03Z"),
+		},
+	}
+
+	db.EXPECT().GetActiveHostdata().Return(pippo, nil)
+	db.EXPECT().FindOldCurrentHostdata(pippo[0].Hostname, utils.P("2019-11-04T14:02:03Z")).Return(true, nil)
+	db.EXPECT().DeleteNoDataAlertsExcept([]string{"pippohost"}).Return(aerrMock).Times(1)
 
 	fcj.Run()
 }
@@ -232,8 +241,8 @@ func TestFreshnessCheckJobRun_FindOldCurrentHostdataError(t *testing.T) {
 	}
 
 	db.EXPECT().GetActiveHostdata().Return(pippo, nil)
-	db.EXPECT().DeleteAllNoDataAlerts().Return(nil).Times(1)
 	db.EXPECT().FindOldCurrentHostdata(gomock.Any(), gomock.Any()).Return(true, aerrMock).Times(1)
+	db.EXPECT().DeleteNoDataAlertsExcept([]string{"pippohost"}).Return(nil).Times(1)
 
 	fcj.Run()
 }
@@ -268,8 +277,6 @@ func TestFreshnessCheckJobRun_ThrowNoDataAlertError(t *testing.T) {
 		NewObjectID:    utils.NewObjectIDForTests(),
 	}
 
-	db.EXPECT().DeleteAllNoDataAlerts().Return(nil).Times(1)
-
 	pippo := model.HostDataBE{
 		Hostname:  "pippohost",
 		CreatedAt: utils.P("2019-10-05T14:02:03Z"),
@@ -298,6 +305,8 @@ func TestFreshnessCheckJobRun_ThrowNoDataAlertError(t *testing.T) {
 	db.EXPECT().FindOldCurrentHostdata(pluto.Hostname, utils.P("2019-11-04T14:02:03Z")).
 		Return(true, nil)
 
+	db.EXPECT().DeleteNoDataAlertsExcept([]string{pippo.Hostname, pluto.Hostname}).Return(nil).Times(1)
+
 	alert1 := model.Alert{
 		ID:                      utils.Str2oid("000000000000000000000001"),
 		AlertAffectedTechnology: nil,
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"time"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/data-service/database"
+	"github.com/ercole-io/ercole/v2/logger"
+)
+
+// HostDataQueueCleaningJob is the job used to remove the hostdata submissions processed long ago
+type HostDataQueueCleaningJob struct {
+	// Database contains the database layer
+	Database database.MongoDatabaseInterface
+	// TimeNow contains a function that return the current time
+	TimeNow func() time.Time
+	// Config contains the dataservice global configuration
+	Config config.Configuration
+	// Log contains logger formatted
+	Log logger.Logger
+}
+
+// Run remove the submissions processed more than HourThreshold hours ago.
+// They are kept forever if the threshold isn't set
+func (job *HostDataQueueCleaningJob) Run() {
+	hours := job.Config.DataService.HostDataQueueCleaningJob.HourThreshold
+	if hours <= 0 {
+		return
+	}
+
+	deleted, err := job.Database.DeleteHostDataSubmissionsProcessedBefore(job.TimeNow().Add(time.Duration(-hours) * time.Hour))
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	job.Log.Infof("Removed %d hostdata submissions processed more than %d hours ago", deleted, hours)
+}
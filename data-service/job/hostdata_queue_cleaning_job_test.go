@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestHostDataQueueCleaningJobRun_Success(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	job := HostDataQueueCleaningJob{
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Database: db,
+		Config: config.Configuration{
+			DataService: config.DataService{
+				HostDataQueueCleaningJob: config.HostDataQueueCleaningJob{
+					HourThreshold: 10,
+				},
+			},
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().DeleteHostDataSubmissionsProcessedBefore(utils.P("2019-11-05T04:02:03Z")).Return(int64(3), nil).Times(1)
+
+	job.Run()
+}
+
+func TestHostDataQueueCleaningJobRun_KeepForever(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	job := HostDataQueueCleaningJob{
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Database: db,
+		Config:   config.Configuration{},
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().DeleteHostDataSubmissionsProcessedBefore(gomock.Any()).Times(0)
+
+	job.Run()
+}
+
+func TestHostDataQueueCleaningJobRun_DatabaseError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	job := HostDataQueueCleaningJob{
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Database: db,
+		Config: config.Configuration{
+			DataService: config.DataService{
+				HostDataQueueCleaningJob: config.HostDataQueueCleaningJob{
+					HourThreshold: 10,
+				},
+			},
+		},
+		Log: logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().DeleteHostDataSubmissionsProcessedBefore(utils.P("2019-11-05T04:02:03Z")).Return(int64(0), aerrMock).Times(1)
+
+	job.Run()
+}
@@ -23,6 +23,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	alert_service_client "github.com/ercole-io/ercole/v2/alert-service/client"
+	api_service_client "github.com/ercole-io/ercole/v2/api-service/client"
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/data-service/database"
 	"github.com/ercole-io/ercole/v2/logger"
@@ -61,6 +62,15 @@ func (j *Job) Init() {
 		jobrunner.Now(archivedHostCleaningJob)
 	}
 
+	hostDataQueueCleaningJob := &HostDataQueueCleaningJob{TimeNow: j.TimeNow, Database: j.Database, Config: j.Config, Log: j.Log}
+	if err := jobrunner.Schedule(j.Config.DataService.HostDataQueueCleaningJob.Crontab, hostDataQueueCleaningJob); err != nil {
+		j.Log.Errorf("Something went wrong scheduling HostDataQueueCleaningJob: %v", err)
+	}
+
+	if j.Config.DataService.HostDataQueueCleaningJob.RunAtStartup {
+		jobrunner.Now(hostDataQueueCleaningJob)
+	}
+
 	freshnessJob := &FreshnessCheckJob{
 		TimeNow:        j.TimeNow,
 		Database:       j.Database,
@@ -79,11 +89,49 @@ func (j *Job) Init() {
 		jobrunner.Now(freshnessJob)
 	}
 
+	licenseComplianceAlertJob := &LicenseComplianceAlertJob{
+		TimeNow:        j.TimeNow,
+		Database:       j.Database,
+		AlertSvcClient: alert_service_client.NewClient(j.Config.AlertService),
+		ApiSvcClient:   api_service_client.NewClient(j.Config.APIService),
+		Config:         j.Config,
+		Log:            j.Log,
+		NewObjectID: func() primitive.ObjectID {
+			return primitive.NewObjectIDFromTimestamp(j.TimeNow())
+		},
+	}
+	if err := jobrunner.Schedule(j.Config.DataService.LicenseComplianceAlertJob.Crontab, licenseComplianceAlertJob); err != nil {
+		j.Log.Errorf("Something went wrong scheduling LicenseComplianceAlertJob: %v", err)
+	}
+
+	if j.Config.DataService.LicenseComplianceAlertJob.RunAtStartup {
+		jobrunner.Now(licenseComplianceAlertJob)
+	}
+
+	clusterReconciliationAlertJob := &ClusterReconciliationAlertJob{
+		TimeNow:        j.TimeNow,
+		AlertSvcClient: alert_service_client.NewClient(j.Config.AlertService),
+		ApiSvcClient:   api_service_client.NewClient(j.Config.APIService),
+		Config:         j.Config,
+		Log:            j.Log,
+		NewObjectID: func() primitive.ObjectID {
+			return primitive.NewObjectIDFromTimestamp(j.TimeNow())
+		},
+	}
+	if err := jobrunner.Schedule(j.Config.DataService.ClusterReconciliationAlertJob.Crontab, clusterReconciliationAlertJob); err != nil {
+		j.Log.Errorf("Something went wrong scheduling ClusterReconciliationAlertJob: %v", err)
+	}
+
+	if j.Config.DataService.ClusterReconciliationAlertJob.RunAtStartup {
+		jobrunner.Now(clusterReconciliationAlertJob)
+	}
+
 	historicizeLicensesComplianceJob := &HistoricizeLicensesComplianceJob{
-		Database: j.Database,
-		TimeNow:  j.TimeNow,
-		Config:   j.Config,
-		Log:      j.Log,
+		Database:     j.Database,
+		ApiSvcClient: api_service_client.NewClient(j.Config.APIService),
+		TimeNow:      j.TimeNow,
+		Config:       j.Config,
+		Log:          j.Log,
 	}
 	jobrunner.Every(5*time.Minute, historicizeLicensesComplianceJob)
 }
@@ -0,0 +1,153 @@
+// Copyright (c) 2022 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	alert_service_client "github.com/ercole-io/ercole/v2/alert-service/client"
+	api_service_client "github.com/ercole-io/ercole/v2/api-service/client"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/data-service/database"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// LicenseComplianceAlertJob is the job used to check the compliance of the licenses in every location
+type LicenseComplianceAlertJob struct {
+	// TimeNow contains a function that return the current time
+	TimeNow func() time.Time
+	// Database contains the database layer
+	Database database.MongoDatabaseInterface
+	// AlertSvcClient
+	AlertSvcClient alert_service_client.AlertSvcClientInterface
+	// ApiSvcClient
+	ApiSvcClient api_service_client.ApiSvcClientInterface
+	// Config contains the dataservice global configuration
+	Config config.Configuration
+	// Log contains logger formatted
+	Log logger.Logger
+	// NewObjectID return a new ObjectID
+	NewObjectID func() primitive.ObjectID
+}
+
+// Run throws a LICENSE_NON_COMPLIANT alert for each license type that consumes more licenses
+// than the covered ones in a location. An alert still open, new or acked, is folded with the
+// last figures of the license. The open LICENSE_NON_COMPLIANT alerts whose deficit disappeared are resolved
+func (job *LicenseComplianceAlertJob) Run() {
+	locations, err := job.Database.GetCurrentLocations()
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	openAlerts, err := getOpenAlerts(job.ApiSvcClient, model.AlertCodeLicenseNonCompliant)
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	nonCompliant := make(map[string]bool)
+	failedLocations := make(map[string]bool)
+
+	for _, location := range locations {
+		licenses, err := job.ApiSvcClient.GetDatabaseLicensesCompliance(location)
+		if err != nil {
+			job.Log.Errorf("can't get the licenses compliance of location %s: %v", location, err)
+			failedLocations[location] = true
+
+			continue
+		}
+
+		for _, license := range licenses {
+			if license.Unlimited || license.Consumed <= license.Covered {
+				continue
+			}
+
+			nonCompliant[licenseComplianceKey(location, license.LicenseTypeID)] = true
+
+			if err := job.AlertSvcClient.ThrowNewAlert(job.licenseNonCompliantAlert(location, license)); err != nil {
+				job.Log.Error(err)
+			}
+		}
+	}
+
+	for _, alert := range openAlerts {
+		location := fmt.Sprint(alert.OtherInfo["location"])
+		key := licenseComplianceKey(alert.OtherInfo["location"], alert.OtherInfo["licenseTypeID"])
+
+		if nonCompliant[key] || failedLocations[location] {
+			continue
+		}
+
+		if err := job.ApiSvcClient.ResolveAlert(alert.ID, "The license is compliant"); err != nil {
+			job.Log.Error(err)
+		}
+	}
+}
+
+func (job *LicenseComplianceAlertJob) licenseNonCompliantAlert(location string, license dto.LicenseCompliance) model.Alert {
+	deficit := license.Consumed - license.Covered
+
+	return model.Alert{
+		ID:            job.NewObjectID(),
+		AlertCategory: model.AlertCategoryLicense,
+		AlertCode:     model.AlertCodeLicenseNonCompliant,
+		AlertSeverity: model.AlertSeverityCritical,
+		AlertStatus:   model.AlertStatusNew,
+		Date:          job.TimeNow(),
+		Description: fmt.Sprintf("The license %s (%s) isn't compliant in the location %s: %g consumed, %g covered, %g purchased",
+			license.LicenseTypeID, license.ItemDescription, location, license.Consumed, license.Covered, license.Purchased),
+		OtherInfo: map[string]interface{}{
+			"location":        location,
+			"licenseTypeID":   license.LicenseTypeID,
+			"itemDescription": license.ItemDescription,
+			"metric":          license.Metric,
+			"consumed":        license.Consumed,
+			"covered":         license.Covered,
+			"purchased":       license.Purchased,
+			"deficit":         deficit,
+		},
+	}
+}
+
+// getOpenAlerts return the new and the acked alerts with the code
+func getOpenAlerts(apiSvcClient api_service_client.ApiSvcClientInterface, alertCode string) ([]model.Alert, error) {
+	alerts := make([]model.Alert, 0)
+
+	for _, status := range []string{model.AlertStatusNew, model.AlertStatusAck} {
+		res, err := apiSvcClient.GetAlertsByFilter(dto.AlertsFilter{
+			AlertCode:   utils.Str2ptr(alertCode),
+			AlertStatus: utils.Str2ptr(status),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		alerts = append(alerts, res...)
+	}
+
+	return alerts, nil
+}
+
+func licenseComplianceKey(location, licenseTypeID interface{}) string {
+	return fmt.Sprintf("%v/%v", location, licenseTypeID)
+}
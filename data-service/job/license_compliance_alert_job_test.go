@@ -0,0 +1,130 @@
+// Copyright (c) 2022 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestLicenseComplianceAlertJobRun(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	asc := NewMockAlertSvcClientInterface(mockCtrl)
+	apiSvc := NewMockApiSvcClientInterface(mockCtrl)
+
+	job := LicenseComplianceAlertJob{
+		TimeNow:        utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Database:       db,
+		AlertSvcClient: asc,
+		ApiSvcClient:   apiSvc,
+		Log:            logger.NewLogger("TEST"),
+		NewObjectID:    utils.NewObjectIDForTests(),
+	}
+
+	newAlerts := []model.Alert{
+		{
+			ID:        utils.Str2oid("aaaaaaaaaaaaaaaaaaaaaaaa"),
+			AlertCode: model.AlertCodeLicenseNonCompliant,
+			OtherInfo: map[string]interface{}{"location": "Italy", "licenseTypeID": "A90611"},
+		},
+		{
+			ID:        utils.Str2oid("cccccccccccccccccccccccc"),
+			AlertCode: model.AlertCodeLicenseNonCompliant,
+			OtherInfo: map[string]interface{}{"location": "Germany", "licenseTypeID": "A90611"},
+		},
+	}
+	ackedAlerts := []model.Alert{
+		{
+			ID:        utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"),
+			AlertCode: model.AlertCodeLicenseNonCompliant,
+			OtherInfo: map[string]interface{}{"location": "Italy", "licenseTypeID": "A90649"},
+		},
+	}
+
+	db.EXPECT().GetCurrentLocations().Return([]string{"Italy", "Germany", "France"}, nil)
+	apiSvc.EXPECT().GetAlertsByFilter(dto.AlertsFilter{
+		AlertCode:   utils.Str2ptr(model.AlertCodeLicenseNonCompliant),
+		AlertStatus: utils.Str2ptr(model.AlertStatusNew),
+	}).Return(newAlerts, nil)
+	apiSvc.EXPECT().GetAlertsByFilter(dto.AlertsFilter{
+		AlertCode:   utils.Str2ptr(model.AlertCodeLicenseNonCompliant),
+		AlertStatus: utils.Str2ptr(model.AlertStatusAck),
+	}).Return(ackedAlerts, nil)
+
+	apiSvc.EXPECT().GetDatabaseLicensesCompliance("Italy").Return([]dto.LicenseCompliance{
+		{LicenseTypeID: "A90611", Consumed: 10, Covered: 8, Purchased: 8},
+		{LicenseTypeID: "A90649", Consumed: 2, Covered: 4, Purchased: 4},
+		{LicenseTypeID: "A90619", ItemDescription: "Partitioning", Metric: "Processor Perpetual", Consumed: 5, Covered: 3, Purchased: 4},
+		{LicenseTypeID: "L47247", Consumed: 50, Covered: 0, Unlimited: true},
+	}, nil)
+	apiSvc.EXPECT().GetDatabaseLicensesCompliance("Germany").Return(nil, aerrMock)
+	apiSvc.EXPECT().GetDatabaseLicensesCompliance("France").Return([]dto.LicenseCompliance{}, nil)
+
+	asc.EXPECT().ThrowNewAlert(gomock.Any()).Return(nil).Do(func(alert model.Alert) {
+		assert.Equal(t, "The license A90611 () isn't compliant in the location Italy: 10 consumed, 8 covered, 8 purchased", alert.Description)
+		assert.Equal(t, 2.0, alert.OtherInfo["deficit"])
+	}).Times(1)
+	asc.EXPECT().ThrowNewAlert(gomock.Any()).Return(nil).Do(func(alert model.Alert) {
+		assert.Equal(t, model.AlertCodeLicenseNonCompliant, alert.AlertCode)
+		assert.Equal(t, model.AlertCategoryLicense, alert.AlertCategory)
+		assert.Equal(t, model.AlertSeverityCritical, alert.AlertSeverity)
+		assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), alert.Date)
+		assert.Equal(t, "The license A90619 (Partitioning) isn't compliant in the location Italy: 5 consumed, 3 covered, 4 purchased", alert.Description)
+		assert.Equal(t, map[string]interface{}{
+			"location":        "Italy",
+			"licenseTypeID":   "A90619",
+			"itemDescription": "Partitioning",
+			"metric":          "Processor Perpetual",
+			"consumed":        5.0,
+			"covered":         3.0,
+			"purchased":       4.0,
+			"deficit":         2.0,
+		}, alert.OtherInfo)
+	}).Times(1)
+
+	apiSvc.EXPECT().ResolveAlert(utils.Str2oid("bbbbbbbbbbbbbbbbbbbbbbbb"), "The license is compliant").Return(nil).Times(1)
+
+	job.Run()
+}
+
+func TestLicenseComplianceAlertJobRun_GetAlertsError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	apiSvc := NewMockApiSvcClientInterface(mockCtrl)
+
+	job := LicenseComplianceAlertJob{
+		TimeNow:      utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Database:     db,
+		ApiSvcClient: apiSvc,
+		Log:          logger.NewLogger("TEST"),
+		NewObjectID:  utils.NewObjectIDForTests(),
+	}
+
+	db.EXPECT().GetCurrentLocations().Return([]string{"Italy"}, nil)
+	apiSvc.EXPECT().GetAlertsByFilter(gomock.Any()).Return(nil, aerrMock)
+
+	job.Run()
+}
@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"time"
 
+	api_service_client "github.com/ercole-io/ercole/v2/api-service/client"
 	"github.com/ercole-io/ercole/v2/api-service/dto"
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/data-service/database"
@@ -30,10 +31,11 @@ import (
 )
 
 type HistoricizeLicensesComplianceJob struct {
-	Database database.MongoDatabaseInterface
-	TimeNow  func() time.Time
-	Config   config.Configuration
-	Log      logger.Logger
+	Database     database.MongoDatabaseInterface
+	ApiSvcClient api_service_client.ApiSvcClientInterface
+	TimeNow      func() time.Time
+	Config       config.Configuration
+	Log          logger.Logger
 }
 
 func (job *HistoricizeLicensesComplianceJob) Run() {
@@ -70,9 +72,34 @@ func (job *HistoricizeLicensesComplianceJob) Run() {
 
 	licenses := response["licensesCompliance"]
 
-	err = job.Database.HistoricizeLicensesCompliance(licenses)
+	err = job.Database.HistoricizeLicensesCompliance(licenses, "")
 	if err != nil {
 		job.Log.Error("Can't historicize database licenses")
 		return
 	}
+
+	job.historicizeLocations()
+}
+
+// historicizeLocations save the licenses compliance of every location of the current hosts.
+// The compliance isn't historicized by environment: the contracts belong only to a location,
+// so the covered and purchased licenses of an environment can't be computed
+func (job *HistoricizeLicensesComplianceJob) historicizeLocations() {
+	locations, err := job.Database.GetCurrentLocations()
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	for _, location := range locations {
+		licenses, err := job.ApiSvcClient.GetDatabaseLicensesCompliance(location)
+		if err != nil {
+			job.Log.Errorf("Can't get licenses compliance of location %q: %s", location, err)
+			continue
+		}
+
+		if err := job.Database.HistoricizeLicensesCompliance(licenses, location); err != nil {
+			job.Log.Errorf("Can't historicize database licenses of location %q: %s", location, err)
+		}
+	}
 }
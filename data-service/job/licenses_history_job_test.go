@@ -0,0 +1,56 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestHistoricizeLicensesComplianceJob_HistoricizeLocations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	apiSvc := NewMockApiSvcClientInterface(mockCtrl)
+
+	job := HistoricizeLicensesComplianceJob{
+		TimeNow:      utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Database:     db,
+		ApiSvcClient: apiSvc,
+		Log:          logger.NewLogger("TEST"),
+	}
+
+	italy := []dto.LicenseCompliance{
+		{LicenseTypeID: "A90611", Consumed: 10, Covered: 8, Purchased: 8},
+	}
+	france := []dto.LicenseCompliance{
+		{LicenseTypeID: "L47247", Consumed: 2, Covered: 2, Purchased: 2},
+	}
+
+	db.EXPECT().GetCurrentLocations().Return([]string{"Italy", "Germany", "France"}, nil)
+	apiSvc.EXPECT().GetDatabaseLicensesCompliance("Italy").Return(italy, nil)
+	apiSvc.EXPECT().GetDatabaseLicensesCompliance("Germany").Return(nil, aerrMock)
+	apiSvc.EXPECT().GetDatabaseLicensesCompliance("France").Return(france, nil)
+	db.EXPECT().HistoricizeLicensesCompliance(italy, "Italy").Return(nil)
+	db.EXPECT().HistoricizeLicensesCompliance(france, "France").Return(nil)
+
+	job.historicizeLocations()
+}
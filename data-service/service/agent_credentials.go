@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+// agentCredentialLastUsedPrecision avoid to update the last use of a credential at every request
+const agentCredentialLastUsedPrecision = time.Minute
+
+// AuthenticateAgent return the credential enrolled for the host, if secret is its secret and it isn't revoked
+func (hds *HostDataService) AuthenticateAgent(hostname, secret string) (*model.AgentCredential, error) {
+	credential, err := hds.Database.GetAgentCredential(hostname)
+	if errors.Is(err, utils.ErrAgentCredentialNotFound) {
+		return nil, utils.ErrInvalidAgentCredential
+	} else if err != nil {
+		return nil, err
+	}
+
+	if credential.IsRevoked() || subtle.ConstantTimeCompare([]byte(credential.Hash), []byte(cr.HashToken(secret))) == 0 {
+		return nil, utils.ErrInvalidAgentCredential
+	}
+
+	now := hds.TimeNow()
+	if credential.LastUsedAt == nil || now.Sub(*credential.LastUsedAt) >= agentCredentialLastUsedPrecision {
+		if err := hds.Database.UpdateAgentCredentialLastUsed(credential.ID, now); err != nil {
+			hds.Log.Warnf("Can't update the last use of the credential of the agent %s: %s", hostname, err)
+		}
+
+		credential.LastUsedAt = &now
+	}
+
+	return credential, nil
+}
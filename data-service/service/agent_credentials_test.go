@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+func TestAuthenticateAgent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	hds := HostDataService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	secret := model.AgentSecretPrefix + "c2VjcmV0"
+	now := utils.P("2019-11-05T14:02:03Z")
+
+	t.Run("Success", func(t *testing.T) {
+		credential := &model.AgentCredential{
+			ID:       utils.Str2oid("654a1d2f3b8e7c0001a1d4e1"),
+			Hostname: "foobar",
+			Hash:     cr.HashToken(secret),
+		}
+
+		db.EXPECT().GetAgentCredential("foobar").Return(credential, nil)
+		db.EXPECT().UpdateAgentCredentialLastUsed(credential.ID, now).Return(nil)
+
+		actual, err := hds.AuthenticateAgent("foobar", secret)
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", actual.Hostname)
+		assert.Equal(t, now, *actual.LastUsedAt)
+	})
+
+	t.Run("Recently used", func(t *testing.T) {
+		lastUsedAt := now.Add(-10 * time.Second)
+		db.EXPECT().GetAgentCredential("foobar").Return(&model.AgentCredential{
+			Hostname:   "foobar",
+			Hash:       cr.HashToken(secret),
+			LastUsedAt: &lastUsedAt,
+		}, nil)
+
+		_, err := hds.AuthenticateAgent("foobar", secret)
+		require.NoError(t, err)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		db.EXPECT().GetAgentCredential("foobar").Return(&model.AgentCredential{Hostname: "foobar", Hash: cr.HashToken(secret)}, nil)
+
+		_, err := hds.AuthenticateAgent("foobar", model.AgentSecretPrefix+"d3Jvbmc")
+		assert.ErrorIs(t, err, utils.ErrInvalidAgentCredential)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		revokedAt := utils.P("2019-11-04T14:02:03Z")
+		db.EXPECT().GetAgentCredential("foobar").Return(&model.AgentCredential{
+			Hostname:  "foobar",
+			Hash:      cr.HashToken(secret),
+			RevokedAt: &revokedAt,
+		}, nil)
+
+		_, err := hds.AuthenticateAgent("foobar", secret)
+		assert.ErrorIs(t, err, utils.ErrInvalidAgentCredential)
+	})
+
+	t.Run("Not enrolled", func(t *testing.T) {
+		db.EXPECT().GetAgentCredential("foobar").Return(nil, utils.ErrAgentCredentialNotFound)
+
+		_, err := hds.AuthenticateAgent("foobar", secret)
+		assert.ErrorIs(t, err, utils.ErrInvalidAgentCredential)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		db.EXPECT().GetAgentCredential("foobar").Return(nil, aerrMock)
+
+		_, err := hds.AuthenticateAgent("foobar", secret)
+		assert.ErrorIs(t, err, aerrMock)
+	})
+}
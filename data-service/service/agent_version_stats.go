@@ -0,0 +1,46 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package service
+
+import (
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+// RecordAgentPayload update the statistics of the agent versions with an hostdata received,
+// that was rejected if rejection isn't nil
+func (hds *HostDataService) RecordAgentPayload(agentVersion string, schemaVersion int, upcasted bool, rejection error) error {
+	lastError := ""
+	if rejection != nil {
+		lastError = rejection.Error()
+	}
+
+	return hds.Database.RecordAgentPayload(agentVersion, schemaVersion, upcasted, lastError, hds.TimeNow())
+}
+
+// ListAgentVersionStats return the statistics of the hostdata received, by agent and schema version
+func (hds *HostDataService) ListAgentVersionStats() ([]model.AgentVersionStats, error) {
+	stats, err := hds.Database.ListAgentVersionStats()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stats {
+		if total := stats[i].Accepted + stats[i].Rejected; total > 0 {
+			stats[i].AcceptanceRate = float64(stats[i].Accepted) / float64(total)
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestRecordAgentPayload(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	hds := HostDataService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().RecordAgentPayload("1.9.0", 1, true, "", utils.P("2019-11-05T14:02:03Z")).Return(nil)
+	require.NoError(t, hds.RecordAgentPayload("1.9.0", 1, true, nil))
+
+	db.EXPECT().RecordAgentPayload("1.9.0", 3, false, "Unsupported", utils.P("2019-11-05T14:02:03Z")).Return(aerrMock)
+	assert.Equal(t, aerrMock, hds.RecordAgentPayload("1.9.0", 3, false, errors.New("Unsupported")))
+}
+
+func TestListAgentVersionStats(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	hds := HostDataService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().ListAgentVersionStats().Return([]model.AgentVersionStats{
+		{AgentVersion: "1.9.0", SchemaVersion: 1, Accepted: 3, Rejected: 1},
+		{AgentVersion: "2.0.0", SchemaVersion: 2},
+	}, nil)
+
+	actual, err := hds.ListAgentVersionStats()
+	require.NoError(t, err)
+
+	expected := []model.AgentVersionStats{
+		{AgentVersion: "1.9.0", SchemaVersion: 1, Accepted: 3, Rejected: 1, AcceptanceRate: 0.75},
+		{AgentVersion: "2.0.0", SchemaVersion: 2},
+	}
+	assert.Equal(t, expected, actual)
+
+	db.EXPECT().ListAgentVersionStats().Return(nil, aerrMock)
+
+	_, err = hds.ListAgentVersionStats()
+	assert.Equal(t, aerrMock, err)
+}
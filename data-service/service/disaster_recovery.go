@@ -30,7 +30,7 @@ func (hds *HostDataService) createDR(hostdata model.HostDataBE) error {
 		return nil
 	}
 
-	if err := hds.Database.DismissHost(drname); err != nil {
+	if err := hds.Database.DismissHost(drname, hostdata.CreatedAt); err != nil {
 		return err
 	}
 
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -35,13 +36,14 @@ func TestCreateDR(t *testing.T) {
 	drname := "test_DR"
 
 	db.EXPECT().ExistsDR(drname).Return(true).Times(1)
-	db.EXPECT().DismissHost(drname).Return(nil).Times(1)
+	db.EXPECT().DismissHost(drname, utils.P("2019-11-05T14:02:03Z")).Return(nil).Times(1)
 	db.EXPECT().InsertHostData(gomock.Any()).Return(nil).Times(1)
 
 	err := hds.createDR(model.HostDataBE{
-		Hostname: "test",
-		Archived: false,
-		IsDR:     true,
+		Hostname:  "test",
+		CreatedAt: utils.P("2019-11-05T14:02:03Z"),
+		Archived:  false,
+		IsDR:      true,
 	})
 
 	require.NoError(t, err)
@@ -0,0 +1,183 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+// EnqueueHostData save the hostdata in the queue and return the id of its submission
+func (hds *HostDataService) EnqueueHostData(hostdata model.HostDataBE) (primitive.ObjectID, error) {
+	now := hds.TimeNow()
+
+	submission := model.HostDataSubmission{
+		ID:            primitive.NewObjectIDFromTimestamp(now),
+		Hostname:      hostdata.Hostname,
+		Status:        model.HostDataSubmissionStatusQueued,
+		SubmittedAt:   now,
+		NextAttemptAt: now,
+		HostData:      &hostdata,
+	}
+
+	if err := hds.Database.EnqueueHostData(submission); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return submission.ID, nil
+}
+
+// GetHostDataSubmission return the submission with id, without its hostdata
+func (hds *HostDataService) GetHostDataSubmission(id primitive.ObjectID) (*model.HostDataSubmission, error) {
+	return hds.Database.FindHostDataSubmission(id)
+}
+
+// ProcessHostDataSubmission insert the hostdata of the submission. If it fails, the submission
+// is queued again or, when the attempts are exhausted, moved to the dead letters
+func (hds *HostDataService) ProcessHostDataSubmission(submission model.HostDataSubmission) error {
+	if submission.HostData == nil {
+		submission.LastError = "The submission hasn't any hostdata"
+		return hds.Database.DeadLetterHostDataSubmission(submission)
+	}
+
+	err := hds.insertHostDataSubmission(submission)
+	if err == nil {
+		return hds.Database.CompleteHostDataSubmission(submission.ID, hds.TimeNow())
+	}
+
+	hds.Log.Warnf("Can't insert hostdata of %s, submission %s, attempt %d: %v",
+		submission.Hostname, submission.ID.Hex(), submission.Attempts, err)
+
+	conf := hds.Config.DataService.HostDataQueue
+	if submission.Attempts >= conf.MaxAttempts {
+		submission.LastError = err.Error()
+		return hds.Database.DeadLetterHostDataSubmission(submission)
+	}
+
+	backoff := time.Duration(conf.RetryBackoff) * time.Second
+	for i := 1; i < submission.Attempts; i++ {
+		backoff *= 2
+	}
+
+	return hds.Database.RetryHostDataSubmission(submission.ID, err.Error(), hds.TimeNow().Add(backoff))
+}
+
+// insertHostDataSubmission insert the hostdata of the submission as received when it was submitted.
+// Once checked, the hostdata is recorded in the submission so a retry doesn't repeat the checks and their alerts
+func (hds *HostDataService) insertHostDataSubmission(submission model.HostDataSubmission) error {
+	hostdata := *submission.HostData
+
+	if submission.HostDataID == nil {
+		hds.prepareHostData(&hostdata, submission.ID, submission.SubmittedAt)
+
+		if err := hds.checkHostData(&hostdata); err != nil {
+			return err
+		}
+
+		if err := hds.Database.SetHostDataSubmissionChecked(submission.ID, hostdata); err != nil {
+			return err
+		}
+	}
+
+	return hds.saveHostData(hostdata)
+}
+
+// StartHostDataQueueWorkers start the workers that process the queued hostdata.
+// The submissions of the same host are never processed concurrently and a submission waiting
+// to be retried holds the newer ones of its host, so they are inserted in order
+func (hds *HostDataService) StartHostDataQueueWorkers() {
+	if err := hds.Database.ReleaseHostDataSubmissions(); err != nil {
+		hds.Log.Error(err)
+	}
+
+	conf := hds.Config.DataService.HostDataQueue
+	pollInterval := time.Duration(conf.PollInterval) * time.Millisecond
+	inFlight := &inFlightHostnames{hostnames: make(map[string]bool)}
+
+	concurrency := conf.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for {
+				if !hds.processNextHostDataSubmission(inFlight) {
+					time.Sleep(pollInterval)
+				}
+			}
+		}()
+	}
+}
+
+// processNextHostDataSubmission claim and process a submission, returning false if there wasn't any
+func (hds *HostDataService) processNextHostDataSubmission(inFlight *inFlightHostnames) bool {
+	submission, err := inFlight.claim(func(excludedHostnames []string) (*model.HostDataSubmission, error) {
+		return hds.Database.ClaimHostDataSubmission(hds.TimeNow(), excludedHostnames)
+	})
+	if err != nil {
+		hds.Log.Error(err)
+		return false
+	}
+
+	if submission == nil {
+		return false
+	}
+
+	defer inFlight.release(submission.Hostname)
+
+	if err := hds.ProcessHostDataSubmission(*submission); err != nil {
+		hds.Log.Error(err)
+	}
+
+	return true
+}
+
+// inFlightHostnames holds the hostnames whose submission is being processed by a worker
+type inFlightHostnames struct {
+	mutex     sync.Mutex
+	hostnames map[string]bool
+}
+
+func (f *inFlightHostnames) claim(claimFunc func(excludedHostnames []string) (*model.HostDataSubmission, error)) (*model.HostDataSubmission, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	excluded := make([]string, 0, len(f.hostnames))
+	for hostname := range f.hostnames {
+		excluded = append(excluded, hostname)
+	}
+
+	submission, err := claimFunc(excluded)
+	if err != nil || submission == nil {
+		return nil, err
+	}
+
+	f.hostnames[submission.Hostname] = true
+
+	return submission, nil
+}
+
+func (f *inFlightHostnames) release(hostname string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.hostnames, hostname)
+}
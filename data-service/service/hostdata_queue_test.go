@@ -0,0 +1,237 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestEnqueueHostData(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	hds := HostDataService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	hostdata := model.HostDataBE{Hostname: "foobar"}
+
+	db.EXPECT().EnqueueHostData(gomock.Any()).Do(func(submission model.HostDataSubmission) {
+		assert.Equal(t, "foobar", submission.Hostname)
+		assert.Equal(t, model.HostDataSubmissionStatusQueued, submission.Status)
+		assert.Equal(t, 0, submission.Attempts)
+		assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), submission.SubmittedAt)
+		assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), submission.NextAttemptAt)
+		assert.Equal(t, &hostdata, submission.HostData)
+		assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), submission.ID.Timestamp().UTC())
+	}).Return(nil)
+
+	id, err := hds.EnqueueHostData(hostdata)
+	require.NoError(t, err)
+	assert.False(t, id.IsZero())
+}
+
+func TestEnqueueHostData_Fail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	hds := HostDataService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	db.EXPECT().EnqueueHostData(gomock.Any()).Return(aerrMock)
+
+	id, err := hds.EnqueueHostData(model.HostDataBE{Hostname: "foobar"})
+	require.Equal(t, aerrMock, err)
+	assert.True(t, id.IsZero())
+}
+
+func TestProcessHostDataSubmission(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	hds := HostDataService{
+		Config: config.Configuration{
+			DataService: config.DataService{
+				HostDataQueue: config.HostDataQueue{
+					MaxAttempts:  3,
+					RetryBackoff: 30,
+				},
+			},
+		},
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	submission := model.HostDataSubmission{
+		ID:          utils.Str2oid("5dc3f534db7e81a98b726a52"),
+		Hostname:    "foobar",
+		Status:      model.HostDataSubmissionStatusProcessing,
+		SubmittedAt: utils.P("2019-11-05T14:00:00Z"),
+		HostData:    &model.HostDataBE{Hostname: "foobar"},
+	}
+
+	t.Run("Inserted", func(t *testing.T) {
+		submission := submission
+		submission.Attempts = 1
+
+		previous := model.HostDataBE{Hostname: "foobar"}
+
+		gomock.InOrder(
+			db.EXPECT().FindMostRecentHostDataOlderThan("foobar", utils.P("2019-11-05T14:00:00Z")).Return(&previous, nil),
+			db.EXPECT().SetHostDataSubmissionChecked(submission.ID, gomock.Any()).Do(func(_ primitive.ObjectID, hostdata model.HostDataBE) {
+				assert.Equal(t, submission.ID, hostdata.ID)
+				assert.Equal(t, utils.P("2019-11-05T14:00:00Z"), hostdata.CreatedAt)
+			}).Return(nil),
+			db.EXPECT().InsertHostData(gomock.Any()).Do(func(hostdata model.HostDataBE) {
+				assert.Equal(t, submission.ID, hostdata.ID)
+				assert.Equal(t, utils.P("2019-11-05T14:00:00Z"), hostdata.CreatedAt)
+			}).Return(nil),
+			db.EXPECT().DismissHostDataOlderThan("foobar", utils.P("2019-11-05T14:00:00Z")).Return(nil),
+			db.EXPECT().DeleteNoDataAlertByHost("foobar").Return(nil),
+			db.EXPECT().ExistsDR("foobar_DR").Return(false),
+			db.EXPECT().CompleteHostDataSubmission(submission.ID, utils.P("2019-11-05T14:02:03Z")).Return(nil),
+		)
+
+		require.NoError(t, hds.ProcessHostDataSubmission(submission))
+	})
+
+	t.Run("Retried after the checks", func(t *testing.T) {
+		hostdata := model.HostDataBE{
+			ID:        submission.ID,
+			Hostname:  "foobar",
+			CreatedAt: utils.P("2019-11-05T14:00:00Z"),
+		}
+
+		submission := submission
+		submission.Attempts = 2
+		submission.HostDataID = &hostdata.ID
+		submission.HostData = &hostdata
+
+		gomock.InOrder(
+			db.EXPECT().InsertHostData(hostdata).Return(mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}),
+			db.EXPECT().DismissHostDataOlderThan("foobar", utils.P("2019-11-05T14:00:00Z")).Return(nil),
+			db.EXPECT().DeleteNoDataAlertByHost("foobar").Return(nil),
+			db.EXPECT().ExistsDR("foobar_DR").Return(false),
+			db.EXPECT().CompleteHostDataSubmission(submission.ID, utils.P("2019-11-05T14:02:03Z")).Return(nil),
+		)
+
+		require.NoError(t, hds.ProcessHostDataSubmission(submission))
+	})
+
+	t.Run("Retried with backoff", func(t *testing.T) {
+		submission := submission
+		submission.Attempts = 2
+
+		gomock.InOrder(
+			db.EXPECT().FindMostRecentHostDataOlderThan("foobar", utils.P("2019-11-05T14:00:00Z")).Return(nil, errMock),
+			db.EXPECT().RetryHostDataSubmission(submission.ID, "MockError", utils.P("2019-11-05T14:03:03Z")).Return(nil),
+		)
+
+		require.NoError(t, hds.ProcessHostDataSubmission(submission))
+	})
+
+	t.Run("Moved to dead letters", func(t *testing.T) {
+		submission := submission
+		submission.Attempts = 3
+
+		expected := submission
+		expected.LastError = "MockError"
+
+		gomock.InOrder(
+			db.EXPECT().FindMostRecentHostDataOlderThan("foobar", utils.P("2019-11-05T14:00:00Z")).Return(nil, errMock),
+			db.EXPECT().DeadLetterHostDataSubmission(expected).Return(nil),
+		)
+
+		require.NoError(t, hds.ProcessHostDataSubmission(submission))
+	})
+
+	t.Run("Without hostdata", func(t *testing.T) {
+		submission := submission
+		submission.Attempts = 1
+		submission.HostData = nil
+
+		expected := submission
+		expected.LastError = "The submission hasn't any hostdata"
+
+		db.EXPECT().DeadLetterHostDataSubmission(expected).Return(nil)
+
+		require.NoError(t, hds.ProcessHostDataSubmission(submission))
+	})
+}
+
+func TestProcessNextHostDataSubmission(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	hds := HostDataService{
+		Database: db,
+		TimeNow:  utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	t.Run("Empty queue", func(t *testing.T) {
+		inFlight := &inFlightHostnames{hostnames: map[string]bool{"foobar": true}}
+
+		db.EXPECT().ClaimHostDataSubmission(utils.P("2019-11-05T14:02:03Z"), []string{"foobar"}).Return(nil, nil)
+
+		assert.False(t, hds.processNextHostDataSubmission(inFlight))
+	})
+
+	t.Run("Claim error", func(t *testing.T) {
+		inFlight := &inFlightHostnames{hostnames: map[string]bool{}}
+
+		db.EXPECT().ClaimHostDataSubmission(utils.P("2019-11-05T14:02:03Z"), []string{}).Return(nil, aerrMock)
+
+		assert.False(t, hds.processNextHostDataSubmission(inFlight))
+	})
+
+	t.Run("Processed", func(t *testing.T) {
+		inFlight := &inFlightHostnames{hostnames: map[string]bool{}}
+
+		submission := model.HostDataSubmission{
+			ID:       utils.Str2oid("5dc3f534db7e81a98b726a52"),
+			Hostname: "foobar",
+			Attempts: 1,
+		}
+
+		gomock.InOrder(
+			db.EXPECT().ClaimHostDataSubmission(utils.P("2019-11-05T14:02:03Z"), []string{}).Return(&submission, nil),
+			db.EXPECT().DeadLetterHostDataSubmission(gomock.Any()).Do(func(model.HostDataSubmission) {
+				assert.True(t, inFlight.hostnames["foobar"])
+			}).Return(nil),
+		)
+
+		assert.True(t, hds.processNextHostDataSubmission(inFlight))
+		assert.Empty(t, inFlight.hostnames)
+	})
+}
@@ -16,7 +16,10 @@
 package service
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
@@ -24,14 +27,27 @@ import (
 
 // InsertHostData saves the hostdata
 func (hds *HostDataService) InsertHostData(hostdata model.HostDataBE) error {
-	var err error
+	now := hds.TimeNow()
+	hds.prepareHostData(&hostdata, primitive.NewObjectIDFromTimestamp(now), now)
 
+	if err := hds.checkHostData(&hostdata); err != nil {
+		return err
+	}
+
+	return hds.saveHostData(hostdata)
+}
+
+// prepareHostData set the fields of the hostdata added by the server
+func (hds *HostDataService) prepareHostData(hostdata *model.HostDataBE, id primitive.ObjectID, createdAt time.Time) {
 	hostdata.ServerVersion = hds.ServerVersion
 	hostdata.Archived = false
-	hostdata.CreatedAt = hds.TimeNow()
+	hostdata.CreatedAt = createdAt
 	hostdata.ServerSchemaVersion = model.SchemaVersion
-	hostdata.ID = primitive.NewObjectIDFromTimestamp(hds.TimeNow())
+	hostdata.ID = id
+}
 
+// checkHostData compare the hostdata with the previous one of the host, throwing the alerts of what has changed
+func (hds *HostDataService) checkHostData(hostdata *model.HostDataBE) error {
 	previousHostdata, err := hds.Database.FindMostRecentHostDataOlderThan(hostdata.Hostname, hostdata.CreatedAt)
 	if err != nil {
 		hds.Log.Error(err)
@@ -51,15 +67,15 @@ func (hds *HostDataService) InsertHostData(hostdata model.HostDataBE) error {
 	}
 
 	if hostdata.Features.Oracle != nil {
-		hds.oracleDatabasesChecks(previousHostdata, &hostdata)
+		hds.oracleDatabasesChecks(previousHostdata, hostdata)
 	}
 
 	if hostdata.Features.Microsoft != nil {
-		hds.sqlServerDatabasesChecks(previousHostdata, &hostdata)
+		hds.sqlServerDatabasesChecks(previousHostdata, hostdata)
 	}
 
 	if hostdata.Features.MySQL != nil {
-		hds.mySqlDatabasesChecks(previousHostdata, &hostdata)
+		hds.mySqlDatabasesChecks(previousHostdata, hostdata)
 	}
 
 	if hostdata.Clusters != nil {
@@ -70,17 +86,27 @@ func (hds *HostDataService) InsertHostData(hostdata model.HostDataBE) error {
 		hostdata.ClusterMembershipStatus.VeritasClusterHostnames = hds.getVeritasHostsFqdn(hostdata.Hostname, hostdata.ClusterMembershipStatus.VeritasClusterHostnames)
 	}
 
-	err = hds.Database.DismissHost(hostdata.Hostname)
-	if err != nil {
-		return err
+	if len(hostdata.Errors) > 0 {
+		if err := hds.throwAgentErrorsNonBlockingAlert(hostdata.Hostname, hostdata.Errors); err != nil {
+			hds.Log.Error(err)
+		}
 	}
 
+	return nil
+}
+
+// saveHostData insert the checked hostdata as the current one of the host. It can be repeated:
+// the hostdata is inserted before dismissing the previous ones, and it isn't inserted twice
+func (hds *HostDataService) saveHostData(hostdata model.HostDataBE) error {
 	if hds.Config.DataService.LogInsertingHostdata {
 		hds.Log.Info(utils.ToJSON(hostdata))
 	}
 
-	err = hds.Database.InsertHostData(hostdata)
-	if err != nil {
+	if err := hds.Database.InsertHostData(hostdata); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	if err := hds.Database.DismissHostDataOlderThan(hostdata.Hostname, hostdata.CreatedAt); err != nil {
 		return err
 	}
 
@@ -88,18 +114,38 @@ func (hds *HostDataService) InsertHostData(hostdata model.HostDataBE) error {
 		hds.Log.Error(err)
 	}
 
-	if len(hostdata.Errors) > 0 {
-		if err := hds.throwAgentErrorsNonBlockingAlert(hostdata.Hostname, hostdata.Errors); err != nil {
-			hds.Log.Error(err)
-		}
-	}
+	return hds.createDR(hostdata)
+}
+
+// ReplayHostData saves the hostdata as it was received at capturedAt.
+// If the host already has a more recent hostdata, it's saved as archived leaving the current one untouched
+func (hds *HostDataService) ReplayHostData(hostdata model.HostDataBE, capturedAt time.Time) error {
+	capturedAt = capturedAt.UTC().Truncate(time.Millisecond)
 
-	err = hds.createDR(hostdata)
+	next, err := hds.Database.FindLeastRecentHostDataNotOlderThan(hostdata.Hostname, capturedAt)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if next != nil && next.CreatedAt.Equal(capturedAt) {
+		return utils.NewErrorf("%w: %s at %s", utils.ErrHostDataAlreadyExists, hostdata.Hostname, capturedAt.Format(time.RFC3339))
+	}
+
+	if next == nil {
+		replay := *hds
+		replay.TimeNow = func() time.Time { return capturedAt }
+
+		return replay.InsertHostData(hostdata)
+	}
+
+	hostdata.ServerVersion = hds.ServerVersion
+	hostdata.Archived = true
+	hostdata.CreatedAt = capturedAt
+	hostdata.DismissedAt = next.CreatedAt
+	hostdata.ServerSchemaVersion = model.SchemaVersion
+	hostdata.ID = primitive.NewObjectIDFromTimestamp(capturedAt)
+
+	return hds.Database.InsertHostData(hostdata)
 }
 
 func (hds *HostDataService) AlertInvalidHostData(validationErr error, hostdata *model.HostDataBE) {
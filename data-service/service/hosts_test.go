@@ -76,7 +76,6 @@ func TestInsertHostData_Success(t *testing.T) {
 			asc.EXPECT().ThrowNewAlert(gomock.Any()).Do(func(a model.Alert) {
 				assert.Equal(t, "The host rac1_x was added to ercole", a.Description)
 			}).Return(nil),
-			db.EXPECT().DismissHost("rac1_x").Return(nil),
 			db.EXPECT().InsertHostData(gomock.Any()).
 				Do(func(newHD model.HostDataBE) {
 					assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), newHD.ID.Timestamp())
@@ -89,6 +88,7 @@ func TestInsertHostData_Success(t *testing.T) {
 					//I assume that other fields are correct
 				}).
 				Return(nil),
+			db.EXPECT().DismissHostDataOlderThan("rac1_x", utils.P("2019-11-05T14:02:03Z")).Return(nil),
 			db.EXPECT().DeleteNoDataAlertByHost(hd.Hostname).Return(nil),
 			db.EXPECT().ExistsDR("rac1_x_DR").Return(false),
 		)
@@ -106,7 +106,6 @@ func TestInsertHostData_Success(t *testing.T) {
 			asc.EXPECT().ThrowNewAlert(gomock.Any()).Do(func(a model.Alert) {
 				assert.Equal(t, "The host rac1_x was added to ercole", a.Description)
 			}).Return(nil),
-			db.EXPECT().DismissHost("rac1_x").Return(nil),
 			db.EXPECT().InsertHostData(gomock.Any()).
 				Do(func(newHD model.HostDataBE) {
 					assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), newHD.ID.Timestamp())
@@ -119,6 +118,7 @@ func TestInsertHostData_Success(t *testing.T) {
 					//I assume that other fields are correct
 				}).
 				Return(nil),
+			db.EXPECT().DismissHostDataOlderThan("rac1_x", utils.P("2019-11-05T14:02:03Z")).Return(nil),
 			db.EXPECT().DeleteNoDataAlertByHost(hd.Hostname).Return(nil),
 			db.EXPECT().ExistsDR("rac1_x_DR").Return(false),
 		)
@@ -132,7 +132,6 @@ func TestInsertHostData_Success(t *testing.T) {
 		gomock.InOrder(
 			db.EXPECT().FindMostRecentHostDataOlderThan(hd.Hostname, utils.P("2019-11-05T14:02:03Z")).
 				Return(previousHostdata, nil),
-			db.EXPECT().DismissHost("rac1_x").Return(nil),
 			db.EXPECT().InsertHostData(gomock.Any()).
 				Do(func(newHD model.HostDataBE) {
 					assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), newHD.ID.Timestamp())
@@ -145,6 +144,7 @@ func TestInsertHostData_Success(t *testing.T) {
 					//I assume that other fields are correct
 				}).
 				Return(nil),
+			db.EXPECT().DismissHostDataOlderThan("rac1_x", utils.P("2019-11-05T14:02:03Z")).Return(nil),
 			db.EXPECT().DeleteNoDataAlertByHost(hd.Hostname).Return(nil),
 			db.EXPECT().ExistsDR("rac1_x_DR").Return(false),
 		)
@@ -192,7 +192,8 @@ func TestInsertHostData_DatabaseError1(t *testing.T) {
 		asc.EXPECT().ThrowNewAlert(gomock.Any()).Do(func(a model.Alert) {
 			assert.Equal(t, "The host rac1_x was added to ercole", a.Description)
 		}).Return(nil),
-		db.EXPECT().DismissHost("rac1_x").Return(aerrMock),
+		db.EXPECT().InsertHostData(gomock.Any()).Return(nil),
+		db.EXPECT().DismissHostDataOlderThan("rac1_x", utils.P("2019-11-05T14:02:03Z")).Return(aerrMock),
 	)
 
 	err := hds.InsertHostData(hd)
@@ -237,7 +238,6 @@ func TestInsertHostData_DatabaseError2(t *testing.T) {
 		asc.EXPECT().ThrowNewAlert(gomock.Any()).Do(func(a model.Alert) {
 			assert.Equal(t, "The host rac1_x was added to ercole", a.Description)
 		}).Return(nil),
-		db.EXPECT().DismissHost("rac1_x").Return(nil),
 		db.EXPECT().InsertHostData(gomock.Any()).Return(aerrMock).Do(func(newHD model.HostDataBE) {
 			assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), newHD.ID.Timestamp())
 			assert.False(t, newHD.Archived)
@@ -292,7 +292,6 @@ func TestInsertHostData_HttpError(t *testing.T) {
 		asc.EXPECT().ThrowNewAlert(gomock.Any()).Do(func(a model.Alert) {
 			assert.Equal(t, "The host rac1_x was added to ercole", a.Description)
 		}).Return(nil),
-		db.EXPECT().DismissHost("rac1_x").Return(nil),
 		db.EXPECT().InsertHostData(gomock.Any()).Return(aerrMock).Do(func(newHD model.HostDataBE) {
 			assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), newHD.ID.Timestamp())
 			assert.False(t, newHD.Archived)
@@ -309,3 +308,69 @@ func TestInsertHostData_HttpError(t *testing.T) {
 	fmt.Println(err.Error())
 	require.Contains(t, err.Error(), "MockError")
 }
+
+func TestReplayHostData(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	asc := NewMockAlertSvcClientInterface(mockCtrl)
+	hds := HostDataService{
+		Config:         config.Configuration{},
+		ServerVersion:  "1.6.6",
+		Database:       db,
+		AlertSvcClient: asc,
+		TimeNow:        utils.Btc(utils.P("2019-11-05T14:02:03Z")),
+		Log:            logger.NewLogger("TEST"),
+	}
+	hd := mongoutils.LoadFixtureHostData(t, "../../fixture/test_dataservice_hostdata_v1_00.json")
+	capturedAt := utils.P("2019-10-01T08:00:00Z")
+
+	t.Run("Most recent hostdata", func(t *testing.T) {
+		gomock.InOrder(
+			db.EXPECT().FindLeastRecentHostDataNotOlderThan(hd.Hostname, capturedAt).Return(nil, nil),
+			db.EXPECT().FindMostRecentHostDataOlderThan(hd.Hostname, capturedAt).Return(nil, nil),
+			asc.EXPECT().ThrowNewAlert(gomock.Any()).Do(func(a model.Alert) {
+				assert.Equal(t, capturedAt, a.Date)
+			}).Return(nil),
+			db.EXPECT().InsertHostData(gomock.Any()).
+				Do(func(newHD model.HostDataBE) {
+					assert.Equal(t, capturedAt, newHD.ID.Timestamp().UTC())
+					assert.False(t, newHD.Archived)
+					assert.Equal(t, capturedAt, newHD.CreatedAt)
+				}).
+				Return(nil),
+			db.EXPECT().DismissHostDataOlderThan("rac1_x", capturedAt).Return(nil),
+			db.EXPECT().DeleteNoDataAlertByHost(hd.Hostname).Return(nil),
+			db.EXPECT().ExistsDR("rac1_x_DR").Return(false),
+		)
+
+		require.NoError(t, hds.ReplayHostData(hd, capturedAt))
+		assert.Equal(t, utils.P("2019-11-05T14:02:03Z"), hds.TimeNow())
+	})
+
+	t.Run("Older than the current hostdata", func(t *testing.T) {
+		next := &model.HostDataBE{Hostname: hd.Hostname, CreatedAt: utils.P("2019-10-02T08:00:00Z")}
+
+		gomock.InOrder(
+			db.EXPECT().FindLeastRecentHostDataNotOlderThan(hd.Hostname, capturedAt).Return(next, nil),
+			db.EXPECT().InsertHostData(gomock.Any()).
+				Do(func(newHD model.HostDataBE) {
+					assert.True(t, newHD.Archived)
+					assert.Equal(t, capturedAt, newHD.CreatedAt)
+					assert.Equal(t, next.CreatedAt, newHD.DismissedAt)
+					assert.Equal(t, "1.6.6", newHD.ServerVersion)
+				}).
+				Return(nil),
+		)
+
+		require.NoError(t, hds.ReplayHostData(hd, capturedAt))
+	})
+
+	t.Run("Already replayed", func(t *testing.T) {
+		existing := &model.HostDataBE{Hostname: hd.Hostname, CreatedAt: capturedAt}
+		db.EXPECT().FindLeastRecentHostDataNotOlderThan(hd.Hostname, capturedAt).Return(existing, nil)
+
+		err := hds.ReplayHostData(hd, capturedAt)
+		assert.ErrorIs(t, err, utils.ErrHostDataAlreadyExists)
+	})
+}
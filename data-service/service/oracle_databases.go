@@ -84,12 +84,14 @@ func (hds *HostDataService) checkSecondaryDbs(hostdata *model.HostDataBE) {
 
 		if utils.Contains(model.OracleDatabaseStatusMounted, db.Status) &&
 			db.Role != model.OracleDatabaseRolePrimary {
-			hds.addLicensesToSecondaryDb(hostdata.Info, hostdata.CoreFactor(), db)
+			hds.addLicensesToSecondaryDb(hostdata, db)
 		}
 	}
 }
 
-func (hds *HostDataService) addLicensesToSecondaryDb(hostInfo model.Host, hostCoreFactor float64, secondaryDb *model.OracleDatabase) {
+func (hds *HostDataService) addLicensesToSecondaryDb(hostdata *model.HostDataBE, secondaryDb *model.OracleDatabase) {
+	hostInfo := hostdata.Info
+
 	dbs, err := hds.getPrimaryOpenOracleDatabases()
 	if err != nil {
 		hds.Log.Errorf("Can't get primary open oracle databases: %s", err)
@@ -118,10 +120,15 @@ func (hds *HostDataService) addLicensesToSecondaryDb(hostInfo model.Host, hostCo
 		return
 	}
 
-	coreFactor, err := secondaryDb.CoreFactor(hostInfo, hostCoreFactor)
-	if err != nil {
-		hds.Log.Error(err.Error())
-		return
+	count, ok := hostdata.CloudLicenses(secondaryDb.Edition())
+	if !ok {
+		coreFactor, err := secondaryDb.CoreFactor(hostInfo, hostdata.CoreFactor())
+		if err != nil {
+			hds.Log.Error(err.Error())
+			return
+		}
+
+		count = float64(hostInfo.CPUCores) * coreFactor
 	}
 
 primaryDbLicensesCycle:
@@ -132,7 +139,7 @@ primaryDbLicensesCycle:
 				secondaryDbLicense := &secondaryDb.Licenses[i]
 
 				if secondaryDbLicense.Name == primaryDbLicense.Name {
-					secondaryDbLicense.Count = float64(hostInfo.CPUCores) * coreFactor
+					secondaryDbLicense.Count = count
 					continue primaryDbLicensesCycle
 				}
 			}
@@ -150,7 +157,7 @@ primaryDbLicensesCycle:
 				model.OracleDatabaseLicense{
 					LicenseTypeID: primaryDbLicense.LicenseTypeID,
 					Name:          primaryDbLicense.Name,
-					Count:         float64(hostInfo.CPUCores) * coreFactor,
+					Count:         count,
 					Ignored:       primaryDbLicense.Ignored,
 				})
 		}
@@ -123,7 +123,7 @@ func TestAddLicensesToSecondaryDbs(t *testing.T) {
 			},
 		}}).Return(nil)
 
-	hds.addLicensesToSecondaryDb(hdPrimary.Info, 2, &primaryDB)
+	hds.addLicensesToSecondaryDb(&hdPrimary, &primaryDB)
 }
 
 var hostData1 model.HostDataBE = model.HostDataBE{
@@ -19,6 +19,8 @@ package service
 import (
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
 	"github.com/ercole-io/ercole/v2/config"
 	"github.com/ercole-io/ercole/v2/logger"
 	"github.com/ercole-io/ercole/v2/model"
@@ -31,11 +33,27 @@ import (
 
 type HostDataServiceInterface interface {
 	InsertHostData(hostdata model.HostDataBE) error
+	// ReplayHostData saves the hostdata as it was received at capturedAt
+	ReplayHostData(hostdata model.HostDataBE, capturedAt time.Time) error
 	AlertInvalidHostData(validationErr error, hostdata *model.HostDataBE)
 	CompareCmdbInfo(cmdbInfo dto.CmdbInfo) error
 	InsertOracleLicenseTypes(licenseTypes []model.OracleDatabaseLicenseType) error
 	SanitizeLicenseTypes(raw []byte) ([]model.OracleDatabaseLicenseType, error)
 	SaveExadata(exadata *model.OracleExadataInstance) error
+
+	// EnqueueHostData save the hostdata in the queue and return the id of its submission
+	EnqueueHostData(hostdata model.HostDataBE) (primitive.ObjectID, error)
+	GetHostDataSubmission(id primitive.ObjectID) (*model.HostDataSubmission, error)
+	ProcessHostDataSubmission(submission model.HostDataSubmission) error
+	// StartHostDataQueueWorkers start the workers that process the queued hostdata
+	StartHostDataQueueWorkers()
+
+	// RecordAgentPayload update the statistics of the agent versions with an hostdata received
+	RecordAgentPayload(agentVersion string, schemaVersion int, upcasted bool, rejection error) error
+	ListAgentVersionStats() ([]model.AgentVersionStats, error)
+
+	// AuthenticateAgent return the credential enrolled for the host, if secret is its secret
+	AuthenticateAgent(hostname, secret string) (*model.AgentCredential, error)
 }
 
 type HostDataService struct {
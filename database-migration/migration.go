@@ -27,11 +27,23 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/ercole-io/ercole/v2/config"
-	_ "github.com/ercole-io/ercole/v2/database-migration/migrations"
+	"github.com/ercole-io/ercole/v2/database-migration/migrations"
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/utils"
 )
 
+// SetCloudSecrets sets the master keys used by the migrations to encrypt the credentials of the cloud profiles
+func SetCloudSecrets(conf config.CloudSecrets) error {
+	keyring, err := cr.LoadKeyring(conf.Key, conf.KeyFile, conf.PreviousKeys)
+	if err != nil {
+		return err
+	}
+
+	migrations.CloudSecretsKeyring = keyring
+
+	return nil
+}
+
 func Migrate(conf config.Mongodb) error {
 	database, err := connectToMongodb(conf)
 	if err != nil {
@@ -52,6 +64,126 @@ func Migrate(conf config.Mongodb) error {
 	return nil
 }
 
+// MigrateUp apply the migrations up to the version to, or up to the latest one if to is zero.
+// With dryRun the database isn't changed. It return the migrations to apply, in order
+func MigrateUp(conf config.Mongodb, to uint64, dryRun bool) ([]migrate.Migration, error) {
+	return migrateTo(conf, to, dryRun, true)
+}
+
+// MigrateDown roll back the migrations down to the version to.
+// With dryRun the database isn't changed. It return the migrations to roll back, in order
+func MigrateDown(conf config.Mongodb, to uint64, dryRun bool) ([]migrate.Migration, error) {
+	return migrateTo(conf, to, dryRun, false)
+}
+
+func migrateTo(conf config.Mongodb, to uint64, dryRun, up bool) (pending []migrate.Migration, err error) {
+	database, err := connectToMongodb(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if errDisconnect := database.Client().Disconnect(context.TODO()); errDisconnect != nil && err == nil {
+			err = utils.NewError(errDisconnect, "Can't disconnect from the database!")
+		}
+	}()
+
+	migrations := migrate.RegisteredMigrations()
+	m := migrate.NewMigrate(database, migrations...)
+
+	actual, _, err := m.Version()
+	if err != nil {
+		return nil, utils.NewError(err, "Can't get the database version")
+	}
+
+	if up {
+		pending, err = PendingUpMigrations(migrations, actual, to)
+	} else {
+		pending, err = PendingDownMigrations(migrations, actual, to)
+	}
+
+	if err != nil || dryRun || len(pending) == 0 {
+		return pending, err
+	}
+
+	if up {
+		err = m.Up(len(pending))
+	} else {
+		err = m.Down(len(pending))
+	}
+
+	if err != nil {
+		return nil, utils.NewError(err, "Migration failed")
+	}
+
+	return pending, nil
+}
+
+// PendingUpMigrations return the migrations to apply, in order, to bring the database
+// from the version actual to the version to, or to the latest one if to is zero
+func PendingUpMigrations(migrations []migrate.Migration, actual, to uint64) ([]migrate.Migration, error) {
+	migrations = sortedMigrations(migrations)
+
+	if to == 0 && len(migrations) > 0 {
+		to = migrations[len(migrations)-1].Version
+	}
+
+	if to < actual || !hasMigration(migrations, to) {
+		return nil, utils.NewErrorf("%w: can't migrate up from %d to %d", utils.ErrInvalidMigrationVersion, actual, to)
+	}
+
+	pending := make([]migrate.Migration, 0)
+
+	for _, m := range migrations {
+		if m.Version > actual && m.Version <= to {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// PendingDownMigrations return the migrations to roll back, in order, to bring the database
+// from the version actual to the version to
+func PendingDownMigrations(migrations []migrate.Migration, actual, to uint64) ([]migrate.Migration, error) {
+	migrations = sortedMigrations(migrations)
+
+	if to > actual || (to != 0 && !hasMigration(migrations, to)) {
+		return nil, utils.NewErrorf("%w: can't migrate down from %d to %d", utils.ErrInvalidMigrationVersion, actual, to)
+	}
+
+	pending := make([]migrate.Migration, 0)
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if m := migrations[i]; m.Version > to && m.Version <= actual {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+func sortedMigrations(migrations []migrate.Migration) []migrate.Migration {
+	sorted := make([]migrate.Migration, len(migrations))
+	copy(sorted, migrations)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return sorted
+}
+
+func hasMigration(migrations []migrate.Migration, version uint64) bool {
+	for _, m := range migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
+
 func connectToMongodb(conf config.Mongodb) (*mongo.Database, error) {
 	clientOptions := options.Client().ApplyURI(conf.URI)
 
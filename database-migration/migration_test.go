@@ -0,0 +1,103 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	migrate "github.com/xakep666/mongo-migrate"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func versions(migrations []migrate.Migration) []uint64 {
+	out := make([]uint64, 0, len(migrations))
+	for _, m := range migrations {
+		out = append(out, m.Version)
+	}
+
+	return out
+}
+
+var testMigrations = []migrate.Migration{
+	{Version: 3, Description: "three"},
+	{Version: 1, Description: "one"},
+	{Version: 4, Description: "four"},
+	{Version: 2, Description: "two"},
+}
+
+func TestPendingUpMigrations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		actual   uint64
+		to       uint64
+		expected []uint64
+		err      error
+	}{
+		{name: "all", actual: 0, to: 0, expected: []uint64{1, 2, 3, 4}},
+		{name: "to latest", actual: 2, to: 0, expected: []uint64{3, 4}},
+		{name: "to version", actual: 1, to: 3, expected: []uint64{2, 3}},
+		{name: "already there", actual: 3, to: 3, expected: []uint64{}},
+		{name: "backward", actual: 3, to: 2, err: utils.ErrInvalidMigrationVersion},
+		{name: "unknown version", actual: 1, to: 5, err: utils.ErrInvalidMigrationVersion},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := PendingUpMigrations(testMigrations, tc.actual, tc.to)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, versions(actual))
+		})
+	}
+}
+
+func TestPendingDownMigrations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		actual   uint64
+		to       uint64
+		expected []uint64
+		err      error
+	}{
+		{name: "all", actual: 4, to: 0, expected: []uint64{4, 3, 2, 1}},
+		{name: "to version", actual: 4, to: 2, expected: []uint64{4, 3}},
+		{name: "from middle", actual: 3, to: 1, expected: []uint64{3, 2}},
+		{name: "already there", actual: 2, to: 2, expected: []uint64{}},
+		{name: "forward", actual: 2, to: 3, err: utils.ErrInvalidMigrationVersion},
+		{name: "unknown version", actual: 4, to: 7, err: utils.ErrInvalidMigrationVersion},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := PendingDownMigrations(testMigrations, tc.actual, tc.to)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, versions(actual))
+		})
+	}
+}
@@ -34,7 +34,7 @@ func init() {
 		return nil
 
 	}, func(db *mongo.Database) error {
-		return nil
+		return renameCollectionIfExists(db, "database_licenses_history", "oracle_database_licenses_history")
 	})
 
 	if err != nil {
@@ -34,7 +34,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return dropCollectionIfEmpty(db, "users")
 	})
 
 	if err != nil {
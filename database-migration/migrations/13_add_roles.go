@@ -35,7 +35,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return dropCollectionIfEmpty(db, "roles")
 	})
 
 	if err != nil {
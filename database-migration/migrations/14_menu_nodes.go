@@ -33,6 +33,10 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		if err := deleteNodes(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -428,3 +432,13 @@ func getNodes() []interface{} {
 			Parent: "",
 		}}
 }
+
+func deleteNodes(client *mongo.Database) error {
+	for _, node := range getNodes() {
+		if err := deleteNode(node.(model.Node), client); err != nil {
+			return err
+		}
+	}
+
+	return dropCollectionIfEmpty(client, "nodes")
+}
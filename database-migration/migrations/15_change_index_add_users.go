@@ -34,6 +34,10 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		if err := restoreIndexesAddUsers(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -70,3 +74,28 @@ func manageIndexesAddUsers(client *mongo.Database) error {
 
 	return nil
 }
+
+// restoreIndexesAddUsers restore the case sensitive index on the users
+func restoreIndexesAddUsers(client *mongo.Database) error {
+	collectionName := "users"
+	indexName := "username_1_provider_1"
+	ctx := context.TODO()
+
+	if err := dropIndexIfExists(client, collectionName, indexName); err != nil {
+		return utils.NewError(err, "Can't drop index:", indexName)
+	}
+
+	_, errIndex := client.Collection(collectionName).Indexes().
+		CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "username", Value: 1},
+				{Key: "provider", Value: 1},
+			},
+			Options: (&options.IndexOptions{}).SetUnique(true),
+		})
+	if errIndex != nil {
+		return errIndex
+	}
+
+	return nil
+}
@@ -32,6 +32,12 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		for _, node := range getMongoDBDBListNodes() {
+			if err := deleteNode(node.(model.Node), db); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 
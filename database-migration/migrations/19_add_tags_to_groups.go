@@ -45,6 +45,12 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		for _, groupName := range []string{"admin", "dba", "procurement"} {
+			if err := removeTagsFromGroup(groupName, db); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 
@@ -70,3 +76,13 @@ func addTagsToGroup(tags []string, groupName string, client *mongo.Database) err
 
 	return nil
 }
+
+func removeTagsFromGroup(groupName string, client *mongo.Database) error {
+	collectionName := "groups"
+
+	if _, err := client.Collection(collectionName).UpdateOne(context.TODO(), bson.M{"name": groupName}, bson.M{"$unset": bson.M{"tags": ""}}); err != nil {
+		return err
+	}
+
+	return nil
+}
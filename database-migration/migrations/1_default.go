@@ -45,6 +45,19 @@ func init() {
 		return nil
 
 	}, func(db *mongo.Database) error {
+		if err := rollbackHostsSchema(db); err != nil {
+			return err
+		}
+		if err := rollbackOracleDatabaseContractsSchema(db); err != nil {
+			return err
+		}
+
+		for _, collection := range []string{"alerts", "oracle_database_license_types"} {
+			if err := dropCollectionIfEmpty(db, collection); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 
@@ -191,3 +204,32 @@ func migrateOracleDatabaseLicenseTypes(client *mongo.Database) error {
 
 	return nil
 }
+
+// rollbackHostsSchema drop the indexes of the hosts and the collection, if empty
+func rollbackHostsSchema(client *mongo.Database) error {
+	for _, index := range []string{
+		"archived_1_hostname_1",
+		"hostname_1_createdAt_-1",
+		"archived_1_clusters.name_1",
+		"archived_1_clusters.vms.hostname_1",
+	} {
+		if err := dropIndexIfExists(client, "hosts", index); err != nil {
+			return err
+		}
+	}
+
+	return dropCollectionIfEmpty(client, "hosts")
+}
+
+// rollbackOracleDatabaseContractsSchema drop the indexes of the oracle_database_contracts and the collection, if empty
+func rollbackOracleDatabaseContractsSchema(client *mongo.Database) error {
+	collection := "oracle_database_contracts"
+
+	for _, index := range []string{"contractID_1", "licenseTypes._id_1"} {
+		if err := dropIndexIfExists(client, collection, index); err != nil {
+			return err
+		}
+	}
+
+	return dropCollectionIfEmpty(client, collection)
+}
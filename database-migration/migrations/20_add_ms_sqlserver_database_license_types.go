@@ -47,7 +47,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return deleteSqlServerDatabaseLicenseTypes(db, licenseTypes)
 	})
 
 	if err != nil {
@@ -43,7 +43,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return deleteNode(newNode, db)
 	})
 
 	if err != nil {
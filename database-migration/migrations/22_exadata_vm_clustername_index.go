@@ -24,7 +24,7 @@ import (
 )
 
 func init() {
-	err := migrate.Register(create_index_exadata_vm_clustername, nil)
+	err := migrate.Register(create_index_exadata_vm_clustername, drop_index_exadata_vm_clustername)
 
 	if err != nil {
 		panic(err)
@@ -44,3 +44,7 @@ func create_index_exadata_vm_clustername(db *mongo.Database) error {
 
 	return nil
 }
+
+func drop_index_exadata_vm_clustername(db *mongo.Database) error {
+	return dropIndexIfExists(db, "exadata_vm_clusternames", "instancerackid_-1_hostid_-1_vmname_-1")
+}
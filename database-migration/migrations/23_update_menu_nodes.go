@@ -95,6 +95,12 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		for _, node := range nodes {
+			if err := deleteNode(node, db); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 
@@ -31,6 +31,10 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		if err := restoreAlerttypeConfig(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -82,3 +86,41 @@ func updateAlerttypeConfig(client *mongo.Database) error {
 
 	return nil
 }
+
+// restoreAlerttypeConfig restore the alert types as flags, dropping their recipients
+func restoreAlerttypeConfig(client *mongo.Database) error {
+	collectionName := "config"
+
+	pipeline := mongo.Pipeline{
+		{
+			{Key: "$set", Value: bson.D{
+				{Key: "alertservice.emailer.alerttype", Value: bson.D{
+					{Key: "$arrayToObject", Value: bson.D{
+						{Key: "$map", Value: bson.D{
+							{Key: "input", Value: bson.D{
+								{Key: "$objectToArray", Value: "$alertservice.emailer.alerttype"},
+							}},
+							{Key: "as", Value: "field"},
+							{Key: "in", Value: bson.D{
+								{Key: "k", Value: "$$field.k"},
+								{Key: "v", Value: bson.D{
+									{Key: "$ifNull", Value: bson.A{"$$field.v.enable", false}},
+								}},
+							}},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+
+	filter := bson.M{
+		"alertservice.emailer.alerttype.newhost.enable": bson.M{"$exists": true},
+	}
+
+	if _, err := client.Collection(collectionName).UpdateMany(context.TODO(), filter, pipeline); err != nil {
+		return err
+	}
+
+	return nil
+}
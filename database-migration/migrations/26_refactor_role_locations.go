@@ -31,6 +31,10 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		if err := restoreLocationRolesCollection(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -56,3 +60,21 @@ func refactorLocationsRolesCollection(client *mongo.Database) error {
 
 	return nil
 }
+
+// restoreLocationRolesCollection restore the single location of the roles, keeping the first one
+func restoreLocationRolesCollection(client *mongo.Database) error {
+	collectionName := "roles"
+
+	filter := bson.M{"locations": bson.M{"$exists": true}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "location", Value: bson.D{{Key: "$first", Value: "$locations"}}}}}},
+		{{Key: "$unset", Value: "locations"}},
+	}
+
+	if _, err := client.Collection(collectionName).UpdateMany(context.TODO(), filter, pipeline); err != nil {
+		return err
+	}
+
+	return nil
+}
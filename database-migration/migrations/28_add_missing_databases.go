@@ -34,6 +34,10 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		if err := removeMissingDatabases(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -97,3 +101,33 @@ func addMissingDatabases(client *mongo.Database) error {
 
 	return nil
 }
+
+// removeMissingDatabases restore the missing databases as unlisted running databases,
+// since the two lists were merged and can't be told apart
+func removeMissingDatabases(client *mongo.Database) error {
+	collectionName := "hosts"
+
+	filter := bson.M{"features.oracle.database.missingDatabases": bson.M{"$exists": true}}
+
+	pipeline := mongo.Pipeline{
+		{{
+			Key: "$addFields",
+			Value: bson.M{
+				"features.oracle.database.unlistedRunningDatabases": bson.M{
+					"$map": bson.M{
+						"input": bson.M{"$ifNull": bson.A{"$features.oracle.database.missingDatabases", bson.A{}}},
+						"as":    "e",
+						"in":    "$$e.name",
+					},
+				},
+			},
+		}},
+		{{Key: "$unset", Value: "features.oracle.database.missingDatabases"}},
+	}
+
+	if _, err := client.Collection(collectionName).UpdateMany(context.TODO(), filter, pipeline); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -33,6 +33,10 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
+		if err := removeIsDR(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -60,3 +64,15 @@ func addIsDR(client *mongo.Database) error {
 
 	return nil
 }
+
+// removeIsDR remove the flag from the hosts that aren't disaster recovery
+func removeIsDR(client *mongo.Database) error {
+	collectionName := "hosts"
+
+	if _, err := client.Collection(collectionName).
+		UpdateMany(context.Background(), bson.M{"isDR": false}, bson.M{"$unset": bson.M{"isDR": ""}}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -17,11 +17,11 @@ package migrations
 
 import (
 	"context"
-	"errors"
 
 	migrate "github.com/xakep666/mongo-migrate"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/ercole-io/ercole/v2/utils"
 )
@@ -89,5 +89,79 @@ func unwind_oracle_contracts(db *mongo.Database) error {
 }
 
 func group_oracle_contract_by_license_type_id(db *mongo.Database) error {
-	return utils.NewError(errors.New("Not yet implemented"))
+	collection := "oracle_database_contracts"
+	ctx := context.TODO()
+
+	cursor, err := db.Collection(collection).
+		Aggregate(ctx,
+			bson.A{
+				bson.M{
+					"$group": bson.M{
+						"_id":          "$contractID",
+						"csi":          bson.M{"$first": "$csi"},
+						"licenseTypes": bson.M{"$push": "$$ROOT"},
+					},
+				},
+				bson.M{
+					"$project": bson.M{
+						"_id":        0,
+						"contractID": "$_id",
+						"csi":        1,
+						"licenseTypes": bson.M{
+							"$map": bson.M{
+								"input": "$licenseTypes",
+								"as":    "licenseType",
+								"in": bson.M{
+									"$arrayToObject": bson.M{
+										"$filter": bson.M{
+											"input": bson.M{"$objectToArray": "$$licenseType"},
+											"as":    "field",
+											"cond": bson.M{
+												"$not": bson.A{bson.M{"$in": bson.A{"$$field.k", bson.A{"contractID", "csi"}}}},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			})
+	if err != nil {
+		return utils.NewError(err, "Can't aggregate", collection)
+	}
+
+	var contracts []interface{}
+	if err := cursor.All(ctx, &contracts); err != nil {
+		return utils.NewError(err, "Can't decode cursor")
+	}
+
+	if err := db.Collection(collection).Drop(ctx); err != nil {
+		return utils.NewError(err, "Can't drop", collection)
+	}
+
+	if len(contracts) > 0 {
+		if _, err := db.Collection(collection).InsertMany(ctx, contracts); err != nil {
+			return utils.NewError(err, "Can't insert all contracts")
+		}
+	}
+
+	if _, err := db.Collection(collection).
+		Indexes().
+		CreateMany(ctx,
+			[]mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "contractID", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				{
+					Keys:    bson.D{{Key: "licenseTypes._id", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+			},
+		); err != nil {
+		return utils.NewError(err, "Can't create indexes", collection)
+	}
+
+	return nil
 }
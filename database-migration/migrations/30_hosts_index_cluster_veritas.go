@@ -32,7 +32,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return dropIndexIfExists(db, "hosts", "clusterMembershipStatus.veritasClusterServer_-1_archived_1")
 	})
 
 	if err != nil {
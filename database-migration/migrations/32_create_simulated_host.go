@@ -31,7 +31,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return dropCollectionIfEmpty(db, "simulated_hosts")
 	})
 
 	if err != nil {
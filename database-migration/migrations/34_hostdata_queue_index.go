@@ -0,0 +1,70 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const hostDataQueueIndexName = "status_nextAttemptAt_submittedAt"
+
+func init() {
+	err := migrate.Register(func(db *mongo.Database) error {
+		if err := createHostDataQueueIndex(db); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(db *mongo.Database) error {
+		if err := dropIndexIfExists(db, "hostdata_queue", hostDataQueueIndexName); err != nil {
+			return err
+		}
+
+		for _, collection := range []string{"hostdata_queue", "hostdata_dead_letters"} {
+			if err := dropCollectionIfEmpty(db, collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+}
+
+func createHostDataQueueIndex(db *mongo.Database) error {
+	if _, err := db.Collection("hostdata_queue").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "status", Value: 1},
+			{Key: "nextAttemptAt", Value: 1},
+			{Key: "submittedAt", Value: 1},
+		},
+		Options: options.Index().SetName(hostDataQueueIndexName),
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
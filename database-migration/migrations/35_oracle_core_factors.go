@@ -0,0 +1,92 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ercole-io/ercole/v2/model"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const oracleCoreFactorsCollection = "oracle_core_factors"
+
+// oracleCoreFactors is the Oracle Processor Core Factor Table, plus the rules
+// of the Oracle licensing policy for authorized cloud environments: 2 vCPUs, which
+// are a single hyper-threaded core, count as one processor license
+var oracleCoreFactors = []model.CoreFactor{
+	{Description: "Amazon Web Services", CloudMembership: model.CloudMembershipAws, Factor: 1},
+	{Description: "Microsoft Azure", CloudMembership: model.CloudMembershipAzure, Factor: 1},
+	{Description: "Google Cloud Platform", CloudMembership: model.CloudMembershipGcp, Factor: 1},
+	{Description: "Oracle Cloud Infrastructure", CloudMembership: model.CloudMembershipOci, Factor: 1},
+	{Description: "Sun and Fujitsu SPARC64 VI, VII, VII+", CPUModelRegex: `SPARC64[- ]?VII?\b`, Factor: 0.75, Priority: 10},
+	{Description: "Sun UltraSPARC IV, IV+ or earlier", CPUModelRegex: `UltraSPARC[- ]?(I|II|III|IV)\b`, Factor: 0.75, Priority: 10},
+	{Description: "Sun UltraSPARC T1, T2, T2+", CPUModelRegex: `UltraSPARC[- ]?T[12]`, Factor: 0.5, Priority: 20},
+	{Description: "SPARC T3, T4, T5, T7, T8, M5, M6, M7, M8, S7, SPARC64 X, X+, XII", CPUModelRegex: `SPARC`, Factor: 0.5, Priority: 30},
+	{Description: "HP PA-RISC", CPUModelRegex: `PA-?RISC`, Factor: 0.75, Priority: 10},
+	{Description: "IBM POWER", CPUModelRegex: `POWER[0-9]|PowerPC`, Factor: 1, Priority: 10},
+	{Description: "IBM System z", CPUModelRegex: `s390|z/Architecture`, Factor: 1, Priority: 10},
+	{Description: "Intel Itanium", CPUModelRegex: `Itanium`, Factor: 1, Priority: 10},
+	{Description: "Intel Xeon and other x86 processors", CPUModelRegex: `Intel|Xeon`, Factor: 0.5, Priority: 50},
+	{Description: "AMD Opteron, EPYC and other x86 processors", CPUModelRegex: `AMD|EPYC|Opteron`, Factor: 0.5, Priority: 50},
+}
+
+func init() {
+	err := migrate.Register(func(db *mongo.Database) error {
+		if err := addOracleCoreFactors(db); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(db *mongo.Database) error {
+		descriptions := make([]string, 0, len(oracleCoreFactors))
+		for _, cf := range oracleCoreFactors {
+			descriptions = append(descriptions, cf.Description)
+		}
+
+		if _, err := db.Collection(oracleCoreFactorsCollection).DeleteMany(context.TODO(), bson.M{
+			"description": bson.M{"$in": descriptions},
+		}); err != nil {
+			return err
+		}
+
+		return dropCollectionIfEmpty(db, oracleCoreFactorsCollection)
+	})
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+}
+
+func addOracleCoreFactors(db *mongo.Database) error {
+	coreFactors := make([]interface{}, 0, len(oracleCoreFactors))
+
+	for _, cf := range oracleCoreFactors {
+		cf.ID = primitive.NewObjectID()
+		coreFactors = append(coreFactors, cf)
+	}
+
+	if _, err := db.Collection(oracleCoreFactorsCollection).InsertMany(context.TODO(), coreFactors); err != nil {
+		return err
+	}
+
+	return nil
+}
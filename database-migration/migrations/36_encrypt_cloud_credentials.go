@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+// CloudSecretsKeyring encrypts the credentials of the cloud profiles.
+// When it's nil the credentials are left in clear, they can be encrypted later by `ercole cloud-secrets rotate`
+var CloudSecretsKeyring *cr.Keyring
+
+var cloudCredentialFields = []struct {
+	collection string
+	field      string
+}{
+	{"aws_profiles", "secretaccesskey"},
+	{"azure_profiles", "clientsecret"},
+	{"gcp_profiles", "privatekey"},
+	{"oci_profiles", "privateKey"},
+}
+
+func init() {
+	err := migrate.Register(func(db *mongo.Database) error {
+		if CloudSecretsKeyring == nil {
+			fmt.Println("No master key configured in ThunderService.CloudSecrets: the cloud credentials are left in clear")
+			return nil
+		}
+
+		return updateCloudCredentials(db, CloudSecretsKeyring.Encrypt)
+	}, func(db *mongo.Database) error {
+		return updateCloudCredentials(db, CloudSecretsKeyring.Decrypt)
+	})
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+}
+
+func updateCloudCredentials(db *mongo.Database, update func(string) (string, error)) error {
+	ctx := context.TODO()
+
+	for _, c := range cloudCredentialFields {
+		cur, err := db.Collection(c.collection).Find(ctx, bson.M{c.field: bson.M{"$type": "string"}})
+		if err != nil {
+			return err
+		}
+
+		docs := make([]bson.M, 0)
+		if err := cur.All(ctx, &docs); err != nil {
+			return err
+		}
+
+		for _, doc := range docs {
+			value := doc[c.field].(string)
+
+			updated, err := update(value)
+			if err != nil {
+				return fmt.Errorf("%s of %s %v: %w", c.field, c.collection, doc["_id"], err)
+			}
+
+			if updated == value {
+				continue
+			}
+
+			if _, err := db.Collection(c.collection).UpdateOne(ctx,
+				bson.M{"_id": doc["_id"]},
+				bson.M{"$set": bson.M{c.field: updated}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	apiTokensCollection        = "api_tokens"
+	apiTokensHashIndexName     = "hash_1"
+	apiTokensUsernameIndexName = "username_1_createdAt_1"
+)
+
+func init() {
+	err := migrate.Register(func(db *mongo.Database) error {
+		if err := createAPITokensIndexes(db); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(db *mongo.Database) error {
+		for _, index := range []string{apiTokensHashIndexName, apiTokensUsernameIndexName} {
+			if err := dropIndexIfExists(db, apiTokensCollection, index); err != nil {
+				return err
+			}
+		}
+
+		return dropCollectionIfEmpty(db, apiTokensCollection)
+	})
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+}
+
+// createAPITokensIndexes create the index used to authenticate the requests by the hash of the token,
+// and the one used to list the tokens of an user
+func createAPITokensIndexes(db *mongo.Database) error {
+	if _, err := db.Collection(apiTokensCollection).Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetName(apiTokensHashIndexName).SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "username", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+			Options: options.Index().SetName(apiTokensUsernameIndexName),
+		},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
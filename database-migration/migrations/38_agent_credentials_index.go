@@ -0,0 +1,65 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	agentCredentialsCollection        = "agent_credentials"
+	agentCredentialsHostnameIndexName = "hostname_1"
+)
+
+func init() {
+	err := migrate.Register(func(db *mongo.Database) error {
+		if err := createAgentCredentialsIndexes(db); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(db *mongo.Database) error {
+		if err := dropIndexIfExists(db, agentCredentialsCollection, agentCredentialsHostnameIndexName); err != nil {
+			return err
+		}
+
+		return dropCollectionIfEmpty(db, agentCredentialsCollection)
+	})
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+}
+
+// createAgentCredentialsIndexes create the index used to authenticate the agents by their hostname,
+// that ensure each host has only one credential
+func createAgentCredentialsIndexes(db *mongo.Database) error {
+	if _, err := db.Collection(agentCredentialsCollection).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "hostname", Value: 1}},
+		Options: options.Index().SetName(agentCredentialsHostnameIndexName).SetUnique(true),
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
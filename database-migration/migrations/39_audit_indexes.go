@@ -0,0 +1,85 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	auditCollection            = "audit"
+	auditDateIndexName         = "date_1"
+	auditUsernameDateIndexName = "username_1_date_1"
+	auditRouteDateIndexName    = "route_1_date_1"
+)
+
+func init() {
+	err := migrate.Register(func(db *mongo.Database) error {
+		if err := createAuditIndexes(db); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(db *mongo.Database) error {
+		for _, index := range []string{auditDateIndexName, auditUsernameDateIndexName, auditRouteDateIndexName} {
+			if err := dropIndexIfExists(db, auditCollection, index); err != nil {
+				return err
+			}
+		}
+
+		return dropCollectionIfEmpty(db, auditCollection)
+	})
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+}
+
+// createAuditIndexes create the indexes used to search the audit log by date, user and route,
+// and to delete the entries older than the retention period
+func createAuditIndexes(db *mongo.Database) error {
+	if _, err := db.Collection(auditCollection).Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "date", Value: 1}},
+			Options: options.Index().SetName(auditDateIndexName),
+		},
+		{
+			Keys: bson.D{
+				{Key: "username", Value: 1},
+				{Key: "date", Value: 1},
+			},
+			Options: options.Index().SetName(auditUsernameDateIndexName),
+		},
+		{
+			Keys: bson.D{
+				{Key: "route", Value: 1},
+				{Key: "date", Value: 1},
+			},
+			Options: options.Index().SetName(auditRouteDateIndexName),
+		},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
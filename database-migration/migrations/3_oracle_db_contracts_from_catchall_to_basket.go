@@ -37,6 +37,10 @@ func init() {
 		return nil
 
 	}, func(db *mongo.Database) error {
+		if err := migrateFromBasketToCatchAll(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -75,3 +79,20 @@ func migrateOracleLicensesContract(db *mongo.Database) error {
 
 	return nil
 }
+
+// migrateFromBasketToCatchAll rename back basket to catchAll. The contracts set as basket
+// because they were unlimited can't be told apart, so they remain catchAll
+func migrateFromBasketToCatchAll(db *mongo.Database) error {
+	collection := "oracle_database_contracts"
+	ctx := context.TODO()
+
+	filter := bson.M{}
+	update := bson.M{"$rename": bson.M{"basket": "catchAll"}}
+
+	_, err := db.Collection(collection).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return utils.NewError(err, "Can't rename from basket to catchAll", collection)
+	}
+
+	return nil
+}
@@ -34,6 +34,10 @@ func init() {
 		return nil
 
 	}, func(db *mongo.Database) error {
+		if err := migrateLicenseRemoveFieldIgnored(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -56,3 +60,18 @@ func migrateLicenseAddFieldIgnored(db *mongo.Database) error {
 
 	return nil
 }
+
+func migrateLicenseRemoveFieldIgnored(db *mongo.Database) error {
+	collection := "hosts"
+	ctx := context.TODO()
+
+	filter := bson.M{"features.oracle.database.databases.licenses.ignored": bson.M{"$exists": true}}
+	update := bson.M{"$unset": bson.M{"features.oracle.database.databases.$[].licenses.$[].ignored": ""}}
+
+	_, err := db.Collection(collection).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return utils.NewError(err, "Can't remove field 'ignored' from hosts collection", collection)
+	}
+
+	return nil
+}
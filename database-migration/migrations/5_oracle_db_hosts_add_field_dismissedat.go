@@ -39,6 +39,10 @@ func init() {
 		return nil
 
 	}, func(db *mongo.Database) error {
+		if err := migrateHostsRemoveFieldDismissedAt(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -107,3 +111,17 @@ func migrateHostsAddFieldDismissedAtArchivedTrue(db *mongo.Database) error {
 
 	return nil
 }
+
+func migrateHostsRemoveFieldDismissedAt(db *mongo.Database) error {
+	collection := "hosts"
+	ctx := context.TODO()
+	filter := bson.M{"dismissedAt": bson.M{"$exists": true}}
+	update := bson.M{"$unset": bson.M{"dismissedAt": ""}}
+
+	_, err := db.Collection(collection).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return utils.NewError(err, "Can't remove field 'dismissedAt' from hosts collection", collection)
+	}
+
+	return nil
+}
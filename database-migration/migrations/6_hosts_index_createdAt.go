@@ -24,7 +24,7 @@ import (
 )
 
 func init() {
-	err := migrate.Register(create_index_hosts, nil)
+	err := migrate.Register(create_index_hosts, drop_index_hosts)
 
 	if err != nil {
 		panic(err)
@@ -42,3 +42,7 @@ func create_index_hosts(db *mongo.Database) error {
 
 	return nil
 }
+
+func drop_index_hosts(db *mongo.Database) error {
+	return dropIndexIfExists(db, "hosts", "createdAt_-1")
+}
@@ -37,6 +37,10 @@ func init() {
 		return nil
 
 	}, func(db *mongo.Database) error {
+		if err := rename_mysql_contracts_collection_field(db); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -155,3 +159,23 @@ func rename_mysql_agreements_collection_field(db *mongo.Database) error {
 
 	return nil
 }
+
+// rename_mysql_contracts_collection_field move back the mysql contracts to the agreements.
+// The oracle ones aren't moved back: oracle_database_contracts is created by the first migration
+// and was already used by the versions before this one
+func rename_mysql_contracts_collection_field(db *mongo.Database) error {
+	collectionFrom := "mysql_contracts"
+	collectionTo := "mysql_agreements"
+	ctx := context.TODO()
+
+	if err := renameCollectionIfExists(db, collectionFrom, collectionTo); err != nil {
+		return utils.NewError(err, "Can't rename collection", collectionFrom)
+	}
+
+	update := bson.M{"$rename": bson.M{"contractID": "agreementID"}}
+	if _, err := db.Collection(collectionTo).UpdateMany(ctx, bson.M{"contractID": bson.M{"$exists": true}}, update); err != nil {
+		return utils.NewError(err, "Can't rename from contractID to agreementID", collectionTo)
+	}
+
+	return nil
+}
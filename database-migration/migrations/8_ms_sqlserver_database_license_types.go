@@ -34,7 +34,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return dropCollectionIfEmpty(db, "ms_sqlserver_database_license_types")
 	})
 
 	if err != nil {
@@ -49,7 +49,7 @@ func init() {
 
 		return nil
 	}, func(db *mongo.Database) error {
-		return nil
+		return deleteSqlServerDatabaseLicenseTypes(db, licenseTypes)
 	})
 
 	if err != nil {
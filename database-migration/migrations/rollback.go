@@ -0,0 +1,101 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+// The down steps revert what the up steps did so that the previous version of ercole can work
+// on the database. Collections created by a migration are dropped only when they are empty,
+// so rolling back never lose data that can't be created again.
+
+const (
+	errCodeNamespaceNotFound = 26
+	errCodeIndexNotFound     = 27
+)
+
+// dropIndexIfExists drop the index of the collection, if both exist
+func dropIndexIfExists(db *mongo.Database, collectionName, indexName string) error {
+	_, err := db.Collection(collectionName).Indexes().DropOne(context.TODO(), indexName)
+
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == errCodeNamespaceNotFound || cmdErr.Code == errCodeIndexNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// dropCollectionIfEmpty drop the collection if it hasn't any document
+func dropCollectionIfEmpty(db *mongo.Database, collectionName string) error {
+	count, err := db.Collection(collectionName).CountDocuments(context.TODO(), bson.D{})
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	return db.Collection(collectionName).Drop(context.TODO())
+}
+
+// renameCollectionIfExists rename the collection from to the collection to,
+// if from exists and to doesn't
+func renameCollectionIfExists(db *mongo.Database, from, to string) error {
+	names, err := db.ListCollectionNames(context.TODO(), bson.M{"name": bson.M{"$in": bson.A{from, to}}})
+	if err != nil {
+		return err
+	}
+
+	if len(names) != 1 || names[0] != from {
+		return nil
+	}
+
+	return db.Client().Database("admin").RunCommand(context.TODO(), bson.D{
+		{Key: "renameCollection", Value: db.Name() + "." + from},
+		{Key: "to", Value: db.Name() + "." + to},
+	}).Err()
+}
+
+// deleteNode delete a menu node inserted by insertNode
+func deleteNode(node model.Node, db *mongo.Database) error {
+	_, err := db.Collection("nodes").DeleteOne(context.TODO(), bson.M{"name": node.Name, "parent": node.Parent})
+
+	return err
+}
+
+// deleteSqlServerDatabaseLicenseTypes delete the license types inserted by addSqlServerDatabaseLicenseTypes
+func deleteSqlServerDatabaseLicenseTypes(db *mongo.Database, licenseTypes []interface{}) error {
+	ids := make(bson.A, 0, len(licenseTypes))
+
+	for _, licenseType := range licenseTypes {
+		if lt, ok := licenseType.(model.SqlServerDatabaseLicenseType); ok {
+			ids = append(ids, lt.ID)
+		}
+	}
+
+	_, err := db.Collection("ms_sqlserver_database_license_types").DeleteMany(context.TODO(), bson.M{"_id": bson.M{"$in": ids}})
+
+	return err
+}
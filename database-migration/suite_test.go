@@ -0,0 +1,176 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ercole-io/ercole/v2/config"
+)
+
+type MigrationSuite struct {
+	suite.Suite
+
+	conf config.Mongodb
+	db   *mongo.Database
+}
+
+func (m *MigrationSuite) SetupSuite() {
+	val, ok := os.LookupEnv("MONGODB_URI")
+	if !ok {
+		val = "mongodb://127.0.0.1:27017"
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	m.conf = config.Mongodb{
+		URI:    val,
+		DBName: fmt.Sprintf("ercole_test_%d", rand.Int()),
+	}
+	fmt.Println("DBNAME:", m.conf.DBName)
+	m.conf.URI += "/" + m.conf.DBName
+
+	var err error
+	m.db, err = connectToMongodb(m.conf)
+	m.Require().NoError(err)
+
+	m.Require().NoError(Migrate(m.conf))
+}
+
+func TestMigrationSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skip test for mongodb database(database-migration)")
+	}
+
+	suite.Run(t, new(MigrationSuite))
+}
+
+func (m *MigrationSuite) TearDownSuite() {
+	m.Require().NoError(m.db.Drop(context.TODO()))
+	m.Require().NoError(m.db.Client().Disconnect(context.TODO()))
+}
+
+func (m *MigrationSuite) version() uint64 {
+	version, _, err := migrate.NewMigrate(m.db).Version()
+	m.Require().NoError(err)
+
+	return version
+}
+
+func (m *MigrationSuite) findOne(collection string, filter interface{}) bson.M {
+	var out bson.M
+	m.Require().NoError(m.db.Collection(collection).FindOne(context.TODO(), filter).Decode(&out))
+
+	return out
+}
+
+func (m *MigrationSuite) TestMigrateDownAndUp() {
+	ctx := context.TODO()
+	_, latest, err := GetVersions(m.conf)
+	m.Require().NoError(err)
+
+	_, err = m.db.Collection("hosts").InsertOne(ctx, bson.M{
+		"hostname":    "foobar",
+		"archived":    false,
+		"isDR":        false,
+		"dismissedAt": nil,
+		"features": bson.M{"oracle": bson.M{"database": bson.M{
+			"databases": bson.A{
+				bson.M{"name": "ERCOLE", "licenses": bson.A{bson.M{"licenseTypeID": "A90611", "ignored": false}}},
+			},
+			"missingDatabases": bson.A{bson.M{"name": "MISSING", "ignored": false, "ignoredComment": nil}},
+		}}},
+	})
+	m.Require().NoError(err)
+
+	_, err = m.db.Collection("oracle_database_contracts").InsertMany(ctx, []interface{}{
+		bson.M{"contractID": "AAA", "csi": "123", "licenseTypeID": "A90611", "basket": true},
+		bson.M{"contractID": "AAA", "csi": "123", "licenseTypeID": "A90649", "basket": false},
+	})
+	m.Require().NoError(err)
+
+	_, err = m.db.Collection("config").InsertOne(ctx, bson.M{
+		"alertservice": bson.M{"emailer": bson.M{"alerttype": bson.M{
+			"newhost": bson.M{"enable": true, "to": bson.A{}},
+			"nodata":  bson.M{"enable": false, "to": bson.A{"foo@bar.com"}},
+		}}},
+	})
+	m.Require().NoError(err)
+
+	m.T().Run("dry_run_doesnt_change_the_database", func(t *testing.T) {
+		pending, err := MigrateDown(m.conf, 1, true)
+		require.NoError(t, err)
+		require.Len(t, pending, int(latest-1))
+		require.Equal(t, latest, m.version())
+	})
+
+	m.T().Run("should_migrate_down", func(t *testing.T) {
+		_, err := MigrateDown(m.conf, 1, false)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), m.version())
+
+		host := m.findOne("hosts", bson.M{"hostname": "foobar"})
+		require.NotContains(t, host, "isDR")
+		require.NotContains(t, host, "dismissedAt")
+
+		database := host["features"].(bson.M)["oracle"].(bson.M)["database"].(bson.M)
+		require.NotContains(t, database, "missingDatabases")
+		require.Equal(t, bson.A{"MISSING"}, database["unlistedRunningDatabases"])
+		license := database["databases"].(bson.A)[0].(bson.M)["licenses"].(bson.A)[0].(bson.M)
+		require.NotContains(t, license, "ignored")
+
+		contract := m.findOne("oracle_database_contracts", bson.M{"contractID": "AAA"})
+		require.Equal(t, "123", contract["csi"])
+		require.Len(t, contract["licenseTypes"], 2)
+
+		conf := m.findOne("config", bson.M{})
+		alertTypes := conf["alertservice"].(bson.M)["emailer"].(bson.M)["alerttype"].(bson.M)
+		require.Equal(t, true, alertTypes["newhost"])
+		require.Equal(t, false, alertTypes["nodata"])
+
+		collections, err := m.db.ListCollectionNames(ctx, bson.M{})
+		require.NoError(t, err)
+		require.NotContains(t, collections, "nodes")
+		require.NotContains(t, collections, "ms_sqlserver_database_license_types")
+		require.NotContains(t, collections, "scenarios")
+		require.Contains(t, collections, "hosts")
+	})
+
+	m.T().Run("should_migrate_up_again", func(t *testing.T) {
+		applied, err := MigrateUp(m.conf, 0, false)
+		require.NoError(t, err)
+		require.Len(t, applied, int(latest-1))
+		require.Equal(t, latest, m.version())
+
+		host := m.findOne("hosts", bson.M{"hostname": "foobar"})
+		database := host["features"].(bson.M)["oracle"].(bson.M)["database"].(bson.M)
+		require.Len(t, database["missingDatabases"], 1)
+
+		count, err := m.db.Collection("oracle_database_contracts").CountDocuments(ctx, bson.M{"contractID": "AAA"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+	})
+}
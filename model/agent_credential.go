@@ -0,0 +1,45 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AgentSecretPrefix is the prefix of the secrets of the agent credentials
+const AgentSecretPrefix = "ercagt_"
+
+// AgentCredential holds the credential enrolled for the agent of a host.
+// The agent authenticate with its hostname as username and the secret as password,
+// and it can send only the data of its host. Only the hash of the secret is stored
+type AgentCredential struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	Hostname string             `json:"hostname" bson:"hostname"`
+	Hash     string             `json:"-" bson:"hash"`
+	// Hint contains the first characters of the secret, to recognize it
+	Hint       string     `json:"hint" bson:"hint"`
+	CreatedAt  time.Time  `json:"createdAt" bson:"createdAt"`
+	RotatedAt  *time.Time `json:"rotatedAt" bson:"rotatedAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt" bson:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt" bson:"revokedAt"`
+}
+
+// IsRevoked return true if the credential has been revoked
+func (c AgentCredential) IsRevoked() bool {
+	return c.RevokedAt != nil
+}
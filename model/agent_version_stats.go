@@ -0,0 +1,34 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package model
+
+import (
+	"time"
+)
+
+// AgentVersionStats holds how many hostdata sent by the agents with a version,
+// declaring a schema version, were accepted or rejected
+type AgentVersionStats struct {
+	AgentVersion   string     `json:"agentVersion" bson:"agentVersion"`
+	SchemaVersion  int        `json:"schemaVersion" bson:"schemaVersion"`
+	Accepted       int        `json:"accepted" bson:"accepted"`
+	Rejected       int        `json:"rejected" bson:"rejected"`
+	Upcasted       int        `json:"upcasted" bson:"upcasted"`
+	AcceptanceRate float64    `json:"acceptanceRate" bson:"-"`
+	FirstSeen      time.Time  `json:"firstSeen" bson:"firstSeen"`
+	LastSeen       time.Time  `json:"lastSeen" bson:"lastSeen"`
+	LastRejectedAt *time.Time `json:"lastRejectedAt,omitempty" bson:"lastRejectedAt,omitempty"`
+	LastError      string     `json:"lastError,omitempty" bson:"lastError,omitempty"`
+}
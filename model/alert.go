@@ -32,8 +32,48 @@ type Alert struct {
 	Description             string                 `json:"description" bson:"description"`
 	Date                    time.Time              `json:"date" bson:"date"`
 	OtherInfo               map[string]interface{} `json:"otherInfo" bson:"otherInfo"`
+	// Occurrences contains how many times the alert was thrown while it was open
+	Occurrences int `json:"occurrences,omitempty" bson:"occurrences,omitempty"`
+	// LastSeen contains the date of the last occurrence of the alert
+	LastSeen *time.Time `json:"lastSeen,omitempty" bson:"lastSeen,omitempty"`
+	// Flapping contains true if the alert was thrown too many times in the flapping window
+	Flapping bool `json:"flapping,omitempty" bson:"flapping,omitempty"`
+	// Suppressed contains true if the alert was recorded but not notified
+	Suppressed bool `json:"suppressed,omitempty" bson:"suppressed,omitempty"`
+	// Assignee contains the username of who is in charge of the alert
+	Assignee string `json:"assignee,omitempty" bson:"assignee,omitempty"`
+	// Comments contains the comments about the alert, from the oldest
+	Comments []AlertComment `json:"comments,omitempty" bson:"comments,omitempty"`
+	// ResolutionReason contains why the alert was resolved
+	ResolutionReason string `json:"resolutionReason,omitempty" bson:"resolutionReason,omitempty"`
+	// StatusHistory contains the changes of status and assignee of the alert, from the oldest
+	StatusHistory []AlertStatusChange `json:"statusHistory,omitempty" bson:"statusHistory,omitempty"`
 }
 
+// AlertComment holds a comment about an alert
+type AlertComment struct {
+	Date     time.Time `json:"date" bson:"date"`
+	Username string    `json:"username" bson:"username"`
+	Text     string    `json:"text" bson:"text"`
+}
+
+// AlertStatusChange holds a change of status or assignee of an alert.
+// Username is empty when the change wasn't made by a user
+type AlertStatusChange struct {
+	Date     time.Time `json:"date" bson:"date"`
+	Username string    `json:"username" bson:"username"`
+	Field    string    `json:"field" bson:"field"`
+	From     string    `json:"from" bson:"from"`
+	To       string    `json:"to" bson:"to"`
+	Reason   string    `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// Fields of the alert tracked in the status history
+const (
+	AlertStatusChangeFieldStatus   string = "alertStatus"
+	AlertStatusChangeFieldAssignee string = "assignee"
+)
+
 const (
 	AlertCategoryEngine  string = "ENGINE"
 	AlertCategoryAgent   string = "AGENT"
@@ -66,6 +106,12 @@ const (
 	AlertCodeNewOption         string = "NEW_OPTION"
 	AlertCodeIncreasedCPUCores string = "INCREASED_CPU_CORES"
 	AlertCodeMissingDatabase   string = "MISSING_DATABASE"
+
+	AlertCodeLicenseNonCompliant     string = "LICENSE_NON_COMPLIANT"
+	AlertCodeContractSupportExpiring string = "CONTRACT_SUPPORT_EXPIRING"
+	AlertCodeContractSupportExpired  string = "CONTRACT_SUPPORT_EXPIRED"
+
+	AlertCodeClusterTopologyMismatch string = "CLUSTER_TOPOLOGY_MISMATCH"
 )
 
 func getAlertCodes() []string {
@@ -73,6 +119,8 @@ func getAlertCodes() []string {
 		AlertCodeNewServer, AlertCodeUnlistedRunningDatabase, AlertCodeMissingPrimaryDatabase, AlertCodeMissingHostInErcole, AlertCodeMissingHostInCmdb, AlertCodeAgentError,
 		AlertCodeNoData,
 		AlertCodeNewDatabase, AlertCodeNewLicense, AlertCodeNewOption, AlertCodeIncreasedCPUCores, AlertCodeMissingDatabase, AlertCodeDismissHost,
+		AlertCodeLicenseNonCompliant, AlertCodeContractSupportExpiring, AlertCodeContractSupportExpired,
+		AlertCodeClusterTopologyMismatch,
 	}
 }
 
@@ -94,10 +142,12 @@ const (
 	AlertStatusAck string = "ACK"
 	// Dismissed contains string DISMISSED
 	AlertStatusDismissed string = "DISMISSED"
+	// Resolved contains string RESOLVED
+	AlertStatusResolved string = "RESOLVED"
 )
 
 func getAlertStatuses() []string {
-	return []string{AlertStatusNew, AlertStatusAck}
+	return []string{AlertStatusNew, AlertStatusAck, AlertStatusResolved}
 }
 
 func (alert Alert) IsValid() bool {
@@ -124,3 +174,39 @@ fields:
 func (alert Alert) IsCode(code string) bool {
 	return alert.AlertCode == code
 }
+
+// alertIdentityKeys contains, by alert code, the keys of otherInfo other than hostname and dbname
+// that distinguish alerts with the same code
+var alertIdentityKeys = map[string][]string{
+	AlertCodeNewLicense:              {"licenseTypeID"},
+	AlertCodeNewOption:               {"licenseTypeID"},
+	AlertCodeLicenseNonCompliant:     {"licenseTypeID", "location"},
+	AlertCodeContractSupportExpiring: {"contractID", "leadTime"},
+	AlertCodeContractSupportExpired:  {"contractID", "leadTime"},
+	AlertCodeClusterTopologyMismatch: {"kind", "location"},
+}
+
+// IdentityKeys return the keys of otherInfo, other than hostname and dbname,
+// that distinguish the alert from the others with the same code
+func (alert Alert) IdentityKeys() []string {
+	return alertIdentityKeys[alert.AlertCode]
+}
+
+// Hostname return the hostname of the alert, or empty string if the alert isn't related to a host
+func (alert Alert) Hostname() string {
+	return alert.otherInfoString("hostname")
+}
+
+// Dbname return the database name of the alert, or empty string if the alert isn't related to a database
+func (alert Alert) Dbname() string {
+	return alert.otherInfoString("dbname")
+}
+
+func (alert Alert) otherInfoString(key string) string {
+	value, ok := alert.OtherInfo[key].(string)
+	if !ok {
+		return ""
+	}
+
+	return value
+}
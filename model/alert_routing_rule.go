@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// AlertRoutingRule holds a rule that choose who must be notified about the alerts it matches
+type AlertRoutingRule struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Enabled     bool               `json:"enabled" bson:"enabled"`
+	// Priority contains the evaluation order of the rule, lower values are evaluated first
+	Priority int `json:"priority" bson:"priority"`
+	// StopProcessing contains true if the rules that follow aren't evaluated when this one matches
+	StopProcessing bool              `json:"stopProcessing" bson:"stopProcessing"`
+	Match          AlertRoutingMatch `json:"match" bson:"match"`
+	// Recipients contains the email addresses that receive the alert
+	Recipients []string `json:"recipients" bson:"recipients"`
+	// Channels contains the names of the notifiers that receive the alert
+	Channels []string `json:"channels" bson:"channels"`
+}
+
+// AlertRoutingMatch contains the conditions of a AlertRoutingRule.
+// Every non empty condition must be satisfied by at least one of its values
+type AlertRoutingMatch struct {
+	AlertCodes   []string `json:"alertCodes" bson:"alertCodes"`
+	Categories   []string `json:"categories" bson:"categories"`
+	Severities   []string `json:"severities" bson:"severities"`
+	Locations    []string `json:"locations" bson:"locations"`
+	Environments []string `json:"environments" bson:"environments"`
+	Tags         []string `json:"tags" bson:"tags"`
+	Technologies []string `json:"technologies" bson:"technologies"`
+}
+
+// AlertRoute contains the result of the evaluation of the routing rules on an alert
+type AlertRoute struct {
+	Rules      []AlertRoutingRule `json:"rules"`
+	Recipients []string           `json:"recipients"`
+	Channels   []string           `json:"channels"`
+}
+
+// Matches return true if the alert, raised on host, satisfies the conditions of the rule.
+// host can be nil when the alert isn't related to a host: in that case the rules that
+// have conditions on locations, environments or tags don't match
+func (rule AlertRoutingRule) Matches(alert Alert, host *HostDataBE) bool {
+	m := rule.Match
+
+	if !matchesAny(m.AlertCodes, alert.AlertCode) ||
+		!matchesAny(m.Categories, alert.AlertCategory) ||
+		!matchesAny(m.Severities, alert.AlertSeverity) {
+		return false
+	}
+
+	if len(m.Technologies) > 0 &&
+		(alert.AlertAffectedTechnology == nil || !utils.Contains(m.Technologies, *alert.AlertAffectedTechnology)) {
+		return false
+	}
+
+	if len(m.Locations) == 0 && len(m.Environments) == 0 && len(m.Tags) == 0 {
+		return true
+	}
+
+	if host == nil {
+		return false
+	}
+
+	if !matchesAny(m.Locations, host.Location) || !matchesAny(m.Environments, host.Environment) {
+		return false
+	}
+
+	if len(m.Tags) > 0 && !utils.ContainsSomeI(host.Tags, m.Tags...) {
+		return false
+	}
+
+	return true
+}
+
+func matchesAny(values []string, value string) bool {
+	return len(values) == 0 || utils.Contains(values, value)
+}
+
+// RouteAlert evaluates the enabled rules by priority and return the route of the alert
+func RouteAlert(rules []AlertRoutingRule, alert Alert, host *HostDataBE) AlertRoute {
+	sorted := make([]AlertRoutingRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	route := AlertRoute{
+		Rules:      make([]AlertRoutingRule, 0),
+		Recipients: make([]string, 0),
+		Channels:   make([]string, 0),
+	}
+
+	for _, rule := range sorted {
+		if !rule.Enabled || !rule.Matches(alert, host) {
+			continue
+		}
+
+		route.Rules = append(route.Rules, rule)
+		route.Recipients = appendMissing(route.Recipients, rule.Recipients...)
+		route.Channels = appendMissing(route.Channels, rule.Channels...)
+
+		if rule.StopProcessing {
+			break
+		}
+	}
+
+	return route
+}
+
+func appendMissing(slice []string, values ...string) []string {
+	for _, v := range values {
+		if !utils.Contains(slice, v) {
+			slice = append(slice, v)
+		}
+	}
+
+	return slice
+}
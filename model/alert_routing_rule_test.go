@@ -0,0 +1,124 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertRoutingRule_Matches(t *testing.T) {
+	alert := Alert{
+		AlertCategory:           AlertCategoryEngine,
+		AlertAffectedTechnology: TechnologyOracleDatabasePtr,
+		AlertCode:               AlertCodeMissingHostInCmdb,
+		AlertSeverity:           AlertSeverityWarning,
+		OtherInfo:               map[string]interface{}{"hostname": "foobar"},
+	}
+	host := &HostDataBE{
+		Hostname:    "foobar",
+		Location:    "Italy",
+		Environment: "PROD",
+		Tags:        []string{"Finance", "erp"},
+	}
+
+	testCases := []struct {
+		name     string
+		match    AlertRoutingMatch
+		host     *HostDataBE
+		expected bool
+	}{
+		{name: "empty match", match: AlertRoutingMatch{}, host: host, expected: true},
+		{name: "code", match: AlertRoutingMatch{AlertCodes: []string{AlertCodeDismissHost, AlertCodeMissingHostInCmdb}}, host: host, expected: true},
+		{name: "wrong code", match: AlertRoutingMatch{AlertCodes: []string{AlertCodeNoData}}, host: host, expected: false},
+		{name: "category and severity", match: AlertRoutingMatch{Categories: []string{AlertCategoryEngine}, Severities: []string{AlertSeverityWarning}}, host: host, expected: true},
+		{name: "wrong severity", match: AlertRoutingMatch{Severities: []string{AlertSeverityCritical}}, host: host, expected: false},
+		{name: "technology", match: AlertRoutingMatch{Technologies: []string{TechnologyOracleDatabase}}, host: host, expected: true},
+		{name: "wrong technology", match: AlertRoutingMatch{Technologies: []string{TechnologyOracleMySQL}}, host: host, expected: false},
+		{name: "location and environment", match: AlertRoutingMatch{Locations: []string{"Italy"}, Environments: []string{"PROD"}}, host: host, expected: true},
+		{name: "wrong environment", match: AlertRoutingMatch{Locations: []string{"Italy"}, Environments: []string{"TST"}}, host: host, expected: false},
+		{name: "tags case insensitive", match: AlertRoutingMatch{Tags: []string{"finance"}}, host: host, expected: true},
+		{name: "wrong tags", match: AlertRoutingMatch{Tags: []string{"hr"}}, host: host, expected: false},
+		{name: "host conditions without host", match: AlertRoutingMatch{Locations: []string{"Italy"}}, host: nil, expected: false},
+		{name: "no host conditions without host", match: AlertRoutingMatch{AlertCodes: []string{AlertCodeMissingHostInCmdb}}, host: nil, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := AlertRoutingRule{Enabled: true, Match: tc.match}
+			assert.Equal(t, tc.expected, rule.Matches(alert, tc.host))
+		})
+	}
+
+	t.Run("technology without affected technology", func(t *testing.T) {
+		rule := AlertRoutingRule{Match: AlertRoutingMatch{Technologies: []string{TechnologyOracleDatabase}}}
+		assert.False(t, rule.Matches(Alert{AlertCode: AlertCodeNoData}, host))
+	})
+}
+
+func TestRouteAlert(t *testing.T) {
+	alert := Alert{
+		AlertCategory: AlertCategoryAgent,
+		AlertCode:     AlertCodeNoData,
+		AlertSeverity: AlertSeverityCritical,
+	}
+	host := &HostDataBE{Location: "Italy"}
+
+	rules := []AlertRoutingRule{
+		{Name: "catch-all", Enabled: true, Priority: 100, Recipients: []string{"ops@ercole.test"}},
+		{Name: "disabled", Enabled: false, Priority: 0, Recipients: []string{"nobody@ercole.test"}},
+		{Name: "italy", Enabled: true, Priority: 10, Match: AlertRoutingMatch{Locations: []string{"Italy"}},
+			Recipients: []string{"italy@ercole.test", "ops@ercole.test"}, Channels: []string{"slack-italy"}},
+		{Name: "other code", Enabled: true, Priority: 1, Match: AlertRoutingMatch{AlertCodes: []string{AlertCodeNewDatabase}},
+			Recipients: []string{"dba@ercole.test"}},
+	}
+
+	t.Run("Collect all matching rules", func(t *testing.T) {
+		route := RouteAlert(rules, alert, host)
+
+		assert.Equal(t, []string{"italy", "catch-all"}, ruleNames(route.Rules))
+		assert.Equal(t, []string{"italy@ercole.test", "ops@ercole.test"}, route.Recipients)
+		assert.Equal(t, []string{"slack-italy"}, route.Channels)
+	})
+
+	t.Run("Stop processing", func(t *testing.T) {
+		stopping := make([]AlertRoutingRule, len(rules))
+		copy(stopping, rules)
+		stopping[2].StopProcessing = true
+
+		route := RouteAlert(stopping, alert, host)
+
+		assert.Equal(t, []string{"italy"}, ruleNames(route.Rules))
+	})
+
+	t.Run("No matching rules", func(t *testing.T) {
+		route := RouteAlert(rules[1:2], alert, host)
+
+		assert.Empty(t, route.Rules)
+		assert.Empty(t, route.Recipients)
+		assert.Empty(t, route.Channels)
+	})
+}
+
+func ruleNames(rules []AlertRoutingRule) []string {
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlert_IdentityKeys(t *testing.T) {
+	testCases := []struct {
+		code     string
+		expected []string
+	}{
+		{code: AlertCodeNoData, expected: nil},
+		{code: AlertCodeNewLicense, expected: []string{"licenseTypeID"}},
+		{code: AlertCodeLicenseNonCompliant, expected: []string{"licenseTypeID", "location"}},
+		{code: AlertCodeContractSupportExpired, expected: []string{"contractID", "leadTime"}},
+		{code: AlertCodeClusterTopologyMismatch, expected: []string{"kind", "location"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Alert{AlertCode: tc.code}.IdentityKeys())
+		})
+	}
+}
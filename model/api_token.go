@@ -0,0 +1,64 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APITokenPrefix is the prefix of the API tokens, that distinguish them from the JWT bearer tokens
+const APITokenPrefix = "ercpat_"
+
+// APIToken holds a long-lived token used by scripts and service accounts to call the API.
+// Only the hash of the token is stored, the token itself is returned once when it's created
+type APIToken struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	Name     string             `json:"name" bson:"name"`
+	Username string             `json:"username" bson:"username"`
+	Hash     string             `json:"-" bson:"hash"`
+	// Hint contains the first characters of the token, to recognize it in the list
+	Hint string `json:"hint" bson:"hint"`
+	// Locations restricts the locations visible with the token to a subset of the ones of the owner
+	Locations []string `json:"locations" bson:"locations"`
+	// Permission is read or write, the write permission must be granted also by the roles of the owner
+	Permission string     `json:"permission" bson:"permission"`
+	ExpiresAt  time.Time  `json:"expiresAt" bson:"expiresAt"`
+	CreatedAt  time.Time  `json:"createdAt" bson:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt" bson:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt" bson:"revokedAt"`
+}
+
+// IsValid return true if the token isn't revoked nor expired
+func (t APIToken) IsValid(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// IsLocationScoped return true if the token doesn't give access to all the locations of the owner
+func (t APIToken) IsLocationScoped() bool {
+	if len(t.Locations) == 0 {
+		return false
+	}
+
+	for _, location := range t.Locations {
+		if location == AllLocation {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Services whose requests are recorded in the audit log
+const (
+	AuditServiceAPI     = "api-service"
+	AuditServiceChart   = "chart-service"
+	AuditServiceThunder = "thunder-service"
+)
+
+// AuditEntry is the record of a request that modified data, kept in the append-only audit log
+type AuditEntry struct {
+	ID   primitive.ObjectID `json:"id" bson:"_id"`
+	Date time.Time          `json:"date" bson:"date"`
+	// Service is the service that received the request
+	Service  string `json:"service" bson:"service"`
+	Username string `json:"username" bson:"username"`
+	// AuthProvider is the type of the authentication provider used by the user
+	AuthProvider string `json:"authProvider" bson:"authProvider"`
+	// APITokenID is the id of the API token used to authenticate, if any
+	APITokenID *primitive.ObjectID `json:"apiTokenID,omitempty" bson:"apiTokenID,omitempty"`
+	Method     string              `json:"method" bson:"method"`
+	// Route is the template of the path, like /hosts/{hostname}
+	Route string `json:"route" bson:"route"`
+	Path  string `json:"path" bson:"path"`
+	// Target contains the variables of the route, that identify the modified entity
+	Target   map[string]string `json:"target" bson:"target"`
+	Location string            `json:"location,omitempty" bson:"location,omitempty"`
+	Status   int               `json:"status" bson:"status"`
+	// Before and After are the state of the modified entity, when it can be loaded,
+	// otherwise After is the body of the request. The secrets are redacted
+	Before  json.RawMessage `json:"before" bson:"before"`
+	After   json.RawMessage `json:"after" bson:"after"`
+	Changes []AuditChange   `json:"changes" bson:"changes"`
+}
+
+// AuditChange is a field of the modified entity whose value has changed
+type AuditChange struct {
+	// Field is the path of the field, like contract.hosts
+	Field  string          `json:"field" bson:"field"`
+	Before json.RawMessage `json:"before" bson:"before"`
+	After  json.RawMessage `json:"after" bson:"after"`
+}
+
+// DiffAuditSnapshots return the fields that differ between the two JSON documents, sorted by field.
+// Nested objects are compared field by field, arrays as a whole
+func DiffAuditSnapshots(before, after json.RawMessage) ([]AuditChange, error) {
+	beforeFields, err := flattenAuditSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := flattenAuditSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(beforeFields)+len(afterFields))
+
+	for field := range beforeFields {
+		fields = append(fields, field)
+	}
+
+	for field := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+
+	changes := make([]AuditChange, 0)
+
+	for _, field := range fields {
+		beforeValue, beforeOk := beforeFields[field]
+		afterValue, afterOk := afterFields[field]
+
+		if beforeOk == afterOk && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+
+		change := AuditChange{Field: field}
+
+		if beforeOk {
+			if change.Before, err = json.Marshal(beforeValue); err != nil {
+				return nil, err
+			}
+		}
+
+		if afterOk {
+			if change.After, err = json.Marshal(afterValue); err != nil {
+				return nil, err
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func flattenAuditSnapshot(snapshot json.RawMessage) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+
+	if len(snapshot) == 0 {
+		return fields, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(snapshot, &value); err != nil {
+		return nil, err
+	}
+
+	if value != nil {
+		flattenAuditValue("", value, fields)
+	}
+
+	return fields, nil
+}
+
+func flattenAuditValue(prefix string, value interface{}, fields map[string]interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok || (len(object) == 0 && prefix != "") {
+		fields[prefix] = value
+		return
+	}
+
+	for key, nested := range object {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+
+		flattenAuditValue(field, nested, fields)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAuditSnapshots(t *testing.T) {
+	testCases := []struct {
+		name     string
+		before   string
+		after    string
+		expected []AuditChange
+	}{
+		{
+			name:     "Unchanged",
+			before:   `{"id":"a1","hosts":["h1","h2"],"basket":false}`,
+			after:    `{"basket":false,"hosts":["h1","h2"],"id":"a1"}`,
+			expected: []AuditChange{},
+		},
+		{
+			name:   "Modified fields",
+			before: `{"id":"a1","hosts":["h1"],"license":{"ignored":false,"comment":""}}`,
+			after:  `{"id":"a1","hosts":["h1","h2"],"license":{"ignored":true,"comment":"test db"}}`,
+			expected: []AuditChange{
+				{Field: "hosts", Before: json.RawMessage(`["h1"]`), After: json.RawMessage(`["h1","h2"]`)},
+				{Field: "license.comment", Before: json.RawMessage(`""`), After: json.RawMessage(`"test db"`)},
+				{Field: "license.ignored", Before: json.RawMessage(`false`), After: json.RawMessage(`true`)},
+			},
+		},
+		{
+			name:  "Created",
+			after: `{"name":"Italy","tags":{}}`,
+			expected: []AuditChange{
+				{Field: "name", After: json.RawMessage(`"Italy"`)},
+				{Field: "tags", After: json.RawMessage(`{}`)},
+			},
+		},
+		{
+			name:   "Deleted",
+			before: `{"hostname":"foobar","dismissedAt":null}`,
+			after:  `null`,
+			expected: []AuditChange{
+				{Field: "dismissedAt", Before: json.RawMessage(`null`)},
+				{Field: "hostname", Before: json.RawMessage(`"foobar"`)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var before, after json.RawMessage
+			if tc.before != "" {
+				before = json.RawMessage(tc.before)
+			}
+
+			if tc.after != "" {
+				after = json.RawMessage(tc.after)
+			}
+
+			actual, err := DiffAuditSnapshots(before, after)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("Invalid snapshot", func(t *testing.T) {
+		_, err := DiffAuditSnapshots(json.RawMessage(`{`), nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AzureRecommendation struct {
+	SeqValue    uint64                 `json:"seqValue" bson:"seqValue"`
+	ProfileID   primitive.ObjectID     `json:"profileID" bson:"profileID"`
+	ProfileName string                 `json:"profileName" bson:"profileName"`
+	Category    string                 `json:"category" bson:"category"`
+	Suggestion  string                 `json:"suggestion" bson:"suggestion"`
+	Name        string                 `json:"name" bson:"name"`
+	ResourceID  string                 `json:"resourceID" bson:"resourceID"`
+	ObjectType  string                 `json:"objectType" bson:"objectType"`
+	Details     map[string]interface{} `json:"details" bson:"details"`
+	CreatedAt   time.Time              `json:"createdAt" bson:"createdAt"`
+}
+
+type AzureRecommendationError struct {
+	SeqValue    uint64             `json:"seqValue" bson:"seqValue"`
+	ProfileID   primitive.ObjectID `json:"profileID" bson:"profileID"`
+	ProfileName string             `json:"profileName" bson:"profileName"`
+	Category    string             `json:"category" bson:"category"`
+	Error       string             `json:"error" bson:"error"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+const (
+	AzureUnusedResource               = "Unused Resource"
+	AzureNotActiveResource            = "Not Active Resource"
+	AzureComputeInstanceRightsizing   = "Compute Instance Rightsizing"
+	AzureAuthentication               = "Authentication"
+	AzureManagedDisk                  = "Managed Disk"
+	AzurePublicIP                     = "Public IP"
+	AzureVirtualMachine               = "Virtual Machine"
+	AzureDeleteManagedDiskNotAttached = "Delete managed disk not attached"
+	AzureDeletePublicIPNotAssociated  = "Delete public IP address not associated"
+	AzureDeallocateStoppedVM          = "Deallocate stopped virtual machine"
+	AzureResizeOversizedVM            = "Resize oversized virtual machine"
+)
@@ -15,6 +15,8 @@
 
 package model
 
+import "math"
+
 type Cloud struct {
 	Membership string `json:"membership" bson:"membership"`
 }
@@ -23,4 +25,74 @@ const (
 	CloudMembershipUnknown string = ""     // It's unknown if host is in a cloud
 	CloudMembershipNone    string = "None" // Host isn't in a known cloud
 	CloudMembershipAws     string = "AWS"
+	CloudMembershipAzure   string = "Azure"
+	CloudMembershipGcp     string = "GCP"
+	CloudMembershipOci     string = "OCI"
 )
+
+// CloudLicensingPolicy describes how Oracle counts the licenses of a database running in an authorized cloud environment
+type CloudLicensingPolicy struct {
+	// CountOCPUs count the physical cores (OCPUs) of the instance instead of its vCPUs
+	CountOCPUs bool
+	// VCPUsPerProcessor is the number of vCPUs counted as one Processor license when hyperthreading is enabled
+	VCPUsPerProcessor int
+	// UnitsPerSocket is the number of vCPUs, or OCPUs, counted as one socket by Standard Edition
+	UnitsPerSocket int
+}
+
+// CloudLicensingPolicies contains the licensing policy of every authorized cloud environment
+var CloudLicensingPolicies = map[string]CloudLicensingPolicy{
+	CloudMembershipAws:   {VCPUsPerProcessor: 2, UnitsPerSocket: 4},
+	CloudMembershipAzure: {VCPUsPerProcessor: 2, UnitsPerSocket: 4},
+	CloudMembershipGcp:   {VCPUsPerProcessor: 2, UnitsPerSocket: 4},
+	CloudMembershipOci:   {CountOCPUs: true, UnitsPerSocket: 4},
+}
+
+// IsAuthorizedCloud returns true if the membership is an authorized cloud environment
+func IsAuthorizedCloud(membership string) bool {
+	_, ok := CloudLicensingPolicies[membership]
+	return ok
+}
+
+func (p CloudLicensingPolicy) units(host Host) int {
+	if p.CountOCPUs || host.CPUThreads == 0 {
+		return host.CPUCores
+	}
+
+	return host.CPUThreads
+}
+
+// ProcessorLicenses returns the Processor licenses needed by an Enterprise or Extreme Edition database on the instance
+func (p CloudLicensingPolicy) ProcessorLicenses(host Host) float64 {
+	units := p.units(host)
+
+	hyperthreading := host.ThreadsPerCore > 1 || host.CPUThreads > host.CPUCores
+	if p.CountOCPUs || !hyperthreading {
+		return float64(units)
+	}
+
+	return math.Ceil(float64(units) / float64(p.VCPUsPerProcessor))
+}
+
+// StandardEditionSockets returns the sockets counted for a Standard Edition database on the instance
+func (p CloudLicensingPolicy) StandardEditionSockets(host Host) float64 {
+	return math.Ceil(float64(p.units(host)) / float64(p.UnitsPerSocket))
+}
+
+// CloudLicenses returns the licenses needed by a database of the given edition on an authorized cloud instance.
+// It returns false if the host isn't in an authorized cloud
+func (v *HostDataBE) CloudLicenses(edition string) (float64, bool) {
+	policy, ok := CloudLicensingPolicies[v.Cloud.Membership]
+	if !ok {
+		return 0, false
+	}
+
+	switch edition {
+	case OracleDatabaseEditionEnterprise, OracleDatabaseEditionExtreme:
+		return policy.ProcessorLicenses(v.Info), true
+	case OracleDatabaseEditionStandard:
+		return policy.StandardEditionSockets(v.Info), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudLicenses(t *testing.T) {
+	testCases := []struct {
+		name       string
+		membership string
+		info       Host
+		edition    string
+		expected   float64
+		ok         bool
+	}{
+		{
+			name:       "on premise",
+			membership: CloudMembershipNone,
+			info:       Host{CPUCores: 4, CPUThreads: 8, ThreadsPerCore: 2},
+			edition:    OracleDatabaseEditionEnterprise,
+		},
+		{
+			name:       "aws with hyperthreading",
+			membership: CloudMembershipAws,
+			info:       Host{CPUCores: 4, CPUThreads: 8, ThreadsPerCore: 2},
+			edition:    OracleDatabaseEditionEnterprise,
+			expected:   4,
+			ok:         true,
+		},
+		{
+			name:       "azure without hyperthreading",
+			membership: CloudMembershipAzure,
+			info:       Host{CPUCores: 4, CPUThreads: 4, ThreadsPerCore: 1},
+			edition:    OracleDatabaseEditionExtreme,
+			expected:   4,
+			ok:         true,
+		},
+		{
+			name:       "gcp odd vcpus",
+			membership: CloudMembershipGcp,
+			info:       Host{CPUCores: 3, CPUThreads: 6, ThreadsPerCore: 2},
+			edition:    OracleDatabaseEditionEnterprise,
+			expected:   3,
+			ok:         true,
+		},
+		{
+			name:       "oci counts ocpus",
+			membership: CloudMembershipOci,
+			info:       Host{CPUCores: 2, CPUThreads: 4, ThreadsPerCore: 2},
+			edition:    OracleDatabaseEditionEnterprise,
+			expected:   2,
+			ok:         true,
+		},
+		{
+			name:       "standard edition up to four vcpus is one socket",
+			membership: CloudMembershipAws,
+			info:       Host{CPUCores: 2, CPUThreads: 4, ThreadsPerCore: 2},
+			edition:    OracleDatabaseEditionStandard,
+			expected:   1,
+			ok:         true,
+		},
+		{
+			name:       "standard edition rounds sockets up",
+			membership: CloudMembershipAzure,
+			info:       Host{CPUCores: 3, CPUThreads: 6, ThreadsPerCore: 2},
+			edition:    OracleDatabaseEditionStandard,
+			expected:   2,
+			ok:         true,
+		},
+		{
+			name:       "express edition",
+			membership: CloudMembershipAws,
+			info:       Host{CPUCores: 2, CPUThreads: 4, ThreadsPerCore: 2},
+			edition:    OracleDatabaseEditionExpress,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hostdata := HostDataBE{Info: tc.info, Cloud: Cloud{Membership: tc.membership}}
+
+			actual, ok := hostdata.CloudLicenses(tc.edition)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2022 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContractSupport holds the support expiration of a contract of any technology
+type ContractSupport struct {
+	ID                primitive.ObjectID `json:"id" bson:"_id"`
+	Technology        string             `json:"technology" bson:"technology"`
+	ContractID        string             `json:"contractID" bson:"contractID"`
+	Location          string             `json:"location" bson:"location"`
+	SupportExpiration time.Time          `json:"supportExpiration" bson:"supportExpiration"`
+}
+
+// DaysLeft return the number of days, rounded up, until the support of the contract expires.
+// It's zero or negative if the support is already expired
+func (c ContractSupport) DaysLeft(now time.Time) int {
+	return int(math.Ceil(c.SupportExpiration.Sub(now).Hours() / 24))
+}
+
+// SupportLeadTime return the smallest lead time, in days, that includes the expiration of the support.
+// It return false if the expiration is farther than every lead time or if the support is already expired
+func (c ContractSupport) SupportLeadTime(now time.Time, leadTimes []int) (int, bool) {
+	daysLeft := c.DaysLeft(now)
+	if daysLeft <= 0 {
+		return 0, false
+	}
+
+	sorted := make([]int, len(leadTimes))
+	copy(sorted, leadTimes)
+	sort.Ints(sorted)
+
+	for _, leadTime := range sorted {
+		if daysLeft <= leadTime {
+			return leadTime, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2022 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestContractSupport_SupportLeadTime(t *testing.T) {
+	now := utils.P("2024-03-01T10:00:00Z")
+	leadTimes := []int{90, 7, 30}
+
+	testCases := []struct {
+		name       string
+		expiration string
+		daysLeft   int
+		leadTime   int
+		ok         bool
+	}{
+		{name: "far away", expiration: "2024-12-31T00:00:00Z", daysLeft: 305, ok: false},
+		{name: "within 90 days", expiration: "2024-05-01T00:00:00Z", daysLeft: 61, leadTime: 90, ok: true},
+		{name: "exactly 30 days", expiration: "2024-03-31T10:00:00Z", daysLeft: 30, leadTime: 30, ok: true},
+		{name: "within 7 days", expiration: "2024-03-02T00:00:00Z", daysLeft: 1, leadTime: 7, ok: true},
+		{name: "expired", expiration: "2024-02-01T00:00:00Z", daysLeft: -29, ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			contract := ContractSupport{SupportExpiration: utils.P(tc.expiration)}
+
+			assert.Equal(t, tc.daysLeft, contract.DaysLeft(now))
+
+			leadTime, ok := contract.SupportLeadTime(now, leadTimes)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.leadTime, leadTime)
+		})
+	}
+}
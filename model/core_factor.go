@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/ercole-io/ercole/v2/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CoreFactor is a row of the Oracle Processor Core Factor Table.
+// It matches a host by its cloud membership or by a case insensitive regex on its cpu model
+type CoreFactor struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	Description     string             `json:"description" bson:"description"`
+	CPUModelRegex   string             `json:"cpuModelRegex" bson:"cpuModelRegex"`
+	CloudMembership string             `json:"cloudMembership" bson:"cloudMembership"`
+	Factor          float64            `json:"factor" bson:"factor"`
+	Priority        int                `json:"priority" bson:"priority"`
+}
+
+// Validate check that the core factor matches exactly one between a cloud or a cpu model
+func (cf CoreFactor) Validate() error {
+	if (cf.CPUModelRegex == "") == (cf.CloudMembership == "") {
+		return utils.NewErrorf("%w: exactly one of cpuModelRegex and cloudMembership must be set", utils.ErrInvalidCoreFactor)
+	}
+
+	if cf.Factor <= 0 {
+		return utils.NewErrorf("%w: factor must be greater than zero", utils.ErrInvalidCoreFactor)
+	}
+
+	if _, err := cf.regexp(); err != nil {
+		return utils.NewErrorf("%w: %s", utils.ErrInvalidCoreFactor, err)
+	}
+
+	return nil
+}
+
+func (cf CoreFactor) regexp() (*regexp.Regexp, error) {
+	if cf.CPUModelRegex == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile("(?i)" + cf.CPUModelRegex)
+}
+
+type coreFactorRow struct {
+	CoreFactor
+	re *regexp.Regexp
+}
+
+// CoreFactorTable looks up the core factor of hosts.
+// Cloud rules come first, cpu model rules follow by ascending priority
+type CoreFactorTable struct {
+	rows []coreFactorRow
+}
+
+func NewCoreFactorTable(coreFactors []CoreFactor) (*CoreFactorTable, error) {
+	rows := make([]coreFactorRow, 0, len(coreFactors))
+
+	for _, cf := range coreFactors {
+		re, err := cf.regexp()
+		if err != nil {
+			return nil, utils.NewErrorf("%w: %s", utils.ErrInvalidCoreFactor, err)
+		}
+
+		rows = append(rows, coreFactorRow{CoreFactor: cf, re: re})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		iCloud, jCloud := rows[i].CloudMembership != "", rows[j].CloudMembership != ""
+		if iCloud != jCloud {
+			return iCloud
+		}
+
+		return rows[i].Priority < rows[j].Priority
+	})
+
+	return &CoreFactorTable{rows: rows}, nil
+}
+
+// Lookup returns the first row of the table matching the host
+func (t *CoreFactorTable) Lookup(host *HostDataBE) (CoreFactor, bool) {
+	if t == nil {
+		return CoreFactor{}, false
+	}
+
+	for _, row := range t.rows {
+		if row.CloudMembership != "" && row.CloudMembership == host.Cloud.Membership {
+			return row.CoreFactor, true
+		}
+
+		if row.re != nil && row.re.MatchString(host.Info.CPUModel) {
+			return row.CoreFactor, true
+		}
+	}
+
+	return CoreFactor{}, false
+}
+
+// Factor returns the core factor of the host, falling back to HostDataBE.CoreFactor if no row matches
+func (t *CoreFactorTable) Factor(host *HostDataBE) float64 {
+	if cf, ok := t.Lookup(host); ok {
+		return cf.Factor
+	}
+
+	return host.CoreFactor()
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoreFactor_Validate(t *testing.T) {
+	testCases := []struct {
+		coreFactor CoreFactor
+		valid      bool
+	}{
+		{CoreFactor{CPUModelRegex: "POWER[0-9]", Factor: 1}, true},
+		{CoreFactor{CloudMembership: CloudMembershipAzure, Factor: 1}, true},
+		{CoreFactor{Factor: 1}, false},
+		{CoreFactor{CPUModelRegex: "Xeon", CloudMembership: CloudMembershipAws, Factor: 1}, false},
+		{CoreFactor{CPUModelRegex: "Xeon", Factor: 0}, false},
+		{CoreFactor{CPUModelRegex: "Xeon(", Factor: 0.5}, false},
+	}
+
+	for _, tc := range testCases {
+		err := tc.coreFactor.Validate()
+		if tc.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, utils.ErrInvalidCoreFactor)
+		}
+	}
+}
+
+func TestCoreFactorTable_Factor(t *testing.T) {
+	table, err := NewCoreFactorTable([]CoreFactor{
+		{Description: "Intel", CPUModelRegex: "Xeon", Factor: 0.5, Priority: 20},
+		{Description: "SPARC64 VI/VII", CPUModelRegex: "SPARC64[- ]?VII?\\b", Factor: 0.75, Priority: 10},
+		{Description: "SPARC", CPUModelRegex: "SPARC", Factor: 0.5, Priority: 20},
+		{Description: "IBM POWER", CPUModelRegex: "POWER[0-9]", Factor: 1, Priority: 10},
+		{Description: "Azure", CloudMembership: CloudMembershipAzure, Factor: 1},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		host     HostDataBE
+		expected float64
+	}{
+		{HostDataBE{Info: Host{CPUModel: "Intel(R) Xeon(R) Gold 6248R CPU @ 3.00GHz"}}, 0.5},
+		{HostDataBE{Info: Host{CPUModel: "PowerPC_POWER9"}}, 1},
+		{HostDataBE{Info: Host{CPUModel: "SPARC64-VII+"}}, 0.75},
+		{HostDataBE{Info: Host{CPUModel: "SPARC-M8"}}, 0.5},
+		{HostDataBE{Info: Host{CPUModel: "Intel(R) Xeon(R) Platinum 8272CL"}, Cloud: Cloud{Membership: CloudMembershipAzure}}, 1},
+		{HostDataBE{Info: Host{CPUModel: "Intel(R) Itanium(R) Processor 9560"}}, 0.5},
+		{HostDataBE{Info: Host{CPUModel: "Intel(R) Itanium(R) Processor 9560"}, Cloud: Cloud{Membership: CloudMembershipAws}}, 1},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, table.Factor(&tc.host), tc.host.Info.CPUModel)
+	}
+
+	var nilTable *CoreFactorTable
+	assert.Equal(t, 0.5, nilTable.Factor(&HostDataBE{}))
+}
@@ -22,6 +22,6 @@ type GcpProfile struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id"`
 	Name        string             `json:"name" bson:"name"`
 	Selected    bool               `json:"selected" bson:"selected"`
-	PrivateKey  string             `json:"privatekey" bson:"privatekey"`
+	PrivateKey  string             `json:"privatekey,omitempty" bson:"privatekey"`
 	ClientEmail string             `json:"clientemail" bson:"clientemail"`
 }
@@ -74,8 +74,9 @@ func (v *HostDataBE) GetClusterCores(hostdatasPerHostname map[string]*HostDataBE
 	return sumClusterCores, nil
 }
 
+// CoreFactor returns the default core factor of the host, used when no row of the core factor table matches it
 func (v *HostDataBE) CoreFactor() float64 {
-	if v.Cloud.Membership == CloudMembershipAws {
+	if IsAuthorizedCloud(v.Cloud.Membership) {
 		return 1
 	}
 
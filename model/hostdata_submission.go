@@ -0,0 +1,49 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Statuses of a hostdata submission
+const (
+	// HostDataSubmissionStatusQueued contains the status of a submission waiting to be processed
+	HostDataSubmissionStatusQueued = "QUEUED"
+	// HostDataSubmissionStatusProcessing contains the status of a submission taken by a worker
+	HostDataSubmissionStatusProcessing = "PROCESSING"
+	// HostDataSubmissionStatusDone contains the status of a submission whose hostdata was inserted
+	HostDataSubmissionStatusDone = "DONE"
+	// HostDataSubmissionStatusFailed contains the status of a submission moved to the dead letters
+	HostDataSubmissionStatusFailed = "FAILED"
+)
+
+// HostDataSubmission holds an hostdata received from an agent and queued to be inserted
+type HostDataSubmission struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id"`
+	Hostname      string             `json:"hostname" bson:"hostname"`
+	Status        string             `json:"status" bson:"status"`
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	LastError     string             `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	SubmittedAt   time.Time          `json:"submittedAt" bson:"submittedAt"`
+	NextAttemptAt time.Time          `json:"nextAttemptAt" bson:"nextAttemptAt"`
+	ProcessedAt   *time.Time         `json:"processedAt,omitempty" bson:"processedAt,omitempty"`
+	// HostDataID contains the id of the hostdata, set when it has been checked and its alerts thrown
+	HostDataID *primitive.ObjectID `json:"hostDataID,omitempty" bson:"hostDataID,omitempty"`
+	HostData   *HostDataBE         `json:"-" bson:"hostData,omitempty"`
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// MaintenanceWindow holds a period during which the alerts of the hosts it covers
+// are recorded but not notified
+type MaintenanceWindow struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Start       time.Time          `json:"start" bson:"start"`
+	End         time.Time          `json:"end" bson:"end"`
+	Hostnames   []string           `json:"hostnames" bson:"hostnames"`
+	Locations   []string           `json:"locations" bson:"locations"`
+	Tags        []string           `json:"tags" bson:"tags"`
+}
+
+// IsActive return true if t is between the start and the end of the window
+func (mw MaintenanceWindow) IsActive(t time.Time) bool {
+	return !t.Before(mw.Start) && t.Before(mw.End)
+}
+
+// Covers return true if the alert, raised on host, belongs to one of the hosts,
+// locations or tags of the window. host can be nil when it isn't known
+func (mw MaintenanceWindow) Covers(alert Alert, host *HostDataBE) bool {
+	if hostname := alert.Hostname(); hostname != "" && utils.Contains(mw.Hostnames, hostname) {
+		return true
+	}
+
+	if host == nil {
+		return false
+	}
+
+	if utils.Contains(mw.Hostnames, host.Hostname) || utils.Contains(mw.Locations, host.Location) {
+		return true
+	}
+
+	return len(mw.Tags) > 0 && utils.ContainsSomeI(host.Tags, mw.Tags...)
+}
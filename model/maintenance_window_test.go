@@ -0,0 +1,68 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestMaintenanceWindow_IsActive(t *testing.T) {
+	mw := MaintenanceWindow{
+		Start: utils.P("2024-03-01T00:00:00Z"),
+		End:   utils.P("2024-03-02T00:00:00Z"),
+	}
+
+	assert.False(t, mw.IsActive(utils.P("2024-02-29T23:59:59Z")))
+	assert.True(t, mw.IsActive(utils.P("2024-03-01T00:00:00Z")))
+	assert.True(t, mw.IsActive(utils.P("2024-03-01T12:00:00Z")))
+	assert.False(t, mw.IsActive(utils.P("2024-03-02T00:00:00Z")))
+}
+
+func TestMaintenanceWindow_Covers(t *testing.T) {
+	alert := Alert{
+		AlertCode: AlertCodeNoData,
+		OtherInfo: map[string]interface{}{"hostname": "foobar"},
+	}
+	host := &HostDataBE{
+		Hostname: "foobar",
+		Location: "Italy",
+		Tags:     []string{"Finance"},
+	}
+
+	testCases := []struct {
+		name     string
+		window   MaintenanceWindow
+		host     *HostDataBE
+		expected bool
+	}{
+		{name: "hostname", window: MaintenanceWindow{Hostnames: []string{"foobar"}}, host: nil, expected: true},
+		{name: "other hostname", window: MaintenanceWindow{Hostnames: []string{"barfoo"}}, host: host, expected: false},
+		{name: "location", window: MaintenanceWindow{Locations: []string{"Italy"}}, host: host, expected: true},
+		{name: "location without host", window: MaintenanceWindow{Locations: []string{"Italy"}}, host: nil, expected: false},
+		{name: "tag case insensitive", window: MaintenanceWindow{Tags: []string{"finance"}}, host: host, expected: true},
+		{name: "other tag", window: MaintenanceWindow{Tags: []string{"hr"}}, host: host, expected: false},
+		{name: "empty window", window: MaintenanceWindow{}, host: host, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.window.Covers(alert, tc.host))
+		})
+	}
+}
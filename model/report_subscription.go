@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reports that can be subscribed
+const (
+	ReportHosts                       = "hosts"
+	ReportUsedLicensesPerHost         = "used-licenses-per-host"
+	ReportUsedLicensesPerCluster      = "used-licenses-per-cluster"
+	ReportLicensesCompliance          = "licenses-compliance"
+	ReportExadata                     = "exadata"
+	ReportOracleDatabasePoliciesAudit = "oracle-database-policies-audit"
+	ReportAwsRecommendations          = "aws-recommendations"
+	ReportOciRecommendations          = "oci-recommendations"
+)
+
+// Formats in which the reports are delivered
+const (
+	ReportFormatXLSX = "XLSX"
+	ReportFormatCSV  = "CSV"
+	ReportFormatPDF  = "PDF"
+)
+
+// Triggers of a ReportRun
+const (
+	ReportRunTriggerSchedule = "schedule"
+	ReportRunTriggerManual   = "manual"
+)
+
+// ReportSubscription holds a report that is periodically exported and sent by email to its recipients
+type ReportSubscription struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	Name    string             `json:"name" bson:"name"`
+	Report  string             `json:"report" bson:"report"`
+	Filters ReportFilters      `json:"filters" bson:"filters"`
+	// Schedule contains the crontab of the subscription, descriptors like @daily are accepted
+	Schedule   string   `json:"schedule" bson:"schedule"`
+	Format     string   `json:"format" bson:"format"`
+	Recipients []string `json:"recipients" bson:"recipients"`
+	Enabled    bool     `json:"enabled" bson:"enabled"`
+	// CreatedAt is the time from which the schedule is evaluated until the first run
+	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
+	LastRun   *time.Time `json:"lastRun" bson:"lastRun"`
+}
+
+// ReportFilters contains the filters applied to the report, when supported
+type ReportFilters struct {
+	Location    string `json:"location" bson:"location"`
+	Environment string `json:"environment" bson:"environment"`
+}
+
+// ReportRun contains the result of a delivery of a ReportSubscription
+type ReportRun struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	SubscriptionID primitive.ObjectID `json:"subscriptionID" bson:"subscriptionID"`
+	Date           time.Time          `json:"date" bson:"date"`
+	Trigger        string             `json:"trigger" bson:"trigger"`
+	Success        bool               `json:"success" bson:"success"`
+	Error          string             `json:"error" bson:"error"`
+	Recipients     []string           `json:"recipients" bson:"recipients"`
+	Attachments    []string           `json:"attachments" bson:"attachments"`
+}
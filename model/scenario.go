@@ -25,8 +25,12 @@ type Scenario struct {
 	Name      string             `bson:"name"`
 	CreatedAt time.Time          `bson:"createdAt"`
 
-	Location string          `bson:"location"`
-	Hosts    []SimulatedHost `bson:"hosts"`
+	Location           string              `bson:"location"`
+	Hosts              []SimulatedHost     `bson:"hosts"`
+	HypotheticalHosts  []HypotheticalHost  `bson:"hypotheticalHosts"`
+	RemovedHosts       []string            `bson:"removedHosts"`
+	MovedHosts         []MovedHost         `bson:"movedHosts"`
+	LicenseTypeChanges []LicenseTypeChange `bson:"licenseTypeChanges"`
 
 	LicenseCompliance LicensesComplianceScenario `bson:"licenseCompliance"`
 	LicenseUsed       LicenseUsedScenario        `bson:"licenseUsed"`
@@ -39,6 +43,28 @@ type SimulatedHost struct {
 	Core      int                `bson:"core"`
 }
 
+// HypotheticalHost is a host that doesn't exist yet, added by a scenario
+type HypotheticalHost struct {
+	Host    HostDataBE `bson:"host"`
+	Cluster string     `bson:"cluster"`
+}
+
+// MovedHost is an existing host moved by a scenario into another hypervisor cluster
+type MovedHost struct {
+	Hostname    string `bson:"hostname"`
+	FromCluster string `bson:"fromCluster"`
+	ToCluster   string `bson:"toCluster"`
+}
+
+// LicenseTypeChange replaces a license type of the databases of a host.
+// An empty DbName applies the change to every database of the host
+type LicenseTypeChange struct {
+	Hostname          string `bson:"hostname"`
+	DbName            string `bson:"dbName"`
+	FromLicenseTypeID string `bson:"fromLicenseTypeID"`
+	ToLicenseTypeID   string `bson:"toLicenseTypeID"`
+}
+
 type LicensesComplianceScenario struct {
 	Actual []LicenseCompliance `bson:"actual"`
 	Got    []LicenseCompliance `bson:"got"`
@@ -31,6 +31,9 @@ type User struct {
 	FirstName string     `json:"firstName,omitempty" bson:"firstName"`
 	LastName  string     `json:"lastName,omitempty" bson:"lastName"`
 	Groups    []string   `json:"groups" bson:"groups"`
+	// ServiceAccount is true for the users used by automations, which haven't a password
+	// and authenticate only with their API tokens
+	ServiceAccount bool `json:"serviceAccount,omitempty" bson:"serviceAccount,omitempty"`
 }
 
 func (u *User) IsGroup(group string) bool {
@@ -0,0 +1,45 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestLoadAlertRoutingRuleSchema(t *testing.T) {
+	_, err := loadAlertRoutingRuleSchema()
+	assert.Nil(t, err)
+}
+
+func TestValidateAlertRoutingRule(t *testing.T) {
+	valid := `{"name": "dba", "enabled": true, "priority": 10, "stopProcessing": false,
+		"match": {"alertCodes": ["NEW_DATABASE"], "severities": ["CRITICAL"], "locations": null},
+		"recipients": ["dba@ercole.test"], "channels": ["slack-dba"]}`
+	assert.NoError(t, ValidateAlertRoutingRule([]byte(valid)))
+
+	invalidSeverity := `{"name": "dba", "enabled": true, "priority": 10, "match": {"severities": ["BAD"]}}`
+	assert.ErrorIs(t, ValidateAlertRoutingRule([]byte(invalidSeverity)), utils.ErrInvalidAlertRoutingRule)
+
+	invalidRecipient := `{"name": "dba", "enabled": true, "priority": 10, "match": {}, "recipients": ["dba"]}`
+	assert.ErrorIs(t, ValidateAlertRoutingRule([]byte(invalidRecipient)), utils.ErrInvalidAlertRoutingRule)
+
+	missingName := `{"enabled": true, "priority": 10, "match": {}}`
+	assert.ErrorIs(t, ValidateAlertRoutingRule([]byte(missingName)), utils.ErrInvalidAlertRoutingRule)
+}
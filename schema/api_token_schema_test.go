@@ -0,0 +1,47 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestLoadAPITokenSchema(t *testing.T) {
+	_, err := loadAPITokenSchema()
+	assert.Nil(t, err)
+}
+
+func TestValidateAPIToken(t *testing.T) {
+	valid := `{"name": "inventory sync", "username": "automation", "locations": ["Italy"],
+		"permission": "read", "expiresAt": "2025-01-01T00:00:00Z"}`
+	assert.NoError(t, ValidateAPIToken([]byte(valid)))
+
+	allLocations := `{"name": "backup", "username": "automation", "locations": null, "permission": "write", "expiresAt": "2025-01-01T00:00:00Z"}`
+	assert.NoError(t, ValidateAPIToken([]byte(allLocations)))
+
+	invalidPermission := `{"name": "t", "username": "automation", "permission": "admin", "expiresAt": "2025-01-01T00:00:00Z"}`
+	assert.ErrorIs(t, ValidateAPIToken([]byte(invalidPermission)), utils.ErrInvalidAPIToken)
+
+	missingName := `{"username": "automation", "permission": "read", "expiresAt": "2025-01-01T00:00:00Z"}`
+	assert.ErrorIs(t, ValidateAPIToken([]byte(missingName)), utils.ErrInvalidAPIToken)
+
+	emptyLocation := `{"name": "t", "username": "automation", "locations": [""], "permission": "read", "expiresAt": "2025-01-01T00:00:00Z"}`
+	assert.ErrorIs(t, ValidateAPIToken([]byte(emptyLocation)), utils.ErrInvalidAPIToken)
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// HostdataVersion is a version of the hostdata sent by the agents
+type HostdataVersion struct {
+	Version int
+	// Schemas are the JSON schemas of the version, the hostdata one first followed by the ones it references
+	Schemas []string
+	// Upcast converts in place a payload of the previous version to this one.
+	// Numbers in the payload are json.Number. It's nil for the oldest version
+	Upcast func(payload map[string]interface{}) error
+}
+
+// HostdataHeader contains the informations declared by the agent in the hostdata
+type HostdataHeader struct {
+	AgentVersion  string
+	SchemaVersion int
+	// Upcasted is true if the hostdata was converted from an older schema version
+	Upcasted bool
+}
+
+// HostdataRegistry contains the known versions of the hostdata
+type HostdataRegistry struct {
+	mutex    sync.RWMutex
+	versions map[int]*registeredHostdataVersion
+}
+
+type registeredHostdataVersion struct {
+	HostdataVersion
+	schema *gojsonschema.Schema
+}
+
+// NewHostdataRegistry return an empty registry
+func NewHostdataRegistry() *HostdataRegistry {
+	return &HostdataRegistry{
+		versions: make(map[int]*registeredHostdataVersion),
+	}
+}
+
+// Register compiles the schemas of the version and adds it to the registry
+func (r *HostdataRegistry) Register(version HostdataVersion) error {
+	if version.Version < 1 {
+		return utils.NewErrorf("Invalid hostdata schema version: %d", version.Version)
+	}
+
+	if len(version.Schemas) == 0 {
+		return utils.NewErrorf("Hostdata schema version %d has no schemas", version.Version)
+	}
+
+	sl := gojsonschema.NewSchemaLoader()
+
+	for i := 1; i < len(version.Schemas); i++ {
+		jl := gojsonschema.NewStringLoader(version.Schemas[i])
+		if err := sl.AddSchemas(jl); err != nil {
+			return utils.NewError(err, fmt.Sprintf("Wrong hostdata schema version %d: [%s]", version.Version, version.Schemas[i]))
+		}
+	}
+
+	compiled, err := sl.Compile(gojsonschema.NewStringLoader(version.Schemas[0]))
+	if err != nil {
+		return utils.NewError(err, fmt.Sprintf("Wrong hostdata schema version %d: can't load or compile it", version.Version))
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.versions[version.Version]; ok {
+		return utils.NewErrorf("Hostdata schema version %d is already registered", version.Version)
+	}
+
+	r.versions[version.Version] = &registeredHostdataVersion{
+		HostdataVersion: version,
+		schema:          compiled,
+	}
+
+	return nil
+}
+
+// Versions return the registered versions, sorted ascending
+func (r *HostdataRegistry) Versions() []int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	versions := make([]int, 0, len(r.versions))
+	for v := range r.versions {
+		versions = append(versions, v)
+	}
+
+	sort.Ints(versions)
+
+	return versions
+}
+
+// Current return the newest registered version, 0 if the registry is empty
+func (r *HostdataRegistry) Current() int {
+	versions := r.Versions()
+	if len(versions) == 0 {
+		return 0
+	}
+
+	return versions[len(versions)-1]
+}
+
+// Validate validates the hostdata against the schema of the version it declares
+func (r *HostdataRegistry) Validate(raw []byte) error {
+	header, err := readHostdataHeader(raw)
+	if err != nil {
+		return err
+	}
+
+	version, err := r.get(header.SchemaVersion)
+	if err != nil {
+		return err
+	}
+
+	return validateHostdata(version.schema, raw)
+}
+
+// Upcast validates the hostdata against the schema of the version it declares
+// and converts it, one version at a time, to the current one.
+// The header is returned whenever the hostdata is a JSON object, even if it's invalid
+func (r *HostdataRegistry) Upcast(raw []byte) ([]byte, *HostdataHeader, error) {
+	header, err := readHostdataHeader(raw)
+	if err != nil {
+		return nil, header, err
+	}
+
+	version, err := r.get(header.SchemaVersion)
+	if err != nil {
+		return nil, header, err
+	}
+
+	if err := validateHostdata(version.schema, raw); err != nil {
+		return nil, header, err
+	}
+
+	current := r.Current()
+	if header.SchemaVersion == current {
+		return raw, header, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	payload := make(map[string]interface{})
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, header, fmt.Errorf("%w: %s", utils.ErrInvalidHostdata, err)
+	}
+
+	for v := header.SchemaVersion + 1; v <= current; v++ {
+		next, err := r.get(v)
+		if err != nil {
+			return nil, header, err
+		}
+
+		if next.Upcast == nil {
+			return nil, header, utils.NewErrorf("Hostdata schema version %d can't be upcasted from version %d", v, v-1)
+		}
+
+		if err := next.Upcast(payload); err != nil {
+			return nil, header, utils.NewError(err, fmt.Sprintf("Can't upcast hostdata from schema version %d to %d", v-1, v))
+		}
+
+		payload["schemaVersion"] = v
+	}
+
+	upcasted, err := json.Marshal(payload)
+	if err != nil {
+		return nil, header, utils.NewError(err, "Can't marshal upcasted hostdata")
+	}
+
+	currentVersion, err := r.get(current)
+	if err != nil {
+		return nil, header, err
+	}
+
+	if err := validateHostdata(currentVersion.schema, upcasted); err != nil {
+		// it's a bug of the upcast functions, not of the agent
+		return nil, header, utils.NewErrorf("Hostdata upcasted from schema version %d isn't valid: %s", header.SchemaVersion, err)
+	}
+
+	header.Upcasted = true
+
+	return upcasted, header, nil
+}
+
+func (r *HostdataRegistry) get(version int) (*registeredHostdataVersion, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	v, ok := r.versions[version]
+	if !ok {
+		supported := make([]string, 0, len(r.versions))
+		for v := range r.versions {
+			supported = append(supported, fmt.Sprint(v))
+		}
+
+		sort.Strings(supported)
+
+		return nil, fmt.Errorf("%w: %w: %d, supported versions are [%s]",
+			utils.ErrInvalidHostdata, utils.ErrUnsupportedSchemaVersion, version, strings.Join(supported, ", "))
+	}
+
+	return v, nil
+}
+
+func readHostdataHeader(raw []byte) (*HostdataHeader, error) {
+	var fields struct {
+		AgentVersion  interface{} `json:"agentVersion"`
+		SchemaVersion interface{} `json:"schemaVersion"`
+	}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrInvalidHostdata, err)
+	}
+
+	header := &HostdataHeader{}
+
+	if agentVersion, ok := fields.AgentVersion.(string); ok {
+		header.AgentVersion = agentVersion
+	}
+
+	schemaVersion, ok := fields.SchemaVersion.(float64)
+	if !ok || schemaVersion != math.Trunc(schemaVersion) {
+		return header, fmt.Errorf("%w: schemaVersion is missing or isn't an integer", utils.ErrInvalidHostdata)
+	}
+
+	header.SchemaVersion = int(schemaVersion)
+
+	return header, nil
+}
+
+func validateHostdata(schema *gojsonschema.Schema, raw []byte) error {
+	documentLoader := gojsonschema.NewBytesLoader(raw)
+	result, err := schema.Validate(documentLoader)
+
+	syntaxErr := &json.SyntaxError{}
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%w: %s", utils.ErrInvalidHostdata, err)
+	} else if err != nil {
+		return err
+	}
+
+	if !result.Valid() {
+		errorMsg := new(strings.Builder)
+
+		for _, err := range result.Errors() {
+			value := fmt.Sprintf("%v", err.Value())
+			if len(value) > 80 {
+				value = value[:78] + ".."
+			}
+
+			errorMsg.WriteString(fmt.Sprintf("\t- %s. Value: [%v]\n", err, value))
+		}
+
+		return fmt.Errorf("%w:\n%s", utils.ErrInvalidHostdata, errorMsg.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const testHostdataV1 = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["hostname", "schemaVersion", "memory"],
+	"properties": {
+		"hostname": {"type": "string"},
+		"agentVersion": {"type": "string"},
+		"schemaVersion": {"type": "integer", "const": 1},
+		"memory": {"type": "number"}
+	}
+}`
+
+const testHostdataV2 = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["hostname", "schemaVersion", "memoryTotal"],
+	"additionalProperties": false,
+	"properties": {
+		"hostname": {"type": "string"},
+		"agentVersion": {"type": "string"},
+		"schemaVersion": {"type": "integer", "const": 2},
+		"memoryTotal": {"$ref": "http://ercole.io/test-memory.json"}
+	}
+}`
+
+const testMemorySchema = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"$id": "http://ercole.io/test-memory.json",
+	"type": "integer"
+}`
+
+func renameMemory(payload map[string]interface{}) error {
+	payload["memoryTotal"] = payload["memory"]
+	delete(payload, "memory")
+
+	return nil
+}
+
+func newTestHostdataRegistry(t *testing.T, upcast func(map[string]interface{}) error) *HostdataRegistry {
+	registry := NewHostdataRegistry()
+	require.NoError(t, registry.Register(HostdataVersion{Version: 1, Schemas: []string{testHostdataV1}}))
+	require.NoError(t, registry.Register(HostdataVersion{Version: 2, Schemas: []string{testHostdataV2, testMemorySchema}, Upcast: upcast}))
+
+	return registry
+}
+
+func TestHostdataSchemaVersions(t *testing.T) {
+	current, supported, err := HostdataSchemaVersions()
+	require.NoError(t, err)
+
+	assert.Equal(t, model.SchemaVersion, current)
+	assert.Contains(t, supported, model.SchemaVersion)
+}
+
+func TestHostdataRegistry_Register(t *testing.T) {
+	registry := newTestHostdataRegistry(t, renameMemory)
+
+	assert.Equal(t, []int{1, 2}, registry.Versions())
+	assert.Equal(t, 2, registry.Current())
+
+	assert.Error(t, registry.Register(HostdataVersion{Version: 2, Schemas: []string{testHostdataV2, testMemorySchema}}))
+	assert.Error(t, registry.Register(HostdataVersion{Version: 0, Schemas: []string{testHostdataV1}}))
+	assert.Error(t, registry.Register(HostdataVersion{Version: 3}))
+	assert.Error(t, registry.Register(HostdataVersion{Version: 3, Schemas: []string{`{"type": 42}`}}))
+
+	assert.Equal(t, 0, NewHostdataRegistry().Current())
+}
+
+func TestHostdataRegistry_Validate(t *testing.T) {
+	registry := newTestHostdataRegistry(t, renameMemory)
+
+	assert.NoError(t, registry.Validate([]byte(`{"hostname": "foo", "schemaVersion": 1, "memory": 42}`)))
+	assert.NoError(t, registry.Validate([]byte(`{"hostname": "foo", "schemaVersion": 2, "memoryTotal": 42}`)))
+
+	err := registry.Validate([]byte(`{"hostname": "foo", "schemaVersion": 2, "memory": 42}`))
+	assert.ErrorIs(t, err, utils.ErrInvalidHostdata)
+	assert.False(t, errors.Is(err, utils.ErrUnsupportedSchemaVersion))
+}
+
+func TestHostdataRegistry_Upcast(t *testing.T) {
+	t.Run("Current version", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, renameMemory)
+		raw := []byte(`{"hostname": "foo", "agentVersion": "2.1.0", "schemaVersion": 2, "memoryTotal": 42}`)
+
+		actual, header, err := registry.Upcast(raw)
+		require.NoError(t, err)
+
+		assert.Equal(t, raw, actual)
+		assert.Equal(t, &HostdataHeader{AgentVersion: "2.1.0", SchemaVersion: 2}, header)
+	})
+
+	t.Run("Older version", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, renameMemory)
+		raw := []byte(`{"hostname": "foo", "agentVersion": "1.9.0", "schemaVersion": 1, "memory": 9007199254740993}`)
+
+		actual, header, err := registry.Upcast(raw)
+		require.NoError(t, err)
+
+		assert.Equal(t, &HostdataHeader{AgentVersion: "1.9.0", SchemaVersion: 1, Upcasted: true}, header)
+		assert.JSONEq(t, `{"hostname": "foo", "agentVersion": "1.9.0", "schemaVersion": 2, "memoryTotal": 9007199254740993}`, string(actual))
+		assert.Contains(t, string(actual), `"memoryTotal":9007199254740993`)
+	})
+
+	t.Run("Invalid for the declared version", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, renameMemory)
+
+		_, header, err := registry.Upcast([]byte(`{"hostname": "foo", "agentVersion": "1.9.0", "schemaVersion": 1}`))
+		assert.ErrorIs(t, err, utils.ErrInvalidHostdata)
+		assert.Equal(t, &HostdataHeader{AgentVersion: "1.9.0", SchemaVersion: 1}, header)
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, renameMemory)
+
+		_, header, err := registry.Upcast([]byte(`{"hostname": "foo", "agentVersion": "3.0.0", "schemaVersion": 3}`))
+		assert.ErrorIs(t, err, utils.ErrInvalidHostdata)
+		assert.ErrorIs(t, err, utils.ErrUnsupportedSchemaVersion)
+		assert.Equal(t, &HostdataHeader{AgentVersion: "3.0.0", SchemaVersion: 3}, header)
+		assert.Contains(t, err.Error(), "[1, 2]")
+	})
+
+	t.Run("Missing version", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, renameMemory)
+
+		_, header, err := registry.Upcast([]byte(`{"hostname": "foo", "agentVersion": "1.9.0", "schemaVersion": "1"}`))
+		assert.ErrorIs(t, err, utils.ErrInvalidHostdata)
+		assert.Equal(t, &HostdataHeader{AgentVersion: "1.9.0"}, header)
+
+		_, header, err = registry.Upcast([]byte(`{"hostname": "foo"`))
+		assert.ErrorIs(t, err, utils.ErrInvalidHostdata)
+		assert.Nil(t, header)
+	})
+
+	t.Run("Upcast error", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, func(map[string]interface{}) error { return errors.New("boom") })
+
+		_, _, err := registry.Upcast([]byte(`{"hostname": "foo", "schemaVersion": 1, "memory": 42}`))
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, utils.ErrInvalidHostdata))
+	})
+
+	t.Run("Upcast produces an invalid hostdata", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, func(map[string]interface{}) error { return nil })
+
+		_, _, err := registry.Upcast([]byte(`{"hostname": "foo", "schemaVersion": 1, "memory": 42}`))
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, utils.ErrInvalidHostdata))
+	})
+
+	t.Run("Missing upcast function", func(t *testing.T) {
+		registry := newTestHostdataRegistry(t, nil)
+
+		_, _, err := registry.Upcast([]byte(`{"hostname": "foo", "schemaVersion": 1, "memory": 42}`))
+		assert.Error(t, err)
+	})
+}
@@ -17,14 +17,7 @@ package schema
 
 import (
 	_ "embed"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"strings"
-
-	"github.com/xeipuuv/gojsonschema"
-
-	"github.com/ercole-io/ercole/v2/utils"
+	"sync"
 )
 
 //go:embed hostdata.json
@@ -45,62 +38,63 @@ var mysqlSchema string
 //go:embed mongodb.json
 var mongodbSchema string
 
-var schema *gojsonschema.Schema
+// hostdataVersions are the versions of the hostdata accepted from the agents.
+// When the hostdata format changes, add the new version here with its schemas
+// and the function that upcasts a payload of the previous version to it
+var hostdataVersions = []HostdataVersion{
+	{
+		Version: 1,
+		Schemas: []string{hostdataSchema, oracleSchema, postgresqlSchema, microsoftSchema, mysqlSchema, mongodbSchema},
+	},
+}
 
-func ValidateHostdata(raw []byte) error {
-	if schema == nil {
-		if err := loadSchema(); err != nil {
-			return err
-		}
-	}
+var hostdataRegistry *HostdataRegistry
+
+var loadSchemaOnce sync.Once
 
-	documentLoader := gojsonschema.NewBytesLoader(raw)
-	result, err := schema.Validate(documentLoader)
+var loadSchemaErr error
 
-	syntaxErr := &json.SyntaxError{}
-	if errors.As(err, &syntaxErr) {
-		return fmt.Errorf("%w: %s", utils.ErrInvalidHostdata, err)
-	} else if err != nil {
+// ValidateHostdata validates the hostdata against the schema of the version it declares
+func ValidateHostdata(raw []byte) error {
+	if err := loadSchema(); err != nil {
 		return err
 	}
 
-	if !result.Valid() {
-		errorMsg := new(strings.Builder)
+	return hostdataRegistry.Validate(raw)
+}
 
-		for _, err := range result.Errors() {
-			value := fmt.Sprintf("%v", err.Value())
-			if len(value) > 80 {
-				value = value[:78] + ".."
-			}
+// UpcastHostdata validates the hostdata against the schema of the version it declares
+// and converts it to the current version
+func UpcastHostdata(raw []byte) ([]byte, *HostdataHeader, error) {
+	if err := loadSchema(); err != nil {
+		return nil, nil, err
+	}
 
-			errorMsg.WriteString(fmt.Sprintf("\t- %s. Value: [%v]\n", err, value))
-		}
+	return hostdataRegistry.Upcast(raw)
+}
 
-		return fmt.Errorf("%w:\n%s", utils.ErrInvalidHostdata, errorMsg.String())
+// HostdataSchemaVersions return the current hostdata schema version and all the supported ones
+func HostdataSchemaVersions() (int, []int, error) {
+	if err := loadSchema(); err != nil {
+		return 0, nil, err
 	}
 
-	return nil
+	return hostdataRegistry.Current(), hostdataRegistry.Versions(), nil
 }
 
 func loadSchema() error {
-	sl := gojsonschema.NewSchemaLoader()
+	loadSchemaOnce.Do(func() {
+		registry := NewHostdataRegistry()
 
-	schemas := []string{oracleSchema, postgresqlSchema, microsoftSchema, mysqlSchema, mongodbSchema}
-	for i := range schemas {
-		jl := gojsonschema.NewStringLoader(schemas[i])
-		if err := sl.AddSchemas(jl); err != nil {
-			return utils.NewError(err, "Wrong hostdata schema: [%s]", schemas[i])
+		for _, version := range hostdataVersions {
+			if err := registry.Register(version); err != nil {
+				loadSchemaErr = err
+				return
+			}
 		}
-	}
-
-	hostdata := gojsonschema.NewStringLoader(hostdataSchema)
 
-	var err error
-
-	schema, err = sl.Compile(hostdata)
-	if err != nil {
-		return utils.NewError(err, "Wrong hostdata schema: can't load or compile it")
-	}
+		hostdataRegistry = registry
+	})
 
-	return nil
+	return loadSchemaErr
 }
@@ -0,0 +1,44 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestLoadMaintenanceWindowSchema(t *testing.T) {
+	_, err := loadMaintenanceWindowSchema()
+	assert.Nil(t, err)
+}
+
+func TestValidateMaintenanceWindow(t *testing.T) {
+	valid := `{"name": "patching", "description": "", "start": "2024-03-01T20:00:00Z", "end": "2024-03-01T23:00:00Z",
+		"hostnames": ["foobar"], "locations": null, "tags": null}`
+	assert.NoError(t, ValidateMaintenanceWindow([]byte(valid)))
+
+	validTags := `{"name": "patching", "start": "2024-03-01T20:00:00Z", "end": "2024-03-01T23:00:00Z", "tags": ["erp"]}`
+	assert.NoError(t, ValidateMaintenanceWindow([]byte(validTags)))
+
+	withoutScope := `{"name": "patching", "start": "2024-03-01T20:00:00Z", "end": "2024-03-01T23:00:00Z",
+		"hostnames": [], "locations": null, "tags": null}`
+	assert.ErrorIs(t, ValidateMaintenanceWindow([]byte(withoutScope)), utils.ErrInvalidMaintenanceWindow)
+
+	wrongDate := `{"name": "patching", "start": "yesterday", "end": "2024-03-01T23:00:00Z", "hostnames": ["foobar"]}`
+	assert.ErrorIs(t, ValidateMaintenanceWindow([]byte(wrongDate)), utils.ErrInvalidMaintenanceWindow)
+}
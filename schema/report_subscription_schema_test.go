@@ -0,0 +1,47 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestLoadReportSubscriptionSchema(t *testing.T) {
+	_, err := loadReportSubscriptionSchema()
+	assert.Nil(t, err)
+}
+
+func TestValidateReportSubscription(t *testing.T) {
+	valid := `{"name": "weekly hosts", "report": "hosts", "filters": {"location": "Italy", "environment": ""},
+		"schedule": "0 7 * * 1", "format": "XLSX", "recipients": ["dba@ercole.test"], "enabled": true}`
+	assert.NoError(t, ValidateReportSubscription([]byte(valid)))
+
+	invalidReport := `{"name": "r", "report": "alerts", "schedule": "@daily", "format": "XLSX", "recipients": ["dba@ercole.test"], "enabled": true}`
+	assert.ErrorIs(t, ValidateReportSubscription([]byte(invalidReport)), utils.ErrInvalidReportSubscription)
+
+	invalidFormat := `{"name": "r", "report": "hosts", "schedule": "@daily", "format": "DOCX", "recipients": ["dba@ercole.test"], "enabled": true}`
+	assert.ErrorIs(t, ValidateReportSubscription([]byte(invalidFormat)), utils.ErrInvalidReportSubscription)
+
+	noRecipients := `{"name": "r", "report": "hosts", "schedule": "@daily", "format": "CSV", "recipients": [], "enabled": true}`
+	assert.ErrorIs(t, ValidateReportSubscription([]byte(noRecipients)), utils.ErrInvalidReportSubscription)
+
+	missingSchedule := `{"name": "r", "report": "hosts", "format": "PDF", "recipients": ["dba@ercole.test"], "enabled": true}`
+	assert.ErrorIs(t, ValidateReportSubscription([]byte(missingSchedule)), utils.ErrInvalidReportSubscription)
+}
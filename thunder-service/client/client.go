@@ -0,0 +1,93 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+type ThunderSvcClientInterface interface {
+	GetAwsRecommendationsAsXLSX() (*excelize.File, error)
+	GetOciRecommendationsAsXLSX() (*excelize.File, error)
+}
+
+type Client struct {
+	remoteEndpoint string
+	client         *http.Client
+	username       string
+	password       string
+}
+
+// NewClient return the client of the thunder-service, authenticated with the service credential of the authentication provider
+func NewClient(config config.ThunderService, authenticationProvider config.AuthenticationProviderConfig) *Client {
+	return &Client{
+		remoteEndpoint: strings.TrimSuffix(config.RemoteEndpoint, "/"),
+		client:         &http.Client{Timeout: 5 * time.Minute},
+		username:       authenticationProvider.Username,
+		password:       authenticationProvider.Password,
+	}
+}
+
+func (c *Client) GetAwsRecommendationsAsXLSX() (*excelize.File, error) {
+	return c.getXLSX(context.TODO(), "/aws/aws-recommendations")
+}
+
+func (c *Client) GetOciRecommendationsAsXLSX() (*excelize.File, error) {
+	return c.getXLSX(context.TODO(), "/oracle-cloud/oci-recommendations")
+}
+
+func (c *Client) getXLSX(ctx context.Context, path string) (*excelize.File, error) {
+	url := utils.NewAPIUrlNoParams(c.remoteEndpoint, c.username, c.password, path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("Thunder error (code: %d): %s", resp.StatusCode, string(body))
+	}
+
+	file, err := excelize.OpenReader(resp.Body)
+	if err != nil {
+		return nil, utils.NewError(err, "XLSX")
+	}
+
+	return file, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
+)
+
+// auditSnapshots return the functions that load the cloud profiles modified by the routes,
+// so the audit log records what they changed. The other routes, as the ones adding the profiles,
+// record the body of the request
+func (ctrl *ThunderController) auditSnapshots() map[string]middleware.AuditSnapshot {
+	return map[string]middleware.AuditSnapshot{
+		"PUT /oracle-cloud/configurations/{id}":                                         ctrl.ociProfileAuditSnapshot,
+		"DELETE /oracle-cloud/configurations/{id}":                                      ctrl.ociProfileAuditSnapshot,
+		"PUT /oracle-cloud/profile-selection/profileid/{profileid}/selected/{selected}": ctrl.ociProfileAuditSnapshot,
+
+		"PUT /aws/configurations/{id}":                                         ctrl.awsProfileAuditSnapshot,
+		"DELETE /aws/configurations/{id}":                                      ctrl.awsProfileAuditSnapshot,
+		"PUT /aws/profile-selection/profileid/{profileid}/selected/{selected}": ctrl.awsProfileAuditSnapshot,
+
+		"PUT /azure/configurations/{id}":                                         ctrl.azureProfileAuditSnapshot,
+		"DELETE /azure/configurations/{id}":                                      ctrl.azureProfileAuditSnapshot,
+		"PUT /azure/profile-selection/profileid/{profileid}/selected/{selected}": ctrl.azureProfileAuditSnapshot,
+
+		"PUT /gcp/configurations/{profileid}/selected/{selected}": ctrl.gcpProfileAuditSnapshot,
+		"PUT /gcp/configurations/{profileid}":                     ctrl.gcpProfileAuditSnapshot,
+		"DELETE /gcp/configurations/{profileid}":                  ctrl.gcpProfileAuditSnapshot,
+	}
+}
+
+// auditProfileID return the id of the profile modified by the request
+func auditProfileID(r *http.Request) string {
+	if id, ok := mux.Vars(r)["id"]; ok {
+		return id
+	}
+
+	return mux.Vars(r)["profileid"]
+}
+
+func (ctrl *ThunderController) ociProfileAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	profiles, err := ctrl.Service.GetOciProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		if profile.ID.Hex() == auditProfileID(r) {
+			return profile, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *ThunderController) awsProfileAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	profiles, err := ctrl.Service.GetAwsProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		if profile.ID.Hex() == auditProfileID(r) {
+			return profile, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *ThunderController) azureProfileAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	profiles, err := ctrl.Service.GetAzureProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		if profile.ID.Hex() == auditProfileID(r) {
+			return profile, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (ctrl *ThunderController) gcpProfileAuditSnapshot(r *http.Request, body []byte) (interface{}, error) {
+	profiles, err := ctrl.Service.GetGcpProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		if profile.ID.Hex() == auditProfileID(r) {
+			return profile, nil
+		}
+	}
+
+	return nil, nil
+}
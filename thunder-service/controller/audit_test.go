@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func TestAudit_ProfileSnapshot(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockThunderServiceInterface(mockCtrl)
+	apiSvc := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "writer", Groups: []string{"writers"}}
+	ac := ThunderController{
+		TimeNow:    utils.Btc(utils.P("2022-06-28T12:02:03Z")),
+		Service:    as,
+		ApiService: apiSvc,
+		Config:     config.Configuration{},
+		Log:        logger.NewLogger("TEST"),
+	}
+	handler := ac.GetThunderControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	secret := "s3cr3t"
+	before := model.AwsProfile{
+		ID:              utils.Str2oid("62bac3e3c6f0b0a0a0a0a0a1"),
+		AccessKeyId:     "AKIA",
+		SecretAccessKey: &secret,
+		Region:          "eu-west-1",
+		Name:            "production",
+	}
+	after := before
+	after.Selected = true
+
+	gomock.InOrder(
+		apiSvc.EXPECT().GetUserPermission(user, model.AllLocation).Return(model.WritePermission, nil),
+		as.EXPECT().GetAwsProfiles().Return([]model.AwsProfile{before}, nil),
+		as.EXPECT().SelectAwsProfile("62bac3e3c6f0b0a0a0a0a0a1", true).Return(nil),
+		as.EXPECT().GetAwsProfiles().Return([]model.AwsProfile{after}, nil),
+		apiSvc.EXPECT().InsertAuditEntry(gomock.Any()).
+			Do(func(entry model.AuditEntry) {
+				assert.Equal(t, "/aws/profile-selection/profileid/{profileid}/selected/{selected}", entry.Route)
+				assert.NotContains(t, string(entry.Before), secret)
+				assert.Equal(t, []model.AuditChange{
+					{Field: "selected", Before: json.RawMessage("false"), After: json.RawMessage("true")},
+				}, entry.Changes)
+			}).Return(nil),
+	)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "/aws/profile-selection/profileid/62bac3e3c6f0b0a0a0a0a0a1/selected/true", nil)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/golang/gddo/httputil"
+	"github.com/gorilla/mux"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func (ctrl *ThunderController) GetAzureRecommendations(w http.ResponseWriter, r *http.Request) {
+	choice := httputil.NegotiateContentType(r, []string{"application/json", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, "application/json")
+
+	switch choice {
+	case "application/json":
+		recommendations, err := ctrl.Service.GetAzureRecommendations()
+		if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		utils.WriteJSONResponse(w, http.StatusOK, recommendations)
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		result, err := ctrl.Service.CreateAzureRecommendationsXlsx()
+		if err != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		utils.WriteXLSXResponse(w, result)
+	}
+}
+
+func (ctrl *ThunderController) GetAzureRecommendationErrors(w http.ResponseWriter, r *http.Request) {
+	var recommendationErrors []model.AzureRecommendationError
+
+	var err error
+
+	if seqValue, ok := mux.Vars(r)["seqnum"]; ok {
+		seq, errParse := strconv.ParseUint(seqValue, 10, 64)
+		if errParse != nil {
+			utils.WriteAndLogError(ctrl.Log, w, http.StatusBadRequest, utils.NewError(errParse, http.StatusText(http.StatusBadRequest)))
+			return
+		}
+
+		recommendationErrors, err = ctrl.Service.GetAzureRecommendationErrorsBySeqValue(seq)
+	} else {
+		recommendationErrors, err = ctrl.Service.GetLastAzureRecommendationErrors()
+	}
+
+	if err != nil {
+		utils.WriteAndLogError(ctrl.Log, w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, recommendationErrors)
+}
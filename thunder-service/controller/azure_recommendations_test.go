@@ -0,0 +1,172 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func newTestAzureController(t *testing.T) (ThunderController, *MockThunderServiceInterface) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	as := NewMockThunderServiceInterface(mockCtrl)
+
+	return ThunderController{
+		TimeNow: utils.Btc(utils.P("2024-05-08T12:00:00Z")),
+		Service: as,
+		Config:  config.Configuration{},
+		Log:     logger.NewLogger("TEST"),
+	}, as
+}
+
+func TestGetAzureRecommendations_Success(t *testing.T) {
+	ac, as := newTestAzureController(t)
+
+	recommendations := []model.AzureRecommendation{
+		{
+			SeqValue:   1,
+			ProfileID:  utils.Str2oid("000000000000000000000001"),
+			Category:   model.AzureUnusedResource,
+			Suggestion: model.AzureDeletePublicIPNotAssociated,
+			Name:       "ip1",
+			ObjectType: model.AzurePublicIP,
+			Details:    map[string]interface{}{"IP_ADDRESS": "20.1.2.3"},
+			CreatedAt:  utils.P("2024-05-08T12:00:00Z"),
+		},
+	}
+
+	as.EXPECT().GetAzureRecommendations().Return(recommendations, nil)
+
+	req, err := http.NewRequest("GET", "/azure/azure-recommendations", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(ac.GetAzureRecommendations).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, utils.ToJSON(recommendations), rr.Body.String())
+}
+
+func TestGetAzureRecommendations_Error(t *testing.T) {
+	ac, as := newTestAzureController(t)
+
+	as.EXPECT().GetAzureRecommendations().Return(nil, aerrMock)
+
+	req, err := http.NewRequest("GET", "/azure/azure-recommendations", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(ac.GetAzureRecommendations).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+func TestGetAzureRecommendations_XLSX(t *testing.T) {
+	ac, as := newTestAzureController(t)
+
+	as.EXPECT().CreateAzureRecommendationsXlsx().Return(excelize.NewFile(), nil)
+
+	req, err := http.NewRequest("GET", "/azure/azure-recommendations", nil)
+	require.NoError(t, err)
+	req.Header.Add("Accept", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(ac.GetAzureRecommendations).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	_, err = excelize.OpenReader(rr.Body)
+	require.NoError(t, err)
+}
+
+func TestGetAzureRecommendationErrors(t *testing.T) {
+	recommendationErrors := []model.AzureRecommendationError{
+		{
+			SeqValue:  4,
+			ProfileID: utils.Str2oid("000000000000000000000001"),
+			Category:  model.AzureAuthentication,
+			Error:     "unable to get the access token: 401 Unauthorized",
+			CreatedAt: utils.P("2024-05-08T12:00:00Z"),
+		},
+	}
+
+	t.Run("Last", func(t *testing.T) {
+		ac, as := newTestAzureController(t)
+		as.EXPECT().GetLastAzureRecommendationErrors().Return(recommendationErrors, nil)
+
+		req, err := http.NewRequest("GET", "/azure/azure-recommendation-errors", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetAzureRecommendationErrors).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.JSONEq(t, utils.ToJSON(recommendationErrors), rr.Body.String())
+	})
+
+	t.Run("By seqnum", func(t *testing.T) {
+		ac, as := newTestAzureController(t)
+		as.EXPECT().GetAzureRecommendationErrorsBySeqValue(uint64(4)).Return(recommendationErrors, nil)
+
+		req, err := http.NewRequest("GET", "/azure/azure-recommendation-errors/4", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"seqnum": "4"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetAzureRecommendationErrors).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.JSONEq(t, utils.ToJSON(recommendationErrors), rr.Body.String())
+	})
+
+	t.Run("Invalid seqnum", func(t *testing.T) {
+		ac, _ := newTestAzureController(t)
+
+		req, err := http.NewRequest("GET", "/azure/azure-recommendation-errors/abc", nil)
+		require.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"seqnum": "abc"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetAzureRecommendationErrors).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ac, as := newTestAzureController(t)
+		as.EXPECT().GetLastAzureRecommendationErrors().Return(nil, aerrMock)
+
+		req, err := http.NewRequest("GET", "/azure/azure-recommendation-errors", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(ac.GetAzureRecommendationErrors).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
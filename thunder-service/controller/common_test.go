@@ -16,3 +16,4 @@
 package controller
 
 //go:generate mockgen -source ../service/service.go -destination=fake_service_test.go -package=controller
+//go:generate mockgen -source ../../api-service/service/service.go -destination=fake_api_service_test.go -package=controller
@@ -19,6 +19,8 @@ import (
 	"net/http"
 
 	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/api-service/auth/middleware"
+	"github.com/ercole-io/ercole/v2/model"
 	"github.com/gorilla/mux"
 )
 
@@ -41,7 +43,18 @@ func (ctrl *ThunderController) GetThunderControllerHandler(auths []auth.Authenti
 			prefix = "/ldap"
 		}
 
+		if ap.GetType() == auth.OidcType {
+			prefix = "/oidc"
+		}
+
 		subrouter.Use(ap.AuthenticateMiddleware)
+		subrouter.Use(middleware.Write(ctrl.ApiService, middleware.WriteOptions{Prefix: prefix}))
+		subrouter.Use(middleware.Audit(ctrl.ApiService, ctrl.Log, middleware.AuditOptions{
+			Service:      model.AuditServiceThunder,
+			AuthProvider: ap.GetType(),
+			Prefix:       prefix,
+			Snapshots:    ctrl.auditSnapshots(),
+		}))
 		ctrl.setupProtectedRoutes(subrouter.PathPrefix(prefix).Subrouter())
 	}
 
@@ -76,6 +89,9 @@ func (ctrl *ThunderController) setupProtectedRoutes(router *mux.Router) {
 	router.HandleFunc("/azure/configurations/{id}", ctrl.UpdateAzureProfile).Methods("PUT")
 	router.HandleFunc("/azure/configurations/{id}", ctrl.DeleteAzureProfile).Methods("DELETE")
 	router.HandleFunc("/azure/profile-selection/profileid/{profileid}/selected/{selected}", ctrl.SelectAzureProfile).Methods("PUT")
+	router.HandleFunc("/azure/azure-recommendations", ctrl.GetAzureRecommendations).Methods("GET")
+	router.HandleFunc("/azure/azure-recommendation-errors", ctrl.GetAzureRecommendationErrors).Methods("GET")
+	router.HandleFunc("/azure/azure-recommendation-errors/{seqnum}", ctrl.GetAzureRecommendationErrors).Methods("GET")
 
 	router.HandleFunc("/gcp/configurations", ctrl.GetGcpProfiles).Methods("GET")
 	router.HandleFunc("/gcp/configurations", ctrl.AddGcpProfile).Methods("POST")
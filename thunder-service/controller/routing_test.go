@@ -0,0 +1,147 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ercole-io/ercole/v2/api-service/auth"
+	"github.com/ercole-io/ercole/v2/api-service/dto"
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeAuthenticationProvider struct {
+	user model.User
+}
+
+func (ap *fakeAuthenticationProvider) Init() {}
+
+func (ap *fakeAuthenticationProvider) AuthenticateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context.Set(r, "user", ap.user)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (ap *fakeAuthenticationProvider) GetToken(w http.ResponseWriter, r *http.Request) {}
+
+func (ap *fakeAuthenticationProvider) GetUserInfoIfCredentialsAreCorrect(username string, password string) (*dto.User, error) {
+	return nil, nil
+}
+
+func (ap *fakeAuthenticationProvider) GetType() string {
+	return "fake"
+}
+
+func TestGetThunderControllerHandler_ReadOnlyUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockThunderServiceInterface(mockCtrl)
+	apiSvc := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "reader", Groups: []string{"readers"}}
+	ac := ThunderController{
+		TimeNow:    utils.Btc(utils.P("2022-06-28T12:02:03Z")),
+		Service:    as,
+		ApiService: apiSvc,
+		Config:     config.Configuration{},
+		Log:        logger.NewLogger("TEST"),
+	}
+	handler := ac.GetThunderControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	routeVariable := regexp.MustCompile(`{[^}]+}`)
+	routes := 0
+
+	err := handler.(*mux.Router).Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			if method == http.MethodGet {
+				continue
+			}
+
+			routes++
+			path := routeVariable.ReplaceAllString(template, "test")
+
+			t.Run(method+" "+path, func(t *testing.T) {
+				apiSvc.EXPECT().GetUserPermission(user, model.AllLocation).Return(model.ReadPermission, nil).Times(1)
+
+				rr := httptest.NewRecorder()
+				req, err := http.NewRequest(method, path, strings.NewReader("{}"))
+				require.NoError(t, err)
+
+				handler.ServeHTTP(rr, req)
+
+				assert.Equal(t, http.StatusForbidden, rr.Code)
+			})
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, routes)
+}
+
+func TestGetThunderControllerHandler_WriteUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	as := NewMockThunderServiceInterface(mockCtrl)
+	apiSvc := NewMockAPIServiceInterface(mockCtrl)
+
+	user := model.User{Username: "writer", Groups: []string{"writers"}}
+	ac := ThunderController{
+		TimeNow:    utils.Btc(utils.P("2022-06-28T12:02:03Z")),
+		Service:    as,
+		ApiService: apiSvc,
+		Config:     config.Configuration{},
+		Log:        logger.NewLogger("TEST"),
+	}
+	handler := ac.GetThunderControllerHandler([]auth.AuthenticationProvider{&fakeAuthenticationProvider{user: user}})
+
+	apiSvc.EXPECT().GetUserPermission(user, model.AllLocation).Return(model.WritePermission, nil).Times(1)
+	apiSvc.EXPECT().InsertAuditEntry(gomock.Any()).
+		Do(func(entry model.AuditEntry) {
+			assert.Equal(t, model.AuditServiceThunder, entry.Service)
+			assert.Equal(t, "/aws/configurations", entry.Route)
+		}).Return(nil).Times(1)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/aws/configurations", strings.NewReader("{"))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
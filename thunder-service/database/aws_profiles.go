@@ -30,7 +30,13 @@ import (
 const AwsProfile_collection = "aws_profiles"
 
 func (md *MongoDatabase) AddAwsProfile(profile model.AwsProfile) error {
-	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(AwsProfile_collection).
+	var err error
+
+	if profile.SecretAccessKey, err = md.encryptSecret(profile.SecretAccessKey); err != nil {
+		return err
+	}
+
+	_, err = md.Client.Database(md.Config.Mongodb.DBName).Collection(AwsProfile_collection).
 		InsertOne(
 			context.TODO(),
 			profile,
@@ -49,6 +55,10 @@ func (md *MongoDatabase) UpdateAwsProfile(profile model.AwsProfile) error {
 
 	var p bson.M
 
+	if profile.SecretAccessKey, err = md.encryptSecret(profile.SecretAccessKey); err != nil {
+		return err
+	}
+
 	data, err := bson.Marshal(profile)
 	if err != nil {
 		return utils.NewError(err, "Unable to mashal profile")
@@ -98,6 +108,12 @@ func (md *MongoDatabase) GetAwsProfiles(hidePrivateKey bool) ([]model.AwsProfile
 		return nil, utils.NewError(err, "DB ERROR")
 	}
 
+	for i := range profiles {
+		if profiles[i].SecretAccessKey, err = md.decryptSecret(profiles[i].SecretAccessKey); err != nil {
+			return nil, err
+		}
+	}
+
 	return profiles, nil
 }
 
@@ -29,7 +29,13 @@ import (
 const AzureProfile_collection = "azure_profiles"
 
 func (md *MongoDatabase) AddAzureProfile(profile model.AzureProfile) error {
-	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(AzureProfile_collection).
+	var err error
+
+	if profile.ClientSecret, err = md.encryptSecret(profile.ClientSecret); err != nil {
+		return err
+	}
+
+	_, err = md.Client.Database(md.Config.Mongodb.DBName).Collection(AzureProfile_collection).
 		InsertOne(
 			context.TODO(),
 			profile,
@@ -48,6 +54,10 @@ func (md *MongoDatabase) UpdateAzureProfile(profile model.AzureProfile) error {
 
 	var p bson.M
 
+	if profile.ClientSecret, err = md.encryptSecret(profile.ClientSecret); err != nil {
+		return err
+	}
+
 	data, err := bson.Marshal(profile)
 	if err != nil {
 		return utils.NewError(err, "Unable to mashal profile")
@@ -97,6 +107,12 @@ func (md *MongoDatabase) GetAzureProfiles(hidePrivateKey bool) ([]model.AzurePro
 		return nil, utils.NewError(err, "DB ERROR")
 	}
 
+	for i := range profiles {
+		if profiles[i].ClientSecret, err = md.decryptSecret(profiles[i].ClientSecret); err != nil {
+			return nil, err
+		}
+	}
+
 	return profiles, nil
 }
 
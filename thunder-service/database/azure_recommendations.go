@@ -0,0 +1,156 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AzureRecommendationCollection      = "azure_recommendations"
+	AzureRecommendationErrorCollection = "azure_recommendation_errors"
+)
+
+func (md *MongoDatabase) AddAzureRecommendations(recommendations []model.AzureRecommendation) error {
+	if len(recommendations) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(recommendations))
+	for _, r := range recommendations {
+		docs = append(docs, r)
+	}
+
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(AzureRecommendationCollection).InsertMany(context.TODO(), docs)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+func (md *MongoDatabase) AddAzureRecommendationError(recommendationError model.AzureRecommendationError) error {
+	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(AzureRecommendationErrorCollection).InsertOne(context.TODO(), recommendationError)
+	if err != nil {
+		return utils.NewError(err, "DB ERROR")
+	}
+
+	return nil
+}
+
+// GetLastAzureSeqValue return the last seqValue used by the recommendations or by the errors,
+// a retrieve could have produced only errors
+func (md *MongoDatabase) GetLastAzureSeqValue() (uint64, error) {
+	var res uint64
+
+	for _, collection := range []string{AzureRecommendationCollection, AzureRecommendationErrorCollection} {
+		var doc struct {
+			SeqValue uint64 `bson:"seqValue"`
+		}
+
+		err := md.Client.Database(md.Config.Mongodb.DBName).Collection(collection).
+			FindOne(context.TODO(), bson.D{}, options.FindOne().SetSort(bson.M{"seqValue": -1})).
+			Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+
+		if err != nil {
+			return math.MaxUint64, utils.NewError(err, "DB ERROR")
+		}
+
+		if doc.SeqValue > res {
+			res = doc.SeqValue
+		}
+	}
+
+	return res, nil
+}
+
+func (md *MongoDatabase) GetAzureRecommendationsByProfiles(profileIDs []primitive.ObjectID) ([]model.AzureRecommendation, error) {
+	ctx := context.TODO()
+
+	seqValue, err := md.GetLastAzureSeqValue()
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{
+			"profileID": bson.M{"$in": profileIDs},
+			"seqValue":  seqValue,
+		}},
+		bson.M{"$lookup": bson.M{
+			"from":         AzureProfile_collection,
+			"localField":   "profileID",
+			"foreignField": "_id",
+			"as":           "profile",
+		}},
+		bson.M{"$unwind": bson.M{"path": "$profile"}},
+		bson.M{"$set": bson.M{"profileName": "$profile.name"}},
+		bson.M{"$unset": "profile"},
+	}
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(AzureRecommendationCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	result := make([]model.AzureRecommendation, 0)
+	if err := cur.All(ctx, &result); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return result, nil
+}
+
+func (md *MongoDatabase) GetAzureRecommendationErrors(seqValue uint64) ([]model.AzureRecommendationError, error) {
+	ctx := context.TODO()
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"seqValue": seqValue}},
+		bson.M{"$lookup": bson.M{
+			"from":         AzureProfile_collection,
+			"localField":   "profileID",
+			"foreignField": "_id",
+			"as":           "profile",
+		}},
+		bson.M{"$unwind": bson.M{"path": "$profile", "preserveNullAndEmptyArrays": true}},
+		bson.M{"$set": bson.M{"profileName": "$profile.name"}},
+		bson.M{"$unset": "profile"},
+	}
+
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(AzureRecommendationErrorCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	result := make([]model.AzureRecommendationError, 0)
+	if err := cur.All(ctx, &result); err != nil {
+		return nil, utils.NewError(err, "DB ERROR")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var azureProfileID = primitive.NewObjectID()
+
+var azureRecommendationProfile = model.AzureProfile{
+	ID:             azureProfileID,
+	TenantId:       "tenant1",
+	ClientId:       "client1",
+	ClientSecret:   &privateKeyTest,
+	SubscriptionId: "subscription1",
+	Region:         "westeurope",
+	Selected:       true,
+	Name:           "azureprofile1",
+}
+
+var recAzure1 = model.AzureRecommendation{
+	SeqValue:   3,
+	ProfileID:  azureProfileID,
+	Category:   model.AzureUnusedResource,
+	Suggestion: model.AzureDeleteManagedDiskNotAttached,
+	Name:       "disk1",
+	ResourceID: "/subscriptions/subscription1/resourceGroups/rg1/providers/Microsoft.Compute/disks/disk1",
+	ObjectType: model.AzureManagedDisk,
+	Details: map[string]interface{}{
+		"SKU": "Premium_LRS",
+	},
+	CreatedAt: time.Date(2024, 5, 26, 0, 0, 1, 0, time.UTC),
+}
+
+func (m *MongodbSuite) TestGetLastAzureSeqValue() {
+	defer m.db.Client.Database(m.dbname).Collection(AzureRecommendationCollection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(AzureRecommendationErrorCollection).DeleteMany(context.TODO(), bson.M{})
+
+	m.T().Run("should_return_zero_without_data", func(t *testing.T) {
+		res, err := m.db.GetLastAzureSeqValue()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), res)
+	})
+
+	m.T().Run("should_return_the_seq_value_of_the_recommendations", func(t *testing.T) {
+		require.NoError(t, m.db.AddAzureRecommendations([]model.AzureRecommendation{recAzure1}))
+
+		res, err := m.db.GetLastAzureSeqValue()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), res)
+	})
+
+	m.T().Run("should_return_the_seq_value_of_the_errors", func(t *testing.T) {
+		require.NoError(t, m.db.AddAzureRecommendationError(model.AzureRecommendationError{
+			SeqValue:  4,
+			ProfileID: azureProfileID,
+			Category:  model.AzureAuthentication,
+			Error:     "invalid client secret",
+			CreatedAt: time.Date(2024, 5, 27, 0, 0, 1, 0, time.UTC),
+		}))
+
+		res, err := m.db.GetLastAzureSeqValue()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), res)
+	})
+}
+
+func (m *MongodbSuite) TestGetAzureRecommendationsByProfiles() {
+	defer m.db.Client.Database(m.dbname).Collection(AzureRecommendationCollection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(AzureProfile_collection).DeleteMany(context.TODO(), bson.M{})
+
+	require.NoError(m.T(), m.db.AddAzureProfile(azureRecommendationProfile))
+
+	old := recAzure1
+	old.SeqValue = 2
+	old.Name = "disk0"
+	require.NoError(m.T(), m.db.AddAzureRecommendations([]model.AzureRecommendation{old, recAzure1}))
+
+	res, err := m.db.GetAzureRecommendationsByProfiles([]primitive.ObjectID{azureProfileID})
+	require.NoError(m.T(), err)
+
+	expected := recAzure1
+	expected.ProfileName = "azureprofile1"
+	assert.Equal(m.T(), []model.AzureRecommendation{expected}, res)
+
+	res, err = m.db.GetAzureRecommendationsByProfiles([]primitive.ObjectID{primitive.NewObjectID()})
+	require.NoError(m.T(), err)
+	assert.Empty(m.T(), res)
+}
+
+func (m *MongodbSuite) TestGetAzureRecommendationErrors() {
+	defer m.db.Client.Database(m.dbname).Collection(AzureRecommendationErrorCollection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(AzureProfile_collection).DeleteMany(context.TODO(), bson.M{})
+
+	require.NoError(m.T(), m.db.AddAzureProfile(azureRecommendationProfile))
+
+	recErr := model.AzureRecommendationError{
+		SeqValue:  3,
+		ProfileID: azureProfileID,
+		Category:  model.AzureVirtualMachine,
+		Error:     "forbidden",
+		CreatedAt: time.Date(2024, 5, 26, 0, 0, 1, 0, time.UTC),
+	}
+	require.NoError(m.T(), m.db.AddAzureRecommendationError(recErr))
+
+	res, err := m.db.GetAzureRecommendationErrors(3)
+	require.NoError(m.T(), err)
+
+	recErr.ProfileName = "azureprofile1"
+	assert.Equal(m.T(), []model.AzureRecommendationError{recErr}, res)
+
+	res, err = m.db.GetAzureRecommendationErrors(2)
+	require.NoError(m.T(), err)
+	assert.Empty(m.T(), res)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+// cloudSecretFields are the fields of the cloud profiles encrypted at rest
+var cloudSecretFields = []struct {
+	collection string
+	field      string
+}{
+	{AwsProfile_collection, "secretaccesskey"},
+	{AzureProfile_collection, "clientsecret"},
+	{GcpProfileCollection, "privatekey"},
+	{OciProfile_collection, "privateKey"},
+}
+
+// LoadKeyring loads the master keys of the cloud credentials from the configuration
+func (md *MongoDatabase) LoadKeyring() {
+	conf := md.Config.ThunderService.CloudSecrets
+
+	keyring, err := cr.LoadKeyring(conf.Key, conf.KeyFile, conf.PreviousKeys)
+	if err != nil {
+		md.Log.Fatal(err)
+	}
+
+	if keyring == nil {
+		md.Log.Warn("No master key in ThunderService.CloudSecrets: the credentials of the cloud profiles are stored in clear")
+	}
+
+	md.Keyring = keyring
+}
+
+// RotateCloudSecrets encrypts every credential of the cloud profiles with the current master key,
+// including the ones stored in clear. It returns the number of credentials updated
+func (md *MongoDatabase) RotateCloudSecrets() (int, error) {
+	ctx := context.TODO()
+	updated := 0
+
+	for _, s := range cloudSecretFields {
+		collection := md.Client.Database(md.Config.Mongodb.DBName).Collection(s.collection)
+
+		cur, err := collection.Find(ctx, bson.M{s.field: bson.M{"$type": "string"}})
+		if err != nil {
+			return updated, utils.NewError(err, "DB ERROR")
+		}
+
+		docs := make([]bson.M, 0)
+		if err := cur.All(ctx, &docs); err != nil {
+			return updated, utils.NewError(err, "DB ERROR")
+		}
+
+		for _, doc := range docs {
+			secret, changed, err := md.Keyring.Rotate(doc[s.field].(string))
+			if err != nil {
+				return updated, utils.NewErrorf("%w: can't rotate %s of %s %v", err, s.field, s.collection, doc["_id"])
+			}
+
+			if !changed {
+				continue
+			}
+
+			if _, err := collection.UpdateOne(ctx, bson.M{"_id": doc["_id"]}, bson.M{"$set": bson.M{s.field: secret}}); err != nil {
+				return updated, utils.NewError(err, "DB ERROR")
+			}
+
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+func (md *MongoDatabase) encryptSecret(secret *string) (*string, error) {
+	if secret == nil {
+		return nil, nil
+	}
+
+	encrypted, err := md.Keyring.Encrypt(*secret)
+	if err != nil {
+		return nil, utils.NewError(err, "Unable to encrypt secret")
+	}
+
+	return &encrypted, nil
+}
+
+func (md *MongoDatabase) decryptSecret(secret *string) (*string, error) {
+	if secret == nil {
+		return nil, nil
+	}
+
+	decrypted, err := md.Keyring.Decrypt(*secret)
+	if err != nil {
+		return nil, utils.NewError(err, "Unable to decrypt secret")
+	}
+
+	return &decrypted, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package database
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
+)
+
+func (m *MongodbSuite) newTestKeyring(previous ...string) (*cr.Keyring, string) {
+	key, err := cr.GenerateSecretsKey()
+	require.NoError(m.T(), err)
+
+	keyring, err := cr.LoadKeyring(key, "", previous)
+	require.NoError(m.T(), err)
+
+	return keyring, key
+}
+
+func (m *MongodbSuite) TestCloudSecrets_EncryptedAtRest() {
+	keyring, _ := m.newTestKeyring()
+	m.db.Keyring = keyring
+	defer func() { m.db.Keyring = nil }()
+	defer m.db.Client.Database(m.dbname).Collection(AwsProfile_collection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(GcpProfileCollection).DeleteMany(context.TODO(), bson.M{})
+
+	err := m.db.AddAwsProfile(awsProfile1)
+	require.NoError(m.T(), err)
+
+	var raw bson.M
+	err = m.db.Client.Database(m.dbname).Collection(AwsProfile_collection).
+		FindOne(context.TODO(), bson.M{"_id": awsProfile1.ID}).Decode(&raw)
+	require.NoError(m.T(), err)
+
+	assert.True(m.T(), cr.IsEncryptedSecret(raw["secretaccesskey"].(string)))
+	assert.Equal(m.T(), strSecretAccessKey1, *awsProfile1.SecretAccessKey)
+
+	profiles, err := m.db.GetAwsProfiles(false)
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), []model.AwsProfile{awsProfile1}, profiles)
+
+	gcpProfile := model.GcpProfile{
+		ID:          utils.Str2oid("5dd40bfb12f54dfda7b1c299"),
+		Name:        "gcp",
+		Selected:    true,
+		PrivateKey:  "gcp private key",
+		ClientEmail: "ercole@example.com",
+	}
+
+	err = m.db.AddGcpProfile(gcpProfile)
+	require.NoError(m.T(), err)
+
+	active, err := m.db.GetActiveGcpProfiles()
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), []model.GcpProfile{gcpProfile}, active)
+
+	listed, err := m.db.ListGcpProfiles()
+	require.NoError(m.T(), err)
+	require.Len(m.T(), listed, 1)
+	assert.Empty(m.T(), listed[0].PrivateKey)
+
+	err = m.db.UpdateGcpProfile(gcpProfile.ID, model.GcpProfile{Name: "gcp-renamed", ClientEmail: gcpProfile.ClientEmail})
+	require.NoError(m.T(), err)
+
+	active, err = m.db.GetActiveGcpProfiles()
+	require.NoError(m.T(), err)
+	require.Len(m.T(), active, 1)
+	assert.Equal(m.T(), "gcp-renamed", active[0].Name)
+	assert.Equal(m.T(), gcpProfile.PrivateKey, active[0].PrivateKey)
+}
+
+func (m *MongodbSuite) TestRotateCloudSecrets() {
+	oldKeyring, oldKey := m.newTestKeyring()
+	defer func() { m.db.Keyring = nil }()
+	defer m.db.Client.Database(m.dbname).Collection(AwsProfile_collection).DeleteMany(context.TODO(), bson.M{})
+	defer m.db.Client.Database(m.dbname).Collection(AzureProfile_collection).DeleteMany(context.TODO(), bson.M{})
+
+	m.db.Keyring = oldKeyring
+	err := m.db.AddAwsProfile(awsProfile1)
+	require.NoError(m.T(), err)
+
+	m.db.Keyring = nil
+	err = m.db.AddAzureProfile(azureProfile1)
+	require.NoError(m.T(), err)
+
+	keyring, _ := m.newTestKeyring(oldKey)
+	m.db.Keyring = keyring
+
+	updated, err := m.db.RotateCloudSecrets()
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), 2, updated)
+
+	updated, err = m.db.RotateCloudSecrets()
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), 0, updated)
+
+	m.db.Keyring = oldKeyring
+	_, err = m.db.GetAwsProfiles(false)
+	assert.ErrorIs(m.T(), err, utils.ErrUnknownSecretsKey)
+
+	m.db.Keyring = keyring
+	awsProfiles, err := m.db.GetAwsProfiles(false)
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), []model.AwsProfile{awsProfile1}, awsProfiles)
+
+	azureProfiles, err := m.db.GetAzureProfiles(false)
+	require.NoError(m.T(), err)
+	assert.Equal(m.T(), []model.AzureProfile{azureProfile1}, azureProfiles)
+}
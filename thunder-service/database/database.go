@@ -25,6 +25,7 @@ import (
 	"github.com/ercole-io/ercole/v2/model"
 	"github.com/ercole-io/ercole/v2/thunder-service/dto"
 	"github.com/ercole-io/ercole/v2/utils"
+	cr "github.com/ercole-io/ercole/v2/utils/crypto"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -77,6 +78,11 @@ type MongoDatabaseInterface interface {
 	UpdateAzureProfile(profile model.AzureProfile) error
 	SelectAzureProfile(profileId string, selected bool) error
 	GetSelectedAzureProfiles() ([]primitive.ObjectID, error)
+	AddAzureRecommendations(recommendations []model.AzureRecommendation) error
+	AddAzureRecommendationError(recommendationError model.AzureRecommendationError) error
+	GetLastAzureSeqValue() (uint64, error)
+	GetAzureRecommendationsByProfiles(profileIDs []primitive.ObjectID) ([]model.AzureRecommendation, error)
+	GetAzureRecommendationErrors(seqValue uint64) ([]model.AzureRecommendationError, error)
 	GetLastAwsRDSSeqValue() (uint64, error)
 	GetAwsRDS() ([]model.AwsRDS, error)
 
@@ -92,6 +98,8 @@ type MongoDatabaseInterface interface {
 	AddGcpRecommendation(gcprecommendation interface{}) error
 	AddGcpError(gcperror interface{}) error
 	ListGcpErrorsByProfiles(profileIDs []primitive.ObjectID) ([]model.GcpError, error)
+
+	RotateCloudSecrets() (int, error)
 }
 
 // MongoDatabase is a implementation
@@ -100,11 +108,14 @@ type MongoDatabase struct {
 	Client  *mongo.Client
 	TimeNow func() time.Time
 	Log     logger.Logger
+	// Keyring encrypts the credentials of the cloud profiles, they are stored in clear when it's nil
+	Keyring *cr.Keyring
 }
 
 // Init initializes the connection to the database
 func (md *MongoDatabase) Init() {
 	md.ConnectToMongodb()
+	md.LoadKeyring()
 
 	md.Log.Debug("MongoDatabase is connected to MongoDB! ", utils.HideMongoDBPassword(md.Config.Mongodb.URI))
 }
@@ -30,7 +30,9 @@ func (md *MongoDatabase) ListGcpProfiles() ([]model.GcpProfile, error) {
 
 	result := make([]model.GcpProfile, 0)
 
-	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(GcpProfileCollection).Aggregate(ctx, bson.A{})
+	cur, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(GcpProfileCollection).Aggregate(ctx, bson.A{
+		bson.M{"$project": bson.M{"privatekey": 0}},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +45,13 @@ func (md *MongoDatabase) ListGcpProfiles() ([]model.GcpProfile, error) {
 }
 
 func (md *MongoDatabase) AddGcpProfile(profile model.GcpProfile) error {
-	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(GcpProfileCollection).
+	var err error
+
+	if profile.PrivateKey, err = md.Keyring.Encrypt(profile.PrivateKey); err != nil {
+		return utils.NewError(err, "Unable to encrypt secret")
+	}
+
+	_, err = md.Client.Database(md.Config.Mongodb.DBName).Collection(GcpProfileCollection).
 		InsertOne(context.Background(), profile)
 	if err != nil {
 		return utils.NewError(err, "DB ERROR")
@@ -65,6 +73,12 @@ func (md *MongoDatabase) GetActiveGcpProfiles() ([]model.GcpProfile, error) {
 		return nil, err
 	}
 
+	for i := range result {
+		if result[i].PrivateKey, err = md.Keyring.Decrypt(result[i].PrivateKey); err != nil {
+			return nil, utils.NewError(err, "Unable to decrypt secret")
+		}
+	}
+
 	return result, nil
 }
 
@@ -78,15 +92,26 @@ func (md *MongoDatabase) SelectGcpProfile(id primitive.ObjectID, selected bool)
 }
 
 func (md *MongoDatabase) UpdateGcpProfile(id primitive.ObjectID, profile model.GcpProfile) error {
+	set := bson.D{
+		primitive.E{Key: "name", Value: profile.Name},
+		primitive.E{Key: "clientemail", Value: profile.ClientEmail},
+	}
+
+	// the private key is write-only: an empty one keeps the stored key
+	if profile.PrivateKey != "" {
+		privateKey, err := md.Keyring.Encrypt(profile.PrivateKey)
+		if err != nil {
+			return utils.NewError(err, "Unable to encrypt secret")
+		}
+
+		set = append(set, primitive.E{Key: "privatekey", Value: privateKey})
+	}
+
 	if _, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(GcpProfileCollection).
 		UpdateOne(
 			context.TODO(),
 			bson.M{"_id": id},
-			bson.D{{Key: "$set", Value: bson.D{
-				primitive.E{Key: "name", Value: profile.Name},
-				primitive.E{Key: "privatekey", Value: profile.PrivateKey},
-				primitive.E{Key: "clientemail", Value: profile.ClientEmail},
-			}}},
+			bson.D{{Key: "$set", Value: set}},
 		); err != nil {
 		return err
 	}
@@ -30,7 +30,13 @@ import (
 const OciProfile_collection = "oci_profiles"
 
 func (md *MongoDatabase) AddOciProfile(profile model.OciProfile) error {
-	_, err := md.Client.Database(md.Config.Mongodb.DBName).Collection(OciProfile_collection).
+	var err error
+
+	if profile.PrivateKey, err = md.encryptSecret(profile.PrivateKey); err != nil {
+		return err
+	}
+
+	_, err = md.Client.Database(md.Config.Mongodb.DBName).Collection(OciProfile_collection).
 		InsertOne(
 			context.TODO(),
 			profile,
@@ -49,6 +55,10 @@ func (md *MongoDatabase) UpdateOciProfile(profile model.OciProfile) error {
 
 	var p bson.M
 
+	if profile.PrivateKey, err = md.encryptSecret(profile.PrivateKey); err != nil {
+		return err
+	}
+
 	data, err := bson.Marshal(profile)
 	if err != nil {
 		return utils.NewError(err, "Unable to mashal profile")
@@ -98,6 +108,12 @@ func (md *MongoDatabase) GetOciProfiles(hidePrivateKey bool) ([]model.OciProfile
 		return nil, utils.NewError(err, "DB ERROR")
 	}
 
+	for i := range profiles {
+		if profiles[i].PrivateKey, err = md.decryptSecret(profiles[i].PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+
 	return profiles, nil
 }
 
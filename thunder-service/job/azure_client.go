@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ercole-io/ercole/v2/model"
+)
+
+const (
+	azureLoginURL      = "https://login.microsoftonline.com"
+	azureManagementURL = "https://management.azure.com"
+)
+
+// azureClient is a minimal client of the Azure Resource Manager REST API, authenticated with the service principal of a profile
+type azureClient struct {
+	httpClient     *http.Client
+	managementURL  string
+	subscriptionID string
+	token          string
+}
+
+func newAzureClient(httpClient *http.Client, loginURL, managementURL string, profile model.AzureProfile) (*azureClient, error) {
+	if profile.ClientSecret == nil {
+		return nil, fmt.Errorf("missing client secret")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", profile.ClientId)
+	form.Set("client_secret", *profile.ClientSecret)
+	form.Set("scope", managementURL+"/.default")
+
+	resp, err := httpClient.PostForm(fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginURL, url.PathEscape(profile.TenantId)), form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get the access token: %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &azureClient{
+		httpClient:     httpClient,
+		managementURL:  managementURL,
+		subscriptionID: profile.SubscriptionId,
+		token:          token.AccessToken,
+	}, nil
+}
+
+func (c *azureClient) get(uri string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", req.URL.Path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// listResources return all the resources of the provider in the subscription, following the pages
+func (c *azureClient) listResources(provider, apiVersion string, query url.Values, out func(json.RawMessage) error) error {
+	if query == nil {
+		query = url.Values{}
+	}
+
+	query.Set("api-version", apiVersion)
+
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/%s?%s", c.managementURL, url.PathEscape(c.subscriptionID), provider, query.Encode())
+
+	for next != "" {
+		var page struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink string            `json:"nextLink"`
+		}
+
+		if err := c.get(next, &page); err != nil {
+			return err
+		}
+
+		for _, v := range page.Value {
+			if err := out(v); err != nil {
+				return err
+			}
+		}
+
+		next = page.NextLink
+	}
+
+	return nil
+}
+
+type azureMetricValue struct {
+	Average *float64 `json:"average"`
+	Maximum *float64 `json:"maximum"`
+}
+
+// getMetric return the datapoints of a metric of the resource from Azure Monitor
+func (c *azureClient) getMetric(resourceID, metric, timespan, interval string) ([]azureMetricValue, error) {
+	query := url.Values{}
+	query.Set("api-version", "2018-01-01")
+	query.Set("metricnames", metric)
+	query.Set("timespan", timespan)
+	query.Set("interval", interval)
+	query.Set("aggregation", "Average,Maximum")
+
+	var res struct {
+		Value []struct {
+			Timeseries []struct {
+				Data []azureMetricValue `json:"data"`
+			} `json:"timeseries"`
+		} `json:"value"`
+	}
+
+	if err := c.get(fmt.Sprintf("%s%s/providers/Microsoft.Insights/metrics?%s", c.managementURL, resourceID, query.Encode()), &res); err != nil {
+		return nil, err
+	}
+
+	values := make([]azureMetricValue, 0)
+
+	for _, v := range res.Value {
+		for _, ts := range v.Timeseries {
+			values = append(values, ts.Data...)
+		}
+	}
+
+	return values, nil
+}
+
+// azureResourceGroup return the resource group contained in the id of a resource
+func azureResourceGroup(resourceID string) string {
+	parts := strings.Split(resourceID, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+
+	return ""
+}
+
+// azureSameLocation compare an Azure location with the region of a profile, an empty region matches every location
+func azureSameLocation(region, location string) bool {
+	if region == "" {
+		return true
+	}
+
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	}
+
+	return normalize(region) == normalize(location)
+}
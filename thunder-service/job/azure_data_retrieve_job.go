@@ -0,0 +1,355 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/thunder-service/database"
+)
+
+const defaultAzureMetricsDays = 7
+
+type AzureDataRetrieveJob struct {
+	Database database.MongoDatabaseInterface
+	Config   config.Configuration
+	TimeNow  func() time.Time
+	Log      logger.Logger
+	// HTTPClient, LoginURL and ManagementURL are used to contact Azure, the public cloud is used when they are empty
+	HTTPClient    *http.Client
+	LoginURL      string
+	ManagementURL string
+}
+
+type azureFetchFunc func(client *azureClient, profile model.AzureProfile, seqValue uint64) ([]model.AzureRecommendation, error)
+
+func (job *AzureDataRetrieveJob) Run() {
+	profiles, err := job.Database.GetAzureProfiles(false)
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	seqValue, err := job.Database.GetLastAzureSeqValue()
+	if err != nil {
+		job.Log.Error(err)
+		return
+	}
+
+	seqValue = seqValue + 1
+
+	for _, profile := range profiles {
+		if !profile.Selected {
+			continue
+		}
+
+		job.retrieveProfile(profile, seqValue)
+	}
+}
+
+func (job *AzureDataRetrieveJob) retrieveProfile(profile model.AzureProfile, seqValue uint64) {
+	httpClient := job.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Minute}
+	}
+
+	loginURL, managementURL := job.LoginURL, job.ManagementURL
+	if loginURL == "" {
+		loginURL = azureLoginURL
+	}
+
+	if managementURL == "" {
+		managementURL = azureManagementURL
+	}
+
+	client, err := newAzureClient(httpClient, loginURL, managementURL, profile)
+	if err != nil {
+		job.addError(profile, seqValue, model.AzureAuthentication, err)
+		return
+	}
+
+	fetches := []struct {
+		objectType string
+		fetch      azureFetchFunc
+	}{
+		{model.AzureManagedDisk, job.FetchAzureUnusedDisks},
+		{model.AzurePublicIP, job.FetchAzureUnattachedPublicIPs},
+		{model.AzureVirtualMachine, job.FetchAzureVirtualMachines},
+	}
+
+	recommendations := make([]model.AzureRecommendation, 0)
+
+	for _, f := range fetches {
+		res, err := f.fetch(client, profile, seqValue)
+		if err != nil {
+			job.addError(profile, seqValue, f.objectType, err)
+			continue
+		}
+
+		recommendations = append(recommendations, res...)
+	}
+
+	if err := job.Database.AddAzureRecommendations(recommendations); err != nil {
+		job.Log.Error(err)
+	}
+}
+
+func (job *AzureDataRetrieveJob) addError(profile model.AzureProfile, seqValue uint64, category string, err error) {
+	job.Log.Warnf("Azure profile %s, %s: %s", profile.Name, category, err)
+
+	recommendationError := model.AzureRecommendationError{
+		SeqValue:  seqValue,
+		ProfileID: profile.ID,
+		Category:  category,
+		Error:     err.Error(),
+		CreatedAt: job.TimeNow().UTC(),
+	}
+
+	if errDb := job.Database.AddAzureRecommendationError(recommendationError); errDb != nil {
+		job.Log.Error(errDb)
+	}
+}
+
+func (job *AzureDataRetrieveJob) newRecommendation(profile model.AzureProfile, seqValue uint64,
+	category, suggestion, objectType, name, resourceID string, details map[string]interface{}) model.AzureRecommendation {
+	details["RESOURCE_GROUP"] = azureResourceGroup(resourceID)
+
+	return model.AzureRecommendation{
+		SeqValue:   seqValue,
+		ProfileID:  profile.ID,
+		Category:   category,
+		Suggestion: suggestion,
+		Name:       name,
+		ResourceID: resourceID,
+		ObjectType: objectType,
+		Details:    details,
+		CreatedAt:  job.TimeNow().UTC(),
+	}
+}
+
+// FetchAzureUnusedDisks return the managed disks that aren't attached to any virtual machine
+func (job *AzureDataRetrieveJob) FetchAzureUnusedDisks(client *azureClient, profile model.AzureProfile, seqValue uint64) ([]model.AzureRecommendation, error) {
+	type disk struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Location string `json:"location"`
+		Sku      struct {
+			Name string `json:"name"`
+		} `json:"sku"`
+		ManagedBy  string `json:"managedBy"`
+		Properties struct {
+			DiskSizeGB int    `json:"diskSizeGB"`
+			DiskState  string `json:"diskState"`
+		} `json:"properties"`
+	}
+
+	res := make([]model.AzureRecommendation, 0)
+
+	err := client.listResources("Microsoft.Compute/disks", "2023-04-02", nil, func(raw json.RawMessage) error {
+		var d disk
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return err
+		}
+
+		if !azureSameLocation(profile.Region, d.Location) || d.ManagedBy != "" || d.Properties.DiskState != "Unattached" {
+			return nil
+		}
+
+		res = append(res, job.newRecommendation(profile, seqValue,
+			model.AzureUnusedResource, model.AzureDeleteManagedDiskNotAttached, model.AzureManagedDisk, d.Name, d.ID,
+			map[string]interface{}{
+				"DISK_NAME": d.Name,
+				"SIZE_GB":   d.Properties.DiskSizeGB,
+				"SKU":       d.Sku.Name,
+				"LOCATION":  d.Location,
+				"ATTACHED":  "No",
+			}))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FetchAzureUnattachedPublicIPs return the public IP addresses that aren't associated to any resource
+func (job *AzureDataRetrieveJob) FetchAzureUnattachedPublicIPs(client *azureClient, profile model.AzureProfile, seqValue uint64) ([]model.AzureRecommendation, error) {
+	type publicIP struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Location   string `json:"location"`
+		Properties struct {
+			IPAddress       string           `json:"ipAddress"`
+			AllocationType  string           `json:"publicIPAllocationMethod"`
+			IPConfiguration *json.RawMessage `json:"ipConfiguration"`
+			NatGateway      *json.RawMessage `json:"natGateway"`
+		} `json:"properties"`
+	}
+
+	res := make([]model.AzureRecommendation, 0)
+
+	err := client.listResources("Microsoft.Network/publicIPAddresses", "2023-09-01", nil, func(raw json.RawMessage) error {
+		var ip publicIP
+		if err := json.Unmarshal(raw, &ip); err != nil {
+			return err
+		}
+
+		if !azureSameLocation(profile.Region, ip.Location) || ip.Properties.IPConfiguration != nil || ip.Properties.NatGateway != nil {
+			return nil
+		}
+
+		res = append(res, job.newRecommendation(profile, seqValue,
+			model.AzureUnusedResource, model.AzureDeletePublicIPNotAssociated, model.AzurePublicIP, ip.Name, ip.ID,
+			map[string]interface{}{
+				"IP_NAME":         ip.Name,
+				"IP_ADDRESS":      ip.Properties.IPAddress,
+				"ALLOCATION_TYPE": ip.Properties.AllocationType,
+				"LOCATION":        ip.Location,
+			}))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FetchAzureVirtualMachines return the virtual machines stopped but still allocated, that are still billed,
+// and the running ones with a low cpu usage in the Azure Monitor metrics
+func (job *AzureDataRetrieveJob) FetchAzureVirtualMachines(client *azureClient, profile model.AzureProfile, seqValue uint64) ([]model.AzureRecommendation, error) {
+	type virtualMachine struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Location   string `json:"location"`
+		Properties struct {
+			HardwareProfile struct {
+				VMSize string `json:"vmSize"`
+			} `json:"hardwareProfile"`
+			InstanceView struct {
+				Statuses []struct {
+					Code string `json:"code"`
+				} `json:"statuses"`
+			} `json:"instanceView"`
+		} `json:"properties"`
+	}
+
+	days := job.Config.ThunderService.AzureDataRetrieveJob.MetricsDays
+	if days <= 0 {
+		days = defaultAzureMetricsDays
+	}
+
+	now := job.TimeNow().UTC()
+	timespan := fmt.Sprintf("%s/%s", now.AddDate(0, 0, -days).Format(time.RFC3339), now.Format(time.RFC3339))
+
+	res := make([]model.AzureRecommendation, 0)
+
+	err := client.listResources("Microsoft.Compute/virtualMachines", "2023-09-01", url.Values{"statusOnly": {"true"}}, func(raw json.RawMessage) error {
+		var vm virtualMachine
+		if err := json.Unmarshal(raw, &vm); err != nil {
+			return err
+		}
+
+		if !azureSameLocation(profile.Region, vm.Location) {
+			return nil
+		}
+
+		var powerState string
+
+		for _, s := range vm.Properties.InstanceView.Statuses {
+			if strings.HasPrefix(s.Code, "PowerState/") {
+				powerState = strings.TrimPrefix(s.Code, "PowerState/")
+			}
+		}
+
+		switch powerState {
+		case "stopped":
+			res = append(res, job.newRecommendation(profile, seqValue,
+				model.AzureNotActiveResource, model.AzureDeallocateStoppedVM, model.AzureVirtualMachine, vm.Name, vm.ID,
+				map[string]interface{}{
+					"VM_NAME":     vm.Name,
+					"SIZE":        vm.Properties.HardwareProfile.VMSize,
+					"POWER_STATE": powerState,
+					"LOCATION":    vm.Location,
+				}))
+		case "running":
+			values, err := client.getMetric(vm.ID, "Percentage CPU", timespan, "PT1H")
+			if err != nil {
+				job.addError(profile, seqValue, model.AzureComputeInstanceRightsizing, fmt.Errorf("%s: %w", vm.Name, err))
+				return nil
+			}
+
+			avgCpu, maxCpu, ok := azureCpuUsage(values)
+			if !ok || avgCpu >= job.Config.ThunderService.AzureDataRetrieveJob.AvgCpuPercentage ||
+				maxCpu >= job.Config.ThunderService.AzureDataRetrieveJob.MaxCpuPercentage {
+				return nil
+			}
+
+			res = append(res, job.newRecommendation(profile, seqValue,
+				model.AzureComputeInstanceRightsizing, model.AzureResizeOversizedVM, model.AzureVirtualMachine, vm.Name, vm.ID,
+				map[string]interface{}{
+					"VM_NAME":            vm.Name,
+					"SIZE":               vm.Properties.HardwareProfile.VMSize,
+					"AVG_CPU_PERCENTAGE": avgCpu,
+					"MAX_CPU_PERCENTAGE": maxCpu,
+					"METRICS_DAYS":       days,
+					"LOCATION":           vm.Location,
+				}))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// azureCpuUsage return the average and the maximum of the datapoints, ok is false if there aren't datapoints
+func azureCpuUsage(values []azureMetricValue) (avg float64, maximum float64, ok bool) {
+	var sum float64
+
+	var count int
+
+	for _, v := range values {
+		if v.Average != nil {
+			sum += *v.Average
+			count++
+		}
+
+		if v.Maximum != nil && *v.Maximum > maximum {
+			maximum = *v.Maximum
+		}
+	}
+
+	if count == 0 {
+		return 0, 0, false
+	}
+
+	return sum / float64(count), maximum, true
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package job
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const azureTestSubscription = "/subscriptions/sub1/providers/"
+
+func newMockAzure(t *testing.T, virtualMachinesStatus int) *httptest.Server {
+	write := func(w http.ResponseWriter, v interface{}) {
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant1/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		write(w, map[string]string{"access_token": "token"})
+	})
+	mux.HandleFunc(azureTestSubscription+"Microsoft.Compute/disks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		if r.URL.Query().Get("page") == "" {
+			write(w, map[string]interface{}{
+				"value": []interface{}{
+					map[string]interface{}{
+						"id": "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/disks/disk1", "name": "disk1", "location": "westeurope",
+						"sku": map[string]string{"name": "Premium_LRS"}, "properties": map[string]interface{}{"diskSizeGB": 128, "diskState": "Unattached"},
+					},
+					map[string]interface{}{
+						"id": "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/disks/disk2", "name": "disk2", "location": "westeurope",
+						"managedBy": "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm1",
+						"properties": map[string]interface{}{"diskSizeGB": 64, "diskState": "Attached"},
+					},
+				},
+				"nextLink": "http://" + r.Host + r.URL.Path + "?page=2",
+			})
+
+			return
+		}
+
+		write(w, map[string]interface{}{
+			"value": []interface{}{
+				map[string]interface{}{
+					"id": "/subscriptions/sub1/resourceGroups/rg2/providers/Microsoft.Compute/disks/disk3", "name": "disk3", "location": "northeurope",
+					"properties": map[string]interface{}{"diskSizeGB": 32, "diskState": "Unattached"},
+				},
+			},
+		})
+	})
+	mux.HandleFunc(azureTestSubscription+"Microsoft.Network/publicIPAddresses", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]interface{}{
+			"value": []interface{}{
+				map[string]interface{}{
+					"id": "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Network/publicIPAddresses/ip1", "name": "ip1", "location": "westeurope",
+					"properties": map[string]interface{}{"ipAddress": "20.1.2.3", "publicIPAllocationMethod": "Static"},
+				},
+				map[string]interface{}{
+					"id": "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Network/publicIPAddresses/ip2", "name": "ip2", "location": "westeurope",
+					"properties": map[string]interface{}{"ipAddress": "20.1.2.4", "ipConfiguration": map[string]string{"id": "nic1"}},
+				},
+			},
+		})
+	})
+	mux.HandleFunc(azureTestSubscription+"Microsoft.Compute/virtualMachines", func(w http.ResponseWriter, r *http.Request) {
+		if virtualMachinesStatus != http.StatusOK {
+			w.WriteHeader(virtualMachinesStatus)
+			return
+		}
+
+		assert.Equal(t, "true", r.URL.Query().Get("statusOnly"))
+
+		vm := func(name, size, powerState string) map[string]interface{} {
+			return map[string]interface{}{
+				"id": "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/" + name, "name": name, "location": "westeurope",
+				"properties": map[string]interface{}{
+					"hardwareProfile": map[string]string{"vmSize": size},
+					"instanceView": map[string]interface{}{"statuses": []map[string]string{
+						{"code": "ProvisioningState/succeeded"}, {"code": "PowerState/" + powerState},
+					}},
+				},
+			}
+		}
+
+		write(w, map[string]interface{}{
+			"value": []interface{}{
+				vm("stopped", "Standard_D4s_v3", "stopped"),
+				vm("deallocated", "Standard_D4s_v3", "deallocated"),
+				vm("idle", "Standard_D8s_v3", "running"),
+				vm("busy", "Standard_D2s_v3", "running"),
+			},
+		})
+	})
+	mux.HandleFunc("/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/idle/providers/Microsoft.Insights/metrics", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Percentage CPU", r.URL.Query().Get("metricnames"))
+		assert.Equal(t, "2024-05-01T12:00:00Z/2024-05-08T12:00:00Z", r.URL.Query().Get("timespan"))
+
+		write(w, map[string]interface{}{"value": []interface{}{map[string]interface{}{"timeseries": []interface{}{map[string]interface{}{
+			"data": []map[string]float64{{"average": 2, "maximum": 10}, {"average": 4, "maximum": 20}},
+		}}}}})
+	})
+	mux.HandleFunc("/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/busy/providers/Microsoft.Insights/metrics", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]interface{}{"value": []interface{}{map[string]interface{}{"timeseries": []interface{}{map[string]interface{}{
+			"data": []map[string]float64{{"average": 60, "maximum": 95}},
+		}}}}})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestAzureDataRetrieveJob(db *MockMongoDatabaseInterface, server *httptest.Server) *AzureDataRetrieveJob {
+	return &AzureDataRetrieveJob{
+		Database: db,
+		Config: config.Configuration{
+			ThunderService: config.ThunderService{
+				AzureDataRetrieveJob: config.AzureDataRetrieveJob{
+					MetricsDays:      7,
+					AvgCpuPercentage: 10,
+					MaxCpuPercentage: 50,
+				},
+			},
+		},
+		TimeNow:       utils.Btc(utils.P("2024-05-08T12:00:00Z")),
+		Log:           logger.NewLogger("TEST"),
+		HTTPClient:    server.Client(),
+		LoginURL:      server.URL,
+		ManagementURL: server.URL,
+	}
+}
+
+func TestAzureDataRetrieveJob_Run(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	server := newMockAzure(t, http.StatusOK)
+	job := newTestAzureDataRetrieveJob(db, server)
+
+	secret := "secret"
+	profile := model.AzureProfile{
+		ID:             utils.Str2oid("000000000000000000000001"),
+		TenantId:       "tenant1",
+		ClientId:       "client1",
+		ClientSecret:   &secret,
+		SubscriptionId: "sub1",
+		Region:         "West Europe",
+		Selected:       true,
+		Name:           "profile1",
+	}
+	notSelected := profile
+	notSelected.ID = utils.Str2oid("000000000000000000000002")
+	notSelected.Selected = false
+
+	createdAt := utils.P("2024-05-08T12:00:00Z")
+
+	expected := []model.AzureRecommendation{
+		{
+			SeqValue: 8, ProfileID: profile.ID, Category: model.AzureUnusedResource, Suggestion: model.AzureDeleteManagedDiskNotAttached,
+			Name: "disk1", ResourceID: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/disks/disk1", ObjectType: model.AzureManagedDisk,
+			Details: map[string]interface{}{
+				"DISK_NAME": "disk1", "SIZE_GB": 128, "SKU": "Premium_LRS", "LOCATION": "westeurope", "ATTACHED": "No", "RESOURCE_GROUP": "rg1",
+			},
+			CreatedAt: createdAt,
+		},
+		{
+			SeqValue: 8, ProfileID: profile.ID, Category: model.AzureUnusedResource, Suggestion: model.AzureDeletePublicIPNotAssociated,
+			Name: "ip1", ResourceID: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Network/publicIPAddresses/ip1", ObjectType: model.AzurePublicIP,
+			Details: map[string]interface{}{
+				"IP_NAME": "ip1", "IP_ADDRESS": "20.1.2.3", "ALLOCATION_TYPE": "Static", "LOCATION": "westeurope", "RESOURCE_GROUP": "rg1",
+			},
+			CreatedAt: createdAt,
+		},
+		{
+			SeqValue: 8, ProfileID: profile.ID, Category: model.AzureNotActiveResource, Suggestion: model.AzureDeallocateStoppedVM,
+			Name: "stopped", ResourceID: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/stopped", ObjectType: model.AzureVirtualMachine,
+			Details: map[string]interface{}{
+				"VM_NAME": "stopped", "SIZE": "Standard_D4s_v3", "POWER_STATE": "stopped", "LOCATION": "westeurope", "RESOURCE_GROUP": "rg1",
+			},
+			CreatedAt: createdAt,
+		},
+		{
+			SeqValue: 8, ProfileID: profile.ID, Category: model.AzureComputeInstanceRightsizing, Suggestion: model.AzureResizeOversizedVM,
+			Name: "idle", ResourceID: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/idle", ObjectType: model.AzureVirtualMachine,
+			Details: map[string]interface{}{
+				"VM_NAME": "idle", "SIZE": "Standard_D8s_v3", "AVG_CPU_PERCENTAGE": float64(3), "MAX_CPU_PERCENTAGE": float64(20),
+				"METRICS_DAYS": 7, "LOCATION": "westeurope", "RESOURCE_GROUP": "rg1",
+			},
+			CreatedAt: createdAt,
+		},
+	}
+
+	gomock.InOrder(
+		db.EXPECT().GetAzureProfiles(false).Return([]model.AzureProfile{profile, notSelected}, nil),
+		db.EXPECT().GetLastAzureSeqValue().Return(uint64(7), nil),
+		db.EXPECT().AddAzureRecommendations(expected).Return(nil),
+	)
+
+	job.Run()
+}
+
+func TestAzureDataRetrieveJob_Run_Errors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	server := newMockAzure(t, http.StatusForbidden)
+	job := newTestAzureDataRetrieveJob(db, server)
+
+	secret, wrongSecret := "secret", "wrong"
+	profile := model.AzureProfile{
+		ID: utils.Str2oid("000000000000000000000001"), TenantId: "tenant1", ClientSecret: &secret,
+		SubscriptionId: "sub1", Region: "northeurope", Selected: true,
+	}
+	wrongProfile := model.AzureProfile{
+		ID: utils.Str2oid("000000000000000000000002"), TenantId: "tenant1", ClientSecret: &wrongSecret,
+		SubscriptionId: "sub1", Selected: true,
+	}
+
+	createdAt := utils.P("2024-05-08T12:00:00Z")
+
+	db.EXPECT().GetAzureProfiles(false).Return([]model.AzureProfile{profile, wrongProfile}, nil)
+	db.EXPECT().GetLastAzureSeqValue().Return(uint64(0), nil)
+	db.EXPECT().AddAzureRecommendationError(gomock.Any()).DoAndReturn(func(e model.AzureRecommendationError) error {
+		assert.Equal(t, uint64(1), e.SeqValue)
+		assert.Equal(t, profile.ID, e.ProfileID)
+		assert.Equal(t, model.AzureVirtualMachine, e.Category)
+		assert.Contains(t, e.Error, "403")
+		assert.Equal(t, createdAt, e.CreatedAt)
+
+		return nil
+	})
+	db.EXPECT().AddAzureRecommendations(gomock.Any()).DoAndReturn(func(recs []model.AzureRecommendation) error {
+		require.Len(t, recs, 1)
+		assert.Equal(t, "disk3", recs[0].Name)
+
+		return nil
+	})
+	db.EXPECT().AddAzureRecommendationError(gomock.Any()).DoAndReturn(func(e model.AzureRecommendationError) error {
+		assert.Equal(t, wrongProfile.ID, e.ProfileID)
+		assert.Equal(t, model.AzureAuthentication, e.Category)
+
+		return nil
+	})
+
+	job.Run()
+}
+
+func TestAzureDataRetrieveJob_Run_DatabaseError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+
+	job := &AzureDataRetrieveJob{Database: db, Log: logger.NewLogger("TEST")}
+
+	db.EXPECT().GetAzureProfiles(false).Return(nil, aerrMock)
+
+	job.Run()
+}
@@ -76,4 +76,13 @@ func (j *Job) Init() {
 	if j.Config.ThunderService.GcpDataRetrieveJob.RunAtStartup {
 		jobrunner.Now(&gcpDataRetrieverJob)
 	}
+
+	azureDataRetrieveJob := &AzureDataRetrieveJob{Database: j.Database, Config: j.Config, TimeNow: j.TimeNow, Log: j.Log}
+	if err := jobrunner.Schedule(j.Config.ThunderService.AzureDataRetrieveJob.Crontab, azureDataRetrieveJob); err != nil {
+		j.Log.Errorf("Something went wrong scheduling AzureDataRetrieveJob: %v", err)
+	}
+
+	if j.Config.ThunderService.AzureDataRetrieveJob.RunAtStartup {
+		jobrunner.Now(azureDataRetrieveJob)
+	}
 }
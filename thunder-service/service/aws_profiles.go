@@ -29,6 +29,9 @@ func (ts *ThunderService) AddAwsProfile(profile model.AwsProfile) (*model.AwsPro
 		return nil, err
 	}
 
+	// the secret is write-only
+	profile.SecretAccessKey = nil
+
 	return &profile, nil
 }
 func (ts *ThunderService) UpdateAwsProfile(profile model.AwsProfile) (*model.AwsProfile, error) {
@@ -36,6 +39,8 @@ func (ts *ThunderService) UpdateAwsProfile(profile model.AwsProfile) (*model.Aws
 		return nil, err
 	}
 
+	profile.SecretAccessKey = nil
+
 	return &profile, nil
 }
 func (ts *ThunderService) GetAwsProfiles() ([]model.AwsProfile, error) {
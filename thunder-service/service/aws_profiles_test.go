@@ -63,6 +63,7 @@ func TestAddAwsProfile(t *testing.T) {
 		actual, err := as.AddAwsProfile(profile)
 		require.NoError(t, err)
 
+		expected.SecretAccessKey = nil
 		assert.Equal(t, &expected, actual)
 	})
 
@@ -116,6 +117,7 @@ func TestUpdateAwsProfile(t *testing.T) {
 
 		actual, err := as.UpdateAwsProfile(profile)
 		require.NoError(t, err)
+		profile.SecretAccessKey = nil
 		assert.Equal(t, profile, *actual)
 	})
 
@@ -29,6 +29,9 @@ func (ts *ThunderService) AddAzureProfile(profile model.AzureProfile) (*model.Az
 		return nil, err
 	}
 
+	// the secret is write-only
+	profile.ClientSecret = nil
+
 	return &profile, nil
 }
 func (ts *ThunderService) UpdateAzureProfile(profile model.AzureProfile) (*model.AzureProfile, error) {
@@ -36,6 +39,8 @@ func (ts *ThunderService) UpdateAzureProfile(profile model.AzureProfile) (*model
 		return nil, err
 	}
 
+	profile.ClientSecret = nil
+
 	return &profile, nil
 }
 func (ts *ThunderService) GetAzureProfiles() ([]model.AzureProfile, error) {
@@ -67,6 +67,7 @@ func TestAddAzureProfile(t *testing.T) {
 		actual, err := as.AddAzureProfile(profile)
 		require.NoError(t, err)
 
+		expected.ClientSecret = nil
 		assert.Equal(t, &expected, actual)
 	})
 
@@ -122,6 +123,7 @@ func TestUpdateAzureProfile(t *testing.T) {
 
 		actual, err := as.UpdateAzureProfile(profile)
 		require.NoError(t, err)
+		profile.ClientSecret = nil
 		assert.Equal(t, profile, *actual)
 	})
 
@@ -0,0 +1,103 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"github.com/360EntSecGroup-Skylar/excelize"
+
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils/exutils"
+)
+
+func (ts *ThunderService) GetAzureRecommendations() ([]model.AzureRecommendation, error) {
+	selectedProfiles, err := ts.Database.GetSelectedAzureProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(selectedProfiles) == 0 {
+		return []model.AzureRecommendation{}, nil
+	}
+
+	return ts.Database.GetAzureRecommendationsByProfiles(selectedProfiles)
+}
+
+func (ts *ThunderService) GetLastAzureRecommendationErrors() ([]model.AzureRecommendationError, error) {
+	seqValue, err := ts.Database.GetLastAzureSeqValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.Database.GetAzureRecommendationErrors(seqValue)
+}
+
+func (ts *ThunderService) GetAzureRecommendationErrorsBySeqValue(seqValue uint64) ([]model.AzureRecommendationError, error) {
+	return ts.Database.GetAzureRecommendationErrors(seqValue)
+}
+
+func (ts *ThunderService) CreateAzureRecommendationsXlsx() (*excelize.File, error) {
+	recommendations, err := ts.GetAzureRecommendations()
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := "Azure recommendations"
+	headers := []string{
+		"Profile",
+		"Category",
+		"Object Type",
+		"Suggestion",
+		"Name",
+		"Resource Group",
+		"Location",
+		"Size",
+		"Avg Cpu %",
+		"Max Cpu %",
+		"IP Address",
+		"Resource ID",
+	}
+
+	sheets, err := exutils.NewXLSX(ts.Config, sheet, headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	axisHelp := exutils.NewAxisHelper(1)
+
+	for _, val := range recommendations {
+		nextAxis := axisHelp.NewRow()
+
+		size := val.Details["SIZE"]
+		if size == nil {
+			size = val.Details["SIZE_GB"]
+		}
+
+		sheets.SetCellValue(sheet, nextAxis(), val.ProfileName)
+		sheets.SetCellValue(sheet, nextAxis(), val.Category)
+		sheets.SetCellValue(sheet, nextAxis(), val.ObjectType)
+		sheets.SetCellValue(sheet, nextAxis(), val.Suggestion)
+		sheets.SetCellValue(sheet, nextAxis(), val.Name)
+		sheets.SetCellValue(sheet, nextAxis(), val.Details["RESOURCE_GROUP"])
+		sheets.SetCellValue(sheet, nextAxis(), val.Details["LOCATION"])
+		sheets.SetCellValue(sheet, nextAxis(), size)
+		sheets.SetCellValue(sheet, nextAxis(), val.Details["AVG_CPU_PERCENTAGE"])
+		sheets.SetCellValue(sheet, nextAxis(), val.Details["MAX_CPU_PERCENTAGE"])
+		sheets.SetCellValue(sheet, nextAxis(), val.Details["IP_ADDRESS"])
+		sheets.SetCellValue(sheet, nextAxis(), val.ResourceID)
+	}
+
+	return sheets, nil
+}
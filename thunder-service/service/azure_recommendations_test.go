@@ -0,0 +1,186 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ercole-io/ercole/v2/config"
+	"github.com/ercole-io/ercole/v2/logger"
+	"github.com/ercole-io/ercole/v2/model"
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+var testAzureRecommendations = []model.AzureRecommendation{
+	{
+		SeqValue:    2,
+		ProfileID:   utils.Str2oid("000000000000000000000001"),
+		ProfileName: "profile1",
+		Category:    model.AzureUnusedResource,
+		Suggestion:  model.AzureDeleteManagedDiskNotAttached,
+		Name:        "disk1",
+		ResourceID:  "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/disks/disk1",
+		ObjectType:  model.AzureManagedDisk,
+		Details: map[string]interface{}{
+			"RESOURCE_GROUP": "rg1",
+			"LOCATION":       "westeurope",
+			"SIZE_GB":        128,
+		},
+		CreatedAt: utils.P("2024-05-08T12:00:00Z"),
+	},
+	{
+		SeqValue:    2,
+		ProfileID:   utils.Str2oid("000000000000000000000001"),
+		ProfileName: "profile1",
+		Category:    model.AzureComputeInstanceRightsizing,
+		Suggestion:  model.AzureResizeOversizedVM,
+		Name:        "vm1",
+		ResourceID:  "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm1",
+		ObjectType:  model.AzureVirtualMachine,
+		Details: map[string]interface{}{
+			"RESOURCE_GROUP":     "rg1",
+			"LOCATION":           "westeurope",
+			"SIZE":               "Standard_D8s_v3",
+			"AVG_CPU_PERCENTAGE": 3.5,
+			"MAX_CPU_PERCENTAGE": 20.0,
+		},
+		CreatedAt: utils.P("2024-05-08T12:00:00Z"),
+	},
+}
+
+func TestGetAzureRecommendations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	ts := ThunderService{
+		Database: db,
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		selected := []primitive.ObjectID{utils.Str2oid("000000000000000000000001")}
+
+		db.EXPECT().GetSelectedAzureProfiles().Return(selected, nil)
+		db.EXPECT().GetAzureRecommendationsByProfiles(selected).Return(testAzureRecommendations, nil)
+
+		res, err := ts.GetAzureRecommendations()
+		require.NoError(t, err)
+		assert.Equal(t, testAzureRecommendations, res)
+	})
+
+	t.Run("No selected profiles", func(t *testing.T) {
+		db.EXPECT().GetSelectedAzureProfiles().Return(nil, nil)
+
+		res, err := ts.GetAzureRecommendations()
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db.EXPECT().GetSelectedAzureProfiles().Return(nil, aerrMock)
+
+		res, err := ts.GetAzureRecommendations()
+		assert.ErrorIs(t, err, aerrMock)
+		assert.Nil(t, res)
+	})
+}
+
+func TestGetAzureRecommendationErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	ts := ThunderService{
+		Database: db,
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	expected := []model.AzureRecommendationError{
+		{
+			SeqValue:  5,
+			ProfileID: utils.Str2oid("000000000000000000000001"),
+			Category:  model.AzureAuthentication,
+			Error:     "unable to get the access token: 401 Unauthorized",
+			CreatedAt: utils.P("2024-05-08T12:00:00Z"),
+		},
+	}
+
+	t.Run("Last", func(t *testing.T) {
+		db.EXPECT().GetLastAzureSeqValue().Return(uint64(5), nil)
+		db.EXPECT().GetAzureRecommendationErrors(uint64(5)).Return(expected, nil)
+
+		res, err := ts.GetLastAzureRecommendationErrors()
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("Last with error", func(t *testing.T) {
+		db.EXPECT().GetLastAzureSeqValue().Return(uint64(0), aerrMock)
+
+		res, err := ts.GetLastAzureRecommendationErrors()
+		assert.ErrorIs(t, err, aerrMock)
+		assert.Nil(t, res)
+	})
+
+	t.Run("By seqValue", func(t *testing.T) {
+		db.EXPECT().GetAzureRecommendationErrors(uint64(3)).Return(expected, nil)
+
+		res, err := ts.GetAzureRecommendationErrorsBySeqValue(3)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+}
+
+func TestCreateAzureRecommendationsXlsx(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	db := NewMockMongoDatabaseInterface(mockCtrl)
+	ts := ThunderService{
+		Config: config.Configuration{
+			ResourceFilePath: "../../resources",
+		},
+		Database: db,
+		Log:      logger.NewLogger("TEST"),
+	}
+
+	selected := []primitive.ObjectID{utils.Str2oid("000000000000000000000001")}
+
+	db.EXPECT().GetSelectedAzureProfiles().Return(selected, nil)
+	db.EXPECT().GetAzureRecommendationsByProfiles(selected).Return(testAzureRecommendations, nil)
+
+	sheet := "Azure recommendations"
+
+	xlsx, err := ts.CreateAzureRecommendationsXlsx()
+	require.NoError(t, err)
+
+	assert.Equal(t, "Profile", xlsx.GetCellValue(sheet, "A1"))
+	assert.Equal(t, "Resource ID", xlsx.GetCellValue(sheet, "L1"))
+
+	assert.Equal(t, "profile1", xlsx.GetCellValue(sheet, "A2"))
+	assert.Equal(t, model.AzureManagedDisk, xlsx.GetCellValue(sheet, "C2"))
+	assert.Equal(t, "disk1", xlsx.GetCellValue(sheet, "E2"))
+	assert.Equal(t, "rg1", xlsx.GetCellValue(sheet, "F2"))
+	assert.Equal(t, "128", xlsx.GetCellValue(sheet, "H2"))
+
+	assert.Equal(t, "vm1", xlsx.GetCellValue(sheet, "E3"))
+	assert.Equal(t, "Standard_D8s_v3", xlsx.GetCellValue(sheet, "H3"))
+	assert.Equal(t, "3.5", xlsx.GetCellValue(sheet, "I3"))
+	assert.Equal(t, "20", xlsx.GetCellValue(sheet, "J3"))
+}
@@ -29,6 +29,9 @@ func (ts *ThunderService) AddOciProfile(profile model.OciProfile) (*model.OciPro
 		return nil, err
 	}
 
+	// the secret is write-only
+	profile.PrivateKey = nil
+
 	return &profile, nil
 }
 
@@ -37,6 +40,8 @@ func (ts *ThunderService) UpdateOciProfile(profile model.OciProfile) (*model.Oci
 		return nil, err
 	}
 
+	profile.PrivateKey = nil
+
 	return &profile, nil
 }
 func (ts *ThunderService) GetOciProfiles() ([]model.OciProfile, error) {
@@ -68,6 +68,7 @@ func TestAddOciProfile(t *testing.T) {
 		actual, err := as.AddOciProfile(profile)
 		require.NoError(t, err)
 
+		expected.PrivateKey = nil
 		assert.Equal(t, &expected, actual)
 	})
 
@@ -123,6 +124,7 @@ func TestUpdateOciProfile(t *testing.T) {
 
 		actual, err := as.UpdateOciProfile(profile)
 		require.NoError(t, err)
+		profile.PrivateKey = nil
 		assert.Equal(t, profile, *actual)
 	})
 
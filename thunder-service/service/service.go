@@ -60,6 +60,10 @@ type ThunderServiceInterface interface {
 	GetAzureProfiles() ([]model.AzureProfile, error)
 	DeleteAzureProfile(id primitive.ObjectID) error
 	SelectAzureProfile(profileId string, selected bool) error
+	GetAzureRecommendations() ([]model.AzureRecommendation, error)
+	GetLastAzureRecommendationErrors() ([]model.AzureRecommendationError, error)
+	GetAzureRecommendationErrorsBySeqValue(seqValue uint64) ([]model.AzureRecommendationError, error)
+	CreateAzureRecommendationsXlsx() (*excelize.File, error)
 	GetAwsRDS() ([]model.AwsRDS, error)
 
 	GetGcpProfiles() ([]model.GcpProfile, error)
@@ -17,7 +17,9 @@ package crypto
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -39,6 +41,7 @@ const (
 	minUpperCase   = 2
 	passwordLength = 16
 	usernameLength = 16
+	tokenLength    = 32
 )
 
 var sample = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
@@ -125,3 +128,22 @@ func SuggestUsername() string {
 
 	return string(b)
 }
+
+// GenerateToken return a new random token that begins with prefix
+func GenerateToken(prefix string) (string, error) {
+	b := make([]byte, tokenLength)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken return the hash used to store and look up a token generated by GenerateToken.
+// The tokens are random, so they don't need a salt nor a slow hash function
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
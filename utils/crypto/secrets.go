@@ -0,0 +1,327 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+const (
+	secretsKeyLength = 32
+	secretPrefix     = "enc:v1:"
+)
+
+// Keyring encrypts the secrets with envelope encryption: every secret is encrypted with
+// its own random data key, which is in turn encrypted with the current master key.
+// The previous master keys are kept only to decrypt the secrets not rotated yet.
+// A nil Keyring leaves the secrets in clear
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring returns a keyring which encrypts with the current master key
+func NewKeyring(current []byte, previous ...[]byte) (*Keyring, error) {
+	k := &Keyring{
+		keys: make(map[string]cipher.AEAD),
+	}
+
+	id, err := k.add(current)
+	if err != nil {
+		return nil, err
+	}
+
+	k.current = id
+
+	for _, key := range previous {
+		if _, err := k.add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// LoadKeyring returns the keyring of the base64 encoded master keys.
+// The current key is read from keyFile when key is empty, if neither is set it returns nil
+func LoadKeyring(key, keyFile string, previous []string) (*Keyring, error) {
+	if key == "" && keyFile != "" {
+		raw, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, utils.NewError(err, "Can't read the secrets key file")
+		}
+
+		key = string(raw)
+	}
+
+	if strings.TrimSpace(key) == "" {
+		if len(previous) > 0 {
+			return nil, utils.NewErrorf("%w: previous keys without the current one", utils.ErrInvalidSecretsKey)
+		}
+
+		return nil, nil
+	}
+
+	current, err := DecodeSecretsKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	previousKeys := make([][]byte, 0, len(previous))
+
+	for _, p := range previous {
+		k, err := DecodeSecretsKey(p)
+		if err != nil {
+			return nil, err
+		}
+
+		previousKeys = append(previousKeys, k)
+	}
+
+	return NewKeyring(current, previousKeys...)
+}
+
+// DecodeSecretsKey decodes a base64 encoded master key
+func DecodeSecretsKey(key string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, utils.NewErrorf("%w: %s", utils.ErrInvalidSecretsKey, err)
+	}
+
+	if len(raw) != secretsKeyLength {
+		return nil, utils.NewErrorf("%w: the key must be %d bytes long", utils.ErrInvalidSecretsKey, secretsKeyLength)
+	}
+
+	return raw, nil
+}
+
+// GenerateSecretsKey returns a new random master key, base64 encoded
+func GenerateSecretsKey() (string, error) {
+	key := make([]byte, secretsKeyLength)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// IsEncryptedSecret returns true if the value was encrypted by a Keyring
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, secretPrefix)
+}
+
+// CurrentKeyID returns the identifier of the master key used to encrypt
+func (k *Keyring) CurrentKeyID() string {
+	if k == nil {
+		return ""
+	}
+
+	return k.current
+}
+
+// Encrypt encrypts the secret with a new data key
+func (k *Keyring) Encrypt(secret string) (string, error) {
+	if k == nil || IsEncryptedSecret(secret) {
+		return secret, nil
+	}
+
+	dataKey := make([]byte, secretsKeyLength)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	dataCipher, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := seal(dataCipher, []byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(k.keys[k.current], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return formatSecret(k.current, wrappedKey, data), nil
+}
+
+// Decrypt decrypts the secret, the values not encrypted are returned as they are
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncryptedSecret(value) {
+		return value, nil
+	}
+
+	id, wrappedKey, data, err := parseSecret(value)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := k.unwrap(id, wrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	dataCipher, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := open(dataCipher, data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+// Rotate encrypts the data key of the secret with the current master key, the secrets
+// in clear are encrypted. It returns false if the secret was already up to date
+func (k *Keyring) Rotate(value string) (string, bool, error) {
+	if k == nil {
+		return "", false, utils.NewErrorf("%w: no key configured", utils.ErrInvalidSecretsKey)
+	}
+
+	if !IsEncryptedSecret(value) {
+		secret, err := k.Encrypt(value)
+		if err != nil {
+			return "", false, err
+		}
+
+		return secret, true, nil
+	}
+
+	id, wrappedKey, data, err := parseSecret(value)
+	if err != nil {
+		return "", false, err
+	}
+
+	if id == k.current {
+		return value, false, nil
+	}
+
+	dataKey, err := k.unwrap(id, wrappedKey)
+	if err != nil {
+		return "", false, err
+	}
+
+	wrappedKey, err = seal(k.keys[k.current], dataKey)
+	if err != nil {
+		return "", false, err
+	}
+
+	return formatSecret(k.current, wrappedKey, data), true, nil
+}
+
+func (k *Keyring) add(key []byte) (string, error) {
+	if len(key) != secretsKeyLength {
+		return "", utils.NewErrorf("%w: the key must be %d bytes long", utils.ErrInvalidSecretsKey, secretsKeyLength)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(key)
+	id := hex.EncodeToString(sum[:4])
+	k.keys[id] = aead
+
+	return id, nil
+}
+
+func (k *Keyring) unwrap(id string, wrappedKey []byte) ([]byte, error) {
+	if k == nil {
+		return nil, utils.NewErrorf("%w: no key configured to decrypt secrets of key %s", utils.ErrUnknownSecretsKey, id)
+	}
+
+	masterCipher, ok := k.keys[id]
+	if !ok {
+		return nil, utils.NewErrorf("%w: %s", utils.ErrUnknownSecretsKey, id)
+	}
+
+	return open(masterCipher, wrappedKey)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, utils.NewErrorf("%w: ciphertext too short", utils.ErrInvalidSecret)
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, utils.NewErrorf("%w: %s", utils.ErrInvalidSecret, err)
+	}
+
+	return plaintext, nil
+}
+
+func formatSecret(id string, wrappedKey, data []byte) string {
+	return fmt.Sprintf("%s%s:%s:%s", secretPrefix, id,
+		base64.StdEncoding.EncodeToString(wrappedKey),
+		base64.StdEncoding.EncodeToString(data))
+}
+
+func parseSecret(value string) (string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, secretPrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, utils.NewErrorf("%w: malformed value", utils.ErrInvalidSecret)
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, utils.NewErrorf("%w: %s", utils.ErrInvalidSecret, err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, utils.NewErrorf("%w: %s", utils.ErrInvalidSecret, err)
+	}
+
+	return parts[0], wrappedKey, data, nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+func newTestKeyring(t *testing.T) (*Keyring, string) {
+	key, err := GenerateSecretsKey()
+	require.NoError(t, err)
+
+	raw, err := DecodeSecretsKey(key)
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(raw)
+	require.NoError(t, err)
+
+	return keyring, key
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	keyring, _ := newTestKeyring(t)
+
+	encrypted, err := keyring.Encrypt("my secret")
+	require.NoError(t, err)
+
+	assert.True(t, IsEncryptedSecret(encrypted))
+	assert.NotContains(t, encrypted, "my secret")
+	assert.True(t, strings.HasPrefix(encrypted, secretPrefix+keyring.CurrentKeyID()+":"))
+
+	other, err := keyring.Encrypt("my secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, other)
+
+	actual, err := keyring.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "my secret", actual)
+
+	t.Run("Encrypted values aren't encrypted twice", func(t *testing.T) {
+		actual, err := keyring.Encrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, encrypted, actual)
+	})
+
+	t.Run("Values in clear are returned as they are", func(t *testing.T) {
+		actual, err := keyring.Decrypt("plain")
+		require.NoError(t, err)
+		assert.Equal(t, "plain", actual)
+	})
+
+	t.Run("Tampered value", func(t *testing.T) {
+		tampered := encrypted[:len(encrypted)-4] + "AAA="
+
+		_, err := keyring.Decrypt(tampered)
+		assert.ErrorIs(t, err, utils.ErrInvalidSecret)
+
+		_, err = keyring.Decrypt(secretPrefix + "abc")
+		assert.ErrorIs(t, err, utils.ErrInvalidSecret)
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		other, _ := newTestKeyring(t)
+
+		_, err := other.Decrypt(encrypted)
+		assert.ErrorIs(t, err, utils.ErrUnknownSecretsKey)
+	})
+
+	t.Run("Nil keyring", func(t *testing.T) {
+		var nilKeyring *Keyring
+
+		actual, err := nilKeyring.Encrypt("my secret")
+		require.NoError(t, err)
+		assert.Equal(t, "my secret", actual)
+
+		_, err = nilKeyring.Decrypt(encrypted)
+		assert.ErrorIs(t, err, utils.ErrUnknownSecretsKey)
+	})
+}
+
+func TestKeyring_Rotate(t *testing.T) {
+	oldKey, err := GenerateSecretsKey()
+	require.NoError(t, err)
+	newKey, err := GenerateSecretsKey()
+	require.NoError(t, err)
+
+	oldKeyring, err := LoadKeyring(oldKey, "", nil)
+	require.NoError(t, err)
+
+	encrypted, err := oldKeyring.Encrypt("my secret")
+	require.NoError(t, err)
+
+	keyring, err := LoadKeyring(newKey, "", []string{oldKey})
+	require.NoError(t, err)
+
+	rotated, changed, err := keyring.Rotate(encrypted)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, strings.HasPrefix(rotated, secretPrefix+keyring.CurrentKeyID()+":"))
+
+	_, err = oldKeyring.Decrypt(rotated)
+	assert.ErrorIs(t, err, utils.ErrUnknownSecretsKey)
+
+	actual, err := keyring.Decrypt(rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "my secret", actual)
+
+	same, changed, err := keyring.Rotate(rotated)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, rotated, same)
+
+	plain, changed, err := keyring.Rotate("plain")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, IsEncryptedSecret(plain))
+
+	var nilKeyring *Keyring
+	_, _, err = nilKeyring.Rotate(encrypted)
+	assert.ErrorIs(t, err, utils.ErrInvalidSecretsKey)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	key, err := GenerateSecretsKey()
+	require.NoError(t, err)
+
+	t.Run("No key", func(t *testing.T) {
+		keyring, err := LoadKeyring("", "", nil)
+		require.NoError(t, err)
+		assert.Nil(t, keyring)
+	})
+
+	t.Run("Key file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "secrets.key")
+		require.NoError(t, os.WriteFile(path, []byte(key+"\n"), 0600))
+
+		keyring, err := LoadKeyring("", path, nil)
+		require.NoError(t, err)
+
+		expected, err := LoadKeyring(key, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected.CurrentKeyID(), keyring.CurrentKeyID())
+	})
+
+	t.Run("Invalid keys", func(t *testing.T) {
+		_, err := LoadKeyring("not base64!", "", nil)
+		assert.ErrorIs(t, err, utils.ErrInvalidSecretsKey)
+
+		_, err = LoadKeyring("c2hvcnQ=", "", nil)
+		assert.ErrorIs(t, err, utils.ErrInvalidSecretsKey)
+
+		_, err = LoadKeyring("", "", []string{key})
+		assert.ErrorIs(t, err, utils.ErrInvalidSecretsKey)
+
+		_, err = LoadKeyring("", "/nonexistent/secrets.key", nil)
+		assert.Error(t, err)
+	})
+}
@@ -97,3 +97,67 @@ var ErrInvalidExadata = errors.New("invalid exadata")
 var ErrInvalidOracleContract = errors.New("invalid oracle contract")
 
 var ErrMissingDatabaseNotFound = errors.New("Missing database not found")
+
+var ErrAlertRoutingRuleNotFound = errors.New("Alert routing rule not found")
+
+var ErrInvalidAlertRoutingRule = errors.New("Invalid alert routing rule")
+
+var ErrMaintenanceWindowNotFound = errors.New("Maintenance window not found")
+
+var ErrInvalidMaintenanceWindow = errors.New("Invalid maintenance window")
+
+var ErrAlertAlreadyResolved = errors.New("Alert already resolved")
+
+var ErrInvalidAlertResolution = errors.New("Invalid alert resolution")
+
+var ErrInvalidAlertComment = errors.New("Invalid alert comment")
+
+var ErrHostDataSubmissionNotFound = errors.New("Hostdata submission not found")
+
+var ErrInvalidMigrationVersion = errors.New("Invalid migration version")
+
+var ErrOIDCDiscovery = errors.New("Unable to discover the OpenID Connect issuer")
+
+var ErrUnsupportedCredentials = errors.New("Username and password credentials aren't supported by this authentication provider")
+
+var ErrInvalidScenario = errors.New("Invalid scenario")
+
+var ErrCoreFactorNotFound = errors.New("Core factor not found")
+
+var ErrInvalidCoreFactor = errors.New("Invalid core factor")
+
+var ErrInvalidForecastHorizon = errors.New("Invalid forecast horizon")
+
+var ErrReportSubscriptionNotFound = errors.New("Report subscription not found")
+
+var ErrInvalidReportSubscription = errors.New("Invalid report subscription")
+
+var ErrEmailerDisabled = errors.New("Emailer is disabled")
+
+var ErrInvalidHostHistoryRange = errors.New("Invalid host history range")
+
+var ErrHostDataAlreadyExists = errors.New("Hostdata already exists")
+
+var ErrUnsupportedSchemaVersion = errors.New("Unsupported hostdata schema version")
+
+var ErrInvalidSecretsKey = errors.New("Invalid secrets key")
+
+var ErrUnknownSecretsKey = errors.New("Unknown secrets key")
+
+var ErrInvalidSecret = errors.New("Invalid encrypted secret")
+
+var ErrAPITokenNotFound = errors.New("API token not found")
+
+var ErrInvalidAPIToken = errors.New("Invalid API token")
+
+var ErrInvalidServiceAccount = errors.New("Invalid service account")
+
+var ErrAgentCredentialNotFound = errors.New("Agent credential not found")
+
+var ErrInvalidAgentCredential = errors.New("Invalid agent credential")
+
+var ErrAgentHostnameMismatch = errors.New("The hostname doesn't match the agent credential")
+
+var ErrAgentCredentialAlreadyExists = errors.New("Agent credential already exists")
+
+var ErrInvalidAuditFilter = errors.New("Invalid audit log filter")
@@ -0,0 +1,225 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package exutils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+
+	"github.com/ercole-io/ercole/v2/utils"
+)
+
+// Sheet contains a sheet of a xlsx file converted to another format
+type Sheet struct {
+	Name    string
+	Content []byte
+}
+
+// ToCSV return a csv for each sheet of the file, in the order of the sheets
+func ToCSV(file *excelize.File) ([]Sheet, error) {
+	sheets := make([]Sheet, 0)
+
+	for _, name := range sheetNames(file) {
+		var buf bytes.Buffer
+
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(file.GetRows(name)); err != nil {
+			return nil, utils.NewError(err, "CSV")
+		}
+
+		sheets = append(sheets, Sheet{Name: name, Content: buf.Bytes()})
+	}
+
+	return sheets, nil
+}
+
+const (
+	pdfPageWidth     = 842
+	pdfPageHeight    = 595
+	pdfMargin        = 30
+	pdfFontSize      = 7
+	pdfLineHeight    = 9
+	pdfMaxLineLength = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6)
+	pdfLinesPerPage  = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+	pdfMaxCellLength = 40
+)
+
+// ToPDF return a landscape A4 pdf with the sheets of the file written as text tables.
+// Cells longer than 40 characters and rows that don't fit the page width are truncated
+func ToPDF(file *excelize.File, title string) []byte {
+	lines := make([]string, 0)
+
+	for _, name := range sheetNames(file) {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+
+		lines = append(lines, fmt.Sprintf("%s - %s", title, name), "")
+		lines = append(lines, textTable(file.GetRows(name))...)
+	}
+
+	pages := make([][]string, 0)
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+
+	pages = append(pages, lines)
+
+	return writePDF(pages)
+}
+
+func sheetNames(file *excelize.File) []string {
+	sheetMap := file.GetSheetMap()
+
+	indexes := make([]int, 0, len(sheetMap))
+	for i := range sheetMap {
+		indexes = append(indexes, i)
+	}
+
+	sort.Ints(indexes)
+
+	names := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		names = append(names, sheetMap[i])
+	}
+
+	return names
+}
+
+func textTable(rows [][]string) []string {
+	widths := make([]int, 0)
+
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+
+			if l := len([]rune(cell)); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+
+	for i := range widths {
+		if widths[i] > pdfMaxCellLength {
+			widths[i] = pdfMaxCellLength
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+
+	for _, row := range rows {
+		var line strings.Builder
+
+		for i, cell := range row {
+			r := []rune(cell)
+			if len(r) > widths[i] {
+				r = append(r[:widths[i]-2], '.', '.')
+			}
+
+			line.WriteString(string(r))
+			line.WriteString(strings.Repeat(" ", widths[i]-len(r)+2))
+		}
+
+		l := []rune(strings.TrimRight(line.String(), " "))
+		if len(l) > pdfMaxLineLength {
+			l = l[:pdfMaxLineLength]
+		}
+
+		lines = append(lines, string(l))
+	}
+
+	return lines
+}
+
+func writePDF(pages [][]string) []byte {
+	var buf bytes.Buffer
+
+	offsets := make([]int, 0)
+	object := func(content string) {
+		offsets = append(offsets, buf.Len())
+		buf.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", len(offsets), content))
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+
+		var stream strings.Builder
+
+		stream.WriteString(fmt.Sprintf("BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin))
+
+		for _, line := range lines {
+			stream.WriteString(fmt.Sprintf("(%s) Tj T*\n", pdfEscape(line)))
+		}
+
+		stream.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", stream.Len(), stream.String()))
+	}
+
+	xref := buf.Len()
+
+	buf.WriteString(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1))
+
+	for _, offset := range offsets {
+		buf.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+
+	buf.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref))
+
+	return buf.Bytes()
+}
+
+// pdfEscape return the string as content of a pdf literal string encoded in latin-1,
+// the characters that can't be encoded are replaced by '?'
+func pdfEscape(s string) string {
+	var out strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			out.WriteRune('\\')
+			out.WriteRune(r)
+		case r >= 32 && r < 127:
+			out.WriteRune(r)
+		case r >= 160 && r < 256:
+			out.WriteString(fmt.Sprintf("\\%03o", r))
+		default:
+			out.WriteRune('?')
+		}
+	}
+
+	return out.String()
+}
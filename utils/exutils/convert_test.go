@@ -0,0 +1,74 @@
+// Copyright (c) 2024 Sorint.lab S.p.A.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package exutils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestXLSX(rows int) *excelize.File {
+	file := excelize.NewFile()
+	file.SetSheetName("Sheet1", "Hosts")
+	file.SetCellValue("Hosts", "A1", "Hostname")
+	file.SetCellValue("Hosts", "B1", "Location")
+
+	for i := 0; i < rows; i++ {
+		file.SetCellValue("Hosts", fmt.Sprintf("A%d", i+2), fmt.Sprintf("host%02d", i))
+		file.SetCellValue("Hosts", fmt.Sprintf("B%d", i+2), "Italy, Milan")
+	}
+
+	file.NewSheet("Clusters")
+	file.SetCellValue("Clusters", "A1", "Name")
+	file.SetCellValue("Clusters", "A2", "cluster (prod)")
+
+	return file
+}
+
+func TestToCSV(t *testing.T) {
+	sheets, err := ToCSV(newTestXLSX(2))
+	require.NoError(t, err)
+
+	expected := []Sheet{
+		{Name: "Hosts", Content: []byte("Hostname,Location\nhost00,\"Italy, Milan\"\nhost01,\"Italy, Milan\"\n")},
+		{Name: "Clusters", Content: []byte("Name\ncluster (prod)\n")},
+	}
+	assert.Equal(t, expected, sheets)
+}
+
+func TestToPDF(t *testing.T) {
+	pdf := ToPDF(newTestXLSX(100), "Report")
+
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "/Count 2")
+	assert.Contains(t, string(pdf), "(Report - Hosts) Tj")
+	assert.Contains(t, string(pdf), "(host00    Italy, Milan) Tj")
+	assert.Contains(t, string(pdf), "(cluster \\(prod\\)) Tj")
+
+	xref := bytes.Index(pdf, []byte("\nxref\n")) + 1
+	assert.True(t, strings.HasSuffix(string(pdf), fmt.Sprintf("startxref\n%d\n%%%%EOF\n", xref)))
+}
+
+func TestPdfEscape(t *testing.T) {
+	assert.Equal(t, "a\\(b\\) \\\\ \\350 ?", pdfEscape("a(b) \\ è €"))
+}